@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
+	"github.com/buger/jsonparser"
 	jsonpatch "github.com/evanphx/json-patch"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/errortypes"
@@ -12,7 +14,14 @@ import (
 	"github.com/prebid/prebid-server/stored_requests"
 )
 
-// GetAccount looks up the config.Account object referenced by the given accountID, with access rules applied
+// maxAccountAncestors limits the number of parent accounts an account can inherit its config from.
+const maxAccountAncestors = 10
+
+// GetAccount looks up the config.Account object referenced by the given accountID, with access rules applied.
+//
+// The account JSON is applied as a JSON merge patch (RFC 7386) over the config of its parent account, if it has one,
+// and otherwise over the host AccountDefaults. The config of the parent is resolved the same way. An account which
+// fails validation is rejected with the validation errors.
 func GetAccount(ctx context.Context, cfg *config.Configuration, fetcher stored_requests.AccountFetcher, accountID string) (account *config.Account, errs []error) {
 	// Check BlacklistedAcctMap until we have deprecated it
 	if _, found := cfg.BlacklistedAcctMap[accountID]; found {
@@ -44,9 +53,13 @@ func GetAccount(ctx context.Context, cfg *config.Configuration, fetcher stored_r
 		pubAccount.ID = accountID
 		account = &pubAccount
 	} else {
-		// accountID resolved to a valid account, merge with AccountDefaults for a complete config
+		// accountID resolved to a valid account, merge with its parents and AccountDefaults for a complete config
+		accountChain, chainErrs := fetchAccountChain(ctx, fetcher, accountID, accountJSON)
+		if len(chainErrs) > 0 {
+			return nil, chainErrs
+		}
 		account = &config.Account{}
-		completeJSON, err := jsonpatch.MergePatch(cfg.AccountDefaultsJSON(), accountJSON)
+		completeJSON, err := mergeAccountChain(cfg.AccountDefaultsJSON(), accountChain)
 		if err == nil {
 			err = json.Unmarshal(completeJSON, account)
 		}
@@ -58,6 +71,12 @@ func GetAccount(ctx context.Context, cfg *config.Configuration, fetcher stored_r
 		if len(account.ID) == 0 {
 			account.ID = accountID
 		}
+		if validationErrs := account.Validate("account"); len(validationErrs) > 0 {
+			for _, err := range validationErrs {
+				errs = append(errs, fmt.Errorf("The config of account %s is invalid: %v", accountID, err))
+			}
+			return nil, errs
+		}
 	}
 	if account.Disabled {
 		errs = append(errs, &errortypes.BlacklistedAcct{
@@ -67,3 +86,75 @@ func GetAccount(ctx context.Context, cfg *config.Configuration, fetcher stored_r
 	}
 	return account, nil
 }
+
+// fetchAccountChain returns the JSON of the account followed by the JSON of its ancestors, from its parent to the
+// root account. The IDs of the ancestors are left out, so that they don't replace the ID of the account.
+func fetchAccountChain(ctx context.Context, fetcher stored_requests.AccountFetcher, accountID string, accountJSON json.RawMessage) ([]json.RawMessage, []error) {
+	chain := []json.RawMessage{accountJSON}
+	chainIDs := []string{accountID}
+	for {
+		parentID, err := getParentID(chain[len(chain)-1])
+		if err != nil {
+			return nil, []error{fmt.Errorf("The parent of account %s is invalid: %v", chainIDs[len(chainIDs)-1], err)}
+		}
+		if parentID == "" {
+			return chain, nil
+		}
+		for _, id := range chainIDs {
+			if id == parentID {
+				return nil, []error{fmt.Errorf("The parents of account %s form a cycle: %s > %s", accountID, strings.Join(chainIDs, " > "), parentID)}
+			}
+		}
+		if len(chainIDs) > maxAccountAncestors {
+			return nil, []error{fmt.Errorf("Account %s has more than %d ancestors", accountID, maxAccountAncestors)}
+		}
+
+		parentJSON, fetchErrs := fetcher.FetchAccount(ctx, parentID)
+		if len(fetchErrs) > 0 || parentJSON == nil {
+			var errs []error
+			for _, err := range fetchErrs {
+				if _, ok := err.(stored_requests.NotFoundError); !ok {
+					errs = append(errs, err)
+				}
+			}
+			if len(errs) == 0 {
+				errs = []error{fmt.Errorf("The parent account %s of account %s doesn't exist", parentID, chainIDs[len(chainIDs)-1])}
+			}
+			return nil, errs
+		}
+		if parentJSON, err = jsonpatch.MergePatch(parentJSON, []byte(`{"id":null}`)); err != nil {
+			return nil, []error{fmt.Errorf("The parent account %s is invalid: %v", parentID, err)}
+		}
+
+		chain = append(chain, parentJSON)
+		chainIDs = append(chainIDs, parentID)
+	}
+}
+
+// getParentID returns the parent of the account JSON, or an empty string if it has none.
+func getParentID(accountJSON json.RawMessage) (string, error) {
+	value, dataType, _, err := jsonparser.Get(accountJSON, "parent")
+	if dataType == jsonparser.NotExist || dataType == jsonparser.Null {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if dataType != jsonparser.String {
+		return "", fmt.Errorf("parent must be a string. Got %s", value)
+	}
+	return jsonparser.ParseString(value)
+}
+
+// mergeAccountChain applies the account chain returned by fetchAccountChain over the defaults, starting from the
+// root account.
+func mergeAccountChain(defaultsJSON json.RawMessage, chain []json.RawMessage) (json.RawMessage, error) {
+	completeJSON := defaultsJSON
+	for i := len(chain) - 1; i >= 0; i-- {
+		var err error
+		if completeJSON, err = jsonpatch.MergePatch(completeJSON, chain[i]); err != nil {
+			return nil, err
+		}
+	}
+	return completeJSON, nil
+}
@@ -92,3 +92,103 @@ func TestGetAccount(t *testing.T) {
 		})
 	}
 }
+
+func TestGetAccountInheritance(t *testing.T) {
+	fetcher := &mockInheritanceFetcher{
+		accounts: map[string]json.RawMessage{
+			"network":       json.RawMessage(`{"id":"network","debug_allow":true,"events_enabled":true,"bid_adjustments":{"factors":{"appnexus":0.9,"rubicon":0.8}}}`),
+			"publisher":     json.RawMessage(`{"parent":"network","events_enabled":false,"bid_adjustments":{"factors":{"rubicon":null}}}`),
+			"sub_publisher": json.RawMessage(`{"parent":"publisher","bidders":{"disabled":["openx"]}}`),
+			"orphan":        json.RawMessage(`{"parent":"unknown"}`),
+			"cycle_a":       json.RawMessage(`{"parent":"cycle_b"}`),
+			"cycle_b":       json.RawMessage(`{"parent":"cycle_a"}`),
+			"bad_parent":    json.RawMessage(`{"parent":1}`),
+			"invalid":       json.RawMessage(`{"parent":"network","price_floors":{"enforce_floors_rate":120}}`),
+		},
+	}
+
+	testCases := []struct {
+		description     string
+		accountID       string
+		expectedAccount *config.Account
+		expectedErrs    []error
+	}{
+		{
+			description: "Account without a parent",
+			accountID:   "network",
+			expectedAccount: &config.Account{
+				ID:             "network",
+				DebugAllow:     true,
+				EventsEnabled:  true,
+				CacheTTL:       config.DefaultTTLs{Banner: 300},
+				BidAdjustments: config.AccountBidAdjustments{Factors: map[string]float64{"appnexus": 0.9, "rubicon": 0.8}},
+			},
+		},
+		{
+			description: "Account merged over its parent",
+			accountID:   "publisher",
+			expectedAccount: &config.Account{
+				ID:             "publisher",
+				Parent:         "network",
+				DebugAllow:     true,
+				CacheTTL:       config.DefaultTTLs{Banner: 300},
+				BidAdjustments: config.AccountBidAdjustments{Factors: map[string]float64{"appnexus": 0.9}},
+			},
+		},
+		{
+			description: "Account merged over its ancestors",
+			accountID:   "sub_publisher",
+			expectedAccount: &config.Account{
+				ID:             "sub_publisher",
+				Parent:         "publisher",
+				DebugAllow:     true,
+				CacheTTL:       config.DefaultTTLs{Banner: 300},
+				BidAdjustments: config.AccountBidAdjustments{Factors: map[string]float64{"appnexus": 0.9}},
+				Bidders:        config.AccountBidders{Disabled: []string{"openx"}},
+			},
+		},
+		{
+			description:  "Unknown parent",
+			accountID:    "orphan",
+			expectedErrs: []error{fmt.Errorf("The parent account unknown of account orphan doesn't exist")},
+		},
+		{
+			description:  "Cyclic parents",
+			accountID:    "cycle_a",
+			expectedErrs: []error{fmt.Errorf("The parents of account cycle_a form a cycle: cycle_a > cycle_b > cycle_a")},
+		},
+		{
+			description:  "Parent which isn't a string",
+			accountID:    "bad_parent",
+			expectedErrs: []error{fmt.Errorf("The parent of account bad_parent is invalid: parent must be a string. Got 1")},
+		},
+		{
+			description:  "Invalid config",
+			accountID:    "invalid",
+			expectedErrs: []error{fmt.Errorf("The config of account invalid is invalid: account.price_floors.enforce_floors_rate should be between 0 and 100. Got 120")},
+		},
+	}
+
+	for _, test := range testCases {
+		cfg := &config.Configuration{
+			AccountDefaults: config.Account{CacheTTL: config.DefaultTTLs{Banner: 300}},
+		}
+		assert.NoError(t, cfg.MarshalAccountDefaults())
+
+		account, errs := GetAccount(context.Background(), cfg, fetcher, test.accountID)
+
+		assert.Equal(t, test.expectedAccount, account, test.description+":account")
+		assert.Equal(t, test.expectedErrs, errs, test.description+":errors")
+	}
+}
+
+type mockInheritanceFetcher struct {
+	accounts map[string]json.RawMessage
+}
+
+func (f *mockInheritanceFetcher) FetchAccount(ctx context.Context, accountID string) (json.RawMessage, []error) {
+	if account, ok := f.accounts[accountID]; ok {
+		return account, nil
+	}
+	return nil, []error{stored_requests.NotFoundError{ID: accountID, DataType: "Account"}}
+}
@@ -21,7 +21,7 @@ import (
 // It requires that:
 //
 //   1. Bidders communicate with external servers over HTTP.
-//   2. The HTTP request bodies are legal JSON.
+//   2. The HTTP request bodies are legal JSON, or the Bidder implements adapters.BinaryBodyBidder to decode them.
 //
 // Although the project does not require it, we _strongly_ recommend that all Bidders write tests using this.
 // Doing so has the following benefits:
@@ -184,14 +184,14 @@ type expectedBid struct {
 // Marshalling the structs and then using a JSON-diff library isn't great either, since
 
 // assertMakeRequestsOutput compares the actual http requests to the expected ones.
-func assertMakeRequestsOutput(t *testing.T, filename string, actual []*adapters.RequestData, expected []httpCall) {
+func assertMakeRequestsOutput(t *testing.T, filename string, actual []*adapters.RequestData, expected []httpCall, bidder adapters.Bidder) {
 	t.Helper()
 
 	if len(expected) != len(actual) {
 		t.Fatalf("%s: MakeRequests had wrong request count. Expected %d, got %d", filename, len(expected), len(actual))
 	}
 	for i := 0; i < len(actual); i++ {
-		diffHttpRequests(t, fmt.Sprintf("%s: httpRequest[%d]", filename, i), actual[i], &(expected[i].Request))
+		diffHttpRequests(t, fmt.Sprintf("%s: httpRequest[%d]", filename, i), actual[i], &(expected[i].Request), bidder)
 	}
 }
 
@@ -241,8 +241,8 @@ func assertMakeBidsOutput(t *testing.T, filename string, bidderResponse *adapter
 }
 
 // diffHttpRequests compares the actual HTTP request data to the expected one.
-// It assumes that the request bodies are JSON
-func diffHttpRequests(t *testing.T, description string, actual *adapters.RequestData, expected *httpRequest) {
+// The expected request bodies are JSON, so the bodies of bidders which don't send JSON are decoded first.
+func diffHttpRequests(t *testing.T, description string, actual *adapters.RequestData, expected *httpRequest, bidder adapters.Bidder) {
 	if actual == nil {
 		t.Errorf("Bidders cannot return nil HTTP calls. %s was nil.", description)
 		return
@@ -254,7 +254,15 @@ func diffHttpRequests(t *testing.T, description string, actual *adapters.Request
 		expectedHeader, _ := json.Marshal(expected.Headers)
 		diffJson(t, description, actualHeader, expectedHeader)
 	}
-	diffJson(t, description, actual.Body, expected.Body)
+
+	actualBody := actual.Body
+	if binaryBidder, ok := bidder.(adapters.BinaryBodyBidder); ok && len(actualBody) > 0 {
+		var err error
+		if actualBody, err = binaryBidder.DecodeRequestBody(actual); err != nil {
+			t.Fatalf("%s failed to decode the request body. %v", description, err)
+		}
+	}
+	diffJson(t, description, actualBody, expected.Body)
 }
 
 func diffBids(t *testing.T, description string, actual *adapters.TypedBid, expected *expectedBid) {
@@ -338,7 +346,7 @@ func testMakeRequestsImpl(t *testing.T, filename string, spec *testSpec, bidder
 
 	// Compare MakeRequests actual output versus expected values found in JSON file
 	assertErrorList(t, fmt.Sprintf("%s: MakeRequests", filename), errs, spec.MakeRequestErrors)
-	assertMakeRequestsOutput(t, filename, requests, spec.HttpCalls)
+	assertMakeRequestsOutput(t, filename, requests, spec.HttpCalls, bidder)
 
 	// Assert no data races occur using original bidRequest copies of references and values
 	assert.Equal(t, deepBidReqCopy, shallowBidReqCopy, "Data race found. Test: %s", filename)
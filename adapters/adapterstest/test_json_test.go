@@ -0,0 +1,76 @@
+package adapterstest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+func TestJsonSamplesBinaryBody(t *testing.T) {
+	RunJSONBidderTest(t, "binarybodytest", &gzipBidder{})
+}
+
+// gzipBidder sends the OpenRTB request compressed with gzip, so its request bodies aren't JSON.
+type gzipBidder struct{}
+
+func (bidder *gzipBidder) MakeRequests(request *openrtb2.BidRequest, reqInfo *adapters.ExtraRequestInfo) ([]*adapters.RequestData, []error) {
+	reqJSON, err := json.Marshal(request)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	var body bytes.Buffer
+	writer := gzip.NewWriter(&body)
+	if _, err := writer.Write(reqJSON); err != nil {
+		return nil, []error{err}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, []error{err}
+	}
+
+	headers := http.Header{}
+	headers.Add("Content-Encoding", "gzip")
+	headers.Add("Content-Type", "application/json;charset=utf-8")
+
+	return []*adapters.RequestData{{
+		Method:  http.MethodPost,
+		Uri:     "http://binary.bidder.com/openrtb2",
+		Body:    body.Bytes(),
+		Headers: headers,
+	}}, nil
+}
+
+func (bidder *gzipBidder) DecodeRequestBody(req *adapters.RequestData) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(req.Body))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
+}
+
+func (bidder *gzipBidder) MakeBids(internalRequest *openrtb2.BidRequest, externalRequest *adapters.RequestData, response *adapters.ResponseData) (*adapters.BidderResponse, []error) {
+	var bidResp openrtb2.BidResponse
+	if err := json.Unmarshal(response.Body, &bidResp); err != nil {
+		return nil, []error{err}
+	}
+
+	bidResponse := adapters.NewBidderResponseWithBidsCapacity(1)
+	bidResponse.Currency = bidResp.Cur
+	for _, seatBid := range bidResp.SeatBid {
+		for i := range seatBid.Bid {
+			bidResponse.Bids = append(bidResponse.Bids, &adapters.TypedBid{
+				Bid:     &seatBid.Bid[i],
+				BidType: openrtb_ext.BidTypeBanner,
+			})
+		}
+	}
+	return bidResponse, nil
+}
@@ -54,6 +54,16 @@ type TimeoutBidder interface {
 	MakeTimeoutNotification(req *RequestData) (*RequestData, []error)
 }
 
+// BinaryBodyBidder is used to identify bidders whose request bodies aren't JSON, such as bidders which
+// send OpenRTB as protobuf.
+type BinaryBodyBidder interface {
+	Bidder
+
+	// DecodeRequestBody returns a JSON view of the body of a request made by MakeRequests. It's used to show
+	// the request in the debug output, and to compare it with the expected request in the JSON tests.
+	DecodeRequestBody(req *RequestData) ([]byte, error)
+}
+
 // BidderResponse wraps the server's response with the list of bids and the currency used by the bidder.
 //
 // Currency declaration is not mandatory but helps to detect an eventual currency mismatch issue.
@@ -139,7 +149,10 @@ func (r *RequestData) SetBasicAuth(username string, password string) {
 type ExtraRequestInfo struct {
 	PbsEntryPoint              metrics.RequestType
 	GlobalPrivacyControlHeader string
-	currencyConversions        currency.Conversions
+	// StoredBidResponses are the bidder responses stored for some of the imps, keyed by imp ID. These imps are
+	// left out of the requests to the bidder, and the stored responses are given to MakeBids instead.
+	StoredBidResponses  map[string]json.RawMessage
+	currencyConversions currency.Conversions
 }
 
 func NewExtraRequestInfo(c currency.Conversions) ExtraRequestInfo {
@@ -5,16 +5,18 @@ import (
 	"github.com/prebid/prebid-server/analytics"
 	"github.com/prebid/prebid-server/analytics/clients"
 	"github.com/prebid/prebid-server/analytics/filesystem"
+	"github.com/prebid/prebid-server/analytics/kafka"
 	"github.com/prebid/prebid-server/analytics/pubstack"
 	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/metrics"
 )
 
 //Modules that need to be logged to need to be initialized here
-func NewPBSAnalytics(analytics *config.Analytics) analytics.PBSAnalyticsModule {
+func NewPBSAnalytics(analytics *config.Analytics, me metrics.MetricsEngine) analytics.PBSAnalyticsModule {
 	modules := make(enabledAnalytics, 0)
 	if len(analytics.File.Filename) > 0 {
 		if mod, err := filesystem.NewFileLogger(analytics.File.Filename); err == nil {
-			modules = append(modules, mod)
+			modules = append(modules, newFilteredModule(config.AnalyticsModuleFile, mod, analytics, me))
 		} else {
 			glog.Fatalf("Could not initialize FileLogger for file %v :%v", analytics.File.Filename, err)
 		}
@@ -29,14 +31,35 @@ func NewPBSAnalytics(analytics *config.Analytics) analytics.PBSAnalyticsModule {
 			analytics.Pubstack.Buffers.BufferSize,
 			analytics.Pubstack.Buffers.Timeout)
 		if err == nil {
-			modules = append(modules, pubstackModule)
+			modules = append(modules, newFilteredModule(config.AnalyticsModulePubstack, pubstackModule, analytics, me))
 		} else {
 			glog.Errorf("Could not initialize PubstackModule: %v", err)
 		}
 	}
+	if analytics.Kafka.Enabled {
+		if kafkaModule, err := kafka.NewModule(analytics.Kafka, me); err == nil {
+			modules = append(modules, newFilteredModule(config.AnalyticsModuleKafka, kafkaModule, analytics, me))
+		} else {
+			glog.Errorf("Could not initialize KafkaModule: %v", err)
+		}
+	}
 	return modules
 }
 
+// ReloadFilters applies the module filters of a reloaded analytics configuration to the modules built by
+// NewPBSAnalytics.
+func ReloadFilters(module analytics.PBSAnalyticsModule, cfg *config.Analytics) {
+	modules, ok := module.(enabledAnalytics)
+	if !ok {
+		return
+	}
+	for _, module := range modules {
+		if filtered, ok := module.(*filteredModule); ok {
+			filtered.setFilter(cfg)
+		}
+	}
+}
+
 //Collection of all the correctly configured analytics modules - implements the PBSAnalyticsModule interface
 type enabledAnalytics []analytics.PBSAnalyticsModule
 
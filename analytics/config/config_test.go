@@ -10,6 +10,7 @@ import (
 
 	"github.com/prebid/prebid-server/analytics"
 	"github.com/prebid/prebid-server/config"
+	metricsConfig "github.com/prebid/prebid-server/metrics/config"
 )
 
 const TEST_DIR string = "testFiles"
@@ -82,7 +83,7 @@ func initAnalytics(count *int) analytics.PBSAnalyticsModule {
 }
 
 func TestNewPBSAnalytics(t *testing.T) {
-	pbsAnalytics := NewPBSAnalytics(&config.Analytics{})
+	pbsAnalytics := NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{})
 	instance := pbsAnalytics.(enabledAnalytics)
 
 	assert.Equal(t, len(instance), 0)
@@ -95,7 +96,7 @@ func TestNewPBSAnalytics_FileLogger(t *testing.T) {
 		}
 	}
 	defer os.RemoveAll(TEST_DIR)
-	mod := NewPBSAnalytics(&config.Analytics{File: config.FileLogs{Filename: TEST_DIR + "/test"}})
+	mod := NewPBSAnalytics(&config.Analytics{File: config.FileLogs{Filename: TEST_DIR + "/test"}}, &metricsConfig.DummyMetricsEngine{})
 	switch modType := mod.(type) {
 	case enabledAnalytics:
 		if len(enabledAnalytics(modType)) != 1 {
@@ -105,7 +106,7 @@ func TestNewPBSAnalytics_FileLogger(t *testing.T) {
 		t.Fatalf("Failed to initialize analytics module")
 	}
 
-	pbsAnalytics := NewPBSAnalytics(&config.Analytics{File: config.FileLogs{Filename: TEST_DIR + "/test"}})
+	pbsAnalytics := NewPBSAnalytics(&config.Analytics{File: config.FileLogs{Filename: TEST_DIR + "/test"}}, &metricsConfig.DummyMetricsEngine{})
 	instance := pbsAnalytics.(enabledAnalytics)
 
 	assert.Equal(t, len(instance), 1)
@@ -125,7 +126,7 @@ func TestNewPBSAnalytics_Pubstack(t *testing.T) {
 			},
 			ConfRefresh: "2h",
 		},
-	})
+	}, &metricsConfig.DummyMetricsEngine{})
 	instanceWithoutError := pbsAnalyticsWithoutError.(enabledAnalytics)
 
 	assert.Equal(t, len(instanceWithoutError), 1)
@@ -134,7 +135,7 @@ func TestNewPBSAnalytics_Pubstack(t *testing.T) {
 		Pubstack: config.Pubstack{
 			Enabled: true,
 		},
-	})
+	}, &metricsConfig.DummyMetricsEngine{})
 	instanceWithError := pbsAnalyticsWithError.(enabledAnalytics)
 	assert.Equal(t, len(instanceWithError), 0)
 }
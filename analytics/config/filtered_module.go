@@ -0,0 +1,104 @@
+package config
+
+import (
+	"math/rand"
+	"sync/atomic"
+
+	"github.com/prebid/prebid-server/analytics"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/metrics"
+)
+
+// filteredModule sends an analytics module the events it's configured to receive, sampled and filtered by
+// endpoint type as set by the host and overridden by the account of the event.
+//
+// The events are sent from a pool of workers, so that a slow module doesn't delay the requests. The events
+// which don't fit in the queue of the pool are dropped and counted in the metrics.
+type filteredModule struct {
+	name   string
+	module analytics.PBSAnalyticsModule
+	// filter holds the config.AnalyticsModuleFilter of the host, which is replaced when the configuration is reloaded
+	filter atomic.Value
+	me     metrics.MetricsEngine
+	// queue is nil when the events are sent from the request goroutines
+	queue  chan func()
+	random func() float64
+}
+
+func newFilteredModule(name string, module analytics.PBSAnalyticsModule, cfg *config.Analytics, me metrics.MetricsEngine) *filteredModule {
+	m := &filteredModule{
+		name:   name,
+		module: module,
+		me:     me,
+		random: rand.Float64,
+	}
+	m.setFilter(cfg)
+	if cfg.Workers.Count > 0 {
+		m.queue = make(chan func(), cfg.Workers.QueueSize)
+		for i := 0; i < cfg.Workers.Count; i++ {
+			go m.work()
+		}
+	}
+	return m
+}
+
+// setFilter replaces the filter of the host with the one of the analytics configuration.
+func (m *filteredModule) setFilter(cfg *config.Analytics) {
+	m.filter.Store(cfg.Modules[m.name])
+}
+
+func (m *filteredModule) work() {
+	for logEvent := range m.queue {
+		logEvent()
+	}
+}
+
+// send logs the event if the module is configured to receive it.
+func (m *filteredModule) send(endpoint string, account *config.Account, logEvent func()) {
+	filter := m.filter.Load().(config.AnalyticsModuleFilter)
+	if account != nil {
+		if override, ok := account.Analytics.Modules[m.name]; ok {
+			filter = filter.Merge(override)
+		}
+	}
+	if !filter.Allows(endpoint) {
+		return
+	}
+	if filter.SampleRate != nil && m.random() >= *filter.SampleRate {
+		return
+	}
+
+	if m.queue == nil {
+		logEvent()
+		return
+	}
+	select {
+	case m.queue <- logEvent:
+	default:
+		m.me.RecordAnalyticsEventDropped(m.name, endpoint)
+	}
+}
+
+func (m *filteredModule) LogAuctionObject(ao *analytics.AuctionObject) {
+	m.send(config.AnalyticsEndpointAuction, ao.Account, func() { m.module.LogAuctionObject(ao) })
+}
+
+func (m *filteredModule) LogVideoObject(vo *analytics.VideoObject) {
+	m.send(config.AnalyticsEndpointVideo, vo.Account, func() { m.module.LogVideoObject(vo) })
+}
+
+func (m *filteredModule) LogCookieSyncObject(cso *analytics.CookieSyncObject) {
+	m.send(config.AnalyticsEndpointCookieSync, nil, func() { m.module.LogCookieSyncObject(cso) })
+}
+
+func (m *filteredModule) LogSetUIDObject(so *analytics.SetUIDObject) {
+	m.send(config.AnalyticsEndpointSetUID, nil, func() { m.module.LogSetUIDObject(so) })
+}
+
+func (m *filteredModule) LogAmpObject(ao *analytics.AmpObject) {
+	m.send(config.AnalyticsEndpointAmp, ao.Account, func() { m.module.LogAmpObject(ao) })
+}
+
+func (m *filteredModule) LogNotificationEventObject(ne *analytics.NotificationEvent) {
+	m.send(config.AnalyticsEndpointEvent, ne.Account, func() { m.module.LogNotificationEventObject(ne) })
+}
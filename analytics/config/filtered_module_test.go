@@ -0,0 +1,168 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/analytics"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilteredModule(t *testing.T) {
+	disabled := false
+	hostRate := 0.01
+	accountRate := 1.0
+
+	hostConfig := &config.Analytics{
+		Modules: map[string]config.AnalyticsModuleFilter{
+			"sample": {SampleRate: &hostRate, Endpoints: []string{config.AnalyticsEndpointAuction, config.AnalyticsEndpointAmp}},
+		},
+	}
+
+	testCases := []struct {
+		description   string
+		account       *config.Account
+		log           func(module analytics.PBSAnalyticsModule, account *config.Account)
+		random        float64
+		expectedCount int
+	}{
+		{
+			description:   "Sampled in by the host rate",
+			log:           logAuction,
+			random:        0.005,
+			expectedCount: 1,
+		},
+		{
+			description:   "Sampled out by the host rate",
+			log:           logAuction,
+			random:        0.5,
+			expectedCount: 0,
+		},
+		{
+			description: "Sampled in by the account rate",
+			account: &config.Account{Analytics: config.AccountAnalytics{Modules: map[string]config.AnalyticsModuleFilter{
+				"sample": {SampleRate: &accountRate},
+			}}},
+			log:           logAuction,
+			random:        0.5,
+			expectedCount: 1,
+		},
+		{
+			description: "Disabled by the account",
+			account: &config.Account{Analytics: config.AccountAnalytics{Modules: map[string]config.AnalyticsModuleFilter{
+				"sample": {Enabled: &disabled, SampleRate: &accountRate},
+			}}},
+			log:           logAuction,
+			random:        0,
+			expectedCount: 0,
+		},
+		{
+			description: "Account override of another module",
+			account: &config.Account{Analytics: config.AccountAnalytics{Modules: map[string]config.AnalyticsModuleFilter{
+				"other": {Enabled: &disabled},
+			}}},
+			log:           logAuction,
+			random:        0,
+			expectedCount: 1,
+		},
+		{
+			description: "Endpoint type filtered out",
+			log: func(module analytics.PBSAnalyticsModule, account *config.Account) {
+				module.LogVideoObject(&analytics.VideoObject{Account: account})
+			},
+			random:        0,
+			expectedCount: 0,
+		},
+		{
+			description: "Endpoint type without an account",
+			account: &config.Account{Analytics: config.AccountAnalytics{Modules: map[string]config.AnalyticsModuleFilter{
+				"sample": {Endpoints: []string{config.AnalyticsEndpointCookieSync}},
+			}}},
+			log: func(module analytics.PBSAnalyticsModule, account *config.Account) {
+				module.LogCookieSyncObject(&analytics.CookieSyncObject{})
+			},
+			random:        0,
+			expectedCount: 0,
+		},
+	}
+
+	for _, test := range testCases {
+		var count int
+		module := newFilteredModule("sample", &sampleModule{&count}, hostConfig, &metrics.MetricsEngineMock{})
+		module.random = func() float64 { return test.random }
+
+		test.log(module, test.account)
+
+		assert.Equal(t, test.expectedCount, count, test.description)
+	}
+}
+
+func TestFilteredModuleWorkers(t *testing.T) {
+	logged := make(chan struct{})
+	unblock := make(chan struct{})
+	module := &blockingModule{logged: logged, unblock: unblock}
+
+	me := &metrics.MetricsEngineMock{}
+	me.On("RecordAnalyticsEventDropped", "blocking", config.AnalyticsEndpointSetUID).Return()
+
+	filtered := newFilteredModule("blocking", module, &config.Analytics{
+		Workers: config.AnalyticsWorkers{Count: 1, QueueSize: 1},
+	}, me)
+
+	// The worker blocks on the first event, the second one waits in the queue and the third one is dropped
+	filtered.LogSetUIDObject(&analytics.SetUIDObject{})
+	select {
+	case <-logged:
+	case <-time.After(time.Second):
+		t.Fatal("The event wasn't sent to the module")
+	}
+	filtered.LogSetUIDObject(&analytics.SetUIDObject{})
+	filtered.LogSetUIDObject(&analytics.SetUIDObject{})
+	me.AssertNumberOfCalls(t, "RecordAnalyticsEventDropped", 1)
+
+	close(unblock)
+	select {
+	case <-logged:
+	case <-time.After(time.Second):
+		t.Fatal("The queued event wasn't sent to the module")
+	}
+}
+
+func TestReloadFilters(t *testing.T) {
+	var count int
+	module := newFilteredModule("sample", &sampleModule{&count}, &config.Analytics{
+		Modules: map[string]config.AnalyticsModuleFilter{
+			"sample": {Endpoints: []string{config.AnalyticsEndpointAmp}},
+		},
+	}, &metrics.MetricsEngineMock{})
+	modules := enabledAnalytics{module}
+
+	logAuction(modules, nil)
+	assert.Equal(t, 0, count, "The auction events should be filtered out before the reload")
+
+	ReloadFilters(modules, &config.Analytics{
+		Modules: map[string]config.AnalyticsModuleFilter{
+			"sample": {Endpoints: []string{config.AnalyticsEndpointAuction}},
+		},
+	})
+	logAuction(modules, nil)
+	assert.Equal(t, 1, count, "The auction events should be sent after the reload")
+}
+
+func logAuction(module analytics.PBSAnalyticsModule, account *config.Account) {
+	module.LogAuctionObject(&analytics.AuctionObject{Account: account})
+}
+
+// blockingModule signals every SetUID event it receives, then waits to be unblocked
+type blockingModule struct {
+	sampleModule
+	logged  chan struct{}
+	unblock chan struct{}
+}
+
+func (m *blockingModule) LogSetUIDObject(so *analytics.SetUIDObject) {
+	m.logged <- struct{}{}
+	<-m.unblock
+}
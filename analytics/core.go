@@ -5,6 +5,7 @@ import (
 
 	"github.com/mxmCherry/openrtb/v15/openrtb2"
 	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/hooks/hookanalytics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/usersync"
 )
@@ -34,6 +35,12 @@ type AuctionObject struct {
 	Response  *openrtb2.BidResponse
 	Account   *config.Account
 	StartTime time.Time
+	// HookExecutionOutcome holds the outcome of every hook stage which ran for the request.
+	HookExecutionOutcome []hookanalytics.StageOutcome
+	// SeatDetails holds what every bidder of the auction was sent and returned.
+	SeatDetails []*SeatDetails
+	// StoredRequestVariants holds the variants picked for the Stored Requests and Imps, if any were split into variants.
+	StoredRequestVariants *openrtb_ext.ExtStoredRequestVariants
 }
 
 //Loggable object of a transaction at /openrtb2/amp endpoint
@@ -45,6 +52,10 @@ type AmpObject struct {
 	AmpTargetingValues map[string]string
 	Origin             string
 	StartTime          time.Time
+	SeatDetails        []*SeatDetails
+	Account            *config.Account
+	// StoredRequestVariants holds the variant picked for the Stored Request, if it was split into variants.
+	StoredRequestVariants *openrtb_ext.ExtStoredRequestVariants
 }
 
 //Loggable object of a transaction at /openrtb2/video endpoint
@@ -56,6 +67,42 @@ type VideoObject struct {
 	VideoRequest  *openrtb_ext.BidRequestVideo
 	VideoResponse *openrtb_ext.BidResponseVideo
 	StartTime     time.Time
+	SeatDetails   []*SeatDetails
+	Account       *config.Account
+	// StoredRequestVariants holds the variants picked for the Stored Requests and Imps, if any were split into variants.
+	StoredRequestVariants *openrtb_ext.ExtStoredRequestVariants
+}
+
+// SeatDetails describes the part a bidder took in an auction: the request it was sent, the HTTP calls
+// it answered and what became of the bids it returned.
+type SeatDetails struct {
+	Bidder openrtb_ext.BidderName
+	// Request is the request sent to the bidder, once the privacy policies were enforced.
+	Request *openrtb2.BidRequest
+	// HttpCalls holds the status and latency of the HTTP calls made to the bidder.
+	HttpCalls []*HttpCallDetails
+	// Bids are all the bids returned by the bidder, before they were validated.
+	Bids []*openrtb2.Bid
+	// RejectedBids are the bids which were removed from the auction, along with the reason why.
+	RejectedBids []*RejectedBid
+	// Targeting holds the targeting keys set on the bids which won, by bid ID.
+	Targeting          map[string]map[string]string
+	ResponseTimeMillis int
+}
+
+// HttpCallDetails describes an HTTP call made to a bidder. The Status is 0 when no response was received.
+type HttpCallDetails struct {
+	Uri     string
+	Status  int
+	Latency time.Duration
+}
+
+// RejectedBid is a bid which was removed from the auction.
+type RejectedBid struct {
+	Bid    *openrtb2.Bid
+	Reason openrtb_ext.RejectionReason
+	// Message details the reason, such as the missing field or the floor the bid was below.
+	Message string
 }
 
 //Loggable object of a transaction at /setuid
@@ -0,0 +1,201 @@
+package kafka
+
+import (
+	"encoding/json"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/analytics"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/metrics"
+)
+
+const moduleName = "kafka"
+
+// Event types, which are also the suffixes of the topics the events are published on
+const (
+	auctionEvent           = "auction"
+	ampEvent               = "amp"
+	videoEvent             = "video"
+	cookieSyncEvent        = "cookie_sync"
+	setUIDEvent            = "setuid"
+	notificationEventEvent = "notification_event"
+)
+
+// KafkaModule publishes the analytics events to Kafka, as versioned JSON records on a topic per event type.
+// The events are sent in batches by an async producer. Events which don't fit in its buffer, or which the
+// brokers fail to acknowledge, are dropped and counted in the metrics.
+type KafkaModule struct {
+	producer    sarama.AsyncProducer
+	topicPrefix string
+	me          metrics.MetricsEngine
+	sigTermCh   chan os.Signal
+	now         func() time.Time
+	// closed is set once the producer is closed on shutdown, after which the events are dropped
+	closed    bool
+	muxClosed sync.RWMutex
+}
+
+// NewModule connects to the Kafka brokers and returns the analytics module publishing to them.
+func NewModule(cfg config.KafkaAnalytics, me metrics.MetricsEngine) (analytics.PBSAnalyticsModule, error) {
+	producerConfig := sarama.NewConfig()
+	producerConfig.ClientID = cfg.ClientID
+	producerConfig.ChannelBufferSize = cfg.BufferSize
+	producerConfig.Producer.RequiredAcks = sarama.WaitForLocal
+	producerConfig.Producer.Flush.Messages = cfg.BatchSize
+	producerConfig.Producer.Flush.Frequency = time.Duration(cfg.FlushIntervalMillis) * time.Millisecond
+	producerConfig.Producer.Return.Errors = true
+
+	producer, err := sarama.NewAsyncProducer(cfg.Brokers, producerConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	module := newModule(producer, cfg.TopicPrefix, me)
+	signal.Notify(module.sigTermCh, os.Interrupt, syscall.SIGTERM)
+
+	glog.Info("[kafka] Kafka analytics configured and ready")
+	return module, nil
+}
+
+func newModule(producer sarama.AsyncProducer, topicPrefix string, me metrics.MetricsEngine) *KafkaModule {
+	module := &KafkaModule{
+		producer:    producer,
+		topicPrefix: topicPrefix,
+		me:          me,
+		sigTermCh:   make(chan os.Signal, 1),
+		now:         time.Now,
+	}
+	go module.start()
+	return module
+}
+
+// start counts the events which failed to be published, and flushes the pending events on shutdown.
+func (k *KafkaModule) start() {
+	producerErrors := k.producer.Errors()
+	for {
+		select {
+		case producerErr, ok := <-producerErrors:
+			if !ok {
+				return
+			}
+			eventType, _ := producerErr.Msg.Metadata.(string)
+			glog.Warningf("[kafka] Failed to publish a %s event: %v", eventType, producerErr.Err)
+			k.me.RecordAnalyticsEventDropped(moduleName, eventType)
+		case <-k.sigTermCh:
+			glog.Info("[kafka] Received Close, flushing the pending events")
+			k.close()
+			// Keep counting the failures until the producer closes its errors channel
+			k.sigTermCh = nil
+		}
+	}
+}
+
+func (k *KafkaModule) close() {
+	k.muxClosed.Lock()
+	defer k.muxClosed.Unlock()
+
+	k.closed = true
+	k.producer.AsyncClose()
+}
+
+func (k *KafkaModule) LogAuctionObject(ao *analytics.AuctionObject) {
+	if ao == nil {
+		return
+	}
+	var key string
+	if ao.Request != nil {
+		key = ao.Request.ID
+	}
+	k.publish(auctionEvent, key, makeAuctionRecord(ao))
+}
+
+func (k *KafkaModule) LogVideoObject(vo *analytics.VideoObject) {
+	if vo == nil {
+		return
+	}
+	var key string
+	if vo.Request != nil {
+		key = vo.Request.ID
+	}
+	k.publish(videoEvent, key, makeVideoRecord(vo))
+}
+
+func (k *KafkaModule) LogCookieSyncObject(cso *analytics.CookieSyncObject) {
+	if cso == nil {
+		return
+	}
+	k.publish(cookieSyncEvent, "", makeCookieSyncRecord(cso))
+}
+
+func (k *KafkaModule) LogSetUIDObject(so *analytics.SetUIDObject) {
+	if so == nil {
+		return
+	}
+	k.publish(setUIDEvent, "", makeSetUIDRecord(so))
+}
+
+func (k *KafkaModule) LogAmpObject(ao *analytics.AmpObject) {
+	if ao == nil {
+		return
+	}
+	var key string
+	if ao.Request != nil {
+		key = ao.Request.ID
+	}
+	k.publish(ampEvent, key, makeAmpRecord(ao))
+}
+
+func (k *KafkaModule) LogNotificationEventObject(ne *analytics.NotificationEvent) {
+	if ne == nil {
+		return
+	}
+	var key string
+	if ne.Request != nil {
+		key = ne.Request.BidID
+	}
+	k.publish(notificationEventEvent, key, makeNotificationEventRecord(ne))
+}
+
+// publish queues the event to be sent to its topic. The event is dropped rather than blocking the
+// request when the producer's buffer is full.
+func (k *KafkaModule) publish(eventType string, key string, event interface{}) {
+	value, err := json.Marshal(record{
+		Version:   recordVersion,
+		Type:      eventType,
+		Timestamp: k.now(),
+		Event:     event,
+	})
+	if err != nil {
+		glog.Warningf("[kafka] Cannot serialize a %s event: %v", eventType, err)
+		k.me.RecordAnalyticsEventDropped(moduleName, eventType)
+		return
+	}
+
+	message := &sarama.ProducerMessage{
+		Topic:    k.topicPrefix + eventType,
+		Value:    sarama.ByteEncoder(value),
+		Metadata: eventType,
+	}
+	if key != "" {
+		message.Key = sarama.StringEncoder(key)
+	}
+
+	k.muxClosed.RLock()
+	defer k.muxClosed.RUnlock()
+
+	if k.closed {
+		k.me.RecordAnalyticsEventDropped(moduleName, eventType)
+		return
+	}
+	select {
+	case k.producer.Input() <- message:
+	default:
+		k.me.RecordAnalyticsEventDropped(moduleName, eventType)
+	}
+}
@@ -0,0 +1,214 @@
+package kafka
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/mocks"
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/analytics"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLogEvents(t *testing.T) {
+	startTime := time.Date(2021, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		description   string
+		log           func(module analytics.PBSAnalyticsModule)
+		expectedTopic string
+		expectedKey   sarama.Encoder
+		expectedValue string
+	}{
+		{
+			description: "Auction",
+			log: func(module analytics.PBSAnalyticsModule) {
+				module.LogAuctionObject(&analytics.AuctionObject{
+					Status:    200,
+					Errors:    []error{errors.New("some error")},
+					Request:   &openrtb2.BidRequest{ID: "req"},
+					Response:  &openrtb2.BidResponse{ID: "req"},
+					Account:   &config.Account{ID: "account"},
+					StartTime: startTime,
+				})
+			},
+			expectedTopic: "prebid.auction",
+			expectedKey:   sarama.StringEncoder("req"),
+			expectedValue: `{"version":1,"type":"auction","timestamp":"2021-09-01T12:00:01Z","event":{"status":200,"errors":["some error"],"request":{"id":"req","imp":null},"response":{"id":"req"},"account_id":"account","start_time":"2021-09-01T12:00:00Z"}}`,
+		},
+		{
+			description: "AMP",
+			log: func(module analytics.PBSAnalyticsModule) {
+				module.LogAmpObject(&analytics.AmpObject{
+					Status:             200,
+					Request:            &openrtb2.BidRequest{ID: "req"},
+					AmpTargetingValues: map[string]string{"hb_pb": "1.00"},
+					Origin:             "https://example.com",
+					StartTime:          startTime,
+				})
+			},
+			expectedTopic: "prebid.amp",
+			expectedKey:   sarama.StringEncoder("req"),
+			expectedValue: `{"version":1,"type":"amp","timestamp":"2021-09-01T12:00:01Z","event":{"status":200,"request":{"id":"req","imp":null},"targeting":{"hb_pb":"1.00"},"origin":"https://example.com","start_time":"2021-09-01T12:00:00Z"}}`,
+		},
+		{
+			description: "Video",
+			log: func(module analytics.PBSAnalyticsModule) {
+				module.LogVideoObject(&analytics.VideoObject{
+					Status:    200,
+					Request:   &openrtb2.BidRequest{ID: "req"},
+					StartTime: startTime,
+				})
+			},
+			expectedTopic: "prebid.video",
+			expectedKey:   sarama.StringEncoder("req"),
+			expectedValue: `{"version":1,"type":"video","timestamp":"2021-09-01T12:00:01Z","event":{"status":200,"request":{"id":"req","imp":null},"start_time":"2021-09-01T12:00:00Z"}}`,
+		},
+		{
+			description: "Cookie Sync",
+			log: func(module analytics.PBSAnalyticsModule) {
+				module.LogCookieSyncObject(&analytics.CookieSyncObject{
+					Status: 400,
+					Errors: []error{errors.New("bad request")},
+				})
+			},
+			expectedTopic: "prebid.cookie_sync",
+			expectedValue: `{"version":1,"type":"cookie_sync","timestamp":"2021-09-01T12:00:01Z","event":{"status":400,"errors":["bad request"]}}`,
+		},
+		{
+			description: "SetUID",
+			log: func(module analytics.PBSAnalyticsModule) {
+				module.LogSetUIDObject(&analytics.SetUIDObject{
+					Status:  200,
+					Bidder:  "appnexus",
+					UID:     "uid",
+					Success: true,
+				})
+			},
+			expectedTopic: "prebid.setuid",
+			expectedValue: `{"version":1,"type":"setuid","timestamp":"2021-09-01T12:00:01Z","event":{"status":200,"bidder":"appnexus","uid":"uid","success":true}}`,
+		},
+		{
+			description: "Notification Event",
+			log: func(module analytics.PBSAnalyticsModule) {
+				module.LogNotificationEventObject(&analytics.NotificationEvent{
+					Request: &analytics.EventRequest{Type: analytics.Win, BidID: "bid"},
+					Account: &config.Account{ID: "account"},
+				})
+			},
+			expectedTopic: "prebid.notification_event",
+			expectedKey:   sarama.StringEncoder("bid"),
+			expectedValue: `{"version":1,"type":"notification_event","timestamp":"2021-09-01T12:00:01Z","event":{"request":{"type":"win","bidid":"bid"},"account_id":"account"}}`,
+		},
+	}
+
+	for _, test := range testCases {
+		producer := mocks.NewAsyncProducer(t, nil)
+		producer.ExpectInputWithMessageCheckerFunctionAndSucceed(func(message *sarama.ProducerMessage) error {
+			value, _ := message.Value.Encode()
+			assert.Equal(t, test.expectedTopic, message.Topic, test.description)
+			assert.Equal(t, test.expectedKey, message.Key, test.description)
+			assert.JSONEq(t, test.expectedValue, string(value), test.description)
+			return nil
+		})
+
+		module := newModule(producer, "prebid.", &metrics.MetricsEngineMock{})
+		module.now = func() time.Time { return startTime.Add(time.Second) }
+
+		test.log(module)
+		assert.NoError(t, producer.Close(), test.description)
+	}
+}
+
+func TestPublishErrorDropsEvent(t *testing.T) {
+	dropped := make(chan string, 1)
+	me := &metrics.MetricsEngineMock{}
+	me.On("RecordAnalyticsEventDropped", moduleName, mock.Anything).Run(func(args mock.Arguments) {
+		dropped <- args.String(1)
+	}).Return()
+
+	producer := mocks.NewAsyncProducer(t, nil)
+	producer.ExpectInputAndFail(errors.New("broker unavailable"))
+
+	module := newModule(producer, "prebid.", me)
+	module.LogSetUIDObject(&analytics.SetUIDObject{Status: 200})
+
+	select {
+	case eventType := <-dropped:
+		assert.Equal(t, setUIDEvent, eventType)
+	case <-time.After(time.Second):
+		t.Fatal("The failed event wasn't counted as dropped")
+	}
+	producer.AsyncClose()
+}
+
+func TestFullBufferDropsEvent(t *testing.T) {
+	me := &metrics.MetricsEngineMock{}
+	me.On("RecordAnalyticsEventDropped", moduleName, cookieSyncEvent).Return()
+
+	// Nothing reads the producer's input, as when the buffer is full
+	producer := &fakeProducer{
+		input:  make(chan *sarama.ProducerMessage),
+		errors: make(chan *sarama.ProducerError),
+	}
+
+	module := newModule(producer, "prebid.", me)
+	module.LogCookieSyncObject(&analytics.CookieSyncObject{Status: 200})
+
+	me.AssertNumberOfCalls(t, "RecordAnalyticsEventDropped", 1)
+}
+
+func TestClosedModuleDropsEvent(t *testing.T) {
+	me := &metrics.MetricsEngineMock{}
+	me.On("RecordAnalyticsEventDropped", moduleName, ampEvent).Return()
+
+	producer := mocks.NewAsyncProducer(t, nil)
+	module := newModule(producer, "prebid.", me)
+	module.close()
+
+	module.LogAmpObject(&analytics.AmpObject{Status: 200})
+
+	me.AssertNumberOfCalls(t, "RecordAnalyticsEventDropped", 1)
+}
+
+func TestNewModuleError(t *testing.T) {
+	_, err := NewModule(config.KafkaAnalytics{
+		Brokers:             []string{},
+		BatchSize:           1,
+		FlushIntervalMillis: 1,
+		BufferSize:          1,
+	}, &metrics.MetricsEngineMock{})
+
+	assert.Error(t, err, "Brokers must be configured")
+}
+
+type fakeProducer struct {
+	input  chan *sarama.ProducerMessage
+	errors chan *sarama.ProducerError
+}
+
+func (p *fakeProducer) AsyncClose() {
+	close(p.errors)
+}
+
+func (p *fakeProducer) Close() error {
+	close(p.errors)
+	return nil
+}
+
+func (p *fakeProducer) Input() chan<- *sarama.ProducerMessage {
+	return p.input
+}
+
+func (p *fakeProducer) Successes() <-chan *sarama.ProducerMessage {
+	return nil
+}
+
+func (p *fakeProducer) Errors() <-chan *sarama.ProducerError {
+	return p.errors
+}
@@ -0,0 +1,148 @@
+package kafka
+
+import (
+	"time"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/analytics"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/usersync"
+)
+
+// recordVersion is the version of the records published by the module. It must be bumped whenever a record
+// changes in a way which isn't backward compatible, so that the consumers can tell the formats apart.
+const recordVersion = 1
+
+// record is the envelope of every event published by the module.
+type record struct {
+	Version   int         `json:"version"`
+	Type      string      `json:"type"`
+	Timestamp time.Time   `json:"timestamp"`
+	Event     interface{} `json:"event"`
+}
+
+type auctionRecord struct {
+	Status    int                   `json:"status"`
+	Errors    []string              `json:"errors,omitempty"`
+	Request   *openrtb2.BidRequest  `json:"request,omitempty"`
+	Response  *openrtb2.BidResponse `json:"response,omitempty"`
+	AccountID string                `json:"account_id,omitempty"`
+	StartTime time.Time             `json:"start_time"`
+}
+
+type ampRecord struct {
+	Status             int                   `json:"status"`
+	Errors             []string              `json:"errors,omitempty"`
+	Request            *openrtb2.BidRequest  `json:"request,omitempty"`
+	Response           *openrtb2.BidResponse `json:"response,omitempty"`
+	AmpTargetingValues map[string]string     `json:"targeting,omitempty"`
+	Origin             string                `json:"origin,omitempty"`
+	StartTime          time.Time             `json:"start_time"`
+}
+
+type videoRecord struct {
+	Status        int                           `json:"status"`
+	Errors        []string                      `json:"errors,omitempty"`
+	Request       *openrtb2.BidRequest          `json:"request,omitempty"`
+	Response      *openrtb2.BidResponse         `json:"response,omitempty"`
+	VideoRequest  *openrtb_ext.BidRequestVideo  `json:"video_request,omitempty"`
+	VideoResponse *openrtb_ext.BidResponseVideo `json:"video_response,omitempty"`
+	StartTime     time.Time                     `json:"start_time"`
+}
+
+type cookieSyncRecord struct {
+	Status       int                           `json:"status"`
+	Errors       []string                      `json:"errors,omitempty"`
+	BidderStatus []*usersync.CookieSyncBidders `json:"bidder_status,omitempty"`
+}
+
+type setUIDRecord struct {
+	Status  int      `json:"status"`
+	Bidder  string   `json:"bidder,omitempty"`
+	UID     string   `json:"uid,omitempty"`
+	Errors  []string `json:"errors,omitempty"`
+	Success bool     `json:"success"`
+}
+
+type notificationEventRecord struct {
+	Request   *analytics.EventRequest `json:"request,omitempty"`
+	AccountID string                  `json:"account_id,omitempty"`
+}
+
+func makeAuctionRecord(ao *analytics.AuctionObject) *auctionRecord {
+	r := &auctionRecord{
+		Status:    ao.Status,
+		Errors:    errorStrings(ao.Errors),
+		Request:   ao.Request,
+		Response:  ao.Response,
+		StartTime: ao.StartTime,
+	}
+	if ao.Account != nil {
+		r.AccountID = ao.Account.ID
+	}
+	return r
+}
+
+func makeAmpRecord(ao *analytics.AmpObject) *ampRecord {
+	return &ampRecord{
+		Status:             ao.Status,
+		Errors:             errorStrings(ao.Errors),
+		Request:            ao.Request,
+		Response:           ao.AuctionResponse,
+		AmpTargetingValues: ao.AmpTargetingValues,
+		Origin:             ao.Origin,
+		StartTime:          ao.StartTime,
+	}
+}
+
+func makeVideoRecord(vo *analytics.VideoObject) *videoRecord {
+	return &videoRecord{
+		Status:        vo.Status,
+		Errors:        errorStrings(vo.Errors),
+		Request:       vo.Request,
+		Response:      vo.Response,
+		VideoRequest:  vo.VideoRequest,
+		VideoResponse: vo.VideoResponse,
+		StartTime:     vo.StartTime,
+	}
+}
+
+func makeCookieSyncRecord(cso *analytics.CookieSyncObject) *cookieSyncRecord {
+	return &cookieSyncRecord{
+		Status:       cso.Status,
+		Errors:       errorStrings(cso.Errors),
+		BidderStatus: cso.BidderStatus,
+	}
+}
+
+func makeSetUIDRecord(so *analytics.SetUIDObject) *setUIDRecord {
+	return &setUIDRecord{
+		Status:  so.Status,
+		Bidder:  so.Bidder,
+		UID:     so.UID,
+		Errors:  errorStrings(so.Errors),
+		Success: so.Success,
+	}
+}
+
+func makeNotificationEventRecord(ne *analytics.NotificationEvent) *notificationEventRecord {
+	r := &notificationEventRecord{
+		Request: ne.Request,
+	}
+	if ne.Account != nil {
+		r.AccountID = ne.Account.ID
+	}
+	return r
+}
+
+// errorStrings converts the errors to their messages, since most error types don't serialize to JSON.
+func errorStrings(errs []error) []string {
+	if len(errs) == 0 {
+		return nil
+	}
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	return messages
+}
@@ -1,5 +1,11 @@
 package config
 
+import (
+	"fmt"
+
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
 // IntegrationType enumerates the values of integrations Prebid Server can configure for an account
 type IntegrationType string
 
@@ -13,13 +19,143 @@ const (
 
 // Account represents a publisher account configuration
 type Account struct {
-	ID            string      `mapstructure:"id" json:"id"`
-	Disabled      bool        `mapstructure:"disabled" json:"disabled"`
-	CacheTTL      DefaultTTLs `mapstructure:"cache_ttl" json:"cache_ttl"`
-	EventsEnabled bool        `mapstructure:"events_enabled" json:"events_enabled"`
-	CCPA          AccountCCPA `mapstructure:"ccpa" json:"ccpa"`
-	GDPR          AccountGDPR `mapstructure:"gdpr" json:"gdpr"`
-	DebugAllow    bool        `mapstructure:"debug_allow" json:"debug_allow"`
+	ID string `mapstructure:"id" json:"id"`
+	// Parent is the ID of the account this account inherits its config from. The config of the parent,
+	// itself merged over the config of its own parent, replaces the host account_defaults for this account.
+	Parent        string             `mapstructure:"parent" json:"parent,omitempty"`
+	Disabled      bool               `mapstructure:"disabled" json:"disabled"`
+	CacheTTL      DefaultTTLs        `mapstructure:"cache_ttl" json:"cache_ttl"`
+	EventsEnabled bool               `mapstructure:"events_enabled" json:"events_enabled"`
+	CCPA          AccountCCPA        `mapstructure:"ccpa" json:"ccpa"`
+	GDPR          AccountGDPR        `mapstructure:"gdpr" json:"gdpr"`
+	DebugAllow    bool               `mapstructure:"debug_allow" json:"debug_allow"`
+	PriceFloors   AccountPriceFloors `mapstructure:"price_floors" json:"price_floors"`
+	Hooks         AccountHooks       `mapstructure:"hooks" json:"hooks"`
+	Analytics     AccountAnalytics   `mapstructure:"analytics" json:"analytics"`
+	// BidAdjustments and Bidders are merged with request.ext.prebid.bidadjustmentfactors and request.ext.prebid.aliases
+	BidAdjustments AccountBidAdjustments `mapstructure:"bid_adjustments" json:"bid_adjustments"`
+	Bidders        AccountBidders        `mapstructure:"bidders" json:"bidders"`
+}
+
+// Validate checks the config of an account fetched for a request. The invalid fields are named after the path
+// of the account in the errors, like accounts.{id}.price_floors.enforce_floors_rate.
+func (a *Account) Validate(path string) []error {
+	return a.validate(path, nil)
+}
+
+func (a *Account) validate(path string, errs []error) []error {
+	errs = a.PriceFloors.validate(path+".price_floors", errs)
+	errs = a.BidAdjustments.validate(path+".bid_adjustments", errs)
+	errs = validateAnalyticsModules(path+".analytics.modules", a.Analytics.Modules, errs)
+	errs = a.Hooks.ExecutionPlan.validate(path+".hooks.execution_plan", errs)
+	return errs
+}
+
+// AccountAnalytics overrides the host selection of the events sent to the analytics modules
+type AccountAnalytics struct {
+	// Modules are keyed by module name, like the analytics.modules of the host
+	Modules map[string]AnalyticsModuleFilter `mapstructure:"modules" json:"modules,omitempty"`
+}
+
+// AccountBidAdjustments represents account-specific bid adjustment factors
+type AccountBidAdjustments struct {
+	// Factors are keyed by bidder or alias, like request.ext.prebid.bidadjustmentfactors
+	Factors map[string]float64 `mapstructure:"factors" json:"factors,omitempty"`
+	// AllowRequestOverride lets the request factor of a bidder replace the account one
+	AllowRequestOverride bool `mapstructure:"allow_request_override" json:"allow_request_override"`
+}
+
+// MergeFactors returns the request bid adjustment factors merged with the account ones. The request factor of
+// a bidder which also has an account factor is only used if the account allows it.
+func (ba *AccountBidAdjustments) MergeFactors(requestFactors map[string]float64) map[string]float64 {
+	if len(ba.Factors) == 0 {
+		return requestFactors
+	}
+	merged := make(map[string]float64, len(ba.Factors)+len(requestFactors))
+	for bidder, factor := range requestFactors {
+		merged[bidder] = factor
+	}
+	for bidder, factor := range ba.Factors {
+		if _, inRequest := requestFactors[bidder]; !inRequest || !ba.AllowRequestOverride {
+			merged[bidder] = factor
+		}
+	}
+	return merged
+}
+
+func (ba *AccountBidAdjustments) validate(path string, errs []error) []error {
+	for bidder, factor := range ba.Factors {
+		if factor <= 0 {
+			errs = append(errs, fmt.Errorf("%s.factors.%s must be a positive number. Got %f", path, bidder, factor))
+		}
+	}
+	return errs
+}
+
+// AccountBidders represents account-specific bidder configuration
+type AccountBidders struct {
+	// Allowed, if not empty, lists the only bidders which may be called for the account
+	Allowed []string `mapstructure:"allowed" json:"allowed,omitempty"`
+	// Disabled lists the bidders which are never called for the account
+	Disabled []string `mapstructure:"disabled" json:"disabled,omitempty"`
+	// Aliases are defined like request.ext.prebid.aliases
+	Aliases map[string]string `mapstructure:"aliases" json:"aliases,omitempty"`
+	// AllowRequestAliasOverride lets the request redefine an alias which is also defined by the account
+	AllowRequestAliasOverride bool `mapstructure:"allow_request_alias_override" json:"allow_request_alias_override"`
+}
+
+// MergeAliases returns the request aliases merged with the account ones. The request definition of an alias
+// which is also defined by the account is only used if the account allows it.
+func (b *AccountBidders) MergeAliases(requestAliases map[string]string) map[string]string {
+	if len(b.Aliases) == 0 {
+		return requestAliases
+	}
+	merged := make(map[string]string, len(b.Aliases)+len(requestAliases))
+	for alias, bidder := range requestAliases {
+		merged[alias] = bidder
+	}
+	for alias, bidder := range b.Aliases {
+		if _, inRequest := requestAliases[alias]; !inRequest || !b.AllowRequestAliasOverride {
+			merged[alias] = bidder
+		}
+	}
+	return merged
+}
+
+// BidderEnabled indicates whether a bidder may be called for the account. Both the name used in the request
+// and the core bidder name are checked, so that allowing or disabling a bidder applies to its aliases too.
+func (b *AccountBidders) BidderEnabled(bidder string, coreBidder string) bool {
+	for _, disabled := range b.Disabled {
+		if disabled == bidder || disabled == coreBidder {
+			return false
+		}
+	}
+	if len(b.Allowed) == 0 {
+		return true
+	}
+	for _, allowed := range b.Allowed {
+		if allowed == bidder || allowed == coreBidder {
+			return true
+		}
+	}
+	return false
+}
+
+// AccountPriceFloors represents account-specific price floor configuration
+type AccountPriceFloors struct {
+	Enabled           bool `mapstructure:"enabled" json:"enabled"`
+	EnforceFloorsRate int  `mapstructure:"enforce_floors_rate" json:"enforce_floors_rate"`
+	EnforceDealFloors bool `mapstructure:"enforce_deal_floors" json:"enforce_deal_floors"`
+	// Data holds the floor rules used when the request does not provide ext.prebid.floors.data
+	// and no stored rules exist for the account.
+	Data *openrtb_ext.PriceFloorData `mapstructure:"data" json:"data,omitempty"`
+}
+
+func (pf *AccountPriceFloors) validate(path string, errs []error) []error {
+	if pf.EnforceFloorsRate < 0 || pf.EnforceFloorsRate > 100 {
+		errs = append(errs, fmt.Errorf("%s.enforce_floors_rate should be between 0 and 100. Got %d", path, pf.EnforceFloorsRate))
+	}
+	return errs
 }
 
 // AccountCCPA represents account-specific CCPA configuration
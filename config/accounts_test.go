@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -241,3 +242,158 @@ func TestAccountIntegrationGetByIntegrationType(t *testing.T) {
 		}
 	}
 }
+
+func TestAccountBidAdjustmentsMergeFactors(t *testing.T) {
+	tests := []struct {
+		description string
+		giveAccount AccountBidAdjustments
+		giveRequest map[string]float64
+		wantFactors map[string]float64
+	}{
+		{
+			description: "No account factors",
+			giveRequest: map[string]float64{"appnexus": 0.9},
+			wantFactors: map[string]float64{"appnexus": 0.9},
+		},
+		{
+			description: "No request factors",
+			giveAccount: AccountBidAdjustments{Factors: map[string]float64{"appnexus": 0.8}},
+			wantFactors: map[string]float64{"appnexus": 0.8},
+		},
+		{
+			description: "Account factor wins",
+			giveAccount: AccountBidAdjustments{Factors: map[string]float64{"appnexus": 0.8}},
+			giveRequest: map[string]float64{"appnexus": 0.9, "rubicon": 0.7},
+			wantFactors: map[string]float64{"appnexus": 0.8, "rubicon": 0.7},
+		},
+		{
+			description: "Request factor wins if the account allows it",
+			giveAccount: AccountBidAdjustments{Factors: map[string]float64{"appnexus": 0.8, "openx": 0.5}, AllowRequestOverride: true},
+			giveRequest: map[string]float64{"appnexus": 0.9},
+			wantFactors: map[string]float64{"appnexus": 0.9, "openx": 0.5},
+		},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.wantFactors, tt.giveAccount.MergeFactors(tt.giveRequest), tt.description)
+	}
+}
+
+func TestAccountBiddersMergeAliases(t *testing.T) {
+	tests := []struct {
+		description string
+		giveAccount AccountBidders
+		giveRequest map[string]string
+		wantAliases map[string]string
+	}{
+		{
+			description: "No account aliases",
+			giveRequest: map[string]string{"alias": "appnexus"},
+			wantAliases: map[string]string{"alias": "appnexus"},
+		},
+		{
+			description: "Account alias wins",
+			giveAccount: AccountBidders{Aliases: map[string]string{"alias": "rubicon"}},
+			giveRequest: map[string]string{"alias": "appnexus", "other": "openx"},
+			wantAliases: map[string]string{"alias": "rubicon", "other": "openx"},
+		},
+		{
+			description: "Request alias wins if the account allows it",
+			giveAccount: AccountBidders{Aliases: map[string]string{"alias": "rubicon", "other": "openx"}, AllowRequestAliasOverride: true},
+			giveRequest: map[string]string{"alias": "appnexus"},
+			wantAliases: map[string]string{"alias": "appnexus", "other": "openx"},
+		},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.wantAliases, tt.giveAccount.MergeAliases(tt.giveRequest), tt.description)
+	}
+}
+
+func TestAccountBiddersBidderEnabled(t *testing.T) {
+	tests := []struct {
+		description    string
+		giveAccount    AccountBidders
+		giveBidder     string
+		giveCoreBidder string
+		wantEnabled    bool
+	}{
+		{
+			description:    "No restrictions",
+			giveBidder:     "appnexus",
+			giveCoreBidder: "appnexus",
+			wantEnabled:    true,
+		},
+		{
+			description:    "Disabled bidder",
+			giveAccount:    AccountBidders{Disabled: []string{"appnexus"}},
+			giveBidder:     "appnexus",
+			giveCoreBidder: "appnexus",
+			wantEnabled:    false,
+		},
+		{
+			description:    "Alias of a disabled bidder",
+			giveAccount:    AccountBidders{Disabled: []string{"appnexus"}},
+			giveBidder:     "alias",
+			giveCoreBidder: "appnexus",
+			wantEnabled:    false,
+		},
+		{
+			description:    "Disabled alias",
+			giveAccount:    AccountBidders{Disabled: []string{"alias"}},
+			giveBidder:     "appnexus",
+			giveCoreBidder: "appnexus",
+			wantEnabled:    true,
+		},
+		{
+			description:    "Allowed bidder",
+			giveAccount:    AccountBidders{Allowed: []string{"appnexus"}},
+			giveBidder:     "alias",
+			giveCoreBidder: "appnexus",
+			wantEnabled:    true,
+		},
+		{
+			description:    "Bidder not allowed",
+			giveAccount:    AccountBidders{Allowed: []string{"rubicon"}},
+			giveBidder:     "appnexus",
+			giveCoreBidder: "appnexus",
+			wantEnabled:    false,
+		},
+		{
+			description:    "Disabled wins over allowed",
+			giveAccount:    AccountBidders{Allowed: []string{"appnexus"}, Disabled: []string{"alias"}},
+			giveBidder:     "alias",
+			giveCoreBidder: "appnexus",
+			wantEnabled:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.wantEnabled, tt.giveAccount.BidderEnabled(tt.giveBidder, tt.giveCoreBidder), tt.description)
+	}
+}
+
+func TestAccountValidateAnalyticsModules(t *testing.T) {
+	sampleRate := 1.0
+
+	tests := []struct {
+		description string
+		giveModules map[string]AnalyticsModuleFilter
+		wantErrs    []error
+	}{
+		{
+			description: "Known module",
+			giveModules: map[string]AnalyticsModuleFilter{AnalyticsModulePubstack: {SampleRate: &sampleRate}},
+		},
+		{
+			description: "Unknown module",
+			giveModules: map[string]AnalyticsModuleFilter{"pubstak": {SampleRate: &sampleRate}},
+			wantErrs:    []error{errors.New("account.analytics.modules contains an unknown analytics module: pubstak")},
+		},
+	}
+
+	for _, tt := range tests {
+		account := Account{Analytics: AccountAnalytics{Modules: tt.giveModules}}
+		assert.Equal(t, tt.wantErrs, account.Validate("account"), tt.description)
+	}
+}
@@ -29,6 +29,19 @@ type Adapter struct {
 	Disabled         bool   `mapstructure:"disabled"`
 	ExtraAdapterInfo string `mapstructure:"extra_info"`
 
+	// AliasOf makes this adapter a hard alias of a core bidder, for white-label partners which use the same
+	// code with their own endpoint and usersync_url. The alias takes the same bidder params and bidder info
+	// as the core bidder, and syncs users under its own name.
+	AliasOf string `mapstructure:"aliasOf"`
+	// GVLVendorID of a hard alias. If not set, the alias uses the one of its core bidder.
+	GVLVendorID uint16 `mapstructure:"gvlVendorID"`
+
+	// CircuitBreaker skips the bidder for a while when too many of its recent requests failed.
+	CircuitBreaker AdapterCircuitBreaker `mapstructure:"circuit_breaker"`
+
+	// EndpointCompression compresses the request bodies sent to the bidder's endpoint. Only "gzip" is supported.
+	EndpointCompression string `mapstructure:"endpoint_compression"`
+
 	// needed for Rubicon
 	XAPI AdapterXAPI `mapstructure:"xapi"`
 
@@ -43,6 +56,46 @@ type AdapterXAPI struct {
 	Tracker  string `mapstructure:"tracker"`
 }
 
+// AdapterCircuitBreaker defines when the requests to a bidder are considered failing, and for how long
+// the bidder is skipped when they do. Timeouts, 5xx responses and connection errors count as failures. A timeout
+// only counts when the bidder used up its adaptive timeout budget, or had at least MinTimeoutMillis to respond,
+// so that requests with a short tmax or auction timeout don't open the breaker.
+type AdapterCircuitBreaker struct {
+	Enabled bool `mapstructure:"enabled"`
+	// WindowSeconds is the period over which the requests and failures are counted.
+	WindowSeconds int `mapstructure:"window_seconds"`
+	// MinRequests is the number of requests needed within the window before the breaker may open.
+	MinRequests int `mapstructure:"min_requests"`
+	// FailureRatePercent is the percentage of failed requests within the window which opens the breaker.
+	FailureRatePercent int `mapstructure:"failure_rate_percent"`
+	// CooldownSeconds is the period during which the bidder is skipped once the breaker opens.
+	CooldownSeconds int `mapstructure:"cooldown_seconds"`
+	// MinTimeoutMillis is the shortest time to respond after which a timeout counts as a failure.
+	MinTimeoutMillis int `mapstructure:"min_timeout_ms"`
+}
+
+func (cfg *AdapterCircuitBreaker) validate(adapterName string, errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if cfg.WindowSeconds <= 0 {
+		errs = append(errs, fmt.Errorf("adapters.%s.circuit_breaker.window_seconds must be positive. Got %d", adapterName, cfg.WindowSeconds))
+	}
+	if cfg.MinRequests <= 0 {
+		errs = append(errs, fmt.Errorf("adapters.%s.circuit_breaker.min_requests must be positive. Got %d", adapterName, cfg.MinRequests))
+	}
+	if cfg.FailureRatePercent <= 0 || cfg.FailureRatePercent > 100 {
+		errs = append(errs, fmt.Errorf("adapters.%s.circuit_breaker.failure_rate_percent must be between 1 and 100. Got %d", adapterName, cfg.FailureRatePercent))
+	}
+	if cfg.CooldownSeconds <= 0 {
+		errs = append(errs, fmt.Errorf("adapters.%s.circuit_breaker.cooldown_seconds must be positive. Got %d", adapterName, cfg.CooldownSeconds))
+	}
+	if cfg.MinTimeoutMillis <= 0 {
+		errs = append(errs, fmt.Errorf("adapters.%s.circuit_breaker.min_timeout_ms must be positive. Got %d", adapterName, cfg.MinTimeoutMillis))
+	}
+	return errs
+}
+
 // validateAdapters validates adapter's endpoint and user sync URL
 func validateAdapters(adapterMap map[string]Adapter, errs []error) []error {
 	for adapterName, adapter := range adapterMap {
@@ -52,6 +105,10 @@ func validateAdapters(adapterMap map[string]Adapter, errs []error) []error {
 
 			// Verify that valid user_sync URLs are specified in the config
 			errs = validateAdapterUserSyncURL(adapter.UserSyncURL, adapterName, errs)
+
+			errs = adapter.CircuitBreaker.validate(adapterName, errs)
+
+			errs = validateAdapterEndpointCompression(adapter.EndpointCompression, adapterName, errs)
 		}
 	}
 	return errs
@@ -104,6 +161,20 @@ func validateAdapterEndpoint(endpoint string, adapterName string, errs []error)
 	return errs
 }
 
+// Supported values of the adapters endpoint compression
+const (
+	CompressionNone = ""
+	CompressionGZIP = "gzip"
+)
+
+// validateAdapterEndpointCompression makes sure that an adapter's endpoint compression is supported
+func validateAdapterEndpointCompression(compression string, adapterName string, errs []error) []error {
+	if compression != CompressionNone && compression != CompressionGZIP {
+		errs = append(errs, fmt.Errorf("adapters.%s.endpoint_compression must be empty or %q. Got %s", adapterName, CompressionGZIP, compression))
+	}
+	return errs
+}
+
 // validateAdapterUserSyncURL validates an adapter's user sync URL if it is set
 func validateAdapterUserSyncURL(userSyncURL string, adapterName string, errs []error) []error {
 	if userSyncURL != "" {
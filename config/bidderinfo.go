@@ -20,6 +20,7 @@ type BidderInfo struct {
 	ModifyingVastXmlAllowed bool              `yaml:"modifyingVastXmlAllowed"`
 	Debug                   *DebugInfo        `yaml:"debug,omitempty"`
 	GVLVendorID             uint16            `yaml:"gvlVendorID,omitempty"`
+	AliasOf                 string            `yaml:"-"` // copied from adapter config for hard aliases.
 }
 
 // MaintainerInfo is the support email address for a bidder.
@@ -53,14 +54,28 @@ func loadBidderInfo(r infoReader, adapterConfigs map[string]Adapter, bidders []s
 	infos := BidderInfos{}
 
 	for _, bidder := range bidders {
-		data, err := r.Read(bidder)
+		// Hard aliases don't have a yaml file of their own, they share the one of their core bidder
+		adapterConfig := adapterConfigs[strings.ToLower(bidder)]
+		infoName := bidder
+		if parent, found := openrtb_ext.NormalizeBidderName(adapterConfig.AliasOf); found {
+			infoName = string(parent)
+		}
+
+		data, err := r.Read(infoName)
 		if err != nil {
 			return nil, err
 		}
 
 		info := BidderInfo{}
 		if err := yaml.Unmarshal(data, &info); err != nil {
-			return nil, fmt.Errorf("error parsing yaml for bidder %s: %v", bidder, err)
+			return nil, fmt.Errorf("error parsing yaml for bidder %s: %v", infoName, err)
+		}
+
+		if infoName != bidder {
+			info.AliasOf = infoName
+			if adapterConfig.GVLVendorID != 0 {
+				info.GVLVendorID = adapterConfig.GVLVendorID
+			}
 		}
 
 		info.Enabled = isEnabledByConfig(adapterConfigs, bidder)
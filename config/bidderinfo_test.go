@@ -193,6 +193,38 @@ func (r fakeInfoReader) Read(bidder string) ([]byte, error) {
 	return []byte(r.content), r.err
 }
 
+func TestLoadBidderInfoHardAliases(t *testing.T) {
+	adapterConfigs := map[string]Adapter{
+		"appnexus":   {Disabled: true},
+		"whitelabel": {AliasOf: "appnexus", GVLVendorID: 7},
+		"otherlabel": {AliasOf: "AppNexus"},
+	}
+	r := fakeInfoReaderByBidder{"appnexus": testYAML}
+
+	infos, err := loadBidderInfo(r, adapterConfigs, []string{"appnexus", "whitelabel", "otherlabel"})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.False(t, infos["appnexus"].Enabled, "core bidder")
+	assert.Empty(t, infos["appnexus"].AliasOf, "core bidder")
+	assert.Equal(t, "appnexus", infos["whitelabel"].AliasOf, "alias with a vendor ID")
+	assert.True(t, infos["whitelabel"].Enabled, "alias with a vendor ID")
+	assert.Equal(t, uint16(7), infos["whitelabel"].GVLVendorID, "alias with a vendor ID")
+	assert.Equal(t, infos["appnexus"].Capabilities, infos["whitelabel"].Capabilities, "alias with a vendor ID")
+	assert.Equal(t, "appnexus", infos["otherlabel"].AliasOf, "alias without a vendor ID")
+	assert.Equal(t, uint16(42), infos["otherlabel"].GVLVendorID, "alias without a vendor ID")
+}
+
+type fakeInfoReaderByBidder map[string]string
+
+func (r fakeInfoReaderByBidder) Read(bidder string) ([]byte, error) {
+	if content, ok := r[bidder]; ok {
+		return []byte(content), nil
+	}
+	return nil, errors.New("no bidder info for " + bidder)
+}
+
 func TestToGVLVendorIDMap(t *testing.T) {
 	givenBidderInfos := BidderInfos{
 		"bidderA": BidderInfo{Enabled: true, GVLVendorID: 0},
@@ -54,6 +54,8 @@ type Configuration struct {
 	LMT                  LMT                `mapstructure:"lmt"`
 	CurrencyConverter    CurrencyConverter  `mapstructure:"currency_converter"`
 	DefReqConfig         DefReqConfig       `mapstructure:"default_request"`
+	PriceFloors          PriceFloors        `mapstructure:"price_floors"`
+	Hooks                Hooks              `mapstructure:"hooks"`
 
 	VideoStoredRequestRequired bool `mapstructure:"video_stored_request_required"`
 
@@ -82,6 +84,19 @@ type Configuration struct {
 	AutoGenSourceTID bool `mapstructure:"auto_gen_source_tid"`
 	//When true, new bid id will be generated in seatbid[].bid[].ext.prebid.bidid and used in event urls instead
 	GenerateBidID bool `mapstructure:"generate_bid_id"`
+	// AuctionResponseCache lets the AMP and video endpoints reuse the response of an identical auction
+	AuctionResponseCache AuctionResponseCache `mapstructure:"auction_response_cache"`
+	// AdaptiveBidderTimeouts gives each bidder a deadline based on its observed latency, instead of the whole auction timeout.
+	AdaptiveBidderTimeouts AdaptiveBidderTimeouts `mapstructure:"adaptive_bidder_timeouts"`
+	// Tracing exports the spans of the requests to an OpenTelemetry collector.
+	Tracing Tracing `mapstructure:"tracing"`
+	// StoredResponses lets the requests replace the bidders with stored auction and bid responses.
+	StoredResponses StoredResponses `mapstructure:"stored_responses"`
+	// GRPC serves the auction endpoint over gRPC, with protobuf requests and responses.
+	GRPC GRPC `mapstructure:"grpc"`
+
+	// reloads is shared by the configurations reloaded from this one
+	reloads *reloads
 }
 
 const MIN_COOKIE_SIZE_BYTES = 500
@@ -96,6 +111,8 @@ type HTTPClient struct {
 func (cfg *Configuration) validate(v *viper.Viper) []error {
 	var errs []error
 	errs = cfg.AuctionTimeouts.validate(errs)
+	errs = cfg.AdaptiveBidderTimeouts.validate(errs)
+	errs = cfg.Tracing.validate(errs)
 	errs = cfg.StoredRequests.validate(errs)
 	errs = cfg.StoredRequestsAMP.validate(errs)
 	errs = cfg.Accounts.validate(errs)
@@ -110,12 +127,48 @@ func (cfg *Configuration) validate(v *viper.Viper) []error {
 	errs = validateAdapters(cfg.Adapters, errs)
 	errs = cfg.Debug.validate(errs)
 	errs = cfg.ExtCacheURL.validate(errs)
+	errs = cfg.Hooks.validate(errs)
+	errs = cfg.AuctionResponseCache.validate(errs)
+	errs = cfg.GRPC.validate(errs)
+	errs = cfg.Analytics.validate(errs)
+	errs = cfg.AccountDefaults.validate("account_defaults", errs)
+	if cfg.AccountDefaults.Parent != "" {
+		errs = append(errs, fmt.Errorf("account_defaults.parent can't be set, since the defaults apply to the accounts without a parent. Got %s", cfg.AccountDefaults.Parent))
+	}
 	if cfg.AccountDefaults.Disabled {
 		glog.Warning(`With account_defaults.disabled=true, host-defined accounts must exist and have "disabled":false. All other requests will be rejected.`)
 	}
 	return errs
 }
 
+// Tracing configures the export of the request spans over OTLP/HTTP.
+type Tracing struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Endpoint is the host and port of the OTLP/HTTP collector, such as "localhost:4318".
+	Endpoint string `mapstructure:"endpoint"`
+	// Insecure sends the spans over plain HTTP instead of HTTPS.
+	Insecure    bool   `mapstructure:"insecure"`
+	ServiceName string `mapstructure:"service_name"`
+	// SampleRate is the share of the traces started by PBS which are recorded. The traces started upstream
+	// follow the sampling decision of their parent.
+	SampleRate float64 `mapstructure:"sample_rate"`
+	// PropagateToBidders sends the W3C traceparent header on the requests to the bidders.
+	PropagateToBidders bool `mapstructure:"propagate_to_bidders"`
+}
+
+func (cfg *Tracing) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if cfg.Endpoint == "" {
+		errs = append(errs, errors.New("tracing.endpoint must be set when tracing is enabled"))
+	}
+	if cfg.SampleRate < 0 || cfg.SampleRate > 1 {
+		errs = append(errs, fmt.Errorf("tracing.sample_rate must be between 0 and 1. Got %f", cfg.SampleRate))
+	}
+	return errs
+}
+
 type AuctionTimeouts struct {
 	// The default timeout is used if the user's request didn't define one. Use 0 if there's no default.
 	Default uint64 `mapstructure:"default"`
@@ -130,6 +183,44 @@ func (cfg *AuctionTimeouts) validate(errs []error) []error {
 	return errs
 }
 
+// AdaptiveBidderTimeouts configures the per-bidder timeout budgets. Once enough of its responses were observed,
+// a bidder's deadline is its latency percentile plus some headroom, capped by the auction deadline.
+type AdaptiveBidderTimeouts struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Percentile of the bidder's observed latencies used as the basis of its deadline.
+	Percentile int `mapstructure:"percentile"`
+	// HeadroomPercent is added on top of the latency percentile.
+	HeadroomPercent int `mapstructure:"headroom_percent"`
+	// MinTimeoutMillis is the shortest deadline given to a bidder.
+	MinTimeoutMillis int `mapstructure:"min_timeout_ms"`
+	// SampleSize is the number of the most recent responses of each bidder which are kept.
+	SampleSize int `mapstructure:"sample_size"`
+	// MinSamples is the number of responses needed before the bidder's deadline is budgeted.
+	MinSamples int `mapstructure:"min_samples"`
+}
+
+func (cfg *AdaptiveBidderTimeouts) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if cfg.Percentile <= 0 || cfg.Percentile > 100 {
+		errs = append(errs, fmt.Errorf("adaptive_bidder_timeouts.percentile must be between 1 and 100. Got %d", cfg.Percentile))
+	}
+	if cfg.HeadroomPercent < 0 {
+		errs = append(errs, fmt.Errorf("adaptive_bidder_timeouts.headroom_percent must be >= 0. Got %d", cfg.HeadroomPercent))
+	}
+	if cfg.MinTimeoutMillis < 0 {
+		errs = append(errs, fmt.Errorf("adaptive_bidder_timeouts.min_timeout_ms must be >= 0. Got %d", cfg.MinTimeoutMillis))
+	}
+	if cfg.SampleSize <= 0 {
+		errs = append(errs, fmt.Errorf("adaptive_bidder_timeouts.sample_size must be positive. Got %d", cfg.SampleSize))
+	}
+	if cfg.MinSamples <= 0 || cfg.MinSamples > cfg.SampleSize {
+		errs = append(errs, fmt.Errorf("adaptive_bidder_timeouts.min_samples must be between 1 and sample_size. Got %d", cfg.MinSamples))
+	}
+	return errs
+}
+
 func (data *ExternalCache) validate(errs []error) []error {
 	if data.Host == "" && data.Path == "" {
 		// Both host and path can be blank. No further validation needed
@@ -267,8 +358,137 @@ type LMT struct {
 }
 
 type Analytics struct {
-	File     FileLogs `mapstructure:"file"`
-	Pubstack Pubstack `mapstructure:"pubstack"`
+	File     FileLogs       `mapstructure:"file"`
+	Pubstack Pubstack       `mapstructure:"pubstack"`
+	Kafka    KafkaAnalytics `mapstructure:"kafka"`
+	// Modules selects the events sent to each analytics module, keyed by module name (file, pubstack or kafka).
+	// The accounts can override them.
+	Modules map[string]AnalyticsModuleFilter `mapstructure:"modules"`
+	Workers AnalyticsWorkers                 `mapstructure:"workers"`
+}
+
+// The endpoint types the analytics events can be filtered by
+const (
+	AnalyticsEndpointAuction    = "auction"
+	AnalyticsEndpointAmp        = "amp"
+	AnalyticsEndpointVideo      = "video"
+	AnalyticsEndpointCookieSync = "cookie_sync"
+	AnalyticsEndpointSetUID     = "setuid"
+	AnalyticsEndpointEvent      = "event"
+)
+
+var analyticsEndpoints = map[string]bool{
+	AnalyticsEndpointAuction:    true,
+	AnalyticsEndpointAmp:        true,
+	AnalyticsEndpointVideo:      true,
+	AnalyticsEndpointCookieSync: true,
+	AnalyticsEndpointSetUID:     true,
+	AnalyticsEndpointEvent:      true,
+}
+
+// The names of the analytics modules, which key their filters
+const (
+	AnalyticsModuleFile     = "file"
+	AnalyticsModulePubstack = "pubstack"
+	AnalyticsModuleKafka    = "kafka"
+)
+
+var analyticsModules = map[string]bool{
+	AnalyticsModuleFile:     true,
+	AnalyticsModulePubstack: true,
+	AnalyticsModuleKafka:    true,
+}
+
+// validateAnalyticsModules checks the filters of the analytics modules found at the path, keyed by module name.
+func validateAnalyticsModules(path string, modules map[string]AnalyticsModuleFilter, errs []error) []error {
+	for name, filter := range modules {
+		if !analyticsModules[name] {
+			errs = append(errs, fmt.Errorf("%s contains an unknown analytics module: %s", path, name))
+			continue
+		}
+		errs = filter.validate(path+"."+name, errs)
+	}
+	return errs
+}
+
+// AnalyticsModuleFilter selects the events sent to an analytics module. The fields left unset send all the events.
+type AnalyticsModuleFilter struct {
+	// Enabled turns the module off when false.
+	Enabled *bool `mapstructure:"enabled" json:"enabled,omitempty"`
+	// SampleRate is the share of the events sent to the module, from 0 to 1.
+	SampleRate *float64 `mapstructure:"sample_rate" json:"sample_rate,omitempty"`
+	// Endpoints lists the types of endpoint whose events are sent to the module.
+	Endpoints []string `mapstructure:"endpoints" json:"endpoints,omitempty"`
+}
+
+// Merge returns the filter with the fields set in the override replacing its own.
+func (f AnalyticsModuleFilter) Merge(override AnalyticsModuleFilter) AnalyticsModuleFilter {
+	if override.Enabled != nil {
+		f.Enabled = override.Enabled
+	}
+	if override.SampleRate != nil {
+		f.SampleRate = override.SampleRate
+	}
+	if override.Endpoints != nil {
+		f.Endpoints = override.Endpoints
+	}
+	return f
+}
+
+// Allows returns whether the module receives the events of the endpoint type, before any sampling.
+func (f AnalyticsModuleFilter) Allows(endpoint string) bool {
+	if f.Enabled != nil && !*f.Enabled {
+		return false
+	}
+	if f.Endpoints == nil {
+		return true
+	}
+	for _, allowed := range f.Endpoints {
+		if allowed == endpoint {
+			return true
+		}
+	}
+	return false
+}
+
+func (f AnalyticsModuleFilter) validate(path string, errs []error) []error {
+	if f.SampleRate != nil && (*f.SampleRate < 0 || *f.SampleRate > 1) {
+		errs = append(errs, fmt.Errorf("%s.sample_rate must be in the range [0, 1]. Got %g", path, *f.SampleRate))
+	}
+	for _, endpoint := range f.Endpoints {
+		if !analyticsEndpoints[endpoint] {
+			errs = append(errs, fmt.Errorf("%s.endpoints contains an unknown endpoint type: %s", path, endpoint))
+		}
+	}
+	return errs
+}
+
+// AnalyticsWorkers sizes the pool of workers which send the events to each analytics module, so that a slow
+// module doesn't delay the requests. A Count of 0 sends the events from the request goroutines instead.
+type AnalyticsWorkers struct {
+	Count int `mapstructure:"count"`
+	// QueueSize is the number of events waiting for a worker beyond which the events are dropped.
+	QueueSize int `mapstructure:"queue_size"`
+}
+
+func (cfg *Analytics) validate(errs []error) []error {
+	errs = cfg.Kafka.validate(errs)
+	errs = validateAnalyticsModules("analytics.modules", cfg.Modules, errs)
+	if cfg.Workers.Count < 0 {
+		errs = append(errs, fmt.Errorf("analytics.workers.count must be >= 0. Got %d", cfg.Workers.Count))
+	}
+	if cfg.Workers.Count > 0 && cfg.Workers.QueueSize <= 0 {
+		errs = append(errs, fmt.Errorf("analytics.workers.queue_size must be positive. Got %d", cfg.Workers.QueueSize))
+	}
+	return errs
+}
+
+// PriceFloors configures server-side price floor enforcement. Floors are only applied for accounts
+// which have price_floors.enabled set, either directly or through account_defaults.
+type PriceFloors struct {
+	Enabled bool `mapstructure:"enabled"`
+	// RulesFile is a local JSON file which maps account IDs to their stored floor rules.
+	RulesFile string `mapstructure:"rules_file"`
 }
 
 type CurrencyConverter struct {
@@ -303,6 +523,82 @@ type PubstackBuffer struct {
 	Timeout    string `mapstructure:"timeout"`
 }
 
+// KafkaAnalytics configures the analytics module which publishes the events to Kafka. Each event type
+// is published on its own topic, named after the topic prefix and the event type, e.g. "prebid.auction".
+type KafkaAnalytics struct {
+	Enabled     bool     `mapstructure:"enabled"`
+	Brokers     []string `mapstructure:"brokers,flow"`
+	ClientID    string   `mapstructure:"client_id"`
+	TopicPrefix string   `mapstructure:"topic_prefix"`
+	// BatchSize is the number of events which triggers sending a batch to the brokers.
+	BatchSize int `mapstructure:"batch_size"`
+	// FlushIntervalMillis is the longest time an event waits to be sent in a batch.
+	FlushIntervalMillis int `mapstructure:"flush_interval_ms"`
+	// BufferSize is the number of events waiting to be sent. Events which don't fit in the buffer are dropped.
+	BufferSize int `mapstructure:"buffer_size"`
+}
+
+func (cfg *KafkaAnalytics) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if len(cfg.Brokers) == 0 {
+		errs = append(errs, errors.New("analytics.kafka.brokers must not be empty"))
+	}
+	if cfg.BatchSize <= 0 {
+		errs = append(errs, fmt.Errorf("analytics.kafka.batch_size must be positive. Got %d", cfg.BatchSize))
+	}
+	if cfg.FlushIntervalMillis <= 0 {
+		errs = append(errs, fmt.Errorf("analytics.kafka.flush_interval_ms must be positive. Got %d", cfg.FlushIntervalMillis))
+	}
+	if cfg.BufferSize <= 0 {
+		errs = append(errs, fmt.Errorf("analytics.kafka.buffer_size must be positive. Got %d", cfg.BufferSize))
+	}
+	return errs
+}
+
+// AuctionResponseCache configures the in-memory cache used by the AMP and video endpoints to reuse
+// the response of an identical auction (ignoring user and device identifiers) run within a short window.
+// A reused response keeps the targeting of the original auction, with fresh cache IDs, bid IDs and event URLs.
+type AuctionResponseCache struct {
+	Enabled    bool `mapstructure:"enabled"`
+	TTLSeconds int  `mapstructure:"ttl_seconds"`
+	SizeBytes  int  `mapstructure:"size_bytes"`
+}
+
+// StoredResponses configures imp.ext.prebid.storedauctionresponse and imp.ext.prebid.storedbidresponse. They are
+// meant for the hosts used for QA and demos, since any request could otherwise replace the bids of the bidders.
+type StoredResponses struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+func (cfg *AuctionResponseCache) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if cfg.TTLSeconds <= 0 {
+		errs = append(errs, fmt.Errorf("auction_response_cache.ttl_seconds must be positive. Got %d", cfg.TTLSeconds))
+	}
+	if cfg.SizeBytes <= 0 {
+		errs = append(errs, fmt.Errorf("auction_response_cache.size_bytes must be positive. Got %d", cfg.SizeBytes))
+	}
+	return errs
+}
+
+// GRPC configures the gRPC server of the auction endpoint, which accepts OpenRTB requests encoded
+// with the IAB protobuf messages.
+type GRPC struct {
+	Enabled bool `mapstructure:"enabled"`
+	Port    int  `mapstructure:"port"`
+}
+
+func (cfg *GRPC) validate(errs []error) []error {
+	if cfg.Enabled && cfg.Port <= 0 {
+		errs = append(errs, fmt.Errorf("grpc.port must be positive when grpc is enabled. Got %d", cfg.Port))
+	}
+	return errs
+}
+
 type VTrack struct {
 	TimeoutMS          int64 `mapstructure:"timeout_ms"`
 	AllowUnknownBidder bool  `mapstructure:"allow_unknown_bidder"`
@@ -370,12 +666,63 @@ type PrometheusMetrics struct {
 	Namespace        string `mapstructure:"namespace"`
 	Subsystem        string `mapstructure:"subsystem"`
 	TimeoutMillisRaw int    `mapstructure:"timeout_ms"`
+	// Buckets overrides the upper bounds of the histogram buckets, by metric family.
+	Buckets PrometheusBuckets `mapstructure:"buckets"`
+	// Accounts is the allow-list of the accounts labeled in the account metrics. The requests of the other
+	// accounts are labeled "other". Every account is labeled when the list is empty.
+	Accounts []string `mapstructure:"accounts"`
+}
+
+// PrometheusBuckets holds the upper bounds of the histogram buckets of each metric family, in increasing order.
+// The default buckets of the family are used when they're empty.
+type PrometheusBuckets struct {
+	// RequestTime is for the time to resolve the requests, in seconds.
+	RequestTime []float64 `mapstructure:"request_time"`
+	// RequestQueueTime is for the time the requests waited in the queue, in seconds.
+	RequestQueueTime []float64 `mapstructure:"request_queue_time"`
+	// AdapterRequestTime is for the time to resolve the requests to the adapters, in seconds.
+	AdapterRequestTime []float64 `mapstructure:"adapter_request_time"`
+	// ConnectionTime is for the DNS lookups, TLS handshakes and waits for the adapter connections, in seconds.
+	ConnectionTime []float64 `mapstructure:"connection_time"`
+	// StoredDataFetchTime is for the time to fetch the stored data, in seconds.
+	StoredDataFetchTime []float64 `mapstructure:"stored_data_fetch_time"`
+	// PrebidCacheWriteTime is for the time to write to Prebid Cache, in seconds.
+	PrebidCacheWriteTime []float64 `mapstructure:"prebid_cache_write_time"`
+	// Price is for the prices of the bids, in CPM multiplied by 1000.
+	Price []float64 `mapstructure:"price"`
+	// RequestSize is for the size of the request bodies sent to the adapters, in bytes.
+	RequestSize []float64 `mapstructure:"request_size"`
 }
 
 func (cfg *PrometheusMetrics) validate(errs []error) []error {
 	if cfg.Port > 0 && cfg.TimeoutMillisRaw <= 0 {
 		errs = append(errs, fmt.Errorf("metrics.prometheus.timeout_ms must be positive if metrics.prometheus.port is defined. Got timeout=%d and port=%d", cfg.TimeoutMillisRaw, cfg.Port))
 	}
+	return cfg.Buckets.validate(errs)
+}
+
+func (cfg *PrometheusBuckets) validate(errs []error) []error {
+	families := []struct {
+		name    string
+		buckets []float64
+	}{
+		{"request_time", cfg.RequestTime},
+		{"request_queue_time", cfg.RequestQueueTime},
+		{"adapter_request_time", cfg.AdapterRequestTime},
+		{"connection_time", cfg.ConnectionTime},
+		{"stored_data_fetch_time", cfg.StoredDataFetchTime},
+		{"prebid_cache_write_time", cfg.PrebidCacheWriteTime},
+		{"price", cfg.Price},
+		{"request_size", cfg.RequestSize},
+	}
+	for _, family := range families {
+		for i := 1; i < len(family.buckets); i++ {
+			if family.buckets[i] <= family.buckets[i-1] {
+				errs = append(errs, fmt.Errorf("metrics.prometheus.buckets.%s must be in increasing order. Got %v", family.name, family.buckets))
+				break
+			}
+		}
+	}
 	return errs
 }
 
@@ -474,6 +821,7 @@ func New(v *viper.Viper) (*Configuration, error) {
 		return nil, fmt.Errorf("viper failed to unmarshal app config: %v", err)
 	}
 	c.setDerivedDefaults()
+	c.reloads = &reloads{}
 
 	if err := c.RequestValidation.Parse(); err != nil {
 		return nil, err
@@ -732,6 +1080,15 @@ func SetupViper(v *viper.Viper, filename string) {
 	v.SetDefault("metrics.prometheus.namespace", "")
 	v.SetDefault("metrics.prometheus.subsystem", "")
 	v.SetDefault("metrics.prometheus.timeout_ms", 10000)
+	v.SetDefault("metrics.prometheus.buckets.request_time", []float64{})
+	v.SetDefault("metrics.prometheus.buckets.request_queue_time", []float64{})
+	v.SetDefault("metrics.prometheus.buckets.adapter_request_time", []float64{})
+	v.SetDefault("metrics.prometheus.buckets.connection_time", []float64{})
+	v.SetDefault("metrics.prometheus.buckets.stored_data_fetch_time", []float64{})
+	v.SetDefault("metrics.prometheus.buckets.prebid_cache_write_time", []float64{})
+	v.SetDefault("metrics.prometheus.buckets.price", []float64{})
+	v.SetDefault("metrics.prometheus.buckets.request_size", []float64{})
+	v.SetDefault("metrics.prometheus.accounts", []string{})
 	v.SetDefault("datacache.type", "dummy")
 	v.SetDefault("datacache.filename", "")
 	v.SetDefault("datacache.cache_size", 0)
@@ -739,6 +1096,12 @@ func SetupViper(v *viper.Viper, filename string) {
 	v.SetDefault("category_mapping.filesystem.enabled", true)
 	v.SetDefault("category_mapping.filesystem.directorypath", "./static/category-mapping")
 	v.SetDefault("category_mapping.http.endpoint", "")
+	v.SetDefault("category_mapping.redis.connection.address", "")
+	v.SetDefault("category_mapping.redis.connection.password", "")
+	v.SetDefault("category_mapping.redis.connection.db", 0)
+	v.SetDefault("category_mapping.redis.connection.timeout_ms", 500)
+	v.SetDefault("category_mapping.redis.connection.max_idle_connections", 10)
+	v.SetDefault("category_mapping.redis.key_prefixes.categories", "category:")
 	v.SetDefault("stored_requests.filesystem.enabled", false)
 	v.SetDefault("stored_requests.filesystem.directorypath", "./stored_requests/data/by_id")
 	v.SetDefault("stored_requests.directorypath", "./stored_requests/data/by_id")
@@ -749,6 +1112,7 @@ func SetupViper(v *viper.Viper, filename string) {
 	v.SetDefault("stored_requests.postgres.connection.password", "")
 	v.SetDefault("stored_requests.postgres.fetcher.query", "")
 	v.SetDefault("stored_requests.postgres.fetcher.amp_query", "")
+	v.SetDefault("stored_requests.postgres.fetcher.responses_query", "")
 	v.SetDefault("stored_requests.postgres.initialize_caches.timeout_ms", 0)
 	v.SetDefault("stored_requests.postgres.initialize_caches.query", "")
 	v.SetDefault("stored_requests.postgres.initialize_caches.amp_query", "")
@@ -758,6 +1122,17 @@ func SetupViper(v *viper.Viper, filename string) {
 	v.SetDefault("stored_requests.postgres.poll_for_updates.amp_query", "")
 	v.SetDefault("stored_requests.http.endpoint", "")
 	v.SetDefault("stored_requests.http.amp_endpoint", "")
+	v.SetDefault("stored_requests.redis.connection.address", "")
+	v.SetDefault("stored_requests.redis.connection.password", "")
+	v.SetDefault("stored_requests.redis.connection.db", 0)
+	v.SetDefault("stored_requests.redis.connection.timeout_ms", 500)
+	v.SetDefault("stored_requests.redis.connection.max_idle_connections", 10)
+	v.SetDefault("stored_requests.redis.key_prefixes.requests", "stored_request:")
+	v.SetDefault("stored_requests.redis.key_prefixes.amp_requests", "stored_amp_request:")
+	v.SetDefault("stored_requests.redis.key_prefixes.imps", "stored_imp:")
+	v.SetDefault("stored_requests.redis.key_prefixes.responses", "stored_response:")
+	v.SetDefault("stored_requests.redis.events.enabled", false)
+	v.SetDefault("stored_requests.redis.events.channel", "")
 	v.SetDefault("stored_requests.in_memory_cache.type", "none")
 	v.SetDefault("stored_requests.in_memory_cache.ttl_seconds", 0)
 	v.SetDefault("stored_requests.in_memory_cache.request_cache_size_bytes", 0)
@@ -783,6 +1158,15 @@ func SetupViper(v *viper.Viper, filename string) {
 	v.SetDefault("stored_video_req.postgres.poll_for_updates.timeout_ms", 0)
 	v.SetDefault("stored_video_req.postgres.poll_for_updates.query", "")
 	v.SetDefault("stored_video_req.http.endpoint", "")
+	v.SetDefault("stored_video_req.redis.connection.address", "")
+	v.SetDefault("stored_video_req.redis.connection.password", "")
+	v.SetDefault("stored_video_req.redis.connection.db", 0)
+	v.SetDefault("stored_video_req.redis.connection.timeout_ms", 500)
+	v.SetDefault("stored_video_req.redis.connection.max_idle_connections", 10)
+	v.SetDefault("stored_video_req.redis.key_prefixes.requests", "stored_request:")
+	v.SetDefault("stored_video_req.redis.key_prefixes.imps", "stored_imp:")
+	v.SetDefault("stored_video_req.redis.events.enabled", false)
+	v.SetDefault("stored_video_req.redis.events.channel", "")
 	v.SetDefault("stored_video_req.in_memory_cache.type", "none")
 	v.SetDefault("stored_video_req.in_memory_cache.ttl_seconds", 0)
 	v.SetDefault("stored_video_req.in_memory_cache.request_cache_size_bytes", 0)
@@ -802,6 +1186,14 @@ func SetupViper(v *viper.Viper, filename string) {
 	v.SetDefault("accounts.filesystem.enabled", false)
 	v.SetDefault("accounts.filesystem.directorypath", "./stored_requests/data/by_id")
 	v.SetDefault("accounts.in_memory_cache.type", "none")
+	v.SetDefault("accounts.redis.connection.address", "")
+	v.SetDefault("accounts.redis.connection.password", "")
+	v.SetDefault("accounts.redis.connection.db", 0)
+	v.SetDefault("accounts.redis.connection.timeout_ms", 500)
+	v.SetDefault("accounts.redis.connection.max_idle_connections", 10)
+	v.SetDefault("accounts.redis.key_prefixes.accounts", "account:")
+	v.SetDefault("accounts.redis.events.enabled", false)
+	v.SetDefault("accounts.redis.events.channel", "")
 
 	for _, bidder := range openrtb_ext.CoreBidderNames() {
 		setBidderDefaults(v, strings.ToLower(string(bidder)))
@@ -947,6 +1339,15 @@ func SetupViper(v *viper.Viper, filename string) {
 	v.SetDefault("analytics.pubstack.buffers.size", "2MB")
 	v.SetDefault("analytics.pubstack.buffers.count", 100)
 	v.SetDefault("analytics.pubstack.buffers.timeout", "900s")
+	v.SetDefault("analytics.kafka.enabled", false)
+	v.SetDefault("analytics.kafka.brokers", []string{})
+	v.SetDefault("analytics.kafka.client_id", "prebid-server")
+	v.SetDefault("analytics.kafka.topic_prefix", "prebid.")
+	v.SetDefault("analytics.kafka.batch_size", 100)
+	v.SetDefault("analytics.kafka.flush_interval_ms", 500)
+	v.SetDefault("analytics.kafka.buffer_size", 10000)
+	v.SetDefault("analytics.workers.count", 4)
+	v.SetDefault("analytics.workers.queue_size", 1000)
 	v.SetDefault("amp_timeout_adjustment_ms", 0)
 	v.BindEnv("gdpr.default_value")
 	v.SetDefault("gdpr.enabled", true)
@@ -973,14 +1374,40 @@ func SetupViper(v *viper.Viper, filename string) {
 	v.SetDefault("default_request.type", "")
 	v.SetDefault("default_request.file.name", "")
 	v.SetDefault("default_request.alias_info", false)
+	v.SetDefault("price_floors.enabled", false)
+	v.SetDefault("price_floors.rules_file", "")
+	v.SetDefault("hooks.enabled", false)
 	v.SetDefault("blacklisted_apps", []string{""})
 	v.SetDefault("blacklisted_accts", []string{""})
 	v.SetDefault("account_required", false)
 	v.SetDefault("account_defaults.disabled", false)
 	v.SetDefault("account_defaults.debug_allow", true)
+	v.SetDefault("account_defaults.price_floors.enabled", false)
+	v.SetDefault("account_defaults.price_floors.enforce_floors_rate", 100)
+	v.SetDefault("account_defaults.price_floors.enforce_deal_floors", false)
+	v.SetDefault("account_defaults.bid_adjustments.allow_request_override", false)
+	v.SetDefault("account_defaults.bidders.allow_request_alias_override", false)
 	v.SetDefault("certificates_file", "")
 	v.SetDefault("auto_gen_source_tid", true)
 	v.SetDefault("generate_bid_id", false)
+	v.SetDefault("adaptive_bidder_timeouts.enabled", false)
+	v.SetDefault("adaptive_bidder_timeouts.percentile", 95)
+	v.SetDefault("adaptive_bidder_timeouts.headroom_percent", 20)
+	v.SetDefault("adaptive_bidder_timeouts.min_timeout_ms", 50)
+	v.SetDefault("adaptive_bidder_timeouts.sample_size", 500)
+	v.SetDefault("adaptive_bidder_timeouts.min_samples", 100)
+	v.SetDefault("tracing.enabled", false)
+	v.SetDefault("tracing.endpoint", "localhost:4318")
+	v.SetDefault("tracing.insecure", false)
+	v.SetDefault("tracing.service_name", "prebid-server")
+	v.SetDefault("tracing.sample_rate", 1.0)
+	v.SetDefault("tracing.propagate_to_bidders", false)
+	v.SetDefault("stored_responses.enabled", false)
+	v.SetDefault("grpc.enabled", false)
+	v.SetDefault("grpc.port", 8002)
+	v.SetDefault("auction_response_cache.enabled", false)
+	v.SetDefault("auction_response_cache.ttl_seconds", 2)
+	v.SetDefault("auction_response_cache.size_bytes", 10*1024*1024)
 
 	v.SetDefault("request_timeout_headers.request_time_in_queue", "")
 	v.SetDefault("request_timeout_headers.request_timeout_in_queue", "")
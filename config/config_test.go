@@ -607,6 +607,36 @@ func TestInvalidAdapterUserSyncURLConfig(t *testing.T) {
 	assert.Error(t, err, "invalid user_sync URL in config should return an error")
 }
 
+func TestInvalidAdapterCircuitBreakerConfig(t *testing.T) {
+	cfg, v := newDefaultConfig(t)
+	adapter := cfg.Adapters["appnexus"]
+	adapter.CircuitBreaker = AdapterCircuitBreaker{
+		Enabled:            true,
+		WindowSeconds:      10,
+		MinRequests:        0,
+		FailureRatePercent: 101,
+		CooldownSeconds:    30,
+		MinTimeoutMillis:   0,
+	}
+	cfg.Adapters["appnexus"] = adapter
+
+	errs := cfg.validate(v)
+	assert.ElementsMatch(t, []error{
+		errors.New("adapters.appnexus.circuit_breaker.min_requests must be positive. Got 0"),
+		errors.New("adapters.appnexus.circuit_breaker.failure_rate_percent must be between 1 and 100. Got 101"),
+		errors.New("adapters.appnexus.circuit_breaker.min_timeout_ms must be positive. Got 0"),
+	}, errs)
+}
+
+func TestInvalidAdapterEndpointCompression(t *testing.T) {
+	cfg, v := newDefaultConfig(t)
+	adapter := cfg.Adapters["appnexus"]
+	adapter.EndpointCompression = "brotli"
+	cfg.Adapters["appnexus"] = adapter
+
+	assertOneError(t, cfg.validate(v), `adapters.appnexus.endpoint_compression must be empty or "gzip". Got brotli`)
+}
+
 func TestNegativeRequestSize(t *testing.T) {
 	cfg, v := newDefaultConfig(t)
 	cfg.MaxRequestSize = -1
@@ -620,6 +650,32 @@ func TestNegativePrometheusTimeout(t *testing.T) {
 	assertOneError(t, cfg.validate(v), "metrics.prometheus.timeout_ms must be positive if metrics.prometheus.port is defined. Got timeout=0 and port=8001")
 }
 
+func TestPrometheusBucketsNotIncreasing(t *testing.T) {
+	cfg, v := newDefaultConfig(t)
+	cfg.Metrics.Prometheus.Buckets.RequestTime = []float64{0.05, 0.1, 0.1, 0.2}
+	assertOneError(t, cfg.validate(v), "metrics.prometheus.buckets.request_time must be in increasing order. Got [0.05 0.1 0.1 0.2]")
+}
+
+func TestPrometheusMetricsConfig(t *testing.T) {
+	v := viper.New()
+	SetupViper(v, "")
+	v.Set("gdpr.default_value", "0")
+	v.SetConfigType("yaml")
+	v.ReadConfig(bytes.NewBuffer([]byte(`
+metrics:
+  prometheus:
+    buckets:
+      request_time: [0.05, 0.075, 0.1, 0.15, 0.2]
+    accounts: ["account-1", "account-2"]
+`)))
+	cfg, err := New(v)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []float64{0.05, 0.075, 0.1, 0.15, 0.2}, cfg.Metrics.Prometheus.Buckets.RequestTime)
+	assert.Empty(t, cfg.Metrics.Prometheus.Buckets.AdapterRequestTime)
+	assert.Equal(t, []string{"account-1", "account-2"}, cfg.Metrics.Prometheus.Accounts)
+}
+
 func TestInvalidHostVendorID(t *testing.T) {
 	tests := []struct {
 		description  string
@@ -783,6 +839,323 @@ func TestValidateAccountsConfigRestrictions(t *testing.T) {
 	assert.Contains(t, errs, errors.New("accounts.postgres: retrieving accounts via postgres not available, use accounts.files"))
 }
 
+func TestValidateHooks(t *testing.T) {
+	cfg, v := newDefaultConfig(t)
+	cfg.Hooks.HostExecutionPlan = HookExecutionPlan{Endpoints: map[string]HookEndpointPlan{
+		"/openrtb2/auction": {Stages: map[string]HookStagePlan{
+			"entrypoint": {Groups: []HookExecutionGroup{
+				{Timeout: 0, HookSequence: []HookID{{ModuleCode: "vendor.module", HookImplCode: "code"}}},
+			}},
+		}},
+	}}
+	cfg.AccountDefaults.Hooks.ExecutionPlan = HookExecutionPlan{Endpoints: map[string]HookEndpointPlan{
+		"/openrtb2/auction": {Stages: map[string]HookStagePlan{
+			"bidder-request": {Groups: []HookExecutionGroup{
+				{Timeout: 10, HookSequence: []HookID{{ModuleCode: "vendor.module"}}},
+			}},
+		}},
+	}}
+
+	errs := cfg.validate(v)
+	assert.ElementsMatch(t, []error{
+		errors.New("hooks.host_execution_plan.endpoints./openrtb2/auction.stages.entrypoint.groups[0].timeout must be positive. Got 0"),
+		errors.New("account_defaults.hooks.execution_plan.endpoints./openrtb2/auction.stages.bidder-request.groups[0].hook_sequence[0] must define module_code and hook_impl_code"),
+	}, errs)
+}
+
+func TestValidateAccountBidAdjustments(t *testing.T) {
+	cfg, v := newDefaultConfig(t)
+	cfg.AccountDefaults.BidAdjustments.Factors = map[string]float64{"appnexus": 0.9, "rubicon": 0}
+
+	errs := cfg.validate(v)
+	assert.Equal(t, []error{errors.New("account_defaults.bid_adjustments.factors.rubicon must be a positive number. Got 0.000000")}, errs)
+}
+
+func TestValidateAccountDefaultsParent(t *testing.T) {
+	cfg, v := newDefaultConfig(t)
+	cfg.AccountDefaults.Parent = "network"
+
+	errs := cfg.validate(v)
+	assert.Equal(t, []error{errors.New("account_defaults.parent can't be set, since the defaults apply to the accounts without a parent. Got network")}, errs)
+}
+
+func TestValidateAdaptiveBidderTimeouts(t *testing.T) {
+	testCases := []struct {
+		description  string
+		cfg          AdaptiveBidderTimeouts
+		expectedErrs []error
+	}{
+		{
+			description: "Disabled - Invalid values ignored",
+			cfg:         AdaptiveBidderTimeouts{Enabled: false, Percentile: 0},
+		},
+		{
+			description: "Enabled - Valid",
+			cfg:         AdaptiveBidderTimeouts{Enabled: true, Percentile: 95, HeadroomPercent: 20, MinTimeoutMillis: 50, SampleSize: 500, MinSamples: 100},
+		},
+		{
+			description: "Enabled - Invalid",
+			cfg:         AdaptiveBidderTimeouts{Enabled: true, Percentile: 101, HeadroomPercent: -1, MinTimeoutMillis: -1, SampleSize: 10, MinSamples: 20},
+			expectedErrs: []error{
+				errors.New("adaptive_bidder_timeouts.percentile must be between 1 and 100. Got 101"),
+				errors.New("adaptive_bidder_timeouts.headroom_percent must be >= 0. Got -1"),
+				errors.New("adaptive_bidder_timeouts.min_timeout_ms must be >= 0. Got -1"),
+				errors.New("adaptive_bidder_timeouts.min_samples must be between 1 and sample_size. Got 20"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := test.cfg.validate(nil)
+		assert.Equal(t, test.expectedErrs, errs, test.description)
+	}
+}
+
+func TestValidateTracing(t *testing.T) {
+	testCases := []struct {
+		description  string
+		cfg          Tracing
+		expectedErrs []error
+	}{
+		{
+			description: "Disabled - Invalid values ignored",
+			cfg:         Tracing{Enabled: false, SampleRate: 2},
+		},
+		{
+			description: "Enabled - Valid",
+			cfg:         Tracing{Enabled: true, Endpoint: "localhost:4318", SampleRate: 0.5},
+		},
+		{
+			description: "Enabled - Invalid",
+			cfg:         Tracing{Enabled: true, Endpoint: "", SampleRate: -0.5},
+			expectedErrs: []error{
+				errors.New("tracing.endpoint must be set when tracing is enabled"),
+				errors.New("tracing.sample_rate must be between 0 and 1. Got -0.500000"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := test.cfg.validate(nil)
+		assert.Equal(t, test.expectedErrs, errs, test.description)
+	}
+}
+
+func TestValidateAuctionResponseCache(t *testing.T) {
+	testCases := []struct {
+		description  string
+		cfg          AuctionResponseCache
+		expectedErrs []error
+	}{
+		{
+			description: "Disabled - Invalid values ignored",
+			cfg:         AuctionResponseCache{Enabled: false, TTLSeconds: 0, SizeBytes: 0},
+		},
+		{
+			description: "Enabled - Valid",
+			cfg:         AuctionResponseCache{Enabled: true, TTLSeconds: 2, SizeBytes: 1024},
+		},
+		{
+			description: "Enabled - Invalid",
+			cfg:         AuctionResponseCache{Enabled: true, TTLSeconds: 0, SizeBytes: -1},
+			expectedErrs: []error{
+				errors.New("auction_response_cache.ttl_seconds must be positive. Got 0"),
+				errors.New("auction_response_cache.size_bytes must be positive. Got -1"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := test.cfg.validate(nil)
+		assert.Equal(t, test.expectedErrs, errs, test.description)
+	}
+}
+
+func TestValidateGRPC(t *testing.T) {
+	testCases := []struct {
+		description  string
+		cfg          GRPC
+		expectedErrs []error
+	}{
+		{
+			description: "Disabled - Invalid port ignored",
+			cfg:         GRPC{Enabled: false, Port: 0},
+		},
+		{
+			description: "Enabled - Valid",
+			cfg:         GRPC{Enabled: true, Port: 8002},
+		},
+		{
+			description: "Enabled - Invalid port",
+			cfg:         GRPC{Enabled: true, Port: 0},
+			expectedErrs: []error{
+				errors.New("grpc.port must be positive when grpc is enabled. Got 0"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := test.cfg.validate(nil)
+		assert.Equal(t, test.expectedErrs, errs, test.description)
+	}
+}
+
+func TestValidateKafkaAnalytics(t *testing.T) {
+	testCases := []struct {
+		description  string
+		cfg          KafkaAnalytics
+		expectedErrs []error
+	}{
+		{
+			description: "Disabled - Invalid values ignored",
+			cfg:         KafkaAnalytics{Enabled: false},
+		},
+		{
+			description: "Enabled - Valid",
+			cfg:         KafkaAnalytics{Enabled: true, Brokers: []string{"localhost:9092"}, BatchSize: 100, FlushIntervalMillis: 500, BufferSize: 1000},
+		},
+		{
+			description: "Enabled - Invalid",
+			cfg:         KafkaAnalytics{Enabled: true, BatchSize: 0, FlushIntervalMillis: -1, BufferSize: 0},
+			expectedErrs: []error{
+				errors.New("analytics.kafka.brokers must not be empty"),
+				errors.New("analytics.kafka.batch_size must be positive. Got 0"),
+				errors.New("analytics.kafka.flush_interval_ms must be positive. Got -1"),
+				errors.New("analytics.kafka.buffer_size must be positive. Got 0"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := test.cfg.validate(nil)
+		assert.Equal(t, test.expectedErrs, errs, test.description)
+	}
+}
+
+func TestAnalyticsModulesConfig(t *testing.T) {
+	v := viper.New()
+	SetupViper(v, "")
+	v.Set("gdpr.default_value", "0")
+	v.SetConfigType("yaml")
+	v.ReadConfig(bytes.NewBuffer([]byte(`
+analytics:
+  modules:
+    kafka:
+      sample_rate: 0.01
+      endpoints: ["auction", "amp"]
+account_defaults:
+  analytics:
+    modules:
+      file:
+        enabled: false
+`)))
+	cfg, err := New(v)
+	assert.NoError(t, err)
+
+	sampleRate := 0.01
+	disabled := false
+	assert.Equal(t, map[string]AnalyticsModuleFilter{
+		"kafka": {SampleRate: &sampleRate, Endpoints: []string{"auction", "amp"}},
+	}, cfg.Analytics.Modules)
+	assert.Equal(t, map[string]AnalyticsModuleFilter{
+		"file": {Enabled: &disabled},
+	}, cfg.AccountDefaults.Analytics.Modules)
+	assert.Equal(t, AnalyticsWorkers{Count: 4, QueueSize: 1000}, cfg.Analytics.Workers)
+}
+
+func TestValidateAnalytics(t *testing.T) {
+	invalidRate := 1.5
+	validRate := 0.5
+
+	testCases := []struct {
+		description  string
+		cfg          Analytics
+		expectedErrs []error
+	}{
+		{
+			description: "Valid",
+			cfg: Analytics{
+				Modules: map[string]AnalyticsModuleFilter{"kafka": {SampleRate: &validRate, Endpoints: []string{"auction", "event"}}},
+				Workers: AnalyticsWorkers{Count: 4, QueueSize: 1000},
+			},
+		},
+		{
+			description: "Synchronous modules don't need a queue",
+			cfg:         Analytics{Workers: AnalyticsWorkers{Count: 0, QueueSize: 0}},
+		},
+		{
+			description: "Invalid",
+			cfg: Analytics{
+				Modules: map[string]AnalyticsModuleFilter{"kafka": {SampleRate: &invalidRate, Endpoints: []string{"openrtb2"}}},
+				Workers: AnalyticsWorkers{Count: 4, QueueSize: 0},
+			},
+			expectedErrs: []error{
+				errors.New("analytics.modules.kafka.sample_rate must be in the range [0, 1]. Got 1.5"),
+				errors.New("analytics.modules.kafka.endpoints contains an unknown endpoint type: openrtb2"),
+				errors.New("analytics.workers.queue_size must be positive. Got 0"),
+			},
+		},
+		{
+			description: "Unknown module",
+			cfg: Analytics{
+				Modules: map[string]AnalyticsModuleFilter{"kafkaa": {SampleRate: &invalidRate}},
+			},
+			expectedErrs: []error{
+				errors.New("analytics.modules contains an unknown analytics module: kafkaa"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := test.cfg.validate(nil)
+		assert.Equal(t, test.expectedErrs, errs, test.description)
+	}
+}
+
+func TestAnalyticsModuleFilter(t *testing.T) {
+	enabled := true
+	disabled := false
+	hostRate := 0.01
+	accountRate := 1.0
+
+	host := AnalyticsModuleFilter{SampleRate: &hostRate, Endpoints: []string{AnalyticsEndpointAuction}}
+
+	testCases := []struct {
+		description        string
+		override           AnalyticsModuleFilter
+		expectedFilter     AnalyticsModuleFilter
+		expectedAuction    bool
+		expectedCookieSync bool
+	}{
+		{
+			description:     "No override",
+			expectedFilter:  host,
+			expectedAuction: true,
+		},
+		{
+			description:     "Account disables the module",
+			override:        AnalyticsModuleFilter{Enabled: &disabled},
+			expectedFilter:  AnalyticsModuleFilter{Enabled: &disabled, SampleRate: &hostRate, Endpoints: []string{AnalyticsEndpointAuction}},
+			expectedAuction: false,
+		},
+		{
+			description:        "Account overrides the sample rate and allows no endpoint",
+			override:           AnalyticsModuleFilter{Enabled: &enabled, SampleRate: &accountRate, Endpoints: []string{}},
+			expectedFilter:     AnalyticsModuleFilter{Enabled: &enabled, SampleRate: &accountRate, Endpoints: []string{}},
+			expectedAuction:    false,
+			expectedCookieSync: false,
+		},
+	}
+
+	for _, test := range testCases {
+		filter := host.Merge(test.override)
+		assert.Equal(t, test.expectedFilter, filter, test.description)
+		assert.Equal(t, test.expectedAuction, filter.Allows(AnalyticsEndpointAuction), test.description)
+		assert.Equal(t, test.expectedCookieSync, filter.Allows(AnalyticsEndpointCookieSync), test.description)
+	}
+	assert.True(t, AnalyticsModuleFilter{}.Allows(AnalyticsEndpointCookieSync), "No filter")
+}
+
 func newDefaultConfig(t *testing.T) (*Configuration, *viper.Viper) {
 	v := viper.New()
 	SetupViper(v, "")
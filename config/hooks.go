@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+)
+
+// Hooks configures the modules which can be plugged into the auction stages.
+type Hooks struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Modules holds the host configuration of every module, keyed by vendor and then by module name.
+	// A module is only built if it has an entry here with enabled set to true.
+	Modules map[string]map[string]interface{} `mapstructure:"modules"`
+	// HostExecutionPlan is run for every request, before the execution plan of the account.
+	HostExecutionPlan HookExecutionPlan `mapstructure:"host_execution_plan"`
+	// DefaultAccountExecutionPlan is run for accounts which don't define their own execution plan.
+	DefaultAccountExecutionPlan HookExecutionPlan `mapstructure:"default_account_execution_plan"`
+}
+
+// AccountHooks represents the account-specific hooks configuration.
+type AccountHooks struct {
+	ExecutionPlan HookExecutionPlan `mapstructure:"execution_plan" json:"execution_plan"`
+	// Modules holds the account configuration of every module, keyed by vendor and then by module name.
+	// It is passed to the module hooks on every invocation for the account.
+	Modules map[string]map[string]interface{} `mapstructure:"modules" json:"modules,omitempty"`
+}
+
+// HookExecutionPlan defines which hooks run for each endpoint and stage.
+// Endpoints are keyed by their path (e.g. "/openrtb2/auction") and stages by their name (e.g. "entrypoint").
+type HookExecutionPlan struct {
+	Endpoints map[string]HookEndpointPlan `mapstructure:"endpoints" json:"endpoints,omitempty"`
+}
+
+// HookEndpointPlan defines which hooks run for each stage of an endpoint.
+type HookEndpointPlan struct {
+	Stages map[string]HookStagePlan `mapstructure:"stages" json:"stages"`
+}
+
+// HookStagePlan is the list of hook groups which run for a stage.
+type HookStagePlan struct {
+	Groups []HookExecutionGroup `mapstructure:"groups" json:"groups"`
+}
+
+// HookExecutionGroup is a set of hooks which run in parallel. Groups of a stage run one after the other.
+type HookExecutionGroup struct {
+	// Timeout is the time in milliseconds each hook of the group is given to complete.
+	Timeout      int      `mapstructure:"timeout" json:"timeout"`
+	HookSequence []HookID `mapstructure:"hook_sequence" json:"hook_sequence"`
+}
+
+// HookID identifies a hook: the code of the module which provides it, and the code of the hook within that module.
+type HookID struct {
+	ModuleCode   string `mapstructure:"module_code" json:"module_code"`
+	HookImplCode string `mapstructure:"hook_impl_code" json:"hook_impl_code"`
+}
+
+func (cfg *Hooks) validate(errs []error) []error {
+	errs = cfg.HostExecutionPlan.validate("hooks.host_execution_plan", errs)
+	errs = cfg.DefaultAccountExecutionPlan.validate("hooks.default_account_execution_plan", errs)
+	return errs
+}
+
+func (plan *HookExecutionPlan) validate(path string, errs []error) []error {
+	for endpoint, endpointPlan := range plan.Endpoints {
+		for stage, stagePlan := range endpointPlan.Stages {
+			for i, group := range stagePlan.Groups {
+				if group.Timeout <= 0 {
+					errs = append(errs, fmt.Errorf("%s.endpoints.%s.stages.%s.groups[%d].timeout must be positive. Got %d", path, endpoint, stage, i, group.Timeout))
+				}
+				for j, hook := range group.HookSequence {
+					if hook.ModuleCode == "" || hook.HookImplCode == "" {
+						errs = append(errs, fmt.Errorf("%s.endpoints.%s.stages.%s.groups[%d].hook_sequence[%d] must define module_code and hook_impl_code", path, endpoint, stage, i, j))
+					}
+				}
+			}
+		}
+	}
+	return errs
+}
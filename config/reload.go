@@ -0,0 +1,105 @@
+package config
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/spf13/viper"
+)
+
+// reloads holds the latest configuration loaded by Reload, and the functions notified of the reloads.
+type reloads struct {
+	// mutex serializes the reloads, so that the listeners see them in order
+	mutex     sync.Mutex
+	current   atomic.Value
+	listeners []func(cfg *Configuration)
+}
+
+// Current returns the latest reloaded configuration, or the configuration itself if it was never reloaded.
+//
+// Only the sections copied by Reload change from one configuration to the next. The code which reads them
+// while serving requests should go through Current, instead of holding on to the configuration it started with.
+func (cfg *Configuration) Current() *Configuration {
+	if cfg.reloads == nil {
+		return cfg
+	}
+	if current, ok := cfg.reloads.current.Load().(*Configuration); ok {
+		return current
+	}
+	return cfg
+}
+
+// OnReload registers a function called with the new configuration after each reload.
+func (cfg *Configuration) OnReload(listener func(cfg *Configuration)) {
+	if cfg.reloads == nil {
+		return
+	}
+	cfg.reloads.mutex.Lock()
+	defer cfg.reloads.mutex.Unlock()
+	cfg.reloads.listeners = append(cfg.reloads.listeners, listener)
+}
+
+// Reload loads the configuration again from viper, and swaps in the sections which are safe to change while
+// Prebid Server is running:
+//
+//   - the endpoints and disabled flags of the adapters
+//   - the blacklisted apps and accounts
+//   - the account defaults
+//   - the auction timeouts
+//   - the request validation
+//   - the filters of the analytics modules
+//
+// The other sections keep their values until the next restart. Nothing is swapped if the new configuration
+// doesn't pass validation.
+func (cfg *Configuration) Reload(v *viper.Viper) error {
+	if cfg.reloads == nil {
+		return errors.New("the configuration can't be reloaded, since it wasn't loaded by New")
+	}
+	loaded, err := New(v)
+	if err != nil {
+		return err
+	}
+
+	cfg.reloads.mutex.Lock()
+	defer cfg.reloads.mutex.Unlock()
+
+	reloaded := *cfg.Current()
+	reloaded.copyReloadableSections(loaded)
+	if errs := reloaded.validate(v); len(errs) > 0 {
+		return errortypes.NewAggregateError("validation errors", errs)
+	}
+
+	cfg.reloads.current.Store(&reloaded)
+	for _, listener := range cfg.reloads.listeners {
+		listener(&reloaded)
+	}
+	glog.Info("The configuration was reloaded")
+	return nil
+}
+
+// copyReloadableSections replaces the sections which can be reloaded with the ones of the loaded configuration.
+// The maps are replaced rather than updated, since the previous configuration may still be in use.
+func (cfg *Configuration) copyReloadableSections(loaded *Configuration) {
+	adapters := make(map[string]Adapter, len(cfg.Adapters))
+	for name, adapter := range cfg.Adapters {
+		if loadedAdapter, ok := loaded.Adapters[name]; ok {
+			adapter.Endpoint = loadedAdapter.Endpoint
+			adapter.Disabled = loadedAdapter.Disabled
+		}
+		adapters[name] = adapter
+	}
+	cfg.Adapters = adapters
+
+	cfg.BlacklistedApps = loaded.BlacklistedApps
+	cfg.BlacklistedAppMap = loaded.BlacklistedAppMap
+	cfg.BlacklistedAccts = loaded.BlacklistedAccts
+	cfg.BlacklistedAcctMap = loaded.BlacklistedAcctMap
+	cfg.AccountDefaults = loaded.AccountDefaults
+	cfg.accountDefaultsJSON = loaded.accountDefaultsJSON
+	cfg.AuctionTimeouts = loaded.AuctionTimeouts
+	cfg.RequestValidation = loaded.RequestValidation
+	cfg.Analytics.Modules = loaded.Analytics.Modules
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+var reloadInitialConfig = []byte(`
+host: "initial.com"
+blacklisted_apps: ["app-1"]
+blacklisted_accts: ["account-1"]
+auction_timeouts_ms:
+  default: 100
+  max: 200
+account_defaults:
+  events_enabled: false
+adapters:
+  appnexus:
+    endpoint: "http://initial.com"
+request_validation:
+  ipv4_private_networks: ["10.0.0.0/8"]
+analytics:
+  modules:
+    file:
+      sample_rate: 0.5
+`)
+
+var reloadChangedConfig = []byte(`
+host: "changed.com"
+blacklisted_apps: ["app-2"]
+blacklisted_accts: ["account-2"]
+auction_timeouts_ms:
+  default: 150
+  max: 300
+account_defaults:
+  events_enabled: true
+adapters:
+  appnexus:
+    endpoint: "http://changed.com"
+    disabled: true
+request_validation:
+  ipv4_private_networks: ["192.168.0.0/16"]
+analytics:
+  modules:
+    file:
+      sample_rate: 0.1
+`)
+
+func TestReload(t *testing.T) {
+	cfg, err := New(newReloadViper(reloadInitialConfig))
+	if !assert.NoError(t, err) {
+		return
+	}
+	var notified []*Configuration
+	cfg.OnReload(func(reloaded *Configuration) {
+		notified = append(notified, reloaded)
+	})
+
+	assert.NoError(t, cfg.Reload(newReloadViper(reloadChangedConfig)))
+
+	current := cfg.Current()
+	assert.Equal(t, []*Configuration{current}, notified, "The listeners should get the reloaded configuration")
+	assert.Equal(t, map[string]bool{"app-2": true}, current.BlacklistedAppMap)
+	assert.Equal(t, map[string]bool{"account-2": true}, current.BlacklistedAcctMap)
+	assert.Equal(t, AuctionTimeouts{Default: 150, Max: 300}, current.AuctionTimeouts)
+	assert.True(t, current.AccountDefaults.EventsEnabled)
+	assert.Contains(t, string(current.AccountDefaultsJSON()), `"events_enabled":true`)
+	assert.Equal(t, "http://changed.com", current.Adapters["appnexus"].Endpoint)
+	assert.True(t, current.Adapters["appnexus"].Disabled)
+	if assert.Len(t, current.RequestValidation.IPv4PrivateNetworksParsed, 1) {
+		assert.Equal(t, "192.168.0.0/16", current.RequestValidation.IPv4PrivateNetworksParsed[0].String())
+	}
+	assert.Equal(t, 0.1, *current.Analytics.Modules["file"].SampleRate)
+	assert.Equal(t, "initial.com", current.Host, "The sections which can't be reloaded should be kept")
+
+	assert.Equal(t, map[string]bool{"app-1": true}, cfg.BlacklistedAppMap, "The initial configuration should be left untouched")
+	assert.Equal(t, "http://initial.com", cfg.Adapters["appnexus"].Endpoint, "The initial configuration should be left untouched")
+	assert.Equal(t, current, current.Current(), "The reloaded configuration should follow the next reloads too")
+}
+
+func TestReloadInvalid(t *testing.T) {
+	cfg, err := New(newReloadViper(reloadInitialConfig))
+	if !assert.NoError(t, err) {
+		return
+	}
+	notified := false
+	cfg.OnReload(func(reloaded *Configuration) {
+		notified = true
+	})
+
+	err = cfg.Reload(newReloadViper([]byte(`
+blacklisted_apps: ["app-2"]
+auction_timeouts_ms:
+  default: 300
+  max: 200
+`)))
+
+	assert.Error(t, err)
+	assert.False(t, notified, "The listeners shouldn't be notified of an invalid configuration")
+	assert.Equal(t, cfg, cfg.Current(), "The invalid configuration shouldn't be swapped in")
+}
+
+func TestReloadNotLoadedByNew(t *testing.T) {
+	cfg := &Configuration{}
+
+	assert.Error(t, cfg.Reload(newReloadViper(reloadChangedConfig)))
+	assert.Equal(t, cfg, cfg.Current())
+}
+
+func newReloadViper(config []byte) *viper.Viper {
+	v := viper.New()
+	SetupViper(v, "")
+	v.Set("gdpr.default_value", "0")
+	v.SetConfigType("yaml")
+	v.ReadConfig(bytes.NewBuffer(config))
+	return v
+}
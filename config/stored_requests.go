@@ -62,6 +62,10 @@ type StoredRequests struct {
 	// HTTP configures an instance of stored_requests/backends/http/http_fetcher.go.
 	// If non-nil, Stored Requests will be fetched from the endpoint described there.
 	HTTP HTTPFetcherConfig `mapstructure:"http"`
+	// Redis configures Fetchers and EventProducers which read from a Redis server.
+	// Fetchers are in stored_requests/backends/redis_fetcher/fetcher.go
+	// EventProducers are in stored_requests/events/redis
+	Redis RedisConfig `mapstructure:"redis"`
 	// InMemoryCache configures an instance of stored_requests/caches/memory/cache.go.
 	// If non-nil, Stored Requests will be saved in an in-memory cache.
 	InMemoryCache InMemoryCache `mapstructure:"in_memory_cache"`
@@ -126,6 +130,7 @@ func resolvedStoredRequestsConfig(cfg *Configuration) {
 	amp.HTTP.Endpoint = sr.HTTP.AmpEndpoint
 	amp.CacheEvents.Endpoint = "/storedrequests/amp"
 	amp.HTTPEvents.Endpoint = sr.HTTPEvents.AmpEndpoint
+	amp.Redis.KeyPrefixes.Requests = sr.Redis.KeyPrefixes.AmpRequests
 
 	// Set data types for each section
 	cfg.StoredRequests.dataType = RequestDataType
@@ -142,9 +147,14 @@ func (cfg *StoredRequests) validate(errs []error) []error {
 	} else {
 		errs = cfg.Postgres.validate(cfg.DataType(), errs)
 	}
+	errs = cfg.Redis.validate(cfg.DataType(), errs)
 
-	// Categories do not use cache so none of the following checks apply
+	// Categories are only cached in memory if a cache type is set, and aren't updated by events,
+	// so that only the cache itself has checks which apply
 	if cfg.DataType() == CategoryDataType {
+		if cfg.InMemoryCache.Type != "" {
+			errs = cfg.InMemoryCache.validate(cfg.DataType(), errs)
+		}
 		return errs
 	}
 
@@ -163,6 +173,9 @@ func (cfg *StoredRequests) validate(errs []error) []error {
 		if cfg.Postgres.CacheInitialization.Query != "" {
 			errs = append(errs, fmt.Errorf("%s: postgres.initialize_caches.query must be empty if in_memory_cache=none", cfg.Section()))
 		}
+		if cfg.Redis.Events.Enabled {
+			errs = append(errs, fmt.Errorf("%s: redis.events must be disabled if in_memory_cache=none", cfg.Section()))
+		}
 	}
 	errs = cfg.InMemoryCache.validate(cfg.DataType(), errs)
 	return errs
@@ -187,6 +200,85 @@ func (cfg *PostgresConfig) validate(dataType DataType, errs []error) []error {
 	return errs
 }
 
+// RedisConfig configures the Stored Request ecosystem to use Redis. Stored data is fetched from Redis
+// if an address is set, and the in-memory cache may optionally be kept up to date from Redis as well.
+type RedisConfig struct {
+	ConnectionInfo RedisConnection  `mapstructure:"connection"`
+	KeyPrefixes    RedisKeyPrefixes `mapstructure:"key_prefixes"`
+	Events         RedisEvents      `mapstructure:"events"`
+}
+
+func (cfg *RedisConfig) validate(dataType DataType, errs []error) []error {
+	section := dataType.Section()
+	if cfg.ConnectionInfo.Address == "" {
+		return errs
+	}
+
+	if cfg.ConnectionInfo.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("%s: redis.connection.timeout_ms must be positive", section))
+	}
+	if cfg.ConnectionInfo.Database < 0 {
+		errs = append(errs, fmt.Errorf("%s: redis.connection.db must be >= 0", section))
+	}
+	if !cfg.Events.Enabled || cfg.Events.Channel != "" {
+		return errs
+	}
+
+	// Keyspace notifications tell the type of data apart by the key prefix, so each prefix needs to be distinct
+	switch dataType {
+	case AccountDataType:
+		if cfg.KeyPrefixes.Accounts == "" {
+			errs = append(errs, fmt.Errorf("%s: redis.key_prefixes.accounts must be set to use keyspace notifications", section))
+		}
+	case RequestDataType, AMPRequestDataType, VideoDataType:
+		if cfg.KeyPrefixes.Requests == "" || cfg.KeyPrefixes.Imps == "" {
+			errs = append(errs, fmt.Errorf("%s: redis.key_prefixes.requests and imps must be set to use keyspace notifications", section))
+		} else if strings.HasPrefix(cfg.KeyPrefixes.Requests, cfg.KeyPrefixes.Imps) || strings.HasPrefix(cfg.KeyPrefixes.Imps, cfg.KeyPrefixes.Requests) {
+			errs = append(errs, fmt.Errorf("%s: redis.key_prefixes.requests and imps must not be prefixes of each other to use keyspace notifications", section))
+		}
+	}
+	return errs
+}
+
+// RedisConnection has options to dial and authenticate to the Redis server.
+type RedisConnection struct {
+	// Address is the host:port of the Redis server
+	Address  string `mapstructure:"address"`
+	Password string `mapstructure:"password"`
+	Database int    `mapstructure:"db"`
+	// Timeout applies to connecting, reading and writing on a connection
+	Timeout int `mapstructure:"timeout_ms"`
+	// MaxIdle is the max number of idle connections kept in the pool
+	MaxIdle int `mapstructure:"max_idle_connections"`
+}
+
+func (cfg RedisConnection) TimeoutDuration() time.Duration {
+	return time.Duration(cfg.Timeout) * time.Millisecond
+}
+
+// RedisKeyPrefixes are prepended to the IDs to build the Redis key of each type of stored data.
+type RedisKeyPrefixes struct {
+	Requests string `mapstructure:"requests"`
+	// AmpRequests is the same as Requests, but used for the `/openrtb2/amp` endpoint.
+	AmpRequests string `mapstructure:"amp_requests"`
+	Imps        string `mapstructure:"imps"`
+	// Responses prefixes the stored auction and bid responses
+	Responses string `mapstructure:"responses"`
+	Accounts  string `mapstructure:"accounts"`
+	// Categories prefixes the keys {primaryAdServer} and {primaryAdServer}_{publisherId},
+	// which hold the same JSON as the category mapping files.
+	Categories string `mapstructure:"categories"`
+}
+
+// RedisEvents configures an instance of stored_requests/events/redis/redis.go.
+type RedisEvents struct {
+	// Enabled should be true to update the in-memory cache when the stored data changes in Redis
+	Enabled bool `mapstructure:"enabled"`
+	// Channel is a pub/sub channel which receives JSON update messages. If empty, the keyspace
+	// notifications of the key prefixes are used instead, which must be enabled on the Redis server.
+	Channel string `mapstructure:"channel"`
+}
+
 // PostgresConnection has options which put types to the Postgres Connection string. See:
 // https://godoc.org/github.com/lib/pq#hdr-Connection_String_Parameters
 type PostgresConnection struct {
@@ -261,6 +353,13 @@ type PostgresFetcherQueries struct {
 
 	// AmpQueryTemplate is the same as QueryTemplate, but used in the `/openrtb2/amp` endpoint.
 	AmpQueryTemplate string `mapstructure:"amp_query"`
+
+	// ResponsesQueryTemplate is the Postgres Query which can be used to fetch the stored auction and bid responses.
+	// It is a Template like QueryTemplate, with a single list of IDs. For example:
+	//   SELECT id, responseData, 'response' as type
+	//     FROM stored_responses
+	//     WHERE id in %ID_LIST%
+	ResponsesQueryTemplate string `mapstructure:"responses_query"`
 }
 
 type PostgresCacheInitializer struct {
@@ -342,6 +441,13 @@ func (cfg *PostgresFetcherQueries) MakeQuery(numReqs int, numImps int) (query st
 	return resolve(cfg.QueryTemplate, numReqs, numImps)
 }
 
+// MakeResponsesQuery builds a query which can fetch numIDs Stored Responses.
+// See the docs on PostgresFetcherQueries.ResponsesQueryTemplate for a description of how it works.
+func (cfg *PostgresFetcherQueries) MakeResponsesQuery(numIDs int) (query string) {
+	numIDs = ensureNonNegative("Response", numIDs)
+	return strings.Replace(cfg.ResponsesQueryTemplate, "%ID_LIST%", makeIdList(0, numIDs), -1)
+}
+
 func resolve(template string, numReqs int, numImps int) (query string) {
 	numReqs = ensureNonNegative("Request", numReqs)
 	numImps = ensureNonNegative("Imp", numImps)
@@ -413,7 +519,7 @@ func (cfg *InMemoryCache) validate(dataType DataType, errs []error) []error {
 		if cfg.TTL != 0 {
 			errs = append(errs, fmt.Errorf("%s: in_memory_cache.ttl_seconds is not supported for unbounded caches. Got %d", section, cfg.TTL))
 		}
-		if dataType == AccountDataType {
+		if dataType == AccountDataType || dataType == CategoryDataType {
 			// single cache
 			if cfg.Size != 0 {
 				errs = append(errs, fmt.Errorf("%s: in_memory_cache.size_bytes is not supported for unbounded caches. Got %d", section, cfg.Size))
@@ -428,7 +534,7 @@ func (cfg *InMemoryCache) validate(dataType DataType, errs []error) []error {
 			}
 		}
 	case "lru":
-		if dataType == AccountDataType {
+		if dataType == AccountDataType || dataType == CategoryDataType {
 			// single cache
 			if cfg.Size <= 0 {
 				errs = append(errs, fmt.Errorf("%s: in_memory_cache.size_bytes must be >= 0 when in_memory_cache.type=lru. Got %d", section, cfg.Size))
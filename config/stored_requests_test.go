@@ -157,6 +157,37 @@ func TestInMemoryCacheValidationSingleCache(t *testing.T) {
 	}).validate(AccountDataType, nil))
 }
 
+func TestCategoryMappingCacheValidation(t *testing.T) {
+	testCases := []struct {
+		description   string
+		cache         InMemoryCache
+		expectedValid bool
+	}{
+		{
+			description:   "No cache",
+			cache:         InMemoryCache{},
+			expectedValid: true,
+		},
+		{
+			description:   "LRU cache",
+			cache:         InMemoryCache{Type: "lru", Size: 1000},
+			expectedValid: true,
+		},
+		{
+			description:   "LRU cache without size",
+			cache:         InMemoryCache{Type: "lru", RequestCacheSize: 1000, ImpCacheSize: 1000},
+			expectedValid: false,
+		},
+	}
+
+	for _, test := range testCases {
+		cfg := &StoredRequests{InMemoryCache: test.cache}
+		cfg.SetDataType(CategoryDataType)
+		errs := cfg.validate(nil)
+		assert.Equal(t, test.expectedValid, len(errs) == 0, test.description)
+	}
+}
+
 func TestPostgresConfigValidation(t *testing.T) {
 	tests := []struct {
 		description            string
@@ -259,6 +290,84 @@ func TestPostgresConfigValidation(t *testing.T) {
 	}
 }
 
+func TestRedisConfigValidation(t *testing.T) {
+	tests := []struct {
+		description    string
+		dataType       DataType
+		redisConfig    RedisConfig
+		wantErrorCount int
+	}{
+		{
+			description: "No address",
+			dataType:    RequestDataType,
+			redisConfig: RedisConfig{Events: RedisEvents{Enabled: true}},
+		},
+		{
+			description: "Address without events",
+			dataType:    RequestDataType,
+			redisConfig: RedisConfig{ConnectionInfo: RedisConnection{Address: "localhost:6379", Timeout: 100}},
+		},
+		{
+			description:    "Zero timeout and negative database",
+			dataType:       RequestDataType,
+			redisConfig:    RedisConfig{ConnectionInfo: RedisConnection{Address: "localhost:6379", Database: -1}},
+			wantErrorCount: 2,
+		},
+		{
+			description: "Keyspace notifications with distinct request and imp prefixes",
+			dataType:    RequestDataType,
+			redisConfig: RedisConfig{
+				ConnectionInfo: RedisConnection{Address: "localhost:6379", Timeout: 100},
+				KeyPrefixes:    RedisKeyPrefixes{Requests: "request:", Imps: "imp:"},
+				Events:         RedisEvents{Enabled: true},
+			},
+		},
+		{
+			description: "Keyspace notifications with a missing imp prefix",
+			dataType:    RequestDataType,
+			redisConfig: RedisConfig{
+				ConnectionInfo: RedisConnection{Address: "localhost:6379", Timeout: 100},
+				KeyPrefixes:    RedisKeyPrefixes{Requests: "request:"},
+				Events:         RedisEvents{Enabled: true},
+			},
+			wantErrorCount: 1,
+		},
+		{
+			description: "Keyspace notifications with overlapping request and imp prefixes",
+			dataType:    VideoDataType,
+			redisConfig: RedisConfig{
+				ConnectionInfo: RedisConnection{Address: "localhost:6379", Timeout: 100},
+				KeyPrefixes:    RedisKeyPrefixes{Requests: "stored:", Imps: "stored:imp:"},
+				Events:         RedisEvents{Enabled: true},
+			},
+			wantErrorCount: 1,
+		},
+		{
+			description: "Channel doesn't need prefixes",
+			dataType:    RequestDataType,
+			redisConfig: RedisConfig{
+				ConnectionInfo: RedisConnection{Address: "localhost:6379", Timeout: 100},
+				Events:         RedisEvents{Enabled: true, Channel: "updates"},
+			},
+		},
+		{
+			description: "Keyspace notifications with a missing account prefix",
+			dataType:    AccountDataType,
+			redisConfig: RedisConfig{
+				ConnectionInfo: RedisConnection{Address: "localhost:6379", Timeout: 100},
+				KeyPrefixes:    RedisKeyPrefixes{Requests: "request:", Imps: "imp:"},
+				Events:         RedisEvents{Enabled: true},
+			},
+			wantErrorCount: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		errs := tt.redisConfig.validate(tt.dataType, nil)
+		assert.Equal(t, tt.wantErrorCount, len(errs), tt.description)
+	}
+}
+
 func assertErrsExist(t *testing.T, err []error) {
 	t.Helper()
 	if len(err) == 0 {
@@ -345,6 +454,8 @@ func TestResolveConfig(t *testing.T) {
 	cfg.StoredRequests.Postgres.PollUpdates.Query = "auc-poll-query"
 	cfg.StoredRequests.HTTP.Endpoint = "auc-http-fetcher-endpoint"
 	cfg.StoredRequests.HTTPEvents.Endpoint = "auc-http-events-endpoint"
+	cfg.StoredRequests.Redis.KeyPrefixes.Requests = "auc-redis-prefix:"
+	cfg.StoredRequests.Redis.KeyPrefixes.AmpRequests = "amp-redis-prefix:"
 
 	resolvedStoredRequestsConfig(cfg)
 	auc := &cfg.StoredRequests
@@ -359,5 +470,6 @@ func TestResolveConfig(t *testing.T) {
 	assertStringsEqual(t, amp.Postgres.PollUpdates.Query, cfg.StoredRequests.Postgres.PollUpdates.AmpQuery)
 	assertStringsEqual(t, amp.HTTP.Endpoint, cfg.StoredRequests.HTTP.AmpEndpoint)
 	assertStringsEqual(t, amp.HTTPEvents.Endpoint, cfg.StoredRequests.HTTPEvents.AmpEndpoint)
+	assertStringsEqual(t, amp.Redis.KeyPrefixes.Requests, cfg.StoredRequests.Redis.KeyPrefixes.AmpRequests)
 	assertStringsEqual(t, amp.CacheEvents.Endpoint, "/storedrequests/amp")
 }
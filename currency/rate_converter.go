@@ -1,6 +1,7 @@
 package currency
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -10,7 +11,9 @@ import (
 
 	"github.com/golang/glog"
 	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/tracing"
 	"github.com/prebid/prebid-server/util/timeutil"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
 )
 
 // RateConverter holds the currencies conversion rates dictionary
@@ -76,7 +79,9 @@ func (rc *RateConverter) fetch() (*Rates, error) {
 
 // Update updates the internal currencies rates from remote sources
 func (rc *RateConverter) update() error {
+	_, span := tracing.StartSpan(context.Background(), "currency.FetchRates", semconv.HTTPURLKey.String(rc.syncSourceURL))
 	rates, err := rc.fetch()
+	tracing.EndSpan(span, err)
 	if err == nil {
 		rc.rates.Store(rates)
 		rc.lastUpdated.Store(rc.time.Now())
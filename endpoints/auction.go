@@ -80,7 +80,7 @@ func Auction(cfg *config.Configuration, syncers map[openrtb_ext.BidderName]users
 func (a *auction) auction(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 	w.Header().Add("Content-Type", "application/json")
 	var labels = getDefaultLabels(r)
-	req, err := pbs.ParsePBSRequest(r, &a.cfg.AuctionTimeouts, a.dataCache, &(a.cfg.HostCookie))
+	req, err := pbs.ParsePBSRequest(r, &a.cfg.Current().AuctionTimeouts, a.dataCache, &(a.cfg.HostCookie))
 
 	defer a.recordMetrics(req, labels)
 
@@ -0,0 +1,27 @@
+package endpoints
+
+import (
+	"net/http"
+
+	"github.com/golang/glog"
+)
+
+// NewConfigReloadEndpoint reloads the sections of the host configuration which can change while Prebid Server
+// is running. It answers with the validation errors if the configuration is rejected.
+func NewConfigReloadEndpoint(reload func() error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		if err := reload(); err != nil {
+			glog.Errorf("/config/reload failed to reload the configuration: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(err.Error()))
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
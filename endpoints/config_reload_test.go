@@ -0,0 +1,57 @@
+package endpoints
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigReload(t *testing.T) {
+	testCases := []struct {
+		description    string
+		method         string
+		reloadErr      error
+		expectedReload bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			description:    "Reloaded",
+			method:         http.MethodPost,
+			expectedReload: true,
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			description:    "Rejected configuration",
+			method:         http.MethodPost,
+			reloadErr:      errors.New("auction_timeouts_ms.max cannot be less than auction_timeouts_ms.default"),
+			expectedReload: true,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "auction_timeouts_ms.max cannot be less than auction_timeouts_ms.default",
+		},
+		{
+			description:    "Wrong method",
+			method:         http.MethodGet,
+			expectedReload: false,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, test := range testCases {
+		reloaded := false
+		handler := NewConfigReloadEndpoint(func() error {
+			reloaded = true
+			return test.reloadErr
+		})
+		w := httptest.NewRecorder()
+
+		handler(w, httptest.NewRequest(test.method, "/config/reload", nil))
+
+		assert.Equal(t, test.expectedReload, reloaded, test.description+":reload")
+		assert.Equal(t, test.expectedStatus, w.Code, test.description+":status")
+		assert.Equal(t, test.expectedBody, w.Body.String(), test.description+":body")
+	}
+}
@@ -140,6 +140,8 @@ type platform struct {
 func mapDetailFromConfig(c config.BidderInfo, endpoint string) bidderDetail {
 	var bidderDetail bidderDetail
 
+	bidderDetail.AliasOf = c.AliasOf
+
 	if c.Maintainer != nil {
 		bidderDetail.Maintainer = &maintainer{
 			Email: c.Maintainer.Email,
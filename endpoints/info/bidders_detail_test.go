@@ -363,6 +363,19 @@ func TestMapDetailFromConfig(t *testing.T) {
 				UsesHTTPS: &falseValue,
 			},
 		},
+		{
+			description: "Enabled - Hard Alias",
+			givenBidderInfo: config.BidderInfo{
+				Enabled: true,
+				AliasOf: "appnexus",
+			},
+			givenEndpoint: "https://whitelabel.com/bid",
+			expected: bidderDetail{
+				Status:    "ACTIVE",
+				UsesHTTPS: &trueValue,
+				AliasOf:   "appnexus",
+			},
+		},
 	}
 
 	for _, test := range testCases {
@@ -15,12 +15,13 @@ import (
 	"github.com/golang/glog"
 	"github.com/julienschmidt/httprouter"
 	"github.com/mxmCherry/openrtb/v15/openrtb2"
-	accountService "github.com/prebid/prebid-server/account"
 	"github.com/prebid/prebid-server/amp"
 	"github.com/prebid/prebid-server/analytics"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/exchange"
+	"github.com/prebid/prebid-server/hooks"
+	"github.com/prebid/prebid-server/hooks/hookexecution"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/privacy"
@@ -28,8 +29,8 @@ import (
 	"github.com/prebid/prebid-server/privacy/gdpr"
 	"github.com/prebid/prebid-server/stored_requests"
 	"github.com/prebid/prebid-server/stored_requests/backends/empty_fetcher"
+	"github.com/prebid/prebid-server/tracing"
 	"github.com/prebid/prebid-server/usersync"
-	"github.com/prebid/prebid-server/util/iputil"
 )
 
 const defaultAmpRequestTimeoutMillis = 900
@@ -39,6 +40,12 @@ type AmpResponse struct {
 	Debug     *openrtb_ext.ExtResponseDebug                             `json:"debug,omitempty"`
 	Errors    map[openrtb_ext.BidderName][]openrtb_ext.ExtBidderMessage `json:"errors,omitempty"`
 	Warnings  map[openrtb_ext.BidderName][]openrtb_ext.ExtBidderMessage `json:"warnings,omitempty"`
+	Ext       *AmpResponseExt                                           `json:"ext,omitempty"`
+}
+
+// AmpResponseExt defines the contract for the ext of the AMP response.
+type AmpResponseExt struct {
+	Prebid *openrtb_ext.ExtResponsePrebid `json:"prebid,omitempty"`
 }
 
 // NewAmpEndpoint modifies the OpenRTB endpoint to handle AMP requests. This will basically modify the parsing
@@ -54,6 +61,7 @@ func NewAmpEndpoint(
 	disabledBidders map[string]string,
 	defReqJSON []byte,
 	bidderMap map[string]openrtb_ext.BidderName,
+	hookExecutionPlanBuilder hooks.ExecutionPlanBuilder,
 ) (httprouter.Handle, error) {
 
 	if ex == nil || validator == nil || requestsById == nil || accounts == nil || cfg == nil || met == nil {
@@ -62,10 +70,7 @@ func NewAmpEndpoint(
 
 	defRequest := defReqJSON != nil && len(defReqJSON) > 0
 
-	ipValidator := iputil.PublicNetworkIPValidator{
-		IPv4PrivateNetworks: cfg.RequestValidation.IPv4PrivateNetworksParsed,
-		IPv6PrivateNetworks: cfg.RequestValidation.IPv6PrivateNetworksParsed,
-	}
+	ipValidator := hostIPValidator{cfg}
 
 	return httprouter.Handle((&endpointDeps{
 		ex,
@@ -82,7 +87,9 @@ func NewAmpEndpoint(
 		bidderMap,
 		nil,
 		nil,
-		ipValidator}).AmpAuction), nil
+		ipValidator,
+		hookExecutionPlanBuilder,
+		newAuctionResponseCache(cfg.AuctionResponseCache)}).AmpAuction), nil
 
 }
 
@@ -129,21 +136,33 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 	w.Header().Set("AMP-Access-Control-Allow-Source-Origin", origin)
 	w.Header().Set("Access-Control-Expose-Headers", "AMP-Access-Control-Allow-Source-Origin")
 
-	req, errL := deps.parseAmpRequest(r)
+	req, account, variants, errL := deps.parseAmpRequest(r, &labels)
 	ao.Errors = append(ao.Errors, errL...)
+	ao.StoredRequestVariants = variants
 
 	if errortypes.ContainsFatalError(errL) {
-		w.WriteHeader(http.StatusBadRequest)
+		httpStatus := http.StatusBadRequest
+		metricsStatus := metrics.RequestStatusBadInput
+		for _, er := range errL {
+			errCode := errortypes.ReadCode(er)
+			if errCode == errortypes.BlacklistedAppErrorCode || errCode == errortypes.BlacklistedAcctErrorCode {
+				httpStatus = http.StatusServiceUnavailable
+				metricsStatus = metrics.RequestStatusBlacklisted
+				break
+			}
+		}
+		w.WriteHeader(httpStatus)
+		labels.RequestStatus = metricsStatus
 		for _, err := range errortypes.FatalOnly(errL) {
 			w.Write([]byte(fmt.Sprintf("Invalid request format: %s\n", err.Error())))
 		}
-		labels.RequestStatus = metrics.RequestStatusBadInput
 		return
 	}
 
 	ao.Request = req
+	ao.Account = account
 
-	ctx := context.Background()
+	ctx := tracing.RequestContext(r)
 	var cancel context.CancelFunc
 	if req.TMax > 0 {
 		ctx, cancel = context.WithDeadline(ctx, start.Add(time.Duration(req.TMax)*time.Millisecond))
@@ -152,35 +171,26 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 	}
 	defer cancel()
 
+	storedAuctionResponses, storedBidResponses, storedResponsesErrs := deps.processStoredResponses(ctx, req)
+	if len(storedResponsesErrs) > 0 {
+		ao.Errors = append(ao.Errors, storedResponsesErrs...)
+		w.WriteHeader(http.StatusBadRequest)
+		labels.RequestStatus = metrics.RequestStatusBadInput
+		for _, err := range storedResponsesErrs {
+			w.Write([]byte(fmt.Sprintf("Invalid request format: %s\n", err.Error())))
+		}
+		return
+	}
+
 	usersyncs := usersync.ParsePBSCookieFromRequest(r, &(deps.cfg.HostCookie))
 	if usersyncs.LiveSyncCount() == 0 {
 		labels.CookieFlag = metrics.CookieFlagNo
 	} else {
 		labels.CookieFlag = metrics.CookieFlagYes
 	}
-	labels.PubID = getAccountID(req.Site.Publisher)
-	// Look up account now that we have resolved the pubID value
-	account, acctIDErrs := accountService.GetAccount(ctx, deps.cfg, deps.accounts, labels.PubID)
-	if len(acctIDErrs) > 0 {
-		errL = append(errL, acctIDErrs...)
-		httpStatus := http.StatusBadRequest
-		metricsStatus := metrics.RequestStatusBadInput
-		for _, er := range errL {
-			errCode := errortypes.ReadCode(er)
-			if errCode == errortypes.BlacklistedAppErrorCode || errCode == errortypes.BlacklistedAcctErrorCode {
-				httpStatus = http.StatusServiceUnavailable
-				metricsStatus = metrics.RequestStatusBlacklisted
-				break
-			}
-		}
-		w.WriteHeader(httpStatus)
-		labels.RequestStatus = metricsStatus
-		for _, err := range errortypes.FatalOnly(errL) {
-			w.Write([]byte(fmt.Sprintf("Invalid request format: %s\n", err.Error())))
-		}
-		ao.Errors = append(ao.Errors, acctIDErrs...)
-		return
-	}
+
+	hookExecutor := hookexecution.NewHookExecutor(deps.hookExecutionPlanBuilder, hookexecution.EndpointAmp)
+	hookExecutor.SetAccount(account)
 
 	secGPC := r.Header.Get("Sec-GPC")
 
@@ -192,9 +202,13 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 		StartTime:                  start,
 		LegacyLabels:               labels,
 		GlobalPrivacyControlHeader: secGPC,
+		HookExecutor:               hookExecutor,
+		SeatDetails:                &ao.SeatDetails,
+		StoredAuctionResponses:     storedAuctionResponses,
+		StoredBidResponses:         storedBidResponses,
 	}
 
-	response, err := deps.ex.HoldAuction(ctx, auctionRequest, nil)
+	response, err := deps.holdCachedAuction(ctx, auctionRequest, nil)
 	ao.AuctionResponse = response
 
 	if err != nil {
@@ -261,6 +275,12 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 		Warnings:  warnings,
 	}
 
+	if variants != nil {
+		ampResponse.Ext = &AmpResponseExt{
+			Prebid: &openrtb_ext.ExtResponsePrebid{StoredRequestVariants: variants},
+		}
+	}
+
 	ao.AmpTargetingValues = targets
 
 	// add debug information if requested
@@ -292,9 +312,9 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 // possible, it will return errors with messages that suggest improvements.
 //
 // If the errors list has at least one element, then no guarantees are made about the returned request.
-func (deps *endpointDeps) parseAmpRequest(httpRequest *http.Request) (req *openrtb2.BidRequest, errs []error) {
+func (deps *endpointDeps) parseAmpRequest(httpRequest *http.Request, labels *metrics.Labels) (req *openrtb2.BidRequest, account *config.Account, variants *openrtb_ext.ExtStoredRequestVariants, errs []error) {
 	// Load the stored request for the AMP ID.
-	req, e := deps.loadRequestJSONForAmp(httpRequest)
+	req, variants, e := deps.loadRequestJSONForAmp(httpRequest)
 	if errs = append(errs, e...); errortypes.ContainsFatalError(errs) {
 		return
 	}
@@ -308,34 +328,47 @@ func (deps *endpointDeps) parseAmpRequest(httpRequest *http.Request) (req *openr
 		return
 	}
 
+	ctx, cancel := context.WithTimeout(tracing.RequestContext(httpRequest), time.Duration(storedRequestTimeoutMillis)*time.Millisecond)
+	defer cancel()
+	if account, e = deps.lookupAccount(ctx, req, labels); len(e) > 0 {
+		errs = append(errs, e...)
+		return
+	}
+
 	// At this point, we should have a valid request that definitely has Targeting and Cache turned on
 
-	e = deps.validateRequest(req)
+	e = deps.validateRequest(req, account)
 	errs = append(errs, e...)
 	return
 }
 
 // Load the stored OpenRTB request for an incoming AMP request, or return the errors found.
-func (deps *endpointDeps) loadRequestJSONForAmp(httpRequest *http.Request) (req *openrtb2.BidRequest, errs []error) {
+// If the stored request is split into weighted variants, the picked variant is returned as well.
+func (deps *endpointDeps) loadRequestJSONForAmp(httpRequest *http.Request) (req *openrtb2.BidRequest, variants *openrtb_ext.ExtStoredRequestVariants, errs []error) {
 	req = &openrtb2.BidRequest{}
 	errs = nil
 
 	ampParams, err := amp.ParseParams(httpRequest)
 	if err != nil {
-		return nil, []error{err}
+		return nil, nil, []error{err}
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(storedRequestTimeoutMillis)*time.Millisecond)
+	ctx, cancel := context.WithTimeout(tracing.RequestContext(httpRequest), time.Duration(storedRequestTimeoutMillis)*time.Millisecond)
 	defer cancel()
 
 	storedRequests, _, errs := deps.storedReqFetcher.FetchRequests(ctx, []string{ampParams.StoredRequestID}, nil)
 	if len(errs) > 0 {
-		return nil, errs
+		return nil, nil, errs
 	}
 	if len(storedRequests) == 0 {
 		errs = []error{fmt.Errorf("No AMP config found for tag_id '%s'", ampParams.StoredRequestID)}
 		return
 	}
+	storedRequests, requestVariants, err := pickStoredVariants(storedRequests, "Stored Request")
+	if err != nil {
+		return nil, nil, []error{err}
+	}
+	variants = newStoredRequestVariants(requestVariants, nil)
 
 	// The fetched config becomes the entire OpenRTB request
 	requestJSON := storedRequests[ampParams.StoredRequestID]
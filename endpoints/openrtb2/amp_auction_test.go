@@ -18,7 +18,9 @@ import (
 	analyticsConf "github.com/prebid/prebid-server/analytics/config"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/exchange"
+	"github.com/prebid/prebid-server/hooks"
 	"github.com/prebid/prebid-server/metrics"
+	metricsConfig "github.com/prebid/prebid-server/metrics/config"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	gometrics "github.com/rcrowley/go-metrics"
 	"github.com/stretchr/testify/assert"
@@ -47,10 +49,11 @@ func TestGoodAmpRequests(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		newTestMetrics(),
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
+		hooks.EmptyPlanBuilder{},
 	)
 
 	for requestID := range goodRequests {
@@ -99,10 +102,11 @@ func TestAMPPageInfo(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		newTestMetrics(),
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
+		hooks.EmptyPlanBuilder{},
 	)
 	request := httptest.NewRequest("GET", fmt.Sprintf("/openrtb2/auction/amp?tag_id=1&curl=%s", url.QueryEscape(page)), nil)
 	recorder := httptest.NewRecorder()
@@ -118,6 +122,49 @@ func TestAMPPageInfo(t *testing.T) {
 	assert.Equal(t, "test.somepage.co.uk", exchange.lastRequest.Site.Domain)
 }
 
+func TestAMPStoredRequestVariants(t *testing.T) {
+	stored := map[string]json.RawMessage{
+		"1": json.RawMessage(`{"variants":[
+			{"id":"control","weight":0,"data":{}},
+			{"id":"short-timeout","weight":1,"data":` + validRequest(t, "site.json") + `}
+		]}`),
+	}
+	exchange := &mockAmpExchange{}
+	endpoint, _ := NewAmpEndpoint(
+		exchange,
+		newParamsValidator(t),
+		&mockAmpStoredReqFetcher{stored},
+		empty_fetcher.EmptyFetcher{},
+		&config.Configuration{MaxRequestSize: maxSize},
+		newTestMetrics(),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
+		map[string]string{},
+		[]byte{},
+		openrtb_ext.BuildBidderMap(),
+		hooks.EmptyPlanBuilder{},
+	)
+	request := httptest.NewRequest("GET", "/openrtb2/auction/amp?tag_id=1", nil)
+	recorder := httptest.NewRecorder()
+	endpoint(recorder, request, nil)
+
+	if !assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String()) {
+		return
+	}
+	var response AmpResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Error unmarshalling response: %s", err.Error())
+	}
+	expected := &AmpResponseExt{
+		Prebid: &openrtb_ext.ExtResponsePrebid{
+			StoredRequestVariants: &openrtb_ext.ExtStoredRequestVariants{
+				Requests: map[string]string{"1": "short-timeout"},
+			},
+		},
+	}
+	assert.Equal(t, expected, response.Ext)
+	assert.NotNil(t, exchange.lastRequest, "The request of the picked variant should be auctioned")
+}
+
 func TestGDPRConsent(t *testing.T) {
 	consent := "BOu5On0Ou5On0ADACHENAO7pqzAAppY"
 	existingConsent := "BONV8oqONXwgmADACHENAO7pqzAAppY"
@@ -196,10 +243,11 @@ func TestGDPRConsent(t *testing.T) {
 			empty_fetcher.EmptyFetcher{},
 			&config.Configuration{MaxRequestSize: maxSize},
 			newTestMetrics(),
-			analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+			analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
 			map[string]string{},
 			[]byte{},
 			openrtb_ext.BuildBidderMap(),
+			hooks.EmptyPlanBuilder{},
 		)
 
 		// Invoke Endpoint
@@ -348,10 +396,11 @@ func TestCCPAConsent(t *testing.T) {
 			empty_fetcher.EmptyFetcher{},
 			&config.Configuration{MaxRequestSize: maxSize},
 			newTestMetrics(),
-			analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+			analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
 			map[string]string{},
 			[]byte{},
 			openrtb_ext.BuildBidderMap(),
+			hooks.EmptyPlanBuilder{},
 		)
 
 		// Invoke Endpoint
@@ -458,10 +507,11 @@ func TestConsentWarnings(t *testing.T) {
 			empty_fetcher.EmptyFetcher{},
 			&config.Configuration{MaxRequestSize: maxSize},
 			newTestMetrics(),
-			analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+			analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
 			map[string]string{},
 			[]byte{},
 			openrtb_ext.BuildBidderMap(),
+			hooks.EmptyPlanBuilder{},
 		)
 
 		// Invoke Endpoint
@@ -550,10 +600,11 @@ func TestNewAndLegacyConsentBothProvided(t *testing.T) {
 			empty_fetcher.EmptyFetcher{},
 			&config.Configuration{MaxRequestSize: maxSize},
 			newTestMetrics(),
-			analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+			analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
 			map[string]string{},
 			[]byte{},
 			openrtb_ext.BuildBidderMap(),
+			hooks.EmptyPlanBuilder{},
 		)
 
 		// Invoke Endpoint
@@ -601,10 +652,11 @@ func TestAMPSiteExt(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		newTestMetrics(),
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
 		nil,
 		nil,
 		openrtb_ext.BuildBidderMap(),
+		hooks.EmptyPlanBuilder{},
 	)
 	request, err := http.NewRequest("GET", "/openrtb2/auction/amp?tag_id=1", nil)
 	if !assert.NoError(t, err) {
@@ -637,10 +689,11 @@ func TestAmpBadRequests(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		newTestMetrics(),
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
+		hooks.EmptyPlanBuilder{},
 	)
 	for requestID := range badRequests {
 		request := httptest.NewRequest("GET", fmt.Sprintf("/openrtb2/auction/amp?tag_id=%s", requestID), nil)
@@ -667,10 +720,11 @@ func TestAmpDebug(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		newTestMetrics(),
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
+		hooks.EmptyPlanBuilder{},
 	)
 
 	for requestID := range requests {
@@ -739,10 +793,11 @@ func TestQueryParamOverrides(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		newTestMetrics(),
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
+		hooks.EmptyPlanBuilder{},
 	)
 
 	requestID := "1"
@@ -891,10 +946,11 @@ func (s formatOverrideSpec) execute(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		newTestMetrics(),
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
+		hooks.EmptyPlanBuilder{},
 	)
 
 	url := fmt.Sprintf("/openrtb2/auction/amp?tag_id=1&debug=1&w=%d&h=%d&ow=%d&oh=%d&ms=%s&account=%s", s.width, s.height, s.overrideWidth, s.overrideHeight, s.multisize, s.account)
@@ -933,6 +989,10 @@ func (cf *mockAmpStoredReqFetcher) FetchRequests(ctx context.Context, requestIDs
 	return cf.data, nil, nil
 }
 
+func (cf *mockAmpStoredReqFetcher) FetchResponses(ctx context.Context, ids []string) (data map[string]json.RawMessage, errs []error) {
+	return nil, nil
+}
+
 type mockAmpExchange struct {
 	lastRequest *openrtb2.BidRequest
 }
@@ -1274,6 +1334,7 @@ func TestBuildAmpObject(t *testing.T) {
 			map[string]string{},
 			[]byte{},
 			openrtb_ext.BuildBidderMap(),
+			hooks.EmptyPlanBuilder{},
 		)
 
 		// Run test
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/url"
 	"regexp"
@@ -26,6 +27,8 @@ import (
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/exchange"
+	"github.com/prebid/prebid-server/hooks"
+	"github.com/prebid/prebid-server/hooks/hookexecution"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/prebid_cache_client"
@@ -33,6 +36,8 @@ import (
 	"github.com/prebid/prebid-server/privacy/lmt"
 	"github.com/prebid/prebid-server/stored_requests"
 	"github.com/prebid/prebid-server/stored_requests/backends/empty_fetcher"
+	"github.com/prebid/prebid-server/stored_responses"
+	"github.com/prebid/prebid-server/tracing"
 	"github.com/prebid/prebid-server/usersync"
 	"github.com/prebid/prebid-server/util/httputil"
 	"github.com/prebid/prebid-server/util/iputil"
@@ -59,6 +64,7 @@ func NewEndpoint(
 	disabledBidders map[string]string,
 	defReqJSON []byte,
 	bidderMap map[string]openrtb_ext.BidderName,
+	hookExecutionPlanBuilder hooks.ExecutionPlanBuilder,
 ) (httprouter.Handle, error) {
 	if ex == nil || validator == nil || requestsById == nil || accounts == nil || cfg == nil || met == nil {
 		return nil, errors.New("NewEndpoint requires non-nil arguments.")
@@ -66,10 +72,7 @@ func NewEndpoint(
 
 	defRequest := defReqJSON != nil && len(defReqJSON) > 0
 
-	ipValidator := iputil.PublicNetworkIPValidator{
-		IPv4PrivateNetworks: cfg.RequestValidation.IPv4PrivateNetworksParsed,
-		IPv6PrivateNetworks: cfg.RequestValidation.IPv6PrivateNetworksParsed,
-	}
+	ipValidator := hostIPValidator{cfg}
 
 	return httprouter.Handle((&endpointDeps{
 		ex,
@@ -86,7 +89,9 @@ func NewEndpoint(
 		bidderMap,
 		nil,
 		nil,
-		ipValidator}).Auction), nil
+		ipValidator,
+		hookExecutionPlanBuilder,
+		nil}).Auction), nil
 }
 
 type endpointDeps struct {
@@ -105,6 +110,8 @@ type endpointDeps struct {
 	cache                     prebid_cache_client.Client
 	debugLogRegexp            *regexp.Regexp
 	privateNetworkIPValidator iputil.IPValidator
+	hookExecutionPlanBuilder  hooks.ExecutionPlanBuilder
+	auctionResponseCache      auctionResponseCache
 }
 
 func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
@@ -135,16 +142,33 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 		deps.analytics.LogAuctionObject(&ao)
 	}()
 
-	req, errL := deps.parseRequest(r)
+	hookExecutor := hookexecution.NewHookExecutor(deps.hookExecutionPlanBuilder, hookexecution.EndpointAuction)
+	defer func() {
+		ao.HookExecutionOutcome = hookExecutor.GetOutcomes()
+	}()
+
+	req, account, variants, errL := deps.parseRequest(r, &labels, hookExecutor)
+	ao.StoredRequestVariants = variants
+
+	if rejectErr := hookexecution.FindReject(errL); rejectErr != nil {
+		ao.Errors = append(ao.Errors, rejectErr)
+		ao.Response = writeRejectedResponse(w, req, rejectErr)
+		return
+	}
 
 	if errortypes.ContainsFatalError(errL) && writeError(errL, w, &labels) {
 		return
 	}
 	warnings := errortypes.WarningOnly(errL)
 
-	ctx := context.Background()
+	ctx := tracing.RequestContext(r)
 
-	timeout := deps.cfg.AuctionTimeouts.LimitAuctionTimeout(time.Duration(req.TMax) * time.Millisecond)
+	storedAuctionResponses, storedBidResponses, storedResponsesErrs := deps.processStoredResponses(ctx, req)
+	if writeError(storedResponsesErrs, w, &labels) {
+		return
+	}
+
+	timeout := deps.cfg.Current().AuctionTimeouts.LimitAuctionTimeout(time.Duration(req.TMax) * time.Millisecond)
 	if timeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithDeadline(ctx, start.Add(timeout))
@@ -155,7 +179,6 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 	if req.App != nil {
 		labels.Source = metrics.DemandApp
 		labels.RType = metrics.ReqTypeORTB2App
-		labels.PubID = getAccountID(req.App.Publisher)
 	} else { //req.Site != nil
 		labels.Source = metrics.DemandWeb
 		if usersyncs.LiveSyncCount() == 0 {
@@ -163,14 +186,14 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 		} else {
 			labels.CookieFlag = metrics.CookieFlagYes
 		}
-		labels.PubID = getAccountID(req.Site.Publisher)
 	}
 
-	// Look up account now that we have resolved the pubID value
-	account, acctIDErrs := accountService.GetAccount(ctx, deps.cfg, deps.accounts, labels.PubID)
-	if len(acctIDErrs) > 0 {
-		errL = append(errL, acctIDErrs...)
-		writeError(errL, w, &labels)
+	hookExecutor.SetAccount(account)
+	if rejectErr := hookExecutor.ExecuteProcessedAuctionStage(req); rejectErr != nil {
+		ao.Request = req
+		ao.Account = account
+		ao.Errors = append(ao.Errors, rejectErr)
+		ao.Response = writeRejectedResponse(w, req, rejectErr)
 		return
 	}
 
@@ -185,9 +208,23 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 		LegacyLabels:               labels,
 		Warnings:                   warnings,
 		GlobalPrivacyControlHeader: secGPC,
+		HookExecutor:               hookExecutor,
+		SeatDetails:                &ao.SeatDetails,
+		StoredAuctionResponses:     storedAuctionResponses,
+		StoredBidResponses:         storedBidResponses,
 	}
 
 	response, err := deps.ex.HoldAuction(ctx, auctionRequest, nil)
+	if err == nil && response != nil {
+		if response.Ext, err = hookexecution.EnrichExtBidResponse(response.Ext, hookExecutor.GetOutcomes(), req.Test == 1 && account.DebugAllow); err != nil {
+			ao.Errors = append(ao.Errors, err)
+			err = nil
+		}
+		if response.Ext, err = addStoredRequestVariants(response.Ext, variants); err != nil {
+			ao.Errors = append(ao.Errors, err)
+			err = nil
+		}
+	}
 	ao.Request = req
 	ao.Response = response
 	ao.Account = account
@@ -222,12 +259,15 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 //   - A context which times out appropriately, given the request.
 //   - A cancellation function which should be called if the auction finishes early.
 //
+// The account of the request publisher is looked up before the validation, and its ID is recorded in the labels.
+// The variants picked for the Stored Requests and Imps split into weighted variants are returned as well.
+//
 // If the errors list is empty, then the returned request will be valid according to the OpenRTB 2.5 spec.
 // In case of "strong recommendations" in the spec, it tends to be restrictive. If a better workaround is
 // possible, it will return errors with messages that suggest improvements.
 //
 // If the errors list has at least one element, then no guarantees are made about the returned request.
-func (deps *endpointDeps) parseRequest(httpRequest *http.Request) (req *openrtb2.BidRequest, errs []error) {
+func (deps *endpointDeps) parseRequest(httpRequest *http.Request, labels *metrics.Labels, hookExecutor hookexecution.HookStageExecutor) (req *openrtb2.BidRequest, account *config.Account, variants *openrtb_ext.ExtStoredRequestVariants, errs []error) {
 	req = &openrtb2.BidRequest{}
 	errs = nil
 
@@ -249,12 +289,24 @@ func (deps *endpointDeps) parseRequest(httpRequest *http.Request) (req *openrtb2
 		}
 	}
 
+	requestJson, rejectErr := hookExecutor.ExecuteEntrypointStage(httpRequest, requestJson)
+	if rejectErr != nil {
+		errs = []error{rejectErr}
+		return
+	}
+
+	requestJson, rejectErr = hookExecutor.ExecuteRawAuctionStage(requestJson)
+	if rejectErr != nil {
+		errs = []error{rejectErr}
+		return
+	}
+
 	timeout := parseTimeout(requestJson, time.Duration(storedRequestTimeoutMillis)*time.Millisecond)
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(tracing.RequestContext(httpRequest), timeout)
 	defer cancel()
 
 	// Fetch the Stored Request data and merge it into the HTTP request.
-	if requestJson, errs = deps.processStoredRequests(ctx, requestJson); len(errs) > 0 {
+	if requestJson, variants, errs = deps.processStoredRequests(ctx, requestJson); len(errs) > 0 {
 		return
 	}
 
@@ -263,22 +315,27 @@ func (deps *endpointDeps) parseRequest(httpRequest *http.Request) (req *openrtb2
 		return
 	}
 
+	account, errs = deps.resolveRequest(ctx, httpRequest, req, labels)
+	return
+}
+
+// resolveRequest completes a request whose Stored Requests were merged, and validates it against its account.
+func (deps *endpointDeps) resolveRequest(ctx context.Context, httpRequest *http.Request, req *openrtb2.BidRequest, labels *metrics.Labels) (*config.Account, []error) {
 	// Populate any "missing" OpenRTB fields with info from other sources, (e.g. HTTP request headers).
 	deps.setFieldsImplicitly(httpRequest, req)
 
 	if err := processInterstitials(req); err != nil {
-		errs = []error{err}
-		return
+		return nil, []error{err}
 	}
 
 	lmt.ModifyForIOS(req)
 
-	errL := deps.validateRequest(req)
-	if len(errL) > 0 {
-		errs = append(errs, errL...)
+	account, errs := deps.lookupAccount(ctx, req, labels)
+	if len(errs) > 0 {
+		return account, errs
 	}
 
-	return
+	return account, deps.validateRequest(req, account)
 }
 
 // parseTimeout returns parses tmax from the requestJson, or returns the default if it doesn't exist.
@@ -296,7 +353,20 @@ func parseTimeout(requestJson []byte, defaultTimeout time.Duration) time.Duratio
 	return defaultTimeout
 }
 
-func (deps *endpointDeps) validateRequest(req *openrtb2.BidRequest) []error {
+// lookupAccount resolves the account of the request publisher and records its ID in the labels.
+// It runs before the request is validated, since the account may define the bidder aliases used by the request.
+func (deps *endpointDeps) lookupAccount(ctx context.Context, req *openrtb2.BidRequest, labels *metrics.Labels) (*config.Account, []error) {
+	if req.App != nil {
+		labels.PubID = getAccountID(req.App.Publisher)
+	} else if req.Site != nil {
+		labels.PubID = getAccountID(req.Site.Publisher)
+	}
+	return accountService.GetAccount(ctx, deps.cfg.Current(), deps.accounts, labels.PubID)
+}
+
+// validateRequest validates the request against the bidders known to the host and the account.
+// Bidders which are disabled for the account are removed from the imps with a warning.
+func (deps *endpointDeps) validateRequest(req *openrtb2.BidRequest, account *config.Account) []error {
 	errL := []error{}
 	if req.ID == "" {
 		return []error{errors.New("request missing required field: \"id\"")}
@@ -323,16 +393,21 @@ func (deps *endpointDeps) validateRequest(req *openrtb2.BidRequest) []error {
 		}
 	}
 
-	var aliases map[string]string
-	if bidExt, err := deps.parseBidExt(req.Ext); err != nil {
+	bidExt, err := deps.parseBidExt(req.Ext)
+	if err != nil {
 		return []error{err}
-	} else if bidExt != nil {
-		aliases = bidExt.Prebid.Aliases
+	}
 
-		if err := deps.validateAliases(aliases); err != nil {
-			return []error{err}
-		}
+	var requestAliases map[string]string
+	if bidExt != nil {
+		requestAliases = bidExt.Prebid.Aliases
+	}
+	aliases := account.Bidders.MergeAliases(requestAliases)
+	if err := deps.validateAliases(aliases); err != nil {
+		return []error{err}
+	}
 
+	if bidExt != nil {
 		if err := deps.validateBidAdjustmentFactors(bidExt.Prebid.BidAdjustmentFactors, aliases); err != nil {
 			return []error{err}
 		}
@@ -341,6 +416,10 @@ func (deps *endpointDeps) validateRequest(req *openrtb2.BidRequest) []error {
 			return []error{err}
 		}
 
+		if err := validateBidderConfigs(bidExt.Prebid.BidderConfigs); err != nil {
+			return []error{err}
+		}
+
 		if err := deps.validateEidPermissions(bidExt, aliases); err != nil {
 			return []error{err}
 		}
@@ -348,6 +427,13 @@ func (deps *endpointDeps) validateRequest(req *openrtb2.BidRequest) []error {
 		if err := validateCustomRates(bidExt.Prebid.CurrencyConversions); err != nil {
 			return []error{err}
 		}
+
+		if bidExt.Prebid.AuctionTimeout < 0 {
+			return []error{fmt.Errorf("request.ext.prebid.auctiontimeout must be nonnegative. Got %d", bidExt.Prebid.AuctionTimeout)}
+		}
+
+		_, multiBidWarnings := openrtb_ext.ValidateMultiBid(bidExt.Prebid.MultiBid)
+		errL = append(errL, multiBidWarnings...)
 	}
 
 	if (req.Site == nil && req.App == nil) || (req.Site != nil && req.App != nil) {
@@ -397,7 +483,7 @@ func (deps *endpointDeps) validateRequest(req *openrtb2.BidRequest) []error {
 			errL = append(errL, fmt.Errorf(`request.imp[%d].id and request.imp[%d].id are both "%s". Imp IDs must be unique.`, firstIndex, index, imp.ID))
 		}
 		impIDs[imp.ID] = index
-		errs := deps.validateImp(imp, aliases, index)
+		errs := deps.validateImp(imp, aliases, &account.Bidders, index)
 		if len(errs) > 0 {
 			errL = append(errL, errs...)
 		}
@@ -442,6 +528,30 @@ func validateSChains(req *openrtb_ext.ExtRequest) error {
 	return err
 }
 
+// validateBidderConfigs throws a bad input error if an entry of bidRequest.ext.prebid.bidderconfig
+// doesn't list its bidders or defines site, app or user as anything but a JSON object.
+func validateBidderConfigs(bidderConfigs []openrtb_ext.ExtRequestPrebidBidderConfig) error {
+	for i, bidderConfig := range bidderConfigs {
+		if len(bidderConfig.Bidders) == 0 {
+			return &errortypes.BadInput{Message: fmt.Sprintf("request.ext.prebid.bidderconfig[%d] must define at least one bidder", i)}
+		}
+		if bidderConfig.Config == nil || bidderConfig.Config.ORTB2 == nil {
+			continue
+		}
+		ortb2 := bidderConfig.Config.ORTB2
+		names := []string{"site", "app", "user"}
+		for j, object := range []json.RawMessage{ortb2.Site, ortb2.App, ortb2.User} {
+			if len(object) == 0 {
+				continue
+			}
+			if _, dataType, _, err := jsonparser.Get(object); err != nil || dataType != jsonparser.Object {
+				return &errortypes.BadInput{Message: fmt.Sprintf("request.ext.prebid.bidderconfig[%d].config.ortb2.%s must be an object", i, names[j])}
+			}
+		}
+	}
+	return nil
+}
+
 // validateCustomRates throws a bad input error if any of the 3-digit currency codes found in
 // the bidRequest.ext.prebid.currency field is invalid, malfomed or does not represent any actual
 // currency. No error is thrown if bidRequest.ext.prebid.currency is invalid or empty.
@@ -511,7 +621,7 @@ func validateBidders(bidders []string, knownBidders map[string]openrtb_ext.Bidde
 	return nil
 }
 
-func (deps *endpointDeps) validateImp(imp *openrtb2.Imp, aliases map[string]string, index int) []error {
+func (deps *endpointDeps) validateImp(imp *openrtb2.Imp, aliases map[string]string, accountBidders *config.AccountBidders, index int) []error {
 	if imp.ID == "" {
 		return []error{fmt.Errorf("request.imp[%d] missing required field: \"id\"", index)}
 	}
@@ -544,7 +654,7 @@ func (deps *endpointDeps) validateImp(imp *openrtb2.Imp, aliases map[string]stri
 		return []error{err}
 	}
 
-	errL := deps.validateImpExt(imp, aliases, index)
+	errL := deps.validateImpExt(imp, aliases, accountBidders, index)
 	if len(errL) != 0 {
 		return errL
 	}
@@ -959,7 +1069,7 @@ func validatePmp(pmp *openrtb2.PMP, impIndex int) error {
 	return nil
 }
 
-func (deps *endpointDeps) validateImpExt(imp *openrtb2.Imp, aliases map[string]string, impIndex int) []error {
+func (deps *endpointDeps) validateImpExt(imp *openrtb2.Imp, aliases map[string]string, accountBidders *config.AccountBidders, impIndex int) []error {
 	errL := []error{}
 	if len(imp.Ext) == 0 {
 		return []error{fmt.Errorf("request.imp[%d].ext is required", impIndex)}
@@ -994,6 +1104,11 @@ func (deps *endpointDeps) validateImpExt(imp *openrtb2.Imp, aliases map[string]s
 				coreBidder = tmp
 			}
 			if bidderName, isValid := deps.bidderMap[coreBidder]; isValid {
+				if !accountBidders.BidderEnabled(bidder, coreBidder) {
+					errL = append(errL, &errortypes.BidderTemporarilyDisabled{Message: fmt.Sprintf("The bidder '%s' has been disabled for the account.", bidder)})
+					disabledBidders = append(disabledBidders, bidder)
+					continue
+				}
 				if err := deps.paramsValidator.Validate(bidderName, ext); err != nil {
 					return []error{fmt.Errorf("request.imp[%d].ext.%s failed validation.\n%v", impIndex, coreBidder, err)}
 				}
@@ -1097,7 +1212,7 @@ func (deps *endpointDeps) validateApp(app *openrtb2.App) error {
 	}
 
 	if app.ID != "" {
-		if _, found := deps.cfg.BlacklistedAppMap[app.ID]; found {
+		if _, found := deps.cfg.Current().BlacklistedAppMap[app.ID]; found {
 			return &errortypes.BlacklistedApp{Message: fmt.Sprintf("Prebid-server does not process requests from App ID: %s", app.ID)}
 		}
 	}
@@ -1214,6 +1329,20 @@ func validateDevice(device *openrtb2.Device) error {
 	return nil
 }
 
+// hostIPValidator rejects the IPs of the private networks set in the current host configuration, so that
+// it follows the reloads of the request validation.
+type hostIPValidator struct {
+	cfg *config.Configuration
+}
+
+func (v hostIPValidator) IsValid(ip net.IP, ver iputil.IPVersion) bool {
+	requestValidation := v.cfg.Current().RequestValidation
+	return iputil.PublicNetworkIPValidator{
+		IPv4PrivateNetworks: requestValidation.IPv4PrivateNetworksParsed,
+		IPv6PrivateNetworks: requestValidation.IPv6PrivateNetworksParsed,
+	}.IsValid(ip, ver)
+}
+
 func sanitizeRequest(r *openrtb2.BidRequest, ipValidator iputil.IPValidator) {
 	if r.Device != nil {
 		if ip, ver := iputil.ParseIP(r.Device.IP); ip == nil || ver != iputil.IPv4 || !ipValidator.IsValid(ip, ver) {
@@ -1314,15 +1443,23 @@ func getJsonSyntaxError(testJSON []byte) (bool, string) {
 	return false, ""
 }
 
-func (deps *endpointDeps) processStoredRequests(ctx context.Context, requestJson []byte) ([]byte, []error) {
+// processStoredResponses fetches the stored auction and bid responses set on the imps of the request.
+func (deps *endpointDeps) processStoredResponses(ctx context.Context, req *openrtb2.BidRequest) (stored_responses.ImpsWithBidResponses, stored_responses.BidderImpsWithBidResponses, []error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(storedRequestTimeoutMillis)*time.Millisecond)
+	defer cancel()
+
+	return stored_responses.ProcessStoredResponses(ctx, req, deps.storedReqFetcher, deps.cfg.Current().StoredResponses.Enabled)
+}
+
+func (deps *endpointDeps) processStoredRequests(ctx context.Context, requestJson []byte) ([]byte, *openrtb_ext.ExtStoredRequestVariants, []error) {
 	// Parse the Stored Request IDs from the BidRequest and Imps.
 	storedBidRequestId, hasStoredBidRequest, err := getStoredRequestId(requestJson)
 	if err != nil {
-		return nil, []error{err}
+		return nil, nil, []error{err}
 	}
 	imps, impIds, idIndices, errs := parseImpInfo(requestJson)
 	if len(errs) > 0 {
-		return nil, errs
+		return nil, nil, errs
 	}
 
 	// Fetch the Stored Request data
@@ -1332,8 +1469,19 @@ func (deps *endpointDeps) processStoredRequests(ctx context.Context, requestJson
 	}
 	storedRequests, storedImps, errs := deps.storedReqFetcher.FetchRequests(ctx, storedReqIds, impIds)
 	if len(errs) != 0 {
-		return nil, errs
+		return nil, nil, errs
+	}
+
+	// Pick the variants of the Stored Requests and Imps split into weighted variants
+	storedRequests, requestVariants, err := pickStoredVariants(storedRequests, "Stored Request")
+	if err != nil {
+		return nil, nil, []error{err}
+	}
+	storedImps, impVariants, err := pickStoredVariants(storedImps, "Stored Imp")
+	if err != nil {
+		return nil, nil, []error{err}
 	}
+	variants := newStoredRequestVariants(requestVariants, impVariants)
 
 	// Apply the Stored BidRequest, if it exists
 	resolvedRequest := requestJson
@@ -1350,7 +1498,7 @@ func (deps *endpointDeps) processStoredRequests(ctx context.Context, requestJson
 					err = fmt.Errorf("ext.prebid.storedrequest.id refers to Stored Request %s which contains Invalid JSON: %s", storedBidRequestId, Err)
 				}
 			}
-			return nil, []error{err}
+			return nil, nil, []error{err}
 		}
 	}
 
@@ -1367,7 +1515,7 @@ func (deps *endpointDeps) processStoredRequests(ctx context.Context, requestJson
 					err = fmt.Errorf("Invalid JSON in Default Request Settings: %s", Err)
 				}
 			}
-			return nil, []error{err}
+			return nil, nil, []error{err}
 		}
 		resolvedRequest = aliasedRequest
 	}
@@ -1387,22 +1535,22 @@ func (deps *endpointDeps) processStoredRequests(ctx context.Context, requestJson
 					err = fmt.Errorf("imp.ext.prebid.storedrequest.id %s: Stored Imp has Invalid JSON: %s", impIds[i], Err)
 				}
 			}
-			return nil, []error{err}
+			return nil, nil, []error{err}
 		}
 		imps[idIndices[i]] = resolvedImp
 	}
 	if len(impIds) > 0 {
 		newImpJson, err := json.Marshal(imps)
 		if err != nil {
-			return nil, []error{err}
+			return nil, nil, []error{err}
 		}
 		resolvedRequest, err = jsonparser.Set(resolvedRequest, newImpJson, "imp")
 		if err != nil {
-			return nil, []error{err}
+			return nil, nil, []error{err}
 		}
 	}
 
-	return resolvedRequest, nil
+	return resolvedRequest, variants, nil
 }
 
 // parseImpInfo parses the request JSON and returns several things about the Imps
@@ -1530,6 +1678,29 @@ func writeError(errs []error, w http.ResponseWriter, labels *metrics.Labels) boo
 	return rc
 }
 
+// writeRejectedResponse answers a request which was rejected by a hook with an empty bid response,
+// carrying the no-bid reason the hook provided.
+func writeRejectedResponse(w http.ResponseWriter, req *openrtb2.BidRequest, rejectErr *hookexecution.RejectError) *openrtb2.BidResponse {
+	response := rejectedResponse(req, rejectErr)
+
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.Encode(response)
+	return response
+}
+
+// rejectedResponse is the empty bid response to a request rejected by a hook.
+func rejectedResponse(req *openrtb2.BidRequest, rejectErr *hookexecution.RejectError) *openrtb2.BidResponse {
+	response := &openrtb2.BidResponse{
+		NBR: openrtb2.NoBidReasonCode.Ptr(openrtb2.NoBidReasonCode(rejectErr.NBR)),
+	}
+	if req != nil {
+		response.ID = req.ID
+	}
+	return response
+}
+
 // Returns the account ID for the request
 func getAccountID(pub *openrtb2.Publisher) string {
 	if pub != nil {
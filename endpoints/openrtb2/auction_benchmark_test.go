@@ -12,7 +12,10 @@ import (
 	analyticsConf "github.com/prebid/prebid-server/analytics/config"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/exchange"
+	"github.com/prebid/prebid-server/floors"
 	"github.com/prebid/prebid-server/gdpr"
+	"github.com/prebid/prebid-server/hooks"
+	metricsConfig "github.com/prebid/prebid-server/metrics/config"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/stored_requests/backends/empty_fetcher"
 )
@@ -80,6 +83,7 @@ func BenchmarkOpenrtbEndpoint(b *testing.B) {
 		gdpr.AlwaysAllow{},
 		currency.NewRateConverter(&http.Client{}, "", time.Duration(0)),
 		empty_fetcher.EmptyFetcher{},
+		floors.EmptyFetcher{},
 	)
 
 	endpoint, _ := NewEndpoint(
@@ -89,10 +93,11 @@ func BenchmarkOpenrtbEndpoint(b *testing.B) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		newTestMetrics(),
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
 		map[string]string{},
 		[]byte{},
 		nil,
+		hooks.EmptyPlanBuilder{},
 	)
 
 	b.ResetTimer()
@@ -0,0 +1,436 @@
+package openrtb2
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/buger/jsonparser"
+	"github.com/coocood/freecache"
+	"github.com/evanphx/json-patch"
+	"github.com/gofrs/uuid"
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/analytics"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/endpoints/events"
+	"github.com/prebid/prebid-server/exchange"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/prebid_cache_client"
+)
+
+// auctionResponseCache stores the responses of AMP and video auctions, so that an identical request
+// arriving within a short window (e.g. AMP refreshes or CTV pod retries) doesn't fan out to every bidder again.
+type auctionResponseCache interface {
+	Get(key string) (*cachedAuctionResponse, bool)
+	Save(key string, response *cachedAuctionResponse)
+}
+
+// cachedAuctionResponse holds a cached BidResponse along with the timestamp of the auction that produced it,
+// which is needed to regenerate the event URLs when the response is reused.
+type cachedAuctionResponse struct {
+	Response           *openrtb2.BidResponse `json:"response"`
+	AuctionTimestampMs int64                 `json:"ts"`
+}
+
+// newAuctionResponseCache returns the auctionResponseCache described by the config, or nil if it's disabled.
+func newAuctionResponseCache(cfg config.AuctionResponseCache) auctionResponseCache {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &memoryAuctionResponseCache{
+		cache:      freecache.NewCache(cfg.SizeBytes),
+		ttlSeconds: cfg.TTLSeconds,
+	}
+}
+
+// memoryAuctionResponseCache stores the responses serialized, so that callers never share a
+// BidResponse which is modified while the endpoint builds its own response.
+type memoryAuctionResponseCache struct {
+	cache      *freecache.Cache
+	ttlSeconds int
+}
+
+func (c *memoryAuctionResponseCache) Get(key string) (*cachedAuctionResponse, bool) {
+	data, err := c.cache.Get([]byte(key))
+	if err != nil {
+		return nil, false
+	}
+	var cached cachedAuctionResponse
+	if err := json.Unmarshal(data, &cached); err != nil || cached.Response == nil {
+		return nil, false
+	}
+	return &cached, true
+}
+
+func (c *memoryAuctionResponseCache) Save(key string, response *cachedAuctionResponse) {
+	data, err := json.Marshal(response)
+	if err != nil {
+		return
+	}
+	// An error means the response is too large for the cache, in which case it's simply not reused.
+	c.cache.Set([]byte(key), data, c.ttlSeconds)
+}
+
+// holdCachedAuction runs the auction, unless the response of an identical auction can be reused from the
+// auctionResponseCache. Test and debug requests always run a fresh auction, and only responses with bids are saved.
+func (deps *endpointDeps) holdCachedAuction(ctx context.Context, auctionRequest exchange.AuctionRequest, debugLog *exchange.DebugLog) (*openrtb2.BidResponse, error) {
+	req := auctionRequest.BidRequest
+	if deps.auctionResponseCache == nil || req.Test == 1 || (debugLog != nil && debugLog.DebugEnabledOrOverridden) {
+		return deps.ex.HoldAuction(ctx, auctionRequest, debugLog)
+	}
+
+	// The key is computed before the auction, since the exchange may modify the request
+	key, err := auctionResponseCacheKey(auctionRequest.RequestType, auctionRequest.Account.ID, req)
+	if err != nil {
+		return deps.ex.HoldAuction(ctx, auctionRequest, debugLog)
+	}
+
+	auctionTimestampMs := auctionRequest.StartTime.UnixNano() / 1e+6
+	if cached, ok := deps.auctionResponseCache.Get(key); ok {
+		response, err := reuseAuctionResponse(cached, req, &auctionRequest.Account, deps.cfg.ExternalURL, auctionTimestampMs)
+		// A response whose bids can't be cached again would point the publisher at expired cache entries
+		if err == nil {
+			err = recacheAuctionResponse(ctx, deps.cache, response, req, &auctionRequest.Account.CacheTTL)
+		}
+		if err == nil {
+			if auctionRequest.SeatDetails != nil {
+				*auctionRequest.SeatDetails = makeReusedSeatDetails(response)
+			}
+			// The hooks see the response as it was returned by the original auction, with its refreshed IDs
+			if auctionRequest.HookExecutor != nil {
+				auctionRequest.HookExecutor.ExecuteAuctionResponseStage(response)
+			}
+			return response, nil
+		}
+	}
+
+	response, err := deps.ex.HoldAuction(ctx, auctionRequest, debugLog)
+	if err == nil && response != nil && len(response.SeatBid) > 0 {
+		deps.auctionResponseCache.Save(key, &cachedAuctionResponse{
+			Response:           response,
+			AuctionTimestampMs: auctionTimestampMs,
+		})
+	}
+	return response, err
+}
+
+// auctionResponseCacheKey hashes the fully resolved BidRequest into the key under which its response is cached.
+// Identifiers which vary across users and devices, as well as the generated request and transaction IDs, are
+// ignored so that identical ad requests share a response. The GDPR consent string is kept, since it decides
+// which bidders may take part in the auction.
+func auctionResponseCacheKey(requestType metrics.RequestType, accountID string, req *openrtb2.BidRequest) (string, error) {
+	normalized := *req
+	normalized.ID = ""
+
+	if req.Source != nil {
+		source := *req.Source
+		source.TID = ""
+		normalized.Source = &source
+	}
+
+	if req.User != nil {
+		user := *req.User
+		user.ID = ""
+		user.BuyerUID = ""
+		user.Ext = nil
+		if consent, err := jsonparser.GetString(req.User.Ext, "consent"); err == nil && consent != "" {
+			userExt, err := json.Marshal(openrtb_ext.ExtUser{Consent: consent})
+			if err != nil {
+				return "", err
+			}
+			user.Ext = userExt
+		}
+		normalized.User = &user
+	}
+
+	if req.Device != nil {
+		device := *req.Device
+		device.IP = ""
+		device.IPv6 = ""
+		device.IFA = ""
+		device.DIDSHA1 = ""
+		device.DIDMD5 = ""
+		device.DPIDSHA1 = ""
+		device.DPIDMD5 = ""
+		device.MACSHA1 = ""
+		device.MACMD5 = ""
+		normalized.Device = &device
+	}
+
+	reqJSON, err := json.Marshal(&normalized)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(requestType))
+	hash.Write([]byte{0})
+	hash.Write([]byte(accountID))
+	hash.Write([]byte{0})
+	hash.Write(reqJSON)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// reuseAuctionResponse prepares a cached response to answer the given request. The targeting isn't computed
+// again: it only depends on the bids and the request, which are identical for a reused response, apart from the
+// cache IDs replaced by recacheAuctionResponse once the bids are stored in prebid cache again. The bid IDs
+// generated by the exchange (ext.prebid.bidid) are generated anew, and the event URLs embedded in the bids are
+// regenerated with them and the timestamp of the new auction. The bids without a generated ID keep the ID set by
+// their bidder.
+func reuseAuctionResponse(cached *cachedAuctionResponse, req *openrtb2.BidRequest, account *config.Account, externalURL string, auctionTimestampMs int64) (*openrtb2.BidResponse, error) {
+	response := cached.Response
+	response.ID = req.ID
+
+	for i := range response.SeatBid {
+		bidder := response.SeatBid[i].Seat
+		for j := range response.SeatBid[i].Bid {
+			bid := &response.SeatBid[i].Bid[j]
+
+			var bidExt openrtb_ext.ExtBid
+			if err := json.Unmarshal(bid.Ext, &bidExt); err != nil || bidExt.Prebid == nil {
+				continue
+			}
+			oldBidID, newBidID := bid.ID, bid.ID
+			prebidPatch := make(map[string]interface{}, 2)
+			if len(bidExt.Prebid.BidId) > 0 {
+				rawUUID, err := uuid.NewV4()
+				if err != nil {
+					return nil, err
+				}
+				oldBidID, newBidID = bidExt.Prebid.BidId, rawUUID.String()
+				prebidPatch["bidid"] = newBidID
+			}
+
+			if bidExt.Prebid.Type == openrtb_ext.BidTypeVideo {
+				oldURL := events.GetVastUrlTracking(externalURL, oldBidID, bidder, account.ID, cached.AuctionTimestampMs)
+				newURL := events.GetVastUrlTracking(externalURL, newBidID, bidder, account.ID, auctionTimestampMs)
+				bid.AdM = strings.Replace(bid.AdM, oldURL, newURL, 1)
+			}
+
+			if bidExt.Prebid.Events != nil {
+				prebidPatch["events"] = openrtb_ext.ExtBidPrebidEvents{
+					Win: makeAuctionEventURL(analytics.Win, externalURL, newBidID, bidder, account.ID, auctionTimestampMs),
+					Imp: makeAuctionEventURL(analytics.Imp, externalURL, newBidID, bidder, account.ID, auctionTimestampMs),
+				}
+			}
+
+			if len(prebidPatch) == 0 {
+				continue
+			}
+			patch, err := json.Marshal(map[string]interface{}{"prebid": prebidPatch})
+			if err != nil {
+				return nil, err
+			}
+			ext, err := jsonpatch.MergePatch(bid.Ext, patch)
+			if err != nil {
+				return nil, err
+			}
+			bid.Ext = ext
+		}
+	}
+	return response, nil
+}
+
+// makeReusedSeatDetails describes the seats of a reused response for the analytics. No request was sent to the
+// bidders, so only their bids and the targeting of the bids are known.
+func makeReusedSeatDetails(response *openrtb2.BidResponse) []*analytics.SeatDetails {
+	seatDetails := make([]*analytics.SeatDetails, 0, len(response.SeatBid))
+	for i := range response.SeatBid {
+		details := &analytics.SeatDetails{
+			Bidder: openrtb_ext.BidderName(response.SeatBid[i].Seat),
+			Bids:   make([]*openrtb2.Bid, 0, len(response.SeatBid[i].Bid)),
+		}
+		for j := range response.SeatBid[i].Bid {
+			bid := &response.SeatBid[i].Bid[j]
+			details.Bids = append(details.Bids, bid)
+
+			var bidExt openrtb_ext.ExtBid
+			if err := json.Unmarshal(bid.Ext, &bidExt); err != nil || bidExt.Prebid == nil || len(bidExt.Prebid.Targeting) == 0 {
+				continue
+			}
+			if details.Targeting == nil {
+				details.Targeting = make(map[string]map[string]string)
+			}
+			details.Targeting[bid.ID] = bidExt.Prebid.Targeting
+		}
+		seatDetails = append(seatDetails, details)
+	}
+	return seatDetails
+}
+
+// reusedCacheEntry is a bid of a reused response which was stored in prebid cache by the original auction.
+type reusedCacheEntry struct {
+	bid   *openrtb2.Bid
+	oldID string
+	// custom is set for VAST stored under a "<category>_<hb_cache_id>" key due to competitive exclusion.
+	custom bool
+}
+
+// recacheAuctionResponse stores the bids of a reused response in prebid cache again, as the entries of the
+// original auction may have already expired, and replaces their cache IDs in the hb_cache_id and hb_uuid
+// targeting keys and in ext.prebid.cache. The remaining targeting keys only depend on the bids and the request,
+// which are identical for a reused response, so they're kept.
+func recacheAuctionResponse(ctx context.Context, cache prebid_cache_client.Client, response *openrtb2.BidResponse, req *openrtb2.BidRequest, defaultTTLs *config.DefaultTTLs) error {
+	expByImp := make(map[string]int64, len(req.Imp))
+	for _, imp := range req.Imp {
+		expByImp[imp.ID] = imp.Exp
+	}
+
+	var toCache []prebid_cache_client.Cacheable
+	var entries []reusedCacheEntry
+	var hbCacheID string
+	for i := range response.SeatBid {
+		for j := range response.SeatBid[i].Bid {
+			bid := &response.SeatBid[i].Bid[j]
+
+			var bidExt openrtb_ext.ExtBid
+			if err := json.Unmarshal(bid.Ext, &bidExt); err != nil || bidExt.Prebid == nil {
+				continue
+			}
+			bidsID, vastID, catDur := findReusedCacheIDs(bidExt.Prebid)
+			ttl := exchange.BidCacheTTL(expByImp[bid.ImpID], bid.Exp, bidExt.Prebid.Type, defaultTTLs)
+
+			if bidsID != "" {
+				bidJSON, err := json.Marshal(bid)
+				if err != nil {
+					return err
+				}
+				toCache = append(toCache, prebid_cache_client.Cacheable{
+					Type:       prebid_cache_client.TypeJSON,
+					Data:       bidJSON,
+					TTLSeconds: ttl,
+				})
+				entries = append(entries, reusedCacheEntry{bid: bid, oldID: bidsID})
+			}
+			if vastID != "" {
+				vastJSON, err := json.Marshal(exchange.MakeVAST(bid))
+				if err != nil {
+					return err
+				}
+				cacheable := prebid_cache_client.Cacheable{
+					Type:       prebid_cache_client.TypeXML,
+					Data:       vastJSON,
+					TTLSeconds: ttl,
+				}
+				if catDur != "" {
+					if hbCacheID == "" {
+						rawUUID, err := uuid.NewV4()
+						if err != nil {
+							return err
+						}
+						hbCacheID = rawUUID.String()
+					}
+					cacheable.Key = catDur + "_" + hbCacheID
+				}
+				toCache = append(toCache, cacheable)
+				entries = append(entries, reusedCacheEntry{bid: bid, oldID: vastID, custom: catDur != ""})
+			}
+		}
+	}
+	if len(toCache) == 0 {
+		return nil
+	}
+
+	ids, errs := cache.PutJson(ctx, toCache)
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	if len(ids) != len(toCache) {
+		return errors.New("prebid cache didn't return an ID for every bid")
+	}
+
+	newIDs := make(map[*openrtb2.Bid]map[string]string, len(entries))
+	for i, entry := range entries {
+		if ids[i] == "" {
+			return errors.New("prebid cache didn't return an ID for every bid")
+		}
+		if newIDs[entry.bid] == nil {
+			newIDs[entry.bid] = make(map[string]string, 2)
+		}
+		if entry.custom {
+			// the targeting only holds the hb_cache_id portion of the custom key
+			newIDs[entry.bid][entry.oldID] = hbCacheID
+		} else {
+			newIDs[entry.bid][entry.oldID] = ids[i]
+		}
+	}
+	for bid, bidIDs := range newIDs {
+		if err := replaceCacheIDs(bid, bidIDs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findReusedCacheIDs returns the IDs under which the original auction cached the bid JSON and the VAST XML,
+// along with the category and duration of the bid when its VAST was cached under a custom key.
+func findReusedCacheIDs(prebid *openrtb_ext.ExtBidPrebid) (bidsID, vastID, catDur string) {
+	for key, value := range prebid.Targeting {
+		switch {
+		case strings.HasPrefix(key, string(openrtb_ext.HbCacheKey)):
+			bidsID = value
+		case strings.HasPrefix(key, string(openrtb_ext.HbVastCacheKey)):
+			vastID = value
+		case strings.HasPrefix(key, string(openrtb_ext.HbCategoryDurationKey)):
+			catDur = value
+		}
+	}
+	if bidsID == "" && vastID == "" && prebid.Cache != nil && prebid.Cache.Bids != nil {
+		bidsID = prebid.Cache.Bids.CacheId
+	}
+	return
+}
+
+// replaceCacheIDs swaps the old cache IDs of the bid for the new ones in its targeting and ext.prebid.cache.
+func replaceCacheIDs(bid *openrtb2.Bid, newIDs map[string]string) error {
+	var bidExt openrtb_ext.ExtBid
+	if err := json.Unmarshal(bid.Ext, &bidExt); err != nil {
+		return err
+	}
+
+	targeting := make(map[string]string)
+	for key, value := range bidExt.Prebid.Targeting {
+		if newID, ok := newIDs[value]; ok {
+			targeting[key] = newID
+		}
+	}
+	prebidPatch := map[string]interface{}{
+		"targeting": targeting,
+	}
+	if bidExt.Prebid.Cache != nil && bidExt.Prebid.Cache.Bids != nil {
+		if newID, ok := newIDs[bidExt.Prebid.Cache.Bids.CacheId]; ok {
+			prebidPatch["cache"] = map[string]interface{}{
+				"bids": openrtb_ext.ExtBidPrebidCacheBids{
+					Url:     strings.Replace(bidExt.Prebid.Cache.Bids.Url, bidExt.Prebid.Cache.Bids.CacheId, newID, 1),
+					CacheId: newID,
+				},
+			}
+		}
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{"prebid": prebidPatch})
+	if err != nil {
+		return err
+	}
+	ext, err := jsonpatch.MergePatch(bid.Ext, patch)
+	if err != nil {
+		return err
+	}
+	bid.Ext = ext
+	return nil
+}
+
+func makeAuctionEventURL(evType analytics.EventType, externalURL, bidID, bidder, accountID string, auctionTimestampMs int64) string {
+	return events.EventRequestToUrl(externalURL,
+		&analytics.EventRequest{
+			Type:      evType,
+			BidID:     bidID,
+			Bidder:    bidder,
+			AccountID: accountID,
+			Timestamp: auctionTimestampMs,
+		})
+}
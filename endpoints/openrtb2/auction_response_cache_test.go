@@ -0,0 +1,399 @@
+package openrtb2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/analytics"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/exchange"
+	"github.com/prebid/prebid-server/hooks/hookexecution"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/prebid_cache_client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuctionResponseCacheKey(t *testing.T) {
+	baseRequest := func() *openrtb2.BidRequest {
+		return &openrtb2.BidRequest{
+			ID:     "request-1",
+			Imp:    []openrtb2.Imp{{ID: "imp-1", TagID: "tag-1"}},
+			Source: &openrtb2.Source{TID: "tid-1"},
+			User:   &openrtb2.User{ID: "user-1", BuyerUID: "buyer-1", Ext: json.RawMessage(`{"consent":"BONV8oqONXwgmADACHENAO7pqzAAppY","eids":[{"source":"a"}]}`)},
+			Device: &openrtb2.Device{IP: "1.2.3.4", IFA: "ifa-1", UA: "ua"},
+		}
+	}
+	baseKey, err := auctionResponseCacheKey(metrics.ReqTypeAMP, "account-1", baseRequest())
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		description   string
+		requestType   metrics.RequestType
+		accountID     string
+		modifyRequest func(req *openrtb2.BidRequest)
+		expectSameKey bool
+	}{
+		{
+			description:   "Identical",
+			requestType:   metrics.ReqTypeAMP,
+			accountID:     "account-1",
+			modifyRequest: func(req *openrtb2.BidRequest) {},
+			expectSameKey: true,
+		},
+		{
+			description: "Different user and device identifiers",
+			requestType: metrics.ReqTypeAMP,
+			accountID:   "account-1",
+			modifyRequest: func(req *openrtb2.BidRequest) {
+				req.ID = "request-2"
+				req.Source.TID = "tid-2"
+				req.User.ID = "user-2"
+				req.User.BuyerUID = "buyer-2"
+				req.User.Ext = json.RawMessage(`{"consent":"BONV8oqONXwgmADACHENAO7pqzAAppY"}`)
+				req.Device.IP = "5.6.7.8"
+				req.Device.IFA = "ifa-2"
+			},
+			expectSameKey: true,
+		},
+		{
+			description: "Different consent",
+			requestType: metrics.ReqTypeAMP,
+			accountID:   "account-1",
+			modifyRequest: func(req *openrtb2.BidRequest) {
+				req.User.Ext = json.RawMessage(`{"consent":"other"}`)
+			},
+			expectSameKey: false,
+		},
+		{
+			description: "Different imp",
+			requestType: metrics.ReqTypeAMP,
+			accountID:   "account-1",
+			modifyRequest: func(req *openrtb2.BidRequest) {
+				req.Imp[0].TagID = "tag-2"
+			},
+			expectSameKey: false,
+		},
+		{
+			description:   "Different request type",
+			requestType:   metrics.ReqTypeVideo,
+			accountID:     "account-1",
+			modifyRequest: func(req *openrtb2.BidRequest) {},
+			expectSameKey: false,
+		},
+		{
+			description:   "Different account",
+			requestType:   metrics.ReqTypeAMP,
+			accountID:     "account-2",
+			modifyRequest: func(req *openrtb2.BidRequest) {},
+			expectSameKey: false,
+		},
+	}
+
+	for _, test := range testCases {
+		req := baseRequest()
+		test.modifyRequest(req)
+		key, err := auctionResponseCacheKey(test.requestType, test.accountID, req)
+		assert.NoError(t, err, test.description)
+		if test.expectSameKey {
+			assert.Equal(t, baseKey, key, test.description)
+		} else {
+			assert.NotEqual(t, baseKey, key, test.description)
+		}
+	}
+}
+
+func TestReuseAuctionResponse(t *testing.T) {
+	cached := &cachedAuctionResponse{
+		Response: &openrtb2.BidResponse{
+			ID: "request-1",
+			SeatBid: []openrtb2.SeatBid{{
+				Seat: "appnexus",
+				Bid: []openrtb2.Bid{
+					{
+						ID:  "bid-1",
+						Ext: json.RawMessage(`{"prebid":{"type":"banner","targeting":{"hb_pb":"1.00"},"events":{"win":"http://pbs/event?t=win&b=bid-1&a=account-1&ts=1000&bidder=appnexus","imp":"http://pbs/event?t=imp&b=bid-1&a=account-1&ts=1000&bidder=appnexus"}}}`),
+					},
+					{
+						ID:  "bid-2",
+						AdM: `<VAST version="3.0"><Ad><Wrapper><Impression><![CDATA[http://pbs/event?t=imp&b=generated-2&a=account-1&bidder=appnexus&f=b&ts=1000]]></Impression></Wrapper></Ad></VAST>`,
+						Ext: json.RawMessage(`{"prebid":{"type":"video","bidid":"generated-2"}}`),
+					},
+				},
+			}},
+		},
+		AuctionTimestampMs: 1000,
+	}
+
+	response, err := reuseAuctionResponse(cached, &openrtb2.BidRequest{ID: "request-2"}, &config.Account{ID: "account-1"}, "http://pbs", 2000)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "request-2", response.ID)
+	assert.JSONEq(t, `{"prebid":{"type":"banner","targeting":{"hb_pb":"1.00"},"events":{"win":"http://pbs/event?t=win&b=bid-1&a=account-1&bidder=appnexus&ts=2000","imp":"http://pbs/event?t=imp&b=bid-1&a=account-1&bidder=appnexus&ts=2000"}}}`, string(response.SeatBid[0].Bid[0].Ext))
+
+	var bidExt openrtb_ext.ExtBid
+	assert.NoError(t, json.Unmarshal(response.SeatBid[0].Bid[1].Ext, &bidExt))
+	newBidID := bidExt.Prebid.BidId
+	assert.NotEmpty(t, newBidID)
+	assert.NotEqual(t, "generated-2", newBidID)
+	assert.Equal(t, `<VAST version="3.0"><Ad><Wrapper><Impression><![CDATA[http://pbs/event?t=imp&b=`+newBidID+`&a=account-1&bidder=appnexus&f=b&ts=2000]]></Impression></Wrapper></Ad></VAST>`, response.SeatBid[0].Bid[1].AdM)
+	assert.Equal(t, "bid-2", response.SeatBid[0].Bid[1].ID)
+}
+
+func TestMakeReusedSeatDetails(t *testing.T) {
+	response := &openrtb2.BidResponse{
+		SeatBid: []openrtb2.SeatBid{
+			{
+				Seat: "appnexus",
+				Bid: []openrtb2.Bid{
+					{ID: "bid-1", Ext: json.RawMessage(`{"prebid":{"type":"banner","targeting":{"hb_pb":"1.00"}}}`)},
+					{ID: "bid-2", Ext: json.RawMessage(`{"prebid":{"type":"banner"}}`)},
+				},
+			},
+			{
+				Seat: "rubicon",
+				Bid:  []openrtb2.Bid{{ID: "bid-3"}},
+			},
+		},
+	}
+
+	seatDetails := makeReusedSeatDetails(response)
+
+	if assert.Len(t, seatDetails, 2) {
+		assert.Equal(t, openrtb_ext.BidderName("appnexus"), seatDetails[0].Bidder)
+		assert.Equal(t, []*openrtb2.Bid{&response.SeatBid[0].Bid[0], &response.SeatBid[0].Bid[1]}, seatDetails[0].Bids)
+		assert.Equal(t, map[string]map[string]string{"bid-1": {"hb_pb": "1.00"}}, seatDetails[0].Targeting)
+		assert.Nil(t, seatDetails[0].Request)
+		assert.Equal(t, openrtb_ext.BidderName("rubicon"), seatDetails[1].Bidder)
+		assert.Equal(t, []*openrtb2.Bid{&response.SeatBid[1].Bid[0]}, seatDetails[1].Bids)
+		assert.Nil(t, seatDetails[1].Targeting)
+	}
+}
+
+func TestHoldCachedAuction(t *testing.T) {
+	testCases := []struct {
+		description           string
+		cacheEnabled          bool
+		test                  int8
+		noBids                bool
+		expectedAuctionsCount int
+	}{
+		{
+			description:           "Cache disabled",
+			cacheEnabled:          false,
+			expectedAuctionsCount: 2,
+		},
+		{
+			description:           "Cache enabled - Response reused",
+			cacheEnabled:          true,
+			expectedAuctionsCount: 1,
+		},
+		{
+			description:           "Cache enabled - Test request",
+			cacheEnabled:          true,
+			test:                  1,
+			expectedAuctionsCount: 2,
+		},
+		{
+			description:           "Cache enabled - No bids",
+			cacheEnabled:          true,
+			noBids:                true,
+			expectedAuctionsCount: 2,
+		},
+	}
+
+	for _, test := range testCases {
+		ex := &countingExchange{noBids: test.noBids}
+		deps := &endpointDeps{
+			ex:                   ex,
+			cfg:                  &config.Configuration{},
+			auctionResponseCache: newAuctionResponseCache(config.AuctionResponseCache{Enabled: test.cacheEnabled, TTLSeconds: 60, SizeBytes: 1024 * 1024}),
+		}
+
+		for i, requestID := range []string{"request-1", "request-2"} {
+			hookExecutor := &recordingHookExecutor{}
+			var seatDetails []*analytics.SeatDetails
+			auctionRequest := exchange.AuctionRequest{
+				BidRequest:   &openrtb2.BidRequest{ID: requestID, Test: test.test, Imp: []openrtb2.Imp{{ID: "imp-1", TagID: "tag-1"}}},
+				Account:      config.Account{ID: "account-1"},
+				RequestType:  metrics.ReqTypeAMP,
+				StartTime:    time.Now(),
+				HookExecutor: hookExecutor,
+				SeatDetails:  &seatDetails,
+			}
+			response, err := deps.holdCachedAuction(context.Background(), auctionRequest, nil)
+			assert.NoError(t, err, test.description)
+			assert.Equal(t, requestID, response.ID, test.description)
+
+			if i == 1 && ex.auctionsCount == 1 {
+				assert.Equal(t, []*openrtb2.BidResponse{response}, hookExecutor.auctionResponses, test.description+":hooks")
+				if assert.Len(t, seatDetails, 1, test.description+":seat_details") {
+					assert.Equal(t, openrtb_ext.BidderName("appnexus"), seatDetails[0].Bidder, test.description+":seat_details")
+					assert.Len(t, seatDetails[0].Bids, 1, test.description+":seat_details")
+				}
+			}
+		}
+		assert.Equal(t, test.expectedAuctionsCount, ex.auctionsCount, test.description)
+	}
+}
+
+func TestHoldCachedAuctionRecache(t *testing.T) {
+	testCases := []struct {
+		description           string
+		cacheErr              error
+		expectedAuctionsCount int
+		expectedTargeting     []string
+	}{
+		{
+			description:           "Reused response gets fresh cache targeting",
+			expectedAuctionsCount: 1,
+			expectedTargeting:     []string{`{"hb_pb":"1.00","hb_cache_id":"auction-1"}`, `{"hb_pb":"1.00","hb_cache_id":"recached-1"}`},
+		},
+		{
+			description:           "Failed recache runs a fresh auction",
+			cacheErr:              errors.New("cache unavailable"),
+			expectedAuctionsCount: 2,
+			expectedTargeting:     []string{`{"hb_pb":"1.00","hb_cache_id":"auction-1"}`, `{"hb_pb":"1.00","hb_cache_id":"auction-2"}`},
+		},
+	}
+
+	for _, test := range testCases {
+		ex := &countingExchange{cacheTargeting: true}
+		deps := &endpointDeps{
+			ex:                   ex,
+			cfg:                  &config.Configuration{},
+			cache:                &recordingCacheClient{err: test.cacheErr},
+			auctionResponseCache: newAuctionResponseCache(config.AuctionResponseCache{Enabled: true, TTLSeconds: 60, SizeBytes: 1024 * 1024}),
+		}
+
+		for i, requestID := range []string{"request-1", "request-2"} {
+			auctionRequest := exchange.AuctionRequest{
+				BidRequest:  &openrtb2.BidRequest{ID: requestID, Imp: []openrtb2.Imp{{ID: "imp-1", TagID: "tag-1"}}},
+				Account:     config.Account{ID: "account-1"},
+				RequestType: metrics.ReqTypeAMP,
+				StartTime:   time.Now(),
+			}
+			response, err := deps.holdCachedAuction(context.Background(), auctionRequest, nil)
+			assert.NoError(t, err, test.description)
+
+			var bidExt openrtb_ext.ExtBid
+			assert.NoError(t, json.Unmarshal(response.SeatBid[0].Bid[0].Ext, &bidExt), test.description)
+			targeting, _ := json.Marshal(bidExt.Prebid.Targeting)
+			assert.JSONEq(t, test.expectedTargeting[i], string(targeting), test.description)
+		}
+		assert.Equal(t, test.expectedAuctionsCount, ex.auctionsCount, test.description)
+	}
+}
+
+func TestRecacheAuctionResponse(t *testing.T) {
+	response := &openrtb2.BidResponse{
+		SeatBid: []openrtb2.SeatBid{{
+			Seat: "appnexus",
+			Bid: []openrtb2.Bid{
+				{
+					ID:    "bid-1",
+					ImpID: "imp-1",
+					Ext:   json.RawMessage(`{"prebid":{"type":"banner","targeting":{"hb_pb":"1.00","hb_cache_id":"old-1","hb_cache_id_appnexus":"old-1"},"cache":{"key":"","url":"","bids":{"url":"https://cache.com/cache?uuid=old-1","cacheId":"old-1"}}},"bidder":{"a":1}}`),
+				},
+				{
+					ID:    "bid-2",
+					ImpID: "imp-2",
+					AdM:   "<VAST></VAST>",
+					Exp:   100,
+					Ext:   json.RawMessage(`{"prebid":{"type":"video","targeting":{"hb_pb_cat_dur":"10.00_sports_30s","hb_uuid":"old-2"}}}`),
+				},
+				{
+					ID:    "bid-3",
+					ImpID: "imp-1",
+					Ext:   json.RawMessage(`{"prebid":{"type":"banner","targeting":{"hb_pb":"0.50"}}}`),
+				},
+			},
+		}},
+	}
+	req := &openrtb2.BidRequest{Imp: []openrtb2.Imp{{ID: "imp-1"}, {ID: "imp-2"}}}
+	cache := &recordingCacheClient{}
+
+	err := recacheAuctionResponse(context.Background(), cache, response, req, &config.DefaultTTLs{Banner: 300})
+	assert.NoError(t, err)
+
+	if assert.Len(t, cache.values, 2) {
+		assert.Equal(t, prebid_cache_client.TypeJSON, cache.values[0].Type)
+		assert.Equal(t, int64(360), cache.values[0].TTLSeconds)
+		assert.Empty(t, cache.values[0].Key)
+		assert.Equal(t, prebid_cache_client.TypeXML, cache.values[1].Type)
+		assert.JSONEq(t, `"<VAST></VAST>"`, string(cache.values[1].Data))
+		assert.Equal(t, int64(160), cache.values[1].TTLSeconds)
+		assert.True(t, strings.HasPrefix(cache.values[1].Key, "10.00_sports_30s_"))
+	}
+	assert.JSONEq(t, `{"prebid":{"type":"banner","targeting":{"hb_pb":"1.00","hb_cache_id":"recached-1","hb_cache_id_appnexus":"recached-1"},"cache":{"key":"","url":"","bids":{"url":"https://cache.com/cache?uuid=recached-1","cacheId":"recached-1"}}},"bidder":{"a":1}}`, string(response.SeatBid[0].Bid[0].Ext))
+
+	hbCacheID := strings.TrimPrefix(cache.values[1].Key, "10.00_sports_30s_")
+	assert.JSONEq(t, `{"prebid":{"type":"video","targeting":{"hb_pb_cat_dur":"10.00_sports_30s","hb_uuid":"`+hbCacheID+`"}}}`, string(response.SeatBid[0].Bid[1].Ext))
+	assert.JSONEq(t, `{"prebid":{"type":"banner","targeting":{"hb_pb":"0.50"}}}`, string(response.SeatBid[0].Bid[2].Ext))
+}
+
+type countingExchange struct {
+	auctionsCount  int
+	noBids         bool
+	cacheTargeting bool
+}
+
+func (e *countingExchange) HoldAuction(ctx context.Context, r exchange.AuctionRequest, debugLog *exchange.DebugLog) (*openrtb2.BidResponse, error) {
+	e.auctionsCount++
+	response := &openrtb2.BidResponse{ID: r.BidRequest.ID}
+	if !e.noBids {
+		bid := openrtb2.Bid{ID: "bid-1", ImpID: "imp-1", Price: 1}
+		if e.cacheTargeting {
+			bid.Ext = json.RawMessage(fmt.Sprintf(`{"prebid":{"type":"banner","targeting":{"hb_pb":"1.00","hb_cache_id":"auction-%d"}}}`, e.auctionsCount))
+		}
+		response.SeatBid = []openrtb2.SeatBid{{
+			Seat: "appnexus",
+			Bid:  []openrtb2.Bid{bid},
+		}}
+	}
+	return response, nil
+}
+
+// recordingHookExecutor records the responses given to the auction response stage.
+type recordingHookExecutor struct {
+	hookexecution.EmptyHookExecutor
+	auctionResponses []*openrtb2.BidResponse
+}
+
+func (e *recordingHookExecutor) ExecuteAuctionResponseStage(response *openrtb2.BidResponse) {
+	e.auctionResponses = append(e.auctionResponses, response)
+}
+
+// recordingCacheClient mimics prebid cache, which stores the values under their custom keys when given.
+type recordingCacheClient struct {
+	values []prebid_cache_client.Cacheable
+	err    error
+}
+
+func (c *recordingCacheClient) PutJson(ctx context.Context, values []prebid_cache_client.Cacheable) ([]string, []error) {
+	if c.err != nil {
+		return nil, []error{c.err}
+	}
+	ids := make([]string, len(values))
+	for i, value := range values {
+		c.values = append(c.values, value)
+		if value.Key != "" {
+			ids[i] = value.Key
+		} else {
+			ids[i] = fmt.Sprintf("recached-%d", len(c.values))
+		}
+	}
+	return ids, nil
+}
+
+func (c *recordingCacheClient) GetExtCacheData() (scheme string, host string, path string) {
+	return "", "", ""
+}
@@ -27,10 +27,13 @@ import (
 	"github.com/prebid/prebid-server/currency"
 	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/exchange"
+	"github.com/prebid/prebid-server/hooks"
 	"github.com/prebid/prebid-server/metrics"
+	metricsConfig "github.com/prebid/prebid-server/metrics/config"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/stored_requests"
 	"github.com/prebid/prebid-server/stored_requests/backends/empty_fetcher"
+	"github.com/prebid/prebid-server/stored_responses"
 	"github.com/prebid/prebid-server/util/iputil"
 	"github.com/stretchr/testify/assert"
 )
@@ -436,10 +439,10 @@ func TestExplicitUserId(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		cfg,
 		newTestMetrics(),
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
 		map[string]string{},
 		[]byte{},
-		openrtb_ext.BuildBidderMap())
+		openrtb_ext.BuildBidderMap(), hooks.EmptyPlanBuilder{})
 
 	endpoint(httptest.NewRecorder(), request, nil)
 
@@ -477,10 +480,10 @@ func doRequest(t *testing.T, test testCase) (int, string) {
 			AccountRequired:    test.Config.AccountRequired,
 		},
 		newTestMetrics(),
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
 		disabledBidders,
 		[]byte(test.Config.AliasJSON),
-		bidderMap)
+		bidderMap, hooks.EmptyPlanBuilder{})
 
 	request := httptest.NewRequest("POST", "/openrtb2/auction", bytes.NewReader(test.BidRequest))
 	recorder := httptest.NewRecorder()
@@ -540,10 +543,10 @@ func doBadAliasRequest(t *testing.T, filename string, expectMsg string) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		newTestMetrics(),
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
 		disabledBidders,
 		aliasJSON,
-		bidderMap)
+		bidderMap, hooks.EmptyPlanBuilder{})
 
 	request := httptest.NewRequest("POST", "/openrtb2/auction", bytes.NewReader(testBidRequest))
 	recorder := httptest.NewRecorder()
@@ -590,9 +593,9 @@ func TestNilExchange(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		newTestMetrics(),
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}), map[string]string{},
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}), map[string]string{},
 		[]byte{},
-		openrtb_ext.BuildBidderMap())
+		openrtb_ext.BuildBidderMap(), hooks.EmptyPlanBuilder{})
 
 	if err == nil {
 		t.Errorf("NewEndpoint should return an error when given a nil Exchange.")
@@ -610,10 +613,10 @@ func TestNilValidator(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		newTestMetrics(),
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
 		map[string]string{},
 		[]byte{},
-		openrtb_ext.BuildBidderMap())
+		openrtb_ext.BuildBidderMap(), hooks.EmptyPlanBuilder{})
 
 	if err == nil {
 		t.Errorf("NewEndpoint should return an error when given a nil BidderParamValidator.")
@@ -631,10 +634,10 @@ func TestExchangeError(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		newTestMetrics(),
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
 		map[string]string{},
 		[]byte{},
-		openrtb_ext.BuildBidderMap())
+		openrtb_ext.BuildBidderMap(), hooks.EmptyPlanBuilder{})
 
 	request := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, "site.json")))
 	recorder := httptest.NewRecorder()
@@ -753,10 +756,10 @@ func TestImplicitIPsEndToEnd(t *testing.T) {
 			empty_fetcher.EmptyFetcher{},
 			cfg,
 			newTestMetrics(),
-			analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+			analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
 			map[string]string{},
 			[]byte{},
-			openrtb_ext.BuildBidderMap())
+			openrtb_ext.BuildBidderMap(), hooks.EmptyPlanBuilder{})
 
 		httpReq := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, test.reqJSONFile)))
 		httpReq.Header.Set("X-Forwarded-For", test.xForwardedForHeader)
@@ -947,10 +950,10 @@ func TestImplicitDNTEndToEnd(t *testing.T) {
 			empty_fetcher.EmptyFetcher{},
 			&config.Configuration{MaxRequestSize: maxSize},
 			newTestMetrics(),
-			analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+			analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
 			map[string]string{},
 			[]byte{},
-			openrtb_ext.BuildBidderMap())
+			openrtb_ext.BuildBidderMap(), hooks.EmptyPlanBuilder{})
 
 		httpReq := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, test.reqJSONFile)))
 		httpReq.Header.Set("DNT", test.dntHeader)
@@ -1010,7 +1013,7 @@ func TestStoredRequests(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		newTestMetrics(),
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
 		map[string]string{},
 		false,
 		[]byte{},
@@ -1018,10 +1021,12 @@ func TestStoredRequests(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		hooks.EmptyPlanBuilder{},
+		nil,
 	}
 
 	for i, requestData := range testStoredRequests {
-		newRequest, errList := deps.processStoredRequests(context.Background(), json.RawMessage(requestData))
+		newRequest, _, errList := deps.processStoredRequests(context.Background(), json.RawMessage(requestData))
 		if len(errList) != 0 {
 			for _, err := range errList {
 				if err != nil {
@@ -1049,7 +1054,7 @@ func TestOversizedRequest(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: int64(len(reqBody) - 1)},
 		newTestMetrics(),
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
 		map[string]string{},
 		false,
 		[]byte{},
@@ -1057,6 +1062,8 @@ func TestOversizedRequest(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		hooks.EmptyPlanBuilder{},
+		nil,
 	}
 
 	req := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(reqBody))
@@ -1084,7 +1091,7 @@ func TestRequestSizeEdgeCase(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: int64(len(reqBody))},
 		newTestMetrics(),
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
 		map[string]string{},
 		false,
 		[]byte{},
@@ -1092,6 +1099,8 @@ func TestRequestSizeEdgeCase(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		hooks.EmptyPlanBuilder{},
+		nil,
 	}
 
 	req := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(reqBody))
@@ -1117,10 +1126,11 @@ func TestNoEncoding(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		newTestMetrics(),
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
+		hooks.EmptyPlanBuilder{},
 	)
 	request := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, "site.json")))
 	recorder := httptest.NewRecorder()
@@ -1192,10 +1202,11 @@ func TestContentType(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		newTestMetrics(),
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
+		hooks.EmptyPlanBuilder{},
 	)
 	request := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, "site.json")))
 	recorder := httptest.NewRecorder()
@@ -1206,6 +1217,53 @@ func TestContentType(t *testing.T) {
 	}
 }
 
+func TestValidateBidderConfigs(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		bidderConfigs []openrtb_ext.ExtRequestPrebidBidderConfig
+		expectedError error
+	}{
+		{
+			desc:          "nil input, no errors expected",
+			bidderConfigs: nil,
+			expectedError: nil,
+		},
+		{
+			desc: "valid site and user objects, no errors expected",
+			bidderConfigs: []openrtb_ext.ExtRequestPrebidBidderConfig{{
+				Bidders: []string{"appnexus"},
+				Config: &openrtb_ext.ExtBidderConfig{ORTB2: &openrtb_ext.ExtBidderConfigORTB2{
+					Site: json.RawMessage(`{"keywords":"sports"}`),
+					User: json.RawMessage(`{"yob":1980}`),
+				}},
+			}},
+			expectedError: nil,
+		},
+		{
+			desc: "entry without bidders, expect bad input error",
+			bidderConfigs: []openrtb_ext.ExtRequestPrebidBidderConfig{{
+				Config: &openrtb_ext.ExtBidderConfig{},
+			}},
+			expectedError: &errortypes.BadInput{Message: "request.ext.prebid.bidderconfig[0] must define at least one bidder"},
+		},
+		{
+			desc: "app which isn't an object, expect bad input error",
+			bidderConfigs: []openrtb_ext.ExtRequestPrebidBidderConfig{{
+				Bidders: []string{"appnexus"},
+				Config: &openrtb_ext.ExtBidderConfig{ORTB2: &openrtb_ext.ExtBidderConfigORTB2{
+					App: json.RawMessage(`["not","an","object"]`),
+				}},
+			}},
+			expectedError: &errortypes.BadInput{Message: "request.ext.prebid.bidderconfig[0].config.ortb2.app must be an object"},
+		},
+	}
+
+	for _, test := range testCases {
+		err := validateBidderConfigs(test.bidderConfigs)
+		assert.Equal(t, test.expectedError, err, test.desc)
+	}
+}
+
 func TestValidateCustomRates(t *testing.T) {
 	boolTrue := true
 	boolFalse := false
@@ -1512,7 +1570,7 @@ func TestValidateImpExt(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: int64(8096)},
 		newTestMetrics(),
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
 		map[string]string{"disabledbidder": "The bidder 'disabledbidder' has been disabled."},
 		false,
 		[]byte{},
@@ -1520,13 +1578,15 @@ func TestValidateImpExt(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		hooks.EmptyPlanBuilder{},
+		nil,
 	}
 
 	for _, group := range testGroups {
 		for _, test := range group.testCases {
 			imp := &openrtb2.Imp{Ext: test.impExt}
 
-			errs := deps.validateImpExt(imp, nil, 0)
+			errs := deps.validateImpExt(imp, nil, &config.AccountBidders{}, 0)
 
 			if len(test.expectedImpExt) > 0 {
 				assert.JSONEq(t, test.expectedImpExt, string(imp.Ext), "imp.ext JSON does not match expected. Test: %s. %s\n", group.description, test.description)
@@ -1558,7 +1618,7 @@ func TestCurrencyTrunc(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{},
 		newTestMetrics(),
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
 		map[string]string{},
 		false,
 		[]byte{},
@@ -1566,6 +1626,8 @@ func TestCurrencyTrunc(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		hooks.EmptyPlanBuilder{},
+		nil,
 	}
 
 	ui := int64(1)
@@ -1587,12 +1649,102 @@ func TestCurrencyTrunc(t *testing.T) {
 		Cur: []string{"USD", "EUR"},
 	}
 
-	errL := deps.validateRequest(&req)
+	errL := deps.validateRequest(&req, &config.Account{})
 
 	expectedError := errortypes.Warning{Message: "A prebid request can only process one currency. Taking the first currency in the list, USD, as the active currency"}
 	assert.ElementsMatch(t, errL, []error{&expectedError})
 }
 
+func TestValidateRequestAccountBidders(t *testing.T) {
+	deps := &endpointDeps{
+		&nobidExchange{},
+		newParamsValidator(t),
+		&mockStoredReqFetcher{},
+		empty_fetcher.EmptyFetcher{},
+		empty_fetcher.EmptyFetcher{},
+		&config.Configuration{},
+		newTestMetrics(),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
+		map[string]string{},
+		false,
+		[]byte{},
+		openrtb_ext.BuildBidderMap(),
+		nil,
+		nil,
+		hardcodedResponseIPValidator{response: true},
+		hooks.EmptyPlanBuilder{},
+		nil,
+	}
+
+	testCases := []struct {
+		description    string
+		impExt         json.RawMessage
+		requestExt     json.RawMessage
+		accountBidders config.AccountBidders
+		expectedErrs   []error
+		expectedImpExt json.RawMessage
+	}{
+		{
+			description:    "Alias defined by the account",
+			impExt:         json.RawMessage(`{"appnexus":{"placementId":5667},"pubalias":{"placementId":5667}}`),
+			accountBidders: config.AccountBidders{Aliases: map[string]string{"pubalias": "appnexus"}},
+			expectedErrs:   []error{},
+			expectedImpExt: json.RawMessage(`{"appnexus":{"placementId":5667},"pubalias":{"placementId":5667}}`),
+		},
+		{
+			description:    "Alias defined by the account to an unknown bidder",
+			impExt:         json.RawMessage(`{"appnexus":{"placementId":5667}}`),
+			accountBidders: config.AccountBidders{Aliases: map[string]string{"pubalias": "unknown"}},
+			expectedErrs:   []error{errors.New("request.ext.prebid.aliases.pubalias refers to unknown bidder: unknown")},
+			expectedImpExt: json.RawMessage(`{"appnexus":{"placementId":5667}}`),
+		},
+		{
+			description:    "Alias of a bidder disabled for the account",
+			impExt:         json.RawMessage(`{"appnexus":{"placementId":5667},"pubalias":{"placementId":5667}}`),
+			requestExt:     json.RawMessage(`{"prebid":{"aliases":{"pubalias":"appnexus"}}}`),
+			accountBidders: config.AccountBidders{Disabled: []string{"pubalias"}},
+			expectedErrs:   []error{&errortypes.BidderTemporarilyDisabled{Message: "The bidder 'pubalias' has been disabled for the account."}},
+			expectedImpExt: json.RawMessage(`{"appnexus":{"placementId":5667}}`),
+		},
+		{
+			description:    "No bidder allowed for the account",
+			impExt:         json.RawMessage(`{"appnexus":{"placementId":5667}}`),
+			accountBidders: config.AccountBidders{Allowed: []string{"rubicon"}},
+			expectedErrs: []error{
+				&errortypes.BidderTemporarilyDisabled{Message: "The bidder 'appnexus' has been disabled for the account."},
+				errors.New("request.imp[0].ext must contain at least one bidder"),
+			},
+			expectedImpExt: json.RawMessage(`{}`),
+		},
+	}
+
+	for _, test := range testCases {
+		ui := int64(1)
+		req := openrtb2.BidRequest{
+			ID: "anyRequestID",
+			Imp: []openrtb2.Imp{
+				{
+					ID: "anyImpID",
+					Banner: &openrtb2.Banner{
+						W: &ui,
+						H: &ui,
+					},
+					Ext: test.impExt,
+				},
+			},
+			Site: &openrtb2.Site{
+				ID: "anySiteID",
+			},
+			Ext: test.requestExt,
+		}
+
+		errL := deps.validateRequest(&req, &config.Account{Bidders: test.accountBidders})
+
+		assert.Equal(t, test.expectedErrs, errL, test.description)
+		assert.JSONEq(t, string(test.expectedImpExt), string(req.Imp[0].Ext), test.description)
+	}
+}
+
 func TestCCPAInvalid(t *testing.T) {
 	deps := &endpointDeps{
 		&nobidExchange{},
@@ -1602,7 +1754,7 @@ func TestCCPAInvalid(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{},
 		newTestMetrics(),
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
 		map[string]string{},
 		false,
 		[]byte{},
@@ -1610,6 +1762,8 @@ func TestCCPAInvalid(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		hooks.EmptyPlanBuilder{},
+		nil,
 	}
 
 	ui := int64(1)
@@ -1633,7 +1787,7 @@ func TestCCPAInvalid(t *testing.T) {
 		},
 	}
 
-	errL := deps.validateRequest(&req)
+	errL := deps.validateRequest(&req, &config.Account{})
 
 	expectedWarning := errortypes.Warning{
 		Message:     "CCPA consent is invalid and will be ignored. (request.regs.ext.us_privacy must contain 4 characters)",
@@ -1652,7 +1806,7 @@ func TestNoSaleInvalid(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{},
 		newTestMetrics(),
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
 		map[string]string{},
 		false,
 		[]byte{},
@@ -1660,6 +1814,8 @@ func TestNoSaleInvalid(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		hooks.EmptyPlanBuilder{},
+		nil,
 	}
 
 	ui := int64(1)
@@ -1684,7 +1840,7 @@ func TestNoSaleInvalid(t *testing.T) {
 		Ext: json.RawMessage(`{"prebid": {"nosale": ["*", "appnexus"]} }`),
 	}
 
-	errL := deps.validateRequest(&req)
+	errL := deps.validateRequest(&req, &config.Account{})
 
 	expectedError := errors.New("request.ext.prebid.nosale is invalid: can only specify all bidders if no other bidders are provided")
 	assert.ElementsMatch(t, errL, []error{expectedError})
@@ -1703,7 +1859,7 @@ func TestValidateSourceTID(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		cfg,
 		newTestMetrics(),
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
 		map[string]string{},
 		false,
 		[]byte{},
@@ -1711,6 +1867,8 @@ func TestValidateSourceTID(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		hooks.EmptyPlanBuilder{},
+		nil,
 	}
 
 	ui := int64(1)
@@ -1731,7 +1889,7 @@ func TestValidateSourceTID(t *testing.T) {
 		},
 	}
 
-	deps.validateRequest(&req)
+	deps.validateRequest(&req, &config.Account{})
 	assert.NotEmpty(t, req.Source.TID, "Expected req.Source.TID to be filled with a randomly generated UID")
 }
 
@@ -1744,7 +1902,7 @@ func TestSChainInvalid(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{},
 		newTestMetrics(),
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
 		map[string]string{},
 		false,
 		[]byte{},
@@ -1752,6 +1910,8 @@ func TestSChainInvalid(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		hooks.EmptyPlanBuilder{},
+		nil,
 	}
 
 	ui := int64(1)
@@ -1773,7 +1933,7 @@ func TestSChainInvalid(t *testing.T) {
 		Ext: json.RawMessage(`{"prebid":{"schains":[{"bidders":["appnexus"],"schain":{"complete":1,"nodes":[{"asi":"directseller1.com","sid":"00001","rid":"BidRequest1","hp":1}],"ver":"1.0"}}, {"bidders":["appnexus"],"schain":{"complete":1,"nodes":[{"asi":"directseller2.com","sid":"00002","rid":"BidRequest2","hp":1}],"ver":"1.0"}}]}}`),
 	}
 
-	errL := deps.validateRequest(&req)
+	errL := deps.validateRequest(&req, &config.Account{})
 
 	expectedError := errors.New("request.ext.prebid.schains contains multiple schains for bidder appnexus; it must contain no more than one per bidder.")
 	assert.ElementsMatch(t, errL, []error{expectedError})
@@ -1963,7 +2123,7 @@ func TestEidPermissionsInvalid(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{},
 		newTestMetrics(),
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
 		map[string]string{},
 		false,
 		[]byte{},
@@ -1971,6 +2131,8 @@ func TestEidPermissionsInvalid(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		hooks.EmptyPlanBuilder{},
+		nil,
 	}
 
 	ui := int64(1)
@@ -1992,7 +2154,7 @@ func TestEidPermissionsInvalid(t *testing.T) {
 		Ext: json.RawMessage(`{"prebid": {"data": {"eidpermissions": [{"source":"a", "bidders":[]}]} } }`),
 	}
 
-	errL := deps.validateRequest(&req)
+	errL := deps.validateRequest(&req, &config.Account{})
 
 	expectedError := errors.New(`request.ext.prebid.data.eidpermissions[0] missing or empty required field: "bidders"`)
 	assert.ElementsMatch(t, errL, []error{expectedError})
@@ -2218,10 +2380,10 @@ func TestIOS14EndToEnd(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		newTestMetrics(),
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
 		map[string]string{},
 		[]byte{},
-		openrtb_ext.BuildBidderMap())
+		openrtb_ext.BuildBidderMap(), hooks.EmptyPlanBuilder{})
 
 	httpReq := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, "app-ios140-no-ifa.json")))
 
@@ -2246,7 +2408,7 @@ func TestAuctionWarnings(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: int64(len(reqBody))},
 		newTestMetrics(),
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
 		map[string]string{},
 		false,
 		[]byte{},
@@ -2254,6 +2416,8 @@ func TestAuctionWarnings(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		hooks.EmptyPlanBuilder{},
+		nil,
 	}
 
 	req := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(reqBody))
@@ -3216,6 +3380,10 @@ func (cf mockStoredReqFetcher) FetchRequests(ctx context.Context, requestIDs []s
 	return testStoredRequestData, testStoredImpData, nil
 }
 
+func (cf mockStoredReqFetcher) FetchResponses(ctx context.Context, ids []string) (data map[string]json.RawMessage, errs []error) {
+	return nil, nil
+}
+
 var mockAccountData = map[string]json.RawMessage{
 	"valid_acct": json.RawMessage(`{"disabled":false}`),
 }
@@ -3231,6 +3399,105 @@ func (af mockAccountFetcher) FetchAccount(ctx context.Context, accountID string)
 	}
 }
 
+func TestAuctionStoredResponses(t *testing.T) {
+	testCases := []struct {
+		description                string
+		impExt                     string
+		disabled                   bool
+		expectedStatus             int
+		expectedAuctionResponses   stored_responses.ImpsWithBidResponses
+		expectedBidderBidResponses stored_responses.BidderImpsWithBidResponses
+	}{
+		{
+			description:    "Stored auction response",
+			impExt:         `{"appnexus":{"placementId":12883451},"prebid":{"storedauctionresponse":{"id":"auction"}}}`,
+			expectedStatus: http.StatusOK,
+			expectedAuctionResponses: stored_responses.ImpsWithBidResponses{
+				"imp-1": json.RawMessage(`[{"seat":"appnexus","bid":[{"id":"bid","price":1}]}]`),
+			},
+		},
+		{
+			description:    "Stored bid response",
+			impExt:         `{"appnexus":{"placementId":12883451},"prebid":{"storedbidresponse":[{"bidder":"appnexus","id":"bid"}]}}`,
+			expectedStatus: http.StatusOK,
+			expectedBidderBidResponses: stored_responses.BidderImpsWithBidResponses{
+				"appnexus": {"imp-1": json.RawMessage(`{"id":"bid-response"}`)},
+			},
+		},
+		{
+			description:    "Unknown stored response",
+			impExt:         `{"appnexus":{"placementId":12883451},"prebid":{"storedauctionresponse":{"id":"unknown"}}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			description:    "Stored responses disabled",
+			impExt:         `{"appnexus":{"placementId":12883451},"prebid":{"storedauctionresponse":{"id":"auction"}}}`,
+			disabled:       true,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range testCases {
+		ex := &storedResponsesExchange{}
+		endpoint, _ := NewEndpoint(
+			ex,
+			newParamsValidator(t),
+			&mockStoredResponsesFetcher{
+				responses: map[string]json.RawMessage{
+					"auction": json.RawMessage(`[{"seat":"appnexus","bid":[{"id":"bid","price":1}]}]`),
+					"bid":     json.RawMessage(`{"id":"bid-response"}`),
+				},
+			},
+			empty_fetcher.EmptyFetcher{},
+			&config.Configuration{MaxRequestSize: maxSize, StoredResponses: config.StoredResponses{Enabled: !test.disabled}},
+			newTestMetrics(),
+			analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
+			map[string]string{},
+			[]byte{},
+			openrtb_ext.BuildBidderMap(),
+			hooks.EmptyPlanBuilder{})
+		body := `{"id":"request","site":{"page":"prebid.org"},"imp":[{"id":"imp-1","banner":{"format":[{"w":300,"h":250}]},"ext":` + test.impExt + `}]}`
+		recorder := httptest.NewRecorder()
+
+		endpoint(recorder, httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(body)), nil)
+
+		if !assert.Equal(t, test.expectedStatus, recorder.Code, test.description+":"+recorder.Body.String()) || test.expectedStatus != http.StatusOK {
+			continue
+		}
+		assert.Equal(t, test.expectedAuctionResponses, ex.lastAuctionRequest.StoredAuctionResponses, test.description+":auction_responses")
+		assert.Equal(t, test.expectedBidderBidResponses, ex.lastAuctionRequest.StoredBidResponses, test.description+":bid_responses")
+	}
+}
+
+type storedResponsesExchange struct {
+	lastAuctionRequest exchange.AuctionRequest
+}
+
+func (e *storedResponsesExchange) HoldAuction(ctx context.Context, r exchange.AuctionRequest, debugLog *exchange.DebugLog) (*openrtb2.BidResponse, error) {
+	e.lastAuctionRequest = r
+	return &openrtb2.BidResponse{ID: r.BidRequest.ID}, nil
+}
+
+type mockStoredResponsesFetcher struct {
+	responses map[string]json.RawMessage
+}
+
+func (f *mockStoredResponsesFetcher) FetchRequests(ctx context.Context, requestIDs []string, impIDs []string) (requestData map[string]json.RawMessage, impData map[string]json.RawMessage, errs []error) {
+	return nil, nil, nil
+}
+
+func (f *mockStoredResponsesFetcher) FetchResponses(ctx context.Context, ids []string) (data map[string]json.RawMessage, errs []error) {
+	data = make(map[string]json.RawMessage, len(ids))
+	for _, id := range ids {
+		if response, ok := f.responses[id]; ok {
+			data[id] = response
+		} else {
+			errs = append(errs, stored_requests.NotFoundError{ID: id, DataType: "Response"})
+		}
+	}
+	return data, errs
+}
+
 type mockExchange struct {
 	lastRequest *openrtb2.BidRequest
 }
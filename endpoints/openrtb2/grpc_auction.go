@@ -0,0 +1,338 @@
+package openrtb2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/buger/jsonparser"
+	"github.com/golang/glog"
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/analytics"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/exchange"
+	"github.com/prebid/prebid-server/hooks"
+	"github.com/prebid/prebid-server/hooks/hookexecution"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/openrtb_proto"
+	"github.com/prebid/prebid-server/stored_requests"
+	"github.com/prebid/prebid-server/stored_requests/backends/empty_fetcher"
+	"github.com/prebid/prebid-server/tracing"
+	"github.com/prebid/prebid-server/usersync"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// NewGRPCEndpoint returns a gRPC server running the auctions of the prebid.AuctionService, whose requests and
+// responses are the IAB OpenRTB protobuf messages described in openrtb_proto/openrtb.proto. It's the gRPC
+// counterpart of NewEndpoint, and takes the same dependencies.
+func NewGRPCEndpoint(
+	ex exchange.Exchange,
+	validator openrtb_ext.BidderParamValidator,
+	requestsById stored_requests.Fetcher,
+	accounts stored_requests.AccountFetcher,
+	cfg *config.Configuration,
+	met metrics.MetricsEngine,
+	pbsAnalytics analytics.PBSAnalyticsModule,
+	disabledBidders map[string]string,
+	defReqJSON []byte,
+	bidderMap map[string]openrtb_ext.BidderName,
+	hookExecutionPlanBuilder hooks.ExecutionPlanBuilder,
+) (*grpc.Server, error) {
+	if ex == nil || validator == nil || requestsById == nil || accounts == nil || cfg == nil || met == nil {
+		return nil, errors.New("NewGRPCEndpoint requires non-nil arguments.")
+	}
+
+	defRequest := defReqJSON != nil && len(defReqJSON) > 0
+
+	ipValidator := hostIPValidator{cfg}
+
+	opts := []grpc.ServerOption{grpc.ForceServerCodec(openrtb_proto.Codec{})}
+	if cfg.MaxRequestSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(int(cfg.MaxRequestSize)))
+	}
+	if cfg.Tracing.Enabled {
+		opts = append(opts, grpc.UnaryInterceptor(tracing.UnaryServerInterceptor))
+	}
+
+	server := grpc.NewServer(opts...)
+	server.RegisterService(&auctionServiceDesc, &endpointDeps{
+		ex,
+		validator,
+		requestsById,
+		empty_fetcher.EmptyFetcher{},
+		accounts,
+		cfg,
+		met,
+		pbsAnalytics,
+		disabledBidders,
+		defRequest,
+		defReqJSON,
+		bidderMap,
+		nil,
+		nil,
+		ipValidator,
+		hookExecutionPlanBuilder,
+		nil})
+	return server, nil
+}
+
+// auctionServer is the handler type of the prebid.AuctionService declared in openrtb_proto/auction_service.proto.
+type auctionServer interface {
+	GRPCAuction(ctx context.Context, req *openrtb2.BidRequest) (*openrtb2.BidResponse, error)
+}
+
+var auctionServiceDesc = grpc.ServiceDesc{
+	ServiceName: "prebid.AuctionService",
+	HandlerType: (*auctionServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Auction",
+			Handler:    auctionHandler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "auction_service.proto",
+}
+
+func auctionHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	req := &openrtb2.BidRequest{}
+	if err := dec(req); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid request: %v", err)
+	}
+	if interceptor == nil {
+		return srv.(auctionServer).GRPCAuction(ctx, req)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/prebid.AuctionService/Auction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(auctionServer).GRPCAuction(ctx, req.(*openrtb2.BidRequest))
+	}
+	return interceptor(ctx, req, info, handler)
+}
+
+// GRPCAuction runs the auction of a request received by the gRPC server, the same way as Auction does for the
+// /openrtb2/auction endpoint. The gRPC metadata stands in for the HTTP headers, e.g. to fill in the device IP or
+// to read the usersync cookie. The entrypoint and raw auction hooks don't run, since they need the HTTP request
+// and its JSON body.
+func (deps *endpointDeps) GRPCAuction(grpcCtx context.Context, req *openrtb2.BidRequest) (*openrtb2.BidResponse, error) {
+	start := time.Now()
+
+	ao := analytics.AuctionObject{
+		Status:    http.StatusOK,
+		Errors:    make([]error, 0),
+		StartTime: start,
+	}
+
+	labels := metrics.Labels{
+		Source:        metrics.DemandUnknown,
+		RType:         metrics.ReqTypeORTB2Web,
+		PubID:         metrics.PublisherUnknown,
+		CookieFlag:    metrics.CookieFlagUnknown,
+		RequestStatus: metrics.RequestStatusOK,
+	}
+	defer func() {
+		deps.metricsEngine.RecordRequest(labels)
+		deps.metricsEngine.RecordRequestTime(labels, time.Since(start))
+		deps.analytics.LogAuctionObject(&ao)
+	}()
+
+	hookExecutor := hookexecution.NewHookExecutor(deps.hookExecutionPlanBuilder, hookexecution.EndpointAuction)
+	defer func() {
+		ao.HookExecutionOutcome = hookExecutor.GetOutcomes()
+	}()
+
+	httpRequest := newGRPCHTTPRequest(grpcCtx)
+
+	req, account, variants, errL := deps.parseProtoRequest(httpRequest, req, &labels)
+	ao.StoredRequestVariants = variants
+
+	if errortypes.ContainsFatalError(errL) {
+		return nil, grpcError(errL, &labels, &ao)
+	}
+	warnings := errortypes.WarningOnly(errL)
+
+	ctx := tracing.RequestContext(httpRequest)
+
+	storedAuctionResponses, storedBidResponses, storedResponsesErrs := deps.processStoredResponses(ctx, req)
+	if len(storedResponsesErrs) > 0 {
+		return nil, grpcError(storedResponsesErrs, &labels, &ao)
+	}
+
+	timeout := deps.cfg.Current().AuctionTimeouts.LimitAuctionTimeout(time.Duration(req.TMax) * time.Millisecond)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, start.Add(timeout))
+		defer cancel()
+	}
+	// Unlike an HTTP client, a gRPC client sends its own deadline, which the auction mustn't outlive
+	if deadline, ok := grpcCtx.Deadline(); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+		defer cancel()
+	}
+
+	usersyncs := usersync.ParsePBSCookieFromRequest(httpRequest, &(deps.cfg.HostCookie))
+	if req.App != nil {
+		labels.Source = metrics.DemandApp
+		labels.RType = metrics.ReqTypeORTB2App
+	} else { //req.Site != nil
+		labels.Source = metrics.DemandWeb
+		if usersyncs.LiveSyncCount() == 0 {
+			labels.CookieFlag = metrics.CookieFlagNo
+		} else {
+			labels.CookieFlag = metrics.CookieFlagYes
+		}
+	}
+
+	hookExecutor.SetAccount(account)
+	if rejectErr := hookExecutor.ExecuteProcessedAuctionStage(req); rejectErr != nil {
+		ao.Request = req
+		ao.Account = account
+		ao.Errors = append(ao.Errors, rejectErr)
+		ao.Response = rejectedResponse(req, rejectErr)
+		return ao.Response, nil
+	}
+
+	auctionRequest := exchange.AuctionRequest{
+		BidRequest:                 req,
+		Account:                    *account,
+		UserSyncs:                  usersyncs,
+		RequestType:                labels.RType,
+		StartTime:                  start,
+		LegacyLabels:               labels,
+		Warnings:                   warnings,
+		GlobalPrivacyControlHeader: httpRequest.Header.Get("Sec-GPC"),
+		HookExecutor:               hookExecutor,
+		SeatDetails:                &ao.SeatDetails,
+		StoredAuctionResponses:     storedAuctionResponses,
+		StoredBidResponses:         storedBidResponses,
+	}
+
+	response, err := deps.ex.HoldAuction(ctx, auctionRequest, nil)
+	if err == nil && response != nil {
+		if response.Ext, err = hookexecution.EnrichExtBidResponse(response.Ext, hookExecutor.GetOutcomes(), req.Test == 1 && account.DebugAllow); err != nil {
+			ao.Errors = append(ao.Errors, err)
+			err = nil
+		}
+		if response.Ext, err = addStoredRequestVariants(response.Ext, variants); err != nil {
+			ao.Errors = append(ao.Errors, err)
+			err = nil
+		}
+	}
+	ao.Request = req
+	ao.Response = response
+	ao.Account = account
+	if err != nil {
+		labels.RequestStatus = metrics.RequestStatusErr
+		glog.Errorf("gRPC auction Critical error: %v", err)
+		ao.Status = http.StatusInternalServerError
+		ao.Errors = append(ao.Errors, err)
+		return nil, status.Errorf(codes.Internal, "Critical error while running the auction: %v", err)
+	}
+	return response, nil
+}
+
+// parseProtoRequest is the counterpart of parseRequest for a request decoded from protobuf. The request only goes
+// through JSON when Stored Requests or the default request have to be merged into it.
+func (deps *endpointDeps) parseProtoRequest(httpRequest *http.Request, req *openrtb2.BidRequest, labels *metrics.Labels) (*openrtb2.BidRequest, *config.Account, *openrtb_ext.ExtStoredRequestVariants, []error) {
+	timeout := time.Duration(storedRequestTimeoutMillis) * time.Millisecond
+	if req.TMax > 0 {
+		timeout = time.Duration(req.TMax) * time.Millisecond
+	}
+	ctx, cancel := context.WithTimeout(tracing.RequestContext(httpRequest), timeout)
+	defer cancel()
+
+	var variants *openrtb_ext.ExtStoredRequestVariants
+	if deps.defaultRequest || hasStoredRequests(req) {
+		requestJson, err := json.Marshal(req)
+		if err != nil {
+			return req, nil, nil, []error{err}
+		}
+		var errs []error
+		if requestJson, variants, errs = deps.processStoredRequests(ctx, requestJson); len(errs) > 0 {
+			return req, nil, variants, errs
+		}
+		req = &openrtb2.BidRequest{}
+		if err := json.Unmarshal(requestJson, req); err != nil {
+			return req, nil, variants, []error{err}
+		}
+	}
+
+	account, errs := deps.resolveRequest(ctx, httpRequest, req, labels)
+	return req, account, variants, errs
+}
+
+// hasStoredRequests tells whether the request or one of its imps refers to a Stored Request.
+func hasStoredRequests(req *openrtb2.BidRequest) bool {
+	if _, _, _, err := jsonparser.Get(req.Ext, openrtb_ext.PrebidExtKey, "storedrequest", "id"); err == nil {
+		return true
+	}
+	for _, imp := range req.Imp {
+		if _, _, _, err := jsonparser.Get(imp.Ext, openrtb_ext.PrebidExtKey, "storedrequest", "id"); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// newGRPCHTTPRequest returns an HTTP request carrying the metadata of the gRPC request as headers, and the
+// address of the gRPC client, so that the request can be completed the same way as an HTTP auction request.
+// The user-agent metadata is the one of the gRPC client rather than of the device, so it's left out.
+func newGRPCHTTPRequest(ctx context.Context) *http.Request {
+	httpRequest := &http.Request{
+		Method: http.MethodPost,
+		URL:    &url.URL{},
+		Header: http.Header{},
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for key, values := range md {
+			if key == "user-agent" || strings.HasPrefix(key, ":") || strings.HasPrefix(key, "grpc-") {
+				continue
+			}
+			for _, value := range values {
+				httpRequest.Header.Add(key, value)
+			}
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		httpRequest.RemoteAddr = p.Addr.String()
+	}
+	return httpRequest.WithContext(ctx)
+}
+
+// grpcError is the counterpart of writeError for the gRPC server. The status and the errors are recorded in the
+// auction object as well, with the HTTP status the auction endpoint would answer.
+func grpcError(errs []error, labels *metrics.Labels, ao *analytics.AuctionObject) error {
+	code := codes.InvalidArgument
+	ao.Status = http.StatusBadRequest
+	labels.RequestStatus = metrics.RequestStatusBadInput
+	for _, err := range errs {
+		erVal := errortypes.ReadCode(err)
+		if erVal == errortypes.BlacklistedAppErrorCode || erVal == errortypes.BlacklistedAcctErrorCode {
+			code = codes.Unavailable
+			ao.Status = http.StatusServiceUnavailable
+			labels.RequestStatus = metrics.RequestStatusBlacklisted
+			break
+		}
+	}
+	ao.Errors = append(ao.Errors, errs...)
+
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		messages = append(messages, fmt.Sprintf("Invalid request: %s", err.Error()))
+	}
+	return status.Error(code, strings.Join(messages, "\n"))
+}
@@ -0,0 +1,152 @@
+package openrtb2
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/hooks"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/openrtb_proto"
+	"github.com/prebid/prebid-server/stored_requests/backends/empty_fetcher"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestGRPCAuction(t *testing.T) {
+	impExt := json.RawMessage(`{"appnexus":{"placementId":12883451}}`)
+	testCases := []struct {
+		description      string
+		req              *openrtb2.BidRequest
+		md               metadata.MD
+		expectedCode     codes.Code
+		expectedMessage  string
+		expectedTMax     int64
+		expectedDeviceIP string
+		expectedGPC      string
+		expectedStatus   int
+		expectedErrors   int
+	}{
+		{
+			description: "Valid request",
+			req: &openrtb2.BidRequest{
+				ID:   "request-1",
+				Site: &openrtb2.Site{Page: "prebid.org"},
+				Imp:  []openrtb2.Imp{{ID: "imp-1", Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 300, H: 250}}}, Ext: impExt}},
+				TMax: 300,
+			},
+			md:               metadata.Pairs("x-forwarded-for", "1.2.3.4", "sec-gpc", "1"),
+			expectedCode:     codes.OK,
+			expectedTMax:     300,
+			expectedDeviceIP: "1.2.3.4",
+			expectedGPC:      "1",
+			expectedStatus:   http.StatusOK,
+		},
+		{
+			description: "Stored request merged into the request",
+			req: &openrtb2.BidRequest{
+				ID:   "request-1",
+				Site: &openrtb2.Site{Page: "prebid.org"},
+				Imp:  []openrtb2.Imp{{ID: "imp-1", Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 300, H: 250}}}, Ext: impExt}},
+				Ext:  json.RawMessage(`{"prebid":{"storedrequest":{"id":"2"}}}`),
+			},
+			expectedCode:   codes.OK,
+			expectedTMax:   500,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			description: "Invalid request",
+			req: &openrtb2.BidRequest{
+				ID:   "request-1",
+				Site: &openrtb2.Site{Page: "prebid.org"},
+			},
+			expectedCode:    codes.InvalidArgument,
+			expectedMessage: "Invalid request: request.imp must contain at least one element.",
+			expectedStatus:  http.StatusBadRequest,
+			expectedErrors:  1,
+		},
+		{
+			description: "Blacklisted account",
+			req: &openrtb2.BidRequest{
+				ID:   "request-1",
+				Site: &openrtb2.Site{Page: "prebid.org", Publisher: &openrtb2.Publisher{ID: "bad_acct"}},
+				Imp:  []openrtb2.Imp{{ID: "imp-1", Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 300, H: 250}}}, Ext: impExt}},
+			},
+			expectedCode:    codes.Unavailable,
+			expectedMessage: "Invalid request: Prebid-server has disabled Account ID: bad_acct, please reach out to the prebid server host.",
+			expectedStatus:  http.StatusServiceUnavailable,
+			expectedErrors:  1,
+		},
+	}
+
+	for _, test := range testCases {
+		ex := &storedResponsesExchange{}
+		analyticsModule := &mockAnalyticsModule{}
+		server, err := NewGRPCEndpoint(
+			ex,
+			newParamsValidator(t),
+			&mockStoredReqFetcher{},
+			empty_fetcher.EmptyFetcher{},
+			&config.Configuration{MaxRequestSize: maxSize, BlacklistedAcctMap: map[string]bool{"bad_acct": true}},
+			newTestMetrics(),
+			analyticsModule,
+			map[string]string{},
+			[]byte{},
+			openrtb_ext.BuildBidderMap(),
+			hooks.EmptyPlanBuilder{})
+		if !assert.NoError(t, err, test.description) {
+			continue
+		}
+
+		resp, err := invokeGRPCAuction(server, metadata.NewOutgoingContext(context.Background(), test.md), test.req)
+
+		assert.Equal(t, test.expectedCode, status.Code(err), test.description+":code")
+		if assert.Len(t, analyticsModule.auctionObjects, 1, test.description+":analytics") {
+			assert.Equal(t, test.expectedStatus, analyticsModule.auctionObjects[0].Status, test.description+":analytics_status")
+			assert.Len(t, analyticsModule.auctionObjects[0].Errors, test.expectedErrors, test.description+":analytics_errors")
+		}
+		if test.expectedCode != codes.OK {
+			assert.Equal(t, test.expectedMessage, status.Convert(err).Message(), test.description+":message")
+			continue
+		}
+		assert.Equal(t, test.req.ID, resp.ID, test.description+":response")
+		assert.Equal(t, test.expectedTMax, ex.lastAuctionRequest.BidRequest.TMax, test.description+":tmax")
+		if test.expectedDeviceIP != "" {
+			assert.Equal(t, test.expectedDeviceIP, ex.lastAuctionRequest.BidRequest.Device.IP, test.description+":device_ip")
+		}
+		assert.Equal(t, test.expectedGPC, ex.lastAuctionRequest.GlobalPrivacyControlHeader, test.description+":gpc")
+	}
+}
+
+func TestNewGRPCEndpointRequiresArguments(t *testing.T) {
+	_, err := NewGRPCEndpoint(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	assert.EqualError(t, err, "NewGRPCEndpoint requires non-nil arguments.")
+}
+
+// invokeGRPCAuction sends the request to the server through an in-memory connection.
+func invokeGRPCAuction(server *grpc.Server, ctx context.Context, req *openrtb2.BidRequest) (*openrtb2.BidResponse, error) {
+	listener := bufconn.Listen(1024 * 1024)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.DialContext(ctx, "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithInsecure(),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(openrtb_proto.Codec{})))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	resp := &openrtb2.BidResponse{}
+	err = conn.Invoke(ctx, "/prebid.AuctionService/Auction", req, resp)
+	return resp, err
+}
@@ -0,0 +1,73 @@
+package openrtb2
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/stored_requests"
+)
+
+// pickStoredVariants returns a copy of the fetched Stored Requests or Imps where the data split into weighted variants
+// is replaced by the data of the variant picked for this request. The IDs of the picked variants are returned keyed by
+// the stored data IDs, or nil if none of the data is split into variants.
+//
+// The dataType names the stored data in the errors, e.g. "Stored Request".
+func pickStoredVariants(data map[string]json.RawMessage, dataType string) (map[string]json.RawMessage, map[string]string, error) {
+	var picked map[string]json.RawMessage
+	var variants map[string]string
+	for id, storedData := range data {
+		variantData, variant, err := stored_requests.PickVariant(storedData, rand.Intn)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s with ID %s has invalid variants: %v", dataType, id, err)
+		}
+		if variant == "" {
+			continue
+		}
+		// The fetched data can't be written to, so the picks go into a copy
+		if picked == nil {
+			picked = make(map[string]json.RawMessage, len(data))
+			for id, storedData := range data {
+				picked[id] = storedData
+			}
+			variants = make(map[string]string)
+		}
+		picked[id] = variantData
+		variants[id] = variant
+	}
+
+	if picked == nil {
+		return data, nil, nil
+	}
+	return picked, variants, nil
+}
+
+// addStoredRequestVariants records the picked variants in bidresponse.ext.prebid.storedrequestvariants.
+// The ext is returned unchanged if no variant was picked.
+func addStoredRequestVariants(ext json.RawMessage, variants *openrtb_ext.ExtStoredRequestVariants) (json.RawMessage, error) {
+	if variants == nil {
+		return ext, nil
+	}
+
+	var extResponse openrtb_ext.ExtBidResponse
+	if len(ext) > 0 {
+		if err := json.Unmarshal(ext, &extResponse); err != nil {
+			return ext, err
+		}
+	}
+	if extResponse.Prebid == nil {
+		extResponse.Prebid = &openrtb_ext.ExtResponsePrebid{}
+	}
+	extResponse.Prebid.StoredRequestVariants = variants
+
+	return json.Marshal(extResponse)
+}
+
+// newStoredRequestVariants returns the picked variants of the Stored Requests and Imps, or nil if there are none.
+func newStoredRequestVariants(requests map[string]string, imps map[string]string) *openrtb_ext.ExtStoredRequestVariants {
+	if len(requests) == 0 && len(imps) == 0 {
+		return nil
+	}
+	return &openrtb_ext.ExtStoredRequestVariants{Requests: requests, Imps: imps}
+}
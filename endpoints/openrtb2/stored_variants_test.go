@@ -0,0 +1,140 @@
+package openrtb2
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPickStoredVariants(t *testing.T) {
+	testCases := []struct {
+		description      string
+		data             map[string]json.RawMessage
+		expectedData     map[string]json.RawMessage
+		expectedVariants map[string]string
+		expectedErr      string
+	}{
+		{
+			description:  "No variants",
+			data:         map[string]json.RawMessage{"1": json.RawMessage(`{"tmax":500}`)},
+			expectedData: map[string]json.RawMessage{"1": json.RawMessage(`{"tmax":500}`)},
+		},
+		{
+			description: "Variants",
+			data: map[string]json.RawMessage{
+				"1": json.RawMessage(`{"tmax":500}`),
+				"2": json.RawMessage(`{"variants":[{"id":"control","weight":0,"data":{"tmax":500}},{"id":"test","weight":1,"data":{"tmax":300}}]}`),
+			},
+			expectedData: map[string]json.RawMessage{
+				"1": json.RawMessage(`{"tmax":500}`),
+				"2": json.RawMessage(`{"tmax":300}`),
+			},
+			expectedVariants: map[string]string{"2": "test"},
+		},
+		{
+			description: "Invalid variants",
+			data:        map[string]json.RawMessage{"2": json.RawMessage(`{"variants":[]}`)},
+			expectedErr: "Stored Request with ID 2 has invalid variants: the variants list is empty",
+		},
+	}
+
+	for _, test := range testCases {
+		fetched := make(map[string]json.RawMessage, len(test.data))
+		for id, data := range test.data {
+			fetched[id] = data
+		}
+
+		data, variants, err := pickStoredVariants(fetched, "Stored Request")
+
+		if test.expectedErr != "" {
+			assert.EqualError(t, err, test.expectedErr, test.description)
+			continue
+		}
+		assert.NoError(t, err, test.description)
+		assert.Equal(t, test.expectedData, data, test.description+":data")
+		assert.Equal(t, test.expectedVariants, variants, test.description+":variants")
+		assert.Equal(t, test.data, fetched, test.description+":the fetched data shouldn't be written to")
+	}
+}
+
+func TestAddStoredRequestVariants(t *testing.T) {
+	variants := &openrtb_ext.ExtStoredRequestVariants{
+		Requests: map[string]string{"1": "test"},
+		Imps:     map[string]string{"2": "control"},
+	}
+
+	testCases := []struct {
+		description string
+		ext         json.RawMessage
+		variants    *openrtb_ext.ExtStoredRequestVariants
+		expectedExt string
+	}{
+		{
+			description: "No variants",
+			ext:         json.RawMessage(`{"tmaxrequest":500}`),
+			expectedExt: `{"tmaxrequest":500}`,
+		},
+		{
+			description: "Empty ext",
+			variants:    variants,
+			expectedExt: `{"prebid":{"storedrequestvariants":{"requests":{"1":"test"},"imps":{"2":"control"}}}}`,
+		},
+		{
+			description: "Existing ext",
+			ext:         json.RawMessage(`{"tmaxrequest":500,"prebid":{"auctiontimestamp":1000}}`),
+			variants:    variants,
+			expectedExt: `{"tmaxrequest":500,"prebid":{"auctiontimestamp":1000,"storedrequestvariants":{"requests":{"1":"test"},"imps":{"2":"control"}}}}`,
+		},
+	}
+
+	for _, test := range testCases {
+		ext, err := addStoredRequestVariants(test.ext, test.variants)
+
+		if assert.NoError(t, err, test.description) {
+			assert.JSONEq(t, test.expectedExt, string(ext), test.description)
+		}
+	}
+}
+
+func TestProcessStoredRequestVariants(t *testing.T) {
+	deps := &endpointDeps{
+		storedReqFetcher: &mockVariantsFetcher{
+			requests: map[string]json.RawMessage{
+				"req": json.RawMessage(`{"variants":[{"id":"control","weight":0,"data":{"tmax":500}},{"id":"test","weight":1,"data":{"tmax":300}}]}`),
+			},
+			imps: map[string]json.RawMessage{
+				"imp": json.RawMessage(`{"variants":[{"weight":1,"data":{"banner":{"format":[{"w":300,"h":250}]}}}]}`),
+			},
+		},
+		cfg: &config.Configuration{},
+	}
+	request := `{"id":"1","ext":{"prebid":{"storedrequest":{"id":"req"}}},"imp":[{"id":"imp-1","ext":{"prebid":{"storedrequest":{"id":"imp"}}}}]}`
+
+	resolved, variants, errs := deps.processStoredRequests(context.Background(), json.RawMessage(request))
+
+	if !assert.Empty(t, errs) {
+		return
+	}
+	assert.JSONEq(t, `{"id":"1","tmax":300,"ext":{"prebid":{"storedrequest":{"id":"req"}}},"imp":[{"id":"imp-1","banner":{"format":[{"w":300,"h":250}]},"ext":{"prebid":{"storedrequest":{"id":"imp"}}}}]}`, string(resolved))
+	assert.Equal(t, &openrtb_ext.ExtStoredRequestVariants{
+		Requests: map[string]string{"req": "test"},
+		Imps:     map[string]string{"imp": "0"},
+	}, variants)
+}
+
+type mockVariantsFetcher struct {
+	requests map[string]json.RawMessage
+	imps     map[string]json.RawMessage
+}
+
+func (f *mockVariantsFetcher) FetchRequests(ctx context.Context, requestIDs []string, impIDs []string) (requestData map[string]json.RawMessage, impData map[string]json.RawMessage, errs []error) {
+	return f.requests, f.imps, nil
+}
+
+func (f *mockVariantsFetcher) FetchResponses(ctx context.Context, ids []string) (data map[string]json.RawMessage, errs []error) {
+	return nil, nil
+}
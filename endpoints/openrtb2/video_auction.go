@@ -19,18 +19,19 @@ import (
 	"github.com/gofrs/uuid"
 	"github.com/mxmCherry/openrtb/v15/openrtb2"
 	"github.com/prebid/prebid-server/errortypes"
-	"github.com/prebid/prebid-server/util/iputil"
 
 	"github.com/golang/glog"
 	"github.com/julienschmidt/httprouter"
-	accountService "github.com/prebid/prebid-server/account"
 	"github.com/prebid/prebid-server/analytics"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/exchange"
+	"github.com/prebid/prebid-server/hooks"
+	"github.com/prebid/prebid-server/hooks/hookexecution"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/prebid_cache_client"
 	"github.com/prebid/prebid-server/stored_requests"
+	"github.com/prebid/prebid-server/tracing"
 	"github.com/prebid/prebid-server/usersync"
 )
 
@@ -49,6 +50,7 @@ func NewVideoEndpoint(
 	defReqJSON []byte,
 	bidderMap map[string]openrtb_ext.BidderName,
 	cache prebid_cache_client.Client,
+	hookExecutionPlanBuilder hooks.ExecutionPlanBuilder,
 ) (httprouter.Handle, error) {
 
 	if ex == nil || validator == nil || requestsById == nil || accounts == nil || cfg == nil || met == nil {
@@ -57,10 +59,7 @@ func NewVideoEndpoint(
 
 	defRequest := defReqJSON != nil && len(defReqJSON) > 0
 
-	ipValidator := iputil.PublicNetworkIPValidator{
-		IPv4PrivateNetworks: cfg.RequestValidation.IPv4PrivateNetworksParsed,
-		IPv6PrivateNetworks: cfg.RequestValidation.IPv6PrivateNetworksParsed,
-	}
+	ipValidator := hostIPValidator{cfg}
 
 	videoEndpointRegexp := regexp.MustCompile(`[<>]`)
 
@@ -79,7 +78,9 @@ func NewVideoEndpoint(
 		bidderMap,
 		cache,
 		videoEndpointRegexp,
-		ipValidator}).VideoAuctionEndpoint), nil
+		ipValidator,
+		hookExecutionPlanBuilder,
+		newAuctionResponseCache(cfg.AuctionResponseCache)}).VideoAuctionEndpoint), nil
 }
 
 /*
@@ -169,6 +170,7 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 	}
 
 	//load additional data - stored simplified req
+	var requestVariants map[string]string
 	storedRequestId, err := getVideoStoredRequestId(requestJson)
 
 	if err != nil {
@@ -177,7 +179,10 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 			return
 		}
 	} else {
-		storedRequest, errs := deps.loadStoredVideoRequest(context.Background(), storedRequestId)
+		storedRequest, variant, errs := deps.loadStoredVideoRequest(tracing.RequestContext(r), storedRequestId)
+		if variant != "" {
+			requestVariants = map[string]string{storedRequestId: variant}
+		}
 		if len(errs) > 0 {
 			handleError(&labels, w, errs, &vo, &debugLog)
 			return
@@ -222,7 +227,9 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 	}
 
 	//create impressions array
-	imps, podErrors := deps.createImpressions(videoBidReq, podErrors)
+	imps, impVariants, podErrors := deps.createImpressions(videoBidReq, podErrors)
+	variants := newStoredRequestVariants(requestVariants, impVariants)
+	vo.StoredRequestVariants = variants
 
 	if len(podErrors) == initialPodNumber {
 		resPodErr := make([]string, 0)
@@ -241,14 +248,8 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 	// Populate any "missing" OpenRTB fields with info from other sources, (e.g. HTTP request headers).
 	deps.setFieldsImplicitly(r, bidReq) // move after merge
 
-	errL = deps.validateRequest(bidReq)
-	if errortypes.ContainsFatalError(errL) {
-		handleError(&labels, w, errL, &vo, &debugLog)
-		return
-	}
-
-	ctx := context.Background()
-	timeout := deps.cfg.AuctionTimeouts.LimitAuctionTimeout(time.Duration(bidReq.TMax) * time.Millisecond)
+	ctx := tracing.RequestContext(r)
+	timeout := deps.cfg.Current().AuctionTimeouts.LimitAuctionTimeout(time.Duration(bidReq.TMax) * time.Millisecond)
 	if timeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithDeadline(ctx, start.Add(timeout))
@@ -258,7 +259,6 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 	usersyncs := usersync.ParsePBSCookieFromRequest(r, &(deps.cfg.HostCookie))
 	if bidReq.App != nil {
 		labels.Source = metrics.DemandApp
-		labels.PubID = getAccountID(bidReq.App.Publisher)
 	} else { // both bidReq.App == nil and bidReq.Site != nil are true
 		labels.Source = metrics.DemandWeb
 		if usersyncs.LiveSyncCount() == 0 {
@@ -266,16 +266,31 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 		} else {
 			labels.CookieFlag = metrics.CookieFlagYes
 		}
-		labels.PubID = getAccountID(bidReq.Site.Publisher)
 	}
 
-	// Look up account now that we have resolved the pubID value
-	account, acctIDErrs := accountService.GetAccount(ctx, deps.cfg, deps.accounts, labels.PubID)
+	// Look up the account before the validation, since it may define the bidder aliases used by the request
+	account, acctIDErrs := deps.lookupAccount(ctx, bidReq, &labels)
+	vo.Account = account
 	if len(acctIDErrs) > 0 {
 		handleError(&labels, w, acctIDErrs, &vo, &debugLog)
 		return
 	}
 
+	errL = deps.validateRequest(bidReq, account)
+	if errortypes.ContainsFatalError(errL) {
+		handleError(&labels, w, errL, &vo, &debugLog)
+		return
+	}
+
+	storedAuctionResponses, storedBidResponses, storedResponsesErrs := deps.processStoredResponses(ctx, bidReq)
+	if len(storedResponsesErrs) > 0 {
+		handleError(&labels, w, storedResponsesErrs, &vo, &debugLog)
+		return
+	}
+
+	hookExecutor := hookexecution.NewHookExecutor(deps.hookExecutionPlanBuilder, hookexecution.EndpointVideo)
+	hookExecutor.SetAccount(account)
+
 	secGPC := r.Header.Get("Sec-GPC")
 
 	auctionRequest := exchange.AuctionRequest{
@@ -286,9 +301,13 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 		StartTime:                  start,
 		LegacyLabels:               labels,
 		GlobalPrivacyControlHeader: secGPC,
+		HookExecutor:               hookExecutor,
+		SeatDetails:                &vo.SeatDetails,
+		StoredAuctionResponses:     storedAuctionResponses,
+		StoredBidResponses:         storedBidResponses,
 	}
 
-	response, err := deps.ex.HoldAuction(ctx, auctionRequest, &debugLog)
+	response, err := deps.holdCachedAuction(ctx, auctionRequest, &debugLog)
 	vo.Request = bidReq
 	vo.Response = response
 	if err != nil {
@@ -307,6 +326,9 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 	if bidReq.Test == 1 {
 		bidResp.Ext = response.Ext
 	}
+	if bidResp.Ext, err = addStoredRequestVariants(bidResp.Ext, variants); err != nil {
+		vo.Errors = append(vo.Errors, err)
+	}
 
 	if len(bidResp.AdPods) == 0 && debugLog.DebugEnabledOrOverridden {
 		err := debugLog.PutDebugLogError(deps.cache, deps.cfg.CacheURL.ExpectedTimeMillis, vo.Errors)
@@ -375,18 +397,33 @@ func handleError(labels *metrics.Labels, w http.ResponseWriter, errL []error, vo
 	vo.Errors = append(vo.Errors, errL...)
 }
 
-func (deps *endpointDeps) createImpressions(videoReq *openrtb_ext.BidRequestVideo, podErrors []PodError) ([]openrtb2.Imp, []PodError) {
+// createImpressions creates the imps of the pods from their stored imps. The pods which share a stored imp get the same
+// variant of it, if it's split into weighted variants. The picked variants are returned keyed by the stored imp IDs.
+func (deps *endpointDeps) createImpressions(videoReq *openrtb_ext.BidRequestVideo, podErrors []PodError) ([]openrtb2.Imp, map[string]string, []PodError) {
 	videoDur := videoReq.PodConfig.DurationRangeSec
 	minDuration, maxDuration := minMax(videoDur)
 	reqExactDur := videoReq.PodConfig.RequireExactDuration
 	videoData := videoReq.Video
 
 	finalImpsArray := make([]openrtb2.Imp, 0)
+	storedImps := make(map[string]openrtb2.Imp)
+	var impVariants map[string]string
 	for ind, pod := range videoReq.PodConfig.Pods {
 
 		//load stored impression
 		storedImpressionId := string(pod.ConfigId)
-		storedImp, errs := deps.loadStoredImp(storedImpressionId)
+		storedImp, loaded := storedImps[storedImpressionId]
+		var errs []error
+		if !loaded {
+			var variant string
+			storedImp, variant, errs = deps.loadStoredImp(storedImpressionId)
+			if variant != "" {
+				if impVariants == nil {
+					impVariants = make(map[string]string)
+				}
+				impVariants[storedImpressionId] = variant
+			}
+		}
 		if errs != nil {
 			err := fmt.Sprintf("unable to load configid %s, Pod id: %d", storedImpressionId, pod.PodId)
 			podErr := PodError{}
@@ -396,6 +433,7 @@ func (deps *endpointDeps) createImpressions(videoReq *openrtb_ext.BidRequestVide
 			podErrors = append(podErrors, podErr)
 			continue
 		}
+		storedImps[storedImpressionId] = storedImp
 
 		numImps := pod.AdPodDurationSec / minDuration
 		if reqExactDur {
@@ -427,7 +465,7 @@ func (deps *endpointDeps) createImpressions(videoReq *openrtb_ext.BidRequestVide
 		finalImpsArray = append(finalImpsArray, impsArray...)
 
 	}
-	return finalImpsArray, podErrors
+	return finalImpsArray, impVariants, podErrors
 }
 
 func max(a, b int) int {
@@ -444,20 +482,25 @@ func createImpressionTemplate(imp openrtb2.Imp, video *openrtb2.Video) openrtb2.
 	return imp
 }
 
-func (deps *endpointDeps) loadStoredImp(storedImpId string) (openrtb2.Imp, []error) {
+// loadStoredImp returns the stored imp, along with the ID of the variant picked if it's split into weighted variants.
+func (deps *endpointDeps) loadStoredImp(storedImpId string) (openrtb2.Imp, string, []error) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(storedRequestTimeoutMillis)*time.Millisecond)
 	defer cancel()
 
 	impr := openrtb2.Imp{}
 	_, imp, err := deps.storedReqFetcher.FetchRequests(ctx, []string{}, []string{storedImpId})
 	if err != nil {
-		return impr, err
+		return impr, "", err
 	}
 
+	imp, variants, pickErr := pickStoredVariants(imp, "Stored Imp")
+	if pickErr != nil {
+		return impr, "", []error{pickErr}
+	}
 	if err := json.Unmarshal(imp[storedImpId], &impr); err != nil {
-		return impr, []error{err}
+		return impr, "", []error{err}
 	}
-	return impr, nil
+	return impr, variants[storedImpId], nil
 }
 
 func minMax(array []int) (int, int) {
@@ -552,10 +595,18 @@ func findAdPod(podInd int64, pods []*openrtb_ext.AdPod) *openrtb_ext.AdPod {
 	return nil
 }
 
-func (deps *endpointDeps) loadStoredVideoRequest(ctx context.Context, storedRequestId string) ([]byte, []error) {
+// loadStoredVideoRequest returns the stored video request, along with the ID of the variant picked if it's split
+// into weighted variants.
+func (deps *endpointDeps) loadStoredVideoRequest(ctx context.Context, storedRequestId string) ([]byte, string, []error) {
 	storedRequests, _, errs := deps.videoFetcher.FetchRequests(ctx, []string{storedRequestId}, []string{})
-	jsonString := storedRequests[storedRequestId]
-	return jsonString, errs
+	if len(errs) > 0 {
+		return storedRequests[storedRequestId], "", errs
+	}
+	storedRequests, variants, err := pickStoredVariants(storedRequests, "Stored Request")
+	if err != nil {
+		return nil, "", []error{err}
+	}
+	return storedRequests[storedRequestId], variants[storedRequestId], nil
 }
 
 func getVideoStoredRequestId(request []byte) (string, error) {
@@ -772,7 +823,7 @@ func (deps *endpointDeps) validateVideoRequest(req *openrtb_ext.BidRequestVideo)
 		errL = append(errL, err)
 	} else if req.App != nil {
 		if req.App.ID != "" {
-			if _, found := deps.cfg.BlacklistedAppMap[req.App.ID]; found {
+			if _, found := deps.cfg.Current().BlacklistedAppMap[req.App.ID]; found {
 				err := &errortypes.BlacklistedApp{Message: fmt.Sprintf("Prebid-server does not process requests from App ID: %s", req.App.ID)}
 				errL = append(errL, err)
 				return errL, podErrors
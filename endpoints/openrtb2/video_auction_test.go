@@ -17,7 +17,9 @@ import (
 	analyticsConf "github.com/prebid/prebid-server/analytics/config"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/exchange"
+	"github.com/prebid/prebid-server/hooks"
 	"github.com/prebid/prebid-server/metrics"
+	metricsConfig "github.com/prebid/prebid-server/metrics/config"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/prebid_cache_client"
 	"github.com/prebid/prebid-server/stored_requests/backends/empty_fetcher"
@@ -1218,6 +1220,8 @@ func mockDepsWithMetrics(t *testing.T, ex *mockExchangeVideo) (*endpointDeps, *m
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		hooks.EmptyPlanBuilder{},
+		nil,
 	}
 
 	return deps, metrics, mockModule
@@ -1253,7 +1257,7 @@ func mockDeps(t *testing.T, ex *mockExchangeVideo) *endpointDeps {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		newTestMetrics(),
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
 		map[string]string{},
 		false,
 		[]byte{},
@@ -1261,6 +1265,8 @@ func mockDeps(t *testing.T, ex *mockExchangeVideo) *endpointDeps {
 		ex.cache,
 		regexp.MustCompile(`[<>]`),
 		hardcodedResponseIPValidator{response: true},
+		hooks.EmptyPlanBuilder{},
+		nil,
 	}
 
 	return deps
@@ -1275,7 +1281,7 @@ func mockDepsAppendBidderNames(t *testing.T, ex *mockExchangeAppendBidderNames)
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		newTestMetrics(),
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
 		map[string]string{},
 		false,
 		[]byte{},
@@ -1283,6 +1289,8 @@ func mockDepsAppendBidderNames(t *testing.T, ex *mockExchangeAppendBidderNames)
 		ex.cache,
 		regexp.MustCompile(`[<>]`),
 		hardcodedResponseIPValidator{response: true},
+		hooks.EmptyPlanBuilder{},
+		nil,
 	}
 
 	return deps
@@ -1297,7 +1305,7 @@ func mockDepsNoBids(t *testing.T, ex *mockExchangeVideoNoBids) *endpointDeps {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		newTestMetrics(),
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.DummyMetricsEngine{}),
 		map[string]string{},
 		false,
 		[]byte{},
@@ -1305,6 +1313,8 @@ func mockDepsNoBids(t *testing.T, ex *mockExchangeVideoNoBids) *endpointDeps {
 		ex.cache,
 		regexp.MustCompile(`[<>]`),
 		hardcodedResponseIPValidator{response: true},
+		hooks.EmptyPlanBuilder{},
+		nil,
 	}
 
 	return edep
@@ -1332,6 +1342,10 @@ func (cf mockVideoStoredReqFetcher) FetchRequests(ctx context.Context, requestID
 	return testVideoStoredRequestData, testVideoStoredImpData, nil
 }
 
+func (cf mockVideoStoredReqFetcher) FetchResponses(ctx context.Context, ids []string) (data map[string]json.RawMessage, errs []error) {
+	return nil, nil
+}
+
 type mockExchangeVideo struct {
 	lastRequest *openrtb2.BidRequest
 	cache       *mockCacheClient
@@ -393,7 +393,7 @@ func doRequest(req *http.Request, metrics metrics.MetricsEngine, validFamilyName
 		errorHost:           gdprReturnsError,
 		personalInfoAllowed: true,
 	}
-	analytics := analyticsConf.NewPBSAnalytics(&cfg.Analytics)
+	analytics := analyticsConf.NewPBSAnalytics(&cfg.Analytics, &metricsConf.DummyMetricsEngine{})
 	syncers := make(map[openrtb_ext.BidderName]usersync.Usersyncer)
 	for _, name := range validFamilyNames {
 		syncers[openrtb_ext.BidderName(name)] = newFakeSyncer(name)
@@ -0,0 +1,129 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/stored_requests"
+)
+
+// storedDataCacheInfo holds the content and usage of a stored data cache.
+type storedDataCacheInfo struct {
+	Entries  int     `json:"entries"`
+	Hits     int64   `json:"hits"`
+	Misses   int64   `json:"misses"`
+	HitRatio float64 `json:"hit_ratio"`
+}
+
+// storedDataInvalidation holds the IDs invalidated in a stored data cache.
+type storedDataInvalidation struct {
+	Invalidated []string `json:"invalidated"`
+}
+
+// NewStoredDataCachesEndpoint lists the in-memory caches of the stored data, with their number of entries and
+// the hit ratio of their lookups.
+func NewStoredDataCachesEndpoint(caches map[string]stored_requests.InspectableCache) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		infos := make(map[string]storedDataCacheInfo, len(caches))
+		for name, cache := range caches {
+			stats := cache.Stats()
+			infos[name] = storedDataCacheInfo{
+				Entries:  stats.Entries,
+				Hits:     stats.Hits,
+				Misses:   stats.Misses,
+				HitRatio: stats.HitRatio(),
+			}
+		}
+		writeStoredDataJSON(w, "/stored_data/caches", infos)
+	}
+}
+
+// NewStoredDataCacheEntryEndpoint returns an entry of a stored data cache, selected by the "cache" and "id" query
+// parameters. It answers with a 404 if the entry isn't cached. Looking at an entry doesn't count in the hit ratio
+// of the cache.
+func NewStoredDataCacheEntryEndpoint(caches map[string]stored_requests.InspectableCache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cache, ok := storedDataCache(w, r, caches)
+		if !ok {
+			return
+		}
+		id := r.URL.Query().Get("id")
+		if id == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("The id query parameter is required."))
+			return
+		}
+
+		entry, found := cache.Peek(id)
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(fmt.Sprintf("No entry is cached for the ID %s.", id)))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(entry)
+	}
+}
+
+// NewStoredDataCacheInvalidateEndpoint invalidates the entries of a stored data cache, selected by the "cache" query
+// parameter. The entries are given by one or more "id" query parameters, or by a "prefix" of their IDs. An empty
+// prefix invalidates the whole cache. The next lookups of the entries go to the backend.
+func NewStoredDataCacheInvalidateEndpoint(caches map[string]stored_requests.InspectableCache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		cache, ok := storedDataCache(w, r, caches)
+		if !ok {
+			return
+		}
+		query := r.URL.Query()
+		ids := query["id"]
+		if prefix, ok := query["prefix"]; ok {
+			ids = append(ids, cache.IDs(prefix[0])...)
+		} else if len(ids) == 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("Either the id or the prefix query parameter is required."))
+			return
+		}
+
+		cache.Invalidate(r.Context(), ids)
+		writeStoredDataJSON(w, "/stored_data/caches/invalidate", storedDataInvalidation{Invalidated: ids})
+	}
+}
+
+// storedDataCache returns the cache named by the "cache" query parameter. It answers the request with an error
+// if there isn't one.
+func storedDataCache(w http.ResponseWriter, r *http.Request, caches map[string]stored_requests.InspectableCache) (stored_requests.InspectableCache, bool) {
+	name := r.URL.Query().Get("cache")
+	if cache, ok := caches[name]; ok {
+		return cache, true
+	}
+
+	names := make([]string, 0, len(caches))
+	for name := range caches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	w.WriteHeader(http.StatusNotFound)
+	w.Write([]byte(fmt.Sprintf("Unknown cache: '%s'. The caches are: %s.", name, strings.Join(names, ", "))))
+	return nil, false
+}
+
+func writeStoredDataJSON(w http.ResponseWriter, endpoint string, value interface{}) {
+	jsonOutput, err := json.Marshal(value)
+	if err != nil {
+		glog.Errorf("%s Critical error when trying to marshal the response: %v", endpoint, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonOutput)
+}
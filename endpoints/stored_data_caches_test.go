@@ -0,0 +1,158 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prebid/prebid-server/stored_requests"
+	"github.com/prebid/prebid-server/stored_requests/caches/memory"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStoredDataCaches(t *testing.T) {
+	caches := newTestStoredDataCaches()
+	caches["requests"].Get(context.Background(), []string{"pub1-a", "pub1-b", "unknown"})
+	w := httptest.NewRecorder()
+
+	NewStoredDataCachesEndpoint(caches)(w, httptest.NewRequest(http.MethodGet, "/stored_data/caches", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"requests": {"entries": 3, "hits": 2, "misses": 1, "hit_ratio": 0.6666666666666666},
+		"imps": {"entries": 0, "hits": 0, "misses": 0, "hit_ratio": 0}
+	}`, w.Body.String())
+}
+
+func TestStoredDataCacheEntry(t *testing.T) {
+	testCases := []struct {
+		description    string
+		query          string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			description:    "Cached",
+			query:          "cache=requests&id=pub1-a",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":"pub1-a"}`,
+		},
+		{
+			description:    "Not cached",
+			query:          "cache=requests&id=unknown",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "No entry is cached for the ID unknown.",
+		},
+		{
+			description:    "Missing id",
+			query:          "cache=requests",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "The id query parameter is required.",
+		},
+		{
+			description:    "Unknown cache",
+			query:          "cache=accounts&id=pub1-a",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Unknown cache: 'accounts'. The caches are: imps, requests.",
+		},
+	}
+
+	for _, test := range testCases {
+		caches := newTestStoredDataCaches()
+		handler := NewStoredDataCacheEntryEndpoint(caches)
+		w := httptest.NewRecorder()
+
+		handler(w, httptest.NewRequest(http.MethodGet, "/stored_data/caches/entry?"+test.query, nil))
+
+		assert.Equal(t, test.expectedStatus, w.Code, test.description+":status")
+		assert.Equal(t, test.expectedBody, w.Body.String(), test.description+":body")
+		assert.Equal(t, stored_requests.CacheStats{Entries: 3}, caches["requests"].Stats(), test.description+":The lookup shouldn't be counted")
+	}
+}
+
+func TestStoredDataCacheInvalidate(t *testing.T) {
+	testCases := []struct {
+		description    string
+		method         string
+		query          string
+		expectedStatus int
+		expectedBody   string
+		expectedIDs    []string
+	}{
+		{
+			description:    "By ids",
+			method:         http.MethodPost,
+			query:          "cache=requests&id=pub1-a&id=pub2-a",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"invalidated":["pub1-a","pub2-a"]}`,
+			expectedIDs:    []string{"pub1-b"},
+		},
+		{
+			description:    "By prefix",
+			method:         http.MethodPost,
+			query:          "cache=requests&prefix=pub1-",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []string{"pub2-a"},
+		},
+		{
+			description:    "Empty prefix",
+			method:         http.MethodPost,
+			query:          "cache=requests&prefix=",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []string{},
+		},
+		{
+			description:    "Missing id and prefix",
+			method:         http.MethodPost,
+			query:          "cache=requests",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Either the id or the prefix query parameter is required.",
+			expectedIDs:    []string{"pub1-a", "pub1-b", "pub2-a"},
+		},
+		{
+			description:    "Unknown cache",
+			method:         http.MethodPost,
+			query:          "cache=accounts&id=pub1-a",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Unknown cache: 'accounts'. The caches are: imps, requests.",
+			expectedIDs:    []string{"pub1-a", "pub1-b", "pub2-a"},
+		},
+		{
+			description:    "Wrong method",
+			method:         http.MethodGet,
+			query:          "cache=requests&id=pub1-a",
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedIDs:    []string{"pub1-a", "pub1-b", "pub2-a"},
+		},
+	}
+
+	for _, test := range testCases {
+		caches := newTestStoredDataCaches()
+		handler := NewStoredDataCacheInvalidateEndpoint(caches)
+		w := httptest.NewRecorder()
+
+		handler(w, httptest.NewRequest(test.method, "/stored_data/caches/invalidate?"+test.query, nil))
+
+		assert.Equal(t, test.expectedStatus, w.Code, test.description+":status")
+		if test.expectedBody != "" {
+			assert.Equal(t, test.expectedBody, w.Body.String(), test.description+":body")
+		}
+		assert.ElementsMatch(t, test.expectedIDs, caches["requests"].IDs(""), test.description+":remaining")
+	}
+}
+
+func newTestStoredDataCaches() map[string]stored_requests.InspectableCache {
+	requests := memory.NewCache(0, -1, "Request")
+	requests.Save(context.Background(), map[string]json.RawMessage{
+		"pub1-a": json.RawMessage(`{"id":"pub1-a"}`),
+		"pub1-b": json.RawMessage(`{"id":"pub1-b"}`),
+		"pub2-a": json.RawMessage(`{"id":"pub2-a"}`),
+	})
+	return map[string]stored_requests.InspectableCache{
+		"requests": requests,
+		"imps":     memory.NewCache(0, -1, "Imp"),
+	}
+}
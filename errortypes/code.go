@@ -12,6 +12,7 @@ const (
 	BlacklistedAcctErrorCode
 	AcctRequiredErrorCode
 	NoConversionRateErrorCode
+	ModuleRejectionErrorCode
 )
 
 // Defines numeric codes for well-known warnings.
@@ -21,6 +22,7 @@ const (
 	AccountLevelDebugDisabledWarningCode
 	BidderLevelDebugDisabledWarningCode
 	DisabledCurrencyConversionWarningCode
+	MultiBidWarningCode
 )
 
 // Coder provides an error or warning code with severity.
@@ -247,3 +247,13 @@ func newAdapterBuilders() map[openrtb_ext.BidderName]adapters.Builder {
 		openrtb_ext.BidderZeroClickFraud:    zeroclickfraud.Builder,
 	}
 }
+
+// setAliasBuilders registers the hard aliases defined in the host config, which are built by the builder of
+// their core bidder.
+func setAliasBuilders(builders map[openrtb_ext.BidderName]adapters.Builder, aliasToParent map[openrtb_ext.BidderName]openrtb_ext.BidderName) {
+	for alias, parent := range aliasToParent {
+		if builder, found := builders[parent]; found {
+			builders[alias] = builder
+		}
+	}
+}
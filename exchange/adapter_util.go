@@ -3,29 +3,67 @@ package exchange
 import (
 	"fmt"
 	"net/http"
+	"strings"
 
+	"github.com/golang/glog"
 	"github.com/prebid/prebid-server/adapters"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 )
 
+// BuildAdapters builds the bidders enabled in the host configuration. The bidders follow the reloads of their
+// endpoints and disabled flags, except the ones disabled here, which need a restart to be enabled.
 func BuildAdapters(client *http.Client, cfg *config.Configuration, infos config.BidderInfos, me metrics.MetricsEngine) (map[openrtb_ext.BidderName]adaptedBidder, []error) {
-	bidders, errs := buildBidders(cfg.Adapters, infos, newAdapterBuilders())
+	builders := newAdapterBuilders()
+	setAliasBuilders(builders, openrtb_ext.GetAliasBidderToParent())
+
+	bidders, errs := buildBidders(cfg.Adapters, infos, builders)
 	if len(errs) > 0 {
 		return nil, errs
 	}
 
 	exchangeBidders := make(map[openrtb_ext.BidderName]adaptedBidder, len(bidders))
+	reloadableBidders := make(map[openrtb_ext.BidderName]*reloadableBidder, len(bidders))
 	for bidderName, bidder := range bidders {
 		info := infos[string(bidderName)]
-		exchangeBidder := adaptBidder(bidder, client, cfg, me, bidderName, info.Debug)
-		exchangeBidder = addValidatedBidderMiddleware(exchangeBidder)
+		reloadableBidder := addReloadableBidderMiddleware(adaptBidder(bidder, client, cfg, me, bidderName, info.Debug))
+		reloadableBidders[bidderName] = reloadableBidder
+		exchangeBidder := addValidatedBidderMiddleware(reloadableBidder)
+		exchangeBidder = addCircuitBreakerBidderMiddleware(exchangeBidder, bidderName, cfg.Adapters[strings.ToLower(string(bidderName))].CircuitBreaker, me)
 		exchangeBidders[bidderName] = exchangeBidder
 	}
+
+	cfg.OnReload(func(reloaded *config.Configuration) {
+		reloadBidders(reloadableBidders, reloaded.Adapters, infos, builders)
+	})
 	return exchangeBidders, nil
 }
 
+// reloadBidders rebuilds the bidders with their reloaded adapter configuration, or disables them.
+func reloadBidders(bidders map[openrtb_ext.BidderName]*reloadableBidder, adapterConfig map[string]config.Adapter, infos config.BidderInfos, builders map[openrtb_ext.BidderName]adapters.Builder) {
+	for bidderName, bidder := range bidders {
+		cfg := adapterConfig[strings.ToLower(string(bidderName))]
+		if cfg.Disabled {
+			bidder.disable()
+			continue
+		}
+		bidderInstance, err := builders[bidderName](bidderName, cfg)
+		if err != nil {
+			glog.Errorf("The bidder %s keeps its previous configuration, since it couldn't be rebuilt: %v", bidderName, err)
+			continue
+		}
+		bidder.reload(adapters.BuildInfoAwareBidder(bidderInstance, infos[string(bidderName)]))
+	}
+
+	for bidder, cfg := range adapterConfig {
+		bidderName, found := openrtb_ext.NormalizeBidderName(bidder)
+		if _, built := bidders[bidderName]; found && !built && !cfg.Disabled {
+			glog.Warningf("The bidder %s can't be enabled without a restart, since it was disabled when Prebid Server started", bidderName)
+		}
+	}
+}
+
 func buildBidders(adapterConfig map[string]config.Adapter, infos config.BidderInfos, builders map[openrtb_ext.BidderName]adapters.Builder) (map[openrtb_ext.BidderName]adapters.Bidder, []error) {
 	bidders := make(map[openrtb_ext.BidderName]adapters.Bidder)
 	var errs []error
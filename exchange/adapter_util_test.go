@@ -27,12 +27,12 @@ func TestBuildAdapters(t *testing.T) {
 	appnexusBidder, _ := appnexus.Builder(openrtb_ext.BidderAppnexus, config.Adapter{})
 	appnexusBidderWithInfo := adapters.BuildInfoAwareBidder(appnexusBidder, infoEnabled)
 	appnexusBidderAdapted := adaptBidder(appnexusBidderWithInfo, client, &config.Configuration{}, metricEngine, openrtb_ext.BidderAppnexus, nil)
-	appnexusValidated := addValidatedBidderMiddleware(appnexusBidderAdapted)
+	appnexusValidated := addValidatedBidderMiddleware(addReloadableBidderMiddleware(appnexusBidderAdapted))
 
 	rubiconBidder, _ := rubicon.Builder(openrtb_ext.BidderRubicon, config.Adapter{})
 	rubiconBidderWithInfo := adapters.BuildInfoAwareBidder(rubiconBidder, infoEnabled)
 	rubiconBidderAdapted := adaptBidder(rubiconBidderWithInfo, client, &config.Configuration{}, metricEngine, openrtb_ext.BidderRubicon, nil)
-	rubiconbidderValidated := addValidatedBidderMiddleware(rubiconBidderAdapted)
+	rubiconbidderValidated := addValidatedBidderMiddleware(addReloadableBidderMiddleware(rubiconBidderAdapted))
 
 	testCases := []struct {
 		description     string
@@ -193,6 +193,23 @@ func TestBuildBidders(t *testing.T) {
 	}
 }
 
+func TestSetAliasBuilders(t *testing.T) {
+	appnexusBuilder := fakeBuilder{fakeBidder{"a"}, nil}.Builder
+	builders := map[openrtb_ext.BidderName]adapters.Builder{openrtb_ext.BidderAppnexus: appnexusBuilder}
+
+	setAliasBuilders(builders, map[openrtb_ext.BidderName]openrtb_ext.BidderName{
+		"whitelabel": openrtb_ext.BidderAppnexus,
+		"orphan":     openrtb_ext.BidderRubicon,
+	})
+
+	assert.Len(t, builders, 2)
+	if assert.Contains(t, builders, openrtb_ext.BidderName("whitelabel")) {
+		bidder, err := builders["whitelabel"]("whitelabel", config.Adapter{Endpoint: "https://whitelabel.com/bid"})
+		assert.NoError(t, err)
+		assert.Equal(t, fakeBidder{"a"}, bidder)
+	}
+}
+
 func TestGetActiveBidders(t *testing.T) {
 	testCases := []struct {
 		description string
@@ -271,6 +288,58 @@ func TestGetDisabledBiddersErrorMessages(t *testing.T) {
 	}
 }
 
+func TestReloadBidders(t *testing.T) {
+	initialBidder := adapters.BuildInfoAwareBidder(fakeBidder{"initial"}, infoEnabled)
+	reloadedBidder := fakeBidder{"reloaded"}
+
+	testCases := []struct {
+		description    string
+		adapterConfig  config.Adapter
+		builder        adapters.Builder
+		expectedBidder adapters.Bidder
+	}{
+		{
+			description:    "Rebuilt",
+			adapterConfig:  config.Adapter{Endpoint: "http://reloaded.com"},
+			builder:        fakeBuilder{reloadedBidder, nil}.Builder,
+			expectedBidder: adapters.BuildInfoAwareBidder(reloadedBidder, infoEnabled),
+		},
+		{
+			description:    "Disabled",
+			adapterConfig:  config.Adapter{Disabled: true},
+			builder:        fakeBuilder{reloadedBidder, nil}.Builder,
+			expectedBidder: nil,
+		},
+		{
+			description:    "Builder error keeps the previous bidder",
+			adapterConfig:  config.Adapter{Endpoint: "http://reloaded.com"},
+			builder:        fakeBuilder{nil, errors.New("anyError")}.Builder,
+			expectedBidder: initialBidder,
+		},
+	}
+
+	for _, test := range testCases {
+		bidder := addReloadableBidderMiddleware(adaptBidder(initialBidder, &http.Client{}, &config.Configuration{}, &metrics.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil))
+
+		reloadBidders(
+			map[openrtb_ext.BidderName]*reloadableBidder{openrtb_ext.BidderAppnexus: bidder},
+			map[string]config.Adapter{"appnexus": test.adapterConfig},
+			config.BidderInfos{"appnexus": infoEnabled},
+			map[openrtb_ext.BidderName]adapters.Builder{openrtb_ext.BidderAppnexus: test.builder})
+
+		current := bidder.current.Load().(*bidderAdapter)
+		if test.expectedBidder == nil {
+			assert.Nil(t, current, test.description)
+			continue
+		}
+		if assert.NotNil(t, current, test.description) {
+			assert.Equal(t, test.expectedBidder, current.Bidder, test.description)
+			assert.Equal(t, openrtb_ext.BidderAppnexus, current.BidderName, test.description)
+		}
+		assert.Equal(t, initialBidder, bidder.base.Bidder, test.description+":base")
+	}
+}
+
 type fakeBidder struct {
 	name string
 }
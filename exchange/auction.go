@@ -21,6 +21,10 @@ const (
 	DebugOverrideHeader string = "x-pbs-debug-override"
 )
 
+// cacheTTLBuffer is added to the TTL of the bids stored in prebid cache, to account for the time
+// it takes the publisher to fetch them.
+const cacheTTLBuffer int64 = 60
+
 type DebugLog struct {
 	Enabled       bool
 	CacheType     prebid_cache_client.PayloadType
@@ -104,27 +108,34 @@ func (d *DebugLog) PutDebugLogError(cache prebid_cache_client.Client, timeout in
 	return nil
 }
 
-func newAuction(seatBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid, numImps int, preferDeals bool) *auction {
+func newAuction(seatBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid, numImps int, preferDeals bool, multiBid map[string]openrtb_ext.ExtMultiBid) *auction {
 	winningBids := make(map[string]*pbsOrtbBid, numImps)
-	winningBidsByBidder := make(map[string]map[openrtb_ext.BidderName]*pbsOrtbBid, numImps)
+	winningBidsByBidder := make(map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid, numImps)
 
 	for bidderName, seatBid := range seatBids {
 		if seatBid != nil {
 			for _, bid := range seatBid.bids {
-				cpm := bid.bid.Price
 				wbid, ok := winningBids[bid.bid.ImpID]
 				if !ok || isNewWinningBid(bid.bid, wbid.bid, preferDeals) {
 					winningBids[bid.bid.ImpID] = bid
 				}
-				if bidMap, ok := winningBidsByBidder[bid.bid.ImpID]; ok {
-					bestSoFar, ok := bidMap[bidderName]
-					if !ok || cpm > bestSoFar.bid.Price {
-						bidMap[bidderName] = bid
-					}
-				} else {
-					winningBidsByBidder[bid.bid.ImpID] = make(map[openrtb_ext.BidderName]*pbsOrtbBid)
-					winningBidsByBidder[bid.bid.ImpID][bidderName] = bid
+				if _, ok := winningBidsByBidder[bid.bid.ImpID]; !ok {
+					winningBidsByBidder[bid.bid.ImpID] = make(map[openrtb_ext.BidderName][]*pbsOrtbBid)
 				}
+				winningBidsByBidder[bid.bid.ImpID][bidderName] = append(winningBidsByBidder[bid.bid.ImpID][bidderName], bid)
+			}
+		}
+	}
+
+	for _, topBidsPerImp := range winningBidsByBidder {
+		for bidderName, topBidsPerBidder := range topBidsPerImp {
+			sortBidsByRank(topBidsPerBidder, preferDeals)
+			if limit := targetingBidLimit(multiBid, bidderName); len(topBidsPerBidder) > limit {
+				topBidsPerBidder = topBidsPerBidder[:limit]
+				topBidsPerImp[bidderName] = topBidsPerBidder
+			}
+			for i := 1; i < len(topBidsPerBidder); i++ {
+				topBidsPerBidder[i].targetBidderCode = targetBidderCode(multiBid[string(bidderName)].TargetBidderCodePrefix, i)
 			}
 		}
 	}
@@ -151,8 +162,10 @@ func isNewWinningBid(bid, wbid *openrtb2.Bid, preferDeals bool) bool {
 func (a *auction) setRoundedPrices(priceGranularity openrtb_ext.PriceGranularity) {
 	roundedPrices := make(map[*pbsOrtbBid]string, 5*len(a.winningBids))
 	for _, topBidsPerImp := range a.winningBidsByBidder {
-		for _, topBidPerBidder := range topBidsPerImp {
-			roundedPrices[topBidPerBidder] = GetPriceBucket(topBidPerBidder.bid.Price, priceGranularity)
+		for _, topBidsPerBidder := range topBidsPerImp {
+			for _, topBid := range topBidsPerBidder {
+				roundedPrices[topBid] = GetPriceBucket(topBid.bid.Price, priceGranularity)
+			}
 		}
 	}
 	a.roundedPrices = roundedPrices
@@ -187,63 +200,65 @@ func (a *auction) doCache(ctx context.Context, cache prebid_cache_client.Client,
 		expByImp[imp.ID] = imp.Exp
 	}
 	for _, topBidsPerImp := range a.winningBidsByBidder {
-		for bidderName, topBidPerBidder := range topBidsPerImp {
-			impID := topBidPerBidder.bid.ImpID
-			isOverallWinner := a.winningBids[impID] == topBidPerBidder
-			if !includeBidderKeys && !isOverallWinner {
-				continue
-			}
-			var customCacheKey string
-			var catDur string
-			useCustomCacheKey := false
-			if competitiveExclusion && isOverallWinner || includeBidderKeys {
-				// set custom cache key for winning bid when competitive exclusion applies
-				catDur = bidCategory[topBidPerBidder.bid.ID]
-				if len(catDur) > 0 {
-					customCacheKey = fmt.Sprintf("%s_%s", catDur, hbCacheID)
-					useCustomCacheKey = true
+		for bidderName, topBidsPerBidder := range topBidsPerImp {
+			for _, topBidPerBidder := range topBidsPerBidder {
+				impID := topBidPerBidder.bid.ImpID
+				isOverallWinner := a.winningBids[impID] == topBidPerBidder
+				if !includeBidderKeys && !isOverallWinner {
+					continue
 				}
-			}
-			if bids {
-				if jsonBytes, err := json.Marshal(topBidPerBidder.bid); err == nil {
-					jsonBytes, err = evTracking.modifyBidJSON(topBidPerBidder, bidderName, jsonBytes)
-					if err != nil {
-						errs = append(errs, err)
-					}
-					if useCustomCacheKey {
-						// not allowed if bids is true; log error and cache normally
-						errs = append(errs, errors.New("cannot use custom cache key for non-vast bids"))
+				var customCacheKey string
+				var catDur string
+				useCustomCacheKey := false
+				if competitiveExclusion && isOverallWinner || includeBidderKeys {
+					// set custom cache key for winning bid when competitive exclusion applies
+					catDur = bidCategory[topBidPerBidder.bid.ID]
+					if len(catDur) > 0 {
+						customCacheKey = fmt.Sprintf("%s_%s", catDur, hbCacheID)
+						useCustomCacheKey = true
 					}
-					toCache = append(toCache, prebid_cache_client.Cacheable{
-						Type:       prebid_cache_client.TypeJSON,
-						Data:       jsonBytes,
-						TTLSeconds: cacheTTL(expByImp[impID], topBidPerBidder.bid.Exp, defTTL(topBidPerBidder.bidType, defaultTTLs), ttlBuffer),
-					})
-					bidIndices[len(toCache)-1] = topBidPerBidder.bid
-				} else {
-					errs = append(errs, err)
 				}
-			}
-			if vast && topBidPerBidder.bidType == openrtb_ext.BidTypeVideo {
-				vastXML := makeVAST(topBidPerBidder.bid)
-				if jsonBytes, err := json.Marshal(vastXML); err == nil {
-					if useCustomCacheKey {
+				if bids {
+					if jsonBytes, err := json.Marshal(topBidPerBidder.bid); err == nil {
+						jsonBytes, err = evTracking.modifyBidJSON(topBidPerBidder, bidderName, jsonBytes)
+						if err != nil {
+							errs = append(errs, err)
+						}
+						if useCustomCacheKey {
+							// not allowed if bids is true; log error and cache normally
+							errs = append(errs, errors.New("cannot use custom cache key for non-vast bids"))
+						}
 						toCache = append(toCache, prebid_cache_client.Cacheable{
-							Type:       prebid_cache_client.TypeXML,
+							Type:       prebid_cache_client.TypeJSON,
 							Data:       jsonBytes,
 							TTLSeconds: cacheTTL(expByImp[impID], topBidPerBidder.bid.Exp, defTTL(topBidPerBidder.bidType, defaultTTLs), ttlBuffer),
-							Key:        customCacheKey,
 						})
+						bidIndices[len(toCache)-1] = topBidPerBidder.bid
 					} else {
-						toCache = append(toCache, prebid_cache_client.Cacheable{
-							Type:       prebid_cache_client.TypeXML,
-							Data:       jsonBytes,
-							TTLSeconds: cacheTTL(expByImp[impID], topBidPerBidder.bid.Exp, defTTL(topBidPerBidder.bidType, defaultTTLs), ttlBuffer),
-						})
+						errs = append(errs, err)
+					}
+				}
+				if vast && topBidPerBidder.bidType == openrtb_ext.BidTypeVideo {
+					vastXML := MakeVAST(topBidPerBidder.bid)
+					if jsonBytes, err := json.Marshal(vastXML); err == nil {
+						if useCustomCacheKey {
+							toCache = append(toCache, prebid_cache_client.Cacheable{
+								Type:       prebid_cache_client.TypeXML,
+								Data:       jsonBytes,
+								TTLSeconds: cacheTTL(expByImp[impID], topBidPerBidder.bid.Exp, defTTL(topBidPerBidder.bidType, defaultTTLs), ttlBuffer),
+								Key:        customCacheKey,
+							})
+						} else {
+							toCache = append(toCache, prebid_cache_client.Cacheable{
+								Type:       prebid_cache_client.TypeXML,
+								Data:       jsonBytes,
+								TTLSeconds: cacheTTL(expByImp[impID], topBidPerBidder.bid.Exp, defTTL(topBidPerBidder.bidType, defaultTTLs), ttlBuffer),
+							})
+						}
+						vastIndices[len(toCache)-1] = topBidPerBidder.bid
+					} else {
+						errs = append(errs, err)
 					}
-					vastIndices[len(toCache)-1] = topBidPerBidder.bid
-				} else {
-					errs = append(errs, err)
 				}
 			}
 		}
@@ -291,9 +306,9 @@ func (a *auction) doCache(ctx context.Context, cache prebid_cache_client.Client,
 	return errs
 }
 
-// makeVAST returns some VAST XML for the given bid. If AdM is defined,
+// MakeVAST returns some VAST XML for the given bid. If AdM is defined,
 // it takes precedence. Otherwise the Nurl will be wrapped in a redirect tag.
-func makeVAST(bid *openrtb2.Bid) string {
+func MakeVAST(bid *openrtb2.Bid) string {
 	if bid.AdM == "" {
 		return `<VAST version="3.0"><Ad><Wrapper>` +
 			`<AdSystem>prebid.org wrapper</AdSystem>` +
@@ -337,6 +352,11 @@ func cacheTTL(impTTL int64, bidTTL int64, defTTL int64, buffer int64) (ttl int64
 	return addBuffer(bidTTL, buffer)
 }
 
+// BidCacheTTL returns the TTL with which the auction stores a bid of the given type in prebid cache.
+func BidCacheTTL(impTTL int64, bidTTL int64, bidType openrtb_ext.BidType, defaultTTLs *config.DefaultTTLs) int64 {
+	return cacheTTL(impTTL, bidTTL, defTTL(bidType, defaultTTLs), cacheTTLBuffer)
+}
+
 func addBuffer(base int64, buffer int64) int64 {
 	if base <= 0 {
 		return 0
@@ -361,8 +381,9 @@ func defTTL(bidType openrtb_ext.BidType, defaultTTLs *config.DefaultTTLs) (ttl i
 type auction struct {
 	// winningBids is a map from imp.id to the highest overall CPM bid in that imp.
 	winningBids map[string]*pbsOrtbBid
-	// winningBidsByBidder stores the highest bid on each imp by each bidder.
-	winningBidsByBidder map[string]map[openrtb_ext.BidderName]*pbsOrtbBid
+	// winningBidsByBidder stores the bids on each imp by each bidder which get targeting keys, from best to worst.
+	// It holds the best bid of each bidder, followed by the extra bids allowed by multibid.
+	winningBidsByBidder map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid
 	// roundedPrices stores the price strings rounded for each bid according to the price granularity.
 	roundedPrices map[*pbsOrtbBid]string
 	// cacheIds stores the UUIDs from Prebid Cache for fetching the full bid JSON.
@@ -24,7 +24,7 @@ func TestMakeVASTGiven(t *testing.T) {
 	bid := &openrtb2.Bid{
 		AdM: expect,
 	}
-	vast := makeVAST(bid)
+	vast := MakeVAST(bid)
 	assert.Equal(t, expect, vast)
 }
 
@@ -38,7 +38,7 @@ func TestMakeVASTNurl(t *testing.T) {
 	bid := &openrtb2.Bid{
 		NURL: url,
 	}
-	vast := makeVAST(bid)
+	vast := MakeVAST(bid)
 	assert.Equal(t, expect, vast)
 }
 
@@ -188,7 +188,7 @@ func loadCacheSpec(filename string) (*cacheSpec, error) {
 func runCacheSpec(t *testing.T, fileDisplayName string, specData *cacheSpec) {
 	var bid *pbsOrtbBid
 	winningBidsByImp := make(map[string]*pbsOrtbBid)
-	winningBidsByBidder := make(map[string]map[openrtb_ext.BidderName]*pbsOrtbBid)
+	winningBidsByBidder := make(map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid)
 	roundedPrices := make(map[*pbsOrtbBid]string)
 	bidCategory := make(map[string]string)
 
@@ -209,12 +209,12 @@ func runCacheSpec(t *testing.T, fileDisplayName string, specData *cacheSpec) {
 		// Map this bid if it's the highest we've seen from this bidder so far
 		if _, ok := winningBidsByBidder[bid.bid.ImpID]; ok {
 			bestSoFar, ok := winningBidsByBidder[bid.bid.ImpID][pbsBid.Bidder]
-			if !ok || cpm > bestSoFar.bid.Price {
-				winningBidsByBidder[bid.bid.ImpID][pbsBid.Bidder] = bid
+			if !ok || cpm > bestSoFar[0].bid.Price {
+				winningBidsByBidder[bid.bid.ImpID][pbsBid.Bidder] = []*pbsOrtbBid{bid}
 			}
 		} else {
-			winningBidsByBidder[bid.bid.ImpID] = make(map[openrtb_ext.BidderName]*pbsOrtbBid)
-			winningBidsByBidder[bid.bid.ImpID][pbsBid.Bidder] = bid
+			winningBidsByBidder[bid.bid.ImpID] = make(map[openrtb_ext.BidderName][]*pbsOrtbBid)
+			winningBidsByBidder[bid.bid.ImpID][pbsBid.Bidder] = []*pbsOrtbBid{bid}
 		}
 
 		if len(pbsBid.Bid.Cat) == 1 {
@@ -384,10 +384,10 @@ func TestNewAuction(t *testing.T) {
 				winningBids: map[string]*pbsOrtbBid{
 					"imp1": &bid1p230,
 				},
-				winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+				winningBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
 					"imp1": {
-						"appnexus": &bid1p123,
-						"rubicon":  &bid1p230,
+						"appnexus": {&bid1p123},
+						"rubicon":  {&bid1p230},
 					},
 				},
 			},
@@ -412,15 +412,15 @@ func TestNewAuction(t *testing.T) {
 					"imp1": &bid1p230,
 					"imp2": &bid2p144,
 				},
-				winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+				winningBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
 					"imp1": {
-						"appnexus": &bid1p230,
-						"rubicon":  &bid1p077,
-						"openx":    &bid1p123,
+						"appnexus": {&bid1p230},
+						"rubicon":  {&bid1p077},
+						"openx":    {&bid1p123},
 					},
 					"imp2": {
-						"appnexus": &bid2p123,
-						"rubicon":  &bid2p144,
+						"appnexus": {&bid2p123},
+						"rubicon":  {&bid2p144},
 					},
 				},
 			},
@@ -441,10 +441,10 @@ func TestNewAuction(t *testing.T) {
 				winningBids: map[string]*pbsOrtbBid{
 					"imp1": &bid1p123,
 				},
-				winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+				winningBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
 					"imp1": {
-						"appnexus": &bid1p123,
-						"rubicon":  &bid1p088d,
+						"appnexus": {&bid1p123},
+						"rubicon":  {&bid1p088d},
 					},
 				},
 			},
@@ -465,10 +465,10 @@ func TestNewAuction(t *testing.T) {
 				winningBids: map[string]*pbsOrtbBid{
 					"imp1": &bid1p088d,
 				},
-				winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+				winningBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
 					"imp1": {
-						"appnexus": &bid1p123,
-						"rubicon":  &bid1p088d,
+						"appnexus": {&bid1p123},
+						"rubicon":  {&bid1p088d},
 					},
 				},
 			},
@@ -489,10 +489,10 @@ func TestNewAuction(t *testing.T) {
 				winningBids: map[string]*pbsOrtbBid{
 					"imp1": &bid1p166d,
 				},
-				winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+				winningBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
 					"imp1": {
-						"appnexus": &bid1p166d,
-						"rubicon":  &bid1p088d,
+						"appnexus": {&bid1p166d},
+						"rubicon":  {&bid1p088d},
 					},
 				},
 			},
@@ -516,11 +516,11 @@ func TestNewAuction(t *testing.T) {
 				winningBids: map[string]*pbsOrtbBid{
 					"imp1": &bid1p166d,
 				},
-				winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+				winningBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
 					"imp1": {
-						"appnexus": &bid1p166d,
-						"rubicon":  &bid1p088d,
-						"openx":    &bid1p230,
+						"appnexus": {&bid1p166d},
+						"rubicon":  {&bid1p088d},
+						"openx":    {&bid1p230},
 					},
 				},
 			},
@@ -528,7 +528,7 @@ func TestNewAuction(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		auc := newAuction(test.seatBids, test.numImps, test.preferDeals)
+		auc := newAuction(test.seatBids, test.numImps, test.preferDeals, nil)
 
 		assert.Equal(t, test.expectedAuction, *auc, test.description)
 	}
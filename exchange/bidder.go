@@ -2,14 +2,17 @@ package exchange
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"crypto/tls"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptrace"
+	"strings"
 	"time"
 
 	"github.com/golang/glog"
@@ -20,10 +23,16 @@ import (
 	nativeResponse "github.com/mxmCherry/openrtb/v15/native1/response"
 	"github.com/mxmCherry/openrtb/v15/openrtb2"
 	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/analytics"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/hooks/hookexecution"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/net/context/ctxhttp"
 )
 
@@ -49,7 +58,9 @@ type adaptedBidder interface {
 	//
 	// Any errors will be user-facing in the API.
 	// Error messages should help publishers understand what might account for "bad" bids.
-	requestBid(ctx context.Context, request *openrtb2.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, accountDebugAllowed, headerDebugAllowed bool) (*pbsOrtbSeatBid, []error)
+	//
+	// The bidder-request and raw-bidder-response hook stages are run through the hookExecutor.
+	requestBid(ctx context.Context, request *openrtb2.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, accountDebugAllowed, headerDebugAllowed bool, hookExecutor hookexecution.StageExecutor) (*pbsOrtbSeatBid, []error)
 }
 
 // pbsOrtbBid is a Bid returned by an adaptedBidder.
@@ -62,6 +73,7 @@ type adaptedBidder interface {
 // pbsOrtbBid.dealPriority is optionally provided by adapters and used internally by the exchange to support deal targeted campaigns.
 // pbsOrtbBid.dealTierSatisfied is set to true by exchange.updateHbPbCatDur if deal tier satisfied otherwise it will be set to false
 // pbsOrtbBid.generatedBidID is unique bid id generated by prebid server if generate bid id option is enabled in config
+// pbsOrtbBid.bidFloors is set by exchange when a price floor applied to the bid's imp
 type pbsOrtbBid struct {
 	bid               *openrtb2.Bid
 	bidType           openrtb_ext.BidType
@@ -71,6 +83,9 @@ type pbsOrtbBid struct {
 	dealPriority      int
 	dealTierSatisfied bool
 	generatedBidID    string
+	bidFloors         *openrtb_ext.ExtBidPrebidFloors
+	// targetBidderCode replaces the bidder name in the targeting keys of the extra bids allowed by multibid.
+	targetBidderCode openrtb_ext.BidderName
 }
 
 // pbsOrtbSeatBid is a SeatBid returned by an adaptedBidder.
@@ -85,23 +100,43 @@ type pbsOrtbSeatBid struct {
 	// httpCalls is the list of debugging info. It should only be populated if the request.test == 1.
 	// This will become response.ext.debug.httpcalls.{bidder} on the final Response.
 	httpCalls []*openrtb_ext.ExtHttpCall
+	// httpCallDetails is the status and latency of every HTTP call made to the bidder, for the analytics.
+	httpCallDetails []*analytics.HttpCallDetails
+	// receivedBids are all the bids returned by the bidder, before any of them were validated.
+	receivedBids []*openrtb2.Bid
+	// rejectedBids are the bids which were removed from the auction, along with the reason why.
+	rejectedBids []*analytics.RejectedBid
+}
+
+// rejectBid records that the bid was removed from the auction for the given reason.
+// It doesn't remove the bid from the seat's bids, which is up to the caller.
+func (sb *pbsOrtbSeatBid) rejectBid(bid *openrtb2.Bid, reason openrtb_ext.RejectionReason, message string) {
+	sb.rejectedBids = append(sb.rejectedBids, &analytics.RejectedBid{Bid: bid, Reason: reason, Message: message})
 }
 
 // adaptBidder converts an adapters.Bidder into an exchange.adaptedBidder.
 //
 // The name refers to the "Adapter" architecture pattern, and should not be confused with a Prebid "Adapter"
 // (which is being phased out and replaced by Bidder for OpenRTB auctions)
-func adaptBidder(bidder adapters.Bidder, client *http.Client, cfg *config.Configuration, me metrics.MetricsEngine, name openrtb_ext.BidderName, debugInfo *config.DebugInfo) adaptedBidder {
+func adaptBidder(bidder adapters.Bidder, client *http.Client, cfg *config.Configuration, me metrics.MetricsEngine, name openrtb_ext.BidderName, debugInfo *config.DebugInfo) *bidderAdapter {
+	var latency *latencyTracker
+	if cfg.AdaptiveBidderTimeouts.Enabled {
+		latency = newLatencyTracker(cfg.AdaptiveBidderTimeouts.SampleSize)
+	}
 	return &bidderAdapter{
 		Bidder:     bidder,
 		BidderName: name,
 		Client:     client,
 		me:         me,
 		config: bidderAdapterConfig{
-			Debug:              cfg.Debug,
-			DisableConnMetrics: cfg.Metrics.Disabled.AdapterConnectionMetrics,
-			DebugInfo:          config.DebugInfo{Allow: parseDebugInfo(debugInfo)},
+			Debug:                 cfg.Debug,
+			DisableConnMetrics:    cfg.Metrics.Disabled.AdapterConnectionMetrics,
+			DebugInfo:             config.DebugInfo{Allow: parseDebugInfo(debugInfo)},
+			AdaptiveTimeouts:      cfg.AdaptiveBidderTimeouts,
+			EndpointCompression:   cfg.Adapters[strings.ToLower(string(name))].EndpointCompression,
+			PropagateTraceContext: cfg.Tracing.PropagateToBidders,
 		},
+		latency: latency,
 	}
 }
 
@@ -118,18 +153,46 @@ type bidderAdapter struct {
 	Client     *http.Client
 	me         metrics.MetricsEngine
 	config     bidderAdapterConfig
+	// latency holds the bidder's recent response times when adaptive timeouts are enabled, nil otherwise
+	latency *latencyTracker
 }
 
 type bidderAdapterConfig struct {
-	Debug              config.Debug
-	DisableConnMetrics bool
-	DebugInfo          config.DebugInfo
+	Debug               config.Debug
+	DisableConnMetrics  bool
+	DebugInfo           config.DebugInfo
+	AdaptiveTimeouts    config.AdaptiveBidderTimeouts
+	EndpointCompression string
+	// PropagateTraceContext sends the W3C traceparent of the bidder call to the bidder
+	PropagateTraceContext bool
 }
 
-func (bidder *bidderAdapter) requestBid(ctx context.Context, request *openrtb2.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, accountDebugAllowed, headerDebugAllowed bool) (*pbsOrtbSeatBid, []error) {
-	reqData, errs := bidder.Bidder.MakeRequests(request, reqInfo)
+func (bidder *bidderAdapter) requestBid(ctx context.Context, request *openrtb2.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, accountDebugAllowed, headerDebugAllowed bool, hookExecutor hookexecution.StageExecutor) (*pbsOrtbSeatBid, []error) {
+	if rejectErr := hookExecutor.ExecuteBidderRequestStage(request, string(name)); rejectErr != nil {
+		return nil, []error{rejectErr}
+	}
 
-	if len(reqData) == 0 {
+	if bidder.latency != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = makeBidderContext(ctx, bidder.latency, bidder.config.AdaptiveTimeouts)
+		defer cancel()
+		// Let the bidder know about the time it actually has to respond
+		if deadline, ok := ctx.Deadline(); ok {
+			if tmax := time.Until(deadline).Milliseconds(); tmax > 0 {
+				requestCopy := *request
+				requestCopy.TMax = tmax
+				request = &requestCopy
+			}
+		}
+	}
+
+	var reqData []*adapters.RequestData
+	var errs []error
+	if liveRequest := removeImpsWithStoredBidResponses(request, reqInfo.StoredBidResponses); len(liveRequest.Imp) > 0 || len(reqInfo.StoredBidResponses) == 0 {
+		reqData, errs = bidder.Bidder.MakeRequests(liveRequest, reqInfo)
+	}
+
+	if len(reqData) == 0 && len(reqInfo.StoredBidResponses) == 0 {
 		// If the adapter failed to generate both requests and errors, this is an error.
 		if len(errs) == 0 {
 			errs = append(errs, &errortypes.FailedToRequestBids{Message: "The adapter failed to generate any bid requests, but also failed to generate an error explaining why"})
@@ -152,7 +215,14 @@ func (bidder *bidderAdapter) requestBid(ctx context.Context, request *openrtb2.B
 
 	// Make any HTTP requests in parallel.
 	// If the bidder only needs to make one, save some cycles by just using the current one.
-	responseChannel := make(chan *httpCallInfo, len(reqData))
+	responseChannel := make(chan *httpCallInfo, len(reqData)+len(reqInfo.StoredBidResponses))
+	responseCount := len(reqData)
+	for _, imp := range request.Imp {
+		if storedBidResponse, ok := reqInfo.StoredBidResponses[imp.ID]; ok {
+			responseChannel <- makeStoredBidResponseCall(request, imp, storedBidResponse)
+			responseCount++
+		}
+	}
 	if len(reqData) == 1 {
 		responseChannel <- bidder.doRequest(ctx, reqData[0])
 	} else {
@@ -172,8 +242,9 @@ func (bidder *bidderAdapter) requestBid(ctx context.Context, request *openrtb2.B
 
 	// If the bidder made multiple requests, we still want them to enter as many bids as possible...
 	// even if the timeout occurs sometime halfway through.
-	for i := 0; i < len(reqData); i++ {
+	for i := 0; i < responseCount; i++ {
 		httpInfo := <-responseChannel
+		seatBid.httpCallDetails = append(seatBid.httpCallDetails, makeHttpCallDetails(httpInfo))
 		// If this is a test bid, capture debugging info from the requests.
 		// Write debug data to ext in case if:
 		// - headerDebugAllowed (debug override header specified correct) - it overrides all other debug restrictions
@@ -181,13 +252,13 @@ func (bidder *bidderAdapter) requestBid(ctx context.Context, request *openrtb2.B
 		// - account debug is allowed
 		// - bidder debug is allowed
 		if headerDebugAllowed {
-			seatBid.httpCalls = append(seatBid.httpCalls, makeExt(httpInfo))
+			seatBid.httpCalls = append(seatBid.httpCalls, makeExt(httpInfo, bidder.coreBidder()))
 		} else {
 			debugInfo := ctx.Value(DebugContextKey)
 			if debugInfo != nil && debugInfo.(bool) {
 				if accountDebugAllowed {
 					if bidder.config.DebugInfo.Allow {
-						seatBid.httpCalls = append(seatBid.httpCalls, makeExt(httpInfo))
+						seatBid.httpCalls = append(seatBid.httpCalls, makeExt(httpInfo, bidder.coreBidder()))
 					} else {
 						debugDisabledWarning := errortypes.Warning{
 							WarningCode: errortypes.BidderLevelDebugDisabledWarningCode,
@@ -204,6 +275,27 @@ func (bidder *bidderAdapter) requestBid(ctx context.Context, request *openrtb2.B
 			errs = append(errs, moreErrs...)
 
 			if bidResponse != nil {
+				var rejectErr *hookexecution.RejectError
+				for _, typedBid := range bidResponse.Bids {
+					// The bids of a stored response are made for the imp the response is stored for
+					if typedBid != nil && typedBid.Bid != nil && httpInfo.storedImpID != "" {
+						typedBid.Bid.ImpID = httpInfo.storedImpID
+					}
+					if typedBid != nil && typedBid.Bid != nil {
+						// Copied, since the price of the bid is adjusted and converted in place
+						receivedBid := *typedBid.Bid
+						seatBid.receivedBids = append(seatBid.receivedBids, &receivedBid)
+					}
+				}
+
+				rawBids := bidResponse.Bids
+				if bidResponse.Bids, rejectErr = hookExecutor.ExecuteRawBidderResponseStage(rawBids, string(name)); rejectErr != nil {
+					errs = append(errs, rejectErr)
+					rejectBidsRemovedByHooks(seatBid, rawBids, nil, "Bidder response rejected by a raw bidder response hook")
+					continue
+				}
+				rejectBidsRemovedByHooks(seatBid, rawBids, bidResponse.Bids, "Bid removed by a raw bidder response hook")
+
 				// Setup default currency as `USD` is not set in bid request nor bid response
 				if bidResponse.Currency == "" {
 					bidResponse.Currency = defaultCurrency
@@ -217,12 +309,17 @@ func (bidder *bidderAdapter) requestBid(ctx context.Context, request *openrtb2.B
 				// and use it as currency
 				var conversionRate float64
 				var err error
+				_, conversionSpan := tracing.StartSpan(ctx, "currency.Convert",
+					attribute.String("bidder", string(name)),
+					attribute.String("currency.from", bidResponse.Currency))
 				for _, bidReqCur := range request.Cur {
 					if conversionRate, err = conversions.GetRate(bidResponse.Currency, bidReqCur); err == nil {
 						seatBid.currency = bidReqCur
+						conversionSpan.SetAttributes(attribute.String("currency.to", bidReqCur))
 						break
 					}
 				}
+				tracing.EndSpan(conversionSpan, err)
 
 				// Only do this for request from mobile app
 				if request.App != nil {
@@ -259,6 +356,9 @@ func (bidder *bidderAdapter) requestBid(ctx context.Context, request *openrtb2.B
 				} else {
 					// If no conversions found, do not handle the bid
 					errs = append(errs, err)
+					for _, typedBid := range bidResponse.Bids {
+						seatBid.rejectBid(typedBid.Bid, openrtb_ext.RejectionReasonCurrencyConversion, err.Error())
+					}
 				}
 			}
 		} else {
@@ -360,13 +460,39 @@ func filterHeader(h http.Header) http.Header {
 	return clone
 }
 
+// makeHttpCallDetails summarizes the HTTP call for the analytics.
+func makeHttpCallDetails(httpInfo *httpCallInfo) *analytics.HttpCallDetails {
+	details := &analytics.HttpCallDetails{
+		Latency: httpInfo.latency,
+	}
+	if httpInfo.request != nil {
+		details.Uri = httpInfo.request.Uri
+	}
+	if httpInfo.response != nil {
+		details.Status = httpInfo.response.StatusCode
+	}
+	return details
+}
+
+// coreBidder returns the bidder implementation wrapped by the bidder adapter.
+func (bidder *bidderAdapter) coreBidder() adapters.Bidder {
+	var corebidder adapters.Bidder = bidder.Bidder
+	// The bidder adapter normally stores an info-aware bidder (a bidder wrapper)
+	// rather than the actual bidder. So we need to unpack that first.
+	if b, ok := corebidder.(*adapters.InfoAwareBidder); ok {
+		corebidder = b.Bidder
+	}
+	return corebidder
+}
+
 // makeExt transforms information about the HTTP call into the contract class for the PBS response.
-func makeExt(httpInfo *httpCallInfo) *openrtb_ext.ExtHttpCall {
+// The request body of a bidder which doesn't send JSON is shown as decoded by the bidder.
+func makeExt(httpInfo *httpCallInfo, bidder adapters.Bidder) *openrtb_ext.ExtHttpCall {
 	ext := &openrtb_ext.ExtHttpCall{}
 
 	if httpInfo != nil && httpInfo.request != nil {
 		ext.Uri = httpInfo.request.Uri
-		ext.RequestBody = string(httpInfo.request.Body)
+		ext.RequestBody = makeExtRequestBody(httpInfo.request, bidder)
 		ext.RequestHeaders = filterHeader(httpInfo.request.Headers)
 
 		if httpInfo.err == nil && httpInfo.response != nil {
@@ -378,38 +504,103 @@ func makeExt(httpInfo *httpCallInfo) *openrtb_ext.ExtHttpCall {
 	return ext
 }
 
+// makeExtRequestBody returns the request body shown in the debug output. Binary bodies which the bidder
+// can't decode are shown base64 encoded.
+func makeExtRequestBody(req *adapters.RequestData, bidder adapters.Bidder) string {
+	binaryBidder, ok := bidder.(adapters.BinaryBodyBidder)
+	if !ok {
+		return string(req.Body)
+	}
+	body, err := binaryBidder.DecodeRequestBody(req)
+	if err != nil {
+		return base64.StdEncoding.EncodeToString(req.Body)
+	}
+	return string(body)
+}
+
+// compressRequest returns the body and headers sent to the bidder, compressed according to the bidder's
+// endpoint compression. The request data is left untouched so that the debug output shows the original body.
+func (bidder *bidderAdapter) compressRequest(req *adapters.RequestData) ([]byte, http.Header, error) {
+	if bidder.config.EndpointCompression != config.CompressionGZIP || len(req.Body) == 0 {
+		return req.Body, req.Headers, nil
+	}
+
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	if _, err := writer.Write(req.Body); err != nil {
+		return nil, nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	headers := req.Headers.Clone()
+	if headers == nil {
+		headers = http.Header{}
+	}
+	headers.Set("Content-Encoding", "gzip")
+
+	bidder.me.RecordAdapterGzipRequestSize(bidder.BidderName, buffer.Len())
+	return buffer.Bytes(), headers, nil
+}
+
 // doRequest makes a request, handles the response, and returns the data needed by the
 // Bidder interface.
 func (bidder *bidderAdapter) doRequest(ctx context.Context, req *adapters.RequestData) *httpCallInfo {
 	return bidder.doRequestImpl(ctx, req, glog.Warningf)
 }
 
-func (bidder *bidderAdapter) doRequestImpl(ctx context.Context, req *adapters.RequestData, logger util.LogMsg) *httpCallInfo {
-	httpReq, err := http.NewRequest(req.Method, req.Uri, bytes.NewBuffer(req.Body))
+func (bidder *bidderAdapter) doRequestImpl(ctx context.Context, req *adapters.RequestData, logger util.LogMsg) (callInfo *httpCallInfo) {
+	ctx, span := tracing.StartSpan(ctx, "bidder.request",
+		attribute.String("bidder", string(bidder.BidderName)),
+		semconv.HTTPMethodKey.String(req.Method))
+	defer func() {
+		if callInfo.response != nil {
+			span.SetAttributes(semconv.HTTPStatusCodeKey.Int(callInfo.response.StatusCode))
+		}
+		tracing.EndSpan(span, callInfo.err)
+	}()
+
+	body, headers, err := bidder.compressRequest(req)
 	if err != nil {
 		return &httpCallInfo{
 			request: req,
 			err:     err,
 		}
 	}
-	httpReq.Header = req.Headers
+	httpReq, err := http.NewRequest(req.Method, req.Uri, bytes.NewBuffer(body))
+	if err != nil {
+		return &httpCallInfo{
+			request: req,
+			err:     err,
+		}
+	}
+	httpReq.Header = headers
+	span.SetAttributes(semconv.HTTPHostKey.String(httpReq.URL.Host))
+	if bidder.config.PropagateTraceContext {
+		// Cloned, since the headers are also those shown in the debug output
+		httpReq.Header = headers.Clone()
+		if httpReq.Header == nil {
+			httpReq.Header = http.Header{}
+		}
+		tracing.Inject(ctx, httpReq.Header)
+	}
 
-	// If adapter connection metrics are not disabled, add the client trace
-	// to get complete connection info into our metrics
-	if !bidder.config.DisableConnMetrics {
+	// If adapter connection metrics are not disabled, or the call is traced, add the client trace
+	// to get complete connection info into our metrics and the span
+	if !bidder.config.DisableConnMetrics || span.IsRecording() {
 		ctx = bidder.addClientTrace(ctx)
 	}
+	bidder.me.RecordAdapterRequestSize(bidder.BidderName, len(req.Body))
+	start := time.Now()
 	httpResp, err := ctxhttp.Do(ctx, bidder.Client, httpReq)
 	if err != nil {
+		// The bidder took at least this long, so the sample keeps a bidder which times out from having its
+		// budget lowered by its fast responses only
+		bidder.recordCutOffLatency(ctx, time.Since(start))
 		if err == context.DeadlineExceeded {
-			err = &errortypes.Timeout{Message: err.Error()}
-			var corebidder adapters.Bidder = bidder.Bidder
-			// The bidder adapter normally stores an info-aware bidder (a bidder wrapper)
-			// rather than the actual bidder. So we need to unpack that first.
-			if b, ok := corebidder.(*adapters.InfoAwareBidder); ok {
-				corebidder = b.Bidder
-			}
-			if tb, ok := corebidder.(adapters.TimeoutBidder); ok {
+			err = newBidderTimeout(ctx, err, start)
+			if tb, ok := bidder.coreBidder().(adapters.TimeoutBidder); ok {
 				// Toss the timeout notification call into a go routine, as we are out of time'
 				// and cannot delay processing. We don't do anything result, as there is not much
 				// we can do about a timeout notification failure. We do not want to get stuck in
@@ -421,18 +612,24 @@ func (bidder *bidderAdapter) doRequestImpl(ctx context.Context, req *adapters.Re
 		return &httpCallInfo{
 			request: req,
 			err:     err,
+			latency: time.Since(start),
 		}
 	}
 
 	respBody, err := ioutil.ReadAll(httpResp.Body)
 	if err != nil {
+		bidder.recordCutOffLatency(ctx, time.Since(start))
 		return &httpCallInfo{
 			request: req,
 			err:     err,
+			latency: time.Since(start),
 		}
 	}
 	defer httpResp.Body.Close()
 
+	latency := time.Since(start)
+	bidder.recordLatency(latency)
+
 	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 400 {
 		err = &errortypes.BadServerResponse{
 			Message: fmt.Sprintf("Server responded with failure status: %d. Set request.test = 1 for debugging info.", httpResp.StatusCode),
@@ -446,7 +643,24 @@ func (bidder *bidderAdapter) doRequestImpl(ctx context.Context, req *adapters.Re
 			Body:       respBody,
 			Headers:    httpResp.Header,
 		},
-		err: err,
+		err:     err,
+		latency: latency,
+	}
+}
+
+// recordLatency adds the response time of a request to the bidder's latencies, if adaptive timeouts are enabled.
+func (bidder *bidderAdapter) recordLatency(latency time.Duration) {
+	if bidder.latency != nil {
+		bidder.latency.record(latency)
+	}
+}
+
+// recordCutOffLatency records the time a request took before it failed. The requests cut off by the request's tmax
+// or auction timeout are left out, since they tell how short the deadline was rather than how long the bidder takes,
+// and would lower the budget given to every request.
+func (bidder *bidderAdapter) recordCutOffLatency(ctx context.Context, latency time.Duration) {
+	if !isCutOff(ctx) {
+		bidder.recordLatency(latency)
 	}
 }
 
@@ -495,6 +709,51 @@ type httpCallInfo struct {
 	request  *adapters.RequestData
 	response *adapters.ResponseData
 	err      error
+	// latency is the time from sending the request to getting the response or the error, which is 0 if the
+	// request couldn't be built.
+	latency time.Duration
+	// storedImpID is the imp the response is stored for, if it is a stored bid response rather than a bidder's.
+	storedImpID string
+}
+
+// removeImpsWithStoredBidResponses returns a copy of the request without the imps which have a stored bid response,
+// or the request itself if there aren't any.
+func removeImpsWithStoredBidResponses(request *openrtb2.BidRequest, storedBidResponses map[string]json.RawMessage) *openrtb2.BidRequest {
+	if len(storedBidResponses) == 0 {
+		return request
+	}
+
+	requestCopy := *request
+	requestCopy.Imp = make([]openrtb2.Imp, 0, len(request.Imp))
+	for _, imp := range request.Imp {
+		if _, ok := storedBidResponses[imp.ID]; !ok {
+			requestCopy.Imp = append(requestCopy.Imp, imp)
+		}
+	}
+	return &requestCopy
+}
+
+// makeStoredBidResponseCall returns a successful call to the bidder, as if it had responded with the stored bid
+// response. The request body holds the request for the single imp, for the bidders which read it in MakeBids.
+func makeStoredBidResponseCall(request *openrtb2.BidRequest, imp openrtb2.Imp, storedBidResponse json.RawMessage) *httpCallInfo {
+	impRequest := *request
+	impRequest.Imp = []openrtb2.Imp{imp}
+	body, err := json.Marshal(impRequest)
+	if err != nil {
+		return &httpCallInfo{err: err}
+	}
+
+	return &httpCallInfo{
+		request: &adapters.RequestData{
+			Method: http.MethodPost,
+			Body:   body,
+		},
+		response: &adapters.ResponseData{
+			StatusCode: http.StatusOK,
+			Body:       storedBidResponse,
+		},
+		storedImpID: imp.ID,
+	}
 }
 
 // This function adds an httptrace.ClientTrace object to the context so, if connection with the bidder
@@ -502,8 +761,10 @@ type httpCallInfo struct {
 // the time from the connection request, to the connection creation.
 func (bidder *bidderAdapter) addClientTrace(ctx context.Context) context.Context {
 	var connStart, dnsStart, tlsStart time.Time
+	recordMetrics := !bidder.config.DisableConnMetrics
+	span := trace.SpanFromContext(ctx)
 
-	trace := &httptrace.ClientTrace{
+	clientTrace := &httptrace.ClientTrace{
 		// GetConn is called before a connection is created or retrieved from an idle pool
 		GetConn: func(hostPort string) {
 			connStart = time.Now()
@@ -512,7 +773,12 @@ func (bidder *bidderAdapter) addClientTrace(ctx context.Context) context.Context
 		GotConn: func(info httptrace.GotConnInfo) {
 			connWaitTime := time.Now().Sub(connStart)
 
-			bidder.me.RecordAdapterConnections(bidder.BidderName, info.Reused, connWaitTime)
+			if recordMetrics {
+				bidder.me.RecordAdapterConnections(bidder.BidderName, info.Reused, connWaitTime)
+			}
+			span.AddEvent("connection obtained", trace.WithAttributes(
+				attribute.Bool("reused", info.Reused),
+				attribute.Int64("wait_ms", connWaitTime.Milliseconds())))
 		},
 		// DNSStart is called when a DNS lookup begins.
 		DNSStart: func(info httptrace.DNSStartInfo) {
@@ -522,7 +788,10 @@ func (bidder *bidderAdapter) addClientTrace(ctx context.Context) context.Context
 		DNSDone: func(info httptrace.DNSDoneInfo) {
 			dnsLookupTime := time.Now().Sub(dnsStart)
 
-			bidder.me.RecordDNSTime(dnsLookupTime)
+			if recordMetrics {
+				bidder.me.RecordDNSTime(dnsLookupTime)
+			}
+			span.AddEvent("dns done", trace.WithAttributes(attribute.Int64("duration_ms", dnsLookupTime.Milliseconds())))
 		},
 
 		TLSHandshakeStart: func() {
@@ -532,8 +801,16 @@ func (bidder *bidderAdapter) addClientTrace(ctx context.Context) context.Context
 		TLSHandshakeDone: func(tls.ConnectionState, error) {
 			tlsHandshakeTime := time.Now().Sub(tlsStart)
 
-			bidder.me.RecordTLSHandshakeTime(tlsHandshakeTime)
+			if recordMetrics {
+				bidder.me.RecordTLSHandshakeTime(tlsHandshakeTime)
+			}
+			span.AddEvent("tls handshake done", trace.WithAttributes(attribute.Int64("duration_ms", tlsHandshakeTime.Milliseconds())))
+		},
+
+		// GotFirstResponseByte is called when the first byte of the response headers is available.
+		GotFirstResponseByte: func() {
+			span.AddEvent("first response byte")
 		},
 	}
-	return httptrace.WithClientTrace(ctx, trace)
+	return httptrace.WithClientTrace(ctx, clientTrace)
 }
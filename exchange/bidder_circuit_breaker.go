@@ -0,0 +1,227 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/hooks/hookexecution"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// addCircuitBreakerBidderMiddleware returns a bidder which skips the argument bidder for a cooldown period once
+// too many of its recent requests timed out, got a 5xx response or failed to connect.
+//
+// The goal here is to stop waiting out the auction timeout on a bidder whose endpoint is degraded, which drags
+// down the fill of every other bidder in the auction.
+func addCircuitBreakerBidderMiddleware(bidder adaptedBidder, bidderName openrtb_ext.BidderName, cfg config.AdapterCircuitBreaker, me metrics.MetricsEngine) adaptedBidder {
+	if !cfg.Enabled {
+		return bidder
+	}
+	return &circuitBreakerBidder{
+		bidder:     bidder,
+		breaker:    newCircuitBreaker(bidderName, cfg, me, time.Now),
+		minTimeout: time.Duration(cfg.MinTimeoutMillis) * time.Millisecond,
+	}
+}
+
+type circuitBreakerBidder struct {
+	bidder  adaptedBidder
+	breaker *circuitBreaker
+	// minTimeout is the shortest time to respond after which a timeout counts as a failure
+	minTimeout time.Duration
+}
+
+func (b *circuitBreakerBidder) requestBid(ctx context.Context, request *openrtb2.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, accountDebugAllowed, headerDebugAllowed bool, hookExecutor hookexecution.StageExecutor) (*pbsOrtbSeatBid, []error) {
+	if !b.breaker.allow() {
+		return nil, []error{&errortypes.BidderTemporarilyDisabled{
+			Message: fmt.Sprintf("The bidder '%s' is temporarily skipped because too many of its recent requests failed.", name),
+		}}
+	}
+
+	// The outcome is recorded even if the bidder panics, so that a probe request can't leave the breaker half open
+	// with its probe in flight for good
+	outcome := outcomeInconclusive
+	defer func() {
+		b.breaker.record(outcome)
+	}()
+
+	seatBid, errs := b.bidder.requestBid(ctx, request, name, bidAdjustment, conversions, reqInfo, accountDebugAllowed, headerDebugAllowed, hookExecutor)
+	outcome = getRequestOutcome(seatBid, errs, b.minTimeout)
+
+	return seatBid, errs
+}
+
+// requestOutcome tells what a request to a bidder showed about the health of its endpoint.
+type requestOutcome int
+
+const (
+	// outcomeInconclusive means the endpoint wasn't reached, e.g. because the adapter couldn't make the request
+	// or a hook rejected it.
+	outcomeInconclusive requestOutcome = iota
+	outcomeSuccess
+	outcomeFailure
+)
+
+// getRequestOutcome tells whether a request failed because of the bidder's endpoint, from the errors and the
+// status of the HTTP calls. The endpoint failed if a call timed out, failed to connect or got a 5xx response.
+func getRequestOutcome(seatBid *pbsOrtbSeatBid, errs []error, minTimeout time.Duration) requestOutcome {
+	for _, err := range errs {
+		if isBidderFailure(err, minTimeout) {
+			return outcomeFailure
+		}
+	}
+	if seatBid == nil {
+		return outcomeInconclusive
+	}
+
+	outcome := outcomeInconclusive
+	for _, call := range seatBid.httpCallDetails {
+		// The calls serving stored bid responses have no URI, and the calls which failed before getting a
+		// response have no status
+		if call.Uri == "" || call.Status == 0 {
+			continue
+		}
+		if call.Status >= http.StatusInternalServerError {
+			return outcomeFailure
+		}
+		outcome = outcomeSuccess
+	}
+	return outcome
+}
+
+// isBidderFailure returns true if the error shows that the bidder's endpoint is degraded, rather than
+// a problem with the request itself. A timeout only counts if the bidder used up its own budget, or had at
+// least minTimeout to respond before the auction deadline. The timeouts caused by a short tmax or by the
+// auction timeout of the request don't tell anything about the bidder.
+func isBidderFailure(err error, minTimeout time.Duration) bool {
+	switch e := err.(type) {
+	case *bidderTimeout:
+		switch e.cause {
+		case deadlineBidderBudget:
+			return true
+		case deadlineAuction:
+			return e.timeout >= minTimeout
+		}
+		return false
+	case net.Error:
+		return true
+	}
+	return false
+}
+
+// circuitBreaker counts the requests and failures of a bidder over a fixed window. It opens when the failure
+// rate passes the threshold, and lets a single probe request through once the cooldown period is over. The
+// probe closes the breaker if it succeeds, or opens it again for another cooldown period if it fails. A probe
+// which is inconclusive lets another one through.
+type circuitBreaker struct {
+	bidderName openrtb_ext.BidderName
+	cfg        config.AdapterCircuitBreaker
+	me         metrics.MetricsEngine
+	now        func() time.Time
+
+	mutex       sync.Mutex
+	state       metrics.CircuitBreakerState
+	windowStart time.Time
+	requests    int
+	failures    int
+	cooldownEnd time.Time
+	probing     bool
+}
+
+func newCircuitBreaker(bidderName openrtb_ext.BidderName, cfg config.AdapterCircuitBreaker, me metrics.MetricsEngine, now func() time.Time) *circuitBreaker {
+	return &circuitBreaker{
+		bidderName:  bidderName,
+		cfg:         cfg,
+		me:          me,
+		now:         now,
+		state:       metrics.CircuitBreakerClosed,
+		windowStart: now(),
+	}
+}
+
+// allow returns true if a request may be sent to the bidder.
+func (cb *circuitBreaker) allow() bool {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	switch cb.state {
+	case metrics.CircuitBreakerOpen:
+		if cb.now().Before(cb.cooldownEnd) {
+			return false
+		}
+		cb.setState(metrics.CircuitBreakerHalfOpen)
+		cb.probing = true
+		return true
+	case metrics.CircuitBreakerHalfOpen:
+		if cb.probing {
+			// The probe request is still in flight
+			return false
+		}
+		cb.probing = true
+		return true
+	}
+	return true
+}
+
+// record takes the outcome of a request which was allowed by the breaker.
+func (cb *circuitBreaker) record(outcome requestOutcome) {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	now := cb.now()
+	switch cb.state {
+	case metrics.CircuitBreakerOpen:
+		// A request sent before the breaker opened, which can't change its state anymore
+		return
+	case metrics.CircuitBreakerHalfOpen:
+		cb.probing = false
+		switch outcome {
+		case outcomeFailure:
+			cb.open(now)
+		case outcomeSuccess:
+			cb.resetWindow(now)
+			cb.setState(metrics.CircuitBreakerClosed)
+		}
+		return
+	}
+
+	if outcome == outcomeInconclusive {
+		return
+	}
+	if now.Sub(cb.windowStart) >= time.Duration(cb.cfg.WindowSeconds)*time.Second {
+		cb.resetWindow(now)
+	}
+	cb.requests++
+	if outcome == outcomeFailure {
+		cb.failures++
+	}
+	if cb.requests >= cb.cfg.MinRequests && cb.failures*100 >= cb.cfg.FailureRatePercent*cb.requests {
+		cb.open(now)
+	}
+}
+
+func (cb *circuitBreaker) open(now time.Time) {
+	cb.cooldownEnd = now.Add(time.Duration(cb.cfg.CooldownSeconds) * time.Second)
+	cb.setState(metrics.CircuitBreakerOpen)
+}
+
+func (cb *circuitBreaker) resetWindow(now time.Time) {
+	cb.windowStart = now
+	cb.requests = 0
+	cb.failures = 0
+}
+
+func (cb *circuitBreaker) setState(state metrics.CircuitBreakerState) {
+	cb.state = state
+	cb.me.RecordAdapterCircuitBreakerStateChange(cb.bidderName, state)
+}
@@ -0,0 +1,270 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/analytics"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/hooks/hookexecution"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestIsBidderFailure(t *testing.T) {
+	testCases := []struct {
+		description string
+		err         error
+		expected    bool
+	}{
+		{
+			description: "Timeout at the bidder's budget",
+			err:         &bidderTimeout{Timeout: errortypes.Timeout{Message: "context deadline exceeded"}, cause: deadlineBidderBudget, timeout: 20 * time.Millisecond},
+			expected:    true,
+		},
+		{
+			description: "Timeout at the auction deadline, with the full time to respond",
+			err:         &bidderTimeout{Timeout: errortypes.Timeout{Message: "context deadline exceeded"}, timeout: 300 * time.Millisecond},
+			expected:    true,
+		},
+		{
+			description: "Timeout at the auction deadline of a request with a short tmax",
+			err:         &bidderTimeout{Timeout: errortypes.Timeout{Message: "context deadline exceeded"}, timeout: time.Millisecond},
+			expected:    false,
+		},
+		{
+			description: "Timeout at the auction timeout of the request",
+			err:         &bidderTimeout{Timeout: errortypes.Timeout{Message: "context deadline exceeded"}, cause: deadlineSoftTimeout, timeout: 300 * time.Millisecond},
+			expected:    false,
+		},
+		{
+			description: "Timeout reported by the adapter",
+			err:         &errortypes.Timeout{Message: "timeout"},
+			expected:    false,
+		},
+		{
+			description: "Bad response, told apart by the status of the HTTP call",
+			err:         &errortypes.BadServerResponse{Message: "Server responded with failure status: 503. Set request.test = 1 for debugging info."},
+			expected:    false,
+		},
+		{
+			description: "Connection error",
+			err:         &url.Error{Op: "Post", URL: "http://bidder.com", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}},
+			expected:    true,
+		},
+		{
+			description: "Bad input",
+			err:         &errortypes.BadInput{Message: "missing param"},
+			expected:    false,
+		},
+		{
+			description: "Generic error",
+			err:         errors.New("any error"),
+			expected:    false,
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, isBidderFailure(test.err, 100*time.Millisecond), test.description)
+	}
+}
+
+func TestGetRequestOutcome(t *testing.T) {
+	testCases := []struct {
+		description string
+		seatBid     *pbsOrtbSeatBid
+		errs        []error
+		expected    requestOutcome
+	}{
+		{
+			description: "Successful call",
+			seatBid:     &pbsOrtbSeatBid{httpCallDetails: []*analytics.HttpCallDetails{{Uri: "http://bidder.com", Status: 200}}},
+			expected:    outcomeSuccess,
+		},
+		{
+			description: "4xx response",
+			seatBid:     &pbsOrtbSeatBid{httpCallDetails: []*analytics.HttpCallDetails{{Uri: "http://bidder.com", Status: 400}}},
+			errs:        []error{&errortypes.BadServerResponse{Message: "Server responded with failure status: 400. Set request.test = 1 for debugging info."}},
+			expected:    outcomeSuccess,
+		},
+		{
+			description: "5xx response",
+			seatBid:     &pbsOrtbSeatBid{httpCallDetails: []*analytics.HttpCallDetails{{Uri: "http://bidder.com", Status: 200}, {Uri: "http://bidder.com", Status: 503}}},
+			errs:        []error{&errortypes.BadServerResponse{Message: "Server responded with failure status: 503. Set request.test = 1 for debugging info."}},
+			expected:    outcomeFailure,
+		},
+		{
+			description: "Timeout",
+			seatBid:     &pbsOrtbSeatBid{httpCallDetails: []*analytics.HttpCallDetails{{Uri: "http://bidder.com"}}},
+			errs:        []error{&bidderTimeout{Timeout: errortypes.Timeout{Message: "context deadline exceeded"}, timeout: 300 * time.Millisecond}},
+			expected:    outcomeFailure,
+		},
+		{
+			description: "Timeout of a request with a short tmax",
+			seatBid:     &pbsOrtbSeatBid{httpCallDetails: []*analytics.HttpCallDetails{{Uri: "http://bidder.com"}}},
+			errs:        []error{&bidderTimeout{Timeout: errortypes.Timeout{Message: "context deadline exceeded"}, timeout: time.Millisecond}},
+			expected:    outcomeInconclusive,
+		},
+		{
+			description: "Adapter failed to make the request",
+			errs:        []error{&errortypes.BadInput{Message: "missing param"}},
+			expected:    outcomeInconclusive,
+		},
+		{
+			description: "Rejected by a hook",
+			errs:        []error{&hookexecution.RejectError{}},
+			expected:    outcomeInconclusive,
+		},
+		{
+			description: "Stored bid responses only",
+			seatBid:     &pbsOrtbSeatBid{httpCallDetails: []*analytics.HttpCallDetails{{Status: 200}}},
+			expected:    outcomeInconclusive,
+		},
+		{
+			description: "Call canceled before a response",
+			seatBid:     &pbsOrtbSeatBid{httpCallDetails: []*analytics.HttpCallDetails{{Uri: "http://bidder.com"}}},
+			errs:        []error{context.Canceled},
+			expected:    outcomeInconclusive,
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, getRequestOutcome(test.seatBid, test.errs, 100*time.Millisecond), test.description)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	cfg := config.AdapterCircuitBreaker{
+		Enabled:            true,
+		WindowSeconds:      10,
+		MinRequests:        4,
+		FailureRatePercent: 50,
+		CooldownSeconds:    30,
+	}
+	now := time.Unix(1000, 0)
+	clock := func() time.Time { return now }
+
+	me := &metrics.MetricsEngineMock{}
+	me.On("RecordAdapterCircuitBreakerStateChange", openrtb_ext.BidderAppnexus, mock.Anything).Return()
+
+	cb := newCircuitBreaker(openrtb_ext.BidderAppnexus, cfg, me, clock)
+
+	// Inconclusive requests aren't counted
+	for i := 0; i < 4; i++ {
+		assert.True(t, cb.allow(), "Closed - Inconclusive")
+		cb.record(outcomeInconclusive)
+	}
+	assert.Zero(t, cb.requests)
+
+	// Failures within a window below the minimum number of requests don't open the breaker
+	for i := 0; i < 3; i++ {
+		assert.True(t, cb.allow(), "Closed - Below min requests")
+		cb.record(outcomeFailure)
+	}
+	assert.Equal(t, metrics.CircuitBreakerClosed, cb.state)
+
+	// The counts are reset with a new window
+	now = now.Add(10 * time.Second)
+	assert.True(t, cb.allow(), "Closed - New window")
+	cb.record(outcomeFailure)
+	assert.Equal(t, metrics.CircuitBreakerClosed, cb.state)
+
+	// The failure rate passes the threshold
+	for i := 0; i < 2; i++ {
+		assert.True(t, cb.allow(), "Closed - Success")
+		cb.record(outcomeSuccess)
+	}
+	assert.True(t, cb.allow(), "Closed - Last failure")
+	cb.record(outcomeFailure)
+	assert.Equal(t, metrics.CircuitBreakerOpen, cb.state)
+
+	// The bidder is skipped during the cooldown period
+	now = now.Add(29 * time.Second)
+	assert.False(t, cb.allow(), "Open - Cooldown")
+
+	// A single probe request is let through once the cooldown period is over, and opens the breaker again if it fails
+	now = now.Add(time.Second)
+	assert.True(t, cb.allow(), "Half open - Probe")
+	assert.False(t, cb.allow(), "Half open - Probe in flight")
+	cb.record(outcomeFailure)
+	assert.Equal(t, metrics.CircuitBreakerOpen, cb.state)
+	assert.False(t, cb.allow(), "Open - Cooldown after failed probe")
+
+	// An inconclusive probe lets another one through
+	now = now.Add(30 * time.Second)
+	assert.True(t, cb.allow(), "Half open - Second probe")
+	cb.record(outcomeInconclusive)
+	assert.Equal(t, metrics.CircuitBreakerHalfOpen, cb.state)
+
+	// A successful probe closes the breaker
+	assert.True(t, cb.allow(), "Half open - Third probe")
+	cb.record(outcomeSuccess)
+	assert.Equal(t, metrics.CircuitBreakerClosed, cb.state)
+	assert.True(t, cb.allow(), "Closed - After successful probe")
+
+	me.AssertNumberOfCalls(t, "RecordAdapterCircuitBreakerStateChange", 5)
+}
+
+func TestCircuitBreakerBidder(t *testing.T) {
+	cfg := config.AdapterCircuitBreaker{
+		Enabled:            true,
+		WindowSeconds:      10,
+		MinRequests:        2,
+		FailureRatePercent: 100,
+		CooldownSeconds:    30,
+	}
+	me := &metrics.MetricsEngineMock{}
+	me.On("RecordAdapterCircuitBreakerStateChange", openrtb_ext.BidderAppnexus, metrics.CircuitBreakerOpen).Return()
+
+	mockBidder := &mockAdaptedBidder{errorResponse: []error{&bidderTimeout{Timeout: errortypes.Timeout{Message: "timeout"}, timeout: time.Second}}}
+	bidder := addCircuitBreakerBidderMiddleware(mockBidder, openrtb_ext.BidderAppnexus, cfg, me)
+
+	for i := 0; i < 2; i++ {
+		_, errs := bidder.requestBid(context.Background(), &openrtb2.BidRequest{}, openrtb_ext.BidderAppnexus, 1.0, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, true, false, hookexecution.EmptyHookExecutor{})
+		assert.Equal(t, mockBidder.errorResponse, errs)
+	}
+
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb2.BidRequest{}, openrtb_ext.BidderAppnexus, 1.0, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, true, false, hookexecution.EmptyHookExecutor{})
+	assert.Nil(t, seatBid)
+	assert.Equal(t, []error{&errortypes.BidderTemporarilyDisabled{Message: "The bidder 'appnexus' is temporarily skipped because too many of its recent requests failed."}}, errs)
+	me.AssertExpectations(t)
+}
+
+func TestCircuitBreakerBidderPanickingProbe(t *testing.T) {
+	cfg := config.AdapterCircuitBreaker{
+		Enabled:            true,
+		WindowSeconds:      10,
+		MinRequests:        1,
+		FailureRatePercent: 100,
+		CooldownSeconds:    30,
+		MinTimeoutMillis:   100,
+	}
+	me := &metrics.MetricsEngineMock{}
+	me.On("RecordAdapterCircuitBreakerStateChange", openrtb_ext.BidderAppnexus, mock.Anything).Return()
+
+	breaker := newCircuitBreaker(openrtb_ext.BidderAppnexus, cfg, me, time.Now)
+	breaker.open(time.Now().Add(-time.Minute))
+	bidder := &circuitBreakerBidder{bidder: &panicingAdapter{}, breaker: breaker}
+
+	assert.Panics(t, func() {
+		bidder.requestBid(context.Background(), &openrtb2.BidRequest{}, openrtb_ext.BidderAppnexus, 1.0, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, true, false, hookexecution.EmptyHookExecutor{})
+	})
+	assert.Equal(t, metrics.CircuitBreakerHalfOpen, breaker.state)
+	assert.True(t, breaker.allow(), "Another probe should be let through after a panic")
+}
+
+func TestCircuitBreakerBidderDisabled(t *testing.T) {
+	mockBidder := &mockAdaptedBidder{}
+	bidder := addCircuitBreakerBidderMiddleware(mockBidder, openrtb_ext.BidderAppnexus, config.AdapterCircuitBreaker{}, &metrics.MetricsEngineMock{})
+	assert.Equal(t, mockBidder, bidder)
+}
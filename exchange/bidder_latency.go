@@ -0,0 +1,85 @@
+package exchange
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+)
+
+// latencyTracker keeps the response times of the most recent requests to a bidder.
+type latencyTracker struct {
+	mutex   sync.Mutex
+	samples []time.Duration
+	next    int
+	count   int
+}
+
+func newLatencyTracker(size int) *latencyTracker {
+	return &latencyTracker{
+		samples: make([]time.Duration, size),
+	}
+}
+
+// record adds a response time, replacing the oldest one once the tracker is full.
+func (t *latencyTracker) record(latency time.Duration) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.samples[t.next] = latency
+	t.next = (t.next + 1) % len(t.samples)
+	if t.count < len(t.samples) {
+		t.count++
+	}
+}
+
+// percentile returns the given percentile of the recorded response times, along with the number of samples it's based on.
+func (t *latencyTracker) percentile(p int) (time.Duration, int) {
+	t.mutex.Lock()
+	sorted := make([]time.Duration, t.count)
+	copy(sorted, t.samples[:t.count])
+	t.mutex.Unlock()
+
+	if len(sorted) == 0 {
+		return 0, 0
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	// Nearest-rank method
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1], len(sorted)
+}
+
+// budgetTimeout returns the timeout given to a bidder based on its observed response times, or false if there
+// aren't enough of them yet for the bidder to get its own timeout.
+func budgetTimeout(tracker *latencyTracker, cfg config.AdaptiveBidderTimeouts) (time.Duration, bool) {
+	latency, samples := tracker.percentile(cfg.Percentile)
+	if samples < cfg.MinSamples {
+		return 0, false
+	}
+
+	timeout := latency + latency*time.Duration(cfg.HeadroomPercent)/100
+	if minTimeout := time.Duration(cfg.MinTimeoutMillis) * time.Millisecond; timeout < minTimeout {
+		timeout = minTimeout
+	}
+	return timeout, true
+}
+
+// makeBidderContext returns the context used for the requests to a bidder. Its deadline is the bidder's
+// budgeted timeout, capped by the deadline of the auction.
+func makeBidderContext(ctx context.Context, tracker *latencyTracker, cfg config.AdaptiveBidderTimeouts) (context.Context, context.CancelFunc) {
+	timeout, ok := budgetTimeout(tracker, cfg)
+	if !ok {
+		return ctx, func() {}
+	}
+	deadline := time.Now().Add(timeout)
+	if auctionDeadline, hasDeadline := ctx.Deadline(); hasDeadline && auctionDeadline.Before(deadline) {
+		return ctx, func() {}
+	}
+	return withDeadlineCause(ctx, deadline, deadlineBidderBudget)
+}
@@ -0,0 +1,275 @@
+package exchange
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/hooks/hookexecution"
+	metricsConfig "github.com/prebid/prebid-server/metrics/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatencyTrackerPercentile(t *testing.T) {
+	tracker := newLatencyTracker(10)
+
+	latency, samples := tracker.percentile(95)
+	assert.Equal(t, time.Duration(0), latency, "Empty")
+	assert.Equal(t, 0, samples, "Empty")
+
+	for i := 1; i <= 10; i++ {
+		tracker.record(time.Duration(i) * time.Millisecond)
+	}
+	latency, samples = tracker.percentile(95)
+	assert.Equal(t, 10*time.Millisecond, latency, "Full - p95")
+	assert.Equal(t, 10, samples, "Full")
+	latency, _ = tracker.percentile(50)
+	assert.Equal(t, 5*time.Millisecond, latency, "Full - p50")
+
+	// The oldest samples are replaced
+	for i := 0; i < 5; i++ {
+		tracker.record(100 * time.Millisecond)
+	}
+	latency, samples = tracker.percentile(50)
+	assert.Equal(t, 10*time.Millisecond, latency, "Replaced - p50")
+	assert.Equal(t, 10, samples, "Replaced")
+}
+
+func TestBudgetTimeout(t *testing.T) {
+	cfg := config.AdaptiveBidderTimeouts{
+		Enabled:          true,
+		Percentile:       95,
+		HeadroomPercent:  20,
+		MinTimeoutMillis: 50,
+		SampleSize:       20,
+		MinSamples:       10,
+	}
+
+	testCases := []struct {
+		description     string
+		samples         []time.Duration
+		expectedTimeout time.Duration
+		expectedOk      bool
+	}{
+		{
+			description: "Not enough samples",
+			samples:     repeatLatency(100*time.Millisecond, 9),
+			expectedOk:  false,
+		},
+		{
+			description:     "Percentile with headroom",
+			samples:         repeatLatency(100*time.Millisecond, 10),
+			expectedTimeout: 120 * time.Millisecond,
+			expectedOk:      true,
+		},
+		{
+			description:     "Min timeout",
+			samples:         repeatLatency(10*time.Millisecond, 10),
+			expectedTimeout: 50 * time.Millisecond,
+			expectedOk:      true,
+		},
+	}
+
+	for _, test := range testCases {
+		tracker := newLatencyTracker(cfg.SampleSize)
+		for _, latency := range test.samples {
+			tracker.record(latency)
+		}
+		timeout, ok := budgetTimeout(tracker, cfg)
+		assert.Equal(t, test.expectedOk, ok, test.description)
+		assert.Equal(t, test.expectedTimeout, timeout, test.description)
+	}
+}
+
+func TestMakeBidderContext(t *testing.T) {
+	cfg := config.AdaptiveBidderTimeouts{Enabled: true, Percentile: 95, SampleSize: 10, MinSamples: 10}
+	tracker := newLatencyTracker(cfg.SampleSize)
+	for _, latency := range repeatLatency(100*time.Millisecond, 10) {
+		tracker.record(latency)
+	}
+
+	// The bidder's budget is shorter than the auction deadline
+	auctionCtx, auctionCancel := context.WithTimeout(context.Background(), time.Second)
+	defer auctionCancel()
+	bidderCtx, cancel := makeBidderContext(auctionCtx, tracker, cfg)
+	deadline, ok := bidderCtx.Deadline()
+	cancel()
+	assert.True(t, ok, "Budgeted")
+	assert.True(t, time.Until(deadline) <= 100*time.Millisecond, "Budgeted")
+	assert.Equal(t, deadlineBidderBudget, getDeadlineCause(bidderCtx), "Budgeted")
+
+	// The auction deadline caps the bidder's budget
+	shortAuctionCtx, shortAuctionCancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer shortAuctionCancel()
+	bidderCtx, cancel = makeBidderContext(shortAuctionCtx, tracker, cfg)
+	cancel()
+	assert.Equal(t, shortAuctionCtx, bidderCtx, "Capped by the auction deadline")
+
+	// Without enough samples, the auction context is used
+	bidderCtx, cancel = makeBidderContext(auctionCtx, newLatencyTracker(cfg.SampleSize), cfg)
+	cancel()
+	assert.Equal(t, auctionCtx, bidderCtx, "Not enough samples")
+}
+
+func TestAdaptiveBidderTimeouts(t *testing.T) {
+	server := httptest.NewServer(mockHandler(200, "getBody", `{"bid":false}`))
+	defer server.Close()
+
+	bidderImpl := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{
+			Method:  "POST",
+			Uri:     server.URL,
+			Body:    []byte(`{"key":"val"}`),
+			Headers: http.Header{},
+		},
+		bidResponse: &adapters.BidderResponse{},
+	}
+	cfg := &config.Configuration{
+		AdaptiveBidderTimeouts: config.AdaptiveBidderTimeouts{
+			Enabled:          true,
+			Percentile:       95,
+			MinTimeoutMillis: 200,
+			SampleSize:       10,
+			MinSamples:       1,
+		},
+	}
+	bidder := adaptBidder(bidderImpl, server.Client(), cfg, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	request := &openrtb2.BidRequest{TMax: 1000}
+
+	// Without any observed response, the bidder gets the auction deadline
+	bidder.requestBid(ctx, request, openrtb_ext.BidderAppnexus, 1.0, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, true, false, hookexecution.EmptyHookExecutor{})
+	assert.True(t, bidderImpl.bidRequest.TMax > 200 && bidderImpl.bidRequest.TMax <= 1000, "Auction deadline. Got %d", bidderImpl.bidRequest.TMax)
+	_, samples := bidder.latency.percentile(95)
+	assert.Equal(t, 1, samples, "Response time recorded")
+
+	// Once a response was observed, the bidder gets its own budget
+	bidder.requestBid(ctx, request, openrtb_ext.BidderAppnexus, 1.0, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, true, false, hookexecution.EmptyHookExecutor{})
+	assert.True(t, bidderImpl.bidRequest.TMax <= 200, "Budgeted deadline. Got %d", bidderImpl.bidRequest.TMax)
+	assert.Equal(t, int64(1000), request.TMax, "Original request unchanged")
+}
+
+func TestAdaptiveBidderTimeoutsWithTimeouts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	bidderImpl := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{
+			Method:  "POST",
+			Uri:     server.URL,
+			Body:    []byte(`{"key":"val"}`),
+			Headers: http.Header{},
+		},
+		bidResponse: &adapters.BidderResponse{},
+	}
+	cfg := &config.Configuration{
+		AdaptiveBidderTimeouts: config.AdaptiveBidderTimeouts{
+			Enabled:          true,
+			Percentile:       95,
+			HeadroomPercent:  50,
+			MinTimeoutMillis: 20,
+			SampleSize:       10,
+			MinSamples:       1,
+		},
+	}
+	bidder := adaptBidder(bidderImpl, server.Client(), cfg, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil)
+	for _, latency := range repeatLatency(10*time.Millisecond, 10) {
+		bidder.latency.record(latency)
+	}
+	initialBudget, _ := budgetTimeout(bidder.latency, cfg.AdaptiveBidderTimeouts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	request := &openrtb2.BidRequest{TMax: 5000}
+
+	// Every request times out at the bidder's budget
+	for i := 0; i < 3; i++ {
+		_, errs := bidder.requestBid(ctx, request, openrtb_ext.BidderAppnexus, 1.0, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, true, false, hookexecution.EmptyHookExecutor{})
+		if assert.Len(t, errs, 1) && assert.Equal(t, errortypes.TimeoutErrorCode, errortypes.ReadCode(errs[0])) {
+			assert.Equal(t, deadlineBidderBudget, errs[0].(*bidderTimeout).cause)
+		}
+	}
+
+	budget, _ := budgetTimeout(bidder.latency, cfg.AdaptiveBidderTimeouts)
+	assert.Equal(t, 20*time.Millisecond, initialBudget, "Initial budget")
+	assert.True(t, budget > initialBudget, "The timeouts should raise the budget. Got %v", budget)
+}
+
+func TestAdaptiveBidderTimeoutsCutOff(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		description   string
+		makeContext   func() (context.Context, context.CancelFunc)
+		expectedCause deadlineCause
+	}{
+		{
+			description:   "Short tmax",
+			makeContext:   func() (context.Context, context.CancelFunc) { return context.WithTimeout(context.Background(), 20*time.Millisecond) },
+			expectedCause: deadlineAuction,
+		},
+		{
+			description: "Auction timeout",
+			makeContext: func() (context.Context, context.CancelFunc) {
+				return makeSoftTimeoutContext(context.Background(), time.Now(), 20)
+			},
+			expectedCause: deadlineSoftTimeout,
+		},
+	}
+
+	for _, test := range testCases {
+		bidderImpl := &goodSingleBidder{
+			httpRequest: &adapters.RequestData{
+				Method:  "POST",
+				Uri:     server.URL,
+				Body:    []byte(`{"key":"val"}`),
+				Headers: http.Header{},
+			},
+			bidResponse: &adapters.BidderResponse{},
+		}
+		cfg := &config.Configuration{
+			AdaptiveBidderTimeouts: config.AdaptiveBidderTimeouts{
+				Enabled:          true,
+				Percentile:       95,
+				HeadroomPercent:  50,
+				MinTimeoutMillis: 20,
+				SampleSize:       10,
+				MinSamples:       5,
+			},
+		}
+		bidder := adaptBidder(bidderImpl, server.Client(), cfg, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil)
+
+		ctx, cancel := test.makeContext()
+		_, errs := bidder.requestBid(ctx, &openrtb2.BidRequest{}, openrtb_ext.BidderAppnexus, 1.0, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, true, false, hookexecution.EmptyHookExecutor{})
+		cancel()
+
+		if assert.Len(t, errs, 1, test.description) && assert.Equal(t, errortypes.TimeoutErrorCode, errortypes.ReadCode(errs[0]), test.description) {
+			assert.Equal(t, test.expectedCause, errs[0].(*bidderTimeout).cause, test.description)
+		}
+		_, samples := bidder.latency.percentile(95)
+		assert.Zero(t, samples, "%s: the cut off request shouldn't be recorded", test.description)
+	}
+}
+
+func repeatLatency(latency time.Duration, count int) []time.Duration {
+	samples := make([]time.Duration, count)
+	for i := range samples {
+		samples[i] = latency
+	}
+	return samples
+}
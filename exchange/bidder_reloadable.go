@@ -0,0 +1,54 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/hooks/hookexecution"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// addReloadableBidderMiddleware returns a bidder whose implementation can be replaced, or which can be disabled,
+// when the host configuration is reloaded.
+//
+// It sits right around the bidder adapter, so that the middlewares wrapping it, such as the circuit breaker,
+// keep their state across the reloads. The replacements keep the client and the recent response times of the
+// argument bidder adapter.
+func addReloadableBidderMiddleware(bidder *bidderAdapter) *reloadableBidder {
+	b := &reloadableBidder{base: bidder}
+	b.current.Store(bidder)
+	return b
+}
+
+type reloadableBidder struct {
+	base *bidderAdapter
+	// current holds the *bidderAdapter of the latest configuration, which is nil while the bidder is disabled
+	current atomic.Value
+}
+
+func (b *reloadableBidder) requestBid(ctx context.Context, request *openrtb2.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, accountDebugAllowed, headerDebugAllowed bool, hookExecutor hookexecution.StageExecutor) (*pbsOrtbSeatBid, []error) {
+	bidder := b.current.Load().(*bidderAdapter)
+	if bidder == nil {
+		return nil, []error{&errortypes.BidderTemporarilyDisabled{
+			Message: fmt.Sprintf("The bidder '%s' has been disabled on this instance of Prebid Server.", name),
+		}}
+	}
+	return bidder.requestBid(ctx, request, name, bidAdjustment, conversions, reqInfo, accountDebugAllowed, headerDebugAllowed, hookExecutor)
+}
+
+// reload replaces the implementation of the bidder with the one built from the reloaded configuration.
+func (b *reloadableBidder) reload(bidder adapters.Bidder) {
+	reloaded := *b.base
+	reloaded.Bidder = bidder
+	b.current.Store(&reloaded)
+}
+
+// disable makes the requests to the bidder fail until it's reloaded.
+func (b *reloadableBidder) disable() {
+	b.current.Store((*bidderAdapter)(nil))
+}
@@ -0,0 +1,35 @@
+package exchange
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/hooks/hookexecution"
+	metricsConfig "github.com/prebid/prebid-server/metrics/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReloadableBidderDisabled(t *testing.T) {
+	bidder := addReloadableBidderMiddleware(adaptBidder(fakeBidder{"a"}, &http.Client{}, &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil))
+	bidder.disable()
+
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb2.BidRequest{}, openrtb_ext.BidderAppnexus, 1.0, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, true, false, hookexecution.EmptyHookExecutor{})
+
+	assert.Nil(t, seatBid)
+	assert.Equal(t, []error{&errortypes.BidderTemporarilyDisabled{
+		Message: "The bidder 'appnexus' has been disabled on this instance of Prebid Server.",
+	}}, errs)
+
+	bidder.reload(fakeBidder{"b"})
+	_, errs = bidder.requestBid(context.Background(), &openrtb2.BidRequest{}, openrtb_ext.BidderAppnexus, 1.0, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, true, false, hookexecution.EmptyHookExecutor{})
+	if assert.Len(t, errs, 1) {
+		assert.IsType(t, &errortypes.FailedToRequestBids{}, errs[0], "The reloaded bidder should be called again")
+	}
+}
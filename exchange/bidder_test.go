@@ -2,6 +2,7 @@ package exchange
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"crypto/tls"
 	"encoding/json"
@@ -20,14 +21,25 @@ import (
 	nativeResponse "github.com/mxmCherry/openrtb/v15/native1/response"
 	"github.com/mxmCherry/openrtb/v15/openrtb2"
 	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/analytics"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/currency"
 	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/hooks/hookexecution"
 	"github.com/prebid/prebid-server/metrics"
 	metricsConfig "github.com/prebid/prebid-server/metrics/config"
 	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/tracing"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // TestSingleBidder makes sure that the following things work if the Bidder needs only one request.
@@ -94,7 +106,7 @@ func TestSingleBidder(t *testing.T) {
 		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, test.debugInfo)
 		currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
 
-		seatBid, errs := bidder.requestBid(ctx, &openrtb2.BidRequest{}, "test", bidAdjustment, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, true, false)
+		seatBid, errs := bidder.requestBid(ctx, &openrtb2.BidRequest{}, "test", bidAdjustment, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, true, false, hookexecution.EmptyHookExecutor{})
 
 		// Make sure the goodSingleBidder was called with the expected arguments.
 		if bidderImpl.httpResponse == nil {
@@ -167,7 +179,7 @@ func TestRequestBidRemovesSensitiveHeaders(t *testing.T) {
 
 	bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, debugInfo)
 	currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
-	seatBid, errs := bidder.requestBid(ctx, &openrtb2.BidRequest{}, "test", 1, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, true, false)
+	seatBid, errs := bidder.requestBid(ctx, &openrtb2.BidRequest{}, "test", 1, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, true, false, hookexecution.EmptyHookExecutor{})
 
 	expectedHttpCalls := []*openrtb_ext.ExtHttpCall{
 		{
@@ -208,7 +220,7 @@ func TestSetGPCHeader(t *testing.T) {
 
 	bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, debugInfo)
 	currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
-	seatBid, errs := bidder.requestBid(ctx, &openrtb2.BidRequest{}, "test", 1, currencyConverter.Rates(), &adapters.ExtraRequestInfo{GlobalPrivacyControlHeader: "1"}, true, false)
+	seatBid, errs := bidder.requestBid(ctx, &openrtb2.BidRequest{}, "test", 1, currencyConverter.Rates(), &adapters.ExtraRequestInfo{GlobalPrivacyControlHeader: "1"}, true, false, hookexecution.EmptyHookExecutor{})
 
 	expectedHttpCall := []*openrtb_ext.ExtHttpCall{
 		{
@@ -246,7 +258,7 @@ func TestSetGPCHeaderNil(t *testing.T) {
 
 	bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, debugInfo)
 	currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
-	seatBid, errs := bidder.requestBid(ctx, &openrtb2.BidRequest{}, "test", 1, currencyConverter.Rates(), &adapters.ExtraRequestInfo{GlobalPrivacyControlHeader: "1"}, true, false)
+	seatBid, errs := bidder.requestBid(ctx, &openrtb2.BidRequest{}, "test", 1, currencyConverter.Rates(), &adapters.ExtraRequestInfo{GlobalPrivacyControlHeader: "1"}, true, false, hookexecution.EmptyHookExecutor{})
 
 	expectedHttpCall := []*openrtb_ext.ExtHttpCall{
 		{
@@ -304,7 +316,7 @@ func TestMultiBidder(t *testing.T) {
 	}
 	bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil)
 	currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
-	seatBid, errs := bidder.requestBid(context.Background(), &openrtb2.BidRequest{}, "test", 1.0, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, true, true)
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb2.BidRequest{}, "test", 1.0, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, true, true, hookexecution.EmptyHookExecutor{})
 
 	if seatBid == nil {
 		t.Fatalf("SeatBid should exist, because bids exist.")
@@ -682,6 +694,7 @@ func TestMultiCurrencies(t *testing.T) {
 			&adapters.ExtraRequestInfo{},
 			true,
 			true,
+			hookexecution.EmptyHookExecutor{},
 		)
 
 		// Verify:
@@ -828,6 +841,7 @@ func TestMultiCurrencies_RateConverterNotSet(t *testing.T) {
 			&adapters.ExtraRequestInfo{},
 			true,
 			true,
+			hookexecution.EmptyHookExecutor{},
 		)
 
 		// Verify:
@@ -1002,6 +1016,7 @@ func TestMultiCurrencies_RequestCurrencyPick(t *testing.T) {
 			&adapters.ExtraRequestInfo{},
 			true,
 			false,
+			hookexecution.EmptyHookExecutor{},
 		)
 
 		// Verify:
@@ -1150,11 +1165,346 @@ func TestMakeExt(t *testing.T) {
 	}
 
 	for _, test := range testCases {
-		result := makeExt(test.given)
+		result := makeExt(test.given, &goodSingleBidder{})
 		assert.Equal(t, test.expected, result, test.description)
 	}
 }
 
+func TestGzipEndpointCompression(t *testing.T) {
+	var receivedEncoding string
+	var receivedBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedEncoding = r.Header.Get("Content-Encoding")
+		reader, err := gzip.NewReader(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		receivedBody, _ = ioutil.ReadAll(reader)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	requestData := &adapters.RequestData{
+		Method:  "POST",
+		Uri:     server.URL,
+		Body:    []byte(`{"id":"req"}`),
+		Headers: http.Header{"Content-Type": []string{"application/json"}},
+	}
+	bidderImpl := &goodSingleBidder{
+		httpRequest: requestData,
+		bidResponse: &adapters.BidderResponse{},
+	}
+	cfg := &config.Configuration{
+		Adapters: map[string]config.Adapter{
+			"appnexus": {EndpointCompression: config.CompressionGZIP},
+		},
+	}
+	me := &metrics.MetricsEngineMock{}
+	me.On("RecordAdapterGzipRequestSize", openrtb_ext.BidderAppnexus, mock.Anything).Return()
+	me.On("RecordAdapterRequestSize", openrtb_ext.BidderAppnexus, len(`{"id":"req"}`)).Return()
+	me.On("RecordAdapterConnections", openrtb_ext.BidderAppnexus, mock.Anything, mock.Anything).Return()
+
+	bidder := adaptBidder(bidderImpl, server.Client(), cfg, me, openrtb_ext.BidderAppnexus, nil)
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb2.BidRequest{}, openrtb_ext.BidderAppnexus, 1.0, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, true, true, hookexecution.EmptyHookExecutor{})
+
+	assert.Empty(t, errs)
+	assert.Equal(t, "gzip", receivedEncoding)
+	assert.Equal(t, `{"id":"req"}`, string(receivedBody))
+	assert.Empty(t, requestData.Headers.Get("Content-Encoding"), "The request data is left untouched")
+	if assert.Len(t, seatBid.httpCalls, 1) {
+		assert.Equal(t, `{"id":"req"}`, seatBid.httpCalls[0].RequestBody, "Debug output shows the uncompressed body")
+	}
+	me.AssertCalled(t, "RecordAdapterGzipRequestSize", openrtb_ext.BidderAppnexus, mock.Anything)
+	me.AssertCalled(t, "RecordAdapterRequestSize", openrtb_ext.BidderAppnexus, len(`{"id":"req"}`))
+}
+
+func TestBidderCallTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	testCases := []struct {
+		description         string
+		propagate           bool
+		expectedTraceparent bool
+	}{
+		{
+			description:         "Propagated to the bidder",
+			propagate:           true,
+			expectedTraceparent: true,
+		},
+		{
+			description:         "Not propagated to the bidder",
+			propagate:           false,
+			expectedTraceparent: false,
+		},
+	}
+
+	for _, test := range testCases {
+		var receivedTraceparent string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			receivedTraceparent = r.Header.Get("traceparent")
+			w.WriteHeader(http.StatusNoContent)
+		}))
+
+		requestData := &adapters.RequestData{
+			Method:  "POST",
+			Uri:     server.URL,
+			Body:    []byte(`{"id":"req"}`),
+			Headers: http.Header{"Content-Type": []string{"application/json"}},
+		}
+		bidderImpl := &goodSingleBidder{
+			httpRequest: requestData,
+			bidResponse: &adapters.BidderResponse{},
+		}
+		cfg := &config.Configuration{Tracing: config.Tracing{PropagateToBidders: test.propagate}}
+		cfg.Metrics.Disabled.AdapterConnectionMetrics = true
+
+		recorder.Ended()
+		ctx, auctionSpan := tracing.StartSpan(context.Background(), "auction")
+		me := &metrics.MetricsEngineMock{}
+		me.On("RecordAdapterRequestSize", openrtb_ext.BidderAppnexus, mock.Anything).Return()
+		bidder := adaptBidder(bidderImpl, server.Client(), cfg, me, openrtb_ext.BidderAppnexus, nil)
+		_, errs := bidder.requestBid(ctx, &openrtb2.BidRequest{}, openrtb_ext.BidderAppnexus, 1.0, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, true, true, hookexecution.EmptyHookExecutor{})
+		auctionSpan.End()
+		server.Close()
+
+		assert.Empty(t, errs, test.description)
+		assert.Empty(t, requestData.Headers.Get("traceparent"), test.description+": The request data is left untouched")
+
+		var bidderSpan sdktrace.ReadOnlySpan
+		for _, span := range recorder.Ended() {
+			if span.Name() == "bidder.request" {
+				bidderSpan = span
+			}
+		}
+		if !assert.NotNil(t, bidderSpan, test.description) {
+			continue
+		}
+		assert.Equal(t, auctionSpan.SpanContext().SpanID(), bidderSpan.Parent().SpanID(), test.description)
+		assert.Contains(t, bidderSpan.Attributes(), attribute.String("bidder", "appnexus"), test.description)
+		assert.Contains(t, bidderSpan.Attributes(), semconv.HTTPStatusCodeKey.Int(http.StatusNoContent), test.description)
+		assert.NotEmpty(t, bidderSpan.Events(), test.description+": The connection events should be recorded")
+
+		if test.expectedTraceparent {
+			expected := "00-" + bidderSpan.SpanContext().TraceID().String() + "-" + bidderSpan.SpanContext().SpanID().String() + "-01"
+			assert.Equal(t, expected, receivedTraceparent, test.description)
+		} else {
+			assert.Empty(t, receivedTraceparent, test.description)
+		}
+	}
+}
+
+func TestCurrencyConversionTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	server := httptest.NewServer(mockHandler(200, "getBody", "{}"))
+	defer server.Close()
+
+	testCases := []struct {
+		description         string
+		bidResponseCurrency string
+		expectedAttributes  []attribute.KeyValue
+		expectedError       bool
+	}{
+		{
+			description:         "Rate found",
+			bidResponseCurrency: "USD",
+			expectedAttributes: []attribute.KeyValue{
+				attribute.String("bidder", "appnexus"),
+				attribute.String("currency.from", "USD"),
+				attribute.String("currency.to", "USD"),
+			},
+		},
+		{
+			description:         "Rate not found",
+			bidResponseCurrency: "EUR",
+			expectedAttributes: []attribute.KeyValue{
+				attribute.String("bidder", "appnexus"),
+				attribute.String("currency.from", "EUR"),
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		bidderImpl := &goodSingleBidder{
+			httpRequest: &adapters.RequestData{
+				Method: "POST",
+				Uri:    server.URL,
+			},
+			bidResponse: &adapters.BidderResponse{
+				Currency: test.bidResponseCurrency,
+				Bids:     []*adapters.TypedBid{{Bid: &openrtb2.Bid{ID: "bid", Price: 1}, BidType: openrtb_ext.BidTypeBanner}},
+			},
+		}
+		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil)
+
+		recorder.Ended()
+		ctx, auctionSpan := tracing.StartSpan(context.Background(), "auction")
+		bidder.requestBid(ctx, &openrtb2.BidRequest{}, openrtb_ext.BidderAppnexus, 1.0, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, true, false, hookexecution.EmptyHookExecutor{})
+		auctionSpan.End()
+
+		var conversionSpan sdktrace.ReadOnlySpan
+		for _, span := range recorder.Ended() {
+			if span.Name() == "currency.Convert" {
+				conversionSpan = span
+			}
+		}
+		if !assert.NotNil(t, conversionSpan, test.description) {
+			continue
+		}
+		assert.Equal(t, auctionSpan.SpanContext().SpanID(), conversionSpan.Parent().SpanID(), test.description)
+		assert.ElementsMatch(t, test.expectedAttributes, conversionSpan.Attributes(), test.description)
+		assert.Equal(t, test.expectedError, conversionSpan.Status().Code == codes.Error, test.description)
+	}
+}
+
+func TestRequestBidCapturesSeatDetails(t *testing.T) {
+	server := httptest.NewServer(mockHandler(200, "getBody", "{}"))
+	defer server.Close()
+
+	testCases := []struct {
+		description          string
+		bidResponseCurrency  string
+		expectedBids         int
+		expectedRejectedBids []*analytics.RejectedBid
+	}{
+		{
+			description:         "Bids kept",
+			bidResponseCurrency: "USD",
+			expectedBids:        1,
+		},
+		{
+			description:         "Bids rejected for lack of a conversion rate",
+			bidResponseCurrency: "EUR",
+			expectedBids:        0,
+			expectedRejectedBids: []*analytics.RejectedBid{
+				{Bid: &openrtb2.Bid{ID: "bid", Price: 1}, Reason: openrtb_ext.RejectionReasonCurrencyConversion, Message: "Currency conversion rate not found: 'EUR' => 'USD'"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		bid := &openrtb2.Bid{ID: "bid", Price: 1}
+		bidderImpl := &goodSingleBidder{
+			httpRequest: &adapters.RequestData{
+				Method: "POST",
+				Uri:    server.URL,
+				Body:   []byte("{}"),
+			},
+			bidResponse: &adapters.BidderResponse{
+				Currency: test.bidResponseCurrency,
+				Bids:     []*adapters.TypedBid{{Bid: bid, BidType: openrtb_ext.BidTypeBanner}},
+			},
+		}
+		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil)
+
+		seatBid, _ := bidder.requestBid(context.Background(), &openrtb2.BidRequest{}, "test", 2.0, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, true, false, hookexecution.EmptyHookExecutor{})
+
+		if assert.Len(t, seatBid.httpCallDetails, 1, test.description) {
+			assert.Equal(t, server.URL, seatBid.httpCallDetails[0].Uri, test.description)
+			assert.Equal(t, 200, seatBid.httpCallDetails[0].Status, test.description)
+			assert.NotZero(t, seatBid.httpCallDetails[0].Latency, test.description)
+		}
+		assert.Equal(t, []*openrtb2.Bid{{ID: "bid", Price: 1}}, seatBid.receivedBids, test.description+":the received price shouldn't be adjusted")
+		if assert.Len(t, seatBid.bids, test.expectedBids, test.description) && test.expectedBids > 0 {
+			assert.Equal(t, 2.0, seatBid.bids[0].bid.Price, test.description+":adjusted price")
+		}
+		assert.Equal(t, test.expectedRejectedBids, seatBid.rejectedBids, test.description)
+	}
+}
+
+func TestRequestBidStoredBidResponses(t *testing.T) {
+	server := httptest.NewServer(mockHandler(200, "getBody", `{"id":"live-bid","impid":"imp-2","price":2}`))
+	defer server.Close()
+
+	testCases := []struct {
+		description          string
+		imps                 []string
+		storedBidResponses   map[string]json.RawMessage
+		expectedRequestImps  []string
+		expectedBids         map[string]string
+		expectedHttpRequests int
+	}{
+		{
+			description:         "All the imps have a stored bid response",
+			imps:                []string{"imp-1"},
+			storedBidResponses:  map[string]json.RawMessage{"imp-1": json.RawMessage(`{"id":"stored-bid","impid":"other","price":1}`)},
+			expectedRequestImps: nil,
+			expectedBids:        map[string]string{"stored-bid": "imp-1"},
+		},
+		{
+			description:          "Some of the imps have a stored bid response",
+			imps:                 []string{"imp-1", "imp-2"},
+			storedBidResponses:   map[string]json.RawMessage{"imp-1": json.RawMessage(`{"id":"stored-bid","impid":"other","price":1}`)},
+			expectedRequestImps:  []string{"imp-2"},
+			expectedBids:         map[string]string{"stored-bid": "imp-1", "live-bid": "imp-2"},
+			expectedHttpRequests: 1,
+		},
+	}
+
+	for _, test := range testCases {
+		request := &openrtb2.BidRequest{ID: "request"}
+		for _, impID := range test.imps {
+			request.Imp = append(request.Imp, openrtb2.Imp{ID: impID})
+		}
+		bidderImpl := &bidFromBodyBidder{
+			httpRequest: &adapters.RequestData{Method: "POST", Uri: server.URL, Body: []byte("{}")},
+		}
+		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil)
+		reqInfo := &adapters.ExtraRequestInfo{StoredBidResponses: test.storedBidResponses}
+
+		seatBid, errs := bidder.requestBid(context.Background(), request, "test", 1.0, currency.NewConstantRates(), reqInfo, true, false, hookexecution.EmptyHookExecutor{})
+
+		assert.Empty(t, errs, test.description)
+		var requestImps []string
+		if bidderImpl.bidRequest != nil {
+			for _, imp := range bidderImpl.bidRequest.Imp {
+				requestImps = append(requestImps, imp.ID)
+			}
+		}
+		assert.Equal(t, test.expectedRequestImps, requestImps, test.description+":request_imps")
+		bids := make(map[string]string)
+		for _, bid := range seatBid.bids {
+			bids[bid.bid.ID] = bid.bid.ImpID
+		}
+		assert.Equal(t, test.expectedBids, bids, test.description+":bids")
+		assert.Len(t, request.Imp, len(test.imps), test.description+":the request shouldn't be modified")
+	}
+}
+
+func TestMakeExtBinaryBody(t *testing.T) {
+	testCases := []struct {
+		description string
+		decodeErr   error
+		expected    string
+	}{
+		{
+			description: "Decoded",
+			expected:    `{"id":"req"}`,
+		},
+		{
+			description: "Decode Error",
+			decodeErr:   errors.New("invalid body"),
+			expected:    "AAEC",
+		},
+	}
+
+	for _, test := range testCases {
+		bidder := &binaryBodyBidder{decodedBody: []byte(`{"id":"req"}`), decodeErr: test.decodeErr}
+		httpInfo := &httpCallInfo{
+			request: &adapters.RequestData{Uri: "requestUri", Body: []byte{0, 1, 2}},
+		}
+		result := makeExt(httpInfo, bidder)
+		assert.Equal(t, test.expected, result.RequestBody, test.description)
+	}
+}
+
 func TestFilterHeader(t *testing.T) {
 	testCases := []struct {
 		description string
@@ -1307,6 +1657,7 @@ func TestMobileNativeTypes(t *testing.T) {
 			&adapters.ExtraRequestInfo{},
 			true,
 			true,
+			hookexecution.EmptyHookExecutor{},
 		)
 
 		var actualValue string
@@ -1320,7 +1671,7 @@ func TestMobileNativeTypes(t *testing.T) {
 func TestErrorReporting(t *testing.T) {
 	bidder := adaptBidder(&bidRejector{}, nil, &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil)
 	currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
-	bids, errs := bidder.requestBid(context.Background(), &openrtb2.BidRequest{}, "test", 1.0, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, true, false)
+	bids, errs := bidder.requestBid(context.Background(), &openrtb2.BidRequest{}, "test", 1.0, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, true, false, hookexecution.EmptyHookExecutor{})
 	if bids != nil {
 		t.Errorf("There should be no seatbid if no http requests are returned.")
 	}
@@ -1537,11 +1888,12 @@ func TestCallRecordAdapterConnections(t *testing.T) {
 	compareConnWaitTime := func(dur time.Duration) bool { return dur.Nanoseconds() > 0 }
 
 	metrics.On("RecordAdapterConnections", expectedAdapterName, false, mock.MatchedBy(compareConnWaitTime)).Once()
+	metrics.On("RecordAdapterRequestSize", expectedAdapterName, len("{\"key\":\"val\"}")).Once()
 
 	// Run requestBid using an http.Client with a mock handler
 	bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, metrics, openrtb_ext.BidderAppnexus, nil)
 	currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
-	_, errs := bidder.requestBid(context.Background(), &openrtb2.BidRequest{}, "test", bidAdjustment, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, true, true)
+	_, errs := bidder.requestBid(context.Background(), &openrtb2.BidRequest{}, "test", bidAdjustment, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, true, true, hookexecution.EmptyHookExecutor{})
 
 	// Assert no errors
 	assert.Equal(t, 0, len(errs), "bidder.requestBid returned errors %v \n", errs)
@@ -1586,6 +1938,7 @@ func TestCallRecordDNSTime(t *testing.T) {
 	// setup a mock metrics engine and its expectation
 	metricsMock := &metrics.MetricsEngineMock{}
 	metricsMock.Mock.On("RecordDNSTime", mock.Anything).Return()
+	metricsMock.Mock.On("RecordAdapterRequestSize", openrtb_ext.BidderName(""), 0).Return()
 
 	// Instantiate the bidder that will send the request. We'll make sure to use an
 	// http.Client that runs our mock RoundTripper so DNSDone(httptrace.DNSDoneInfo{})
@@ -1607,6 +1960,7 @@ func TestCallRecordTLSHandshakeTime(t *testing.T) {
 	// setup a mock metrics engine and its expectation
 	metricsMock := &metrics.MetricsEngineMock{}
 	metricsMock.Mock.On("RecordTLSHandshakeTime", mock.Anything).Return()
+	metricsMock.Mock.On("RecordAdapterRequestSize", openrtb_ext.BidderName(""), 0).Return()
 
 	// Instantiate the bidder that will send the request. We'll make sure to use an
 	// http.Client that runs our mock RoundTripper so DNSDone(httptrace.DNSDoneInfo{})
@@ -1762,6 +2116,37 @@ func (bidder *goodSingleBidder) MakeBids(internalRequest *openrtb2.BidRequest, e
 	return bidder.bidResponse, nil
 }
 
+// bidFromBodyBidder makes one bid out of each response body.
+type bidFromBodyBidder struct {
+	bidRequest  *openrtb2.BidRequest
+	httpRequest *adapters.RequestData
+}
+
+func (bidder *bidFromBodyBidder) MakeRequests(request *openrtb2.BidRequest, reqInfo *adapters.ExtraRequestInfo) ([]*adapters.RequestData, []error) {
+	bidder.bidRequest = request
+	return []*adapters.RequestData{bidder.httpRequest}, nil
+}
+
+func (bidder *bidFromBodyBidder) MakeBids(internalRequest *openrtb2.BidRequest, externalRequest *adapters.RequestData, response *adapters.ResponseData) (*adapters.BidderResponse, []error) {
+	var bid openrtb2.Bid
+	if err := json.Unmarshal(response.Body, &bid); err != nil {
+		return nil, []error{err}
+	}
+	return &adapters.BidderResponse{
+		Bids: []*adapters.TypedBid{{Bid: &bid, BidType: openrtb_ext.BidTypeBanner}},
+	}, nil
+}
+
+type binaryBodyBidder struct {
+	goodSingleBidder
+	decodedBody []byte
+	decodeErr   error
+}
+
+func (bidder *binaryBodyBidder) DecodeRequestBody(req *adapters.RequestData) ([]byte, error) {
+	return bidder.decodedBody, bidder.decodeErr
+}
+
 type goodMultiHTTPCallsBidder struct {
 	bidRequest        *openrtb2.BidRequest
 	httpRequest       []*adapters.RequestData
@@ -0,0 +1,65 @@
+package exchange
+
+import (
+	"context"
+	"time"
+
+	"github.com/prebid/prebid-server/errortypes"
+)
+
+// deadlineCause tells which of the deadlines applying to a bidder request is the one the request expires at.
+type deadlineCause int
+
+const (
+	// deadlineAuction is the deadline of the auction, set from the tmax of the request.
+	deadlineAuction deadlineCause = iota
+	// deadlineSoftTimeout is the auction timeout requested through ext.prebid.auctiontimeout.
+	deadlineSoftTimeout
+	// deadlineBidderBudget is the bidder's own timeout, budgeted from its observed response times.
+	deadlineBidderBudget
+)
+
+type deadlineCauseKey struct{}
+
+// withDeadlineCause returns a context expiring at the deadline, which remembers the cause of the deadline.
+func withDeadlineCause(ctx context.Context, deadline time.Time, cause deadlineCause) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	return context.WithValue(ctx, deadlineCauseKey{}, cause), cancel
+}
+
+// getDeadlineCause returns the cause of the deadline of the context. The deadlines set outside of the exchange
+// are the auction's.
+func getDeadlineCause(ctx context.Context) deadlineCause {
+	if cause, ok := ctx.Value(deadlineCauseKey{}).(deadlineCause); ok {
+		return cause
+	}
+	return deadlineAuction
+}
+
+// bidderTimeout is the error of a bidder request which timed out. It's reported as an errortypes.Timeout, and
+// tells which deadline cut the request off and how long the bidder had to respond, so that the timeouts caused
+// by the request itself don't count against the bidder.
+type bidderTimeout struct {
+	errortypes.Timeout
+	cause deadlineCause
+	// timeout is the time from sending the request to its deadline
+	timeout time.Duration
+}
+
+// newBidderTimeout returns the timeout error of a request sent at start with the context.
+func newBidderTimeout(ctx context.Context, err error, start time.Time) *bidderTimeout {
+	timeoutErr := &bidderTimeout{
+		Timeout: errortypes.Timeout{Message: err.Error()},
+		cause:   getDeadlineCause(ctx),
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		timeoutErr.timeout = deadline.Sub(start)
+	}
+	return timeoutErr
+}
+
+// isCutOff returns true if a request expiring at the deadline of the context ended before the bidder could
+// respond in its own time, because of a deadline set by the request: either its tmax or its auction timeout.
+func isCutOff(ctx context.Context) bool {
+	return ctx.Err() == context.DeadlineExceeded && getDeadlineCause(ctx) != deadlineBidderBudget
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/mxmCherry/openrtb/v15/openrtb2"
 	"github.com/prebid/prebid-server/adapters"
 	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/hooks/hookexecution"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	goCurrency "golang.org/x/text/currency"
 )
@@ -28,8 +29,8 @@ type validatedBidder struct {
 	bidder adaptedBidder
 }
 
-func (v *validatedBidder) requestBid(ctx context.Context, request *openrtb2.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, accountDebugAllowed, headerDebugAllowed bool) (*pbsOrtbSeatBid, []error) {
-	seatBid, errs := v.bidder.requestBid(ctx, request, name, bidAdjustment, conversions, reqInfo, accountDebugAllowed, headerDebugAllowed)
+func (v *validatedBidder) requestBid(ctx context.Context, request *openrtb2.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, accountDebugAllowed, headerDebugAllowed bool, hookExecutor hookexecution.StageExecutor) (*pbsOrtbSeatBid, []error) {
+	seatBid, errs := v.bidder.requestBid(ctx, request, name, bidAdjustment, conversions, reqInfo, accountDebugAllowed, headerDebugAllowed, hookExecutor)
 	if validationErrors := removeInvalidBids(request, seatBid); len(validationErrors) > 0 {
 		errs = append(errs, validationErrors...)
 	}
@@ -45,6 +46,9 @@ func removeInvalidBids(request *openrtb2.BidRequest, seatBid *pbsOrtbSeatBid) []
 
 	// By design, default currency is USD.
 	if cerr := validateCurrency(request.Cur, seatBid.currency); cerr != nil {
+		for _, bid := range seatBid.bids {
+			seatBid.rejectBid(bid.bid, openrtb_ext.RejectionReasonCurrencyNotAllowed, cerr.Error())
+		}
 		seatBid.bids = nil
 		return []error{cerr}
 	}
@@ -56,6 +60,7 @@ func removeInvalidBids(request *openrtb2.BidRequest, seatBid *pbsOrtbSeatBid) []
 			validBids = append(validBids, bid)
 		} else {
 			errs = append(errs, berr)
+			seatBid.rejectBid(bid.bid, openrtb_ext.RejectionReasonInvalidBid, berr.Error())
 		}
 	}
 	seatBid.bids = validBids
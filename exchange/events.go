@@ -61,7 +61,7 @@ func (ev *eventTracking) modifyBidVAST(pbsBid *pbsOrtbBid, bidderName openrtb_ex
 	if pbsBid.bidType != openrtb_ext.BidTypeVideo || len(bid.AdM) == 0 && len(bid.NURL) == 0 {
 		return
 	}
-	vastXML := makeVAST(bid)
+	vastXML := MakeVAST(bid)
 	bidID := bid.ID
 	if len(pbsBid.generatedBidID) > 0 {
 		bidID = pbsBid.generatedBidID
@@ -16,14 +16,18 @@ import (
 
 	"github.com/mxmCherry/openrtb/v15/openrtb2"
 	"github.com/prebid/prebid-server/stored_requests"
+	"github.com/prebid/prebid-server/stored_responses"
 
 	"github.com/gofrs/uuid"
 	"github.com/golang/glog"
 	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/analytics"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/currency"
 	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/floors"
 	"github.com/prebid/prebid-server/gdpr"
+	"github.com/prebid/prebid-server/hooks/hookexecution"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/prebid_cache_client"
@@ -63,6 +67,8 @@ type exchange struct {
 	privacyConfig     config.Privacy
 	categoriesFetcher stored_requests.CategoryFetcher
 	bidIDGenerator    BidIDGenerator
+	floorsEnabled     bool
+	floorsFetcher     floors.Fetcher
 }
 
 // Container to pass out response ext data from the GetAllBids goroutines back into the main thread
@@ -109,7 +115,7 @@ func (randomDeduplicateBidBooleanGenerator) Generate() bool {
 	return rand.Intn(100) < 50
 }
 
-func NewExchange(adapters map[openrtb_ext.BidderName]adaptedBidder, cache prebid_cache_client.Client, cfg *config.Configuration, metricsEngine metrics.MetricsEngine, infos config.BidderInfos, gDPR gdpr.Permissions, currencyConverter *currency.RateConverter, categoriesFetcher stored_requests.CategoryFetcher) Exchange {
+func NewExchange(adapters map[openrtb_ext.BidderName]adaptedBidder, cache prebid_cache_client.Client, cfg *config.Configuration, metricsEngine metrics.MetricsEngine, infos config.BidderInfos, gDPR gdpr.Permissions, currencyConverter *currency.RateConverter, categoriesFetcher stored_requests.CategoryFetcher, floorsFetcher floors.Fetcher) Exchange {
 	gdprDefaultValue := gdpr.SignalYes
 	if cfg.GDPR.DefaultValue == "0" {
 		gdprDefaultValue = gdpr.SignalNo
@@ -132,6 +138,8 @@ func NewExchange(adapters map[openrtb_ext.BidderName]adaptedBidder, cache prebid
 			LMT:  cfg.LMT,
 		},
 		bidIDGenerator: &bidIDGenerator{cfg.GenerateBidID},
+		floorsEnabled:  cfg.PriceFloors.Enabled,
+		floorsFetcher:  floorsFetcher,
 	}
 }
 
@@ -145,6 +153,14 @@ type AuctionRequest struct {
 	StartTime                  time.Time
 	Warnings                   []error
 	GlobalPrivacyControlHeader string
+	// HookExecutor runs the hook stages of the auction. If nil, no hooks are run.
+	HookExecutor hookexecution.StageExecutor
+	// SeatDetails, if not nil, is set to the details of every bidder of the auction once it's over.
+	SeatDetails *[]*analytics.SeatDetails
+	// StoredAuctionResponses, if not empty, hold the seatbids of every imp. They are used instead of calling the bidders.
+	StoredAuctionResponses stored_responses.ImpsWithBidResponses
+	// StoredBidResponses hold the bidder responses fed to the bidders instead of calling them, by bidder and imp.
+	StoredBidResponses stored_responses.BidderImpsWithBidResponses
 
 	// LegacyLabels is included here for temporary compatability with cleanOpenRTBRequests
 	// in HoldAuction until we get to factoring it away. Do not use for anything new.
@@ -158,6 +174,8 @@ type BidderRequest struct {
 	BidderName     openrtb_ext.BidderName
 	BidderCoreName openrtb_ext.BidderName
 	BidderLabels   metrics.AdapterLabels
+	// StoredBidResponses are the stored responses of the bidder, keyed by imp ID.
+	StoredBidResponses map[string]json.RawMessage
 }
 
 func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *DebugLog) (*openrtb2.BidResponse, error) {
@@ -179,6 +197,11 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 
 	requestDebugInfo := getDebugInfo(r.BidRequest, requestExt)
 
+	hookExecutor := r.HookExecutor
+	if hookExecutor == nil {
+		hookExecutor = hookexecution.EmptyHookExecutor{}
+	}
+
 	debugInfo := debugLog.DebugEnabledOrOverridden || (requestDebugInfo && r.Account.DebugAllow)
 	debugLog.Enabled = debugLog.DebugEnabledOrOverridden || r.Account.DebugAllow
 
@@ -186,18 +209,32 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 		ctx = e.makeDebugContext(ctx, debugInfo)
 	}
 
-	bidAdjustmentFactors := getExtBidAdjustmentFactors(requestExt)
+	bidAdjustmentFactors := getExtBidAdjustmentFactors(requestExt, &r.Account)
 
 	recordImpMetrics(r.BidRequest, e.me)
 
+	// Get currency rates conversions for the auction
+	conversions := e.getAuctionCurrencyRates(requestExt.Prebid.CurrencyConversions)
+
+	// Set the price floor of each imp before the request is split, so that every bidder sees it
+	var impFloors map[string]floors.Result
+	var floorsEnforcement floors.Enforcement
+	var floorErrs []error
+	r.BidRequest, impFloors, floorsEnforcement, floorErrs = e.selectFloors(r, requestExt, conversions)
+
 	// Make our best guess if GDPR applies
 	gdprDefaultValue := e.parseGDPRDefaultValue(r.BidRequest)
 
 	// Slice of BidRequests, each a copy of the original cleaned to only contain bidder data for the named bidder
 	bidderRequests, privacyLabels, errs := cleanOpenRTBRequests(ctx, r, requestExt, e.gDPR, e.me, gdprDefaultValue, e.privacyConfig, &r.Account)
+	errs = append(errs, floorErrs...)
 
 	e.me.RecordRequestPrivacy(privacyLabels)
 
+	for i := range bidderRequests {
+		bidderRequests[i].StoredBidResponses = r.StoredBidResponses[bidderRequests[i].BidderName]
+	}
+
 	// List of bidders we have requests for.
 	liveAdapters := listBiddersWithRequests(bidderRequests)
 
@@ -206,29 +243,64 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 	auctionCtx, cancel := e.makeAuctionContext(ctx, cacheInstructions.cacheBids)
 	defer cancel()
 
-	// Get currency rates conversions for the auction
-	conversions := e.getAuctionCurrencyRates(requestExt.Prebid.CurrencyConversions)
+	// The bidders which haven't responded by the soft auction timeout time out, and the auction goes on
+	// with the bids received so far. The hard deadline still applies to caching.
+	auctionCtx, cancelSoftTimeout := makeSoftTimeoutContext(auctionCtx, r.StartTime, requestExt.Prebid.AuctionTimeout)
+	defer cancelSoftTimeout()
+
+	var adapterBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid
+	var adapterExtra map[openrtb_ext.BidderName]*seatResponseExtra
+	var receivedSeatBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid
+	var anyBidsReturned bool
+	if len(r.StoredAuctionResponses) > 0 {
+		adapterBids, adapterExtra, liveAdapters, err = buildStoredAuctionResponse(r.BidRequest, r.StoredAuctionResponses)
+		if err != nil {
+			return nil, err
+		}
+		receivedSeatBids = adapterBids
+		anyBidsReturned = len(adapterBids) > 0
+	} else {
+		adapterBids, adapterExtra, receivedSeatBids, anyBidsReturned = e.getAllBids(auctionCtx, bidderRequests, bidAdjustmentFactors, conversions, r.Account.DebugAllow, r.GlobalPrivacyControlHeader, debugLog.DebugOverride, hookExecutor)
+	}
+
+	// The bidders already reported the bids they rejected, so only the rejections made past this point become errors
+	bidderRejections := countRejectedBids(receivedSeatBids)
 
-	adapterBids, adapterExtra, anyBidsReturned := e.getAllBids(auctionCtx, bidderRequests, bidAdjustmentFactors, conversions, r.Account.DebugAllow, r.GlobalPrivacyControlHeader, debugLog.DebugOverride)
+	if anyBidsReturned && len(impFloors) > 0 {
+		enforceFloors(adapterBids, impFloors, floorsEnforcement, conversions)
+	}
+
+	if anyBidsReturned {
+		adapterBids, anyBidsReturned = executeAllProcessedBidResponsesStage(hookExecutor, adapterBids)
+	}
+
+	// Invalid multibid entries were already reported by the endpoint.
+	multiBid, _ := openrtb_ext.ValidateMultiBid(requestExt.Prebid.MultiBid)
+	if anyBidsReturned && len(multiBid) > 0 {
+		adapterBids, anyBidsReturned = applyMultiBidLimits(adapterBids, multiBid, targData != nil && targData.preferDeals)
+	}
+
+	var bidCategory map[string]string
+	//If includebrandcategory is present in ext then CE feature is on.
+	if anyBidsReturned && requestExt.Prebid.Targeting != nil && requestExt.Prebid.Targeting.IncludeBrandCategory != nil {
+		bidCategory, adapterBids, err = applyCategoryMapping(ctx, requestExt, adapterBids, e.categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
+		if err != nil {
+			return nil, fmt.Errorf("Error in category mapping : %s", err.Error())
+		}
+	}
+	errs = append(errs, makeRejectionErrors(receivedSeatBids, bidderRejections)...)
+
+	// Every bid was rejected by now, so that the seatnonbid is complete in the debug log as well
+	var seatNonBids []openrtb_ext.SeatNonBid
+	if debugInfo || requestExt.Prebid.ReturnAllBidStatus {
+		seatNonBids = makeSeatNonBids(receivedSeatBids)
+	}
 
 	var auc *auction
 	var cacheErrs []error
 	var bidResponseExt *openrtb_ext.ExtBidResponse
 	if anyBidsReturned {
 
-		var bidCategory map[string]string
-		//If includebrandcategory is present in ext then CE feature is on.
-		if requestExt.Prebid.Targeting != nil && requestExt.Prebid.Targeting.IncludeBrandCategory != nil {
-			var rejections []string
-			bidCategory, adapterBids, rejections, err = applyCategoryMapping(ctx, requestExt, adapterBids, e.categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
-			if err != nil {
-				return nil, fmt.Errorf("Error in category mapping : %s", err.Error())
-			}
-			for _, message := range rejections {
-				errs = append(errs, errors.New(message))
-			}
-		}
-
 		if e.bidIDGenerator.Enabled() {
 			for _, seatBid := range adapterBids {
 				for _, pbsBid := range seatBid.bids {
@@ -245,7 +317,7 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 
 		if targData != nil {
 			// A non-nil auction is only needed if targeting is active. (It is used below this block to extract cache keys)
-			auc = newAuction(adapterBids, len(r.BidRequest.Imp), targData.preferDeals)
+			auc = newAuction(adapterBids, len(r.BidRequest.Imp), targData.preferDeals, multiBid)
 			auc.setRoundedPrices(targData.priceGranularity)
 
 			if requestExt.Prebid.SupportDeals {
@@ -254,6 +326,7 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 			}
 
 			bidResponseExt = e.makeExtBidResponse(adapterBids, adapterExtra, r, debugInfo, errs)
+			bidResponseExt.SeatNonBid = seatNonBids
 			if debugLog.DebugEnabledOrOverridden {
 				if bidRespExtBytes, err := json.Marshal(bidResponseExt); err == nil {
 					debugLog.Data.Response = string(bidRespExtBytes)
@@ -263,7 +336,7 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 				}
 			}
 
-			cacheErrs = auc.doCache(ctx, e.cache, targData, evTracking, r.BidRequest, 60, &r.Account.CacheTTL, bidCategory, debugLog)
+			cacheErrs = auc.doCache(ctx, e.cache, targData, evTracking, r.BidRequest, cacheTTLBuffer, &r.Account.CacheTTL, bidCategory, debugLog)
 			if len(cacheErrs) > 0 {
 				errs = append(errs, cacheErrs...)
 			}
@@ -272,8 +345,10 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 
 		}
 		bidResponseExt = e.makeExtBidResponse(adapterBids, adapterExtra, r, debugInfo, errs)
+		bidResponseExt.SeatNonBid = seatNonBids
 	} else {
 		bidResponseExt = e.makeExtBidResponse(adapterBids, adapterExtra, r, debugInfo, errs)
+		bidResponseExt.SeatNonBid = seatNonBids
 
 		if debugLog.DebugEnabledOrOverridden {
 
@@ -286,6 +361,8 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 		}
 	}
 
+	e.recordRejectedBids(bidderRequests, receivedSeatBids)
+
 	if !r.Account.DebugAllow && requestDebugInfo && !debugLog.DebugOverride {
 		accountDebugDisabledWarning := openrtb_ext.ExtBidderMessage{
 			Code:    errortypes.AccountLevelDebugDisabledWarningCode,
@@ -303,7 +380,144 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 	}
 
 	// Build the response
-	return e.buildBidResponse(ctx, liveAdapters, adapterBids, r.BidRequest, adapterExtra, auc, bidResponseExt, cacheInstructions.returnCreative, errs)
+	bidResponse, err := e.buildBidResponse(ctx, liveAdapters, adapterBids, r.BidRequest, adapterExtra, auc, bidResponseExt, cacheInstructions.returnCreative, errs)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.SeatDetails != nil {
+		*r.SeatDetails = makeSeatDetails(bidderRequests, receivedSeatBids, adapterExtra)
+	}
+
+	hookExecutor.ExecuteAuctionResponseStage(bidResponse)
+	return bidResponse, nil
+}
+
+// recordRejectedBids counts the bids removed from the auction, by adapter and reason.
+func (e *exchange) recordRejectedBids(bidderRequests []BidderRequest, receivedSeatBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid) {
+	for _, bidderRequest := range bidderRequests {
+		seatBid, ok := receivedSeatBids[bidderRequest.BidderName]
+		if !ok {
+			continue
+		}
+		for _, rejectedBid := range seatBid.rejectedBids {
+			e.me.RecordAdapterRejectedBid(bidderRequest.BidderCoreName, rejectedBid.Reason)
+		}
+	}
+}
+
+// countRejectedBids returns the number of bids rejected so far by every seat.
+func countRejectedBids(seatBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid) map[openrtb_ext.BidderName]int {
+	counts := make(map[openrtb_ext.BidderName]int, len(seatBids))
+	for bidderName, seatBid := range seatBids {
+		counts[bidderName] = len(seatBid.rejectedBids)
+	}
+	return counts
+}
+
+// makeRejectionErrors describes the bids rejected by the seats since the counts were taken, sorted by seat.
+func makeRejectionErrors(seatBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid, counts map[openrtb_ext.BidderName]int) []error {
+	bidderNames := make([]openrtb_ext.BidderName, 0, len(seatBids))
+	for bidderName, seatBid := range seatBids {
+		if len(seatBid.rejectedBids) > counts[bidderName] {
+			bidderNames = append(bidderNames, bidderName)
+		}
+	}
+	sort.Slice(bidderNames, func(i, j int) bool {
+		return bidderNames[i] < bidderNames[j]
+	})
+
+	var errs []error
+	for _, bidderName := range bidderNames {
+		for _, rejectedBid := range seatBids[bidderName].rejectedBids[counts[bidderName]:] {
+			var bidID string
+			if rejectedBid.Bid != nil {
+				bidID = rejectedBid.Bid.ID
+			}
+			errs = append(errs, fmt.Errorf("bid rejected [bid ID: %s] reason: %s", bidID, rejectedBid.Message))
+		}
+	}
+	return errs
+}
+
+// makeSeatNonBids lists the bids removed from the auction for bidresponse.ext.seatnonbid, sorted by seat.
+func makeSeatNonBids(receivedSeatBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid) []openrtb_ext.SeatNonBid {
+	var seatNonBids []openrtb_ext.SeatNonBid
+	for bidderName, seatBid := range receivedSeatBids {
+		if len(seatBid.rejectedBids) == 0 {
+			continue
+		}
+		seatNonBid := openrtb_ext.SeatNonBid{
+			Seat:   string(bidderName),
+			NonBid: make([]openrtb_ext.NonBid, 0, len(seatBid.rejectedBids)),
+		}
+		for _, rejectedBid := range seatBid.rejectedBids {
+			nonBid := openrtb_ext.NonBid{StatusCode: rejectedBid.Reason}
+			nonBid.Ext.Prebid.Reason = rejectedBid.Message
+			if rejectedBid.Bid != nil {
+				nonBid.ImpID = rejectedBid.Bid.ImpID
+				nonBid.Ext.Prebid.Bid = openrtb_ext.NonBidExtPrebidBid{ID: rejectedBid.Bid.ID, Price: rejectedBid.Bid.Price}
+			}
+			seatNonBid.NonBid = append(seatNonBid.NonBid, nonBid)
+		}
+		seatNonBids = append(seatNonBids, seatNonBid)
+	}
+	sort.Slice(seatNonBids, func(i, j int) bool {
+		return seatNonBids[i].Seat < seatNonBids[j].Seat
+	})
+	return seatNonBids
+}
+
+// makeSeatDetails describes what every bidder of the auction was sent and returned, for the analytics.
+// It must be called once the auction is over, so that the rejected bids and the targeting are known.
+func makeSeatDetails(bidderRequests []BidderRequest, receivedSeatBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid, adapterExtra map[openrtb_ext.BidderName]*seatResponseExtra) []*analytics.SeatDetails {
+	seatDetails := make([]*analytics.SeatDetails, 0, len(bidderRequests))
+	for _, bidderRequest := range bidderRequests {
+		details := &analytics.SeatDetails{
+			Bidder:  bidderRequest.BidderName,
+			Request: bidderRequest.BidRequest,
+		}
+		if extra, ok := adapterExtra[bidderRequest.BidderName]; ok && extra != nil {
+			details.ResponseTimeMillis = extra.ResponseTimeMillis
+		}
+		if seatBid, ok := receivedSeatBids[bidderRequest.BidderName]; ok {
+			details.HttpCalls = seatBid.httpCallDetails
+			details.Bids = seatBid.receivedBids
+			details.RejectedBids = seatBid.rejectedBids
+			for _, pbsBid := range seatBid.bids {
+				if len(pbsBid.bidTargets) == 0 {
+					continue
+				}
+				if details.Targeting == nil {
+					details.Targeting = make(map[string]map[string]string)
+				}
+				details.Targeting[pbsBid.bid.ID] = pbsBid.bidTargets
+			}
+		}
+		seatDetails = append(seatDetails, details)
+	}
+	return seatDetails
+}
+
+// selectFloors resolves the price floor rules which apply to the auction and returns the request with the floor
+// of every imp set, along with the selected floors keyed by imp ID, which is empty if floors are disabled.
+func (e *exchange) selectFloors(r AuctionRequest, requestExt *openrtb_ext.ExtRequest, conversions currency.Conversions) (*openrtb2.BidRequest, map[string]floors.Result, floors.Enforcement, []error) {
+	if !e.floorsEnabled {
+		return r.BidRequest, nil, floors.Enforcement{}, nil
+	}
+
+	var storedRules *openrtb_ext.PriceFloorRules
+	if e.floorsFetcher != nil {
+		storedRules = e.floorsFetcher.Fetch(r.Account.ID)
+	}
+
+	rules := floors.ResolveRules(requestExt.Prebid.Floors, storedRules, r.Account.PriceFloors)
+	if rules == nil {
+		return r.BidRequest, nil, floors.Enforcement{}, nil
+	}
+
+	request, impFloors, errs := floors.SelectFloors(r.BidRequest, rules, conversions)
+	return request, impFloors, floors.ResolveEnforcement(rules, r.Account.PriceFloors), errs
 }
 
 func (e *exchange) parseGDPRDefaultValue(bidRequest *openrtb2.BidRequest) gdpr.Signal {
@@ -348,12 +562,14 @@ func applyDealSupport(bidRequest *openrtb2.BidRequest, auc *auction, bidCategory
 
 	for impID, topBidsPerImp := range auc.winningBidsByBidder {
 		impDeal := impDealMap[impID]
-		for bidder, topBidPerBidder := range topBidsPerImp {
-			if topBidPerBidder.dealPriority > 0 {
-				if validateDealTier(impDeal[bidder]) {
-					updateHbPbCatDur(topBidPerBidder, impDeal[bidder], bidCategory)
-				} else {
-					errs = append(errs, fmt.Errorf("dealTier configuration invalid for bidder '%s', imp ID '%s'", string(bidder), impID))
+		for bidder, topBidsPerBidder := range topBidsPerImp {
+			for _, topBid := range topBidsPerBidder {
+				if topBid.dealPriority > 0 {
+					if validateDealTier(impDeal[bidder]) {
+						updateHbPbCatDur(topBid, impDeal[bidder], bidCategory)
+					} else {
+						errs = append(errs, fmt.Errorf("dealTier configuration invalid for bidder '%s', imp ID '%s'", string(bidder), impID))
+					}
 				}
 			}
 		}
@@ -412,6 +628,23 @@ func (e *exchange) makeAuctionContext(ctx context.Context, needsCache bool) (auc
 	return
 }
 
+// makeSoftTimeoutContext returns a context whose deadline is the auction timeout requested through
+// ext.prebid.auctiontimeout, measured from the start of the auction, unless the context already expires earlier.
+// The bidders cut off by this deadline don't count against their circuit breaker or their latencies.
+func makeSoftTimeoutContext(ctx context.Context, start time.Time, auctionTimeoutMillis int64) (context.Context, context.CancelFunc) {
+	if auctionTimeoutMillis <= 0 {
+		return ctx, func() {}
+	}
+	if start.IsZero() {
+		start = time.Now()
+	}
+	softDeadline := start.Add(time.Duration(auctionTimeoutMillis) * time.Millisecond)
+	if deadline, ok := ctx.Deadline(); ok && !softDeadline.Before(deadline) {
+		return ctx, func() {}
+	}
+	return withDeadlineCause(ctx, softDeadline, deadlineSoftTimeout)
+}
+
 // This piece sends all the requests to the bidder adapters and gathers the results.
 func (e *exchange) getAllBids(
 	ctx context.Context,
@@ -420,12 +653,16 @@ func (e *exchange) getAllBids(
 	conversions currency.Conversions,
 	accountDebugAllowed bool,
 	globalPrivacyControlHeader string,
-	headerDebugAllowed bool) (
+	headerDebugAllowed bool,
+	hookExecutor hookexecution.StageExecutor) (
 	map[openrtb_ext.BidderName]*pbsOrtbSeatBid,
-	map[openrtb_ext.BidderName]*seatResponseExtra, bool) {
+	map[openrtb_ext.BidderName]*seatResponseExtra,
+	map[openrtb_ext.BidderName]*pbsOrtbSeatBid, bool) {
 	// Set up pointers to the bid results
 	adapterBids := make(map[openrtb_ext.BidderName]*pbsOrtbSeatBid, len(bidderRequests))
 	adapterExtra := make(map[openrtb_ext.BidderName]*seatResponseExtra, len(bidderRequests))
+	// receivedSeatBids keeps the seats without any bids left as well, for the analytics
+	receivedSeatBids := make(map[openrtb_ext.BidderName]*pbsOrtbSeatBid, len(bidderRequests))
 	chBids := make(chan *bidResponseWrapper, len(bidderRequests))
 	bidsFound := false
 
@@ -452,7 +689,8 @@ func (e *exchange) getAllBids(
 			reqInfo := adapters.NewExtraRequestInfo(conversions)
 			reqInfo.PbsEntryPoint = bidderRequest.BidderLabels.RType
 			reqInfo.GlobalPrivacyControlHeader = globalPrivacyControlHeader
-			bids, err := e.adapterMap[bidderRequest.BidderCoreName].requestBid(ctx, bidderRequest.BidRequest, bidderRequest.BidderName, adjustmentFactor, conversions, &reqInfo, accountDebugAllowed, headerDebugAllowed)
+			reqInfo.StoredBidResponses = bidderRequest.StoredBidResponses
+			bids, err := e.adapterMap[bidderRequest.BidderCoreName].requestBid(ctx, bidderRequest.BidRequest, bidderRequest.BidderName, adjustmentFactor, conversions, &reqInfo, accountDebugAllowed, headerDebugAllowed, hookExecutor)
 
 			// Add in time reporting
 			elapsed := time.Since(start)
@@ -476,6 +714,7 @@ func (e *exchange) getAllBids(
 				for _, bid := range bids.bids {
 					var cpm = float64(bid.bid.Price * 1000)
 					e.me.RecordAdapterPrice(bidderRequest.BidderLabels, cpm)
+					e.me.RecordAdapterBidPrice(bidderRequest.BidderLabels.Adapter, bid.bidType, cpm)
 					e.me.RecordAdapterBidReceived(bidderRequest.BidderLabels, bid.bidType, bid.bid.AdM != "")
 				}
 			}
@@ -493,13 +732,89 @@ func (e *exchange) getAllBids(
 		}
 		//but we need to add all bidders data to adapterExtra to have metrics and other metadata
 		adapterExtra[brw.bidder] = brw.adapterExtra
+		if brw.adapterBids != nil {
+			receivedSeatBids[brw.bidder] = brw.adapterBids
+		}
 
 		if !bidsFound && adapterBids[brw.bidder] != nil && len(adapterBids[brw.bidder].bids) > 0 {
 			bidsFound = true
 		}
 	}
 
-	return adapterBids, adapterExtra, bidsFound
+	return adapterBids, adapterExtra, receivedSeatBids, bidsFound
+}
+
+// buildStoredAuctionResponse returns the bids of the stored auction responses in place of the bids of the bidders.
+// The stored bids are made for the imp the stored response is set on, in USD, and are typed by bid.ext.prebid.type
+// or else by the media type of the imp.
+func buildStoredAuctionResponse(request *openrtb2.BidRequest, storedAuctionResponses stored_responses.ImpsWithBidResponses) (
+	map[openrtb_ext.BidderName]*pbsOrtbSeatBid,
+	map[openrtb_ext.BidderName]*seatResponseExtra,
+	[]openrtb_ext.BidderName,
+	error) {
+	adapterBids := make(map[openrtb_ext.BidderName]*pbsOrtbSeatBid)
+	adapterExtra := make(map[openrtb_ext.BidderName]*seatResponseExtra)
+	var liveAdapters []openrtb_ext.BidderName
+
+	// Going through the imps in order keeps the response deterministic
+	for _, imp := range request.Imp {
+		storedData, ok := storedAuctionResponses[imp.ID]
+		if !ok {
+			continue
+		}
+		var seatBids []openrtb2.SeatBid
+		if err := json.Unmarshal(storedData, &seatBids); err != nil {
+			return nil, nil, nil, fmt.Errorf("Error decoding the stored auction response of imp %s: %v", imp.ID, err)
+		}
+
+		for _, seatBid := range seatBids {
+			bidder := openrtb_ext.BidderName(seatBid.Seat)
+			if _, ok := adapterExtra[bidder]; !ok {
+				adapterExtra[bidder] = &seatResponseExtra{}
+				liveAdapters = append(liveAdapters, bidder)
+			}
+			for i := range seatBid.Bid {
+				bid := &seatBid.Bid[i]
+				bid.ImpID = imp.ID
+				bidType, err := storedBidType(bid, imp)
+				if err != nil {
+					return nil, nil, nil, fmt.Errorf("Error decoding the stored auction response of imp %s: %v", imp.ID, err)
+				}
+				if adapterBids[bidder] == nil {
+					adapterBids[bidder] = &pbsOrtbSeatBid{currency: "USD"}
+				}
+				receivedBid := *bid
+				adapterBids[bidder].bids = append(adapterBids[bidder].bids, &pbsOrtbBid{bid: bid, bidType: bidType})
+				adapterBids[bidder].receivedBids = append(adapterBids[bidder].receivedBids, &receivedBid)
+			}
+		}
+	}
+
+	return adapterBids, adapterExtra, liveAdapters, nil
+}
+
+// storedBidType returns the bid.ext.prebid.type of a stored bid or, if it isn't set, the media type of its imp.
+func storedBidType(bid *openrtb2.Bid, imp openrtb2.Imp) (openrtb_ext.BidType, error) {
+	if len(bid.Ext) > 0 {
+		var bidExt openrtb_ext.ExtBid
+		if err := json.Unmarshal(bid.Ext, &bidExt); err != nil {
+			return "", err
+		}
+		if bidExt.Prebid != nil && bidExt.Prebid.Type != "" {
+			return openrtb_ext.ParseBidType(string(bidExt.Prebid.Type))
+		}
+	}
+
+	switch {
+	case imp.Video != nil:
+		return openrtb_ext.BidTypeVideo, nil
+	case imp.Native != nil:
+		return openrtb_ext.BidTypeNative, nil
+	case imp.Audio != nil:
+		return openrtb_ext.BidTypeAudio, nil
+	default:
+		return openrtb_ext.BidTypeBanner, nil
+	}
 }
 
 func (e *exchange) recoverSafely(bidderRequests []BidderRequest,
@@ -628,7 +943,9 @@ func encodeBidResponseExt(bidResponseExt *openrtb_ext.ExtBidResponse) ([]byte, e
 	return buffer.Bytes(), err
 }
 
-func applyCategoryMapping(ctx context.Context, requestExt *openrtb_ext.ExtRequest, seatBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid, categoriesFetcher stored_requests.CategoryFetcher, targData *targetData, booleanGenerator deduplicateChanceGenerator) (map[string]string, map[openrtb_ext.BidderName]*pbsOrtbSeatBid, []string, error) {
+// applyCategoryMapping removes the bids which fail the category mapping, exceed the allowed durations or are
+// deduplicated, and records them as rejected by their seat.
+func applyCategoryMapping(ctx context.Context, requestExt *openrtb_ext.ExtRequest, seatBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid, categoriesFetcher stored_requests.CategoryFetcher, targData *targetData, booleanGenerator deduplicateChanceGenerator) (map[string]string, map[openrtb_ext.BidderName]*pbsOrtbSeatBid, error) {
 	res := make(map[string]string)
 
 	type bidDedupe struct {
@@ -636,6 +953,7 @@ func applyCategoryMapping(ctx context.Context, requestExt *openrtb_ext.ExtReques
 		bidIndex   int
 		bidID      string
 		bidPrice   string
+		bid        *openrtb2.Bid
 	}
 
 	dedupe := make(map[string]bidDedupe)
@@ -651,7 +969,6 @@ func applyCategoryMapping(ctx context.Context, requestExt *openrtb_ext.ExtReques
 	var primaryAdServer string
 	var publisher string
 	var err error
-	var rejections []string
 	var translateCategories = true
 
 	if includeBrandCategory && brandCatExt.WithCategory {
@@ -663,7 +980,7 @@ func applyCategoryMapping(ctx context.Context, requestExt *openrtb_ext.ExtReques
 			//if ext.prebid.targeting.includebrandcategory present but primaryadserver/publisher not present then error out the request right away.
 			primaryAdServer, err = getPrimaryAdServer(brandCatExt.PrimaryAdServer) //1-Freewheel 2-DFP
 			if err != nil {
-				return res, seatBids, rejections, err
+				return res, seatBids, err
 			}
 			publisher = brandCatExt.Publisher
 		}
@@ -690,7 +1007,7 @@ func applyCategoryMapping(ctx context.Context, requestExt *openrtb_ext.ExtReques
 					//TODO: add metrics
 					//on receiving bids from adapters if no unique IAB category is returned  or if no ad server category is returned discard the bid
 					bidsToRemove = append(bidsToRemove, bidInd)
-					rejections = updateRejections(rejections, bidID, "Bid did not contain a category")
+					seatBid.rejectBid(bid.bid, openrtb_ext.RejectionReasonCategoryMapping, "Bid did not contain a category")
 					continue
 				}
 				if translateCategories {
@@ -701,7 +1018,7 @@ func applyCategoryMapping(ctx context.Context, requestExt *openrtb_ext.ExtReques
 						//if mapping required but no mapping file is found then discard the bid
 						bidsToRemove = append(bidsToRemove, bidInd)
 						reason := fmt.Sprintf("Category mapping file for primary ad server: '%s', publisher: '%s' not found", primaryAdServer, publisher)
-						rejections = updateRejections(rejections, bidID, reason)
+						seatBid.rejectBid(bid.bid, openrtb_ext.RejectionReasonCategoryMapping, reason)
 						continue
 					}
 				} else {
@@ -721,7 +1038,7 @@ func applyCategoryMapping(ctx context.Context, requestExt *openrtb_ext.ExtReques
 				//if the bid is above the range of the listed durations (and outside the buffer), reject the bid
 				if duration > durationRange[len(durationRange)-1] {
 					bidsToRemove = append(bidsToRemove, bidInd)
-					rejections = updateRejections(rejections, bidID, "Bid duration exceeds maximum allowed")
+					seatBid.rejectBid(bid.bid, openrtb_ext.RejectionReasonDuration, "Bid duration exceeds maximum allowed")
 					continue
 				}
 				for _, dur := range durationRange {
@@ -768,11 +1085,11 @@ func applyCategoryMapping(ctx context.Context, requestExt *openrtb_ext.ExtReques
 					if dupe.bidderName == bidderName {
 						// An older bid from the current bidder
 						bidsToRemove = append(bidsToRemove, dupe.bidIndex)
-						rejections = updateRejections(rejections, dupe.bidID, "Bid was deduplicated")
+						seatBid.rejectBid(dupe.bid, openrtb_ext.RejectionReasonDuplicate, "Bid was deduplicated")
 					} else {
 						// An older bid from a different seatBid we've already finished with
 						oldSeatBid := (seatBids)[dupe.bidderName]
-						rejections = updateRejections(rejections, dupe.bidID, "Bid was deduplicated")
+						oldSeatBid.rejectBid(dupe.bid, openrtb_ext.RejectionReasonDuplicate, "Bid was deduplicated")
 						if len(oldSeatBid.bids) == 1 {
 							seatBidsToRemove = append(seatBidsToRemove, dupe.bidderName)
 						} else {
@@ -788,12 +1105,12 @@ func applyCategoryMapping(ctx context.Context, requestExt *openrtb_ext.ExtReques
 				} else {
 					// Remove this bid
 					bidsToRemove = append(bidsToRemove, bidInd)
-					rejections = updateRejections(rejections, bidID, "Bid was deduplicated")
+					seatBid.rejectBid(bid.bid, openrtb_ext.RejectionReasonDuplicate, "Bid was deduplicated")
 					continue
 				}
 			}
 			res[bidID] = categoryDuration
-			dedupe[dupeKey] = bidDedupe{bidderName: bidderName, bidIndex: bidInd, bidID: bidID, bidPrice: pb}
+			dedupe[dupeKey] = bidDedupe{bidderName: bidderName, bidIndex: bidInd, bidID: bidID, bidPrice: pb, bid: bid.bid}
 		}
 
 		if len(bidsToRemove) > 0 {
@@ -816,7 +1133,7 @@ func applyCategoryMapping(ctx context.Context, requestExt *openrtb_ext.ExtReques
 		seatBids[seatBidInd].bids = nil
 	}
 
-	return res, seatBids, rejections, nil
+	return res, seatBids, nil
 }
 
 func removeBidById(seatBid *pbsOrtbSeatBid, bidID string) {
@@ -837,11 +1154,6 @@ func removeBidById(seatBid *pbsOrtbSeatBid, bidID string) {
 	}
 }
 
-func updateRejections(rejections []string, bidID string, reason string) []string {
-	message := fmt.Sprintf("bid rejected [bid ID: %s] reason: %s", bidID, reason)
-	return append(rejections, message)
-}
-
 func getPrimaryAdServer(adServerId int) (string, error) {
 	switch adServerId {
 	case 1:
@@ -927,6 +1239,8 @@ func (e *exchange) makeBid(bids []*pbsOrtbBid, auc *auction, returnCreative bool
 			Type:              bid.bidType,
 			Video:             bid.bidVideo,
 			BidId:             bid.generatedBidID,
+			Floors:            bid.bidFloors,
+			TargetBidderCode:  string(bid.targetBidderCode),
 		}
 
 		if cacheInfo, found := e.getBidCacheInfo(bid, auc); found {
@@ -17,10 +17,13 @@ import (
 
 	"github.com/mxmCherry/openrtb/v15/openrtb2"
 	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/analytics"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/currency"
 	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/floors"
 	"github.com/prebid/prebid-server/gdpr"
+	"github.com/prebid/prebid-server/hooks/hookexecution"
 	"github.com/prebid/prebid-server/metrics"
 	metricsConf "github.com/prebid/prebid-server/metrics/config"
 	metricsConfig "github.com/prebid/prebid-server/metrics/config"
@@ -28,6 +31,7 @@ import (
 	pbc "github.com/prebid/prebid-server/prebid_cache_client"
 	"github.com/prebid/prebid-server/stored_requests"
 	"github.com/prebid/prebid-server/stored_requests/backends/file_fetcher"
+	"github.com/prebid/prebid-server/stored_responses"
 
 	"github.com/buger/jsonparser"
 	"github.com/stretchr/testify/assert"
@@ -65,7 +69,7 @@ func TestNewExchange(t *testing.T) {
 	}
 
 	currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
-	e := NewExchange(adapters, nil, cfg, &metricsConf.DummyMetricsEngine{}, biddersInfo, gdpr.AlwaysAllow{}, currencyConverter, nilCategoryFetcher{}).(*exchange)
+	e := NewExchange(adapters, nil, cfg, &metricsConf.DummyMetricsEngine{}, biddersInfo, gdpr.AlwaysAllow{}, currencyConverter, nilCategoryFetcher{}, floors.EmptyFetcher{}).(*exchange)
 	for _, bidderName := range knownAdapters {
 		if _, ok := e.adapterMap[bidderName]; !ok {
 			t.Errorf("NewExchange produced an Exchange without bidder %s", bidderName)
@@ -113,7 +117,7 @@ func TestCharacterEscape(t *testing.T) {
 	}
 
 	currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
-	e := NewExchange(adapters, nil, cfg, &metricsConf.DummyMetricsEngine{}, biddersInfo, gdpr.AlwaysAllow{}, currencyConverter, nilCategoryFetcher{}).(*exchange)
+	e := NewExchange(adapters, nil, cfg, &metricsConf.DummyMetricsEngine{}, biddersInfo, gdpr.AlwaysAllow{}, currencyConverter, nilCategoryFetcher{}, floors.EmptyFetcher{}).(*exchange)
 
 	// 	3) Build all the parameters e.buildBidResponse(ctx.Background(), liveA... ) needs
 	//liveAdapters []openrtb_ext.BidderName,
@@ -761,6 +765,280 @@ func TestAdapterCurrency(t *testing.T) {
 	}
 }
 
+func TestHoldAuctionStoredAuctionResponse(t *testing.T) {
+	mockBidder := &mockBidder{}
+	e := exchange{
+		cache:             &wellBehavedCache{},
+		me:                &metricsConf.DummyMetricsEngine{},
+		gDPR:              gdpr.AlwaysAllow{},
+		currencyConverter: currency.NewRateConverter(&http.Client{}, "", time.Duration(0)),
+		categoriesFetcher: nilCategoryFetcher{},
+		bidIDGenerator:    &mockBidIDGenerator{false, false},
+		adapterMap: map[openrtb_ext.BidderName]adaptedBidder{
+			openrtb_ext.BidderName("foo"): adaptBidder(mockBidder, nil, &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderName("foo"), nil),
+		},
+	}
+	request := &openrtb2.BidRequest{
+		ID: "some-request-id",
+		Imp: []openrtb2.Imp{{
+			ID:     "some-impression-id",
+			Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 300, H: 250}}},
+			Ext:    json.RawMessage(`{"foo": {"placementId": 1}}`),
+		}},
+		Site: &openrtb2.Site{Page: "prebid.org"},
+	}
+	auctionRequest := AuctionRequest{
+		BidRequest: request,
+		Account:    config.Account{},
+		UserSyncs:  &emptyUsersync{},
+		StoredAuctionResponses: stored_responses.ImpsWithBidResponses{
+			"some-impression-id": json.RawMessage(`[{"seat":"stored-seat","bid":[{"id":"stored-bid","impid":"other","price":1.5,"adm":"<div></div>"}]}]`),
+		},
+	}
+
+	response, err := e.HoldAuction(context.Background(), auctionRequest, &DebugLog{})
+
+	if !assert.NoError(t, err) {
+		return
+	}
+	mockBidder.AssertNotCalled(t, "MakeRequests", mock.Anything, mock.Anything)
+	if assert.Len(t, response.SeatBid, 1) && assert.Len(t, response.SeatBid[0].Bid, 1) {
+		assert.Equal(t, "stored-seat", response.SeatBid[0].Seat)
+		assert.Equal(t, "stored-bid", response.SeatBid[0].Bid[0].ID)
+		assert.Equal(t, "some-impression-id", response.SeatBid[0].Bid[0].ImpID)
+		assert.Equal(t, 1.5, response.SeatBid[0].Bid[0].Price)
+	}
+}
+
+func TestHoldAuctionRejectedBids(t *testing.T) {
+	e := exchange{
+		cache:             &wellBehavedCache{},
+		me:                &metricsConf.DummyMetricsEngine{},
+		gDPR:              gdpr.AlwaysAllow{},
+		currencyConverter: currency.NewRateConverter(&http.Client{}, "", time.Duration(0)),
+		categoriesFetcher: nilCategoryFetcher{},
+		bidIDGenerator:    &mockBidIDGenerator{false, false},
+		floorsEnabled:     true,
+	}
+	request := &openrtb2.BidRequest{
+		ID: "some-request-id",
+		Imp: []openrtb2.Imp{{
+			ID:     "some-impression-id",
+			Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 300, H: 250}}},
+			Ext:    json.RawMessage(`{"appnexus": {"placementId": 1}}`),
+		}},
+		Site: &openrtb2.Site{Page: "prebid.org"},
+		Ext:  json.RawMessage(`{"prebid":{"returnallbidstatus":true,"targeting":{"includewinners":true},"floors":{"floormin":2,"floormincur":"USD"}}}`),
+	}
+	auctionRequest := AuctionRequest{
+		BidRequest: request,
+		Account:    config.Account{PriceFloors: config.AccountPriceFloors{Enabled: true, EnforceFloorsRate: 100}},
+		UserSyncs:  &emptyUsersync{},
+		StoredAuctionResponses: stored_responses.ImpsWithBidResponses{
+			"some-impression-id": json.RawMessage(`[{"seat":"appnexus","bid":[{"id":"low-bid","price":1.5,"crid":"1","adm":"<div></div>"},{"id":"high-bid","price":2.5,"crid":"2","adm":"<div></div>"}]}]`),
+		},
+	}
+	debugLog := &DebugLog{Enabled: true, DebugEnabledOrOverridden: true}
+
+	response, err := e.HoldAuction(context.Background(), auctionRequest, debugLog)
+
+	if !assert.NoError(t, err) {
+		return
+	}
+	var responseExt openrtb_ext.ExtBidResponse
+	if !assert.NoError(t, json.Unmarshal(response.Ext, &responseExt)) {
+		return
+	}
+	expectedSeatNonBids := []openrtb_ext.SeatNonBid{{
+		Seat: "appnexus",
+		NonBid: []openrtb_ext.NonBid{{
+			ImpID:      "some-impression-id",
+			StatusCode: openrtb_ext.RejectionReasonBelowFloor,
+		}},
+	}}
+	expectedSeatNonBids[0].NonBid[0].Ext.Prebid.Reason = "Bid price 1.5000 USD is below the floor 2.0000 USD for imp ID some-impression-id"
+	expectedSeatNonBids[0].NonBid[0].Ext.Prebid.Bid = openrtb_ext.NonBidExtPrebidBid{ID: "low-bid", Price: 1.5}
+	assert.Equal(t, expectedSeatNonBids, responseExt.SeatNonBid, "ext.seatnonbid")
+	assert.Equal(t, []openrtb_ext.ExtBidderMessage{{
+		Code:    errortypes.UnknownErrorCode,
+		Message: "bid rejected [bid ID: low-bid] reason: Bid price 1.5000 USD is below the floor 2.0000 USD for imp ID some-impression-id",
+	}}, responseExt.Errors[openrtb_ext.PrebidExtKey], "The rejection should be reported once")
+
+	var debugLogExt openrtb_ext.ExtBidResponse
+	if assert.NoError(t, json.Unmarshal([]byte(debugLog.Data.Response), &debugLogExt)) {
+		assert.Equal(t, expectedSeatNonBids, debugLogExt.SeatNonBid, "Debug log ext.seatnonbid")
+	}
+}
+
+func TestBuildStoredAuctionResponse(t *testing.T) {
+	request := &openrtb2.BidRequest{
+		Imp: []openrtb2.Imp{
+			{ID: "imp-1", Banner: &openrtb2.Banner{}},
+			{ID: "imp-2", Video: &openrtb2.Video{}},
+		},
+	}
+
+	testCases := []struct {
+		description          string
+		storedResponses      stored_responses.ImpsWithBidResponses
+		expectedBidTypes     map[string]openrtb_ext.BidType
+		expectedLiveAdapters []openrtb_ext.BidderName
+		expectedErr          string
+	}{
+		{
+			description: "Bid types from the imps",
+			storedResponses: stored_responses.ImpsWithBidResponses{
+				"imp-1": json.RawMessage(`[{"seat":"appnexus","bid":[{"id":"bid-1"}]}]`),
+				"imp-2": json.RawMessage(`[{"seat":"rubicon","bid":[{"id":"bid-2"}]},{"seat":"appnexus","bid":[{"id":"bid-3"}]}]`),
+			},
+			expectedBidTypes:     map[string]openrtb_ext.BidType{"bid-1": openrtb_ext.BidTypeBanner, "bid-2": openrtb_ext.BidTypeVideo, "bid-3": openrtb_ext.BidTypeVideo},
+			expectedLiveAdapters: []openrtb_ext.BidderName{"appnexus", "rubicon"},
+		},
+		{
+			description: "Bid type from the bid",
+			storedResponses: stored_responses.ImpsWithBidResponses{
+				"imp-1": json.RawMessage(`[{"seat":"appnexus","bid":[{"id":"bid-1","ext":{"prebid":{"type":"native"}}}]}]`),
+			},
+			expectedBidTypes:     map[string]openrtb_ext.BidType{"bid-1": openrtb_ext.BidTypeNative},
+			expectedLiveAdapters: []openrtb_ext.BidderName{"appnexus"},
+		},
+		{
+			description: "Invalid bid type",
+			storedResponses: stored_responses.ImpsWithBidResponses{
+				"imp-1": json.RawMessage(`[{"seat":"appnexus","bid":[{"id":"bid-1","ext":{"prebid":{"type":"popup"}}}]}]`),
+			},
+			expectedErr: "Error decoding the stored auction response of imp imp-1: invalid BidType: popup",
+		},
+		{
+			description: "Malformed stored response",
+			storedResponses: stored_responses.ImpsWithBidResponses{
+				"imp-1": json.RawMessage(`{"seat":"appnexus"}`),
+			},
+			expectedErr: "Error decoding the stored auction response of imp imp-1: json: cannot unmarshal object into Go value of type []openrtb2.SeatBid",
+		},
+	}
+
+	for _, test := range testCases {
+		adapterBids, adapterExtra, liveAdapters, err := buildStoredAuctionResponse(request, test.storedResponses)
+
+		if test.expectedErr != "" {
+			assert.EqualError(t, err, test.expectedErr, test.description)
+			continue
+		}
+		if !assert.NoError(t, err, test.description) {
+			continue
+		}
+		bidTypes := make(map[string]openrtb_ext.BidType)
+		for _, seatBid := range adapterBids {
+			for _, bid := range seatBid.bids {
+				bidTypes[bid.bid.ID] = bid.bidType
+			}
+		}
+		assert.Equal(t, test.expectedBidTypes, bidTypes, test.description+":bid_types")
+		assert.Equal(t, test.expectedLiveAdapters, liveAdapters, test.description+":live_adapters")
+		assert.Len(t, adapterExtra, len(test.expectedLiveAdapters), test.description+":adapter_extra")
+	}
+}
+
+func TestMakeSeatDetails(t *testing.T) {
+	appnexusRequest := &openrtb2.BidRequest{ID: "appnexus-request"}
+	rubiconRequest := &openrtb2.BidRequest{ID: "rubicon-request"}
+	winningBid := &openrtb2.Bid{ID: "winning-bid", ImpID: "imp", Price: 2}
+	losingBid := &openrtb2.Bid{ID: "losing-bid", ImpID: "imp", Price: 1}
+	rejectedBid := &openrtb2.Bid{ID: "rejected-bid", ImpID: "imp"}
+
+	bidderRequests := []BidderRequest{
+		{BidRequest: appnexusRequest, BidderName: openrtb_ext.BidderAppnexus},
+		{BidRequest: rubiconRequest, BidderName: openrtb_ext.BidderRubicon},
+	}
+	receivedSeatBids := map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+		openrtb_ext.BidderAppnexus: {
+			bids: []*pbsOrtbBid{
+				{bid: winningBid, bidTargets: map[string]string{"hb_pb": "2.00"}},
+				{bid: losingBid},
+			},
+			httpCallDetails: []*analytics.HttpCallDetails{{Uri: "https://appnexus.com", Status: 200, Latency: time.Millisecond}},
+			receivedBids:    []*openrtb2.Bid{winningBid, losingBid, rejectedBid},
+			rejectedBids:    []*analytics.RejectedBid{{Bid: rejectedBid, Reason: openrtb_ext.RejectionReasonInvalidBid, Message: "Bid does not contain a positive 'price'"}},
+		},
+	}
+	adapterExtra := map[openrtb_ext.BidderName]*seatResponseExtra{
+		openrtb_ext.BidderAppnexus: {ResponseTimeMillis: 10},
+		openrtb_ext.BidderRubicon:  {ResponseTimeMillis: 20},
+	}
+
+	expected := []*analytics.SeatDetails{
+		{
+			Bidder:       openrtb_ext.BidderAppnexus,
+			Request:      appnexusRequest,
+			HttpCalls:    []*analytics.HttpCallDetails{{Uri: "https://appnexus.com", Status: 200, Latency: time.Millisecond}},
+			Bids:         []*openrtb2.Bid{winningBid, losingBid, rejectedBid},
+			RejectedBids: []*analytics.RejectedBid{{Bid: rejectedBid, Reason: openrtb_ext.RejectionReasonInvalidBid, Message: "Bid does not contain a positive 'price'"}},
+			Targeting: map[string]map[string]string{
+				"winning-bid": {"hb_pb": "2.00"},
+			},
+			ResponseTimeMillis: 10,
+		},
+		{
+			Bidder:             openrtb_ext.BidderRubicon,
+			Request:            rubiconRequest,
+			ResponseTimeMillis: 20,
+		},
+	}
+
+	assert.Equal(t, expected, makeSeatDetails(bidderRequests, receivedSeatBids, adapterExtra))
+}
+
+func TestMakeSeatNonBids(t *testing.T) {
+	receivedSeatBids := map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+		openrtb_ext.BidderRubicon: {
+			rejectedBids: []*analytics.RejectedBid{
+				{Bid: &openrtb2.Bid{ID: "low", ImpID: "imp", Price: 0.5}, Reason: openrtb_ext.RejectionReasonBelowFloor, Message: "Bid price 0.5000 USD is below the floor 1.0000 USD for imp ID imp"},
+			},
+		},
+		openrtb_ext.BidderAppnexus: {
+			rejectedBids: []*analytics.RejectedBid{
+				{Reason: openrtb_ext.RejectionReasonInvalidBid, Message: "Empty bid object submitted."},
+			},
+		},
+		openrtb_ext.BidderPubmatic: {
+			bids: []*pbsOrtbBid{{bid: &openrtb2.Bid{ID: "kept", ImpID: "imp", Price: 1}}},
+		},
+	}
+
+	seatNonBids := makeSeatNonBids(receivedSeatBids)
+
+	seatNonBidsJSON, err := json.Marshal(seatNonBids)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"seat":"appnexus","nonbid":[{"impid":"","statuscode":350,"ext":{"prebid":{"bid":{"id":"","price":0},"reason":"Empty bid object submitted."}}}]},
+		{"seat":"rubicon","nonbid":[{"impid":"imp","statuscode":301,"ext":{"prebid":{"bid":{"id":"low","price":0.5},"reason":"Bid price 0.5000 USD is below the floor 1.0000 USD for imp ID imp"}}}]}
+	]`, string(seatNonBidsJSON))
+}
+
+func TestRecordRejectedBids(t *testing.T) {
+	me := &metrics.MetricsEngineMock{}
+	me.On("RecordAdapterRejectedBid", openrtb_ext.BidderAppnexus, openrtb_ext.RejectionReasonDuplicate).Return()
+	e := exchange{me: me}
+
+	bidderRequests := []BidderRequest{
+		{BidderName: "appnexusAlias", BidderCoreName: openrtb_ext.BidderAppnexus},
+		{BidderName: openrtb_ext.BidderRubicon, BidderCoreName: openrtb_ext.BidderRubicon},
+	}
+	receivedSeatBids := map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+		"appnexusAlias": {
+			rejectedBids: []*analytics.RejectedBid{
+				{Bid: &openrtb2.Bid{ID: "one"}, Reason: openrtb_ext.RejectionReasonDuplicate},
+				{Bid: &openrtb2.Bid{ID: "two"}, Reason: openrtb_ext.RejectionReasonDuplicate},
+			},
+		},
+	}
+
+	e.recordRejectedBids(bidderRequests, receivedSeatBids)
+
+	me.AssertNumberOfCalls(t, "RecordAdapterRejectedBid", 2)
+}
+
 func TestGetAuctionCurrencyRates(t *testing.T) {
 
 	pbsRates := map[string]map[string]float64{
@@ -1203,7 +1481,7 @@ func TestGetBidCacheInfoEndToEnd(t *testing.T) {
 	}
 	currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
 	pbc := pbc.NewClient(&http.Client{}, &cfg.CacheURL, &cfg.ExtCacheURL, testEngine)
-	e := NewExchange(adapters, pbc, cfg, &metricsConf.DummyMetricsEngine{}, biddersInfo, gdpr.AlwaysAllow{}, currencyConverter, nilCategoryFetcher{}).(*exchange)
+	e := NewExchange(adapters, pbc, cfg, &metricsConf.DummyMetricsEngine{}, biddersInfo, gdpr.AlwaysAllow{}, currencyConverter, nilCategoryFetcher{}, floors.EmptyFetcher{}).(*exchange)
 	// 	3) Build all the parameters e.buildBidResponse(ctx.Background(), liveA... ) needs
 	liveAdapters := []openrtb_ext.BidderName{bidderName}
 
@@ -1557,7 +1835,7 @@ func TestBidResponseCurrency(t *testing.T) {
 	}
 
 	currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
-	e := NewExchange(adapters, nil, cfg, &metricsConf.DummyMetricsEngine{}, biddersInfo, gdpr.AlwaysAllow{}, currencyConverter, nilCategoryFetcher{}).(*exchange)
+	e := NewExchange(adapters, nil, cfg, &metricsConf.DummyMetricsEngine{}, biddersInfo, gdpr.AlwaysAllow{}, currencyConverter, nilCategoryFetcher{}, floors.EmptyFetcher{}).(*exchange)
 
 	liveAdapters := make([]openrtb_ext.BidderName, 1)
 	liveAdapters[0] = "appnexus"
@@ -1743,7 +2021,7 @@ func TestRaceIntegration(t *testing.T) {
 	}
 
 	debugLog := DebugLog{}
-	ex := NewExchange(adapters, &wellBehavedCache{}, cfg, &metricsConf.DummyMetricsEngine{}, biddersInfo, gdpr.AlwaysAllow{}, currencyConverter, &nilCategoryFetcher{}).(*exchange)
+	ex := NewExchange(adapters, &wellBehavedCache{}, cfg, &metricsConf.DummyMetricsEngine{}, biddersInfo, gdpr.AlwaysAllow{}, currencyConverter, &nilCategoryFetcher{}, floors.EmptyFetcher{}).(*exchange)
 	_, err = ex.HoldAuction(context.Background(), auctionRequest, &debugLog)
 	if err != nil {
 		t.Errorf("HoldAuction returned unexpected error: %v", err)
@@ -1837,7 +2115,7 @@ func TestPanicRecovery(t *testing.T) {
 	}
 
 	currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
-	e := NewExchange(adapters, nil, cfg, &metricsConf.DummyMetricsEngine{}, biddersInfo, gdpr.AlwaysAllow{}, currencyConverter, nilCategoryFetcher{}).(*exchange)
+	e := NewExchange(adapters, nil, cfg, &metricsConf.DummyMetricsEngine{}, biddersInfo, gdpr.AlwaysAllow{}, currencyConverter, nilCategoryFetcher{}, floors.EmptyFetcher{}).(*exchange)
 
 	chBids := make(chan *bidResponseWrapper, 1)
 	panicker := func(bidderRequest BidderRequest, conversions currency.Conversions) {
@@ -1875,6 +2153,153 @@ func TestPanicRecovery(t *testing.T) {
 	recovered(bidderRequests[0], nil)
 }
 
+func TestMakeSoftTimeoutContext(t *testing.T) {
+	start := time.Now()
+	hardCtx, hardCancel := context.WithDeadline(context.Background(), start.Add(time.Second))
+	defer hardCancel()
+
+	testCases := []struct {
+		description          string
+		ctx                  context.Context
+		auctionTimeoutMillis int64
+		expectedDeadline     time.Time
+		expectedHasDeadline  bool
+		expectedCause        deadlineCause
+	}{
+		{
+			description:         "No auction timeout",
+			ctx:                 hardCtx,
+			expectedDeadline:    start.Add(time.Second),
+			expectedHasDeadline: true,
+			expectedCause:       deadlineAuction,
+		},
+		{
+			description:          "Auction timeout before the hard deadline",
+			ctx:                  hardCtx,
+			auctionTimeoutMillis: 300,
+			expectedDeadline:     start.Add(300 * time.Millisecond),
+			expectedHasDeadline:  true,
+			expectedCause:        deadlineSoftTimeout,
+		},
+		{
+			description:          "Auction timeout after the hard deadline",
+			ctx:                  hardCtx,
+			auctionTimeoutMillis: 2000,
+			expectedDeadline:     start.Add(time.Second),
+			expectedHasDeadline:  true,
+			expectedCause:        deadlineAuction,
+		},
+		{
+			description:          "Auction timeout without hard deadline",
+			ctx:                  context.Background(),
+			auctionTimeoutMillis: 300,
+			expectedDeadline:     start.Add(300 * time.Millisecond),
+			expectedHasDeadline:  true,
+			expectedCause:        deadlineSoftTimeout,
+		},
+	}
+
+	for _, test := range testCases {
+		ctx, cancel := makeSoftTimeoutContext(test.ctx, start, test.auctionTimeoutMillis)
+		deadline, hasDeadline := ctx.Deadline()
+		cancel()
+		assert.Equal(t, test.expectedHasDeadline, hasDeadline, test.description)
+		assert.Equal(t, test.expectedCause, getDeadlineCause(ctx), test.description)
+		assert.True(t, test.expectedDeadline.Equal(deadline), test.description)
+	}
+}
+
+func TestHoldAuctionSoftTimeout(t *testing.T) {
+	fastServer := httptest.NewServer(mockHandler(200, "getBody", "{}"))
+	defer fastServer.Close()
+	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+		w.WriteHeader(200)
+	}))
+	defer slowServer.Close()
+
+	fastBidder := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{
+			Method: "POST",
+			Uri:    fastServer.URL,
+		},
+		bidResponse: &adapters.BidderResponse{
+			Bids: []*adapters.TypedBid{{
+				Bid:     &openrtb2.Bid{ID: "fast-bid", ImpID: "some-impression-id", Price: 1, CrID: "1"},
+				BidType: openrtb_ext.BidTypeBanner,
+			}},
+		},
+	}
+	slowBidder := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{
+			Method: "POST",
+			Uri:    slowServer.URL,
+		},
+		bidResponse: &adapters.BidderResponse{
+			Bids: []*adapters.TypedBid{{
+				Bid:     &openrtb2.Bid{ID: "slow-bid", ImpID: "some-impression-id", Price: 2, CrID: "2"},
+				BidType: openrtb_ext.BidTypeBanner,
+			}},
+		},
+	}
+
+	e := exchange{
+		adapterMap: map[openrtb_ext.BidderName]adaptedBidder{
+			openrtb_ext.BidderAppnexus: adaptBidder(fastBidder, fastServer.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil),
+			openrtb_ext.BidderRubicon:  adaptBidder(slowBidder, slowServer.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderRubicon, nil),
+		},
+		cache:             &wellBehavedCache{},
+		me:                &metricsConf.DummyMetricsEngine{},
+		gDPR:              gdpr.AlwaysAllow{},
+		currencyConverter: currency.NewRateConverter(&http.Client{}, "", time.Duration(0)),
+		categoriesFetcher: nilCategoryFetcher{},
+		bidIDGenerator:    &mockBidIDGenerator{false, false},
+	}
+	request := &openrtb2.BidRequest{
+		ID: "some-request-id",
+		Imp: []openrtb2.Imp{{
+			ID:     "some-impression-id",
+			Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 300, H: 250}}},
+			Ext:    json.RawMessage(`{"appnexus": {"placementId": 1}, "rubicon": {"accountId": 1, "siteId": 2, "zoneId": 3}}`),
+		}},
+		Site: &openrtb2.Site{Page: "prebid.org"},
+		TMax: 2000,
+		Ext:  json.RawMessage(`{"prebid":{"auctiontimeout":100}}`),
+	}
+	auctionRequest := AuctionRequest{
+		BidRequest: request,
+		Account:    config.Account{},
+		UserSyncs:  &emptyUsersync{},
+		StartTime:  time.Now(),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	response, err := e.HoldAuction(ctx, auctionRequest, &DebugLog{})
+	elapsed := time.Since(auctionRequest.StartTime)
+
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Less(t, int64(elapsed), int64(time.Second), "The auction should not wait for the slow bidder")
+	if assert.Len(t, response.SeatBid, 1, "Only the fast bidder should bid") {
+		assert.Equal(t, "appnexus", response.SeatBid[0].Seat)
+		if assert.Len(t, response.SeatBid[0].Bid, 1) {
+			assert.Equal(t, "fast-bid", response.SeatBid[0].Bid[0].ID)
+		}
+	}
+	var responseExt openrtb_ext.ExtBidResponse
+	if assert.NoError(t, json.Unmarshal(response.Ext, &responseExt)) {
+		if assert.Len(t, responseExt.Errors[openrtb_ext.BidderRubicon], 1, "The slow bidder should time out") {
+			assert.Equal(t, errortypes.TimeoutErrorCode, responseExt.Errors[openrtb_ext.BidderRubicon][0].Code)
+		}
+		assert.Empty(t, responseExt.Errors[openrtb_ext.BidderAppnexus])
+	}
+}
+
 func buildImpExt(t *testing.T, jsonFilename string) json.RawMessage {
 	adapterFolders, err := ioutil.ReadDir("../adapters")
 	if err != nil {
@@ -1933,7 +2358,7 @@ func TestPanicRecoveryHighLevel(t *testing.T) {
 		t.Errorf("Failed to create a category Fetcher: %v", error)
 	}
 
-	e := NewExchange(adapters, &mockCache{}, cfg, &metricsConf.DummyMetricsEngine{}, biddersInfo, gdpr.AlwaysAllow{}, currencyConverter, categoriesFetcher).(*exchange)
+	e := NewExchange(adapters, &mockCache{}, cfg, &metricsConf.DummyMetricsEngine{}, biddersInfo, gdpr.AlwaysAllow{}, currencyConverter, categoriesFetcher, floors.EmptyFetcher{}).(*exchange)
 
 	e.adapterMap[openrtb_ext.BidderBeachfront] = panicingAdapter{}
 	e.adapterMap[openrtb_ext.BidderAppnexus] = panicingAdapter{}
@@ -2095,7 +2520,7 @@ func runSpec(t *testing.T, filename string, spec *exchangeSpec) {
 	if spec.BidIDGenerator != nil {
 		*bidIdGenerator = *spec.BidIDGenerator
 	}
-	ex := newExchangeForTests(t, filename, spec.OutgoingRequests, aliases, privacyConfig, bidIdGenerator)
+	ex := newExchangeForTests(t, filename, spec.OutgoingRequests, aliases, privacyConfig, bidIdGenerator, spec.FloorsEnabled)
 	biddersInAuction := findBiddersInAuction(t, filename, &spec.IncomingRequest.OrtbRequest)
 	debugLog := &DebugLog{}
 	if spec.DebugLog != nil {
@@ -2109,6 +2534,10 @@ func runSpec(t *testing.T, filename string, spec *exchangeSpec) {
 			ID:            "testaccount",
 			EventsEnabled: spec.EventsEnabled,
 			DebugAllow:    true,
+			PriceFloors: config.AccountPriceFloors{
+				Enabled:           spec.FloorsEnabled,
+				EnforceFloorsRate: 100,
+			},
 		},
 		UserSyncs: mockIdFetcher(spec.IncomingRequest.Usersyncs),
 	}
@@ -2191,7 +2620,7 @@ func extractResponseTimes(t *testing.T, context string, bid *openrtb2.BidRespons
 	}
 }
 
-func newExchangeForTests(t *testing.T, filename string, expectations map[string]*bidderSpec, aliases map[string]string, privacyConfig config.Privacy, bidIDGenerator BidIDGenerator) Exchange {
+func newExchangeForTests(t *testing.T, filename string, expectations map[string]*bidderSpec, aliases map[string]string, privacyConfig config.Privacy, bidIDGenerator BidIDGenerator, floorsEnabled bool) Exchange {
 	bidderAdapters := make(map[openrtb_ext.BidderName]adaptedBidder, len(expectations))
 	bidderInfos := make(config.BidderInfos, len(expectations))
 	for _, bidderName := range openrtb_ext.CoreBidderNames() {
@@ -2247,6 +2676,8 @@ func newExchangeForTests(t *testing.T, filename string, expectations map[string]
 		bidderInfo:        bidderInfos,
 		externalURL:       "http://localhost",
 		bidIDGenerator:    bidIDGenerator,
+		floorsEnabled:     floorsEnabled,
+		floorsFetcher:     floors.EmptyFetcher{},
 	}
 }
 
@@ -2359,10 +2790,10 @@ func TestCategoryMapping(t *testing.T) {
 	bid3 := openrtb2.Bid{ID: "bid_id3", ImpID: "imp_id3", Price: 30.0000, Cat: cats3, W: 1, H: 1}
 	bid4 := openrtb2.Bid{ID: "bid_id4", ImpID: "imp_id4", Price: 40.0000, Cat: cats4, W: 1, H: 1}
 
-	bid1_1 := pbsOrtbBid{&bid1, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
-	bid1_2 := pbsOrtbBid{&bid2, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 40}, nil, 0, false, ""}
-	bid1_3 := pbsOrtbBid{&bid3, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30, PrimaryCategory: "AdapterOverride"}, nil, 0, false, ""}
-	bid1_4 := pbsOrtbBid{&bid4, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
+	bid1_1 := pbsOrtbBid{&bid1, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", nil, ""}
+	bid1_2 := pbsOrtbBid{&bid2, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 40}, nil, 0, false, "", nil, ""}
+	bid1_3 := pbsOrtbBid{&bid3, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30, PrimaryCategory: "AdapterOverride"}, nil, 0, false, "", nil, ""}
+	bid1_4 := pbsOrtbBid{&bid4, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", nil, ""}
 
 	innerBids := []*pbsOrtbBid{
 		&bid1_1,
@@ -2376,7 +2807,8 @@ func TestCategoryMapping(t *testing.T) {
 
 	adapterBids[bidderName1] = &seatBid
 
-	bidCategory, adapterBids, rejections, err := applyCategoryMapping(nil, &requestExt, adapterBids, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
+	bidCategory, adapterBids, err := applyCategoryMapping(nil, &requestExt, adapterBids, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
+	rejections := getRejectionMessages(adapterBids)
 
 	assert.Equal(t, nil, err, "Category mapping error should be empty")
 	assert.Equal(t, 1, len(rejections), "There should be 1 bid rejection message")
@@ -2414,10 +2846,10 @@ func TestCategoryMappingNoIncludeBrandCategory(t *testing.T) {
 	bid3 := openrtb2.Bid{ID: "bid_id3", ImpID: "imp_id3", Price: 30.0000, Cat: cats3, W: 1, H: 1}
 	bid4 := openrtb2.Bid{ID: "bid_id4", ImpID: "imp_id4", Price: 40.0000, Cat: cats4, W: 1, H: 1}
 
-	bid1_1 := pbsOrtbBid{&bid1, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
-	bid1_2 := pbsOrtbBid{&bid2, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 40}, nil, 0, false, ""}
-	bid1_3 := pbsOrtbBid{&bid3, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30, PrimaryCategory: "AdapterOverride"}, nil, 0, false, ""}
-	bid1_4 := pbsOrtbBid{&bid4, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 50}, nil, 0, false, ""}
+	bid1_1 := pbsOrtbBid{&bid1, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", nil, ""}
+	bid1_2 := pbsOrtbBid{&bid2, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 40}, nil, 0, false, "", nil, ""}
+	bid1_3 := pbsOrtbBid{&bid3, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30, PrimaryCategory: "AdapterOverride"}, nil, 0, false, "", nil, ""}
+	bid1_4 := pbsOrtbBid{&bid4, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 50}, nil, 0, false, "", nil, ""}
 
 	innerBids := []*pbsOrtbBid{
 		&bid1_1,
@@ -2431,7 +2863,8 @@ func TestCategoryMappingNoIncludeBrandCategory(t *testing.T) {
 
 	adapterBids[bidderName1] = &seatBid
 
-	bidCategory, adapterBids, rejections, err := applyCategoryMapping(nil, &requestExt, adapterBids, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
+	bidCategory, adapterBids, err := applyCategoryMapping(nil, &requestExt, adapterBids, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
+	rejections := getRejectionMessages(adapterBids)
 
 	assert.Equal(t, nil, err, "Category mapping error should be empty")
 	assert.Empty(t, rejections, "There should be no bid rejection messages")
@@ -2468,9 +2901,9 @@ func TestCategoryMappingTranslateCategoriesNil(t *testing.T) {
 	bid2 := openrtb2.Bid{ID: "bid_id2", ImpID: "imp_id2", Price: 20.0000, Cat: cats2, W: 1, H: 1}
 	bid3 := openrtb2.Bid{ID: "bid_id3", ImpID: "imp_id3", Price: 30.0000, Cat: cats3, W: 1, H: 1}
 
-	bid1_1 := pbsOrtbBid{&bid1, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
-	bid1_2 := pbsOrtbBid{&bid2, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 40}, nil, 0, false, ""}
-	bid1_3 := pbsOrtbBid{&bid3, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
+	bid1_1 := pbsOrtbBid{&bid1, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", nil, ""}
+	bid1_2 := pbsOrtbBid{&bid2, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 40}, nil, 0, false, "", nil, ""}
+	bid1_3 := pbsOrtbBid{&bid3, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", nil, ""}
 
 	innerBids := []*pbsOrtbBid{
 		&bid1_1,
@@ -2483,7 +2916,8 @@ func TestCategoryMappingTranslateCategoriesNil(t *testing.T) {
 
 	adapterBids[bidderName1] = &seatBid
 
-	bidCategory, adapterBids, rejections, err := applyCategoryMapping(nil, &requestExt, adapterBids, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
+	bidCategory, adapterBids, err := applyCategoryMapping(nil, &requestExt, adapterBids, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
+	rejections := getRejectionMessages(adapterBids)
 
 	assert.Equal(t, nil, err, "Category mapping error should be empty")
 	assert.Equal(t, 1, len(rejections), "There should be 1 bid rejection message")
@@ -2550,9 +2984,9 @@ func TestCategoryMappingTranslateCategoriesFalse(t *testing.T) {
 	bid2 := openrtb2.Bid{ID: "bid_id2", ImpID: "imp_id2", Price: 20.0000, Cat: cats2, W: 1, H: 1}
 	bid3 := openrtb2.Bid{ID: "bid_id3", ImpID: "imp_id3", Price: 30.0000, Cat: cats3, W: 1, H: 1}
 
-	bid1_1 := pbsOrtbBid{&bid1, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
-	bid1_2 := pbsOrtbBid{&bid2, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 40}, nil, 0, false, ""}
-	bid1_3 := pbsOrtbBid{&bid3, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
+	bid1_1 := pbsOrtbBid{&bid1, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", nil, ""}
+	bid1_2 := pbsOrtbBid{&bid2, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 40}, nil, 0, false, "", nil, ""}
+	bid1_3 := pbsOrtbBid{&bid3, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", nil, ""}
 
 	innerBids := []*pbsOrtbBid{
 		&bid1_1,
@@ -2565,7 +2999,8 @@ func TestCategoryMappingTranslateCategoriesFalse(t *testing.T) {
 
 	adapterBids[bidderName1] = &seatBid
 
-	bidCategory, adapterBids, rejections, err := applyCategoryMapping(nil, &requestExt, adapterBids, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
+	bidCategory, adapterBids, err := applyCategoryMapping(nil, &requestExt, adapterBids, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
+	rejections := getRejectionMessages(adapterBids)
 
 	assert.Equal(t, nil, err, "Category mapping error should be empty")
 	assert.Empty(t, rejections, "There should be no bid rejection messages")
@@ -2602,11 +3037,11 @@ func TestCategoryDedupe(t *testing.T) {
 	bid4 := openrtb2.Bid{ID: "bid_id4", ImpID: "imp_id4", Price: 20.0000, Cat: cats4, W: 1, H: 1}
 	bid5 := openrtb2.Bid{ID: "bid_id5", ImpID: "imp_id5", Price: 20.0000, Cat: cats1, W: 1, H: 1}
 
-	bid1_1 := pbsOrtbBid{&bid1, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
-	bid1_2 := pbsOrtbBid{&bid2, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 50}, nil, 0, false, ""}
-	bid1_3 := pbsOrtbBid{&bid3, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
-	bid1_4 := pbsOrtbBid{&bid4, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
-	bid1_5 := pbsOrtbBid{&bid5, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
+	bid1_1 := pbsOrtbBid{&bid1, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", nil, ""}
+	bid1_2 := pbsOrtbBid{&bid2, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 50}, nil, 0, false, "", nil, ""}
+	bid1_3 := pbsOrtbBid{&bid3, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", nil, ""}
+	bid1_4 := pbsOrtbBid{&bid4, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", nil, ""}
+	bid1_5 := pbsOrtbBid{&bid5, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", nil, ""}
 
 	selectedBids := make(map[string]int)
 	expectedCategories := map[string]string{
@@ -2635,7 +3070,8 @@ func TestCategoryDedupe(t *testing.T) {
 
 		adapterBids[bidderName1] = &seatBid
 
-		bidCategory, adapterBids, rejections, err := applyCategoryMapping(nil, &requestExt, adapterBids, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
+		bidCategory, adapterBids, err := applyCategoryMapping(nil, &requestExt, adapterBids, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
+		rejections := getRejectionMessages(adapterBids)
 
 		assert.Equal(t, nil, err, "Category mapping error should be empty")
 		assert.Equal(t, 3, len(rejections), "There should be 2 bid rejection messages")
@@ -2681,11 +3117,11 @@ func TestNoCategoryDedupe(t *testing.T) {
 	bid4 := openrtb2.Bid{ID: "bid_id4", ImpID: "imp_id4", Price: 20.0000, Cat: cats4, W: 1, H: 1}
 	bid5 := openrtb2.Bid{ID: "bid_id5", ImpID: "imp_id5", Price: 10.0000, Cat: cats1, W: 1, H: 1}
 
-	bid1_1 := pbsOrtbBid{&bid1, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
-	bid1_2 := pbsOrtbBid{&bid2, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
-	bid1_3 := pbsOrtbBid{&bid3, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
-	bid1_4 := pbsOrtbBid{&bid4, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
-	bid1_5 := pbsOrtbBid{&bid5, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
+	bid1_1 := pbsOrtbBid{&bid1, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", nil, ""}
+	bid1_2 := pbsOrtbBid{&bid2, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", nil, ""}
+	bid1_3 := pbsOrtbBid{&bid3, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", nil, ""}
+	bid1_4 := pbsOrtbBid{&bid4, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", nil, ""}
+	bid1_5 := pbsOrtbBid{&bid5, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", nil, ""}
 
 	selectedBids := make(map[string]int)
 	expectedCategories := map[string]string{
@@ -2715,7 +3151,8 @@ func TestNoCategoryDedupe(t *testing.T) {
 
 		adapterBids[bidderName1] = &seatBid
 
-		bidCategory, adapterBids, rejections, err := applyCategoryMapping(nil, &requestExt, adapterBids, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
+		bidCategory, adapterBids, err := applyCategoryMapping(nil, &requestExt, adapterBids, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
+		rejections := getRejectionMessages(adapterBids)
 
 		assert.Equal(t, nil, err, "Category mapping error should be empty")
 		assert.Equal(t, 2, len(rejections), "There should be 2 bid rejection messages")
@@ -2761,8 +3198,8 @@ func TestCategoryMappingBidderName(t *testing.T) {
 	bid1 := openrtb2.Bid{ID: "bid_id1", ImpID: "imp_id1", Price: 10.0000, Cat: cats1, W: 1, H: 1}
 	bid2 := openrtb2.Bid{ID: "bid_id2", ImpID: "imp_id2", Price: 10.0000, Cat: cats2, W: 1, H: 1}
 
-	bid1_1 := pbsOrtbBid{&bid1, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
-	bid1_2 := pbsOrtbBid{&bid2, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
+	bid1_1 := pbsOrtbBid{&bid1, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", nil, ""}
+	bid1_2 := pbsOrtbBid{&bid2, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", nil, ""}
 
 	innerBids1 := []*pbsOrtbBid{
 		&bid1_1,
@@ -2780,7 +3217,8 @@ func TestCategoryMappingBidderName(t *testing.T) {
 	adapterBids[bidderName1] = &seatBid1
 	adapterBids[bidderName2] = &seatBid2
 
-	bidCategory, adapterBids, rejections, err := applyCategoryMapping(nil, &requestExt, adapterBids, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
+	bidCategory, adapterBids, err := applyCategoryMapping(nil, &requestExt, adapterBids, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
+	rejections := getRejectionMessages(adapterBids)
 
 	assert.NoError(t, err, "Category mapping error should be empty")
 	assert.Empty(t, rejections, "There should be 0 bid rejection messages")
@@ -2815,8 +3253,8 @@ func TestCategoryMappingBidderNameNoCategories(t *testing.T) {
 	bid1 := openrtb2.Bid{ID: "bid_id1", ImpID: "imp_id1", Price: 10.0000, Cat: cats1, W: 1, H: 1}
 	bid2 := openrtb2.Bid{ID: "bid_id2", ImpID: "imp_id2", Price: 12.0000, Cat: cats2, W: 1, H: 1}
 
-	bid1_1 := pbsOrtbBid{&bid1, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
-	bid1_2 := pbsOrtbBid{&bid2, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
+	bid1_1 := pbsOrtbBid{&bid1, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", nil, ""}
+	bid1_2 := pbsOrtbBid{&bid2, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", nil, ""}
 
 	innerBids1 := []*pbsOrtbBid{
 		&bid1_1,
@@ -2834,7 +3272,8 @@ func TestCategoryMappingBidderNameNoCategories(t *testing.T) {
 	adapterBids[bidderName1] = &seatBid1
 	adapterBids[bidderName2] = &seatBid2
 
-	bidCategory, adapterBids, rejections, err := applyCategoryMapping(nil, &requestExt, adapterBids, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
+	bidCategory, adapterBids, err := applyCategoryMapping(nil, &requestExt, adapterBids, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
+	rejections := getRejectionMessages(adapterBids)
 
 	assert.NoError(t, err, "Category mapping error should be empty")
 	assert.Empty(t, rejections, "There should be 0 bid rejection messages")
@@ -2927,7 +3366,7 @@ func TestBidRejectionErrors(t *testing.T) {
 		innerBids := []*pbsOrtbBid{}
 		for _, bid := range test.bids {
 			currentBid := pbsOrtbBid{
-				bid, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: test.duration}, nil, 0, false, ""}
+				bid, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: test.duration}, nil, 0, false, "", nil, ""}
 			innerBids = append(innerBids, &currentBid)
 		}
 
@@ -2935,7 +3374,8 @@ func TestBidRejectionErrors(t *testing.T) {
 
 		adapterBids[bidderName] = &seatBid
 
-		bidCategory, adapterBids, rejections, err := applyCategoryMapping(nil, &test.reqExt, adapterBids, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
+		bidCategory, adapterBids, err := applyCategoryMapping(nil, &test.reqExt, adapterBids, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
+		rejections := getRejectionMessages(adapterBids)
 
 		if len(test.expectedCatDur) > 0 {
 			// Bid deduplication case
@@ -2975,8 +3415,8 @@ func TestCategoryMappingTwoBiddersOneBidEachNoCategorySamePrice(t *testing.T) {
 	bidApn1 := openrtb2.Bid{ID: "bid_idApn1", ImpID: "imp_idApn1", Price: 10.0000, Cat: cats1, W: 1, H: 1}
 	bidApn2 := openrtb2.Bid{ID: "bid_idApn2", ImpID: "imp_idApn2", Price: 10.0000, Cat: cats2, W: 1, H: 1}
 
-	bid1_Apn1 := pbsOrtbBid{&bidApn1, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
-	bid1_Apn2 := pbsOrtbBid{&bidApn2, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
+	bid1_Apn1 := pbsOrtbBid{&bidApn1, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", nil, ""}
+	bid1_Apn2 := pbsOrtbBid{&bidApn2, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", nil, ""}
 
 	innerBidsApn1 := []*pbsOrtbBid{
 		&bid1_Apn1,
@@ -2998,7 +3438,8 @@ func TestCategoryMappingTwoBiddersOneBidEachNoCategorySamePrice(t *testing.T) {
 		adapterBids[bidderNameApn1] = &seatBidApn1
 		adapterBids[bidderNameApn2] = &seatBidApn2
 
-		bidCategory, adapterBids, rejections, err := applyCategoryMapping(nil, &requestExt, adapterBids, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
+		bidCategory, adapterBids, err := applyCategoryMapping(nil, &requestExt, adapterBids, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
+		rejections := getRejectionMessages(adapterBids)
 
 		assert.NoError(t, err, "Category mapping error should be empty")
 		assert.Len(t, rejections, 1, "There should be 1 bid rejection message")
@@ -3055,11 +3496,11 @@ func TestCategoryMappingTwoBiddersManyBidsEachNoCategorySamePrice(t *testing.T)
 	bidApn2_1 := openrtb2.Bid{ID: "bid_idApn2_1", ImpID: "imp_idApn2_1", Price: 10.0000, Cat: cats2, W: 1, H: 1}
 	bidApn2_2 := openrtb2.Bid{ID: "bid_idApn2_2", ImpID: "imp_idApn2_2", Price: 20.0000, Cat: cats2, W: 1, H: 1}
 
-	bid1_Apn1_1 := pbsOrtbBid{&bidApn1_1, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
-	bid1_Apn1_2 := pbsOrtbBid{&bidApn1_2, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
+	bid1_Apn1_1 := pbsOrtbBid{&bidApn1_1, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", nil, ""}
+	bid1_Apn1_2 := pbsOrtbBid{&bidApn1_2, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", nil, ""}
 
-	bid1_Apn2_1 := pbsOrtbBid{&bidApn2_1, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
-	bid1_Apn2_2 := pbsOrtbBid{&bidApn2_2, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
+	bid1_Apn2_1 := pbsOrtbBid{&bidApn2_1, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", nil, ""}
+	bid1_Apn2_2 := pbsOrtbBid{&bidApn2_2, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", nil, ""}
 
 	innerBidsApn1 := []*pbsOrtbBid{
 		&bid1_Apn1_1,
@@ -3082,7 +3523,8 @@ func TestCategoryMappingTwoBiddersManyBidsEachNoCategorySamePrice(t *testing.T)
 	adapterBids[bidderNameApn1] = &seatBidApn1
 	adapterBids[bidderNameApn2] = &seatBidApn2
 
-	_, adapterBids, rejections, err := applyCategoryMapping(nil, &requestExt, adapterBids, categoriesFetcher, targData, &fakeRandomDeduplicateBidBooleanGenerator{true})
+	_, adapterBids, err := applyCategoryMapping(nil, &requestExt, adapterBids, categoriesFetcher, targData, &fakeRandomDeduplicateBidBooleanGenerator{true})
+	rejections := getRejectionMessages(adapterBids)
 
 	assert.NoError(t, err, "Category mapping error should be empty")
 
@@ -3117,6 +3559,9 @@ func TestCategoryMappingTwoBiddersManyBidsEachNoCategorySamePrice(t *testing.T)
 		assert.Equal(t, "bid rejected [bid ID: bid_idApn2_1] reason: Bid was deduplicated", rejections[0], "Incorrect rejected bid 1")
 		assert.Equal(t, "bid rejected [bid ID: bid_idApn2_2] reason: Bid was deduplicated", rejections[1], "Incorrect rejected bid 2")
 
+		assert.Empty(t, adapterBids[bidderNameApn1].rejectedBids)
+		assert.Equal(t, []*analytics.RejectedBid{{Bid: &bidApn2_1, Reason: openrtb_ext.RejectionReasonDuplicate, Message: "Bid was deduplicated"}, {Bid: &bidApn2_2, Reason: openrtb_ext.RejectionReasonDuplicate, Message: "Bid was deduplicated"}}, adapterBids[bidderNameApn2].rejectedBids)
+
 	} else {
 		assert.Len(t, adapterBids[bidderNameApn1].bids, 0)
 		assert.Len(t, adapterBids[bidderNameApn2].bids, 2)
@@ -3127,6 +3572,9 @@ func TestCategoryMappingTwoBiddersManyBidsEachNoCategorySamePrice(t *testing.T)
 		assert.Equal(t, "bid rejected [bid ID: bid_idApn1_1] reason: Bid was deduplicated", rejections[0], "Incorrect rejected bid 1")
 		assert.Equal(t, "bid rejected [bid ID: bid_idApn1_2] reason: Bid was deduplicated", rejections[1], "Incorrect rejected bid 2")
 
+		assert.Equal(t, []*analytics.RejectedBid{{Bid: &bidApn1_1, Reason: openrtb_ext.RejectionReasonDuplicate, Message: "Bid was deduplicated"}, {Bid: &bidApn1_2, Reason: openrtb_ext.RejectionReasonDuplicate, Message: "Bid was deduplicated"}}, adapterBids[bidderNameApn1].rejectedBids)
+		assert.Empty(t, adapterBids[bidderNameApn2].rejectedBids)
+
 	}
 }
 
@@ -3137,9 +3585,9 @@ func TestRemoveBidById(t *testing.T) {
 	bidApn1_2 := openrtb2.Bid{ID: "bid_idApn1_2", ImpID: "imp_idApn1_2", Price: 20.0000, Cat: cats1, W: 1, H: 1}
 	bidApn1_3 := openrtb2.Bid{ID: "bid_idApn1_3", ImpID: "imp_idApn1_3", Price: 10.0000, Cat: cats1, W: 1, H: 1}
 
-	bid1_Apn1_1 := pbsOrtbBid{&bidApn1_1, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
-	bid1_Apn1_2 := pbsOrtbBid{&bidApn1_2, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
-	bid1_Apn1_3 := pbsOrtbBid{&bidApn1_3, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
+	bid1_Apn1_1 := pbsOrtbBid{&bidApn1_1, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", nil, ""}
+	bid1_Apn1_2 := pbsOrtbBid{&bidApn1_2, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", nil, ""}
+	bid1_Apn1_3 := pbsOrtbBid{&bidApn1_3, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", nil, ""}
 
 	type aTest struct {
 		desc      string
@@ -3185,15 +3633,36 @@ func TestRemoveBidById(t *testing.T) {
 
 }
 
-func TestUpdateRejections(t *testing.T) {
-	rejections := []string{}
+func TestMakeRejectionErrors(t *testing.T) {
+	seatBids := map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+		"rubicon": {rejectedBids: []*analytics.RejectedBid{
+			{Bid: &openrtb2.Bid{ID: "bid_id3"}, Reason: openrtb_ext.RejectionReasonDuplicate, Message: "Bid was deduplicated"},
+		}},
+		"appnexus": {rejectedBids: []*analytics.RejectedBid{
+			{Bid: &openrtb2.Bid{ID: "bid_id1"}, Reason: openrtb_ext.RejectionReasonInvalidBid, Message: "Bid missing required field 'crid'"},
+			{Bid: &openrtb2.Bid{ID: "bid_id2"}, Reason: openrtb_ext.RejectionReasonBelowFloor, Message: "some reason 2"},
+		}},
+		"openx": {rejectedBids: []*analytics.RejectedBid{
+			{Bid: &openrtb2.Bid{ID: "bid_id4"}, Reason: openrtb_ext.RejectionReasonInvalidBid, Message: "Bid missing required field 'crid'"},
+		}},
+	}
+	counts := map[openrtb_ext.BidderName]int{"appnexus": 1, "openx": 1}
+
+	errs := makeRejectionErrors(seatBids, counts)
 
-	rejections = updateRejections(rejections, "bid_id1", "some reason 1")
-	rejections = updateRejections(rejections, "bid_id2", "some reason 2")
+	assert.Equal(t, []error{
+		errors.New("bid rejected [bid ID: bid_id2] reason: some reason 2"),
+		errors.New("bid rejected [bid ID: bid_id3] reason: Bid was deduplicated"),
+	}, errs, "Only the rejections made since the counts were taken, sorted by seat")
+}
 
-	assert.Equal(t, 2, len(rejections), "Rejections should contain 2 rejection messages")
-	assert.Containsf(t, rejections, "bid rejected [bid ID: bid_id1] reason: some reason 1", "Rejection message did not match expected")
-	assert.Containsf(t, rejections, "bid rejected [bid ID: bid_id2] reason: some reason 2", "Rejection message did not match expected")
+// getRejectionMessages describes all the bids rejected by the seats.
+func getRejectionMessages(seatBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid) []string {
+	var messages []string
+	for _, err := range makeRejectionErrors(seatBids, nil) {
+		messages = append(messages, err.Error())
+	}
+	return messages
 }
 
 func TestApplyDealSupport(t *testing.T) {
@@ -3264,23 +3733,23 @@ func TestApplyDealSupport(t *testing.T) {
 			},
 		}
 
-		bid := pbsOrtbBid{&openrtb2.Bid{ID: "123456"}, "video", map[string]string{}, &openrtb_ext.ExtBidPrebidVideo{}, nil, test.dealPriority, false, ""}
+		bid := pbsOrtbBid{&openrtb2.Bid{ID: "123456"}, "video", map[string]string{}, &openrtb_ext.ExtBidPrebidVideo{}, nil, test.dealPriority, false, "", nil, ""}
 		bidCategory := map[string]string{
 			bid.bid.ID: test.targ["hb_pb_cat_dur"],
 		}
 
 		auc := &auction{
-			winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+			winningBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
 				"imp_id1": {
-					bidderName: &bid,
+					bidderName: {&bid},
 				},
 			},
 		}
 
 		dealErrs := applyDealSupport(bidRequest, auc, bidCategory)
 
-		assert.Equal(t, test.expectedHbPbCatDur, bidCategory[auc.winningBidsByBidder["imp_id1"][bidderName].bid.ID], test.description)
-		assert.Equal(t, test.expectedDealTierSatisfied, auc.winningBidsByBidder["imp_id1"][bidderName].dealTierSatisfied, "expectedDealTierSatisfied=%v when %v", test.expectedDealTierSatisfied, test.description)
+		assert.Equal(t, test.expectedHbPbCatDur, bidCategory[auc.winningBidsByBidder["imp_id1"][bidderName][0].bid.ID], test.description)
+		assert.Equal(t, test.expectedDealTierSatisfied, auc.winningBidsByBidder["imp_id1"][bidderName][0].dealTierSatisfied, "expectedDealTierSatisfied=%v when %v", test.expectedDealTierSatisfied, test.description)
 		if len(test.expectedDealErr) > 0 {
 			assert.Containsf(t, dealErrs, errors.New(test.expectedDealErr), "Expected error message not found in deal errors")
 		}
@@ -3431,7 +3900,7 @@ func TestUpdateHbPbCatDur(t *testing.T) {
 	}
 
 	for _, test := range testCases {
-		bid := pbsOrtbBid{&openrtb2.Bid{ID: "123456"}, "video", map[string]string{}, &openrtb_ext.ExtBidPrebidVideo{}, nil, test.dealPriority, false, ""}
+		bid := pbsOrtbBid{&openrtb2.Bid{ID: "123456"}, "video", map[string]string{}, &openrtb_ext.ExtBidPrebidVideo{}, nil, test.dealPriority, false, "", nil, ""}
 		bidCategory := map[string]string{
 			bid.bid.ID: test.targ["hb_pb_cat_dur"],
 		}
@@ -3455,6 +3924,7 @@ type exchangeSpec struct {
 	EventsEnabled     bool                   `json:"events_enabled,omitempty"`
 	StartTime         int64                  `json:"start_time_ms,omitempty"`
 	BidIDGenerator    *mockBidIDGenerator    `json:"bidIDGenerator,omitempty"`
+	FloorsEnabled     bool                   `json:"floors_enabled,omitempty"`
 }
 
 type exchangeRequest struct {
@@ -3520,7 +3990,7 @@ type validatingBidder struct {
 	mockResponses map[string]bidderResponse
 }
 
-func (b *validatingBidder) requestBid(ctx context.Context, request *openrtb2.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, accountDebugAllowed, headerDebugAllowed bool) (seatBid *pbsOrtbSeatBid, errs []error) {
+func (b *validatingBidder) requestBid(ctx context.Context, request *openrtb2.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, accountDebugAllowed, headerDebugAllowed bool, hookExecutor hookexecution.StageExecutor) (seatBid *pbsOrtbSeatBid, errs []error) {
 	if expectedRequest, ok := b.expectations[string(name)]; ok {
 		if expectedRequest != nil {
 			if expectedRequest.BidAdjustment != bidAdjustment {
@@ -3544,6 +4014,7 @@ func (b *validatingBidder) requestBid(ctx context.Context, request *openrtb2.Bid
 
 			seatBid = &pbsOrtbSeatBid{
 				bids:      bids,
+				currency:  "USD",
 				httpCalls: mockResponse.HttpCalls,
 			}
 		} else {
@@ -3699,7 +4170,7 @@ func (e *mockUsersync) LiveSyncCount() int {
 
 type panicingAdapter struct{}
 
-func (panicingAdapter) requestBid(ctx context.Context, request *openrtb2.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, accountDebugAllowed, headerDebugAllowed bool) (posb *pbsOrtbSeatBid, errs []error) {
+func (panicingAdapter) requestBid(ctx context.Context, request *openrtb2.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, accountDebugAllowed, headerDebugAllowed bool, hookExecutor hookexecution.StageExecutor) (posb *pbsOrtbSeatBid, errs []error) {
 	panic("Panic! Panic! The world is ending!")
 }
 
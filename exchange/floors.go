@@ -0,0 +1,53 @@
+package exchange
+
+import (
+	"fmt"
+
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/floors"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// enforceFloors removes the bids which are below the floor of their imp. Bid prices have already been
+// converted to the seat currency and had bid adjustment factors applied, so the floor is converted
+// into the seat currency before comparing. Every bid with a floor is annotated with it, whether or not
+// floors are enforced in this auction. The removed bids are recorded as rejected by their seat.
+func enforceFloors(seatBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid, impFloors map[string]floors.Result, enforcement floors.Enforcement, conversions currency.Conversions) {
+	for _, seatBid := range seatBids {
+		validBids := make([]*pbsOrtbBid, 0, len(seatBid.bids))
+		for _, bid := range seatBid.bids {
+			floor, hasFloor := impFloors[bid.bid.ImpID]
+			if !hasFloor {
+				validBids = append(validBids, bid)
+				continue
+			}
+
+			bid.bidFloors = &openrtb_ext.ExtBidPrebidFloors{
+				FloorRule:      floor.FloorRule,
+				FloorRuleValue: floor.FloorRuleValue,
+				FloorValue:     floor.FloorValue,
+				FloorCurrency:  floor.FloorCurrency,
+			}
+
+			if !enforcement.Enforce || (bid.bid.DealID != "" && !enforcement.FloorDeals) {
+				validBids = append(validBids, bid)
+				continue
+			}
+
+			rate, err := conversions.GetRate(floor.FloorCurrency, seatBid.currency)
+			if err != nil {
+				reason := fmt.Sprintf("Unable to convert price floor from %s to %s", floor.FloorCurrency, seatBid.currency)
+				seatBid.rejectBid(bid.bid, openrtb_ext.RejectionReasonFloorCurrency, reason)
+				continue
+			}
+
+			if floorValue := floor.FloorValue * rate; bid.bid.Price < floorValue {
+				reason := fmt.Sprintf("Bid price %.4f %s is below the floor %.4f %s for imp ID %s", bid.bid.Price, seatBid.currency, floorValue, seatBid.currency, bid.bid.ImpID)
+				seatBid.rejectBid(bid.bid, openrtb_ext.RejectionReasonBelowFloor, reason)
+				continue
+			}
+			validBids = append(validBids, bid)
+		}
+		seatBid.bids = validBids
+	}
+}
@@ -0,0 +1,109 @@
+package exchange
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/floors"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnforceFloors(t *testing.T) {
+	impFloors := map[string]floors.Result{
+		"imp1": {FloorValue: 1.0, FloorCurrency: "USD", FloorRule: "banner|*", FloorRuleValue: 1.0},
+	}
+	conversions := currency.NewRates(time.Now(), map[string]map[string]float64{
+		"USD": {"EUR": 0.8},
+	})
+
+	testCases := []struct {
+		description        string
+		enforcement        floors.Enforcement
+		seatCurrency       string
+		bids               []*openrtb2.Bid
+		expectedBidIDs     []string
+		expectedRejections int
+	}{
+		{
+			description:        "Bid below floor is dropped",
+			enforcement:        floors.Enforcement{Enforce: true},
+			seatCurrency:       "USD",
+			bids:               []*openrtb2.Bid{{ID: "low", ImpID: "imp1", Price: 0.9}, {ID: "high", ImpID: "imp1", Price: 1.1}},
+			expectedBidIDs:     []string{"high"},
+			expectedRejections: 1,
+		},
+		{
+			description:        "Bids on imps without a floor are kept",
+			enforcement:        floors.Enforcement{Enforce: true},
+			seatCurrency:       "USD",
+			bids:               []*openrtb2.Bid{{ID: "other", ImpID: "imp2", Price: 0.1}},
+			expectedBidIDs:     []string{"other"},
+			expectedRejections: 0,
+		},
+		{
+			description:        "Floors not enforced",
+			enforcement:        floors.Enforcement{Enforce: false},
+			seatCurrency:       "USD",
+			bids:               []*openrtb2.Bid{{ID: "low", ImpID: "imp1", Price: 0.5}},
+			expectedBidIDs:     []string{"low"},
+			expectedRejections: 0,
+		},
+		{
+			description:        "Deal bids are exempt by default",
+			enforcement:        floors.Enforcement{Enforce: true},
+			seatCurrency:       "USD",
+			bids:               []*openrtb2.Bid{{ID: "deal", ImpID: "imp1", Price: 0.5, DealID: "deal1"}},
+			expectedBidIDs:     []string{"deal"},
+			expectedRejections: 0,
+		},
+		{
+			description:        "Deal bids are enforced when floor deals is set",
+			enforcement:        floors.Enforcement{Enforce: true, FloorDeals: true},
+			seatCurrency:       "USD",
+			bids:               []*openrtb2.Bid{{ID: "deal", ImpID: "imp1", Price: 0.5, DealID: "deal1"}},
+			expectedBidIDs:     []string{},
+			expectedRejections: 1,
+		},
+		{
+			description:        "Floor is converted to the seat currency",
+			enforcement:        floors.Enforcement{Enforce: true},
+			seatCurrency:       "EUR",
+			bids:               []*openrtb2.Bid{{ID: "low", ImpID: "imp1", Price: 0.7}, {ID: "high", ImpID: "imp1", Price: 0.85}},
+			expectedBidIDs:     []string{"high"},
+			expectedRejections: 1,
+		},
+		{
+			description:        "Unknown seat currency",
+			enforcement:        floors.Enforcement{Enforce: true},
+			seatCurrency:       "JPY",
+			bids:               []*openrtb2.Bid{{ID: "bid", ImpID: "imp1", Price: 100}},
+			expectedBidIDs:     []string{},
+			expectedRejections: 1,
+		},
+	}
+
+	for _, test := range testCases {
+		seatBid := &pbsOrtbSeatBid{currency: test.seatCurrency}
+		for _, bid := range test.bids {
+			seatBid.bids = append(seatBid.bids, &pbsOrtbBid{bid: bid, bidType: openrtb_ext.BidTypeBanner})
+		}
+		seatBids := map[openrtb_ext.BidderName]*pbsOrtbSeatBid{openrtb_ext.BidderAppnexus: seatBid}
+
+		enforceFloors(seatBids, impFloors, test.enforcement, conversions)
+
+		bidIDs := make([]string, 0, len(seatBid.bids))
+		for _, bid := range seatBid.bids {
+			bidIDs = append(bidIDs, bid.bid.ID)
+			if bid.bid.ImpID == "imp1" {
+				assert.Equal(t, &openrtb_ext.ExtBidPrebidFloors{FloorRule: "banner|*", FloorRuleValue: 1.0, FloorValue: 1.0, FloorCurrency: "USD"}, bid.bidFloors, test.description)
+			} else {
+				assert.Nil(t, bid.bidFloors, test.description)
+			}
+		}
+		assert.Equal(t, test.expectedBidIDs, bidIDs, test.description)
+		assert.Len(t, seatBid.rejectedBids, test.expectedRejections, test.description)
+	}
+}
@@ -0,0 +1,105 @@
+package exchange
+
+import (
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/hooks/hookexecution"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+const processedBidRemovedMessage = "Bid removed by a processed bid responses hook"
+
+// executeAllProcessedBidResponsesStage runs the all-processed-bid-responses hooks on the bids of every seat.
+// Bids kept by the hooks retain the data the exchange attached to them, while bids added by the hooks are
+// appended to their seat, and bids removed by the hooks are recorded as rejected by their seat. Seats the
+// hooks add are ignored, as the exchange has no response data for them.
+// It returns the updated seat bids and whether any bids remain.
+func executeAllProcessedBidResponsesStage(hookExecutor hookexecution.StageExecutor, seatBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid) (map[openrtb_ext.BidderName]*pbsOrtbSeatBid, bool) {
+	responses := make(map[openrtb_ext.BidderName]*adapters.BidderResponse, len(seatBids))
+	for bidder, seatBid := range seatBids {
+		response := &adapters.BidderResponse{
+			Currency: seatBid.currency,
+			Bids:     make([]*adapters.TypedBid, 0, len(seatBid.bids)),
+		}
+		for _, pbsBid := range seatBid.bids {
+			response.Bids = append(response.Bids, &adapters.TypedBid{
+				Bid:          pbsBid.bid,
+				BidType:      pbsBid.bidType,
+				BidVideo:     pbsBid.bidVideo,
+				DealPriority: pbsBid.dealPriority,
+			})
+		}
+		responses[bidder] = response
+	}
+
+	responses = hookExecutor.ExecuteAllProcessedBidResponsesStage(responses)
+
+	bidsFound := false
+	for bidder, seatBid := range seatBids {
+		response, ok := responses[bidder]
+		if !ok || response == nil || len(response.Bids) == 0 {
+			for _, pbsBid := range seatBid.bids {
+				seatBid.rejectBid(pbsBid.bid, openrtb_ext.RejectionReasonModule, processedBidRemovedMessage)
+			}
+			delete(seatBids, bidder)
+			continue
+		}
+
+		bidsByOrtbBid := make(map[*openrtb2.Bid]*pbsOrtbBid, len(seatBid.bids))
+		for _, pbsBid := range seatBid.bids {
+			bidsByOrtbBid[pbsBid.bid] = pbsBid
+		}
+
+		bids := make([]*pbsOrtbBid, 0, len(response.Bids))
+		for _, typedBid := range response.Bids {
+			if typedBid == nil || typedBid.Bid == nil {
+				continue
+			}
+			pbsBid, ok := bidsByOrtbBid[typedBid.Bid]
+			if !ok {
+				pbsBid = &pbsOrtbBid{bid: typedBid.Bid}
+			}
+			pbsBid.bidType = typedBid.BidType
+			pbsBid.bidVideo = typedBid.BidVideo
+			pbsBid.dealPriority = typedBid.DealPriority
+			bids = append(bids, pbsBid)
+		}
+
+		keptBids := make(map[*openrtb2.Bid]struct{}, len(bids))
+		for _, pbsBid := range bids {
+			keptBids[pbsBid.bid] = struct{}{}
+		}
+		for _, pbsBid := range seatBid.bids {
+			if _, ok := keptBids[pbsBid.bid]; !ok {
+				seatBid.rejectBid(pbsBid.bid, openrtb_ext.RejectionReasonModule, processedBidRemovedMessage)
+			}
+		}
+
+		if len(bids) == 0 {
+			delete(seatBids, bidder)
+			continue
+		}
+		seatBid.bids = bids
+		bidsFound = true
+	}
+
+	return seatBids, bidsFound
+}
+
+// rejectBidsRemovedByHooks records the bids which the hooks didn't keep as rejected by the seat.
+func rejectBidsRemovedByHooks(seatBid *pbsOrtbSeatBid, bids []*adapters.TypedBid, keptBids []*adapters.TypedBid, message string) {
+	kept := make(map[*openrtb2.Bid]struct{}, len(keptBids))
+	for _, typedBid := range keptBids {
+		if typedBid != nil {
+			kept[typedBid.Bid] = struct{}{}
+		}
+	}
+	for _, typedBid := range bids {
+		if typedBid == nil || typedBid.Bid == nil {
+			continue
+		}
+		if _, ok := kept[typedBid.Bid]; !ok {
+			seatBid.rejectBid(typedBid.Bid, openrtb_ext.RejectionReasonModule, message)
+		}
+	}
+}
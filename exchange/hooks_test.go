@@ -0,0 +1,73 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/analytics"
+	"github.com/prebid/prebid-server/hooks/hookexecution"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecuteAllProcessedBidResponsesStageRejections(t *testing.T) {
+	keptBid := &openrtb2.Bid{ID: "kept", ImpID: "imp", Price: 1}
+	removedBid := &openrtb2.Bid{ID: "removed", ImpID: "imp", Price: 2}
+	otherSeatBid := &openrtb2.Bid{ID: "other", ImpID: "imp", Price: 3}
+
+	appnexusSeatBid := &pbsOrtbSeatBid{bids: []*pbsOrtbBid{{bid: keptBid}, {bid: removedBid}}}
+	rubiconSeatBid := &pbsOrtbSeatBid{bids: []*pbsOrtbBid{{bid: otherSeatBid}}}
+	seatBids := map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+		openrtb_ext.BidderAppnexus: appnexusSeatBid,
+		openrtb_ext.BidderRubicon:  rubiconSeatBid,
+	}
+	hookExecutor := &mockProcessedBidsHookExecutor{removedBidIDs: map[string]bool{"removed": true, "other": true}}
+
+	seatBids, bidsFound := executeAllProcessedBidResponsesStage(hookExecutor, seatBids)
+
+	assert.True(t, bidsFound)
+	assert.Len(t, seatBids, 1, "The seat without bids should be removed")
+	assert.Equal(t, []*pbsOrtbBid{{bid: keptBid}}, appnexusSeatBid.bids)
+	assert.Equal(t, []*analytics.RejectedBid{
+		{Bid: removedBid, Reason: openrtb_ext.RejectionReasonModule, Message: "Bid removed by a processed bid responses hook"},
+	}, appnexusSeatBid.rejectedBids)
+	assert.Equal(t, []*analytics.RejectedBid{
+		{Bid: otherSeatBid, Reason: openrtb_ext.RejectionReasonModule, Message: "Bid removed by a processed bid responses hook"},
+	}, rubiconSeatBid.rejectedBids)
+}
+
+func TestRejectBidsRemovedByHooks(t *testing.T) {
+	keptBid := &openrtb2.Bid{ID: "kept"}
+	removedBid := &openrtb2.Bid{ID: "removed"}
+	bids := []*adapters.TypedBid{{Bid: keptBid}, {Bid: removedBid}, nil}
+
+	seatBid := &pbsOrtbSeatBid{}
+	rejectBidsRemovedByHooks(seatBid, bids, []*adapters.TypedBid{{Bid: keptBid}}, "Bid removed by a raw bidder response hook")
+	assert.Equal(t, []*analytics.RejectedBid{
+		{Bid: removedBid, Reason: openrtb_ext.RejectionReasonModule, Message: "Bid removed by a raw bidder response hook"},
+	}, seatBid.rejectedBids, "Bids removed")
+
+	seatBid = &pbsOrtbSeatBid{}
+	rejectBidsRemovedByHooks(seatBid, bids, nil, "Bidder response rejected by a raw bidder response hook")
+	assert.Len(t, seatBid.rejectedBids, 2, "Response rejected")
+}
+
+// mockProcessedBidsHookExecutor removes the bids with the given IDs in the all-processed-bid-responses stage.
+type mockProcessedBidsHookExecutor struct {
+	hookexecution.EmptyHookExecutor
+	removedBidIDs map[string]bool
+}
+
+func (e *mockProcessedBidsHookExecutor) ExecuteAllProcessedBidResponsesStage(responses map[openrtb_ext.BidderName]*adapters.BidderResponse) map[openrtb_ext.BidderName]*adapters.BidderResponse {
+	for _, response := range responses {
+		bids := make([]*adapters.TypedBid, 0, len(response.Bids))
+		for _, typedBid := range response.Bids {
+			if !e.removedBidIDs[typedBid.Bid.ID] {
+				bids = append(bids, typedBid)
+			}
+		}
+		response.Bids = bids
+	}
+	return responses
+}
@@ -0,0 +1,81 @@
+package exchange
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// applyMultiBidLimits keeps, for every bidder with a multibid entry, its best maxbids bids on each imp. The other
+// bids are rejected. Bids of other bidders are left untouched. It returns the seat bids and whether any bids remain.
+func applyMultiBidLimits(seatBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid, multiBid map[string]openrtb_ext.ExtMultiBid, preferDeals bool) (map[openrtb_ext.BidderName]*pbsOrtbSeatBid, bool) {
+	bidsFound := false
+	for bidderName, seatBid := range seatBids {
+		if seatBid == nil {
+			continue
+		}
+		if entry, ok := multiBid[string(bidderName)]; ok {
+			var dropped []*pbsOrtbBid
+			seatBid.bids, dropped = limitBidsPerImp(seatBid.bids, *entry.MaxBids, preferDeals)
+			for _, bid := range dropped {
+				seatBid.rejectBid(bid.bid, openrtb_ext.RejectionReasonMultiBidLimit, fmt.Sprintf("Bid exceeds the multibid limit of %d bids per imp", *entry.MaxBids))
+			}
+		}
+		if len(seatBid.bids) > 0 {
+			bidsFound = true
+		}
+	}
+	return seatBids, bidsFound
+}
+
+// limitBidsPerImp keeps the best maxBids bids on each imp, preserving the order of the bids it keeps and of those
+// it drops.
+func limitBidsPerImp(bids []*pbsOrtbBid, maxBids int, preferDeals bool) (kept, dropped []*pbsOrtbBid) {
+	bidsByImp := make(map[string][]*pbsOrtbBid)
+	for _, bid := range bids {
+		bidsByImp[bid.bid.ImpID] = append(bidsByImp[bid.bid.ImpID], bid)
+	}
+
+	keep := make(map[*pbsOrtbBid]bool, len(bids))
+	for _, impBids := range bidsByImp {
+		sortBidsByRank(impBids, preferDeals)
+		if len(impBids) > maxBids {
+			impBids = impBids[:maxBids]
+		}
+		for _, bid := range impBids {
+			keep[bid] = true
+		}
+	}
+
+	kept = make([]*pbsOrtbBid, 0, len(keep))
+	for _, bid := range bids {
+		if keep[bid] {
+			kept = append(kept, bid)
+		} else {
+			dropped = append(dropped, bid)
+		}
+	}
+	return kept, dropped
+}
+
+// sortBidsByRank sorts the bids from best to worst. Bids of equal rank keep their order.
+func sortBidsByRank(bids []*pbsOrtbBid, preferDeals bool) {
+	sort.SliceStable(bids, func(i, j int) bool {
+		return isNewWinningBid(bids[i].bid, bids[j].bid, preferDeals)
+	})
+}
+
+// targetingBidLimit returns how many bids of the bidder get targeting keys on each imp. Bids after the first
+// one are only targeted when the multibid entry of the bidder defines the bidder code to target them with.
+func targetingBidLimit(multiBid map[string]openrtb_ext.ExtMultiBid, bidderName openrtb_ext.BidderName) int {
+	if entry, ok := multiBid[string(bidderName)]; ok && entry.TargetBidderCodePrefix != "" {
+		return *entry.MaxBids
+	}
+	return 1
+}
+
+// targetBidderCode is the bidder code in the targeting keys of the bid ranked at the index (starting at 0) among the bids of the bidder.
+func targetBidderCode(prefix string, index int) openrtb_ext.BidderName {
+	return openrtb_ext.BidderName(fmt.Sprintf("%s%d", prefix, index+1))
+}
@@ -0,0 +1,124 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyMultiBidLimits(t *testing.T) {
+	maxBids := 2
+	multiBid := map[string]openrtb_ext.ExtMultiBid{
+		"appnexus": {Bidder: "appnexus", MaxBids: &maxBids},
+	}
+
+	bid1p100 := &pbsOrtbBid{bid: &openrtb2.Bid{ID: "1", ImpID: "imp1", Price: 1.00}}
+	bid1p300 := &pbsOrtbBid{bid: &openrtb2.Bid{ID: "2", ImpID: "imp1", Price: 3.00}}
+	bid1p200 := &pbsOrtbBid{bid: &openrtb2.Bid{ID: "3", ImpID: "imp1", Price: 2.00}}
+	bid1p050d := &pbsOrtbBid{bid: &openrtb2.Bid{ID: "4", ImpID: "imp1", Price: 0.50, DealID: "deal"}}
+	bid2p100 := &pbsOrtbBid{bid: &openrtb2.Bid{ID: "5", ImpID: "imp2", Price: 1.00}}
+	rubiconBid1 := &pbsOrtbBid{bid: &openrtb2.Bid{ID: "6", ImpID: "imp1", Price: 1.00}}
+	rubiconBid2 := &pbsOrtbBid{bid: &openrtb2.Bid{ID: "7", ImpID: "imp1", Price: 2.00}}
+	rubiconBid3 := &pbsOrtbBid{bid: &openrtb2.Bid{ID: "8", ImpID: "imp1", Price: 3.00}}
+
+	testCases := []struct {
+		description            string
+		preferDeals            bool
+		expectedBidIDs         []string
+		expectedRejectedBidIDs []string
+	}{
+		{
+			description:            "Best bids by price are kept, in their original order",
+			preferDeals:            false,
+			expectedBidIDs:         []string{"2", "3", "5"},
+			expectedRejectedBidIDs: []string{"1", "4"},
+		},
+		{
+			description:            "Deals rank first when preferred",
+			preferDeals:            true,
+			expectedBidIDs:         []string{"2", "4", "5"},
+			expectedRejectedBidIDs: []string{"1", "3"},
+		},
+	}
+
+	for _, test := range testCases {
+		seatBids := map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+			"appnexus": {bids: []*pbsOrtbBid{bid1p100, bid1p300, bid1p200, bid1p050d, bid2p100}},
+			"rubicon":  {bids: []*pbsOrtbBid{rubiconBid1, rubiconBid2, rubiconBid3}},
+		}
+
+		seatBids, bidsFound := applyMultiBidLimits(seatBids, multiBid, test.preferDeals)
+		assert.True(t, bidsFound, test.description)
+
+		var bidIDs []string
+		for _, bid := range seatBids["appnexus"].bids {
+			bidIDs = append(bidIDs, bid.bid.ID)
+		}
+		assert.Equal(t, test.expectedBidIDs, bidIDs, test.description)
+
+		var rejectedBidIDs []string
+		for _, rejectedBid := range seatBids["appnexus"].rejectedBids {
+			rejectedBidIDs = append(rejectedBidIDs, rejectedBid.Bid.ID)
+			assert.Equal(t, openrtb_ext.RejectionReasonMultiBidLimit, rejectedBid.Reason, test.description)
+			assert.Equal(t, "Bid exceeds the multibid limit of 2 bids per imp", rejectedBid.Message, test.description)
+		}
+		assert.Equal(t, test.expectedRejectedBidIDs, rejectedBidIDs, test.description)
+
+		assert.Len(t, seatBids["rubicon"].bids, 3, "%s: bidders without multibid shouldn't be limited", test.description)
+		assert.Empty(t, seatBids["rubicon"].rejectedBids, "%s: bidders without multibid shouldn't be limited", test.description)
+	}
+}
+
+func TestMultiBidTargeting(t *testing.T) {
+	maxBids := 3
+	multiBid := map[string]openrtb_ext.ExtMultiBid{
+		"appnexus": {Bidder: "appnexus", MaxBids: &maxBids, TargetBidderCodePrefix: "apn"},
+		"rubicon":  {Bidder: "rubicon", MaxBids: &maxBids},
+	}
+
+	appnexusBids := []*pbsOrtbBid{
+		{bid: &openrtb2.Bid{ID: "apn-1", ImpID: "imp1", Price: 1.00}, bidType: openrtb_ext.BidTypeVideo},
+		{bid: &openrtb2.Bid{ID: "apn-2", ImpID: "imp1", Price: 3.00}, bidType: openrtb_ext.BidTypeVideo},
+		{bid: &openrtb2.Bid{ID: "apn-3", ImpID: "imp1", Price: 2.00}, bidType: openrtb_ext.BidTypeVideo},
+	}
+	rubiconBids := []*pbsOrtbBid{
+		{bid: &openrtb2.Bid{ID: "rp-1", ImpID: "imp1", Price: 1.50}, bidType: openrtb_ext.BidTypeVideo},
+		{bid: &openrtb2.Bid{ID: "rp-2", ImpID: "imp1", Price: 2.50}, bidType: openrtb_ext.BidTypeVideo},
+	}
+	seatBids := map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+		"appnexus": {bids: appnexusBids},
+		"rubicon":  {bids: rubiconBids},
+	}
+
+	auc := newAuction(seatBids, 1, false, multiBid)
+	assert.Equal(t, []*pbsOrtbBid{appnexusBids[1], appnexusBids[2], appnexusBids[0]}, auc.winningBidsByBidder["imp1"]["appnexus"], "All bids with a prefix should be targeted, from best to worst")
+	assert.Equal(t, []*pbsOrtbBid{rubiconBids[1]}, auc.winningBidsByBidder["imp1"]["rubicon"], "Only the best bid should be targeted without a prefix")
+
+	targData := &targetData{
+		priceGranularity:  openrtb_ext.PriceGranularityFromString("med"),
+		includeWinners:    true,
+		includeBidderKeys: true,
+	}
+	auc.setRoundedPrices(targData.priceGranularity)
+	targData.setTargeting(auc, false, nil)
+
+	assert.Equal(t, map[string]string{
+		"hb_bidder":          "appnexus",
+		"hb_bidder_appnexus": "appnexus",
+		"hb_pb":              "3.00",
+		"hb_pb_appnexus":     "3.00",
+	}, appnexusBids[1].bidTargets)
+	assert.Equal(t, map[string]string{
+		"hb_bidder_apn2": "apn2",
+		"hb_pb_apn2":     "2.00",
+	}, appnexusBids[2].bidTargets)
+	assert.Equal(t, map[string]string{
+		"hb_bidder_apn3": "apn3",
+		"hb_pb_apn3":     "1.00",
+	}, appnexusBids[0].bidTargets)
+	assert.Equal(t, openrtb_ext.BidderName("apn2"), appnexusBids[2].targetBidderCode)
+	assert.Empty(t, appnexusBids[1].targetBidderCode, "The best bid should keep the bidder name")
+	assert.Nil(t, rubiconBids[0].bidTargets, "Extra bids without a prefix shouldn't be targeted")
+}
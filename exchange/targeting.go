@@ -30,6 +30,7 @@ type targetData struct {
 }
 
 // setTargeting writes all the targeting params into the bids.
+// The extra bids allowed by multibid are targeted with the bidder code of their multibid entry instead of the bidder name.
 // If any errors occur when setting the targeting params for a particular bid, then that bid will be ejected from the auction.
 //
 // The one exception is the `hb_cache_id` key. Since our APIs explicitly document cache keys to be on a "best effort" basis,
@@ -38,46 +39,52 @@ type targetData struct {
 func (targData *targetData) setTargeting(auc *auction, isApp bool, categoryMapping map[string]string) {
 	for impId, topBidsPerImp := range auc.winningBidsByBidder {
 		overallWinner := auc.winningBids[impId]
-		for bidderName, topBidPerBidder := range topBidsPerImp {
-			isOverallWinner := overallWinner == topBidPerBidder
+		for bidderName, topBidsPerBidder := range topBidsPerImp {
+			for _, topBid := range topBidsPerBidder {
+				isOverallWinner := overallWinner == topBid
+				targetingBidderCode := bidderName
+				if topBid.targetBidderCode != "" {
+					targetingBidderCode = topBid.targetBidderCode
+				}
 
-			targets := make(map[string]string, 10)
-			if cpm, ok := auc.roundedPrices[topBidPerBidder]; ok {
-				targData.addKeys(targets, openrtb_ext.HbpbConstantKey, cpm, bidderName, isOverallWinner)
-			}
-			targData.addKeys(targets, openrtb_ext.HbBidderConstantKey, string(bidderName), bidderName, isOverallWinner)
-			if hbSize := makeHbSize(topBidPerBidder.bid); hbSize != "" {
-				targData.addKeys(targets, openrtb_ext.HbSizeConstantKey, hbSize, bidderName, isOverallWinner)
-			}
-			if cacheID, ok := auc.cacheIds[topBidPerBidder.bid]; ok {
-				targData.addKeys(targets, openrtb_ext.HbCacheKey, cacheID, bidderName, isOverallWinner)
-			}
-			if vastID, ok := auc.vastCacheIds[topBidPerBidder.bid]; ok {
-				targData.addKeys(targets, openrtb_ext.HbVastCacheKey, vastID, bidderName, isOverallWinner)
-			}
-			if targData.includeFormat {
-				targData.addKeys(targets, openrtb_ext.HbFormatKey, string(topBidPerBidder.bidType), bidderName, isOverallWinner)
-			}
+				targets := make(map[string]string, 10)
+				if cpm, ok := auc.roundedPrices[topBid]; ok {
+					targData.addKeys(targets, openrtb_ext.HbpbConstantKey, cpm, targetingBidderCode, isOverallWinner)
+				}
+				targData.addKeys(targets, openrtb_ext.HbBidderConstantKey, string(targetingBidderCode), targetingBidderCode, isOverallWinner)
+				if hbSize := makeHbSize(topBid.bid); hbSize != "" {
+					targData.addKeys(targets, openrtb_ext.HbSizeConstantKey, hbSize, targetingBidderCode, isOverallWinner)
+				}
+				if cacheID, ok := auc.cacheIds[topBid.bid]; ok {
+					targData.addKeys(targets, openrtb_ext.HbCacheKey, cacheID, targetingBidderCode, isOverallWinner)
+				}
+				if vastID, ok := auc.vastCacheIds[topBid.bid]; ok {
+					targData.addKeys(targets, openrtb_ext.HbVastCacheKey, vastID, targetingBidderCode, isOverallWinner)
+				}
+				if targData.includeFormat {
+					targData.addKeys(targets, openrtb_ext.HbFormatKey, string(topBid.bidType), targetingBidderCode, isOverallWinner)
+				}
 
-			if targData.cacheHost != "" {
-				targData.addKeys(targets, openrtb_ext.HbConstantCacheHostKey, targData.cacheHost, bidderName, isOverallWinner)
-			}
-			if targData.cachePath != "" {
-				targData.addKeys(targets, openrtb_ext.HbConstantCachePathKey, targData.cachePath, bidderName, isOverallWinner)
-			}
+				if targData.cacheHost != "" {
+					targData.addKeys(targets, openrtb_ext.HbConstantCacheHostKey, targData.cacheHost, targetingBidderCode, isOverallWinner)
+				}
+				if targData.cachePath != "" {
+					targData.addKeys(targets, openrtb_ext.HbConstantCachePathKey, targData.cachePath, targetingBidderCode, isOverallWinner)
+				}
 
-			if deal := topBidPerBidder.bid.DealID; len(deal) > 0 {
-				targData.addKeys(targets, openrtb_ext.HbDealIDConstantKey, deal, bidderName, isOverallWinner)
-			}
+				if deal := topBid.bid.DealID; len(deal) > 0 {
+					targData.addKeys(targets, openrtb_ext.HbDealIDConstantKey, deal, targetingBidderCode, isOverallWinner)
+				}
 
-			if isApp {
-				targData.addKeys(targets, openrtb_ext.HbEnvKey, openrtb_ext.HbEnvKeyApp, bidderName, isOverallWinner)
-			}
-			if len(categoryMapping) > 0 {
-				targData.addKeys(targets, openrtb_ext.HbCategoryDurationKey, categoryMapping[topBidPerBidder.bid.ID], bidderName, isOverallWinner)
-			}
+				if isApp {
+					targData.addKeys(targets, openrtb_ext.HbEnvKey, openrtb_ext.HbEnvKeyApp, targetingBidderCode, isOverallWinner)
+				}
+				if len(categoryMapping) > 0 {
+					targData.addKeys(targets, openrtb_ext.HbCategoryDurationKey, categoryMapping[topBid.bid.ID], targetingBidderCode, isOverallWinner)
+				}
 
-			topBidPerBidder.bidTargets = targets
+				topBid.bidTargets = targets
+			}
 		}
 	}
 }
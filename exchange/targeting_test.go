@@ -280,16 +280,16 @@ var TargetingTests []TargetingTestData = []TargetingTestData{
 			includeWinners:   true,
 		},
 		Auction: auction{
-			winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+			winningBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
 				"ImpId-1": {
-					openrtb_ext.BidderAppnexus: {
+					openrtb_ext.BidderAppnexus: {{
 						bid:     bid123,
 						bidType: openrtb_ext.BidTypeBanner,
-					},
-					openrtb_ext.BidderRubicon: {
+					}},
+					openrtb_ext.BidderRubicon: {{
 						bid:     bid084,
 						bidType: openrtb_ext.BidTypeBanner,
-					},
+					}},
 				},
 			},
 		},
@@ -310,16 +310,16 @@ var TargetingTests []TargetingTestData = []TargetingTestData{
 			includeBidderKeys: true,
 		},
 		Auction: auction{
-			winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+			winningBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
 				"ImpId-1": {
-					openrtb_ext.BidderAppnexus: {
+					openrtb_ext.BidderAppnexus: {{
 						bid:     bid123,
 						bidType: openrtb_ext.BidTypeBanner,
-					},
-					openrtb_ext.BidderRubicon: {
+					}},
+					openrtb_ext.BidderRubicon: {{
 						bid:     bid084,
 						bidType: openrtb_ext.BidTypeBanner,
-					},
+					}},
 				},
 			},
 		},
@@ -345,16 +345,16 @@ var TargetingTests []TargetingTestData = []TargetingTestData{
 			includeFormat:     true,
 		},
 		Auction: auction{
-			winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+			winningBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
 				"ImpId-1": {
-					openrtb_ext.BidderAppnexus: {
+					openrtb_ext.BidderAppnexus: {{
 						bid:     bid123,
 						bidType: openrtb_ext.BidTypeBanner,
-					},
-					openrtb_ext.BidderRubicon: {
+					}},
+					openrtb_ext.BidderRubicon: {{
 						bid:     bid084,
 						bidType: openrtb_ext.BidTypeBanner,
-					},
+					}},
 				},
 			},
 		},
@@ -385,16 +385,16 @@ var TargetingTests []TargetingTestData = []TargetingTestData{
 			cachePath:         "cache",
 		},
 		Auction: auction{
-			winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+			winningBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
 				"ImpId-1": {
-					openrtb_ext.BidderAppnexus: {
+					openrtb_ext.BidderAppnexus: {{
 						bid:     bid123,
 						bidType: openrtb_ext.BidTypeBanner,
-					},
-					openrtb_ext.BidderRubicon: {
+					}},
+					openrtb_ext.BidderRubicon: {{
 						bid:     bid111,
 						bidType: openrtb_ext.BidTypeBanner,
-					},
+					}},
 				},
 			},
 			cacheIds: map[*openrtb2.Bid]string{
@@ -432,7 +432,8 @@ func TestSetTargeting(t *testing.T) {
 		winningBids := make(map[string]*pbsOrtbBid)
 		// Set winning bids from the auction data
 		for imp, bidsByBidder := range auc.winningBidsByBidder {
-			for _, bid := range bidsByBidder {
+			for _, bids := range bidsByBidder {
+				bid := bids[0]
 				if winningBid, ok := winningBids[imp]; ok {
 					if winningBid.bid.Price < bid.bid.Price {
 						winningBids[imp] = bid
@@ -449,7 +450,7 @@ func TestSetTargeting(t *testing.T) {
 			for bidder, expected := range targetsByBidder {
 				assert.Equal(t,
 					expected,
-					auc.winningBidsByBidder[imp][bidder].bidTargets,
+					auc.winningBidsByBidder[imp][bidder][0].bidTargets,
 					"Test: %s\nTargeting failed for bidder %s on imp %s.",
 					test.Description,
 					string(bidder),
@@ -3,6 +3,7 @@ package exchange
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/rand"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/prebid/go-gdpr/vendorconsent"
 
 	"github.com/buger/jsonparser"
+	"github.com/evanphx/json-patch"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/gdpr"
 	"github.com/prebid/prebid-server/metrics"
@@ -71,9 +73,21 @@ func cleanOpenRTBRequests(ctx context.Context,
 	if len(errs) > 0 {
 		return
 	}
+	aliases = req.Account.Bidders.MergeAliases(aliases)
+
+	// The buyer uids are removed from user.ext before the first party data copies the user, so that no bidder gets them
+	explicitBuyerUIDs, err := extractBuyerUIDs(req.BidRequest.User)
+	if err != nil {
+		errs = []error{err}
+		return
+	}
+
+	bidderFPD, errs := extractBidderFirstPartyData(req.BidRequest, requestExt, impsByBidder)
 
 	var allBidderRequests []BidderRequest
-	allBidderRequests, errs = getAuctionBidderRequests(req, requestExt, impsByBidder, aliases)
+	var requestErrs []error
+	allBidderRequests, requestErrs = getAuctionBidderRequests(req, requestExt, impsByBidder, aliases, explicitBuyerUIDs, bidderFPD)
+	errs = append(errs, requestErrs...)
 
 	if len(allBidderRequests) == 0 {
 		return
@@ -206,18 +220,13 @@ func extractLMT(orig *openrtb2.BidRequest, privacyConfig config.Privacy) privacy
 func getAuctionBidderRequests(req AuctionRequest,
 	requestExt *openrtb_ext.ExtRequest,
 	impsByBidder map[string][]openrtb2.Imp,
-	aliases map[string]string) ([]BidderRequest, []error) {
+	aliases map[string]string,
+	explicitBuyerUIDs map[string]string,
+	bidderFPD map[string]*firstPartyData) ([]BidderRequest, []error) {
 
 	bidderRequests := make([]BidderRequest, 0, len(impsByBidder))
 
-	explicitBuyerUIDs, err := extractBuyerUIDs(req.BidRequest.User)
-	if err != nil {
-		return nil, []error{err}
-	}
-
-	var sChainsByBidder map[string]*openrtb_ext.ExtRequestPrebidSChainSChain
-
-	sChainsByBidder, err = BidderToPrebidSChains(requestExt)
+	sChainsByBidder, err := BidderToPrebidSChains(requestExt)
 	if err != nil {
 		return nil, []error{err}
 	}
@@ -230,6 +239,10 @@ func getAuctionBidderRequests(req AuctionRequest,
 	var errs []error
 	for bidder, imps := range impsByBidder {
 		coreBidder := resolveBidder(bidder, aliases)
+		// The endpoints already strip the bidders disabled for the account, with a warning
+		if !req.Account.Bidders.BidderEnabled(bidder, string(coreBidder)) {
+			continue
+		}
 
 		reqCopy := *req.BidRequest
 		reqCopy.Imp = imps
@@ -237,6 +250,12 @@ func getAuctionBidderRequests(req AuctionRequest,
 
 		prepareSource(&reqCopy, bidder, sChainsByBidder)
 
+		if fpd, ok := bidderFPD[bidder]; ok {
+			reqCopy.Site = fpd.site
+			reqCopy.App = fpd.app
+			reqCopy.User = fpd.user
+		}
+
 		if err := removeUnpermissionedEids(&reqCopy, bidder, requestExt); err != nil {
 			errs = append(errs, fmt.Errorf("unable to enforce request.ext.prebid.data.eidpermissions because %v", err))
 			continue
@@ -274,6 +293,12 @@ func getExtJson(req *openrtb2.BidRequest, unpackedExt *openrtb_ext.ExtRequest) (
 
 	extCopy := *unpackedExt
 	extCopy.Prebid.SChains = nil
+	extCopy.Prebid.BidderConfigs = nil
+	if extCopy.Prebid.Data != nil {
+		dataCopy := *extCopy.Prebid.Data
+		dataCopy.Bidders = nil
+		extCopy.Prebid.Data = &dataCopy
+	}
 	return json.Marshal(extCopy)
 }
 
@@ -579,6 +604,175 @@ func setUserExtWithCopy(request *openrtb2.BidRequest, userExtJSON json.RawMessag
 	request.User = &userCopy
 }
 
+// firstPartyData holds the site, app and user objects sent to a bidder in place of those of the request.
+type firstPartyData struct {
+	site *openrtb2.Site
+	app  *openrtb2.App
+	user *openrtb2.User
+}
+
+// extractBidderFirstPartyData resolves the first party data of every bidder which has imps. The global FPD in site.ext.data,
+// app.ext.data and user.ext.data is removed for the bidders not listed in ext.prebid.data.bidders, then the objects of
+// the ext.prebid.bidderconfig entries matching the bidder are merged in. Bidders whose FPD can't be resolved are removed
+// from impsByBidder, so that no request is sent to them. It returns nil if the request has no bidder specific FPD.
+func extractBidderFirstPartyData(req *openrtb2.BidRequest, requestExt *openrtb_ext.ExtRequest, impsByBidder map[string][]openrtb2.Imp) (map[string]*firstPartyData, []error) {
+	if requestExt == nil {
+		return nil, nil
+	}
+	var allowedBidders []string
+	if requestExt.Prebid.Data != nil {
+		allowedBidders = requestExt.Prebid.Data.Bidders
+	}
+	bidderConfigs := requestExt.Prebid.BidderConfigs
+	if len(allowedBidders) == 0 && len(bidderConfigs) == 0 {
+		return nil, nil
+	}
+
+	var errs []error
+	bidderFPD := make(map[string]*firstPartyData, len(impsByBidder))
+	for bidder := range impsByBidder {
+		fpd := &firstPartyData{site: req.Site, app: req.App, user: req.User}
+
+		if len(allowedBidders) > 0 && !containsBidder(allowedBidders, bidder) {
+			if err := fpd.removeGlobalData(); err != nil {
+				errs = append(errs, fmt.Errorf("unable to remove first party data for bidder %s because %v", bidder, err))
+				delete(impsByBidder, bidder)
+				continue
+			}
+		}
+
+		if err := fpd.mergeBidderConfigs(bidder, bidderConfigs); err != nil {
+			errs = append(errs, fmt.Errorf("unable to apply request.ext.prebid.bidderconfig for bidder %s because %v", bidder, err))
+			delete(impsByBidder, bidder)
+			continue
+		}
+
+		bidderFPD[bidder] = fpd
+	}
+	return bidderFPD, errs
+}
+
+// removeGlobalData removes ext.data from the site, app and user. The objects are copied before being modified.
+func (fpd *firstPartyData) removeGlobalData() error {
+	if fpd.site != nil {
+		ext, err := removeExtData(fpd.site.Ext)
+		if err != nil {
+			return err
+		}
+		siteCopy := *fpd.site
+		siteCopy.Ext = ext
+		fpd.site = &siteCopy
+	}
+	if fpd.app != nil {
+		ext, err := removeExtData(fpd.app.Ext)
+		if err != nil {
+			return err
+		}
+		appCopy := *fpd.app
+		appCopy.Ext = ext
+		fpd.app = &appCopy
+	}
+	if fpd.user != nil {
+		ext, err := removeExtData(fpd.user.Ext)
+		if err != nil {
+			return err
+		}
+		userCopy := *fpd.user
+		userCopy.Ext = ext
+		fpd.user = &userCopy
+	}
+	return nil
+}
+
+func removeExtData(ext json.RawMessage) (json.RawMessage, error) {
+	if len(ext) == 0 {
+		return ext, nil
+	}
+
+	// low level unmarshal to preserve the other ext values
+	var extMap map[string]json.RawMessage
+	if err := json.Unmarshal(ext, &extMap); err != nil {
+		return nil, err
+	}
+	if _, ok := extMap[openrtb_ext.FirstPartyDataExtKey]; !ok {
+		return ext, nil
+	}
+
+	delete(extMap, openrtb_ext.FirstPartyDataExtKey)
+	if len(extMap) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(extMap)
+}
+
+// mergeBidderConfigs merges the objects of the bidder config entries matching the bidder, in order. The site can't be set on
+// app requests and the app can't be set on site requests, as a request must define exactly one of them.
+func (fpd *firstPartyData) mergeBidderConfigs(bidder string, bidderConfigs []openrtb_ext.ExtRequestPrebidBidderConfig) error {
+	for _, bidderConfig := range bidderConfigs {
+		if bidderConfig.Config == nil || bidderConfig.Config.ORTB2 == nil || !containsBidder(bidderConfig.Bidders, bidder) {
+			continue
+		}
+		ortb2 := bidderConfig.Config.ORTB2
+
+		if len(ortb2.Site) > 0 {
+			if fpd.site == nil {
+				return errors.New("site cannot be set on an app request")
+			}
+			site := &openrtb2.Site{}
+			if err := mergeFirstPartyObject(fpd.site, ortb2.Site, site); err != nil {
+				return fmt.Errorf("invalid site: %v", err)
+			}
+			fpd.site = site
+		}
+
+		if len(ortb2.App) > 0 {
+			if fpd.app == nil {
+				return errors.New("app cannot be set on a site request")
+			}
+			app := &openrtb2.App{}
+			if err := mergeFirstPartyObject(fpd.app, ortb2.App, app); err != nil {
+				return fmt.Errorf("invalid app: %v", err)
+			}
+			fpd.app = app
+		}
+
+		if len(ortb2.User) > 0 {
+			user := &openrtb2.User{}
+			var original interface{} = fpd.user
+			if fpd.user == nil {
+				original = struct{}{}
+			}
+			if err := mergeFirstPartyObject(original, ortb2.User, user); err != nil {
+				return fmt.Errorf("invalid user: %v", err)
+			}
+			fpd.user = user
+		}
+	}
+	return nil
+}
+
+// mergeFirstPartyObject applies the bidder config to the original object as a JSON merge patch, and unmarshals the result into merged.
+func mergeFirstPartyObject(original interface{}, bidderConfig json.RawMessage, merged interface{}) error {
+	originalJSON, err := json.Marshal(original)
+	if err != nil {
+		return err
+	}
+	mergedJSON, err := jsonpatch.MergePatch(originalJSON, bidderConfig)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(mergedJSON, merged)
+}
+
+func containsBidder(bidders []string, bidder string) bool {
+	for _, b := range bidders {
+		if b == "*" || b == bidder {
+			return true
+		}
+	}
+	return false
+}
+
 // resolveBidder returns the known BidderName associated with bidder, if bidder is an alias. If it's not an alias, the bidder is returned.
 func resolveBidder(bidder string, aliases map[string]string) openrtb_ext.BidderName {
 	if coreBidder, ok := aliases[bidder]; ok {
@@ -688,10 +882,11 @@ func getDebugInfo(bidRequest *openrtb2.BidRequest, requestExt *openrtb_ext.ExtRe
 	return (bidRequest != nil && bidRequest.Test == 1) || (requestExt != nil && requestExt.Prebid.Debug)
 }
 
-func getExtBidAdjustmentFactors(requestExt *openrtb_ext.ExtRequest) map[string]float64 {
+// getExtBidAdjustmentFactors merges the request bid adjustment factors with the account ones
+func getExtBidAdjustmentFactors(requestExt *openrtb_ext.ExtRequest, account *config.Account) map[string]float64 {
 	var bidAdjustmentFactors map[string]float64
 	if requestExt != nil {
 		bidAdjustmentFactors = requestExt.Prebid.BidAdjustmentFactors
 	}
-	return bidAdjustmentFactors
+	return account.BidAdjustments.MergeFactors(bidAdjustmentFactors)
 }
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/buger/jsonparser"
 	"github.com/mxmCherry/openrtb/v15/openrtb2"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/errortypes"
@@ -489,6 +490,72 @@ func TestCleanOpenRTBRequests(t *testing.T) {
 	}
 }
 
+func TestCleanOpenRTBRequestsAccountBidders(t *testing.T) {
+	testCases := []struct {
+		description     string
+		requestExt      json.RawMessage
+		accountBidders  config.AccountBidders
+		expectedBidders map[openrtb_ext.BidderName]openrtb_ext.BidderName
+	}{
+		{
+			description:     "Alias defined by the account",
+			accountBidders:  config.AccountBidders{Aliases: map[string]string{"brightroll": "appnexus"}},
+			expectedBidders: map[openrtb_ext.BidderName]openrtb_ext.BidderName{"appnexus": "appnexus", "brightroll": "appnexus"},
+		},
+		{
+			description:     "Account alias wins over the request one",
+			requestExt:      json.RawMessage(`{"prebid":{"aliases":{"brightroll":"rubicon"}}}`),
+			accountBidders:  config.AccountBidders{Aliases: map[string]string{"brightroll": "appnexus"}},
+			expectedBidders: map[openrtb_ext.BidderName]openrtb_ext.BidderName{"appnexus": "appnexus", "brightroll": "appnexus"},
+		},
+		{
+			description:     "Request alias wins if the account allows it",
+			requestExt:      json.RawMessage(`{"prebid":{"aliases":{"brightroll":"rubicon"}}}`),
+			accountBidders:  config.AccountBidders{Aliases: map[string]string{"brightroll": "appnexus"}, AllowRequestAliasOverride: true},
+			expectedBidders: map[openrtb_ext.BidderName]openrtb_ext.BidderName{"appnexus": "appnexus", "brightroll": "rubicon"},
+		},
+		{
+			description:     "Disabled alias",
+			requestExt:      json.RawMessage(`{"prebid":{"aliases":{"brightroll":"appnexus"}}}`),
+			accountBidders:  config.AccountBidders{Disabled: []string{"brightroll"}},
+			expectedBidders: map[openrtb_ext.BidderName]openrtb_ext.BidderName{"appnexus": "appnexus"},
+		},
+		{
+			description:     "Disabled core bidder disables its aliases",
+			requestExt:      json.RawMessage(`{"prebid":{"aliases":{"brightroll":"appnexus"}}}`),
+			accountBidders:  config.AccountBidders{Disabled: []string{"appnexus"}},
+			expectedBidders: map[openrtb_ext.BidderName]openrtb_ext.BidderName{},
+		},
+		{
+			description:     "Only allowed bidders",
+			requestExt:      json.RawMessage(`{"prebid":{"aliases":{"brightroll":"rubicon"}}}`),
+			accountBidders:  config.AccountBidders{Allowed: []string{"rubicon"}},
+			expectedBidders: map[openrtb_ext.BidderName]openrtb_ext.BidderName{"brightroll": "rubicon"},
+		},
+	}
+
+	for _, test := range testCases {
+		req := newAdapterAliasBidRequest(t)
+		req.Ext = test.requestExt
+		auctionReq := AuctionRequest{
+			BidRequest: req,
+			UserSyncs:  &emptyUsersync{},
+			Account:    config.Account{Bidders: test.accountBidders},
+		}
+
+		metricsMock := metrics.MetricsEngineMock{}
+		permissions := permissionsMock{allowAllBidders: true, passGeo: true, passID: true}
+		bidderRequests, _, errs := cleanOpenRTBRequests(context.Background(), auctionReq, nil, &permissions, &metricsMock, gdpr.SignalNo, config.Privacy{}, nil)
+		assert.Empty(t, errs, test.description)
+
+		bidders := make(map[openrtb_ext.BidderName]openrtb_ext.BidderName, len(bidderRequests))
+		for _, bidderRequest := range bidderRequests {
+			bidders[bidderRequest.BidderName] = bidderRequest.BidderCoreName
+		}
+		assert.Equal(t, test.expectedBidders, bidders, test.description)
+	}
+}
+
 func TestCleanOpenRTBRequestsCCPA(t *testing.T) {
 	trueValue, falseValue := true, false
 
@@ -1340,6 +1407,7 @@ func TestGetExtBidAdjustmentFactors(t *testing.T) {
 	testCases := []struct {
 		desc                    string
 		inRequestExt            *openrtb_ext.ExtRequest
+		inAccount               config.Account
 		outBidAdjustmentFactors map[string]float64
 	}{
 		{
@@ -1357,9 +1425,27 @@ func TestGetExtBidAdjustmentFactors(t *testing.T) {
 			inRequestExt:            &openrtb_ext.ExtRequest{Prebid: openrtb_ext.ExtRequestPrebid{BidAdjustmentFactors: map[string]float64{"bid-factor": 1.0}}},
 			outBidAdjustmentFactors: map[string]float64{"bid-factor": 1.0},
 		},
+		{
+			desc:                    "Nil request ext, account BidAdjustments",
+			inRequestExt:            nil,
+			inAccount:               config.Account{BidAdjustments: config.AccountBidAdjustments{Factors: map[string]float64{"bid-factor": 0.8}}},
+			outBidAdjustmentFactors: map[string]float64{"bid-factor": 0.8},
+		},
+		{
+			desc:                    "Account BidAdjustments merged with request ones, account wins",
+			inRequestExt:            &openrtb_ext.ExtRequest{Prebid: openrtb_ext.ExtRequestPrebid{BidAdjustmentFactors: map[string]float64{"bid-factor": 1.0, "other-factor": 0.5}}},
+			inAccount:               config.Account{BidAdjustments: config.AccountBidAdjustments{Factors: map[string]float64{"bid-factor": 0.8}}},
+			outBidAdjustmentFactors: map[string]float64{"bid-factor": 0.8, "other-factor": 0.5},
+		},
+		{
+			desc:                    "Account BidAdjustments merged with request ones, request wins if allowed",
+			inRequestExt:            &openrtb_ext.ExtRequest{Prebid: openrtb_ext.ExtRequestPrebid{BidAdjustmentFactors: map[string]float64{"bid-factor": 1.0}}},
+			inAccount:               config.Account{BidAdjustments: config.AccountBidAdjustments{Factors: map[string]float64{"bid-factor": 0.8}, AllowRequestOverride: true}},
+			outBidAdjustmentFactors: map[string]float64{"bid-factor": 1.0},
+		},
 	}
 	for _, test := range testCases {
-		actualBidAdjustmentFactors := getExtBidAdjustmentFactors(test.inRequestExt)
+		actualBidAdjustmentFactors := getExtBidAdjustmentFactors(test.inRequestExt, &test.inAccount)
 
 		assert.Equal(t, test.outBidAdjustmentFactors, actualBidAdjustmentFactors, "%s. Unexpected BidAdjustmentFactors value. \n", test.desc)
 	}
@@ -2216,3 +2302,167 @@ func TestRemoveUnpermissionedEidsEmptyValidations(t *testing.T) {
 		assert.Equal(t, &requestExpected, test.request, test.description+":request")
 	}
 }
+
+func TestCleanOpenRTBRequestsFirstPartyDataBuyerUIDs(t *testing.T) {
+	testCases := []struct {
+		description string
+		requestExt  *openrtb_ext.ExtRequest
+	}{
+		{
+			description: "Global data removed for a bidder not allowed",
+			requestExt: &openrtb_ext.ExtRequest{Prebid: openrtb_ext.ExtRequestPrebid{
+				Data: &openrtb_ext.ExtRequestPrebidData{Bidders: []string{"appnexus"}},
+			}},
+		},
+		{
+			description: "User overridden by a bidder config",
+			requestExt: &openrtb_ext.ExtRequest{Prebid: openrtb_ext.ExtRequestPrebid{
+				BidderConfigs: []openrtb_ext.ExtRequestPrebidBidderConfig{{
+					Bidders: []string{"rubicon"},
+					Config:  &openrtb_ext.ExtBidderConfig{ORTB2: &openrtb_ext.ExtBidderConfigORTB2{User: json.RawMessage(`{"keywords":"sports"}`)}},
+				}},
+			}},
+		},
+	}
+
+	for _, test := range testCases {
+		req := &openrtb2.BidRequest{
+			Site: &openrtb2.Site{Page: "www.some.domain.com"},
+			User: &openrtb2.User{
+				ID:  "our-id",
+				Ext: json.RawMessage(`{"data":{"interest":"sports"},"prebid":{"buyeruids":{"appnexus":"AN-UID","rubicon":"RP-UID"}}}`),
+			},
+			Imp: []openrtb2.Imp{{
+				ID:     "some-imp-id",
+				Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 300, H: 250}}},
+				Ext:    json.RawMessage(`{"appnexus":{"placementId":1},"rubicon":{"accountId":1,"siteId":2,"zoneId":3}}`),
+			}},
+		}
+		auctionReq := AuctionRequest{
+			BidRequest: req,
+			UserSyncs:  &emptyUsersync{},
+		}
+
+		metricsMock := metrics.MetricsEngineMock{}
+		permissions := permissionsMock{allowAllBidders: true, passGeo: true, passID: true}
+		bidderRequests, _, errs := cleanOpenRTBRequests(context.Background(), auctionReq, test.requestExt, &permissions, &metricsMock, gdpr.SignalNo, config.Privacy{}, nil)
+		assert.Empty(t, errs, test.description)
+		assert.Len(t, bidderRequests, 2, test.description)
+
+		expectedBuyerUIDs := map[openrtb_ext.BidderName]string{"appnexus": "AN-UID", "rubicon": "RP-UID"}
+		for _, bidderRequest := range bidderRequests {
+			user := bidderRequest.BidRequest.User
+			if !assert.NotNil(t, user, test.description+":"+string(bidderRequest.BidderName)) {
+				continue
+			}
+			assert.Equal(t, expectedBuyerUIDs[bidderRequest.BidderName], user.BuyerUID, test.description+":"+string(bidderRequest.BidderName))
+			_, _, _, err := jsonparser.Get(user.Ext, "prebid")
+			assert.Equal(t, jsonparser.KeyPathNotFoundError, err, test.description+":"+string(bidderRequest.BidderName)+" got user.ext "+string(user.Ext))
+		}
+	}
+}
+
+func TestExtractBidderFirstPartyData(t *testing.T) {
+	site := &openrtb2.Site{Page: "test.somepage.com", Ext: json.RawMessage(`{"data":{"segment":"global"},"amp":0}`)}
+	app := &openrtb2.App{Bundle: "com.prebid", Ext: json.RawMessage(`{"data":{"segment":"global"}}`)}
+	user := &openrtb2.User{ID: "user", Ext: json.RawMessage(`{"data":{"interest":"sports"}}`)}
+
+	testCases := []struct {
+		description      string
+		request          *openrtb2.BidRequest
+		requestExt       *openrtb_ext.ExtRequest
+		expectedFPD      map[string]*firstPartyData
+		expectedBidders  []string
+		expectedErrCount int
+	}{
+		{
+			description:     "No bidder specific first party data",
+			request:         &openrtb2.BidRequest{Site: site, User: user},
+			requestExt:      &openrtb_ext.ExtRequest{},
+			expectedFPD:     nil,
+			expectedBidders: []string{"appnexus", "rubicon"},
+		},
+		{
+			description: "Global data removed for bidders not allowed",
+			request:     &openrtb2.BidRequest{Site: site, User: user},
+			requestExt: &openrtb_ext.ExtRequest{Prebid: openrtb_ext.ExtRequestPrebid{
+				Data: &openrtb_ext.ExtRequestPrebidData{Bidders: []string{"appnexus"}},
+			}},
+			expectedFPD: map[string]*firstPartyData{
+				"appnexus": {site: site, user: user},
+				"rubicon": {
+					site: &openrtb2.Site{Page: "test.somepage.com", Ext: json.RawMessage(`{"amp":0}`)},
+					user: &openrtb2.User{ID: "user"},
+				},
+			},
+			expectedBidders: []string{"appnexus", "rubicon"},
+		},
+		{
+			description: "Wildcard allows global data for every bidder",
+			request:     &openrtb2.BidRequest{App: app},
+			requestExt: &openrtb_ext.ExtRequest{Prebid: openrtb_ext.ExtRequestPrebid{
+				Data: &openrtb_ext.ExtRequestPrebidData{Bidders: []string{"*"}},
+			}},
+			expectedFPD: map[string]*firstPartyData{
+				"appnexus": {app: app},
+				"rubicon":  {app: app},
+			},
+			expectedBidders: []string{"appnexus", "rubicon"},
+		},
+		{
+			description: "Bidder config merged into the objects of the bidder",
+			request:     &openrtb2.BidRequest{App: app},
+			requestExt: &openrtb_ext.ExtRequest{Prebid: openrtb_ext.ExtRequestPrebid{
+				BidderConfigs: []openrtb_ext.ExtRequestPrebidBidderConfig{{
+					Bidders: []string{"rubicon"},
+					Config: &openrtb_ext.ExtBidderConfig{ORTB2: &openrtb_ext.ExtBidderConfigORTB2{
+						App:  json.RawMessage(`{"name":"bidder-app","ext":{"data":{"segment":"bidder"}}}`),
+						User: json.RawMessage(`{"yob":1980}`),
+					}},
+				}},
+			}},
+			expectedFPD: map[string]*firstPartyData{
+				"appnexus": {app: app},
+				"rubicon": {
+					app:  &openrtb2.App{Bundle: "com.prebid", Name: "bidder-app", Ext: json.RawMessage(`{"data":{"segment":"bidder"}}`)},
+					user: &openrtb2.User{Yob: 1980},
+				},
+			},
+			expectedBidders: []string{"appnexus", "rubicon"},
+		},
+		{
+			description: "Bidder dropped when its config sets a site on an app request",
+			request:     &openrtb2.BidRequest{App: app},
+			requestExt: &openrtb_ext.ExtRequest{Prebid: openrtb_ext.ExtRequestPrebid{
+				BidderConfigs: []openrtb_ext.ExtRequestPrebidBidderConfig{{
+					Bidders: []string{"rubicon"},
+					Config: &openrtb_ext.ExtBidderConfig{ORTB2: &openrtb_ext.ExtBidderConfigORTB2{
+						Site: json.RawMessage(`{"page":"bidder-page"}`),
+					}},
+				}},
+			}},
+			expectedFPD: map[string]*firstPartyData{
+				"appnexus": {app: app},
+			},
+			expectedBidders:  []string{"appnexus"},
+			expectedErrCount: 1,
+		},
+	}
+
+	for _, test := range testCases {
+		impsByBidder := map[string][]openrtb2.Imp{
+			"appnexus": {{ID: "imp1"}},
+			"rubicon":  {{ID: "imp1"}},
+		}
+
+		fpd, errs := extractBidderFirstPartyData(test.request, test.requestExt, impsByBidder)
+
+		assert.Equal(t, test.expectedFPD, fpd, test.description)
+		assert.Len(t, errs, test.expectedErrCount, test.description)
+		var bidders []string
+		for bidder := range impsByBidder {
+			bidders = append(bidders, bidder)
+		}
+		assert.ElementsMatch(t, test.expectedBidders, bidders, test.description)
+	}
+}
@@ -0,0 +1,59 @@
+package floors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// Fetcher provides the stored floor rules of an account.
+type Fetcher interface {
+	// Fetch returns the stored floor rules for the account, or nil if the account has none.
+	Fetch(accountID string) *openrtb_ext.PriceFloorRules
+}
+
+// NewFileFetcher _immediately_ loads stored floor rules from a local file.
+// These are stored in memory for low-latency reads.
+//
+// The file must contain a JSON object keyed by account ID, where each value follows the
+// request.ext.prebid.floors contract. For example:
+//
+// {
+//   "account1": { "floormin": 0.1, "data": { "modelgroups": [ ... ] } }
+// }
+func NewFileFetcher(filename string) (Fetcher, error) {
+	rulesJSON, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error reading price floor rules file %s: %v", filename, err)
+	}
+
+	var rules map[string]*openrtb_ext.PriceFloorRules
+	if err := json.Unmarshal(rulesJSON, &rules); err != nil {
+		return nil, fmt.Errorf("error parsing price floor rules file %s: %v", filename, err)
+	}
+
+	for accountID, accountRules := range rules {
+		if err := validateRules(accountRules); err != nil {
+			return nil, fmt.Errorf("invalid price floor rules for account %s: %v", accountID, err)
+		}
+	}
+
+	return &eagerFetcher{rules: rules}, nil
+}
+
+type eagerFetcher struct {
+	rules map[string]*openrtb_ext.PriceFloorRules
+}
+
+func (f *eagerFetcher) Fetch(accountID string) *openrtb_ext.PriceFloorRules {
+	return f.rules[accountID]
+}
+
+// EmptyFetcher is a Fetcher which never has stored floor rules.
+type EmptyFetcher struct{}
+
+func (EmptyFetcher) Fetch(accountID string) *openrtb_ext.PriceFloorRules {
+	return nil
+}
@@ -0,0 +1,220 @@
+package floors
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+const defaultCurrency = "USD"
+
+// Result describes the floor which was selected for a single imp.
+type Result struct {
+	// FloorValue is the floor set on the imp, after floormin has been applied.
+	FloorValue    float64
+	FloorCurrency string
+	// FloorRule is the rule key which matched the imp. It is empty if the model group default was used.
+	FloorRule      string
+	FloorRuleValue float64
+}
+
+// Enforcement holds the enforcement options resolved for a single auction.
+type Enforcement struct {
+	// Enforce is true if bids below the floor should be dropped in this auction.
+	Enforce bool
+	// FloorDeals is true if floors should also be enforced on deal bids.
+	FloorDeals bool
+}
+
+// ResolveRules picks the floor definition which applies to an auction. Values from the request take
+// precedence over the stored rules for the account, which take precedence over the account config.
+// It returns nil if floors are disabled for the account or the request.
+func ResolveRules(requestRules, storedRules *openrtb_ext.PriceFloorRules, account config.AccountPriceFloors) *openrtb_ext.PriceFloorRules {
+	if !account.Enabled {
+		return nil
+	}
+	if requestRules != nil && requestRules.Enabled != nil && !*requestRules.Enabled {
+		return nil
+	}
+
+	var resolved openrtb_ext.PriceFloorRules
+	if requestRules != nil {
+		resolved = *requestRules
+	}
+
+	if storedRules != nil {
+		if !hasModelGroups(resolved.Data) {
+			resolved.Data = storedRules.Data
+		}
+		if resolved.FloorMin == 0 {
+			resolved.FloorMin = storedRules.FloorMin
+			resolved.FloorMinCur = storedRules.FloorMinCur
+		}
+		if resolved.Enforcement == nil {
+			resolved.Enforcement = storedRules.Enforcement
+		}
+	}
+
+	if !hasModelGroups(resolved.Data) {
+		resolved.Data = account.Data
+	}
+
+	if !hasModelGroups(resolved.Data) && resolved.FloorMin == 0 {
+		return nil
+	}
+	return &resolved
+}
+
+// ResolveEnforcement decides whether floors are enforced in this auction, using the request
+// enforcement options where given and the account config otherwise.
+func ResolveEnforcement(rules *openrtb_ext.PriceFloorRules, account config.AccountPriceFloors) Enforcement {
+	enforcement := Enforcement{
+		Enforce:    true,
+		FloorDeals: account.EnforceDealFloors,
+	}
+	enforceRate := account.EnforceFloorsRate
+
+	if rules != nil && rules.Enforcement != nil {
+		if rules.Enforcement.EnforcePBS != nil {
+			enforcement.Enforce = *rules.Enforcement.EnforcePBS
+		}
+		if rules.Enforcement.FloorDeals != nil {
+			enforcement.FloorDeals = *rules.Enforcement.FloorDeals
+		}
+		if rules.Enforcement.EnforceRate != nil {
+			enforceRate = *rules.Enforcement.EnforceRate
+		}
+	}
+
+	if enforcement.Enforce && enforceRate < 100 {
+		enforcement.Enforce = rand.Intn(100) < enforceRate
+	}
+	return enforcement
+}
+
+// SelectFloors finds the floor of every imp in the request. It returns a copy of the request with the floors
+// written to imp.bidfloor and imp.bidfloorcur, or the request itself if no imp received a floor. The returned
+// map is keyed by imp ID and only contains the imps which received a floor.
+func SelectFloors(request *openrtb2.BidRequest, rules *openrtb_ext.PriceFloorRules, conversions currency.Conversions) (*openrtb2.BidRequest, map[string]Result, []error) {
+	if rules == nil {
+		return request, nil, nil
+	}
+	if err := validateRules(rules); err != nil {
+		return request, nil, []error{err}
+	}
+
+	floorCurrency := defaultCurrency
+	var modelGroup *openrtb_ext.PriceFloorModelGroup
+	if hasModelGroups(rules.Data) {
+		modelGroup = selectModelGroup(rules.Data.ModelGroups, rand.Intn)
+		if modelGroup.Currency != "" {
+			floorCurrency = modelGroup.Currency
+		} else if rules.Data.Currency != "" {
+			floorCurrency = rules.Data.Currency
+		}
+	}
+
+	var errs []error
+	floorMin := 0.0
+	if rules.FloorMin > 0 {
+		floorMinCurrency := rules.FloorMinCur
+		if floorMinCurrency == "" {
+			floorMinCurrency = floorCurrency
+		}
+		if rate, err := conversions.GetRate(floorMinCurrency, floorCurrency); err == nil {
+			floorMin = rules.FloorMin * rate
+		} else {
+			errs = append(errs, fmt.Errorf("unable to convert request.ext.prebid.floors.floormin from %s to %s: %v", floorMinCurrency, floorCurrency, err))
+		}
+	}
+
+	var ruleValues map[string]float64
+	var masks []uint
+	if modelGroup != nil {
+		ruleValues = normalizeRuleValues(modelGroup.Values)
+		masks = wildcardMasks(len(modelGroup.Schema.Fields))
+	}
+
+	// The request is shared with the caller, so the floors are written to a copy of it
+	flooredRequest := *request
+	flooredRequest.Imp = make([]openrtb2.Imp, len(request.Imp))
+	copy(flooredRequest.Imp, request.Imp)
+
+	results := make(map[string]Result, len(request.Imp))
+	for i := range flooredRequest.Imp {
+		imp := &flooredRequest.Imp[i]
+		var result Result
+
+		if modelGroup != nil {
+			fieldValues := getFieldValues(modelGroup.Schema.Fields, request, imp)
+			if rule, value, found := findRule(ruleValues, fieldValues, masks, getDelimiter(modelGroup.Schema)); found {
+				result.FloorRule = rule
+				result.FloorRuleValue = value
+			} else {
+				result.FloorRuleValue = modelGroup.Default
+			}
+		}
+
+		result.FloorValue = result.FloorRuleValue
+		if result.FloorValue < floorMin {
+			result.FloorValue = floorMin
+		}
+		if result.FloorValue <= 0 {
+			continue
+		}
+
+		result.FloorCurrency = floorCurrency
+		imp.BidFloor = result.FloorValue
+		imp.BidFloorCur = result.FloorCurrency
+		results[imp.ID] = result
+	}
+
+	if len(results) == 0 {
+		return request, results, errs
+	}
+	return &flooredRequest, results, errs
+}
+
+func hasModelGroups(data *openrtb_ext.PriceFloorData) bool {
+	return data != nil && len(data.ModelGroups) > 0
+}
+
+// selectModelGroup picks one of the model groups at random, in proportion to their weights.
+// Groups without a weight are weighted as 1.
+func selectModelGroup(groups []openrtb_ext.PriceFloorModelGroup, randInt func(int) int) *openrtb_ext.PriceFloorModelGroup {
+	if len(groups) == 1 {
+		return &groups[0]
+	}
+
+	totalWeight := 0
+	for _, group := range groups {
+		totalWeight += modelWeight(group)
+	}
+
+	pick := randInt(totalWeight)
+	for i := range groups {
+		pick -= modelWeight(groups[i])
+		if pick < 0 {
+			return &groups[i]
+		}
+	}
+	return &groups[len(groups)-1]
+}
+
+func modelWeight(group openrtb_ext.PriceFloorModelGroup) int {
+	if group.ModelWeight <= 0 {
+		return 1
+	}
+	return group.ModelWeight
+}
+
+func getDelimiter(schema openrtb_ext.PriceFloorSchema) string {
+	if schema.Delimiter == "" {
+		return openrtb_ext.FloorSchemaDefaultDelimiter
+	}
+	return schema.Delimiter
+}
@@ -0,0 +1,202 @@
+package floors
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveRules(t *testing.T) {
+	disabled := false
+	requestData := &openrtb_ext.PriceFloorData{ModelGroups: []openrtb_ext.PriceFloorModelGroup{{ModelVersion: "request"}}}
+	storedData := &openrtb_ext.PriceFloorData{ModelGroups: []openrtb_ext.PriceFloorModelGroup{{ModelVersion: "stored"}}}
+	accountData := &openrtb_ext.PriceFloorData{ModelGroups: []openrtb_ext.PriceFloorModelGroup{{ModelVersion: "account"}}}
+
+	testCases := []struct {
+		description   string
+		requestRules  *openrtb_ext.PriceFloorRules
+		storedRules   *openrtb_ext.PriceFloorRules
+		account       config.AccountPriceFloors
+		expectedRules *openrtb_ext.PriceFloorRules
+	}{
+		{
+			description:   "Disabled for account",
+			requestRules:  &openrtb_ext.PriceFloorRules{Data: requestData},
+			account:       config.AccountPriceFloors{Enabled: false},
+			expectedRules: nil,
+		},
+		{
+			description:   "Disabled for request",
+			requestRules:  &openrtb_ext.PriceFloorRules{Enabled: &disabled, Data: requestData},
+			account:       config.AccountPriceFloors{Enabled: true},
+			expectedRules: nil,
+		},
+		{
+			description:   "No rules anywhere",
+			account:       config.AccountPriceFloors{Enabled: true},
+			expectedRules: nil,
+		},
+		{
+			description:   "Request rules win",
+			requestRules:  &openrtb_ext.PriceFloorRules{Data: requestData},
+			storedRules:   &openrtb_ext.PriceFloorRules{Data: storedData, FloorMin: 0.5, FloorMinCur: "EUR"},
+			account:       config.AccountPriceFloors{Enabled: true, Data: accountData},
+			expectedRules: &openrtb_ext.PriceFloorRules{Data: requestData, FloorMin: 0.5, FloorMinCur: "EUR"},
+		},
+		{
+			description:   "Stored rules are used when the request has no data",
+			requestRules:  &openrtb_ext.PriceFloorRules{FloorMin: 0.2},
+			storedRules:   &openrtb_ext.PriceFloorRules{Data: storedData, FloorMin: 0.5},
+			account:       config.AccountPriceFloors{Enabled: true, Data: accountData},
+			expectedRules: &openrtb_ext.PriceFloorRules{Data: storedData, FloorMin: 0.2},
+		},
+		{
+			description:   "Account rules are the last resort",
+			account:       config.AccountPriceFloors{Enabled: true, Data: accountData},
+			expectedRules: &openrtb_ext.PriceFloorRules{Data: accountData},
+		},
+	}
+
+	for _, test := range testCases {
+		rules := ResolveRules(test.requestRules, test.storedRules, test.account)
+		assert.Equal(t, test.expectedRules, rules, test.description)
+	}
+}
+
+func TestResolveEnforcement(t *testing.T) {
+	enforcePBS := false
+	floorDeals := true
+	zeroRate := 0
+
+	testCases := []struct {
+		description string
+		rules       *openrtb_ext.PriceFloorRules
+		account     config.AccountPriceFloors
+		expected    Enforcement
+	}{
+		{
+			description: "Account defaults",
+			rules:       &openrtb_ext.PriceFloorRules{},
+			account:     config.AccountPriceFloors{EnforceFloorsRate: 100, EnforceDealFloors: true},
+			expected:    Enforcement{Enforce: true, FloorDeals: true},
+		},
+		{
+			description: "Request overrides",
+			rules:       &openrtb_ext.PriceFloorRules{Enforcement: &openrtb_ext.PriceFloorEnforcement{EnforcePBS: &enforcePBS, FloorDeals: &floorDeals}},
+			account:     config.AccountPriceFloors{EnforceFloorsRate: 100},
+			expected:    Enforcement{Enforce: false, FloorDeals: true},
+		},
+		{
+			description: "Zero enforce rate",
+			rules:       &openrtb_ext.PriceFloorRules{Enforcement: &openrtb_ext.PriceFloorEnforcement{EnforceRate: &zeroRate}},
+			account:     config.AccountPriceFloors{EnforceFloorsRate: 100},
+			expected:    Enforcement{Enforce: false},
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, ResolveEnforcement(test.rules, test.account), test.description)
+	}
+}
+
+func TestSelectFloors(t *testing.T) {
+	request := &openrtb2.BidRequest{
+		Site: &openrtb2.Site{Domain: "www.website.com"},
+		Imp: []openrtb2.Imp{
+			{ID: "banner-imp", Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 300, H: 250}}}},
+			{ID: "video-imp", Video: &openrtb2.Video{W: 640, H: 480}},
+			{ID: "native-imp", Native: &openrtb2.Native{}},
+		},
+	}
+	rules := &openrtb_ext.PriceFloorRules{
+		FloorMin:    1,
+		FloorMinCur: "USD",
+		Data: &openrtb_ext.PriceFloorData{
+			Currency: "EUR",
+			ModelGroups: []openrtb_ext.PriceFloorModelGroup{{
+				Schema: openrtb_ext.PriceFloorSchema{Fields: []string{"mediaType", "size"}},
+				Values: map[string]float64{
+					"banner|300x250": 2.0,
+					"video|*":        0.5,
+				},
+			}},
+		},
+	}
+	conversions := currency.NewRates(time.Now(), map[string]map[string]float64{
+		"USD": {"EUR": 0.8},
+	})
+
+	flooredRequest, impFloors, errs := SelectFloors(request, rules, conversions)
+
+	assert.Empty(t, errs)
+	assert.Equal(t, map[string]Result{
+		"banner-imp": {FloorValue: 2.0, FloorCurrency: "EUR", FloorRule: "banner|300x250", FloorRuleValue: 2.0},
+		"video-imp":  {FloorValue: 0.8, FloorCurrency: "EUR", FloorRule: "video|*", FloorRuleValue: 0.5},
+		"native-imp": {FloorValue: 0.8, FloorCurrency: "EUR"},
+	}, impFloors)
+	assert.Equal(t, 2.0, flooredRequest.Imp[0].BidFloor)
+	assert.Equal(t, "EUR", flooredRequest.Imp[0].BidFloorCur)
+	assert.Equal(t, 0.8, flooredRequest.Imp[1].BidFloor)
+	assert.Equal(t, "EUR", flooredRequest.Imp[1].BidFloorCur)
+	assert.Zero(t, request.Imp[0].BidFloor, "The request shouldn't be written to")
+	assert.Empty(t, request.Imp[0].BidFloorCur, "The request shouldn't be written to")
+}
+
+func TestSelectFloorsInvalidRules(t *testing.T) {
+	request := &openrtb2.BidRequest{Imp: []openrtb2.Imp{{ID: "imp", BidFloor: 0.3}}}
+	rules := &openrtb_ext.PriceFloorRules{FloorMin: -1}
+
+	flooredRequest, impFloors, errs := SelectFloors(request, rules, currency.NewConstantRates())
+
+	assert.Nil(t, impFloors)
+	assert.Len(t, errs, 1)
+	assert.Same(t, request, flooredRequest)
+	assert.Equal(t, 0.3, request.Imp[0].BidFloor)
+}
+
+func TestSelectModelGroup(t *testing.T) {
+	groups := []openrtb_ext.PriceFloorModelGroup{
+		{ModelVersion: "a", ModelWeight: 10},
+		{ModelVersion: "b", ModelWeight: 90},
+	}
+
+	assert.Equal(t, "a", selectModelGroup(groups, func(int) int { return 9 }).ModelVersion)
+	assert.Equal(t, "b", selectModelGroup(groups, func(int) int { return 10 }).ModelVersion)
+	assert.Equal(t, "b", selectModelGroup(groups, func(int) int { return 99 }).ModelVersion)
+}
+
+func TestFileFetcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "floors")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	rules := map[string]*openrtb_ext.PriceFloorRules{
+		"account1": {FloorMin: 0.5},
+	}
+	rulesJSON, _ := json.Marshal(rules)
+	filename := filepath.Join(dir, "floors.json")
+	if !assert.NoError(t, ioutil.WriteFile(filename, rulesJSON, 0644)) {
+		return
+	}
+
+	fetcher, err := NewFileFetcher(filename)
+	assert.NoError(t, err)
+	assert.Equal(t, &openrtb_ext.PriceFloorRules{FloorMin: 0.5}, fetcher.Fetch("account1"))
+	assert.Nil(t, fetcher.Fetch("account2"))
+}
+
+func TestFileFetcherErrors(t *testing.T) {
+	_, err := NewFileFetcher("does-not-exist.json")
+	assert.Error(t, err)
+}
@@ -0,0 +1,232 @@
+package floors
+
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+	"strings"
+
+	"github.com/buger/jsonparser"
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+var supportedSchemaFields = map[string]struct{}{
+	openrtb_ext.FloorSchemaMediaType:  {},
+	openrtb_ext.FloorSchemaSize:       {},
+	openrtb_ext.FloorSchemaDomain:     {},
+	openrtb_ext.FloorSchemaSiteDomain: {},
+	openrtb_ext.FloorSchemaPubDomain:  {},
+	openrtb_ext.FloorSchemaBundle:     {},
+	openrtb_ext.FloorSchemaGptSlot:    {},
+	openrtb_ext.FloorSchemaPbAdSlot:   {},
+	openrtb_ext.FloorSchemaCountry:    {},
+}
+
+// validateRules checks that every model group has a supported schema and rules which match it.
+func validateRules(rules *openrtb_ext.PriceFloorRules) error {
+	if rules == nil {
+		return nil
+	}
+	if rules.FloorMin < 0 {
+		return fmt.Errorf("price floors floormin must be positive. Got %f", rules.FloorMin)
+	}
+	if rules.Enforcement != nil && rules.Enforcement.EnforceRate != nil {
+		if rate := *rules.Enforcement.EnforceRate; rate < 0 || rate > 100 {
+			return fmt.Errorf("price floors enforcement.enforcerate must be between 0 and 100. Got %d", rate)
+		}
+	}
+	if rules.Data == nil {
+		return nil
+	}
+
+	for i, group := range rules.Data.ModelGroups {
+		if len(group.Schema.Fields) == 0 {
+			return fmt.Errorf("price floors modelgroups[%d].schema.fields must not be empty", i)
+		}
+		// Rule matching tries every combination of wildcards, so the number of fields must stay small
+		if len(group.Schema.Fields) > len(supportedSchemaFields) {
+			return fmt.Errorf("price floors modelgroups[%d].schema.fields must not have more than %d fields. Got %d", i, len(supportedSchemaFields), len(group.Schema.Fields))
+		}
+		seenFields := make(map[string]struct{}, len(group.Schema.Fields))
+		for _, field := range group.Schema.Fields {
+			if _, ok := supportedSchemaFields[field]; !ok {
+				return fmt.Errorf("price floors modelgroups[%d].schema.fields contains unsupported field %s", i, field)
+			}
+			if _, ok := seenFields[field]; ok {
+				return fmt.Errorf("price floors modelgroups[%d].schema.fields contains duplicate field %s", i, field)
+			}
+			seenFields[field] = struct{}{}
+		}
+
+		delimiter := getDelimiter(group.Schema)
+		for rule, value := range group.Values {
+			if parts := strings.Split(rule, delimiter); len(parts) != len(group.Schema.Fields) {
+				return fmt.Errorf("price floors modelgroups[%d].values rule %s does not match the %d schema fields", i, rule, len(group.Schema.Fields))
+			}
+			if value < 0 {
+				return fmt.Errorf("price floors modelgroups[%d].values rule %s must have a positive floor. Got %f", i, rule, value)
+			}
+		}
+	}
+	return nil
+}
+
+// normalizeRuleValues lowercases the rule keys so that matching is case insensitive.
+func normalizeRuleValues(values map[string]float64) map[string]float64 {
+	normalized := make(map[string]float64, len(values))
+	for rule, value := range values {
+		normalized[strings.ToLower(rule)] = value
+	}
+	return normalized
+}
+
+// findRule returns the most specific rule which matches the field values. Rules with fewer wildcards
+// are more specific. Among rules with the same number of wildcards, a concrete value in an earlier
+// schema field wins. The masks are the wildcardMasks of the number of fields.
+func findRule(ruleValues map[string]float64, fieldValues []string, masks []uint, delimiter string) (string, float64, bool) {
+	for _, mask := range masks {
+		parts := make([]string, len(fieldValues))
+		for i, value := range fieldValues {
+			if mask&(1<<uint(i)) != 0 {
+				parts[i] = openrtb_ext.FloorSchemaWildcard
+			} else {
+				parts[i] = value
+			}
+		}
+		rule := strings.Join(parts, delimiter)
+		if value, ok := ruleValues[rule]; ok {
+			return rule, value, true
+		}
+	}
+	return "", 0, false
+}
+
+// wildcardMasks lists every combination of wildcard positions for n fields, ordered from most to least specific.
+// Bit i of a mask is set if field i is replaced by a wildcard.
+func wildcardMasks(n int) []uint {
+	masks := make([]uint, 1<<uint(n))
+	for i := range masks {
+		masks[i] = uint(i)
+	}
+	sort.Slice(masks, func(a, b int) bool {
+		countA, countB := bits.OnesCount(masks[a]), bits.OnesCount(masks[b])
+		if countA != countB {
+			return countA < countB
+		}
+		// The lowest differing bit is the earliest field where only one of the masks has a wildcard.
+		lowest := (masks[a] ^ masks[b]) & -(masks[a] ^ masks[b])
+		return masks[a]&lowest == 0
+	})
+	return masks
+}
+
+// getFieldValues reads the value of each schema field for the imp. Values which cannot be
+// determined are returned as a wildcard, so that only wildcard rules can match them.
+func getFieldValues(fields []string, request *openrtb2.BidRequest, imp *openrtb2.Imp) []string {
+	values := make([]string, len(fields))
+	for i, field := range fields {
+		var value string
+		switch field {
+		case openrtb_ext.FloorSchemaMediaType:
+			value = getMediaType(imp)
+		case openrtb_ext.FloorSchemaSize:
+			value = getSize(imp)
+		case openrtb_ext.FloorSchemaDomain, openrtb_ext.FloorSchemaSiteDomain:
+			value = getDomain(request)
+		case openrtb_ext.FloorSchemaPubDomain:
+			value = getPubDomain(request)
+		case openrtb_ext.FloorSchemaBundle:
+			if request.App != nil {
+				value = request.App.Bundle
+			}
+		case openrtb_ext.FloorSchemaGptSlot:
+			value = getGptSlot(imp)
+		case openrtb_ext.FloorSchemaPbAdSlot:
+			value, _ = jsonparser.GetString(imp.Ext, "data", "pbadslot")
+		case openrtb_ext.FloorSchemaCountry:
+			if request.Device != nil && request.Device.Geo != nil {
+				value = request.Device.Geo.Country
+			}
+		}
+
+		if value == "" {
+			value = openrtb_ext.FloorSchemaWildcard
+		}
+		values[i] = strings.ToLower(value)
+	}
+	return values
+}
+
+func getMediaType(imp *openrtb2.Imp) string {
+	var mediaType openrtb_ext.BidType
+	count := 0
+	if imp.Banner != nil {
+		mediaType = openrtb_ext.BidTypeBanner
+		count++
+	}
+	if imp.Video != nil {
+		mediaType = openrtb_ext.BidTypeVideo
+		count++
+	}
+	if imp.Audio != nil {
+		mediaType = openrtb_ext.BidTypeAudio
+		count++
+	}
+	if imp.Native != nil {
+		mediaType = openrtb_ext.BidTypeNative
+		count++
+	}
+	if count != 1 {
+		return ""
+	}
+	return string(mediaType)
+}
+
+func getSize(imp *openrtb2.Imp) string {
+	switch getMediaType(imp) {
+	case string(openrtb_ext.BidTypeBanner):
+		if len(imp.Banner.Format) == 1 {
+			return fmt.Sprintf("%dx%d", imp.Banner.Format[0].W, imp.Banner.Format[0].H)
+		}
+		if len(imp.Banner.Format) == 0 && imp.Banner.W != nil && imp.Banner.H != nil {
+			return fmt.Sprintf("%dx%d", *imp.Banner.W, *imp.Banner.H)
+		}
+	case string(openrtb_ext.BidTypeVideo):
+		if imp.Video.W > 0 && imp.Video.H > 0 {
+			return fmt.Sprintf("%dx%d", imp.Video.W, imp.Video.H)
+		}
+	}
+	return ""
+}
+
+func getDomain(request *openrtb2.BidRequest) string {
+	if request.Site != nil {
+		return request.Site.Domain
+	}
+	if request.App != nil {
+		return request.App.Domain
+	}
+	return ""
+}
+
+func getPubDomain(request *openrtb2.BidRequest) string {
+	if request.Site != nil && request.Site.Publisher != nil {
+		return request.Site.Publisher.Domain
+	}
+	if request.App != nil && request.App.Publisher != nil {
+		return request.App.Publisher.Domain
+	}
+	return ""
+}
+
+// getGptSlot reads imp.ext.data.adserver.adslot if the ad server is GAM, and falls back to imp.ext.data.pbadslot.
+func getGptSlot(imp *openrtb2.Imp) string {
+	if adServer, _ := jsonparser.GetString(imp.Ext, "data", "adserver", "name"); adServer == "gam" {
+		if adSlot, err := jsonparser.GetString(imp.Ext, "data", "adserver", "adslot"); err == nil {
+			return adSlot
+		}
+	}
+	pbAdSlot, _ := jsonparser.GetString(imp.Ext, "data", "pbadslot")
+	return pbAdSlot
+}
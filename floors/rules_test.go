@@ -0,0 +1,248 @@
+package floors
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWildcardMasksOrder(t *testing.T) {
+	// Field 0 is the most significant, so a wildcard in field 2 is preferred over a wildcard in field 0.
+	assert.Equal(t, []uint{0, 4, 2, 1, 6, 5, 3, 7}, wildcardMasks(3))
+}
+
+func TestFindRule(t *testing.T) {
+	ruleValues := normalizeRuleValues(map[string]float64{
+		"banner|300x250|www.website.com": 1.5,
+		"banner|300x250|*":               1.0,
+		"banner|*|www.website.com":       0.8,
+		"*|300x250|www.website.com":      0.7,
+		"*|*|*":                          0.1,
+	})
+
+	testCases := []struct {
+		description   string
+		fieldValues   []string
+		expectedRule  string
+		expectedValue float64
+		expectedFound bool
+	}{
+		{
+			description:   "Exact match",
+			fieldValues:   []string{"banner", "300x250", "www.website.com"},
+			expectedRule:  "banner|300x250|www.website.com",
+			expectedValue: 1.5,
+			expectedFound: true,
+		},
+		{
+			description:   "Wildcard in the last field is preferred",
+			fieldValues:   []string{"banner", "300x250", "www.other.com"},
+			expectedRule:  "banner|300x250|*",
+			expectedValue: 1.0,
+			expectedFound: true,
+		},
+		{
+			description:   "Wildcard in the middle field is preferred over the first field",
+			fieldValues:   []string{"banner", "728x90", "www.website.com"},
+			expectedRule:  "banner|*|www.website.com",
+			expectedValue: 0.8,
+			expectedFound: true,
+		},
+		{
+			description:   "Wildcard in the first field",
+			fieldValues:   []string{"video", "300x250", "www.website.com"},
+			expectedRule:  "*|300x250|www.website.com",
+			expectedValue: 0.7,
+			expectedFound: true,
+		},
+		{
+			description:   "Catch all",
+			fieldValues:   []string{"video", "640x480", "www.other.com"},
+			expectedRule:  "*|*|*",
+			expectedValue: 0.1,
+			expectedFound: true,
+		},
+	}
+
+	for _, test := range testCases {
+		rule, value, found := findRule(ruleValues, test.fieldValues, wildcardMasks(3), "|")
+		assert.Equal(t, test.expectedRule, rule, test.description)
+		assert.Equal(t, test.expectedValue, value, test.description)
+		assert.Equal(t, test.expectedFound, found, test.description)
+	}
+}
+
+func TestFindRuleNoMatch(t *testing.T) {
+	ruleValues := map[string]float64{"banner|300x250": 1.0}
+
+	_, _, found := findRule(ruleValues, []string{"video", "300x250"}, wildcardMasks(2), "|")
+	assert.False(t, found)
+}
+
+func TestGetFieldValues(t *testing.T) {
+	request := &openrtb2.BidRequest{
+		Site: &openrtb2.Site{
+			Domain:    "www.Website.com",
+			Publisher: &openrtb2.Publisher{Domain: "website.com"},
+		},
+		Device: &openrtb2.Device{Geo: &openrtb2.Geo{Country: "USA"}},
+	}
+	imp := &openrtb2.Imp{
+		Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 300, H: 250}}},
+		Ext:    json.RawMessage(`{"data":{"adserver":{"name":"gam","adslot":"/1111/homepage"},"pbadslot":"homepage-top"}}`),
+	}
+	fields := []string{
+		openrtb_ext.FloorSchemaMediaType,
+		openrtb_ext.FloorSchemaSize,
+		openrtb_ext.FloorSchemaDomain,
+		openrtb_ext.FloorSchemaPubDomain,
+		openrtb_ext.FloorSchemaBundle,
+		openrtb_ext.FloorSchemaGptSlot,
+		openrtb_ext.FloorSchemaPbAdSlot,
+		openrtb_ext.FloorSchemaCountry,
+	}
+
+	values := getFieldValues(fields, request, imp)
+
+	assert.Equal(t, []string{"banner", "300x250", "www.website.com", "website.com", "*", "/1111/homepage", "homepage-top", "usa"}, values)
+}
+
+func TestGetMediaTypeAndSize(t *testing.T) {
+	w, h := int64(728), int64(90)
+
+	testCases := []struct {
+		description       string
+		imp               openrtb2.Imp
+		expectedMediaType string
+		expectedSize      string
+	}{
+		{
+			description:       "Banner with one format",
+			imp:               openrtb2.Imp{Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 300, H: 250}}}},
+			expectedMediaType: "banner",
+			expectedSize:      "300x250",
+		},
+		{
+			description:       "Banner with several formats",
+			imp:               openrtb2.Imp{Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 300, H: 250}, {W: 300, H: 600}}}},
+			expectedMediaType: "banner",
+			expectedSize:      "",
+		},
+		{
+			description:       "Banner with w and h",
+			imp:               openrtb2.Imp{Banner: &openrtb2.Banner{W: &w, H: &h}},
+			expectedMediaType: "banner",
+			expectedSize:      "728x90",
+		},
+		{
+			description:       "Video",
+			imp:               openrtb2.Imp{Video: &openrtb2.Video{W: 640, H: 480}},
+			expectedMediaType: "video",
+			expectedSize:      "640x480",
+		},
+		{
+			description:       "Multi format",
+			imp:               openrtb2.Imp{Banner: &openrtb2.Banner{}, Video: &openrtb2.Video{W: 640, H: 480}},
+			expectedMediaType: "",
+			expectedSize:      "",
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expectedMediaType, getMediaType(&test.imp), test.description)
+		assert.Equal(t, test.expectedSize, getSize(&test.imp), test.description)
+	}
+}
+
+func TestValidateRules(t *testing.T) {
+	invalidRate := 101
+
+	testCases := []struct {
+		description string
+		rules       *openrtb_ext.PriceFloorRules
+		expectedErr string
+	}{
+		{
+			description: "Nil rules",
+			rules:       nil,
+		},
+		{
+			description: "Valid rules",
+			rules: &openrtb_ext.PriceFloorRules{
+				Data: &openrtb_ext.PriceFloorData{
+					ModelGroups: []openrtb_ext.PriceFloorModelGroup{{
+						Schema: openrtb_ext.PriceFloorSchema{Fields: []string{"mediaType", "size"}, Delimiter: ","},
+						Values: map[string]float64{"banner,300x250": 1.0},
+					}},
+				},
+			},
+		},
+		{
+			description: "Negative floormin",
+			rules:       &openrtb_ext.PriceFloorRules{FloorMin: -1},
+			expectedErr: "price floors floormin must be positive. Got -1.000000",
+		},
+		{
+			description: "Invalid enforce rate",
+			rules:       &openrtb_ext.PriceFloorRules{Enforcement: &openrtb_ext.PriceFloorEnforcement{EnforceRate: &invalidRate}},
+			expectedErr: "price floors enforcement.enforcerate must be between 0 and 100. Got 101",
+		},
+		{
+			description: "Unsupported schema field",
+			rules: &openrtb_ext.PriceFloorRules{
+				Data: &openrtb_ext.PriceFloorData{
+					ModelGroups: []openrtb_ext.PriceFloorModelGroup{{
+						Schema: openrtb_ext.PriceFloorSchema{Fields: []string{"mediaType", "color"}},
+					}},
+				},
+			},
+			expectedErr: "price floors modelgroups[0].schema.fields contains unsupported field color",
+		},
+		{
+			description: "Duplicate schema field",
+			rules: &openrtb_ext.PriceFloorRules{
+				Data: &openrtb_ext.PriceFloorData{
+					ModelGroups: []openrtb_ext.PriceFloorModelGroup{{
+						Schema: openrtb_ext.PriceFloorSchema{Fields: []string{"mediaType", "size", "mediaType"}},
+					}},
+				},
+			},
+			expectedErr: "price floors modelgroups[0].schema.fields contains duplicate field mediaType",
+		},
+		{
+			description: "Too many schema fields",
+			rules: &openrtb_ext.PriceFloorRules{
+				Data: &openrtb_ext.PriceFloorData{
+					ModelGroups: []openrtb_ext.PriceFloorModelGroup{{
+						Schema: openrtb_ext.PriceFloorSchema{Fields: make([]string, 63)},
+					}},
+				},
+			},
+			expectedErr: "price floors modelgroups[0].schema.fields must not have more than 9 fields. Got 63",
+		},
+		{
+			description: "Rule does not match schema",
+			rules: &openrtb_ext.PriceFloorRules{
+				Data: &openrtb_ext.PriceFloorData{
+					ModelGroups: []openrtb_ext.PriceFloorModelGroup{{
+						Schema: openrtb_ext.PriceFloorSchema{Fields: []string{"mediaType", "size"}},
+						Values: map[string]float64{"banner": 1.0},
+					}},
+				},
+			},
+			expectedErr: "price floors modelgroups[0].values rule banner does not match the 2 schema fields",
+		},
+	}
+
+	for _, test := range testCases {
+		err := validateRules(test.rules)
+		if test.expectedErr == "" {
+			assert.NoError(t, err, test.description)
+		} else {
+			assert.EqualError(t, err, test.expectedErr, test.description)
+		}
+	}
+}
@@ -15,6 +15,8 @@ import (
 	"github.com/prebid/go-gdpr/vendorlist"
 	"github.com/prebid/go-gdpr/vendorlist2"
 	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/tracing"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
 	"golang.org/x/net/context/ctxhttp"
 )
 
@@ -103,32 +105,41 @@ func newOccasionalSaver(timeout time.Duration) func(ctx context.Context, client
 }
 
 func saveOne(ctx context.Context, client *http.Client, url string, saver saveVendors) uint16 {
+	ctx, span := tracing.StartSpan(ctx, "gdpr.FetchVendorList", semconv.HTTPURLKey.String(url))
+	defer span.End()
+
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		glog.Errorf("Failed to build GET %s request. Cookie syncs may be affected: %v", url, err)
+		tracing.SetError(span, err)
 		return 0
 	}
 
 	resp, err := ctxhttp.Do(ctx, client, req)
 	if err != nil {
 		glog.Errorf("Error calling GET %s. Cookie syncs may be affected: %v", url, err)
+		tracing.SetError(span, err)
 		return 0
 	}
 	defer resp.Body.Close()
+	span.SetAttributes(semconv.HTTPStatusCodeKey.Int(resp.StatusCode))
 
 	respBody, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		glog.Errorf("Error reading response body from GET %s. Cookie syncs may be affected: %v", url, err)
+		tracing.SetError(span, err)
 		return 0
 	}
 	if resp.StatusCode != http.StatusOK {
 		glog.Errorf("GET %s returned %d. Cookie syncs may be affected.", url, resp.StatusCode)
+		tracing.SetError(span, fmt.Errorf("GET %s returned %d", url, resp.StatusCode))
 		return 0
 	}
 	var newList api.VendorList
 	newList, err = vendorlist2.ParseEagerly(respBody)
 	if err != nil {
 		glog.Errorf("GET %s returned malformed JSON. Cookie syncs may be affected. Error was %v. Body was %s", url, err, string(respBody))
+		tracing.SetError(span, err)
 		return 0
 	}
 
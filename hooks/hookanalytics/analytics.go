@@ -0,0 +1,49 @@
+// Package hookanalytics defines the analytics tags which hooks may return to describe what they did,
+// and the outcome of the hook stages which is reported to the analytics modules and in the auction response.
+package hookanalytics
+
+// Analytics holds the activities a hook performed during a single invocation.
+type Analytics struct {
+	Activities []Activity `json:"activities"`
+}
+
+// Activity describes a single piece of work done by a hook, e.g. "device-enrichment".
+type Activity struct {
+	Name    string         `json:"name"`
+	Status  ActivityStatus `json:"status"`
+	Results []Result       `json:"results,omitempty"`
+}
+
+// Result describes the outcome of an activity, optionally restricted to parts of the request or response.
+type Result struct {
+	Status    ResultStatus           `json:"status,omitempty"`
+	Values    map[string]interface{} `json:"values,omitempty"`
+	AppliedTo AppliedTo              `json:"appliedto,omitempty"`
+}
+
+// AppliedTo lists the entities an activity result applies to.
+type AppliedTo struct {
+	ImpIds   []string `json:"impids,omitempty"`
+	Bidders  []string `json:"bidders,omitempty"`
+	BidIds   []string `json:"bidids,omitempty"`
+	Request  bool     `json:"request,omitempty"`
+	Response bool     `json:"response,omitempty"`
+}
+
+// ActivityStatus describes the allowed values of Activity.Status
+type ActivityStatus string
+
+const (
+	ActivityStatusSuccess ActivityStatus = "success"
+	ActivityStatusError   ActivityStatus = "error"
+)
+
+// ResultStatus describes the allowed values of Result.Status
+type ResultStatus string
+
+const (
+	ResultStatusAllow  ResultStatus = "success-allow"
+	ResultStatusBlock  ResultStatus = "success-block"
+	ResultStatusModify ResultStatus = "success-modify"
+	ResultStatusError  ResultStatus = "error"
+)
@@ -0,0 +1,67 @@
+package hookanalytics
+
+// Status describes how a hook invocation ended.
+type Status string
+
+const (
+	StatusSuccess Status = "success"
+	// StatusTimeout means the hook didn't complete within the timeout of its group. Its result is discarded.
+	StatusTimeout Status = "timeout"
+	// StatusFailure means the hook returned an error.
+	StatusFailure Status = "failure"
+	// StatusExecutionFailure means the hook panicked or returned a result which couldn't be applied.
+	StatusExecutionFailure Status = "execution_failure"
+)
+
+// Action describes what the executor did with the result of a successful hook invocation.
+type Action string
+
+const (
+	ActionUpdate Action = "update"
+	ActionNOP    Action = "no_action"
+	ActionReject Action = "reject"
+)
+
+// Entity describes what the payload of a stage outcome refers to.
+type Entity string
+
+const (
+	EntityHttpRequest      Entity = "http-request"
+	EntityAuctionRequest   Entity = "auction-request"
+	EntityAllProcessedBids Entity = "all-processed-bid-responses"
+	EntityAuctionResponse  Entity = "auction-response"
+)
+
+// StageOutcome is the result of running the execution plan of a stage once.
+// Bidder stages run once per bidder, in which case the entity is the bidder name.
+type StageOutcome struct {
+	Entity              Entity         `json:"entity"`
+	Stage               string         `json:"stage"`
+	ExecutionTimeMillis int            `json:"execution_time_millis"`
+	Groups              []GroupOutcome `json:"groups"`
+}
+
+// GroupOutcome holds the results of the hooks of a group.
+type GroupOutcome struct {
+	ExecutionTimeMillis int           `json:"execution_time_millis"`
+	InvocationResults   []HookOutcome `json:"invocation_results"`
+}
+
+// HookOutcome is the result of a single hook invocation.
+type HookOutcome struct {
+	HookID              HookID    `json:"hook_id"`
+	Status              Status    `json:"status"`
+	Action              Action    `json:"action,omitempty"`
+	Message             string    `json:"message,omitempty"`
+	DebugMessages       []string  `json:"debug_messages,omitempty"`
+	AnalyticsTags       Analytics `json:"analytics_tags"`
+	Errors              []string  `json:"-"`
+	Warnings            []string  `json:"-"`
+	ExecutionTimeMillis int       `json:"execution_time_millis"`
+}
+
+// HookID identifies the hook which produced an outcome.
+type HookID struct {
+	ModuleCode   string `json:"module_code"`
+	HookImplCode string `json:"hook_impl_code"`
+}
@@ -0,0 +1,27 @@
+package hookexecution
+
+import (
+	"fmt"
+
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/hooks/hookanalytics"
+)
+
+// RejectError is returned when a hook rejects the payload of a stage.
+type RejectError struct {
+	NBR   int
+	Hook  hookanalytics.HookID
+	Stage string
+}
+
+func (e *RejectError) Error() string {
+	return fmt.Sprintf("Module %s (hook: %s) rejected request with code %d at %s stage", e.Hook.ModuleCode, e.Hook.HookImplCode, e.NBR, e.Stage)
+}
+
+func (e *RejectError) Code() int {
+	return errortypes.ModuleRejectionErrorCode
+}
+
+func (e *RejectError) Severity() errortypes.Severity {
+	return errortypes.SeverityFatal
+}
@@ -0,0 +1,179 @@
+package hookexecution
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/prebid/prebid-server/hooks"
+	"github.com/prebid/prebid-server/hooks/hookanalytics"
+	"github.com/prebid/prebid-server/hooks/hookstage"
+)
+
+// hookInvoker calls the hook with the stage payload. It is provided by each stage, as the hook interface differs per stage.
+type hookInvoker func(ctx context.Context, hook interface{}, miCtx hookstage.ModuleInvocationContext, payload interface{}) (hookstage.HookResult, error)
+
+type hookResponse struct {
+	result    hookstage.HookResult
+	err       error
+	recovered interface{}
+	elapsed   time.Duration
+}
+
+// executeStage runs the groups of the plan one after the other, applying the mutations of each group
+// before the next one runs. It stops at the first group in which a hook rejects the payload.
+func (e *hookExecutor) executeStage(stage hookstage.Stage, entity hookanalytics.Entity, plan hooks.Plan, payload interface{}, invoke hookInvoker) (interface{}, *RejectError) {
+	if len(plan) == 0 {
+		return payload, nil
+	}
+
+	start := time.Now()
+	outcome := hookanalytics.StageOutcome{
+		Entity: entity,
+		Stage:  stage.String(),
+		Groups: make([]hookanalytics.GroupOutcome, 0, len(plan)),
+	}
+	defer func() {
+		outcome.ExecutionTimeMillis = int(time.Since(start) / time.Millisecond)
+		e.pushStageOutcome(outcome)
+	}()
+
+	for _, group := range plan {
+		groupOutcome, newPayload, rejectErr := e.executeGroup(stage, group, payload, invoke)
+		outcome.Groups = append(outcome.Groups, groupOutcome)
+		if rejectErr != nil {
+			return payload, rejectErr
+		}
+		payload = newPayload
+	}
+
+	return payload, nil
+}
+
+func (e *hookExecutor) executeGroup(stage hookstage.Stage, group hooks.Group, payload interface{}, invoke hookInvoker) (hookanalytics.GroupOutcome, interface{}, *RejectError) {
+	start := time.Now()
+	deadline := start.Add(group.Timeout)
+
+	responseChannels := make([]chan hookResponse, len(group.Hooks))
+	for i, hw := range group.Hooks {
+		responseChannels[i] = make(chan hookResponse, 1)
+		go runHook(deadline, hw, e.invocationContext(hw.Module), payload, invoke, responseChannels[i])
+	}
+
+	outcomes := make([]hookanalytics.HookOutcome, len(group.Hooks))
+	results := make([]*hookstage.HookResult, len(group.Hooks))
+	var rejectErr *RejectError
+	for i, hw := range group.Hooks {
+		outcomes[i].HookID = hookanalytics.HookID{ModuleCode: hw.Module, HookImplCode: hw.Code}
+
+		var response hookResponse
+		timer := time.NewTimer(time.Until(deadline))
+		select {
+		case response = <-responseChannels[i]:
+			timer.Stop()
+		case <-timer.C:
+			outcomes[i].Status = hookanalytics.StatusTimeout
+			outcomes[i].ExecutionTimeMillis = int(group.Timeout / time.Millisecond)
+			continue
+		}
+
+		outcomes[i].ExecutionTimeMillis = int(response.elapsed / time.Millisecond)
+		if response.recovered != nil {
+			outcomes[i].Status = hookanalytics.StatusExecutionFailure
+			outcomes[i].Message = fmt.Sprintf("hook panicked: %v", response.recovered)
+			continue
+		}
+		if response.err != nil {
+			outcomes[i].Status = hookanalytics.StatusFailure
+			outcomes[i].Message = response.err.Error()
+			continue
+		}
+
+		result := response.result
+		outcomes[i].Status = hookanalytics.StatusSuccess
+		outcomes[i].AnalyticsTags = result.AnalyticsTags
+		outcomes[i].DebugMessages = result.DebugMessages
+		outcomes[i].Errors = result.Errors
+		outcomes[i].Warnings = result.Warnings
+		e.saveModuleContext(hw.Module, result.ModuleContext)
+
+		if result.Reject {
+			if !stage.IsRejectable() {
+				outcomes[i].Status = hookanalytics.StatusExecutionFailure
+				outcomes[i].Message = fmt.Sprintf("hook tried to reject the payload at the %s stage, which is not rejectable", stage)
+				continue
+			}
+			outcomes[i].Action = hookanalytics.ActionReject
+			if rejectErr == nil {
+				rejectErr = &RejectError{NBR: result.NbrCode, Hook: outcomes[i].HookID, Stage: stage.String()}
+			}
+			continue
+		}
+		results[i] = &result
+	}
+
+	if rejectErr == nil {
+		payload = applyMutations(payload, results, outcomes)
+	}
+
+	groupOutcome := hookanalytics.GroupOutcome{
+		ExecutionTimeMillis: int(time.Since(start) / time.Millisecond),
+		InvocationResults:   outcomes,
+	}
+	return groupOutcome, payload, rejectErr
+}
+
+func runHook(deadline time.Time, hw hooks.HookWrapper, miCtx hookstage.ModuleInvocationContext, payload interface{}, invoke hookInvoker, responseChan chan<- hookResponse) {
+	start := time.Now()
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			responseChan <- hookResponse{recovered: r, elapsed: time.Since(start)}
+		}
+	}()
+
+	result, err := invoke(ctx, hw.Hook, miCtx, payload)
+	responseChan <- hookResponse{result: result, err: err, elapsed: time.Since(start)}
+}
+
+// applyMutations applies the change sets of the successful hooks in the order of the group.
+// The mutations of a hook are discarded if any of them fails.
+func applyMutations(payload interface{}, results []*hookstage.HookResult, outcomes []hookanalytics.HookOutcome) interface{} {
+	for i, result := range results {
+		if result == nil {
+			continue
+		}
+
+		mutations := result.ChangeSet.Mutations()
+		if len(mutations) == 0 {
+			outcomes[i].Action = hookanalytics.ActionNOP
+			continue
+		}
+
+		hookPayload, err := applyHookMutations(payload, mutations)
+		if err != nil {
+			outcomes[i].Status = hookanalytics.StatusExecutionFailure
+			outcomes[i].Message = err.Error()
+			continue
+		}
+		outcomes[i].Action = hookanalytics.ActionUpdate
+		payload = hookPayload
+	}
+	return payload
+}
+
+func applyHookMutations(payload interface{}, mutations []hookstage.Mutation) (interface{}, error) {
+	for _, mutation := range mutations {
+		mutated, err := mutation.Apply(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply %s mutation of %s: %v", mutation.Type(), mutation.Key(), err)
+		}
+		if reflect.TypeOf(mutated) != reflect.TypeOf(payload) {
+			return nil, fmt.Errorf("%s mutation of %s returned a payload of type %T instead of %T", mutation.Type(), mutation.Key(), mutated, payload)
+		}
+		payload = mutated
+	}
+	return payload, nil
+}
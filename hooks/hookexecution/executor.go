@@ -0,0 +1,251 @@
+// Package hookexecution runs the hooks of the execution plans at each stage of a request
+// and records the outcome of every invocation.
+package hookexecution
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/hooks"
+	"github.com/prebid/prebid-server/hooks/hookanalytics"
+	"github.com/prebid/prebid-server/hooks/hookstage"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+const (
+	EndpointAuction = "/openrtb2/auction"
+	EndpointAmp     = "/openrtb2/amp"
+	EndpointVideo   = "/openrtb2/video"
+)
+
+// StageExecutor runs the hooks of each stage for a single request.
+//
+// Mutations of pointer payloads are applied in place, so callers keep using the request or response they passed in.
+// A RejectError is returned if a hook rejected the payload, in which case no mutations of the rejecting group are applied.
+type StageExecutor interface {
+	ExecuteEntrypointStage(req *http.Request, body []byte) ([]byte, *RejectError)
+	ExecuteRawAuctionStage(body []byte) ([]byte, *RejectError)
+	ExecuteProcessedAuctionStage(req *openrtb2.BidRequest) *RejectError
+	ExecuteBidderRequestStage(req *openrtb2.BidRequest, bidder string) *RejectError
+	ExecuteRawBidderResponseStage(bids []*adapters.TypedBid, bidder string) ([]*adapters.TypedBid, *RejectError)
+	ExecuteAllProcessedBidResponsesStage(responses map[openrtb_ext.BidderName]*adapters.BidderResponse) map[openrtb_ext.BidderName]*adapters.BidderResponse
+	ExecuteAuctionResponseStage(response *openrtb2.BidResponse)
+}
+
+// HookStageExecutor is a StageExecutor which also tracks the account of the request and the outcomes of the stages.
+type HookStageExecutor interface {
+	StageExecutor
+	// SetAccount makes the account execution plan apply to the stages run afterwards.
+	SetAccount(account *config.Account)
+	// GetOutcomes returns the outcome of every stage which ran at least one hook, in the order they ran.
+	GetOutcomes() []hookanalytics.StageOutcome
+}
+
+// NewHookExecutor creates an executor for a request received by the endpoint.
+func NewHookExecutor(builder hooks.ExecutionPlanBuilder, endpoint string) HookStageExecutor {
+	return &hookExecutor{
+		planBuilder:    builder,
+		endpoint:       endpoint,
+		moduleContexts: make(map[string]hookstage.ModuleContext),
+	}
+}
+
+type hookExecutor struct {
+	planBuilder hooks.ExecutionPlanBuilder
+	endpoint    string
+	account     *config.Account
+
+	// Bidder stages run concurrently, so the state below is guarded by the mutex.
+	mutex          sync.Mutex
+	moduleContexts map[string]hookstage.ModuleContext
+	stageOutcomes  []hookanalytics.StageOutcome
+}
+
+func (e *hookExecutor) SetAccount(account *config.Account) {
+	e.account = account
+}
+
+func (e *hookExecutor) GetOutcomes() []hookanalytics.StageOutcome {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.stageOutcomes
+}
+
+func (e *hookExecutor) ExecuteEntrypointStage(req *http.Request, body []byte) ([]byte, *RejectError) {
+	plan := e.planBuilder.PlanForStage(e.endpoint, hookstage.StageEntrypoint, nil)
+	invoke := func(ctx context.Context, hook interface{}, miCtx hookstage.ModuleInvocationContext, payload interface{}) (hookstage.HookResult, error) {
+		return hook.(hookstage.Entrypoint).HandleEntrypointHook(ctx, miCtx, payload.(hookstage.EntrypointPayload))
+	}
+
+	payload, rejectErr := e.executeStage(hookstage.StageEntrypoint, hookanalytics.EntityHttpRequest, plan, hookstage.EntrypointPayload{Request: req, Body: body}, invoke)
+	return payload.(hookstage.EntrypointPayload).Body, rejectErr
+}
+
+func (e *hookExecutor) ExecuteRawAuctionStage(body []byte) ([]byte, *RejectError) {
+	plan := e.planBuilder.PlanForStage(e.endpoint, hookstage.StageRawAuctionRequest, nil)
+	invoke := func(ctx context.Context, hook interface{}, miCtx hookstage.ModuleInvocationContext, payload interface{}) (hookstage.HookResult, error) {
+		return hook.(hookstage.RawAuctionRequest).HandleRawAuctionHook(ctx, miCtx, payload.(hookstage.RawAuctionRequestPayload))
+	}
+
+	payload, rejectErr := e.executeStage(hookstage.StageRawAuctionRequest, hookanalytics.EntityAuctionRequest, plan, hookstage.RawAuctionRequestPayload(body), invoke)
+	return payload.(hookstage.RawAuctionRequestPayload), rejectErr
+}
+
+func (e *hookExecutor) ExecuteProcessedAuctionStage(req *openrtb2.BidRequest) *RejectError {
+	plan := e.planBuilder.PlanForStage(e.endpoint, hookstage.StageProcessedAuctionRequest, e.account)
+	invoke := func(ctx context.Context, hook interface{}, miCtx hookstage.ModuleInvocationContext, payload interface{}) (hookstage.HookResult, error) {
+		return hook.(hookstage.ProcessedAuctionRequest).HandleProcessedAuctionHook(ctx, miCtx, payload.(hookstage.ProcessedAuctionRequestPayload))
+	}
+
+	payload, rejectErr := e.executeStage(hookstage.StageProcessedAuctionRequest, hookanalytics.EntityAuctionRequest, plan, hookstage.ProcessedAuctionRequestPayload{BidRequest: req}, invoke)
+	if mutated := payload.(hookstage.ProcessedAuctionRequestPayload).BidRequest; mutated != nil && mutated != req {
+		*req = *mutated
+	}
+	return rejectErr
+}
+
+func (e *hookExecutor) ExecuteBidderRequestStage(req *openrtb2.BidRequest, bidder string) *RejectError {
+	plan := e.planBuilder.PlanForStage(e.endpoint, hookstage.StageBidderRequest, e.account)
+	invoke := func(ctx context.Context, hook interface{}, miCtx hookstage.ModuleInvocationContext, payload interface{}) (hookstage.HookResult, error) {
+		return hook.(hookstage.BidderRequest).HandleBidderRequestHook(ctx, miCtx, payload.(hookstage.BidderRequestPayload))
+	}
+
+	payload, rejectErr := e.executeStage(hookstage.StageBidderRequest, hookanalytics.Entity(bidder), plan, hookstage.BidderRequestPayload{BidRequest: req, Bidder: bidder}, invoke)
+	if mutated := payload.(hookstage.BidderRequestPayload).BidRequest; mutated != nil && mutated != req {
+		*req = *mutated
+	}
+	return rejectErr
+}
+
+func (e *hookExecutor) ExecuteRawBidderResponseStage(bids []*adapters.TypedBid, bidder string) ([]*adapters.TypedBid, *RejectError) {
+	plan := e.planBuilder.PlanForStage(e.endpoint, hookstage.StageRawBidderResponse, e.account)
+	invoke := func(ctx context.Context, hook interface{}, miCtx hookstage.ModuleInvocationContext, payload interface{}) (hookstage.HookResult, error) {
+		return hook.(hookstage.RawBidderResponse).HandleRawBidderResponseHook(ctx, miCtx, payload.(hookstage.RawBidderResponsePayload))
+	}
+
+	payload, rejectErr := e.executeStage(hookstage.StageRawBidderResponse, hookanalytics.Entity(bidder), plan, hookstage.RawBidderResponsePayload{Bids: bids, Bidder: bidder}, invoke)
+	return payload.(hookstage.RawBidderResponsePayload).Bids, rejectErr
+}
+
+func (e *hookExecutor) ExecuteAllProcessedBidResponsesStage(responses map[openrtb_ext.BidderName]*adapters.BidderResponse) map[openrtb_ext.BidderName]*adapters.BidderResponse {
+	plan := e.planBuilder.PlanForStage(e.endpoint, hookstage.StageAllProcessedBidResponses, e.account)
+	invoke := func(ctx context.Context, hook interface{}, miCtx hookstage.ModuleInvocationContext, payload interface{}) (hookstage.HookResult, error) {
+		return hook.(hookstage.AllProcessedBidResponses).HandleAllProcessedBidResponsesHook(ctx, miCtx, payload.(hookstage.AllProcessedBidResponsesPayload))
+	}
+
+	payload, _ := e.executeStage(hookstage.StageAllProcessedBidResponses, hookanalytics.EntityAllProcessedBids, plan, hookstage.AllProcessedBidResponsesPayload{Responses: responses}, invoke)
+	return payload.(hookstage.AllProcessedBidResponsesPayload).Responses
+}
+
+func (e *hookExecutor) ExecuteAuctionResponseStage(response *openrtb2.BidResponse) {
+	plan := e.planBuilder.PlanForStage(e.endpoint, hookstage.StageAuctionResponse, e.account)
+	invoke := func(ctx context.Context, hook interface{}, miCtx hookstage.ModuleInvocationContext, payload interface{}) (hookstage.HookResult, error) {
+		return hook.(hookstage.AuctionResponse).HandleAuctionResponseHook(ctx, miCtx, payload.(hookstage.AuctionResponsePayload))
+	}
+
+	payload, _ := e.executeStage(hookstage.StageAuctionResponse, hookanalytics.EntityAuctionResponse, plan, hookstage.AuctionResponsePayload{BidResponse: response}, invoke)
+	if mutated := payload.(hookstage.AuctionResponsePayload).BidResponse; mutated != nil && mutated != response {
+		*response = *mutated
+	}
+}
+
+// invocationContext builds the context passed to a hook of the module. The module context is copied,
+// as hooks of the same module may run concurrently for different bidders.
+func (e *hookExecutor) invocationContext(moduleCode string) hookstage.ModuleInvocationContext {
+	miCtx := hookstage.ModuleInvocationContext{
+		Endpoint:      e.endpoint,
+		AccountConfig: e.accountModuleConfig(moduleCode),
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if moduleCtx, ok := e.moduleContexts[moduleCode]; ok {
+		miCtx.ModuleContext = make(hookstage.ModuleContext, len(moduleCtx))
+		for k, v := range moduleCtx {
+			miCtx.ModuleContext[k] = v
+		}
+	}
+	return miCtx
+}
+
+// accountModuleConfig returns the account configuration of the module. Module codes have the form "vendor.module".
+func (e *hookExecutor) accountModuleConfig(moduleCode string) json.RawMessage {
+	if e.account == nil {
+		return nil
+	}
+	codeParts := strings.SplitN(moduleCode, ".", 2)
+	if len(codeParts) != 2 {
+		return nil
+	}
+	moduleCfg, ok := e.account.Hooks.Modules[codeParts[0]][codeParts[1]]
+	if !ok {
+		return nil
+	}
+	cfg, err := json.Marshal(moduleCfg)
+	if err != nil {
+		return nil
+	}
+	return cfg
+}
+
+func (e *hookExecutor) saveModuleContext(moduleCode string, moduleCtx hookstage.ModuleContext) {
+	if len(moduleCtx) == 0 {
+		return
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if _, ok := e.moduleContexts[moduleCode]; !ok {
+		e.moduleContexts[moduleCode] = make(hookstage.ModuleContext, len(moduleCtx))
+	}
+	for k, v := range moduleCtx {
+		e.moduleContexts[moduleCode][k] = v
+	}
+}
+
+func (e *hookExecutor) pushStageOutcome(outcome hookanalytics.StageOutcome) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.stageOutcomes = append(e.stageOutcomes, outcome)
+}
+
+// EmptyHookExecutor is used where hooks don't apply. It runs no hooks and leaves every payload untouched.
+type EmptyHookExecutor struct{}
+
+func (EmptyHookExecutor) SetAccount(account *config.Account) {}
+
+func (EmptyHookExecutor) GetOutcomes() []hookanalytics.StageOutcome {
+	return nil
+}
+
+func (EmptyHookExecutor) ExecuteEntrypointStage(req *http.Request, body []byte) ([]byte, *RejectError) {
+	return body, nil
+}
+
+func (EmptyHookExecutor) ExecuteRawAuctionStage(body []byte) ([]byte, *RejectError) {
+	return body, nil
+}
+
+func (EmptyHookExecutor) ExecuteProcessedAuctionStage(req *openrtb2.BidRequest) *RejectError {
+	return nil
+}
+
+func (EmptyHookExecutor) ExecuteBidderRequestStage(req *openrtb2.BidRequest, bidder string) *RejectError {
+	return nil
+}
+
+func (EmptyHookExecutor) ExecuteRawBidderResponseStage(bids []*adapters.TypedBid, bidder string) ([]*adapters.TypedBid, *RejectError) {
+	return bids, nil
+}
+
+func (EmptyHookExecutor) ExecuteAllProcessedBidResponsesStage(responses map[openrtb_ext.BidderName]*adapters.BidderResponse) map[openrtb_ext.BidderName]*adapters.BidderResponse {
+	return responses
+}
+
+func (EmptyHookExecutor) ExecuteAuctionResponseStage(response *openrtb2.BidResponse) {}
@@ -0,0 +1,247 @@
+package hookexecution
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/hooks"
+	"github.com/prebid/prebid-server/hooks/hookanalytics"
+	"github.com/prebid/prebid-server/hooks/hookstage"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakePlanBuilder struct {
+	plans map[hookstage.Stage]hooks.Plan
+}
+
+func (b fakePlanBuilder) PlanForStage(endpoint string, stage hookstage.Stage, account *config.Account) hooks.Plan {
+	return b.plans[stage]
+}
+
+// fakeRawAuctionHook runs the given function as its raw auction hook.
+type fakeRawAuctionHook func(miCtx hookstage.ModuleInvocationContext, payload hookstage.RawAuctionRequestPayload) (hookstage.HookResult, error)
+
+func (h fakeRawAuctionHook) HandleRawAuctionHook(ctx context.Context, miCtx hookstage.ModuleInvocationContext, payload hookstage.RawAuctionRequestPayload) (hookstage.HookResult, error) {
+	return h(miCtx, payload)
+}
+
+func replaceBody(body string) hookstage.HookResult {
+	result := hookstage.HookResult{}
+	result.ChangeSet.AddMutation(func(payload interface{}) (interface{}, error) {
+		return hookstage.RawAuctionRequestPayload(body), nil
+	}, hookstage.MutationUpdate, "body")
+	return result
+}
+
+func singleHookPlan(module string, hook interface{}, timeout time.Duration) hooks.Plan {
+	return hooks.Plan{{Timeout: timeout, Hooks: []hooks.HookWrapper{{Module: module, Code: "code", Hook: hook}}}}
+}
+
+func TestExecuteRawAuctionStage(t *testing.T) {
+	testCases := []struct {
+		description      string
+		plan             hooks.Plan
+		expectedBody     string
+		expectedReject   *RejectError
+		expectedStatuses []hookanalytics.Status
+		expectedActions  []hookanalytics.Action
+	}{
+		{
+			description:  "No hooks leaves the body untouched",
+			plan:         nil,
+			expectedBody: `{"id":"req"}`,
+		},
+		{
+			description: "Mutation is applied",
+			plan: singleHookPlan("vendor.mod", fakeRawAuctionHook(func(miCtx hookstage.ModuleInvocationContext, payload hookstage.RawAuctionRequestPayload) (hookstage.HookResult, error) {
+				return replaceBody(`{"id":"mutated"}`), nil
+			}), time.Second),
+			expectedBody:     `{"id":"mutated"}`,
+			expectedStatuses: []hookanalytics.Status{hookanalytics.StatusSuccess},
+			expectedActions:  []hookanalytics.Action{hookanalytics.ActionUpdate},
+		},
+		{
+			description: "Mutations of groups apply in order",
+			plan: append(
+				singleHookPlan("vendor.first", fakeRawAuctionHook(func(miCtx hookstage.ModuleInvocationContext, payload hookstage.RawAuctionRequestPayload) (hookstage.HookResult, error) {
+					return replaceBody(string(payload) + "-first"), nil
+				}), time.Second),
+				singleHookPlan("vendor.second", fakeRawAuctionHook(func(miCtx hookstage.ModuleInvocationContext, payload hookstage.RawAuctionRequestPayload) (hookstage.HookResult, error) {
+					return replaceBody(string(payload) + "-second"), nil
+				}), time.Second)...,
+			),
+			expectedBody:     `{"id":"req"}-first-second`,
+			expectedStatuses: []hookanalytics.Status{hookanalytics.StatusSuccess, hookanalytics.StatusSuccess},
+			expectedActions:  []hookanalytics.Action{hookanalytics.ActionUpdate, hookanalytics.ActionUpdate},
+		},
+		{
+			description: "Reject stops the stage and discards the mutations of the group",
+			plan: hooks.Plan{{Timeout: time.Second, Hooks: []hooks.HookWrapper{
+				{Module: "vendor.mutate", Code: "code", Hook: fakeRawAuctionHook(func(miCtx hookstage.ModuleInvocationContext, payload hookstage.RawAuctionRequestPayload) (hookstage.HookResult, error) {
+					return replaceBody(`{"id":"mutated"}`), nil
+				})},
+				{Module: "vendor.reject", Code: "code", Hook: fakeRawAuctionHook(func(miCtx hookstage.ModuleInvocationContext, payload hookstage.RawAuctionRequestPayload) (hookstage.HookResult, error) {
+					return hookstage.HookResult{Reject: true, NbrCode: 12}, nil
+				})},
+			}}},
+			expectedBody:     `{"id":"req"}`,
+			expectedReject:   &RejectError{NBR: 12, Hook: hookanalytics.HookID{ModuleCode: "vendor.reject", HookImplCode: "code"}, Stage: "raw-auction-request"},
+			expectedStatuses: []hookanalytics.Status{hookanalytics.StatusSuccess, hookanalytics.StatusSuccess},
+			expectedActions:  []hookanalytics.Action{"", hookanalytics.ActionReject},
+		},
+		{
+			description: "Hook timing out is ignored",
+			plan: singleHookPlan("vendor.mod", fakeRawAuctionHook(func(miCtx hookstage.ModuleInvocationContext, payload hookstage.RawAuctionRequestPayload) (hookstage.HookResult, error) {
+				time.Sleep(50 * time.Millisecond)
+				return replaceBody(`{"id":"mutated"}`), nil
+			}), time.Millisecond),
+			expectedBody:     `{"id":"req"}`,
+			expectedStatuses: []hookanalytics.Status{hookanalytics.StatusTimeout},
+			expectedActions:  []hookanalytics.Action{""},
+		},
+		{
+			description: "Hook returning an error is ignored",
+			plan: singleHookPlan("vendor.mod", fakeRawAuctionHook(func(miCtx hookstage.ModuleInvocationContext, payload hookstage.RawAuctionRequestPayload) (hookstage.HookResult, error) {
+				return replaceBody(`{"id":"mutated"}`), errors.New("failed")
+			}), time.Second),
+			expectedBody:     `{"id":"req"}`,
+			expectedStatuses: []hookanalytics.Status{hookanalytics.StatusFailure},
+			expectedActions:  []hookanalytics.Action{""},
+		},
+		{
+			description: "Hook panicking is recovered",
+			plan: singleHookPlan("vendor.mod", fakeRawAuctionHook(func(miCtx hookstage.ModuleInvocationContext, payload hookstage.RawAuctionRequestPayload) (hookstage.HookResult, error) {
+				panic("hook panic")
+			}), time.Second),
+			expectedBody:     `{"id":"req"}`,
+			expectedStatuses: []hookanalytics.Status{hookanalytics.StatusExecutionFailure},
+			expectedActions:  []hookanalytics.Action{""},
+		},
+		{
+			description: "Mutation returning the wrong payload type is discarded",
+			plan: singleHookPlan("vendor.mod", fakeRawAuctionHook(func(miCtx hookstage.ModuleInvocationContext, payload hookstage.RawAuctionRequestPayload) (hookstage.HookResult, error) {
+				result := hookstage.HookResult{}
+				result.ChangeSet.AddMutation(func(payload interface{}) (interface{}, error) {
+					return "not a payload", nil
+				}, hookstage.MutationUpdate, "body")
+				return result, nil
+			}), time.Second),
+			expectedBody:     `{"id":"req"}`,
+			expectedStatuses: []hookanalytics.Status{hookanalytics.StatusExecutionFailure},
+			expectedActions:  []hookanalytics.Action{""},
+		},
+	}
+
+	for _, test := range testCases {
+		executor := NewHookExecutor(fakePlanBuilder{plans: map[hookstage.Stage]hooks.Plan{hookstage.StageRawAuctionRequest: test.plan}}, EndpointAuction)
+
+		body, rejectErr := executor.ExecuteRawAuctionStage([]byte(`{"id":"req"}`))
+		assert.Equal(t, test.expectedBody, string(body), test.description)
+		assert.Equal(t, test.expectedReject, rejectErr, test.description)
+
+		var statuses []hookanalytics.Status
+		var actions []hookanalytics.Action
+		for _, stage := range executor.GetOutcomes() {
+			assert.Equal(t, "raw-auction-request", stage.Stage, test.description)
+			for _, group := range stage.Groups {
+				for _, hook := range group.InvocationResults {
+					statuses = append(statuses, hook.Status)
+					actions = append(actions, hook.Action)
+				}
+			}
+		}
+		assert.Equal(t, test.expectedStatuses, statuses, test.description)
+		assert.Equal(t, test.expectedActions, actions, test.description)
+	}
+}
+
+type fakeContextModule struct {
+	received hookstage.ModuleInvocationContext
+}
+
+func (m *fakeContextModule) HandleRawAuctionHook(ctx context.Context, miCtx hookstage.ModuleInvocationContext, payload hookstage.RawAuctionRequestPayload) (hookstage.HookResult, error) {
+	return hookstage.HookResult{ModuleContext: hookstage.ModuleContext{"raw": "seen"}}, nil
+}
+
+func (m *fakeContextModule) HandleProcessedAuctionHook(ctx context.Context, miCtx hookstage.ModuleInvocationContext, payload hookstage.ProcessedAuctionRequestPayload) (hookstage.HookResult, error) {
+	m.received = miCtx
+	result := hookstage.HookResult{}
+	result.ChangeSet.AddMutation(func(payload interface{}) (interface{}, error) {
+		p := payload.(hookstage.ProcessedAuctionRequestPayload)
+		req := *p.BidRequest
+		req.TMax = 500
+		p.BidRequest = &req
+		return p, nil
+	}, hookstage.MutationUpdate, "bidrequest", "tmax")
+	return result, nil
+}
+
+func TestModuleContextAndAccountConfig(t *testing.T) {
+	module := &fakeContextModule{}
+	executor := NewHookExecutor(fakePlanBuilder{plans: map[hookstage.Stage]hooks.Plan{
+		hookstage.StageRawAuctionRequest:       singleHookPlan("vendor.mod", module, time.Second),
+		hookstage.StageProcessedAuctionRequest: singleHookPlan("vendor.mod", module, time.Second),
+	}}, EndpointAuction)
+
+	_, rejectErr := executor.ExecuteRawAuctionStage([]byte(`{}`))
+	assert.Nil(t, rejectErr)
+
+	executor.SetAccount(&config.Account{Hooks: config.AccountHooks{Modules: map[string]map[string]interface{}{
+		"vendor": {"mod": map[string]interface{}{"enabled": true}},
+	}}})
+	req := &openrtb2.BidRequest{ID: "req", TMax: 1000}
+	rejectErr = executor.ExecuteProcessedAuctionStage(req)
+	assert.Nil(t, rejectErr)
+
+	assert.Equal(t, EndpointAuction, module.received.Endpoint)
+	assert.Equal(t, hookstage.ModuleContext{"raw": "seen"}, module.received.ModuleContext)
+	assert.JSONEq(t, `{"enabled":true}`, string(module.received.AccountConfig))
+	assert.Equal(t, &openrtb2.BidRequest{ID: "req", TMax: 500}, req, "Expected the mutation to apply to the request in place")
+	assert.Len(t, executor.GetOutcomes(), 2)
+}
+
+type fakeRejectingResponseHook struct{}
+
+func (fakeRejectingResponseHook) HandleAuctionResponseHook(ctx context.Context, miCtx hookstage.ModuleInvocationContext, payload hookstage.AuctionResponsePayload) (hookstage.HookResult, error) {
+	return hookstage.HookResult{Reject: true}, nil
+}
+
+func TestRejectAtNonRejectableStage(t *testing.T) {
+	executor := NewHookExecutor(fakePlanBuilder{plans: map[hookstage.Stage]hooks.Plan{
+		hookstage.StageAuctionResponse: singleHookPlan("vendor.mod", fakeRejectingResponseHook{}, time.Second),
+	}}, EndpointAuction)
+
+	resp := &openrtb2.BidResponse{ID: "resp"}
+	executor.ExecuteAuctionResponseStage(resp)
+
+	assert.Equal(t, &openrtb2.BidResponse{ID: "resp"}, resp)
+	outcomes := executor.GetOutcomes()
+	if assert.Len(t, outcomes, 1) {
+		assert.Equal(t, hookanalytics.StatusExecutionFailure, outcomes[0].Groups[0].InvocationResults[0].Status)
+	}
+}
+
+func TestEnrichExtBidResponse(t *testing.T) {
+	outcomes := []hookanalytics.StageOutcome{{
+		Entity: hookanalytics.EntityAuctionRequest,
+		Stage:  "raw-auction-request",
+		Groups: []hookanalytics.GroupOutcome{{InvocationResults: []hookanalytics.HookOutcome{{
+			HookID:   hookanalytics.HookID{ModuleCode: "vendor.mod", HookImplCode: "code"},
+			Status:   hookanalytics.StatusSuccess,
+			Errors:   []string{"some error"},
+			Warnings: []string{"some warning"},
+		}}}},
+	}}
+
+	ext, err := EnrichExtBidResponse([]byte(`{"prebid":{"auctiontimestamp":1}}`), outcomes, false)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"prebid":{"auctiontimestamp":1,"modules":{"errors":{"vendor.mod":{"code":["some error"]}},"warnings":{"vendor.mod":{"code":["some warning"]}}}}}`, string(ext))
+
+	ext, err = EnrichExtBidResponse(nil, nil, true)
+	assert.NoError(t, err)
+	assert.Nil(t, ext, "Expected the ext to be unchanged without outcomes")
+}
@@ -0,0 +1,89 @@
+package hookexecution
+
+import (
+	"encoding/json"
+
+	"github.com/prebid/prebid-server/hooks/hookanalytics"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// ModulesOutcome defines the contract for bidresponse.ext.prebid.modules
+type ModulesOutcome struct {
+	// Errors and Warnings are keyed by module code and then by hook implementation code.
+	Errors   map[string]map[string][]string `json:"errors,omitempty"`
+	Warnings map[string]map[string][]string `json:"warnings,omitempty"`
+	Trace    *TraceOutcome                  `json:"trace,omitempty"`
+}
+
+// TraceOutcome is the full outcome of the hook stages, only returned for debug requests.
+type TraceOutcome struct {
+	ExecutionTimeMillis int                          `json:"execution_time_millis"`
+	Stages              []hookanalytics.StageOutcome `json:"stages"`
+}
+
+// FindReject returns the first RejectError in the list, or nil if there is none.
+func FindReject(errs []error) *RejectError {
+	for _, err := range errs {
+		if rejectErr, ok := err.(*RejectError); ok {
+			return rejectErr
+		}
+	}
+	return nil
+}
+
+// EnrichExtBidResponse adds the errors and warnings returned by the hooks to bidresponse.ext.prebid.modules,
+// along with the trace of every stage if includeTrace is true. The ext is returned unchanged if there is nothing to add.
+func EnrichExtBidResponse(ext json.RawMessage, outcomes []hookanalytics.StageOutcome, includeTrace bool) (json.RawMessage, error) {
+	modules := ModulesOutcome{}
+	for _, stage := range outcomes {
+		for _, group := range stage.Groups {
+			for _, hook := range group.InvocationResults {
+				modules.Errors = appendHookMessages(modules.Errors, hook.HookID, hook.Errors)
+				modules.Warnings = appendHookMessages(modules.Warnings, hook.HookID, hook.Warnings)
+			}
+		}
+	}
+
+	if includeTrace && len(outcomes) > 0 {
+		modules.Trace = &TraceOutcome{Stages: outcomes}
+		for _, stage := range outcomes {
+			modules.Trace.ExecutionTimeMillis += stage.ExecutionTimeMillis
+		}
+	}
+
+	if modules.Errors == nil && modules.Warnings == nil && modules.Trace == nil {
+		return ext, nil
+	}
+
+	modulesJSON, err := json.Marshal(modules)
+	if err != nil {
+		return ext, err
+	}
+
+	var extResponse openrtb_ext.ExtBidResponse
+	if len(ext) > 0 {
+		if err := json.Unmarshal(ext, &extResponse); err != nil {
+			return ext, err
+		}
+	}
+	if extResponse.Prebid == nil {
+		extResponse.Prebid = &openrtb_ext.ExtResponsePrebid{}
+	}
+	extResponse.Prebid.Modules = modulesJSON
+
+	return json.Marshal(extResponse)
+}
+
+func appendHookMessages(messages map[string]map[string][]string, hookID hookanalytics.HookID, newMessages []string) map[string]map[string][]string {
+	if len(newMessages) == 0 {
+		return messages
+	}
+	if messages == nil {
+		messages = make(map[string]map[string][]string)
+	}
+	if messages[hookID.ModuleCode] == nil {
+		messages[hookID.ModuleCode] = make(map[string][]string)
+	}
+	messages[hookID.ModuleCode][hookID.HookImplCode] = append(messages[hookID.ModuleCode][hookID.HookImplCode], newMessages...)
+	return messages
+}
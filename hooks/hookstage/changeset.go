@@ -0,0 +1,68 @@
+package hookstage
+
+import (
+	"strings"
+)
+
+// MutationType describes the kind of change a mutation makes to the payload.
+type MutationType int
+
+const (
+	MutationUpdate MutationType = iota
+	MutationAdd
+	MutationDelete
+)
+
+func (t MutationType) String() string {
+	switch t {
+	case MutationUpdate:
+		return "update"
+	case MutationAdd:
+		return "add"
+	case MutationDelete:
+		return "delete"
+	}
+	return "unknown"
+}
+
+// MutationFunc receives the stage payload and returns the modified payload, which must be of the same type.
+type MutationFunc func(payload interface{}) (interface{}, error)
+
+// Mutation is a single change a hook wants to apply to the payload.
+type Mutation struct {
+	mutationType MutationType
+	key          []string
+	apply        MutationFunc
+}
+
+// Type returns the kind of change made by the mutation.
+func (m Mutation) Type() MutationType {
+	return m.mutationType
+}
+
+// Key returns the path of the payload field changed by the mutation, joined with dots.
+func (m Mutation) Key() string {
+	return strings.Join(m.key, ".")
+}
+
+// Apply runs the mutation on the payload.
+func (m Mutation) Apply(payload interface{}) (interface{}, error) {
+	return m.apply(payload)
+}
+
+// ChangeSet collects the mutations returned by a hook.
+type ChangeSet struct {
+	mutations []Mutation
+}
+
+// AddMutation adds a mutation to the change set. The key describes the payload field it changes
+// and is only used to report the mutation in the hook execution outcome.
+func (c *ChangeSet) AddMutation(fn MutationFunc, mutationType MutationType, key ...string) *ChangeSet {
+	c.mutations = append(c.mutations, Mutation{mutationType: mutationType, key: key, apply: fn})
+	return c
+}
+
+// Mutations returns the mutations of the change set, in the order they were added.
+func (c ChangeSet) Mutations() []Mutation {
+	return c.mutations
+}
@@ -0,0 +1,40 @@
+package hookstage
+
+import (
+	"encoding/json"
+
+	"github.com/prebid/prebid-server/hooks/hookanalytics"
+)
+
+// HookResult is returned by every hook invocation.
+//
+// Hooks must not modify the payload they receive. Instead, they describe their changes in the ChangeSet,
+// which is applied once all the hooks of the group have completed, unless one of them rejected the payload.
+type HookResult struct {
+	// Reject stops the processing of the payload. It is ignored at stages which aren't rejectable.
+	Reject bool
+	// NbrCode is the OpenRTB no-bid reason returned to the caller when the payload is rejected.
+	NbrCode   int
+	ChangeSet ChangeSet
+	// Errors and Warnings are reported in the response ext and the hook execution outcome.
+	Errors        []string
+	Warnings      []string
+	DebugMessages []string
+	AnalyticsTags hookanalytics.Analytics
+	// ModuleContext is merged into the context passed to the next hooks of the same module for this request.
+	ModuleContext ModuleContext
+}
+
+// ModuleInvocationContext holds the data passed to a hook on every invocation.
+type ModuleInvocationContext struct {
+	// AccountConfig is the configuration of the module for the account of the request,
+	// or nil if the account doesn't configure the module or isn't known yet.
+	AccountConfig json.RawMessage
+	// Endpoint is the path of the endpoint handling the request, e.g. "/openrtb2/auction".
+	Endpoint string
+	// ModuleContext holds the data stored by hooks of the same module earlier in the request.
+	ModuleContext ModuleContext
+}
+
+// ModuleContext lets the hooks of a module share data across the stages of a single request.
+type ModuleContext map[string]interface{}
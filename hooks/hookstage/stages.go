@@ -0,0 +1,112 @@
+// Package hookstage defines the stages of the auction which modules can hook into,
+// the payload each stage provides and the interface a hook must implement to run at that stage.
+package hookstage
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// Stage is the name of a point in the request lifecycle where hooks can run.
+type Stage string
+
+// Stages are listed in the order in which they run for an auction.
+const (
+	StageEntrypoint               Stage = "entrypoint"
+	StageRawAuctionRequest        Stage = "raw-auction-request"
+	StageProcessedAuctionRequest  Stage = "processed-auction-request"
+	StageBidderRequest            Stage = "bidder-request"
+	StageRawBidderResponse        Stage = "raw-bidder-response"
+	StageAllProcessedBidResponses Stage = "all-processed-bid-responses"
+	StageAuctionResponse          Stage = "auction-response"
+)
+
+func (s Stage) String() string {
+	return string(s)
+}
+
+// IsRejectable is true if hooks of the stage are allowed to reject the payload.
+func (s Stage) IsRejectable() bool {
+	return s != StageAllProcessedBidResponses && s != StageAuctionResponse
+}
+
+// Entrypoint hooks run as soon as the HTTP request is received, before the body is parsed.
+// The account is not known yet, so only the host execution plan applies.
+type Entrypoint interface {
+	HandleEntrypointHook(ctx context.Context, miCtx ModuleInvocationContext, payload EntrypointPayload) (HookResult, error)
+}
+
+// EntrypointPayload holds the incoming HTTP request and its body.
+// The request must not be modified; mutations may only change the body.
+type EntrypointPayload struct {
+	Request *http.Request
+	Body    []byte
+}
+
+// RawAuctionRequest hooks run on the body of the auction request before it is parsed
+// and before stored requests are merged into it.
+type RawAuctionRequest interface {
+	HandleRawAuctionHook(ctx context.Context, miCtx ModuleInvocationContext, payload RawAuctionRequestPayload) (HookResult, error)
+}
+
+// RawAuctionRequestPayload is the body of the auction request.
+type RawAuctionRequestPayload []byte
+
+// ProcessedAuctionRequest hooks run on the parsed and validated bid request, once the account is known.
+type ProcessedAuctionRequest interface {
+	HandleProcessedAuctionHook(ctx context.Context, miCtx ModuleInvocationContext, payload ProcessedAuctionRequestPayload) (HookResult, error)
+}
+
+// ProcessedAuctionRequestPayload holds the bid request which will be sent to the auction.
+type ProcessedAuctionRequestPayload struct {
+	BidRequest *openrtb2.BidRequest
+}
+
+// BidderRequest hooks run on the request of every bidder, before the adapter builds the HTTP calls.
+type BidderRequest interface {
+	HandleBidderRequestHook(ctx context.Context, miCtx ModuleInvocationContext, payload BidderRequestPayload) (HookResult, error)
+}
+
+// BidderRequestPayload holds the bid request prepared for a single bidder.
+type BidderRequestPayload struct {
+	BidRequest *openrtb2.BidRequest
+	Bidder     string
+}
+
+// RawBidderResponse hooks run on the bids of a bidder as soon as the adapter has parsed them,
+// before bid adjustments and currency conversion are applied.
+type RawBidderResponse interface {
+	HandleRawBidderResponseHook(ctx context.Context, miCtx ModuleInvocationContext, payload RawBidderResponsePayload) (HookResult, error)
+}
+
+// RawBidderResponsePayload holds the bids returned by a single bidder.
+type RawBidderResponsePayload struct {
+	Bids   []*adapters.TypedBid
+	Bidder string
+}
+
+// AllProcessedBidResponses hooks run once every bidder has responded and the bids have been
+// adjusted, converted and validated. Hooks of this stage can't reject the auction.
+type AllProcessedBidResponses interface {
+	HandleAllProcessedBidResponsesHook(ctx context.Context, miCtx ModuleInvocationContext, payload AllProcessedBidResponsesPayload) (HookResult, error)
+}
+
+// AllProcessedBidResponsesPayload holds the bids of every bidder, keyed by bidder name.
+type AllProcessedBidResponsesPayload struct {
+	Responses map[openrtb_ext.BidderName]*adapters.BidderResponse
+}
+
+// AuctionResponse hooks run on the bid response before it is returned to the caller.
+// Hooks of this stage can't reject the auction.
+type AuctionResponse interface {
+	HandleAuctionResponseHook(ctx context.Context, miCtx ModuleInvocationContext, payload AuctionResponsePayload) (HookResult, error)
+}
+
+// AuctionResponsePayload holds the bid response of the auction.
+type AuctionResponsePayload struct {
+	BidResponse *openrtb2.BidResponse
+}
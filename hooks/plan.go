@@ -0,0 +1,111 @@
+package hooks
+
+import (
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/hooks/hookstage"
+)
+
+// Plan is the ordered list of hook groups to run for a stage.
+// Groups run one after the other, while the hooks of a group run in parallel.
+type Plan []Group
+
+// Group is a set of hooks which run in parallel, each with the same timeout.
+type Group struct {
+	Timeout time.Duration
+	Hooks   []HookWrapper
+}
+
+// HookWrapper pairs a hook with the identifiers it was configured with.
+type HookWrapper struct {
+	Module string
+	Code   string
+	// Hook implements the hookstage interface of the stage the plan was built for.
+	Hook interface{}
+}
+
+// ExecutionPlanBuilder resolves the hooks which run at each stage of an endpoint.
+type ExecutionPlanBuilder interface {
+	// PlanForStage returns the host execution plan followed by the account execution plan.
+	// The account is nil for the stages which run before it is known.
+	PlanForStage(endpoint string, stage hookstage.Stage, account *config.Account) Plan
+}
+
+// NewExecutionPlanBuilder creates a builder for the plans configured in the host config.
+// Hooks of the host plans which aren't found in the repository are skipped.
+func NewExecutionPlanBuilder(cfg config.Hooks, repo HookRepository) ExecutionPlanBuilder {
+	builder := &executionPlanBuilder{
+		repo:              repo,
+		hostPlans:         make(map[string]map[hookstage.Stage]Plan),
+		defaultAccountCfg: cfg.DefaultAccountExecutionPlan,
+	}
+
+	for endpoint, endpointPlan := range cfg.HostExecutionPlan.Endpoints {
+		builder.hostPlans[endpoint] = make(map[hookstage.Stage]Plan, len(endpointPlan.Stages))
+		for stage, stagePlan := range endpointPlan.Stages {
+			builder.hostPlans[endpoint][hookstage.Stage(stage)] = builder.buildPlan(hookstage.Stage(stage), stagePlan.Groups, true)
+		}
+	}
+
+	return builder
+}
+
+type executionPlanBuilder struct {
+	repo              HookRepository
+	hostPlans         map[string]map[hookstage.Stage]Plan
+	defaultAccountCfg config.HookExecutionPlan
+}
+
+func (b *executionPlanBuilder) PlanForStage(endpoint string, stage hookstage.Stage, account *config.Account) Plan {
+	plan := b.hostPlans[endpoint][stage]
+	if account == nil {
+		return plan
+	}
+
+	accountCfg := b.defaultAccountCfg
+	if len(account.Hooks.ExecutionPlan.Endpoints) > 0 {
+		accountCfg = account.Hooks.ExecutionPlan
+	}
+	accountGroups := accountCfg.Endpoints[endpoint].Stages[string(stage)].Groups
+	if len(accountGroups) == 0 {
+		return plan
+	}
+
+	accountPlan := b.buildPlan(stage, accountGroups, false)
+	combined := make(Plan, 0, len(plan)+len(accountPlan))
+	combined = append(combined, plan...)
+	return append(combined, accountPlan...)
+}
+
+func (b *executionPlanBuilder) buildPlan(stage hookstage.Stage, groups []config.HookExecutionGroup, logMissing bool) Plan {
+	plan := make(Plan, 0, len(groups))
+	for _, groupCfg := range groups {
+		group := Group{
+			Timeout: time.Duration(groupCfg.Timeout) * time.Millisecond,
+			Hooks:   make([]HookWrapper, 0, len(groupCfg.HookSequence)),
+		}
+		for _, hookID := range groupCfg.HookSequence {
+			hook, ok := b.repo.GetHook(stage, hookID.ModuleCode)
+			if !ok {
+				if logMissing {
+					glog.Warningf("Hook %s of module %s is not available at stage %s and will be skipped", hookID.HookImplCode, hookID.ModuleCode, stage)
+				}
+				continue
+			}
+			group.Hooks = append(group.Hooks, HookWrapper{Module: hookID.ModuleCode, Code: hookID.HookImplCode, Hook: hook})
+		}
+		if len(group.Hooks) > 0 {
+			plan = append(plan, group)
+		}
+	}
+	return plan
+}
+
+// EmptyPlanBuilder is used when hooks are disabled. It never returns any hooks.
+type EmptyPlanBuilder struct{}
+
+func (EmptyPlanBuilder) PlanForStage(endpoint string, stage hookstage.Stage, account *config.Account) Plan {
+	return nil
+}
@@ -0,0 +1,127 @@
+package hooks
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/hooks/hookstage"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeEntrypointModule struct{}
+
+func (fakeEntrypointModule) HandleEntrypointHook(ctx context.Context, miCtx hookstage.ModuleInvocationContext, payload hookstage.EntrypointPayload) (hookstage.HookResult, error) {
+	return hookstage.HookResult{}, nil
+}
+
+type fakeResponseModule struct{}
+
+func (fakeResponseModule) HandleAuctionResponseHook(ctx context.Context, miCtx hookstage.ModuleInvocationContext, payload hookstage.AuctionResponsePayload) (hookstage.HookResult, error) {
+	return hookstage.HookResult{}, nil
+}
+
+func TestNewHookRepository(t *testing.T) {
+	_, err := NewHookRepository(map[string]interface{}{"vendor.invalid": struct{}{}})
+	assert.EqualError(t, err, "module vendor.invalid does not implement any hook stage")
+
+	repo, err := NewHookRepository(map[string]interface{}{"vendor.entrypoint": fakeEntrypointModule{}})
+	assert.NoError(t, err)
+
+	_, ok := repo.GetHook(hookstage.StageEntrypoint, "vendor.entrypoint")
+	assert.True(t, ok, "Expected the hook of an implemented stage")
+	_, ok = repo.GetHook(hookstage.StageAuctionResponse, "vendor.entrypoint")
+	assert.False(t, ok, "Expected no hook for a stage the module doesn't implement")
+	_, ok = repo.GetHook(hookstage.StageEntrypoint, "vendor.unknown")
+	assert.False(t, ok, "Expected no hook for an unknown module")
+}
+
+func TestPlanForStage(t *testing.T) {
+	repo, err := NewHookRepository(map[string]interface{}{
+		"vendor.entrypoint": fakeEntrypointModule{},
+		"vendor.response":   fakeResponseModule{},
+	})
+	assert.NoError(t, err)
+
+	hostCfg := config.Hooks{
+		Enabled: true,
+		HostExecutionPlan: config.HookExecutionPlan{Endpoints: map[string]config.HookEndpointPlan{
+			"/openrtb2/auction": {Stages: map[string]config.HookStagePlan{
+				"entrypoint": {Groups: []config.HookExecutionGroup{
+					{Timeout: 5, HookSequence: []config.HookID{{ModuleCode: "vendor.entrypoint", HookImplCode: "host-entrypoint"}}},
+				}},
+				"auction-response": {Groups: []config.HookExecutionGroup{
+					{Timeout: 10, HookSequence: []config.HookID{
+						{ModuleCode: "vendor.response", HookImplCode: "host-response"},
+						{ModuleCode: "vendor.entrypoint", HookImplCode: "not-a-response-hook"},
+					}},
+				}},
+			}},
+		}},
+		DefaultAccountExecutionPlan: config.HookExecutionPlan{Endpoints: map[string]config.HookEndpointPlan{
+			"/openrtb2/auction": {Stages: map[string]config.HookStagePlan{
+				"auction-response": {Groups: []config.HookExecutionGroup{
+					{Timeout: 20, HookSequence: []config.HookID{{ModuleCode: "vendor.response", HookImplCode: "default-response"}}},
+				}},
+			}},
+		}},
+	}
+	accountPlan := config.HookExecutionPlan{Endpoints: map[string]config.HookEndpointPlan{
+		"/openrtb2/auction": {Stages: map[string]config.HookStagePlan{
+			"auction-response": {Groups: []config.HookExecutionGroup{
+				{Timeout: 30, HookSequence: []config.HookID{{ModuleCode: "vendor.response", HookImplCode: "account-response"}}},
+			}},
+		}},
+	}}
+
+	testCases := []struct {
+		description  string
+		endpoint     string
+		stage        hookstage.Stage
+		account      *config.Account
+		expectedPlan Plan
+	}{
+		{
+			description: "Host plan only when the account isn't known",
+			endpoint:    "/openrtb2/auction",
+			stage:       hookstage.StageEntrypoint,
+			expectedPlan: Plan{
+				{Timeout: 5 * time.Millisecond, Hooks: []HookWrapper{{Module: "vendor.entrypoint", Code: "host-entrypoint", Hook: fakeEntrypointModule{}}}},
+			},
+		},
+		{
+			description: "Host plan followed by the default account plan, skipping hooks the module doesn't implement",
+			endpoint:    "/openrtb2/auction",
+			stage:       hookstage.StageAuctionResponse,
+			account:     &config.Account{},
+			expectedPlan: Plan{
+				{Timeout: 10 * time.Millisecond, Hooks: []HookWrapper{{Module: "vendor.response", Code: "host-response", Hook: fakeResponseModule{}}}},
+				{Timeout: 20 * time.Millisecond, Hooks: []HookWrapper{{Module: "vendor.response", Code: "default-response", Hook: fakeResponseModule{}}}},
+			},
+		},
+		{
+			description: "Account plan replaces the default account plan",
+			endpoint:    "/openrtb2/auction",
+			stage:       hookstage.StageAuctionResponse,
+			account:     &config.Account{Hooks: config.AccountHooks{ExecutionPlan: accountPlan}},
+			expectedPlan: Plan{
+				{Timeout: 10 * time.Millisecond, Hooks: []HookWrapper{{Module: "vendor.response", Code: "host-response", Hook: fakeResponseModule{}}}},
+				{Timeout: 30 * time.Millisecond, Hooks: []HookWrapper{{Module: "vendor.response", Code: "account-response", Hook: fakeResponseModule{}}}},
+			},
+		},
+		{
+			description:  "No plan for other endpoints",
+			endpoint:     "/openrtb2/amp",
+			stage:        hookstage.StageAuctionResponse,
+			account:      &config.Account{},
+			expectedPlan: nil,
+		},
+	}
+
+	builder := NewExecutionPlanBuilder(hostCfg, repo)
+	for _, test := range testCases {
+		plan := builder.PlanForStage(test.endpoint, test.stage, test.account)
+		assert.Equal(t, test.expectedPlan, plan, test.description)
+	}
+}
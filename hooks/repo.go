@@ -0,0 +1,83 @@
+// Package hooks lets modules plug custom logic into the stages of an auction.
+//
+// Modules are built at startup and registered in a HookRepository. An ExecutionPlanBuilder then
+// resolves, for every endpoint and stage, which hooks run and in which order, using the host execution plan
+// followed by the execution plan of the account. The plans are run by the hookexecution package.
+package hooks
+
+import (
+	"fmt"
+
+	"github.com/prebid/prebid-server/hooks/hookstage"
+)
+
+// HookRepository provides access to the modules registered with Prebid Server.
+type HookRepository interface {
+	// GetHook returns the hook which the module provides for the stage.
+	// The boolean is false if the module doesn't exist or has no hook for the stage.
+	GetHook(stage hookstage.Stage, moduleCode string) (interface{}, bool)
+}
+
+// NewHookRepository creates a repository for the modules, keyed by module code.
+// Every module must implement the interface of at least one stage.
+func NewHookRepository(modules map[string]interface{}) (HookRepository, error) {
+	repo := hookRepository{}
+	for code, module := range modules {
+		if len(implementedStages(module)) == 0 {
+			return nil, fmt.Errorf("module %s does not implement any hook stage", code)
+		}
+		repo[code] = module
+	}
+	return repo, nil
+}
+
+type hookRepository map[string]interface{}
+
+func (r hookRepository) GetHook(stage hookstage.Stage, moduleCode string) (interface{}, bool) {
+	module, ok := r[moduleCode]
+	if !ok || !implementsStage(module, stage) {
+		return nil, false
+	}
+	return module, true
+}
+
+func implementedStages(module interface{}) []hookstage.Stage {
+	var stages []hookstage.Stage
+	for _, stage := range allStages {
+		if implementsStage(module, stage) {
+			stages = append(stages, stage)
+		}
+	}
+	return stages
+}
+
+var allStages = []hookstage.Stage{
+	hookstage.StageEntrypoint,
+	hookstage.StageRawAuctionRequest,
+	hookstage.StageProcessedAuctionRequest,
+	hookstage.StageBidderRequest,
+	hookstage.StageRawBidderResponse,
+	hookstage.StageAllProcessedBidResponses,
+	hookstage.StageAuctionResponse,
+}
+
+func implementsStage(module interface{}, stage hookstage.Stage) bool {
+	var ok bool
+	switch stage {
+	case hookstage.StageEntrypoint:
+		_, ok = module.(hookstage.Entrypoint)
+	case hookstage.StageRawAuctionRequest:
+		_, ok = module.(hookstage.RawAuctionRequest)
+	case hookstage.StageProcessedAuctionRequest:
+		_, ok = module.(hookstage.ProcessedAuctionRequest)
+	case hookstage.StageBidderRequest:
+		_, ok = module.(hookstage.BidderRequest)
+	case hookstage.StageRawBidderResponse:
+		_, ok = module.(hookstage.RawBidderResponse)
+	case hookstage.StageAllProcessedBidResponses:
+		_, ok = module.(hookstage.AllProcessedBidResponses)
+	case hookstage.StageAuctionResponse:
+		_, ok = module.(hookstage.AuctionResponse)
+	}
+	return ok
+}
@@ -4,6 +4,9 @@ import (
 	"flag"
 	"math/rand"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/prebid/prebid-server/config"
@@ -11,6 +14,7 @@ import (
 	pbc "github.com/prebid/prebid-server/prebid_cache_client"
 	"github.com/prebid/prebid-server/router"
 	"github.com/prebid/prebid-server/server"
+	"github.com/prebid/prebid-server/tracing"
 	"github.com/prebid/prebid-server/util/task"
 
 	"github.com/golang/glog"
@@ -51,7 +55,36 @@ func loadConfig() (*config.Configuration, error) {
 	return config.New(v)
 }
 
+// reloadConfig reads the configuration file and the environment again, and swaps in the sections of the
+// configuration which can change while Prebid Server is running.
+func reloadConfig(cfg *config.Configuration) error {
+	v := viper.New()
+	config.SetupViper(v, configFileName)
+	return cfg.Reload(v)
+}
+
+// reloadOnHangup reloads the configuration whenever the process gets a SIGHUP.
+func reloadOnHangup(reload func() error) {
+	hangups := make(chan os.Signal, 1)
+	signal.Notify(hangups, syscall.SIGHUP)
+	go func() {
+		for range hangups {
+			if err := reload(); err != nil {
+				glog.Errorf("The configuration could not be reloaded: %v", err)
+			}
+		}
+	}()
+}
+
 func serve(revision string, cfg *config.Configuration) error {
+	if cfg.Tracing.Enabled {
+		shutdownTracing, err := tracing.NewProvider(cfg.Tracing)
+		if err != nil {
+			return err
+		}
+		defer shutdownTracing()
+	}
+
 	fetchingInterval := time.Duration(cfg.CurrencyConverter.FetchIntervalSeconds) * time.Second
 	staleRatesThreshold := time.Duration(cfg.CurrencyConverter.StaleRatesSeconds) * time.Second
 	currencyConverter := currency.NewRateConverter(&http.Client{}, cfg.CurrencyConverter.FetchURL, staleRatesThreshold)
@@ -67,7 +100,13 @@ func serve(revision string, cfg *config.Configuration) error {
 	pbc.InitPrebidCache(cfg.CacheURL.GetBaseURL())
 
 	corsRouter := router.SupportCORS(r)
-	server.Listen(cfg, router.NoCache{Handler: corsRouter}, router.Admin(revision, currencyConverter, fetchingInterval), r.MetricsEngine)
+	var handler http.Handler = router.NoCache{Handler: corsRouter}
+	if cfg.Tracing.Enabled {
+		handler = tracing.Middleware(handler)
+	}
+	reload := func() error { return reloadConfig(cfg) }
+	reloadOnHangup(reload)
+	server.Listen(cfg, handler, router.Admin(revision, currencyConverter, fetchingInterval, reload, r.StoredDataCaches), r.GRPCServer, r.MetricsEngine)
 
 	r.Shutdown()
 	return nil
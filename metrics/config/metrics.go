@@ -251,6 +251,48 @@ func (me *MultiMetricsEngine) RecordAdapterGDPRRequestBlocked(adapter openrtb_ex
 	}
 }
 
+// RecordAdapterCircuitBreakerStateChange across all engines
+func (me *MultiMetricsEngine) RecordAdapterCircuitBreakerStateChange(adapter openrtb_ext.BidderName, state metrics.CircuitBreakerState) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterCircuitBreakerStateChange(adapter, state)
+	}
+}
+
+// RecordAdapterGzipRequestSize across all engines
+func (me *MultiMetricsEngine) RecordAdapterGzipRequestSize(adapter openrtb_ext.BidderName, bodySize int) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterGzipRequestSize(adapter, bodySize)
+	}
+}
+
+// RecordAdapterRejectedBid across all engines
+func (me *MultiMetricsEngine) RecordAdapterRejectedBid(adapter openrtb_ext.BidderName, reason openrtb_ext.RejectionReason) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterRejectedBid(adapter, reason)
+	}
+}
+
+// RecordAdapterBidPrice across all engines
+func (me *MultiMetricsEngine) RecordAdapterBidPrice(adapter openrtb_ext.BidderName, bidType openrtb_ext.BidType, cpm float64) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterBidPrice(adapter, bidType, cpm)
+	}
+}
+
+// RecordAdapterRequestSize across all engines
+func (me *MultiMetricsEngine) RecordAdapterRequestSize(adapter openrtb_ext.BidderName, bodySize int) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterRequestSize(adapter, bodySize)
+	}
+}
+
+// RecordAnalyticsEventDropped across all engines
+func (me *MultiMetricsEngine) RecordAnalyticsEventDropped(module string, eventType string) {
+	for _, thisME := range *me {
+		thisME.RecordAnalyticsEventDropped(module, eventType)
+	}
+}
+
 // DummyMetricsEngine is a Noop metrics engine in case no metrics are configured. (may also be useful for tests)
 type DummyMetricsEngine struct{}
 
@@ -361,3 +403,27 @@ func (me *DummyMetricsEngine) RecordRequestPrivacy(privacy metrics.PrivacyLabels
 // RecordAdapterGDPRRequestBlocked as a noop
 func (me *DummyMetricsEngine) RecordAdapterGDPRRequestBlocked(adapter openrtb_ext.BidderName) {
 }
+
+// RecordAdapterCircuitBreakerStateChange as a noop
+func (me *DummyMetricsEngine) RecordAdapterCircuitBreakerStateChange(adapter openrtb_ext.BidderName, state metrics.CircuitBreakerState) {
+}
+
+// RecordAdapterGzipRequestSize as a noop
+func (me *DummyMetricsEngine) RecordAdapterGzipRequestSize(adapter openrtb_ext.BidderName, bodySize int) {
+}
+
+// RecordAdapterRejectedBid as a noop
+func (me *DummyMetricsEngine) RecordAdapterRejectedBid(adapter openrtb_ext.BidderName, reason openrtb_ext.RejectionReason) {
+}
+
+// RecordAdapterBidPrice as a noop
+func (me *DummyMetricsEngine) RecordAdapterBidPrice(adapter openrtb_ext.BidderName, bidType openrtb_ext.BidType, cpm float64) {
+}
+
+// RecordAdapterRequestSize as a noop
+func (me *DummyMetricsEngine) RecordAdapterRequestSize(adapter openrtb_ext.BidderName, bodySize int) {
+}
+
+// RecordAnalyticsEventDropped as a noop
+func (me *DummyMetricsEngine) RecordAnalyticsEventDropped(module string, eventType string) {
+}
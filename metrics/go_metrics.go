@@ -88,6 +88,16 @@ type AdapterMetrics struct {
 	ConnReused         metrics.Counter
 	ConnWaitTime       metrics.Timer
 	GDPRRequestBlocked metrics.Meter
+	// CircuitBreakerMeters count the state changes of the adapter's circuit breaker
+	CircuitBreakerMeters map[CircuitBreakerState]metrics.Meter
+	// GzipRequestSize holds the sizes of the gzip compressed request bodies sent to the adapter
+	GzipRequestSize metrics.Histogram
+	// RejectedBidMeters count the bids of the adapter removed from the auctions, by reason
+	RejectedBidMeters map[openrtb_ext.RejectionReason]metrics.Meter
+	// BidTypePriceHistograms hold the prices of the adapter's bids, by media type
+	BidTypePriceHistograms map[openrtb_ext.BidType]metrics.Histogram
+	// RequestSize holds the sizes of the request bodies sent to the adapter, before any compression
+	RequestSize metrics.Histogram
 }
 
 type MarkupDeliveryMetrics struct {
@@ -292,15 +302,25 @@ func NewMetrics(registry metrics.Registry, exchanges []openrtb_ext.BidderName, d
 func makeBlankAdapterMetrics(disabledMetrics config.DisabledMetrics) *AdapterMetrics {
 	blankMeter := &metrics.NilMeter{}
 	newAdapter := &AdapterMetrics{
-		NoCookieMeter:     blankMeter,
-		ErrorMeters:       make(map[AdapterError]metrics.Meter),
-		NoBidMeter:        blankMeter,
-		GotBidsMeter:      blankMeter,
-		RequestTimer:      &metrics.NilTimer{},
-		PriceHistogram:    &metrics.NilHistogram{},
-		BidsReceivedMeter: blankMeter,
-		PanicMeter:        blankMeter,
-		MarkupMetrics:     makeBlankBidMarkupMetrics(),
+		NoCookieMeter:        blankMeter,
+		ErrorMeters:          make(map[AdapterError]metrics.Meter),
+		NoBidMeter:           blankMeter,
+		GotBidsMeter:         blankMeter,
+		RequestTimer:         &metrics.NilTimer{},
+		PriceHistogram:       &metrics.NilHistogram{},
+		BidsReceivedMeter:    blankMeter,
+		PanicMeter:           blankMeter,
+		MarkupMetrics:        makeBlankBidMarkupMetrics(),
+		CircuitBreakerMeters: make(map[CircuitBreakerState]metrics.Meter),
+		GzipRequestSize:      &metrics.NilHistogram{},
+		RejectedBidMeters:    make(map[openrtb_ext.RejectionReason]metrics.Meter),
+		BidTypePriceHistograms: map[openrtb_ext.BidType]metrics.Histogram{
+			openrtb_ext.BidTypeAudio:  &metrics.NilHistogram{},
+			openrtb_ext.BidTypeBanner: &metrics.NilHistogram{},
+			openrtb_ext.BidTypeNative: &metrics.NilHistogram{},
+			openrtb_ext.BidTypeVideo:  &metrics.NilHistogram{},
+		},
+		RequestSize: &metrics.NilHistogram{},
 	}
 	if !disabledMetrics.AdapterConnectionMetrics {
 		newAdapter.ConnCreated = metrics.NilCounter{}
@@ -313,6 +333,12 @@ func makeBlankAdapterMetrics(disabledMetrics config.DisabledMetrics) *AdapterMet
 	for _, err := range AdapterErrors() {
 		newAdapter.ErrorMeters[err] = blankMeter
 	}
+	for _, state := range CircuitBreakerStates() {
+		newAdapter.CircuitBreakerMeters[state] = blankMeter
+	}
+	for _, reason := range openrtb_ext.RejectionReasons() {
+		newAdapter.RejectedBidMeters[reason] = blankMeter
+	}
 	return newAdapter
 }
 
@@ -350,6 +376,19 @@ func registerAdapterMetrics(registry metrics.Registry, adapterOrAccount string,
 	for err := range am.ErrorMeters {
 		am.ErrorMeters[err] = metrics.GetOrRegisterMeter(fmt.Sprintf("%s.%s.requests.%s", adapterOrAccount, exchange, err), registry)
 	}
+	if adapterOrAccount == "adapter" {
+		for state := range am.CircuitBreakerMeters {
+			am.CircuitBreakerMeters[state] = metrics.GetOrRegisterMeter(fmt.Sprintf("%s.%s.circuit_breaker.%s", adapterOrAccount, exchange, state), registry)
+		}
+		am.GzipRequestSize = metrics.GetOrRegisterHistogram(fmt.Sprintf("%[1]s.%[2]s.gzip_request_size", adapterOrAccount, exchange), registry, metrics.NewExpDecaySample(1028, 0.015))
+		for reason := range am.RejectedBidMeters {
+			am.RejectedBidMeters[reason] = metrics.GetOrRegisterMeter(fmt.Sprintf("%s.%s.rejected_bids.%s", adapterOrAccount, exchange, reason), registry)
+		}
+		for bidType := range am.BidTypePriceHistograms {
+			am.BidTypePriceHistograms[bidType] = metrics.GetOrRegisterHistogram(fmt.Sprintf("%s.%s.prices.%s", adapterOrAccount, exchange, bidType), registry, metrics.NewExpDecaySample(1028, 0.015))
+		}
+		am.RequestSize = metrics.GetOrRegisterHistogram(fmt.Sprintf("%[1]s.%[2]s.request_size", adapterOrAccount, exchange), registry, metrics.NewExpDecaySample(1028, 0.015))
+	}
 	if adapterOrAccount != "adapter" {
 		am.BidsReceivedMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.bids_received", adapterOrAccount, exchange), registry)
 	}
@@ -723,6 +762,68 @@ func (me *Metrics) RecordAdapterGDPRRequestBlocked(adapterName openrtb_ext.Bidde
 	am.GDPRRequestBlocked.Mark(1)
 }
 
+func (me *Metrics) RecordAdapterCircuitBreakerStateChange(adapterName openrtb_ext.BidderName, state CircuitBreakerState) {
+	am, ok := me.AdapterMetrics[adapterName]
+	if !ok {
+		glog.Errorf("Trying to log adapter circuit breaker metric for %s: adapter not found", string(adapterName))
+		return
+	}
+
+	if meter, ok := am.CircuitBreakerMeters[state]; ok {
+		meter.Mark(1)
+	}
+}
+
+func (me *Metrics) RecordAdapterGzipRequestSize(adapterName openrtb_ext.BidderName, bodySize int) {
+	am, ok := me.AdapterMetrics[adapterName]
+	if !ok {
+		glog.Errorf("Trying to log adapter gzip request size metric for %s: adapter not found", string(adapterName))
+		return
+	}
+
+	am.GzipRequestSize.Update(int64(bodySize))
+}
+
+func (me *Metrics) RecordAdapterRejectedBid(adapterName openrtb_ext.BidderName, reason openrtb_ext.RejectionReason) {
+	am, ok := me.AdapterMetrics[adapterName]
+	if !ok {
+		glog.Errorf("Trying to log adapter rejected bid metric for %s: adapter not found", string(adapterName))
+		return
+	}
+
+	if meter, ok := am.RejectedBidMeters[reason]; ok {
+		meter.Mark(1)
+	}
+}
+
+func (me *Metrics) RecordAdapterBidPrice(adapterName openrtb_ext.BidderName, bidType openrtb_ext.BidType, cpm float64) {
+	am, ok := me.AdapterMetrics[adapterName]
+	if !ok {
+		glog.Errorf("Trying to log adapter bid price metric for %s: adapter not found", string(adapterName))
+		return
+	}
+
+	if histogram, ok := am.BidTypePriceHistograms[bidType]; ok {
+		histogram.Update(int64(cpm))
+	}
+}
+
+func (me *Metrics) RecordAdapterRequestSize(adapterName openrtb_ext.BidderName, bodySize int) {
+	am, ok := me.AdapterMetrics[adapterName]
+	if !ok {
+		glog.Errorf("Trying to log adapter request size metric for %s: adapter not found", string(adapterName))
+		return
+	}
+
+	am.RequestSize.Update(int64(bodySize))
+}
+
+// RecordAnalyticsEventDropped registers the meters on first use, since the analytics modules and their event types
+// are only known to the modules.
+func (me *Metrics) RecordAnalyticsEventDropped(module string, eventType string) {
+	metrics.GetOrRegisterMeter(fmt.Sprintf("analytics.%s.%s.dropped", module, eventType), me.MetricsRegistry).Mark(1)
+}
+
 func doMark(bidder openrtb_ext.BidderName, meters map[openrtb_ext.BidderName]metrics.Meter) {
 	met, ok := meters[bidder]
 	if ok {
@@ -599,6 +599,79 @@ func TestRecordAdapterGDPRRequestBlocked(t *testing.T) {
 	}
 }
 
+func TestRecordAdapterCircuitBreakerStateChange(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{})
+
+	m.RecordAdapterCircuitBreakerStateChange(openrtb_ext.BidderAppnexus, CircuitBreakerOpen)
+	m.RecordAdapterCircuitBreakerStateChange("fooAdvertising", CircuitBreakerOpen)
+
+	ensureContains(t, registry, "adapter.appnexus.circuit_breaker.open", m.AdapterMetrics[openrtb_ext.BidderAppnexus].CircuitBreakerMeters[CircuitBreakerOpen])
+	assert.Equal(t, int64(1), m.AdapterMetrics[openrtb_ext.BidderAppnexus].CircuitBreakerMeters[CircuitBreakerOpen].Count())
+	assert.Equal(t, int64(0), m.AdapterMetrics[openrtb_ext.BidderAppnexus].CircuitBreakerMeters[CircuitBreakerClosed].Count())
+}
+
+func TestRecordAdapterGzipRequestSize(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{})
+
+	m.RecordAdapterGzipRequestSize(openrtb_ext.BidderAppnexus, 512)
+	m.RecordAdapterGzipRequestSize("fooAdvertising", 512)
+
+	ensureContains(t, registry, "adapter.appnexus.gzip_request_size", m.AdapterMetrics[openrtb_ext.BidderAppnexus].GzipRequestSize)
+	assert.Equal(t, int64(1), m.AdapterMetrics[openrtb_ext.BidderAppnexus].GzipRequestSize.Count())
+	assert.Equal(t, int64(512), m.AdapterMetrics[openrtb_ext.BidderAppnexus].GzipRequestSize.Sum())
+}
+
+func TestRecordAdapterRejectedBid(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{})
+
+	m.RecordAdapterRejectedBid(openrtb_ext.BidderAppnexus, openrtb_ext.RejectionReasonBelowFloor)
+	m.RecordAdapterRejectedBid("fooAdvertising", openrtb_ext.RejectionReasonBelowFloor)
+
+	ensureContains(t, registry, "adapter.appnexus.rejected_bids.below_floor", m.AdapterMetrics[openrtb_ext.BidderAppnexus].RejectedBidMeters[openrtb_ext.RejectionReasonBelowFloor])
+	assert.Equal(t, int64(1), m.AdapterMetrics[openrtb_ext.BidderAppnexus].RejectedBidMeters[openrtb_ext.RejectionReasonBelowFloor].Count())
+	assert.Equal(t, int64(0), m.AdapterMetrics[openrtb_ext.BidderAppnexus].RejectedBidMeters[openrtb_ext.RejectionReasonInvalidBid].Count())
+}
+
+func TestRecordAdapterBidPrice(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{})
+
+	m.RecordAdapterBidPrice(openrtb_ext.BidderAppnexus, openrtb_ext.BidTypeVideo, 1500)
+	m.RecordAdapterBidPrice("fooAdvertising", openrtb_ext.BidTypeVideo, 1500)
+
+	ensureContains(t, registry, "adapter.appnexus.prices.video", m.AdapterMetrics[openrtb_ext.BidderAppnexus].BidTypePriceHistograms[openrtb_ext.BidTypeVideo])
+	assert.Equal(t, int64(1500), m.AdapterMetrics[openrtb_ext.BidderAppnexus].BidTypePriceHistograms[openrtb_ext.BidTypeVideo].Sum())
+	assert.Equal(t, int64(0), m.AdapterMetrics[openrtb_ext.BidderAppnexus].BidTypePriceHistograms[openrtb_ext.BidTypeBanner].Count())
+}
+
+func TestRecordAdapterRequestSize(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{})
+
+	m.RecordAdapterRequestSize(openrtb_ext.BidderAppnexus, 2048)
+	m.RecordAdapterRequestSize("fooAdvertising", 2048)
+
+	ensureContains(t, registry, "adapter.appnexus.request_size", m.AdapterMetrics[openrtb_ext.BidderAppnexus].RequestSize)
+	assert.Equal(t, int64(1), m.AdapterMetrics[openrtb_ext.BidderAppnexus].RequestSize.Count())
+	assert.Equal(t, int64(2048), m.AdapterMetrics[openrtb_ext.BidderAppnexus].RequestSize.Sum())
+}
+
+func TestRecordAnalyticsEventDropped(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{})
+
+	m.RecordAnalyticsEventDropped("kafka", "auction")
+	m.RecordAnalyticsEventDropped("kafka", "auction")
+
+	meter, ok := registry.Get("analytics.kafka.auction.dropped").(metrics.Meter)
+	if assert.True(t, ok, "Meter registered") {
+		assert.Equal(t, int64(2), meter.Count())
+	}
+}
+
 func ensureContainsBidTypeMetrics(t *testing.T, registry metrics.Registry, prefix string, mdm map[openrtb_ext.BidType]*MarkupDeliveryMetrics) {
 	ensureContains(t, registry, prefix+".banner.adm_bids_received", mdm[openrtb_ext.BidTypeBanner].AdmMeter)
 	ensureContains(t, registry, prefix+".banner.nurl_bids_received", mdm[openrtb_ext.BidTypeBanner].NurlMeter)
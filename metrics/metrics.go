@@ -319,6 +319,27 @@ func TCFVersionToValue(version int) TCFVersionValue {
 	return TCFVersionErr
 }
 
+// CircuitBreakerState : The state of a bidder's circuit breaker
+type CircuitBreakerState string
+
+const (
+	// CircuitBreakerClosed lets all the requests through to the bidder
+	CircuitBreakerClosed CircuitBreakerState = "closed"
+	// CircuitBreakerOpen skips the bidder until its cooldown period is over
+	CircuitBreakerOpen CircuitBreakerState = "open"
+	// CircuitBreakerHalfOpen lets a single probe request through to the bidder once the cooldown period is over
+	CircuitBreakerHalfOpen CircuitBreakerState = "half_open"
+)
+
+// CircuitBreakerStates returns the possible states of a bidder's circuit breaker
+func CircuitBreakerStates() []CircuitBreakerState {
+	return []CircuitBreakerState{
+		CircuitBreakerClosed,
+		CircuitBreakerOpen,
+		CircuitBreakerHalfOpen,
+	}
+}
+
 // MetricsEngine is a generic interface to record PBS metrics into the desired backend
 // The first three metrics function fire off once per incoming request, so total metrics
 // will equal the total number of incoming requests. The remaining 5 fire off per outgoing
@@ -355,4 +376,10 @@ type MetricsEngine interface {
 	RecordTimeoutNotice(sucess bool)
 	RecordRequestPrivacy(privacy PrivacyLabels)
 	RecordAdapterGDPRRequestBlocked(adapterName openrtb_ext.BidderName)
+	RecordAdapterCircuitBreakerStateChange(adapterName openrtb_ext.BidderName, state CircuitBreakerState)
+	RecordAdapterGzipRequestSize(adapterName openrtb_ext.BidderName, bodySize int)
+	RecordAdapterRejectedBid(adapterName openrtb_ext.BidderName, reason openrtb_ext.RejectionReason)
+	RecordAdapterBidPrice(adapterName openrtb_ext.BidderName, bidType openrtb_ext.BidType, cpm float64)
+	RecordAdapterRequestSize(adapterName openrtb_ext.BidderName, bodySize int)
+	RecordAnalyticsEventDropped(module string, eventType string)
 }
@@ -145,3 +145,33 @@ func (me *MetricsEngineMock) RecordRequestPrivacy(privacy PrivacyLabels) {
 func (me *MetricsEngineMock) RecordAdapterGDPRRequestBlocked(adapterName openrtb_ext.BidderName) {
 	me.Called(adapterName)
 }
+
+// RecordAdapterCircuitBreakerStateChange mock
+func (me *MetricsEngineMock) RecordAdapterCircuitBreakerStateChange(adapterName openrtb_ext.BidderName, state CircuitBreakerState) {
+	me.Called(adapterName, state)
+}
+
+// RecordAdapterGzipRequestSize mock
+func (me *MetricsEngineMock) RecordAdapterGzipRequestSize(adapterName openrtb_ext.BidderName, bodySize int) {
+	me.Called(adapterName, bodySize)
+}
+
+// RecordAdapterRejectedBid mock
+func (me *MetricsEngineMock) RecordAdapterRejectedBid(adapterName openrtb_ext.BidderName, reason openrtb_ext.RejectionReason) {
+	me.Called(adapterName, reason)
+}
+
+// RecordAdapterBidPrice mock
+func (me *MetricsEngineMock) RecordAdapterBidPrice(adapterName openrtb_ext.BidderName, bidType openrtb_ext.BidType, cpm float64) {
+	me.Called(adapterName, bidType, cpm)
+}
+
+// RecordAdapterRequestSize mock
+func (me *MetricsEngineMock) RecordAdapterRequestSize(adapterName openrtb_ext.BidderName, bodySize int) {
+	me.Called(adapterName, bodySize)
+}
+
+// RecordAnalyticsEventDropped mock
+func (me *MetricsEngineMock) RecordAnalyticsEventDropped(module string, eventType string) {
+	me.Called(module, eventType)
+}
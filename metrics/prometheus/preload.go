@@ -149,6 +149,10 @@ func preloadLabelValues(m *Metrics) {
 		adapterLabel: adapterValues,
 	})
 
+	preloadLabelValuesForHistogram(m.adapterRequestSize, map[string][]string{
+		adapterLabel: adapterValues,
+	})
+
 	preloadLabelValuesForCounter(m.adapterUserSync, map[string][]string{
 		adapterLabel: adapterValues,
 		actionLabel:  actionValues,
@@ -60,19 +60,35 @@ type Metrics struct {
 	adapterCreatedConnections  *prometheus.CounterVec
 	adapterConnectionWaitTime  *prometheus.HistogramVec
 	adapterGDPRBlockedRequests *prometheus.CounterVec
+	adapterCircuitBreaker      *prometheus.CounterVec
+	adapterGzipRequestSize     *prometheus.HistogramVec
+	adapterRejectedBids        *prometheus.CounterVec
+	adapterBidPrices           *prometheus.HistogramVec
+	adapterRequestSize         *prometheus.HistogramVec
+
+	// Analytics Metrics
+	analyticsEventsDropped *prometheus.CounterVec
 
 	// Account Metrics
 	accountRequests *prometheus.CounterVec
+	// labeledAccounts are the accounts given their own label, nil when every account is
+	labeledAccounts map[string]bool
 
 	metricsDisabled config.DisabledMetrics
 }
 
+// accountOther is the label of the accounts which aren't in the allow-list of the labeled accounts.
+const accountOther = "other"
+
 const (
 	accountLabel         = "account"
 	actionLabel          = "action"
 	adapterErrorLabel    = "adapter_error"
 	adapterLabel         = "adapter"
+	analyticsEventLabel  = "analytics_event"
+	analyticsModuleLabel = "analytics_module"
 	bidTypeLabel         = "bid_type"
+	circuitBreakerLabel  = "circuit_breaker_state"
 	cacheResultLabel     = "cache_result"
 	connectionErrorLabel = "connection_error"
 	cookieLabel          = "cookie"
@@ -84,6 +100,7 @@ const (
 	markupDeliveryLabel  = "delivery"
 	optOutLabel          = "opt_out"
 	privacyBlockedLabel  = "privacy_blocked"
+	rejectionReasonLabel = "rejection_reason"
 	requestStatusLabel   = "request_status"
 	requestTypeLabel     = "request_type"
 	successLabel         = "success"
@@ -123,13 +140,24 @@ const (
 // NewMetrics initializes a new Prometheus metrics instance with preloaded label values.
 func NewMetrics(cfg config.PrometheusMetrics, disabledMetrics config.DisabledMetrics) *Metrics {
 	standardTimeBuckets := []float64{0.05, 0.1, 0.15, 0.20, 0.25, 0.3, 0.4, 0.5, 0.75, 1}
-	cacheWriteTimeBuckets := []float64{0.001, 0.002, 0.005, 0.01, 0.025, 0.05, 0.1, 0.2, 0.3, 0.4, 0.5, 1}
-	priceBuckets := []float64{250, 500, 750, 1000, 1500, 2000, 2500, 3000, 3500, 4000}
-	queuedRequestTimeBuckets := []float64{0, 1, 5, 30, 60, 120, 180, 240, 300}
+	requestTimeBuckets := bucketsOrDefault(cfg.Buckets.RequestTime, standardTimeBuckets)
+	adapterRequestTimeBuckets := bucketsOrDefault(cfg.Buckets.AdapterRequestTime, standardTimeBuckets)
+	connectionTimeBuckets := bucketsOrDefault(cfg.Buckets.ConnectionTime, standardTimeBuckets)
+	storedDataFetchTimeBuckets := bucketsOrDefault(cfg.Buckets.StoredDataFetchTime, standardTimeBuckets)
+	cacheWriteTimeBuckets := bucketsOrDefault(cfg.Buckets.PrebidCacheWriteTime, []float64{0.001, 0.002, 0.005, 0.01, 0.025, 0.05, 0.1, 0.2, 0.3, 0.4, 0.5, 1})
+	priceBuckets := bucketsOrDefault(cfg.Buckets.Price, []float64{250, 500, 750, 1000, 1500, 2000, 2500, 3000, 3500, 4000})
+	queuedRequestTimeBuckets := bucketsOrDefault(cfg.Buckets.RequestQueueTime, []float64{0, 1, 5, 30, 60, 120, 180, 240, 300})
+	requestSizeBuckets := bucketsOrDefault(cfg.Buckets.RequestSize, []float64{512, 1024, 2048, 4096, 8192, 16384, 32768, 65536, 131072})
 
 	metrics := Metrics{}
 	metrics.Registry = prometheus.NewRegistry()
 	metrics.metricsDisabled = disabledMetrics
+	if len(cfg.Accounts) > 0 {
+		metrics.labeledAccounts = make(map[string]bool, len(cfg.Accounts))
+		for _, account := range cfg.Accounts {
+			metrics.labeledAccounts[account] = true
+		}
+	}
 
 	metrics.connectionsClosed = newCounterWithoutLabels(cfg, metrics.Registry,
 		"connections_closed",
@@ -172,7 +200,7 @@ func NewMetrics(cfg config.PrometheusMetrics, disabledMetrics config.DisabledMet
 		"request_time_seconds",
 		"Seconds to resolve successful Prebid Server requests labeled by type.",
 		[]string{requestTypeLabel},
-		standardTimeBuckets)
+		requestTimeBuckets)
 
 	metrics.requestsWithoutCookie = newCounter(cfg, metrics.Registry,
 		"requests_without_cookie",
@@ -198,7 +226,7 @@ func NewMetrics(cfg config.PrometheusMetrics, disabledMetrics config.DisabledMet
 		"stored_account_fetch_time_seconds",
 		"Seconds to fetch stored accounts labeled by fetch type",
 		[]string{storedDataFetchTypeLabel},
-		standardTimeBuckets)
+		storedDataFetchTimeBuckets)
 
 	metrics.storedAccountErrors = newCounter(cfg, metrics.Registry,
 		"stored_account_errors",
@@ -209,7 +237,7 @@ func NewMetrics(cfg config.PrometheusMetrics, disabledMetrics config.DisabledMet
 		"stored_amp_fetch_time_seconds",
 		"Seconds to fetch stored AMP requests labeled by fetch type",
 		[]string{storedDataFetchTypeLabel},
-		standardTimeBuckets)
+		storedDataFetchTimeBuckets)
 
 	metrics.storedAMPErrors = newCounter(cfg, metrics.Registry,
 		"stored_amp_errors",
@@ -220,7 +248,7 @@ func NewMetrics(cfg config.PrometheusMetrics, disabledMetrics config.DisabledMet
 		"stored_category_fetch_time_seconds",
 		"Seconds to fetch stored categories labeled by fetch type",
 		[]string{storedDataFetchTypeLabel},
-		standardTimeBuckets)
+		storedDataFetchTimeBuckets)
 
 	metrics.storedCategoryErrors = newCounter(cfg, metrics.Registry,
 		"stored_category_errors",
@@ -231,7 +259,7 @@ func NewMetrics(cfg config.PrometheusMetrics, disabledMetrics config.DisabledMet
 		"stored_request_fetch_time_seconds",
 		"Seconds to fetch stored requests labeled by fetch type",
 		[]string{storedDataFetchTypeLabel},
-		standardTimeBuckets)
+		storedDataFetchTimeBuckets)
 
 	metrics.storedRequestErrors = newCounter(cfg, metrics.Registry,
 		"stored_request_errors",
@@ -242,7 +270,7 @@ func NewMetrics(cfg config.PrometheusMetrics, disabledMetrics config.DisabledMet
 		"stored_video_fetch_time_seconds",
 		"Seconds to fetch stored video labeled by fetch type",
 		[]string{storedDataFetchTypeLabel},
-		standardTimeBuckets)
+		storedDataFetchTimeBuckets)
 
 	metrics.storedVideoErrors = newCounter(cfg, metrics.Registry,
 		"stored_video_errors",
@@ -257,12 +285,12 @@ func NewMetrics(cfg config.PrometheusMetrics, disabledMetrics config.DisabledMet
 	metrics.dnsLookupTimer = newHistogram(cfg, metrics.Registry,
 		"dns_lookup_time",
 		"Seconds to resolve DNS",
-		standardTimeBuckets)
+		connectionTimeBuckets)
 
 	metrics.tlsHandhakeTimer = newHistogram(cfg, metrics.Registry,
 		"tls_handshake_time",
 		"Seconds to perform TLS Handshake",
-		standardTimeBuckets)
+		connectionTimeBuckets)
 
 	metrics.privacyCCPA = newCounter(cfg, metrics.Registry,
 		"privacy_ccpa",
@@ -291,6 +319,43 @@ func NewMetrics(cfg config.PrometheusMetrics, disabledMetrics config.DisabledMet
 			[]string{adapterLabel})
 	}
 
+	// Not preloaded, since only the adapters with a circuit breaker enabled ever change state
+	metrics.adapterCircuitBreaker = newCounter(cfg, metrics.Registry,
+		"adapter_circuit_breaker_state_changes",
+		"Count of state changes of the adapter circuit breakers, labeled by the new state.",
+		[]string{adapterLabel, circuitBreakerLabel})
+
+	// Not preloaded, since only the adapters with an endpoint compression send gzip requests
+	metrics.adapterGzipRequestSize = newHistogramVec(cfg, metrics.Registry,
+		"adapter_gzip_request_size_bytes",
+		"Size in bytes of the gzip compressed request bodies sent to the adapters.",
+		[]string{adapterLabel},
+		requestSizeBuckets)
+
+	// Not preloaded, since most adapters never see some of their bids rejected for most of the reasons
+	metrics.adapterRejectedBids = newCounter(cfg, metrics.Registry,
+		"adapter_rejected_bids",
+		"Count of the bids removed from the auctions, labeled by adapter and rejection reason.",
+		[]string{adapterLabel, rejectionReasonLabel})
+
+	// Not preloaded, since most adapters only bid on some of the media types
+	metrics.adapterBidPrices = newHistogramVec(cfg, metrics.Registry,
+		"adapter_bid_prices",
+		"Monetary value of the bids labeled by adapter and media type.",
+		[]string{adapterLabel, bidTypeLabel},
+		priceBuckets)
+
+	metrics.adapterRequestSize = newHistogramVec(cfg, metrics.Registry,
+		"adapter_request_size_bytes",
+		"Size in bytes of the request bodies sent to the adapters, before any compression.",
+		[]string{adapterLabel},
+		requestSizeBuckets)
+
+	metrics.analyticsEventsDropped = newCounter(cfg, metrics.Registry,
+		"analytics_events_dropped",
+		"Count of analytics events which couldn't be published, labeled by analytics module and event type.",
+		[]string{analyticsModuleLabel, analyticsEventLabel})
+
 	metrics.adapterBids = newCounter(cfg, metrics.Registry,
 		"adapter_bids",
 		"Count of bids labeled by adapter and markup delivery type (adm or nurl).",
@@ -337,14 +402,14 @@ func NewMetrics(cfg config.PrometheusMetrics, disabledMetrics config.DisabledMet
 			"adapter_connection_wait",
 			"Seconds from when the connection was requested until it is either created or reused",
 			[]string{adapterLabel},
-			standardTimeBuckets)
+			connectionTimeBuckets)
 	}
 
 	metrics.adapterRequestsTimer = newHistogramVec(cfg, metrics.Registry,
 		"adapter_request_time_seconds",
 		"Seconds to resolve each successful request labeled by adapter.",
 		[]string{adapterLabel},
-		standardTimeBuckets)
+		adapterRequestTimeBuckets)
 
 	metrics.adapterUserSync = newCounter(cfg, metrics.Registry,
 		"adapter_user_sync",
@@ -367,6 +432,14 @@ func NewMetrics(cfg config.PrometheusMetrics, disabledMetrics config.DisabledMet
 	return &metrics
 }
 
+// bucketsOrDefault returns the buckets configured by the host, or the default ones if none are.
+func bucketsOrDefault(buckets []float64, defaultBuckets []float64) []float64 {
+	if len(buckets) > 0 {
+		return buckets
+	}
+	return defaultBuckets
+}
+
 func newCounter(cfg config.PrometheusMetrics, registry *prometheus.Registry, name, help string, labels []string) *prometheus.CounterVec {
 	opts := prometheus.CounterOpts{
 		Namespace: cfg.Namespace,
@@ -451,11 +524,20 @@ func (m *Metrics) RecordRequest(labels metrics.Labels) {
 
 	if labels.PubID != metrics.PublisherUnknown {
 		m.accountRequests.With(prometheus.Labels{
-			accountLabel: labels.PubID,
+			accountLabel: m.accountLabelValue(labels.PubID),
 		}).Inc()
 	}
 }
 
+// accountLabelValue folds the accounts which aren't in the allow-list into a single label, to bound the
+// number of series.
+func (m *Metrics) accountLabelValue(account string) string {
+	if m.labeledAccounts == nil || m.labeledAccounts[account] {
+		return account
+	}
+	return accountOther
+}
+
 func (m *Metrics) RecordImps(labels metrics.ImpLabels) {
 	m.impressions.With(prometheus.Labels{
 		isBannerLabel: strconv.FormatBool(labels.BannerImps),
@@ -709,3 +791,43 @@ func (m *Metrics) RecordAdapterGDPRRequestBlocked(adapterName openrtb_ext.Bidder
 		adapterLabel: string(adapterName),
 	}).Inc()
 }
+
+func (m *Metrics) RecordAdapterCircuitBreakerStateChange(adapterName openrtb_ext.BidderName, state metrics.CircuitBreakerState) {
+	m.adapterCircuitBreaker.With(prometheus.Labels{
+		adapterLabel:        string(adapterName),
+		circuitBreakerLabel: string(state),
+	}).Inc()
+}
+
+func (m *Metrics) RecordAdapterGzipRequestSize(adapterName openrtb_ext.BidderName, bodySize int) {
+	m.adapterGzipRequestSize.With(prometheus.Labels{
+		adapterLabel: string(adapterName),
+	}).Observe(float64(bodySize))
+}
+
+func (m *Metrics) RecordAdapterRejectedBid(adapterName openrtb_ext.BidderName, reason openrtb_ext.RejectionReason) {
+	m.adapterRejectedBids.With(prometheus.Labels{
+		adapterLabel:         string(adapterName),
+		rejectionReasonLabel: reason.String(),
+	}).Inc()
+}
+
+func (m *Metrics) RecordAdapterBidPrice(adapterName openrtb_ext.BidderName, bidType openrtb_ext.BidType, cpm float64) {
+	m.adapterBidPrices.With(prometheus.Labels{
+		adapterLabel: string(adapterName),
+		bidTypeLabel: string(bidType),
+	}).Observe(cpm)
+}
+
+func (m *Metrics) RecordAdapterRequestSize(adapterName openrtb_ext.BidderName, bodySize int) {
+	m.adapterRequestSize.With(prometheus.Labels{
+		adapterLabel: string(adapterName),
+	}).Observe(float64(bodySize))
+}
+
+func (m *Metrics) RecordAnalyticsEventDropped(module string, eventType string) {
+	m.analyticsEventsDropped.With(prometheus.Labels{
+		analyticsModuleLabel: module,
+		analyticsEventLabel:  eventType,
+	}).Inc()
+}
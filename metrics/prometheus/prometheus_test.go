@@ -251,6 +251,25 @@ func TestAccountMetric(t *testing.T) {
 	}
 }
 
+func TestAccountMetricAllowList(t *testing.T) {
+	m := NewMetrics(config.PrometheusMetrics{Accounts: []string{"allowed"}}, config.DisabledMetrics{})
+
+	for _, pubID := range []string{"allowed", "not-allowed-1", "not-allowed-2"} {
+		m.RecordRequest(metrics.Labels{
+			RType:         metrics.ReqTypeORTB2Web,
+			RequestStatus: metrics.RequestStatusOK,
+			PubID:         pubID,
+		})
+	}
+
+	assertCounterVecValue(t, "Allowed account", "accountRequests", m.accountRequests, 1,
+		prometheus.Labels{accountLabel: "allowed"})
+	assertCounterVecValue(t, "Other accounts", "accountRequests", m.accountRequests, 2,
+		prometheus.Labels{accountLabel: accountOther})
+	assertCounterVecValue(t, "Account not in the allow-list", "accountRequests", m.accountRequests, 0,
+		prometheus.Labels{accountLabel: "not-allowed-1"})
+}
+
 func TestImpressionsMetric(t *testing.T) {
 	performTest := func(m *Metrics, isBanner, isVideo, isAudio, isNative bool) {
 		m.RecordImps(metrics.ImpLabels{
@@ -1514,3 +1533,104 @@ func TestRecordAdapterGDPRRequestBlocked(t *testing.T) {
 			adapterLabel: string(openrtb_ext.BidderAppnexus),
 		})
 }
+
+func TestRecordAdapterCircuitBreakerStateChange(t *testing.T) {
+	m := createMetricsForTesting()
+
+	m.RecordAdapterCircuitBreakerStateChange(openrtb_ext.BidderAppnexus, metrics.CircuitBreakerOpen)
+
+	assertCounterVecValue(t,
+		"Increment adapter circuit breaker counter",
+		"adapter_circuit_breaker_state_changes",
+		m.adapterCircuitBreaker,
+		1,
+		prometheus.Labels{
+			adapterLabel:        string(openrtb_ext.BidderAppnexus),
+			circuitBreakerLabel: string(metrics.CircuitBreakerOpen),
+		})
+}
+
+func TestRecordAdapterGzipRequestSize(t *testing.T) {
+	m := createMetricsForTesting()
+
+	m.RecordAdapterGzipRequestSize(openrtb_ext.BidderAppnexus, 1500)
+
+	histogram := getHistogramFromHistogramVec(m.adapterGzipRequestSize, adapterLabel, string(openrtb_ext.BidderAppnexus))
+	assertHistogram(t, "adapter_gzip_request_size_bytes", histogram, 1, 1500)
+}
+
+func TestRecordAdapterRejectedBid(t *testing.T) {
+	m := createMetricsForTesting()
+
+	m.RecordAdapterRejectedBid(openrtb_ext.BidderAppnexus, openrtb_ext.RejectionReasonBelowFloor)
+
+	assertCounterVecValue(t,
+		"Increment adapter rejected bids counter",
+		"adapter_rejected_bids",
+		m.adapterRejectedBids,
+		1,
+		prometheus.Labels{
+			adapterLabel:         string(openrtb_ext.BidderAppnexus),
+			rejectionReasonLabel: "below_floor",
+		})
+}
+
+func TestRecordAdapterBidPrice(t *testing.T) {
+	m := createMetricsForTesting()
+
+	m.RecordAdapterBidPrice(openrtb_ext.BidderAppnexus, openrtb_ext.BidTypeVideo, 1500)
+
+	histogram := getHistogramFromHistogramVecByTwoKeys(m.adapterBidPrices, adapterLabel, string(openrtb_ext.BidderAppnexus), bidTypeLabel, string(openrtb_ext.BidTypeVideo))
+	assertHistogram(t, "adapter_bid_prices", histogram, 1, 1500)
+}
+
+func TestRecordAdapterRequestSize(t *testing.T) {
+	m := createMetricsForTesting()
+
+	m.RecordAdapterRequestSize(openrtb_ext.BidderAppnexus, 3000)
+
+	histogram := getHistogramFromHistogramVec(m.adapterRequestSize, adapterLabel, string(openrtb_ext.BidderAppnexus))
+	assertHistogram(t, "adapter_request_size_bytes", histogram, 1, 3000)
+}
+
+func TestConfiguredBuckets(t *testing.T) {
+	m := NewMetrics(config.PrometheusMetrics{
+		Buckets: config.PrometheusBuckets{
+			RequestTime: []float64{0.05, 0.075, 0.1, 0.15, 0.2},
+		},
+	}, config.DisabledMetrics{})
+
+	m.RecordRequestTime(metrics.Labels{RType: metrics.ReqTypeORTB2Web, RequestStatus: metrics.RequestStatusOK}, 80*time.Millisecond)
+	m.RecordAdapterTime(metrics.AdapterLabels{Adapter: openrtb_ext.BidderAppnexus}, 80*time.Millisecond)
+
+	requestTime := getHistogramFromHistogramVec(m.requestsTimer, requestTypeLabel, string(metrics.ReqTypeORTB2Web))
+	assertBucketBounds(t, "Configured buckets", requestTime, []float64{0.05, 0.075, 0.1, 0.15, 0.2})
+	assert.Equal(t, uint64(1), requestTime.GetBucket()[2].GetCumulativeCount(), "The request falls in the 75-100ms bucket")
+
+	adapterTime := getHistogramFromHistogramVec(m.adapterRequestsTimer, adapterLabel, string(openrtb_ext.BidderAppnexus))
+	assertBucketBounds(t, "Default buckets", adapterTime, []float64{0.05, 0.1, 0.15, 0.20, 0.25, 0.3, 0.4, 0.5, 0.75, 1})
+}
+
+func assertBucketBounds(t *testing.T, description string, histogram dto.Histogram, expectedBounds []float64) {
+	bounds := make([]float64, 0, len(histogram.GetBucket()))
+	for _, bucket := range histogram.GetBucket() {
+		bounds = append(bounds, bucket.GetUpperBound())
+	}
+	assert.Equal(t, expectedBounds, bounds, description)
+}
+
+func TestRecordAnalyticsEventDropped(t *testing.T) {
+	m := createMetricsForTesting()
+
+	m.RecordAnalyticsEventDropped("kafka", "auction")
+
+	assertCounterVecValue(t,
+		"Increment analytics events dropped counter",
+		"analytics_events_dropped",
+		m.analyticsEventsDropped,
+		1,
+		prometheus.Labels{
+			analyticsModuleLabel: "kafka",
+			analyticsEventLabel:  "auction",
+		})
+}
@@ -0,0 +1,67 @@
+// Package modules builds the hook modules compiled into Prebid Server.
+//
+// To add a module, implement the hookstage interfaces of the stages it hooks into, and register a
+// builder for it in builders(). The module code used in the execution plans is "<vendor>.<module>".
+package modules
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// ModuleDeps holds the shared dependencies which are passed to the module builders.
+type ModuleDeps struct {
+	HTTPClient *http.Client
+}
+
+// ModuleBuilderFn builds a module from its host configuration. The returned value must implement
+// the hookstage interface of at least one stage.
+type ModuleBuilderFn func(cfg json.RawMessage, deps ModuleDeps) (interface{}, error)
+
+// builders lists the available modules, keyed by vendor and then by module name.
+func builders() map[string]map[string]ModuleBuilderFn {
+	return map[string]map[string]ModuleBuilderFn{}
+}
+
+// Build creates the modules enabled in the host configuration, keyed by module code.
+// The configuration is keyed by vendor and then by module name, and only modules with "enabled": true are built.
+func Build(cfg map[string]map[string]interface{}, deps ModuleDeps) (map[string]interface{}, error) {
+	return build(builders(), cfg, deps)
+}
+
+func build(builders map[string]map[string]ModuleBuilderFn, cfg map[string]map[string]interface{}, deps ModuleDeps) (map[string]interface{}, error) {
+	modules := make(map[string]interface{})
+	for vendor, vendorModules := range cfg {
+		for moduleName, moduleCfg := range vendorModules {
+			code := fmt.Sprintf("%s.%s", vendor, moduleName)
+
+			cfgJSON, err := json.Marshal(moduleCfg)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal config of module %s: %v", code, err)
+			}
+
+			var enabled struct {
+				Enabled bool `json:"enabled"`
+			}
+			if err := json.Unmarshal(cfgJSON, &enabled); err != nil {
+				return nil, fmt.Errorf("invalid config of module %s: %v", code, err)
+			}
+			if !enabled.Enabled {
+				continue
+			}
+
+			builder, ok := builders[vendor][moduleName]
+			if !ok {
+				return nil, fmt.Errorf("module %s is enabled but is not available in this build", code)
+			}
+
+			module, err := builder(cfgJSON, deps)
+			if err != nil {
+				return nil, fmt.Errorf("failed to build module %s: %v", code, err)
+			}
+			modules[code] = module
+		}
+	}
+	return modules, nil
+}
@@ -0,0 +1,60 @@
+package modules
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuild(t *testing.T) {
+	testBuilders := map[string]map[string]ModuleBuilderFn{
+		"vendor": {
+			"good": func(cfg json.RawMessage, deps ModuleDeps) (interface{}, error) {
+				return string(cfg), nil
+			},
+			"bad": func(cfg json.RawMessage, deps ModuleDeps) (interface{}, error) {
+				return nil, errors.New("bad config")
+			},
+		},
+	}
+
+	testCases := []struct {
+		description     string
+		cfg             map[string]map[string]interface{}
+		expectedModules map[string]interface{}
+		expectedErr     string
+	}{
+		{
+			description:     "Enabled module is built with its config",
+			cfg:             map[string]map[string]interface{}{"vendor": {"good": map[string]interface{}{"enabled": true, "key": "value"}}},
+			expectedModules: map[string]interface{}{"vendor.good": `{"enabled":true,"key":"value"}`},
+		},
+		{
+			description:     "Disabled modules are skipped",
+			cfg:             map[string]map[string]interface{}{"vendor": {"bad": map[string]interface{}{"enabled": false}, "unknown": map[string]interface{}{}}},
+			expectedModules: map[string]interface{}{},
+		},
+		{
+			description: "Enabled unknown module fails",
+			cfg:         map[string]map[string]interface{}{"vendor": {"unknown": map[string]interface{}{"enabled": true}}},
+			expectedErr: "module vendor.unknown is enabled but is not available in this build",
+		},
+		{
+			description: "Builder error fails",
+			cfg:         map[string]map[string]interface{}{"vendor": {"bad": map[string]interface{}{"enabled": true}}},
+			expectedErr: "failed to build module vendor.bad: bad config",
+		},
+	}
+
+	for _, test := range testCases {
+		modules, err := build(testBuilders, test.cfg, ModuleDeps{})
+		if test.expectedErr != "" {
+			assert.EqualError(t, err, test.expectedErr, test.description)
+			continue
+		}
+		assert.NoError(t, err, test.description)
+		assert.Equal(t, test.expectedModules, modules, test.description)
+	}
+}
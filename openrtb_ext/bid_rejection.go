@@ -0,0 +1,67 @@
+package openrtb_ext
+
+// RejectionReason explains why a bid was removed from the auction. The codes are in the "response rejected"
+// range of the status codes of bidresponse.ext.seatnonbid.
+type RejectionReason int
+
+const (
+	// RejectionReasonBelowFloor is for the bids priced below the floor of their imp.
+	RejectionReasonBelowFloor RejectionReason = 301
+	// RejectionReasonFloorCurrency is for the bids whose currency the floor can't be converted to.
+	RejectionReasonFloorCurrency RejectionReason = 302
+	// RejectionReasonCategoryMapping is for the bids without a category, or whose category can't be mapped.
+	RejectionReasonCategoryMapping RejectionReason = 303
+	// RejectionReasonDuration is for the video bids longer than the longest duration allowed.
+	RejectionReasonDuration RejectionReason = 304
+	// RejectionReasonDuplicate is for the bids which lost the competitive separation against a bid with the
+	// same category or price bucket and duration.
+	RejectionReasonDuplicate RejectionReason = 305
+	// RejectionReasonCurrencyNotAllowed is for the bids made in a currency the request doesn't allow.
+	RejectionReasonCurrencyNotAllowed RejectionReason = 306
+	// RejectionReasonCurrencyConversion is for the bids made in a currency which can't be converted to one
+	// the request allows.
+	RejectionReasonCurrencyConversion RejectionReason = 307
+	// RejectionReasonModule is for the bids removed by the hooks of a module.
+	RejectionReasonModule RejectionReason = 308
+	// RejectionReasonMultiBidLimit is for the bids ranked below the maxbids best bids of their bidder on the imp.
+	RejectionReasonMultiBidLimit RejectionReason = 309
+	// RejectionReasonInvalidBid is for the bids missing a required field, or without a positive price.
+	RejectionReasonInvalidBid RejectionReason = 350
+)
+
+var rejectionReasonNames = map[RejectionReason]string{
+	RejectionReasonBelowFloor:         "below_floor",
+	RejectionReasonFloorCurrency:      "floor_currency",
+	RejectionReasonCategoryMapping:    "category_mapping",
+	RejectionReasonDuration:           "duration",
+	RejectionReasonDuplicate:          "duplicate",
+	RejectionReasonCurrencyNotAllowed: "currency_not_allowed",
+	RejectionReasonCurrencyConversion: "currency_conversion",
+	RejectionReasonModule:             "module",
+	RejectionReasonMultiBidLimit:      "multibid_limit",
+	RejectionReasonInvalidBid:         "invalid_bid",
+}
+
+// RejectionReasons returns all the reasons why a bid can be rejected.
+func RejectionReasons() []RejectionReason {
+	return []RejectionReason{
+		RejectionReasonBelowFloor,
+		RejectionReasonFloorCurrency,
+		RejectionReasonCategoryMapping,
+		RejectionReasonDuration,
+		RejectionReasonDuplicate,
+		RejectionReasonCurrencyNotAllowed,
+		RejectionReasonCurrencyConversion,
+		RejectionReasonModule,
+		RejectionReasonMultiBidLimit,
+		RejectionReasonInvalidBid,
+	}
+}
+
+// String returns the name of the reason, as used in the metrics.
+func (r RejectionReason) String() string {
+	if name, ok := rejectionReasonNames[r]; ok {
+		return name
+	}
+	return "unknown"
+}
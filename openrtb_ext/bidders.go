@@ -194,9 +194,9 @@ const (
 	BidderZeroClickFraud    BidderName = "zeroclickfraud"
 )
 
-// CoreBidderNames returns a slice of all core bidders.
+// CoreBidderNames returns a slice of all core bidders, followed by the hard aliases registered through SetAliasBidderName.
 func CoreBidderNames() []BidderName {
-	return []BidderName{
+	return append([]BidderName{
 		Bidder33Across,
 		BidderAcuityAds,
 		BidderAdf,
@@ -316,7 +316,48 @@ func CoreBidderNames() []BidderName {
 		BidderYieldmo,
 		BidderYieldone,
 		BidderZeroClickFraud,
+	}, aliasBidderNames...)
+}
+
+// aliasBidderNames lists the hard aliases defined in the host config, and aliasBidderToParent maps them to the
+// core bidder they are an alias of.
+var (
+	aliasBidderNames    []BidderName
+	aliasBidderToParent = map[BidderName]BidderName{}
+)
+
+// SetAliasBidderName registers a hard alias of a core bidder, so that it is known like any other core bidder.
+// This must be called on startup, before the bidder lists of this package are used.
+func SetAliasBidderName(aliasBidderName string, parentBidderName string) error {
+	if IsBidderNameReserved(aliasBidderName) {
+		return fmt.Errorf("alias %s is a reserved bidder name and cannot be used", aliasBidderName)
+	}
+
+	parent, parentFound := NormalizeBidderName(parentBidderName)
+	if !parentFound {
+		return fmt.Errorf("alias %s refers to unknown bidder: %s", aliasBidderName, parentBidderName)
+	}
+	if _, parentIsAlias := aliasBidderToParent[parent]; parentIsAlias {
+		return fmt.Errorf("alias %s refers to another alias: %s", aliasBidderName, parentBidderName)
+	}
+
+	alias := BidderName(aliasBidderName)
+	if registeredParent, isAlias := aliasBidderToParent[alias]; isAlias && registeredParent == parent {
+		return nil
+	}
+	if _, exists := NormalizeBidderName(aliasBidderName); exists {
+		return fmt.Errorf("alias %s is already a bidder name", aliasBidderName)
 	}
+
+	aliasBidderNames = append(aliasBidderNames, alias)
+	aliasBidderToParent[alias] = parent
+	bidderNameLookup[strings.ToLower(aliasBidderName)] = alias
+	return nil
+}
+
+// GetAliasBidderToParent returns the hard aliases registered through SetAliasBidderName, mapped to their core bidder.
+func GetAliasBidderToParent() map[BidderName]BidderName {
+	return aliasBidderToParent
 }
 
 // BuildBidderMap builds a map of string to BidderName, to remain compatbile with the
@@ -408,6 +449,12 @@ func NewBidderParamsValidator(schemaDirectory string) (BidderParamValidator, err
 		schemaContents[BidderName(bidderName)] = string(fileBytes)
 	}
 
+	// Hard aliases take the same params as their core bidder
+	for alias, parent := range aliasBidderToParent {
+		schemas[alias] = schemas[parent]
+		schemaContents[alias] = schemaContents[parent]
+	}
+
 	return &bidderParamValidator{
 		schemaContents: schemaContents,
 		parsedSchemas:  schemas,
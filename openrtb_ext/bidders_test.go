@@ -2,6 +2,7 @@ package openrtb_ext
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -118,3 +119,94 @@ func TestIsBidderNameReserved(t *testing.T) {
 		assert.Equal(t, test.expected, result, test.bidder)
 	}
 }
+
+func TestSetAliasBidderName(t *testing.T) {
+	defer resetAliasBidderNames()
+
+	testCases := []struct {
+		description   string
+		alias         string
+		parent        string
+		expectedError string
+	}{
+		{
+			description: "Valid",
+			alias:       "whitelabel",
+			parent:      "appnexus",
+		},
+		{
+			description: "Same alias registered again",
+			alias:       "whitelabel",
+			parent:      "appnexus",
+		},
+		{
+			description:   "Same alias with another parent",
+			alias:         "whitelabel",
+			parent:        "rubicon",
+			expectedError: "alias whitelabel is already a bidder name",
+		},
+		{
+			description:   "Core bidder name",
+			alias:         "rubicon",
+			parent:        "appnexus",
+			expectedError: "alias rubicon is already a bidder name",
+		},
+		{
+			description:   "Reserved name",
+			alias:         "context",
+			parent:        "appnexus",
+			expectedError: "alias context is a reserved bidder name and cannot be used",
+		},
+		{
+			description:   "Unknown parent",
+			alias:         "other",
+			parent:        "unknown",
+			expectedError: "alias other refers to unknown bidder: unknown",
+		},
+		{
+			description:   "Alias of an alias",
+			alias:         "other",
+			parent:        "whitelabel",
+			expectedError: "alias other refers to another alias: whitelabel",
+		},
+	}
+
+	for _, test := range testCases {
+		err := SetAliasBidderName(test.alias, test.parent)
+		if test.expectedError == "" {
+			assert.NoError(t, err, test.description)
+		} else {
+			assert.EqualError(t, err, test.expectedError, test.description)
+		}
+	}
+
+	assert.Equal(t, map[BidderName]BidderName{"whitelabel": BidderAppnexus}, GetAliasBidderToParent())
+	assert.Contains(t, CoreBidderNames(), BidderName("whitelabel"))
+	assert.Equal(t, BidderName("whitelabel"), BuildBidderMap()["whitelabel"])
+
+	bidderName, found := NormalizeBidderName("WhiteLabel")
+	assert.True(t, found)
+	assert.Equal(t, BidderName("whitelabel"), bidderName)
+}
+
+func TestBidderParamsValidatorAliasSchema(t *testing.T) {
+	defer resetAliasBidderNames()
+	assert.NoError(t, SetAliasBidderName("whitelabel", "appnexus"))
+
+	validator, err := NewBidderParamsValidator("../" + schemaDirectory)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, validator.Schema(BidderAppnexus), validator.Schema("whitelabel"))
+	assert.NoError(t, validator.Validate("whitelabel", json.RawMessage(`{"placementId":123}`)))
+	assert.Error(t, validator.Validate("whitelabel", json.RawMessage(`{"placementId":"wrong"}`)))
+}
+
+func resetAliasBidderNames() {
+	for alias := range aliasBidderToParent {
+		delete(bidderNameLookup, strings.ToLower(string(alias)))
+	}
+	aliasBidderNames = nil
+	aliasBidderToParent = map[BidderName]BidderName{}
+}
@@ -0,0 +1,69 @@
+package openrtb_ext
+
+// PriceFloorRules defines the contract for bidrequest.ext.prebid.floors
+type PriceFloorRules struct {
+	Enabled     *bool                  `json:"enabled,omitempty"`
+	FloorMin    float64                `json:"floormin,omitempty"`
+	FloorMinCur string                 `json:"floormincur,omitempty"`
+	Enforcement *PriceFloorEnforcement `json:"enforcement,omitempty"`
+	Data        *PriceFloorData        `json:"data,omitempty"`
+}
+
+// PriceFloorEnforcement defines the contract for bidrequest.ext.prebid.floors.enforcement
+type PriceFloorEnforcement struct {
+	// EnforcePBS specifies whether Prebid Server drops bids below the floor. Defaults to true.
+	EnforcePBS *bool `json:"enforcepbs,omitempty"`
+	// FloorDeals specifies whether floors are enforced on deal bids. Defaults to false.
+	FloorDeals *bool `json:"floordeals,omitempty"`
+	// EnforceRate is the percentage of auctions (0-100) in which floors are enforced.
+	EnforceRate *int `json:"enforcerate,omitempty"`
+}
+
+// PriceFloorData defines the contract for bidrequest.ext.prebid.floors.data
+type PriceFloorData struct {
+	Currency    string                 `json:"currency,omitempty"`
+	ModelGroups []PriceFloorModelGroup `json:"modelgroups,omitempty"`
+}
+
+// PriceFloorModelGroup defines the contract for bidrequest.ext.prebid.floors.data.modelgroups[i]
+type PriceFloorModelGroup struct {
+	Currency     string             `json:"currency,omitempty"`
+	ModelWeight  int                `json:"modelweight,omitempty"`
+	ModelVersion string             `json:"modelversion,omitempty"`
+	Schema       PriceFloorSchema   `json:"schema"`
+	Values       map[string]float64 `json:"values"`
+	Default      float64            `json:"default,omitempty"`
+}
+
+// PriceFloorSchema defines the contract for bidrequest.ext.prebid.floors.data.modelgroups[i].schema
+type PriceFloorSchema struct {
+	Fields    []string `json:"fields"`
+	Delimiter string   `json:"delimiter,omitempty"`
+}
+
+// Supported values of bidrequest.ext.prebid.floors.data.modelgroups[i].schema.fields
+const (
+	FloorSchemaMediaType  = "mediaType"
+	FloorSchemaSize       = "size"
+	FloorSchemaDomain     = "domain"
+	FloorSchemaSiteDomain = "siteDomain"
+	FloorSchemaPubDomain  = "pubDomain"
+	FloorSchemaBundle     = "bundle"
+	FloorSchemaGptSlot    = "gptSlot"
+	FloorSchemaPbAdSlot   = "pbAdSlot"
+	FloorSchemaCountry    = "country"
+)
+
+// FloorSchemaWildcard matches any value of a schema field.
+const FloorSchemaWildcard = "*"
+
+// FloorSchemaDefaultDelimiter separates the schema field values of a rule when no delimiter is given.
+const FloorSchemaDefaultDelimiter = "|"
+
+// ExtBidPrebidFloors defines the contract for bidresponse.seatbid.bid[i].ext.prebid.floors
+type ExtBidPrebidFloors struct {
+	FloorRule      string  `json:"floorRule,omitempty"`
+	FloorRuleValue float64 `json:"floorRuleValue,omitempty"`
+	FloorValue     float64 `json:"floorValue,omitempty"`
+	FloorCurrency  string  `json:"floorCurrency,omitempty"`
+}
@@ -9,6 +9,12 @@ type ExtImpPrebid struct {
 	// StoredRequest specifies which stored impression to use, if any.
 	StoredRequest *ExtStoredRequest `json:"storedrequest"`
 
+	// StoredAuctionResponse specifies the stored seatbids to respond with instead of running the auction, if any.
+	StoredAuctionResponse *ExtStoredAuctionResponse `json:"storedauctionresponse,omitempty"`
+
+	// StoredBidResponse specifies the stored bidder responses to use instead of calling the bidders, if any.
+	StoredBidResponse []ExtStoredBidResponse `json:"storedbidresponse,omitempty"`
+
 	// IsRewardedInventory is a signal intended for video impressions. Must be 0 or 1.
 	IsRewardedInventory int8 `json:"is_rewarded_inventory"`
 
@@ -20,3 +26,14 @@ type ExtImpPrebid struct {
 type ExtStoredRequest struct {
 	ID string `json:"id"`
 }
+
+// ExtStoredAuctionResponse defines the contract for bidrequest.imp[i].ext.prebid.storedauctionresponse
+type ExtStoredAuctionResponse struct {
+	ID string `json:"id"`
+}
+
+// ExtStoredBidResponse defines the contract for bidrequest.imp[i].ext.prebid.storedbidresponse
+type ExtStoredBidResponse struct {
+	Bidder string `json:"bidder"`
+	ID     string `json:"id"`
+}
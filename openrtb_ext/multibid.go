@@ -0,0 +1,85 @@
+package openrtb_ext
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prebid/prebid-server/errortypes"
+)
+
+// MaxBidLimit is the highest number of bids per imp a bidder may be allowed through multibid.
+const MaxBidLimit = 9
+
+// ExtMultiBid defines the contract for bidrequest.ext.prebid.multibid
+type ExtMultiBid struct {
+	// Bidder and Bidders name the bidders the entry applies to. Only one of them may be set.
+	Bidder  string   `json:"bidder,omitempty"`
+	Bidders []string `json:"bidders,omitempty"`
+	// MaxBids is the number of bids per imp the bidders may return. Required, between 1 and MaxBidLimit.
+	MaxBids *int `json:"maxbids,omitempty"`
+	// TargetBidderCodePrefix names the bidder in the targeting keys of the extra bids, followed by the rank of the bid.
+	// Only allowed with Bidder. Extra bids don't get targeting keys without it.
+	TargetBidderCodePrefix string `json:"targetbiddercodeprefix,omitempty"`
+}
+
+// ValidateMultiBid resolves the multibid entries of the request by bidder name. Entries which can't be applied
+// are ignored, and out of range maxbids values are clamped. Every correction is reported as a warning.
+func ValidateMultiBid(entries []*ExtMultiBid) (map[string]ExtMultiBid, []error) {
+	multiBid := make(map[string]ExtMultiBid, len(entries))
+	var warnings []error
+
+	for i, entry := range entries {
+		if entry == nil {
+			continue
+		}
+
+		bidders := entry.Bidders
+		prefix := entry.TargetBidderCodePrefix
+		if entry.Bidder != "" {
+			if len(entry.Bidders) > 0 {
+				warnings = append(warnings, multiBidWarning(i, "bidders ignored as bidder is also defined"))
+			}
+			bidders = []string{entry.Bidder}
+		} else if len(entry.Bidders) == 0 {
+			warnings = append(warnings, multiBidWarning(i, "entry ignored as neither bidder nor bidders is defined"))
+			continue
+		} else if prefix != "" {
+			warnings = append(warnings, multiBidWarning(i, "targetbiddercodeprefix ignored as it requires bidder instead of bidders"))
+			prefix = ""
+		}
+
+		if entry.MaxBids == nil {
+			warnings = append(warnings, multiBidWarning(i, fmt.Sprintf("entry for %s ignored as maxbids is not defined", strings.Join(bidders, ","))))
+			continue
+		}
+		maxBids := *entry.MaxBids
+		if maxBids < 1 {
+			warnings = append(warnings, multiBidWarning(i, fmt.Sprintf("maxbids %d raised to 1", maxBids)))
+			maxBids = 1
+		} else if maxBids > MaxBidLimit {
+			warnings = append(warnings, multiBidWarning(i, fmt.Sprintf("maxbids %d lowered to %d", maxBids, MaxBidLimit)))
+			maxBids = MaxBidLimit
+		}
+
+		for _, bidder := range bidders {
+			if _, ok := multiBid[bidder]; ok {
+				warnings = append(warnings, multiBidWarning(i, fmt.Sprintf("bidder %s ignored as it is already defined by a previous entry", bidder)))
+				continue
+			}
+			multiBid[bidder] = ExtMultiBid{
+				Bidder:                 bidder,
+				MaxBids:                &maxBids,
+				TargetBidderCodePrefix: prefix,
+			}
+		}
+	}
+
+	return multiBid, warnings
+}
+
+func multiBidWarning(index int, message string) error {
+	return &errortypes.Warning{
+		Message:     fmt.Sprintf("request.ext.prebid.multibid[%d]: %s", index, message),
+		WarningCode: errortypes.MultiBidWarningCode,
+	}
+}
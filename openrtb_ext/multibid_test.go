@@ -0,0 +1,109 @@
+package openrtb_ext
+
+import (
+	"testing"
+
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateMultiBid(t *testing.T) {
+	intPtr := func(i int) *int { return &i }
+
+	testCases := []struct {
+		description      string
+		entries          []*ExtMultiBid
+		expectedMultiBid map[string]ExtMultiBid
+		expectedWarnings []string
+	}{
+		{
+			description:      "No entries",
+			entries:          nil,
+			expectedMultiBid: map[string]ExtMultiBid{},
+		},
+		{
+			description: "Valid bidder and bidders entries",
+			entries: []*ExtMultiBid{
+				{Bidder: "appnexus", MaxBids: intPtr(3), TargetBidderCodePrefix: "apn"},
+				{Bidders: []string{"rubicon", "pubmatic"}, MaxBids: intPtr(2)},
+			},
+			expectedMultiBid: map[string]ExtMultiBid{
+				"appnexus": {Bidder: "appnexus", MaxBids: intPtr(3), TargetBidderCodePrefix: "apn"},
+				"rubicon":  {Bidder: "rubicon", MaxBids: intPtr(2)},
+				"pubmatic": {Bidder: "pubmatic", MaxBids: intPtr(2)},
+			},
+		},
+		{
+			description: "Bidders ignored when bidder is defined",
+			entries: []*ExtMultiBid{
+				{Bidder: "appnexus", Bidders: []string{"rubicon"}, MaxBids: intPtr(2)},
+			},
+			expectedMultiBid: map[string]ExtMultiBid{
+				"appnexus": {Bidder: "appnexus", MaxBids: intPtr(2)},
+			},
+			expectedWarnings: []string{"request.ext.prebid.multibid[0]: bidders ignored as bidder is also defined"},
+		},
+		{
+			description: "Prefix ignored with bidders",
+			entries: []*ExtMultiBid{
+				{Bidders: []string{"rubicon"}, MaxBids: intPtr(2), TargetBidderCodePrefix: "rp"},
+			},
+			expectedMultiBid: map[string]ExtMultiBid{
+				"rubicon": {Bidder: "rubicon", MaxBids: intPtr(2)},
+			},
+			expectedWarnings: []string{"request.ext.prebid.multibid[0]: targetbiddercodeprefix ignored as it requires bidder instead of bidders"},
+		},
+		{
+			description: "Entries without bidders or maxbids are ignored",
+			entries: []*ExtMultiBid{
+				{MaxBids: intPtr(2)},
+				{Bidder: "appnexus"},
+				nil,
+			},
+			expectedMultiBid: map[string]ExtMultiBid{},
+			expectedWarnings: []string{
+				"request.ext.prebid.multibid[0]: entry ignored as neither bidder nor bidders is defined",
+				"request.ext.prebid.multibid[1]: entry for appnexus ignored as maxbids is not defined",
+			},
+		},
+		{
+			description: "Out of range maxbids are clamped",
+			entries: []*ExtMultiBid{
+				{Bidder: "appnexus", MaxBids: intPtr(0)},
+				{Bidder: "rubicon", MaxBids: intPtr(10)},
+			},
+			expectedMultiBid: map[string]ExtMultiBid{
+				"appnexus": {Bidder: "appnexus", MaxBids: intPtr(1)},
+				"rubicon":  {Bidder: "rubicon", MaxBids: intPtr(MaxBidLimit)},
+			},
+			expectedWarnings: []string{
+				"request.ext.prebid.multibid[0]: maxbids 0 raised to 1",
+				"request.ext.prebid.multibid[1]: maxbids 10 lowered to 9",
+			},
+		},
+		{
+			description: "Duplicate bidders keep the first entry",
+			entries: []*ExtMultiBid{
+				{Bidder: "appnexus", MaxBids: intPtr(2)},
+				{Bidders: []string{"appnexus", "rubicon"}, MaxBids: intPtr(3)},
+			},
+			expectedMultiBid: map[string]ExtMultiBid{
+				"appnexus": {Bidder: "appnexus", MaxBids: intPtr(2)},
+				"rubicon":  {Bidder: "rubicon", MaxBids: intPtr(3)},
+			},
+			expectedWarnings: []string{"request.ext.prebid.multibid[1]: bidder appnexus ignored as it is already defined by a previous entry"},
+		},
+	}
+
+	for _, test := range testCases {
+		multiBid, warnings := ValidateMultiBid(test.entries)
+		assert.Equal(t, test.expectedMultiBid, multiBid, test.description)
+
+		var warningMessages []string
+		for _, warning := range warnings {
+			assert.Equal(t, errortypes.MultiBidWarningCode, errortypes.ReadCode(warning), test.description)
+			warningMessages = append(warningMessages, warning.Error())
+		}
+		assert.Equal(t, test.expectedWarnings, warningMessages, test.description)
+	}
+}
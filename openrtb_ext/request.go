@@ -26,16 +26,21 @@ type ExtRequest struct {
 
 // ExtRequestPrebid defines the contract for bidrequest.ext.prebid
 type ExtRequestPrebid struct {
-	Aliases              map[string]string         `json:"aliases,omitempty"`
-	BidAdjustmentFactors map[string]float64        `json:"bidadjustmentfactors,omitempty"`
-	Cache                *ExtRequestPrebidCache    `json:"cache,omitempty"`
-	Data                 *ExtRequestPrebidData     `json:"data,omitempty"`
-	Debug                bool                      `json:"debug,omitempty"`
-	Events               json.RawMessage           `json:"events,omitempty"`
-	SChains              []*ExtRequestPrebidSChain `json:"schains,omitempty"`
-	StoredRequest        *ExtStoredRequest         `json:"storedrequest,omitempty"`
-	SupportDeals         bool                      `json:"supportdeals,omitempty"`
-	Targeting            *ExtRequestTargeting      `json:"targeting,omitempty"`
+	Aliases              map[string]string              `json:"aliases,omitempty"`
+	AuctionTimeout       int64                          `json:"auctiontimeout,omitempty"`
+	BidAdjustmentFactors map[string]float64             `json:"bidadjustmentfactors,omitempty"`
+	BidderConfigs        []ExtRequestPrebidBidderConfig `json:"bidderconfig,omitempty"`
+	Cache                *ExtRequestPrebidCache         `json:"cache,omitempty"`
+	Data                 *ExtRequestPrebidData          `json:"data,omitempty"`
+	Debug                bool                           `json:"debug,omitempty"`
+	Events               json.RawMessage                `json:"events,omitempty"`
+	Floors               *PriceFloorRules               `json:"floors,omitempty"`
+	MultiBid             []*ExtMultiBid                 `json:"multibid,omitempty"`
+	ReturnAllBidStatus   bool                           `json:"returnallbidstatus,omitempty"`
+	SChains              []*ExtRequestPrebidSChain      `json:"schains,omitempty"`
+	StoredRequest        *ExtStoredRequest              `json:"storedrequest,omitempty"`
+	SupportDeals         bool                           `json:"supportdeals,omitempty"`
+	Targeting            *ExtRequestTargeting           `json:"targeting,omitempty"`
 
 	// NoSale specifies bidders with whom the publisher has a legal relationship where the
 	// passing of personally identifiable information doesn't constitute a sale per CCPA law.
@@ -311,6 +316,28 @@ var priceGranularityAuto = PriceGranularity{
 // ExtRequestPrebidData defines Prebid's First Party Data (FPD) and related bid request options.
 type ExtRequestPrebidData struct {
 	EidPermissions []ExtRequestPrebidDataEidPermission `json:"eidpermissions"`
+	// Bidders lists the bidders allowed to receive the global FPD in site.ext.data, app.ext.data and user.ext.data.
+	// Every bidder receives it if the list is empty.
+	Bidders []string `json:"bidders,omitempty"`
+}
+
+// ExtRequestPrebidBidderConfig defines the contract for bidrequest.ext.prebid.bidderconfig
+type ExtRequestPrebidBidderConfig struct {
+	Bidders []string         `json:"bidders,omitempty"`
+	Config  *ExtBidderConfig `json:"config,omitempty"`
+}
+
+// ExtBidderConfig defines the contract for bidrequest.ext.prebid.bidderconfig[i].config
+type ExtBidderConfig struct {
+	ORTB2 *ExtBidderConfigORTB2 `json:"ortb2,omitempty"`
+}
+
+// ExtBidderConfigORTB2 defines the contract for bidrequest.ext.prebid.bidderconfig[i].config.ortb2
+// Each object is merged into the matching object of the requests sent to the bidders.
+type ExtBidderConfigORTB2 struct {
+	Site json.RawMessage `json:"site,omitempty"`
+	App  json.RawMessage `json:"app,omitempty"`
+	User json.RawMessage `json:"user,omitempty"`
 }
 
 // ExtRequestPrebidDataEidPermission defines a filter rule for filter user.ext.eids
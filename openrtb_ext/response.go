@@ -1,6 +1,10 @@
 package openrtb_ext
 
-import "github.com/mxmCherry/openrtb/v15/openrtb2"
+import (
+	"encoding/json"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+)
 
 // ExtBidResponse defines the contract for bidresponse.ext
 type ExtBidResponse struct {
@@ -18,6 +22,39 @@ type ExtBidResponse struct {
 	Usersync map[BidderName]*ExtResponseSyncData `json:"usersync,omitempty"`
 	// Prebid defines the contract for bidresponse.ext.prebid
 	Prebid *ExtResponsePrebid `json:"prebid,omitempty"`
+	// SeatNonBid lists the bids which were rejected, per seat
+	SeatNonBid []SeatNonBid `json:"seatnonbid,omitempty"`
+}
+
+// SeatNonBid defines the contract for bidresponse.ext.seatnonbid[i]
+type SeatNonBid struct {
+	Seat   string   `json:"seat"`
+	NonBid []NonBid `json:"nonbid"`
+}
+
+// NonBid defines the contract for bidresponse.ext.seatnonbid[i].nonbid[j]
+type NonBid struct {
+	ImpID      string          `json:"impid"`
+	StatusCode RejectionReason `json:"statuscode"`
+	Ext        NonBidExt       `json:"ext"`
+}
+
+// NonBidExt defines the contract for bidresponse.ext.seatnonbid[i].nonbid[j].ext
+type NonBidExt struct {
+	Prebid NonBidExtPrebid `json:"prebid"`
+}
+
+// NonBidExtPrebid defines the contract for bidresponse.ext.seatnonbid[i].nonbid[j].ext.prebid
+type NonBidExtPrebid struct {
+	Bid NonBidExtPrebidBid `json:"bid"`
+	// Reason details the status code, such as the missing field or the floor the bid was below
+	Reason string `json:"reason,omitempty"`
+}
+
+// NonBidExtPrebidBid defines the contract for bidresponse.ext.seatnonbid[i].nonbid[j].ext.prebid.bid
+type NonBidExtPrebidBid struct {
+	ID    string  `json:"id"`
+	Price float64 `json:"price"`
 }
 
 // ExtResponseDebug defines the contract for bidresponse.ext.debug
@@ -38,6 +75,17 @@ type ExtResponseSyncData struct {
 // ExtResponsePrebid defines the contract for bidresponse.ext.prebid
 type ExtResponsePrebid struct {
 	AuctionTimestamp int64 `json:"auctiontimestamp,omitempty"`
+	// Modules holds the errors, warnings and trace of the hooks which ran for the request.
+	Modules json.RawMessage `json:"modules,omitempty"`
+	// StoredRequestVariants holds the variants picked for the Stored Requests and Imps split into weighted variants.
+	StoredRequestVariants *ExtStoredRequestVariants `json:"storedrequestvariants,omitempty"`
+}
+
+// ExtStoredRequestVariants defines the contract for bidresponse.ext.prebid.storedrequestvariants.
+// The IDs of the picked variants are keyed by the IDs of the Stored Requests and Imps.
+type ExtStoredRequestVariants struct {
+	Requests map[string]string `json:"requests,omitempty"`
+	Imps     map[string]string `json:"imps,omitempty"`
 }
 
 // ExtUserSync defines the contract for bidresponse.ext.usersync.{bidder}.syncs[i]
@@ -0,0 +1,37 @@
+package openrtb_proto
+
+import (
+	"fmt"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+)
+
+// Codec encodes the OpenRTB structs as the messages of openrtb.proto. It implements the gRPC codec interface,
+// so that the auction service can work with the structs used by the rest of Prebid Server rather than with
+// generated protobuf messages.
+type Codec struct{}
+
+func (Codec) Marshal(v interface{}) ([]byte, error) {
+	switch m := v.(type) {
+	case *openrtb2.BidRequest:
+		return MarshalBidRequest(m), nil
+	case *openrtb2.BidResponse:
+		return MarshalBidResponse(m), nil
+	}
+	return nil, fmt.Errorf("openrtb_proto: cannot marshal %T", v)
+}
+
+func (Codec) Unmarshal(data []byte, v interface{}) error {
+	switch m := v.(type) {
+	case *openrtb2.BidRequest:
+		return UnmarshalBidRequest(data, m)
+	case *openrtb2.BidResponse:
+		return UnmarshalBidResponse(data, m)
+	}
+	return fmt.Errorf("openrtb_proto: cannot unmarshal into %T", v)
+}
+
+// Name is the content subtype of the codec. gRPC clients send protobuf as "application/grpc+proto".
+func (Codec) Name() string {
+	return "proto"
+}
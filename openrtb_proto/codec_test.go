@@ -0,0 +1,257 @@
+package openrtb_proto
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestBidRequestRoundTrip(t *testing.T) {
+	secure := int8(1)
+	skip := int8(0)
+	dnt := int8(0)
+	req := &openrtb2.BidRequest{
+		ID: "request-1",
+		Imp: []openrtb2.Imp{
+			{
+				ID: "imp-1",
+				Banner: &openrtb2.Banner{
+					W:      openrtb2.Int64Ptr(300),
+					H:      openrtb2.Int64Ptr(250),
+					Format: []openrtb2.Format{{W: 300, H: 250}, {W: 320, H: 50}},
+					Pos:    openrtb2.AdPosition(1).Ptr(),
+					BType:  []openrtb2.BannerAdType{1, 4},
+					API:    []openrtb2.APIFramework{3, 5},
+				},
+				TagID:       "tag-1",
+				BidFloor:    1.25,
+				BidFloorCur: "EUR",
+				Secure:      &secure,
+				PMP: &openrtb2.PMP{
+					PrivateAuction: 1,
+					Deals:          []openrtb2.Deal{{ID: "deal-1", BidFloor: 2.5, BidFloorCur: "USD", WSeat: []string{"seat-1"}, Ext: json.RawMessage(`{"priority":1}`)}},
+				},
+				Ext: json.RawMessage(`{"prebid":{"bidder":{"appnexus":{"placementId":12883451}}}}`),
+			},
+			{
+				ID: "imp-2",
+				Video: &openrtb2.Video{
+					MIMEs:         []string{"video/mp4"},
+					MinDuration:   5,
+					MaxDuration:   30,
+					Protocols:     []openrtb2.Protocol{2, 3},
+					W:             640,
+					H:             480,
+					StartDelay:    openrtb2.StartDelay(-1).Ptr(),
+					Skip:          &skip,
+					Placement:     1,
+					BoxingAllowed: 1,
+				},
+				Native:      &openrtb2.Native{Request: `{"ver":"1.2"}`, Ver: "1.2"},
+				Audio:       &openrtb2.Audio{MIMEs: []string{"audio/mp4"}, NVol: openrtb2.VolumeNormalizationMode(2).Ptr()},
+				Metric:      []openrtb2.Metric{{Type: "viewability", Value: 0.8}},
+				Exp:         300,
+				BidFloorCur: "USD",
+			},
+		},
+		Site: &openrtb2.Site{
+			ID:        "site-1",
+			Page:      "https://prebid.org/page",
+			Publisher: &openrtb2.Publisher{ID: "account-1", Ext: json.RawMessage(`{"prebid":{"parentAccount":"parent-1"}}`)},
+			Content: &openrtb2.Content{
+				ID:         "content-1",
+				Episode:    3,
+				Producer:   &openrtb2.Producer{ID: "producer-1"},
+				Cat:        []string{"IAB1"},
+				LiveStream: 1,
+				Len:        120,
+				Context:    openrtb2.ContentContext(1),
+				Data:       []openrtb2.Data{{ID: "data-2"}},
+			},
+			Mobile: 1,
+		},
+		Device: &openrtb2.Device{
+			UA:             "Mozilla/5.0",
+			IP:             "1.2.3.4",
+			Geo:            &openrtb2.Geo{Lat: 52.52, Lon: 13.4, Country: "DEU", UTCOffset: -60},
+			DNT:            &dnt,
+			ConnectionType: openrtb2.ConnectionType(2).Ptr(),
+			PxRatio:        2,
+		},
+		User: &openrtb2.User{
+			BuyerUID: "buyer-1",
+			Yob:      1980,
+			Data:     []openrtb2.Data{{ID: "data-1", Segment: []openrtb2.Segment{{ID: "segment-1"}}}},
+			Ext:      json.RawMessage(`{"consent":"BONV8oqONXwgmADACHENAO7pqzAAppY"}`),
+		},
+		AT:     1,
+		TMax:   500,
+		Cur:    []string{"USD"},
+		BCat:   []string{"IAB25"},
+		Regs:   &openrtb2.Regs{COPPA: 1, Ext: json.RawMessage(`{"gdpr":1}`)},
+		Test:   1,
+		Source: &openrtb2.Source{TID: "tid-1"},
+		Ext:    json.RawMessage(`{"prebid":{"targeting":{},"storedrequest":{"id":"stored-1"}}}`),
+	}
+
+	var decoded openrtb2.BidRequest
+	assert.NoError(t, UnmarshalBidRequest(MarshalBidRequest(req), &decoded))
+	assert.Equal(t, req, &decoded)
+}
+
+func TestBidResponseRoundTrip(t *testing.T) {
+	resp := &openrtb2.BidResponse{
+		ID: "request-1",
+		SeatBid: []openrtb2.SeatBid{
+			{
+				Seat: "appnexus",
+				Bid: []openrtb2.Bid{
+					{
+						ID:      "bid-1",
+						ImpID:   "imp-1",
+						Price:   1.5,
+						AdM:     "<div>ad</div>",
+						ADomain: []string{"prebid.org"},
+						CrID:    "creative-1",
+						Attr:    []openrtb2.CreativeAttribute{1, 3},
+						W:       300,
+						H:       250,
+						Ext:     json.RawMessage(`{"prebid":{"type":"banner","targeting":{"hb_pb":"1.50"}}}`),
+					},
+					{
+						ID:    "bid-2",
+						ImpID: "imp-2",
+					},
+				},
+			},
+		},
+		Cur: "USD",
+		Ext: json.RawMessage(`{"responsetimemillis":{"appnexus":12}}`),
+	}
+
+	var decoded openrtb2.BidResponse
+	assert.NoError(t, UnmarshalBidResponse(MarshalBidResponse(resp), &decoded))
+	assert.Equal(t, resp, &decoded)
+
+	noBidResponse := &openrtb2.BidResponse{ID: "request-2", NBR: openrtb2.NoBidReasonCode(2).Ptr()}
+	decoded = openrtb2.BidResponse{}
+	assert.NoError(t, UnmarshalBidResponse(MarshalBidResponse(noBidResponse), &decoded))
+	assert.Equal(t, noBidResponse, &decoded)
+}
+
+func TestUnmarshalBidRequest(t *testing.T) {
+	testCases := []struct {
+		description   string
+		data          []byte
+		expectedReq   *openrtb2.BidRequest
+		expectedError string
+	}{
+		{
+			description: "Unknown fields are skipped",
+			data: func() []byte {
+				b := appendString(nil, 1, "request-1")
+				site := appendString(nil, 7, "https://prebid.org")
+				site = appendInt(site, 99, 1)
+				b = appendMessage(b, 3, site)
+				return appendString(b, 5000, "unknown extension")
+			}(),
+			expectedReq: &openrtb2.BidRequest{ID: "request-1", Site: &openrtb2.Site{Page: "https://prebid.org"}, AT: 2},
+		},
+		{
+			description: "Auction type",
+			data:        appendInt(nil, 7, 1),
+			expectedReq: &openrtb2.BidRequest{AT: 1},
+		},
+		{
+			description: "Site content",
+			data: func() []byte {
+				producer := appendString(nil, 2, "producer-1")
+				content := appendString(nil, 1, "content-1")
+				content = appendMessage(content, 15, producer)
+				content = appendInt(content, 25, 2)
+				content = appendMessage(content, 28, appendString(nil, 1, "data-1"))
+				return appendMessage(nil, 3, appendMessage(nil, 12, content))
+			}(),
+			expectedReq: &openrtb2.BidRequest{
+				Site: &openrtb2.Site{Content: &openrtb2.Content{
+					ID:       "content-1",
+					Producer: &openrtb2.Producer{Name: "producer-1"},
+					ProdQ:    openrtb2.ProductionQuality(2).Ptr(),
+					Data:     []openrtb2.Data{{ID: "data-1"}},
+				}},
+				AT: 2,
+			},
+		},
+		{
+			description: "Native request object",
+			data: func() []byte {
+				native := appendMessage(nil, 50, appendString(nil, 1, "1.2"))
+				imp := appendString(nil, 1, "imp-1")
+				imp = appendMessage(imp, 13, native)
+				return appendMessage(nil, 2, imp)
+			}(),
+			expectedReq:   &openrtb2.BidRequest{Imp: []openrtb2.Imp{{ID: "imp-1", BidFloorCur: "USD", Native: &openrtb2.Native{}}}, AT: 2},
+			expectedError: "bidrequest field 2: imp field 13: native field 50: the native objects aren't supported, the native markup must be sent as a JSON string",
+		},
+		{
+			description: "Unpacked repeated enums",
+			data: func() []byte {
+				banner := protowire.AppendTag(nil, 5, protowire.VarintType)
+				banner = protowire.AppendVarint(banner, 1)
+				banner = protowire.AppendTag(banner, 5, protowire.VarintType)
+				banner = protowire.AppendVarint(banner, 3)
+				imp := appendString(nil, 1, "imp-1")
+				imp = appendMessage(imp, 2, banner)
+				return appendMessage(nil, 2, imp)
+			}(),
+			expectedReq: &openrtb2.BidRequest{Imp: []openrtb2.Imp{{ID: "imp-1", BidFloorCur: "USD", Banner: &openrtb2.Banner{BType: []openrtb2.BannerAdType{1, 3}}}}, AT: 2},
+		},
+		{
+			description:   "Wrong wire type",
+			data:          appendInt(nil, 1, 12),
+			expectedReq:   &openrtb2.BidRequest{AT: 2},
+			expectedError: "bidrequest field 1: unexpected wire type",
+		},
+		{
+			description:   "Wrong wire type in a nested message",
+			data:          appendMessage(nil, 2, appendDouble(nil, 7, 1)),
+			expectedReq:   &openrtb2.BidRequest{Imp: []openrtb2.Imp{{BidFloorCur: "USD"}}, AT: 2},
+			expectedError: "bidrequest field 2: imp field 7: unexpected wire type",
+		},
+		{
+			description:   "Truncated message",
+			data:          appendString(nil, 1, "request-1")[:5],
+			expectedReq:   &openrtb2.BidRequest{AT: 2},
+			expectedError: "bidrequest field 1: unexpected EOF",
+		},
+	}
+
+	for _, test := range testCases {
+		var req openrtb2.BidRequest
+		err := UnmarshalBidRequest(test.data, &req)
+		if test.expectedError != "" {
+			assert.EqualError(t, err, test.expectedError, test.description)
+		} else {
+			assert.NoError(t, err, test.description)
+		}
+		assert.Equal(t, test.expectedReq, &req, test.description)
+	}
+}
+
+func TestCodec(t *testing.T) {
+	codec := Codec{}
+	assert.Equal(t, "proto", codec.Name())
+
+	data, err := codec.Marshal(&openrtb2.BidRequest{ID: "request-1"})
+	assert.NoError(t, err)
+	var req openrtb2.BidRequest
+	assert.NoError(t, codec.Unmarshal(data, &req))
+	assert.Equal(t, "request-1", req.ID)
+
+	_, err = codec.Marshal(openrtb2.BidRequest{})
+	assert.EqualError(t, err, "openrtb_proto: cannot marshal openrtb2.BidRequest")
+	assert.EqualError(t, codec.Unmarshal(data, &openrtb2.Imp{}), "openrtb_proto: cannot unmarshal into *openrtb2.Imp")
+}
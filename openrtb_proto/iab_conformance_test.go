@@ -0,0 +1,503 @@
+package openrtb_proto
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// iabField is a field of the IAB OpenRTB 2.5 protobuf (openrtb.proto of the openrtb-core library). The type is
+// one of string, int32, bool and double, or the name of a message. The enums are int32, which has the same encoding.
+type iabField struct {
+	name   string
+	number int32
+	label  descriptorpb.FieldDescriptorProto_Label
+	typ    string
+	packed bool
+	def    string
+}
+
+func optional(name string, number int32, typ string) iabField {
+	return iabField{name: name, number: number, label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL, typ: typ}
+}
+
+func repeated(name string, number int32, typ string) iabField {
+	return iabField{name: name, number: number, label: descriptorpb.FieldDescriptorProto_LABEL_REPEATED, typ: typ}
+}
+
+func required(name string, number int32, typ string) iabField {
+	return iabField{name: name, number: number, label: descriptorpb.FieldDescriptorProto_LABEL_REQUIRED, typ: typ}
+}
+
+func packed(name string, number int32) iabField {
+	field := repeated(name, number, "int32")
+	field.packed = true
+	return field
+}
+
+func (f iabField) withDefault(def string) iabField {
+	f.def = def
+	return f
+}
+
+type iabMessage struct {
+	name   string
+	fields []iabField
+}
+
+// iabMessages are the messages of the IAB openrtb.proto exchanged by Prebid Server, with their nested messages
+// flattened. The ext object is the JSON string of extension field 100 of every message. The native request and
+// response objects only list the field they need to be encoded. The oneofs aren't declared, so that the golden
+// messages can set every field.
+var iabMessages = []iabMessage{
+	{"BidRequest", []iabField{
+		required("id", 1, "string"), repeated("imp", 2, "Imp"), optional("site", 3, "Site"), optional("app", 4, "App"),
+		optional("device", 5, "Device"), optional("user", 6, "User"), optional("at", 7, "int32").withDefault("2"),
+		optional("tmax", 8, "int32"), repeated("wseat", 9, "string"), optional("allimps", 10, "bool"),
+		repeated("cur", 11, "string"), repeated("bcat", 12, "string"), repeated("badv", 13, "string"),
+		optional("regs", 14, "Regs"), optional("test", 15, "bool"), repeated("bapp", 16, "string"),
+		repeated("bseat", 17, "string"), repeated("wlang", 18, "string"), optional("source", 19, "Source"),
+	}},
+	{"Source", []iabField{
+		optional("fd", 1, "bool"), optional("tid", 2, "string"), optional("pchain", 3, "string"),
+	}},
+	{"Imp", []iabField{
+		required("id", 1, "string"), optional("banner", 2, "Banner"), optional("video", 3, "Video"),
+		optional("displaymanager", 4, "string"), optional("displaymanagerver", 5, "string"), optional("instl", 6, "bool"),
+		optional("tagid", 7, "string"), optional("bidfloor", 8, "double"), optional("bidfloorcur", 9, "string").withDefault("USD"),
+		repeated("iframebuster", 10, "string"), optional("pmp", 11, "Pmp"), optional("secure", 12, "bool"),
+		optional("native", 13, "Native"), optional("exp", 14, "int32"), optional("audio", 15, "Audio"),
+		optional("clickbrowser", 16, "bool"), repeated("metric", 17, "Metric"),
+	}},
+	{"Metric", []iabField{
+		optional("type", 1, "string"), optional("value", 2, "double"), optional("vendor", 3, "string"),
+	}},
+	{"Banner", []iabField{
+		optional("w", 1, "int32"), optional("h", 2, "int32"), optional("id", 3, "string"), optional("pos", 4, "int32"),
+		packed("btype", 5), packed("battr", 6), repeated("mimes", 7, "string"), optional("topframe", 8, "bool"),
+		packed("expdir", 9), packed("api", 10), optional("wmax", 11, "int32"), optional("hmax", 12, "int32"),
+		optional("wmin", 13, "int32"), optional("hmin", 14, "int32"), repeated("format", 15, "Format"),
+		optional("vcm", 16, "bool"),
+	}},
+	{"Format", []iabField{
+		optional("w", 1, "int32"), optional("h", 2, "int32"), optional("wratio", 3, "int32"),
+		optional("hratio", 4, "int32"), optional("wmin", 5, "int32"),
+	}},
+	{"Video", []iabField{
+		repeated("mimes", 1, "string"), optional("linearity", 2, "int32"), optional("minduration", 3, "int32"),
+		optional("maxduration", 4, "int32"), optional("protocol", 5, "int32"), optional("w", 6, "int32"),
+		optional("h", 7, "int32"), optional("startdelay", 8, "int32"), optional("sequence", 9, "int32"),
+		packed("battr", 10), optional("maxextended", 11, "int32"), optional("minbitrate", 12, "int32"),
+		optional("maxbitrate", 13, "int32"), optional("boxingallowed", 14, "bool").withDefault("true"),
+		packed("playbackmethod", 15), packed("delivery", 16), optional("pos", 17, "int32"),
+		repeated("companionad", 18, "Banner"), packed("api", 19), packed("companiontype", 20), packed("protocols", 21),
+		optional("skip", 23, "bool"), optional("skipmin", 24, "int32"), optional("skipafter", 25, "int32"),
+		optional("placement", 26, "int32"), optional("playbackend", 27, "int32"),
+	}},
+	{"Audio", []iabField{
+		repeated("mimes", 1, "string"), optional("minduration", 2, "int32"), optional("maxduration", 3, "int32"),
+		packed("protocols", 4), optional("startdelay", 5, "int32"), optional("sequence", 6, "int32"), packed("battr", 7),
+		optional("maxextended", 8, "int32"), optional("minbitrate", 9, "int32"), optional("maxbitrate", 10, "int32"),
+		packed("delivery", 11), repeated("companionad", 12, "Banner"), packed("api", 13), packed("companiontype", 20),
+		optional("maxseq", 21, "int32"), optional("feed", 22, "int32"), optional("stitched", 23, "bool"),
+		optional("nvol", 24, "int32"),
+	}},
+	{"Native", []iabField{
+		optional("request", 1, "string"), optional("request_native", 50, "NativeRequest"), optional("ver", 2, "string"),
+		packed("api", 3), packed("battr", 4),
+	}},
+	{"NativeRequest", []iabField{
+		optional("ver", 1, "string"),
+	}},
+	{"Pmp", []iabField{
+		optional("private_auction", 1, "bool"), repeated("deals", 2, "Deal"),
+	}},
+	{"Deal", []iabField{
+		required("id", 1, "string"), optional("bidfloor", 2, "double"), optional("bidfloorcur", 3, "string").withDefault("USD"),
+		repeated("wseat", 4, "string"), repeated("wadomain", 5, "string"), optional("at", 6, "int32"),
+	}},
+	{"Site", []iabField{
+		optional("id", 1, "string"), optional("name", 2, "string"), optional("domain", 3, "string"),
+		repeated("cat", 4, "string"), repeated("sectioncat", 5, "string"), repeated("pagecat", 6, "string"),
+		optional("page", 7, "string"), optional("privacypolicy", 8, "bool"), optional("ref", 9, "string"),
+		optional("search", 10, "string"), optional("publisher", 11, "Publisher"), optional("content", 12, "Content"),
+		optional("keywords", 13, "string"), optional("mobile", 15, "bool"),
+	}},
+	{"App", []iabField{
+		optional("id", 1, "string"), optional("name", 2, "string"), optional("domain", 3, "string"),
+		repeated("cat", 4, "string"), repeated("sectioncat", 5, "string"), repeated("pagecat", 6, "string"),
+		optional("ver", 7, "string"), optional("bundle", 8, "string"), optional("privacypolicy", 9, "bool"),
+		optional("paid", 10, "bool"), optional("publisher", 11, "Publisher"), optional("content", 12, "Content"),
+		optional("keywords", 13, "string"), optional("storeurl", 16, "string"),
+	}},
+	{"Publisher", []iabField{
+		optional("id", 1, "string"), optional("name", 2, "string"), repeated("cat", 3, "string"),
+		optional("domain", 4, "string"),
+	}},
+	{"Content", []iabField{
+		optional("id", 1, "string"), optional("episode", 2, "int32"), optional("title", 3, "string"),
+		optional("series", 4, "string"), optional("season", 5, "string"), optional("url", 6, "string"),
+		repeated("cat", 7, "string"), optional("videoquality", 8, "int32"), optional("keywords", 9, "string"),
+		optional("contentrating", 10, "string"), optional("userrating", 11, "string"), optional("livestream", 13, "bool"),
+		optional("sourcerelationship", 14, "bool"), optional("producer", 15, "Producer"), optional("len", 16, "int32"),
+		optional("qagmediarating", 17, "int32"), optional("embeddable", 18, "bool"), optional("language", 19, "string"),
+		optional("context", 20, "int32"), optional("artist", 21, "string"), optional("genre", 22, "string"),
+		optional("album", 23, "string"), optional("isrc", 24, "string"), optional("prodq", 25, "int32"),
+		repeated("data", 28, "Data"),
+	}},
+	{"Producer", []iabField{
+		optional("id", 1, "string"), optional("name", 2, "string"), repeated("cat", 3, "string"),
+		optional("domain", 4, "string"),
+	}},
+	{"Device", []iabField{
+		optional("dnt", 1, "bool"), optional("ua", 2, "string"), optional("ip", 3, "string"), optional("geo", 4, "Geo"),
+		optional("didsha1", 5, "string"), optional("didmd5", 6, "string"), optional("dpidsha1", 7, "string"),
+		optional("dpidmd5", 8, "string"), optional("ipv6", 9, "string"), optional("carrier", 10, "string"),
+		optional("language", 11, "string"), optional("make", 12, "string"), optional("model", 13, "string"),
+		optional("os", 14, "string"), optional("osv", 15, "string"), optional("js", 16, "bool"),
+		optional("connectiontype", 17, "int32"), optional("devicetype", 18, "int32"), optional("flashver", 19, "string"),
+		optional("ifa", 20, "string"), optional("macsha1", 21, "string"), optional("macmd5", 22, "string"),
+		optional("lmt", 23, "bool"), optional("hwv", 24, "string"), optional("w", 25, "int32"), optional("h", 26, "int32"),
+		optional("ppi", 27, "int32"), optional("pxratio", 28, "double"), optional("geofetch", 29, "bool"),
+		optional("mccmnc", 30, "string"),
+	}},
+	{"Geo", []iabField{
+		optional("lat", 1, "double"), optional("lon", 2, "double"), optional("country", 3, "string"),
+		optional("region", 4, "string"), optional("regionfips104", 5, "string"), optional("metro", 6, "string"),
+		optional("city", 7, "string"), optional("zip", 8, "string"), optional("type", 9, "int32"),
+		optional("utcoffset", 10, "int32"), optional("accuracy", 11, "int32"), optional("lastfix", 12, "int32"),
+		optional("ipservice", 13, "int32"),
+	}},
+	{"User", []iabField{
+		optional("id", 1, "string"), optional("buyeruid", 2, "string"), optional("yob", 3, "int32"),
+		optional("gender", 4, "string"), optional("keywords", 5, "string"), optional("customdata", 6, "string"),
+		optional("geo", 7, "Geo"), repeated("data", 8, "Data"),
+	}},
+	{"Data", []iabField{
+		optional("id", 1, "string"), optional("name", 2, "string"), repeated("segment", 3, "Segment"),
+	}},
+	{"Segment", []iabField{
+		optional("id", 1, "string"), optional("name", 2, "string"), optional("value", 3, "string"),
+	}},
+	{"Regs", []iabField{
+		optional("coppa", 1, "bool"),
+	}},
+	{"BidResponse", []iabField{
+		required("id", 1, "string"), repeated("seatbid", 2, "SeatBid"), optional("bidid", 3, "string"),
+		optional("cur", 4, "string"), optional("customdata", 5, "string"), optional("nbr", 6, "int32"),
+	}},
+	{"SeatBid", []iabField{
+		repeated("bid", 1, "Bid"), optional("seat", 2, "string"), optional("group", 3, "bool"),
+	}},
+	{"Bid", []iabField{
+		required("id", 1, "string"), required("impid", 2, "string"), required("price", 3, "double"),
+		optional("adid", 4, "string"), optional("nurl", 5, "string"), optional("adm", 6, "string"),
+		optional("adm_native", 50, "NativeResponse"), repeated("adomain", 7, "string"), optional("iurl", 8, "string"),
+		optional("cid", 9, "string"), optional("crid", 10, "string"), packed("attr", 11), optional("dealid", 13, "string"),
+		optional("bundle", 14, "string"), repeated("cat", 15, "string"), optional("w", 16, "int32"),
+		optional("h", 17, "int32"), optional("api", 18, "int32"), optional("protocol", 19, "int32"),
+		optional("qagmediarating", 20, "int32"), optional("exp", 21, "int32"), optional("burl", 22, "string"),
+		optional("lurl", 23, "string"), optional("tactic", 24, "string"), optional("language", 25, "string"),
+		optional("wratio", 26, "int32"), optional("hratio", 27, "int32"),
+	}},
+	{"NativeResponse", []iabField{
+		optional("ver", 1, "string"),
+	}},
+}
+
+// unsupportedIABFields are the fields of the IAB messages which the codec rejects.
+var unsupportedIABFields = map[string]bool{
+	"Native.request_native": true,
+	"Bid.adm_native":        true,
+}
+
+// newIABFile builds the descriptor of the IAB messages, so that the protobuf runtime can encode and decode them.
+func newIABFile(t *testing.T) protoreflect.FileDescriptor {
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("iab_openrtb.proto"),
+		Package: proto.String("iab"),
+		Syntax:  proto.String("proto2"),
+	}
+	for _, message := range iabMessages {
+		descriptor := &descriptorpb.DescriptorProto{Name: proto.String(message.name)}
+		fields := append(message.fields, optional("ext", int32(extFieldNumber), "string"))
+		for _, field := range fields {
+			fieldDescriptor := &descriptorpb.FieldDescriptorProto{
+				Name:   proto.String(field.name),
+				Number: proto.Int32(field.number),
+				Label:  field.label.Enum(),
+			}
+			switch field.typ {
+			case "string":
+				fieldDescriptor.Type = descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()
+			case "int32":
+				fieldDescriptor.Type = descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum()
+			case "bool":
+				fieldDescriptor.Type = descriptorpb.FieldDescriptorProto_TYPE_BOOL.Enum()
+			case "double":
+				fieldDescriptor.Type = descriptorpb.FieldDescriptorProto_TYPE_DOUBLE.Enum()
+			default:
+				fieldDescriptor.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+				fieldDescriptor.TypeName = proto.String(".iab." + field.typ)
+			}
+			if field.packed {
+				fieldDescriptor.Options = &descriptorpb.FieldOptions{Packed: proto.Bool(true)}
+			}
+			if field.def != "" {
+				fieldDescriptor.DefaultValue = proto.String(field.def)
+			}
+			descriptor.Field = append(descriptor.Field, fieldDescriptor)
+		}
+		file.MessageType = append(file.MessageType, descriptor)
+	}
+
+	fileDescriptor, err := protodesc.NewFile(file, nil)
+	if err != nil {
+		t.Fatalf("Invalid IAB descriptor: %v", err)
+	}
+	return fileDescriptor
+}
+
+// populate sets every supported field of the message to a value derived from its number. The repeated fields
+// get two values.
+func populate(message protoreflect.Message) {
+	fields := message.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		if unsupportedIABFields[string(message.Descriptor().Name())+"."+string(field.Name())] {
+			continue
+		}
+		switch {
+		case field.IsList():
+			list := message.Mutable(field).List()
+			for j := 0; j < 2; j++ {
+				if field.Message() != nil {
+					element := list.NewElement()
+					populate(element.Message())
+					list.Append(element)
+				} else {
+					list.Append(fieldValue(field, j))
+				}
+			}
+		case field.Message() != nil:
+			populate(message.Mutable(field).Message())
+		default:
+			message.Set(field, fieldValue(field, 0))
+		}
+	}
+}
+
+func fieldValue(field protoreflect.FieldDescriptor, index int) protoreflect.Value {
+	number := int(field.Number()) + index
+	switch field.Kind() {
+	case protoreflect.StringKind:
+		if field.Number() == extFieldNumber {
+			return protoreflect.ValueOfString(fmt.Sprintf(`{"%s":%d}`, field.Parent().Name(), number))
+		}
+		return protoreflect.ValueOfString(fmt.Sprintf("%s-%d", field.Name(), number))
+	case protoreflect.Int32Kind:
+		return protoreflect.ValueOfInt32(int32(number))
+	case protoreflect.BoolKind:
+		return protoreflect.ValueOfBool(true)
+	case protoreflect.DoubleKind:
+		return protoreflect.ValueOfFloat64(float64(number) + 0.5)
+	}
+	panic("unexpected kind " + field.Kind().String())
+}
+
+func TestIABBidRequestRoundTrip(t *testing.T) {
+	file := newIABFile(t)
+	golden := dynamicpb.NewMessage(file.Messages().ByName("BidRequest"))
+	populate(golden)
+	data, err := proto.Marshal(golden)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var req openrtb2.BidRequest
+	if !assert.NoError(t, UnmarshalBidRequest(data, &req)) {
+		return
+	}
+	decoded := dynamicpb.NewMessage(file.Messages().ByName("BidRequest"))
+	assert.NoError(t, proto.Unmarshal(MarshalBidRequest(&req), decoded))
+	assert.Equal(t, prototext.Format(golden), prototext.Format(decoded))
+}
+
+func TestIABBidResponseRoundTrip(t *testing.T) {
+	file := newIABFile(t)
+	golden := dynamicpb.NewMessage(file.Messages().ByName("BidResponse"))
+	populate(golden)
+	data, err := proto.Marshal(golden)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var resp openrtb2.BidResponse
+	if !assert.NoError(t, UnmarshalBidResponse(data, &resp)) {
+		return
+	}
+	decoded := dynamicpb.NewMessage(file.Messages().ByName("BidResponse"))
+	assert.NoError(t, proto.Unmarshal(MarshalBidResponse(&resp), decoded))
+	assert.Equal(t, prototext.Format(golden), prototext.Format(decoded))
+}
+
+func TestIABDefaults(t *testing.T) {
+	file := newIABFile(t)
+	messages := file.Messages()
+
+	// A request holding an empty message of every type which has a field with a default
+	golden := dynamicpb.NewMessage(messages.ByName("BidRequest"))
+	if !assert.NoError(t, prototext.Unmarshal([]byte(`id: "request-1" imp { id: "imp-1" video {} pmp { deals { id: "deal-1" } } }`), golden)) {
+		return
+	}
+	data, err := proto.Marshal(golden)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var req openrtb2.BidRequest
+	if !assert.NoError(t, UnmarshalBidRequest(data, &req)) || !assert.Len(t, req.Imp, 1) {
+		return
+	}
+	defaultOf := func(message, field string) protoreflect.Value {
+		return messages.ByName(protoreflect.Name(message)).Fields().ByName(protoreflect.Name(field)).Default()
+	}
+	assert.Equal(t, defaultOf("BidRequest", "at").Int(), req.AT, "at")
+	assert.Equal(t, defaultOf("Imp", "bidfloorcur").String(), req.Imp[0].BidFloorCur, "imp.bidfloorcur")
+	assert.Equal(t, defaultOf("Deal", "bidfloorcur").String(), req.Imp[0].PMP.Deals[0].BidFloorCur, "deal.bidfloorcur")
+	assert.Equal(t, defaultOf("Video", "boxingallowed").Bool(), req.Imp[0].Video.BoxingAllowed == 1, "video.boxingallowed")
+}
+
+func TestIABUnsupportedFields(t *testing.T) {
+	file := newIABFile(t)
+	testCases := []struct {
+		description   string
+		message       string
+		golden        string
+		unmarshal     func(data []byte) error
+		expectedError string
+	}{
+		{
+			description:   "Native request object",
+			message:       "BidRequest",
+			golden:        `id: "request-1" imp { id: "imp-1" native { request_native { ver: "1.2" } } }`,
+			unmarshal:     func(data []byte) error { return UnmarshalBidRequest(data, &openrtb2.BidRequest{}) },
+			expectedError: "bidrequest field 2: imp field 13: native field 50: " + errNativeObject.Error(),
+		},
+		{
+			description:   "Native response object",
+			message:       "BidResponse",
+			golden:        `id: "request-1" seatbid { bid { id: "bid-1" impid: "imp-1" price: 1 adm_native { ver: "1.2" } } }`,
+			unmarshal:     func(data []byte) error { return UnmarshalBidResponse(data, &openrtb2.BidResponse{}) },
+			expectedError: "bidresponse field 2: seatbid field 1: bid field 50: " + errNativeObject.Error(),
+		},
+	}
+
+	for _, test := range testCases {
+		golden := dynamicpb.NewMessage(file.Messages().ByName(protoreflect.Name(test.message)))
+		if !assert.NoError(t, prototext.Unmarshal([]byte(test.golden), golden), test.description) {
+			continue
+		}
+		data, err := proto.Marshal(golden)
+		if !assert.NoError(t, err, test.description) {
+			continue
+		}
+		assert.EqualError(t, test.unmarshal(data), test.expectedError, test.description)
+	}
+}
+
+var (
+	protoMessagePattern = regexp.MustCompile(`^message (\w+) \{$`)
+	protoFieldPattern   = regexp.MustCompile(`^(?:(optional|repeated|required) )?(\w+) (\w+) = (\d+)(?: \[(.*)\])?;$`)
+)
+
+// TestIABOpenRTBProto checks the messages of openrtb.proto, the definitions published to the clients, against
+// the IAB messages. Every field of openrtb.proto must match the IAB field, and every IAB field which the codec
+// supports must be listed.
+func TestIABOpenRTBProto(t *testing.T) {
+	iabFields := make(map[string]map[string]iabField, len(iabMessages))
+	for _, message := range iabMessages {
+		iabFields[message.name] = make(map[string]iabField, len(message.fields))
+		for _, field := range message.fields {
+			iabFields[message.name][field.name] = field
+		}
+	}
+
+	protoFile, err := os.Open("openrtb.proto")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer protoFile.Close()
+
+	protoFields := make(map[string]map[string]bool)
+	// blocks holds the message of every open block, or an empty name for the oneofs
+	var blocks []string
+	scanner := bufio.NewScanner(protoFile)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if match := protoMessagePattern.FindStringSubmatch(line); match != nil {
+			blocks = append(blocks, match[1])
+			if _, ok := iabFields[match[1]]; !ok {
+				t.Errorf("message %s isn't an IAB message", match[1])
+			}
+			protoFields[match[1]] = make(map[string]bool)
+			continue
+		}
+		if strings.HasPrefix(line, "oneof ") {
+			blocks = append(blocks, blocks[len(blocks)-1])
+			continue
+		}
+		if line == "}" {
+			blocks = blocks[:len(blocks)-1]
+			continue
+		}
+		match := protoFieldPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		message := blocks[len(blocks)-1]
+		field := iabField{name: match[3], typ: match[2], label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL}
+		switch match[1] {
+		case "repeated":
+			field.label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+		case "required":
+			field.label = descriptorpb.FieldDescriptorProto_LABEL_REQUIRED
+		}
+		number, _ := strconv.Atoi(match[4])
+		field.number = int32(number)
+		for _, option := range strings.Split(match[5], ", ") {
+			switch {
+			case option == "packed = true":
+				field.packed = true
+			case strings.HasPrefix(option, "default = "):
+				field.def = strings.Trim(strings.TrimPrefix(option, "default = "), `"`)
+			}
+		}
+
+		protoFields[message][field.name] = true
+		assert.Equal(t, iabFields[message][field.name], field, "%s.%s", message, field.name)
+	}
+	assert.NoError(t, scanner.Err())
+
+	for message, fields := range protoFields {
+		for name := range iabFields[message] {
+			if !fields[name] && !unsupportedIABFields[message+"."+name] {
+				t.Errorf("field %s.%s is missing from openrtb.proto", message, name)
+			}
+		}
+	}
+}
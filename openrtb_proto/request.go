@@ -0,0 +1,1223 @@
+package openrtb_proto
+
+import (
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// UnmarshalBidRequest decodes a BidRequest encoded with the messages of openrtb.proto. The fields which have a
+// default in openrtb.proto are set to it when they're absent, since a client doesn't send the fields it didn't set.
+func UnmarshalBidRequest(b []byte, req *openrtb2.BidRequest) error {
+	req.AT = defaultAuctionType
+	return unmarshalFields("bidrequest", b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1:
+			return consumeString(typ, b, &req.ID)
+		case 2:
+			return consumeMessage(typ, b, func(b []byte) error {
+				req.Imp = append(req.Imp, openrtb2.Imp{})
+				return unmarshalImp(b, &req.Imp[len(req.Imp)-1])
+			})
+		case 3:
+			req.Site = &openrtb2.Site{}
+			return consumeMessage(typ, b, func(b []byte) error { return unmarshalSite(b, req.Site) })
+		case 4:
+			req.App = &openrtb2.App{}
+			return consumeMessage(typ, b, func(b []byte) error { return unmarshalApp(b, req.App) })
+		case 5:
+			req.Device = &openrtb2.Device{}
+			return consumeMessage(typ, b, func(b []byte) error { return unmarshalDevice(b, req.Device) })
+		case 6:
+			req.User = &openrtb2.User{}
+			return consumeMessage(typ, b, func(b []byte) error { return unmarshalUser(b, req.User) })
+		case 7:
+			return consumeInt64(typ, b, &req.AT)
+		case 8:
+			return consumeInt64(typ, b, &req.TMax)
+		case 9:
+			return consumeStrings(typ, b, &req.WSeat)
+		case 10:
+			return consumeInt8(typ, b, &req.AllImps)
+		case 11:
+			return consumeStrings(typ, b, &req.Cur)
+		case 12:
+			return consumeStrings(typ, b, &req.BCat)
+		case 13:
+			return consumeStrings(typ, b, &req.BAdv)
+		case 14:
+			req.Regs = &openrtb2.Regs{}
+			return consumeMessage(typ, b, func(b []byte) error { return unmarshalRegs(b, req.Regs) })
+		case 15:
+			return consumeInt8(typ, b, &req.Test)
+		case 16:
+			return consumeStrings(typ, b, &req.BApp)
+		case 17:
+			return consumeStrings(typ, b, &req.BSeat)
+		case 18:
+			return consumeStrings(typ, b, &req.WLang)
+		case 19:
+			req.Source = &openrtb2.Source{}
+			return consumeMessage(typ, b, func(b []byte) error { return unmarshalSource(b, req.Source) })
+		case extFieldNumber:
+			return consumeJSON(typ, b, &req.Ext)
+		}
+		return 0, nil
+	})
+}
+
+func unmarshalSource(b []byte, source *openrtb2.Source) error {
+	return unmarshalFields("source", b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1:
+			return consumeInt8(typ, b, &source.FD)
+		case 2:
+			return consumeString(typ, b, &source.TID)
+		case 3:
+			return consumeString(typ, b, &source.PChain)
+		case extFieldNumber:
+			return consumeJSON(typ, b, &source.Ext)
+		}
+		return 0, nil
+	})
+}
+
+func unmarshalImp(b []byte, imp *openrtb2.Imp) error {
+	imp.BidFloorCur = defaultCurrency
+	return unmarshalFields("imp", b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1:
+			return consumeString(typ, b, &imp.ID)
+		case 2:
+			imp.Banner = &openrtb2.Banner{}
+			return consumeMessage(typ, b, func(b []byte) error { return unmarshalBanner(b, imp.Banner) })
+		case 3:
+			imp.Video = &openrtb2.Video{}
+			return consumeMessage(typ, b, func(b []byte) error { return unmarshalVideo(b, imp.Video) })
+		case 4:
+			return consumeString(typ, b, &imp.DisplayManager)
+		case 5:
+			return consumeString(typ, b, &imp.DisplayManagerVer)
+		case 6:
+			return consumeInt8(typ, b, &imp.Instl)
+		case 7:
+			return consumeString(typ, b, &imp.TagID)
+		case 8:
+			return consumeDouble(typ, b, &imp.BidFloor)
+		case 9:
+			return consumeString(typ, b, &imp.BidFloorCur)
+		case 10:
+			return consumeStrings(typ, b, &imp.IframeBuster)
+		case 11:
+			imp.PMP = &openrtb2.PMP{}
+			return consumeMessage(typ, b, func(b []byte) error { return unmarshalPMP(b, imp.PMP) })
+		case 12:
+			return consumeInt8Ptr(typ, b, &imp.Secure)
+		case 13:
+			imp.Native = &openrtb2.Native{}
+			return consumeMessage(typ, b, func(b []byte) error { return unmarshalNative(b, imp.Native) })
+		case 14:
+			return consumeInt64(typ, b, &imp.Exp)
+		case 15:
+			imp.Audio = &openrtb2.Audio{}
+			return consumeMessage(typ, b, func(b []byte) error { return unmarshalAudio(b, imp.Audio) })
+		case 16:
+			return consumeInt8(typ, b, &imp.ClickBrowser)
+		case 17:
+			return consumeMessage(typ, b, func(b []byte) error {
+				imp.Metric = append(imp.Metric, openrtb2.Metric{})
+				return unmarshalMetric(b, &imp.Metric[len(imp.Metric)-1])
+			})
+		case extFieldNumber:
+			return consumeJSON(typ, b, &imp.Ext)
+		}
+		return 0, nil
+	})
+}
+
+func unmarshalMetric(b []byte, metric *openrtb2.Metric) error {
+	return unmarshalFields("metric", b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1:
+			return consumeString(typ, b, &metric.Type)
+		case 2:
+			return consumeDouble(typ, b, &metric.Value)
+		case 3:
+			return consumeString(typ, b, &metric.Vendor)
+		case extFieldNumber:
+			return consumeJSON(typ, b, &metric.Ext)
+		}
+		return 0, nil
+	})
+}
+
+func unmarshalBanner(b []byte, banner *openrtb2.Banner) error {
+	return unmarshalFields("banner", b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1:
+			var w int64
+			n, err := consumeInt64(typ, b, &w)
+			banner.W = &w
+			return n, err
+		case 2:
+			var h int64
+			n, err := consumeInt64(typ, b, &h)
+			banner.H = &h
+			return n, err
+		case 3:
+			return consumeString(typ, b, &banner.ID)
+		case 4:
+			var pos uint64
+			n, err := consumeVarint(typ, b, &pos)
+			banner.Pos = openrtb2.AdPosition(pos).Ptr()
+			return n, err
+		case 5:
+			return consumeVarints(typ, b, func(v uint64) { banner.BType = append(banner.BType, openrtb2.BannerAdType(v)) })
+		case 6:
+			return consumeVarints(typ, b, func(v uint64) { banner.BAttr = append(banner.BAttr, openrtb2.CreativeAttribute(v)) })
+		case 7:
+			return consumeStrings(typ, b, &banner.MIMEs)
+		case 8:
+			return consumeInt8(typ, b, &banner.TopFrame)
+		case 9:
+			return consumeVarints(typ, b, func(v uint64) { banner.ExpDir = append(banner.ExpDir, openrtb2.ExpandableDirection(v)) })
+		case 10:
+			return consumeVarints(typ, b, func(v uint64) { banner.API = append(banner.API, openrtb2.APIFramework(v)) })
+		case 11:
+			return consumeInt64(typ, b, &banner.WMax)
+		case 12:
+			return consumeInt64(typ, b, &banner.HMax)
+		case 13:
+			return consumeInt64(typ, b, &banner.WMin)
+		case 14:
+			return consumeInt64(typ, b, &banner.HMin)
+		case 15:
+			return consumeMessage(typ, b, func(b []byte) error {
+				banner.Format = append(banner.Format, openrtb2.Format{})
+				return unmarshalFormat(b, &banner.Format[len(banner.Format)-1])
+			})
+		case 16:
+			return consumeInt8(typ, b, &banner.VCm)
+		case extFieldNumber:
+			return consumeJSON(typ, b, &banner.Ext)
+		}
+		return 0, nil
+	})
+}
+
+func unmarshalFormat(b []byte, format *openrtb2.Format) error {
+	return unmarshalFields("format", b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1:
+			return consumeInt64(typ, b, &format.W)
+		case 2:
+			return consumeInt64(typ, b, &format.H)
+		case 3:
+			return consumeInt64(typ, b, &format.WRatio)
+		case 4:
+			return consumeInt64(typ, b, &format.HRatio)
+		case 5:
+			return consumeInt64(typ, b, &format.WMin)
+		case extFieldNumber:
+			return consumeJSON(typ, b, &format.Ext)
+		}
+		return 0, nil
+	})
+}
+
+func unmarshalVideo(b []byte, video *openrtb2.Video) error {
+	// boxingallowed defaults to true
+	video.BoxingAllowed = 1
+	return unmarshalFields("video", b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		var v uint64
+		switch num {
+		case 1:
+			return consumeStrings(typ, b, &video.MIMEs)
+		case 2:
+			n, err := consumeVarint(typ, b, &v)
+			video.Linearity = openrtb2.VideoLinearity(v)
+			return n, err
+		case 3:
+			return consumeInt64(typ, b, &video.MinDuration)
+		case 4:
+			return consumeInt64(typ, b, &video.MaxDuration)
+		case 5:
+			n, err := consumeVarint(typ, b, &v)
+			video.Protocol = openrtb2.Protocol(v)
+			return n, err
+		case 6:
+			return consumeInt64(typ, b, &video.W)
+		case 7:
+			return consumeInt64(typ, b, &video.H)
+		case 8:
+			n, err := consumeVarint(typ, b, &v)
+			video.StartDelay = openrtb2.StartDelay(v).Ptr()
+			return n, err
+		case 9:
+			return consumeInt8(typ, b, &video.Sequence)
+		case 10:
+			return consumeVarints(typ, b, func(v uint64) { video.BAttr = append(video.BAttr, openrtb2.CreativeAttribute(v)) })
+		case 11:
+			return consumeInt64(typ, b, &video.MaxExtended)
+		case 12:
+			return consumeInt64(typ, b, &video.MinBitRate)
+		case 13:
+			return consumeInt64(typ, b, &video.MaxBitRate)
+		case 14:
+			return consumeInt8(typ, b, &video.BoxingAllowed)
+		case 15:
+			return consumeVarints(typ, b, func(v uint64) { video.PlaybackMethod = append(video.PlaybackMethod, openrtb2.PlaybackMethod(v)) })
+		case 16:
+			return consumeVarints(typ, b, func(v uint64) { video.Delivery = append(video.Delivery, openrtb2.ContentDeliveryMethod(v)) })
+		case 17:
+			n, err := consumeVarint(typ, b, &v)
+			video.Pos = openrtb2.AdPosition(v).Ptr()
+			return n, err
+		case 18:
+			return consumeMessage(typ, b, func(b []byte) error {
+				video.CompanionAd = append(video.CompanionAd, openrtb2.Banner{})
+				return unmarshalBanner(b, &video.CompanionAd[len(video.CompanionAd)-1])
+			})
+		case 19:
+			return consumeVarints(typ, b, func(v uint64) { video.API = append(video.API, openrtb2.APIFramework(v)) })
+		case 20:
+			return consumeVarints(typ, b, func(v uint64) { video.CompanionType = append(video.CompanionType, openrtb2.CompanionType(v)) })
+		case 21:
+			return consumeVarints(typ, b, func(v uint64) { video.Protocols = append(video.Protocols, openrtb2.Protocol(v)) })
+		case 23:
+			return consumeInt8Ptr(typ, b, &video.Skip)
+		case 24:
+			return consumeInt64(typ, b, &video.SkipMin)
+		case 25:
+			return consumeInt64(typ, b, &video.SkipAfter)
+		case 26:
+			n, err := consumeVarint(typ, b, &v)
+			video.Placement = openrtb2.VideoPlacementType(v)
+			return n, err
+		case 27:
+			n, err := consumeVarint(typ, b, &v)
+			video.PlaybackEnd = openrtb2.PlaybackCessationMode(v)
+			return n, err
+		case extFieldNumber:
+			return consumeJSON(typ, b, &video.Ext)
+		}
+		return 0, nil
+	})
+}
+
+func unmarshalAudio(b []byte, audio *openrtb2.Audio) error {
+	return unmarshalFields("audio", b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		var v uint64
+		switch num {
+		case 1:
+			return consumeStrings(typ, b, &audio.MIMEs)
+		case 2:
+			return consumeInt64(typ, b, &audio.MinDuration)
+		case 3:
+			return consumeInt64(typ, b, &audio.MaxDuration)
+		case 4:
+			return consumeVarints(typ, b, func(v uint64) { audio.Protocols = append(audio.Protocols, openrtb2.Protocol(v)) })
+		case 5:
+			n, err := consumeVarint(typ, b, &v)
+			audio.StartDelay = openrtb2.StartDelay(v).Ptr()
+			return n, err
+		case 6:
+			return consumeInt64(typ, b, &audio.Sequence)
+		case 7:
+			return consumeVarints(typ, b, func(v uint64) { audio.BAttr = append(audio.BAttr, openrtb2.CreativeAttribute(v)) })
+		case 8:
+			return consumeInt64(typ, b, &audio.MaxExtended)
+		case 9:
+			return consumeInt64(typ, b, &audio.MinBitrate)
+		case 10:
+			return consumeInt64(typ, b, &audio.MaxBitrate)
+		case 11:
+			return consumeVarints(typ, b, func(v uint64) { audio.Delivery = append(audio.Delivery, openrtb2.ContentDeliveryMethod(v)) })
+		case 12:
+			return consumeMessage(typ, b, func(b []byte) error {
+				audio.CompanionAd = append(audio.CompanionAd, openrtb2.Banner{})
+				return unmarshalBanner(b, &audio.CompanionAd[len(audio.CompanionAd)-1])
+			})
+		case 13:
+			return consumeVarints(typ, b, func(v uint64) { audio.API = append(audio.API, openrtb2.APIFramework(v)) })
+		case 20:
+			return consumeVarints(typ, b, func(v uint64) { audio.CompanionType = append(audio.CompanionType, openrtb2.CompanionType(v)) })
+		case 21:
+			return consumeInt64(typ, b, &audio.MaxSeq)
+		case 22:
+			n, err := consumeVarint(typ, b, &v)
+			audio.Feed = openrtb2.FeedType(v)
+			return n, err
+		case 23:
+			return consumeInt8(typ, b, &audio.Stitched)
+		case 24:
+			n, err := consumeVarint(typ, b, &v)
+			audio.NVol = openrtb2.VolumeNormalizationMode(v).Ptr()
+			return n, err
+		case extFieldNumber:
+			return consumeJSON(typ, b, &audio.Ext)
+		}
+		return 0, nil
+	})
+}
+
+func unmarshalNative(b []byte, native *openrtb2.Native) error {
+	return unmarshalFields("native", b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1:
+			return consumeString(typ, b, &native.Request)
+		case 2:
+			return consumeString(typ, b, &native.Ver)
+		case 3:
+			return consumeVarints(typ, b, func(v uint64) { native.API = append(native.API, openrtb2.APIFramework(v)) })
+		case 4:
+			return consumeVarints(typ, b, func(v uint64) { native.BAttr = append(native.BAttr, openrtb2.CreativeAttribute(v)) })
+		case nativeObjectFieldNumber:
+			return 0, errNativeObject
+		case extFieldNumber:
+			return consumeJSON(typ, b, &native.Ext)
+		}
+		return 0, nil
+	})
+}
+
+func unmarshalPMP(b []byte, pmp *openrtb2.PMP) error {
+	return unmarshalFields("pmp", b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1:
+			return consumeInt8(typ, b, &pmp.PrivateAuction)
+		case 2:
+			return consumeMessage(typ, b, func(b []byte) error {
+				pmp.Deals = append(pmp.Deals, openrtb2.Deal{})
+				return unmarshalDeal(b, &pmp.Deals[len(pmp.Deals)-1])
+			})
+		case extFieldNumber:
+			return consumeJSON(typ, b, &pmp.Ext)
+		}
+		return 0, nil
+	})
+}
+
+func unmarshalDeal(b []byte, deal *openrtb2.Deal) error {
+	deal.BidFloorCur = defaultCurrency
+	return unmarshalFields("deal", b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1:
+			return consumeString(typ, b, &deal.ID)
+		case 2:
+			return consumeDouble(typ, b, &deal.BidFloor)
+		case 3:
+			return consumeString(typ, b, &deal.BidFloorCur)
+		case 4:
+			return consumeStrings(typ, b, &deal.WSeat)
+		case 5:
+			return consumeStrings(typ, b, &deal.WADomain)
+		case 6:
+			return consumeInt64(typ, b, &deal.AT)
+		case extFieldNumber:
+			return consumeJSON(typ, b, &deal.Ext)
+		}
+		return 0, nil
+	})
+}
+
+func unmarshalSite(b []byte, site *openrtb2.Site) error {
+	return unmarshalFields("site", b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1:
+			return consumeString(typ, b, &site.ID)
+		case 2:
+			return consumeString(typ, b, &site.Name)
+		case 3:
+			return consumeString(typ, b, &site.Domain)
+		case 4:
+			return consumeStrings(typ, b, &site.Cat)
+		case 5:
+			return consumeStrings(typ, b, &site.SectionCat)
+		case 6:
+			return consumeStrings(typ, b, &site.PageCat)
+		case 7:
+			return consumeString(typ, b, &site.Page)
+		case 8:
+			return consumeInt8(typ, b, &site.PrivacyPolicy)
+		case 9:
+			return consumeString(typ, b, &site.Ref)
+		case 10:
+			return consumeString(typ, b, &site.Search)
+		case 11:
+			site.Publisher = &openrtb2.Publisher{}
+			return consumeMessage(typ, b, func(b []byte) error { return unmarshalPublisher(b, site.Publisher) })
+		case 12:
+			site.Content = &openrtb2.Content{}
+			return consumeMessage(typ, b, func(b []byte) error { return unmarshalContent(b, site.Content) })
+		case 13:
+			return consumeString(typ, b, &site.Keywords)
+		case 15:
+			return consumeInt8(typ, b, &site.Mobile)
+		case extFieldNumber:
+			return consumeJSON(typ, b, &site.Ext)
+		}
+		return 0, nil
+	})
+}
+
+func unmarshalApp(b []byte, app *openrtb2.App) error {
+	return unmarshalFields("app", b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1:
+			return consumeString(typ, b, &app.ID)
+		case 2:
+			return consumeString(typ, b, &app.Name)
+		case 3:
+			return consumeString(typ, b, &app.Domain)
+		case 4:
+			return consumeStrings(typ, b, &app.Cat)
+		case 5:
+			return consumeStrings(typ, b, &app.SectionCat)
+		case 6:
+			return consumeStrings(typ, b, &app.PageCat)
+		case 7:
+			return consumeString(typ, b, &app.Ver)
+		case 8:
+			return consumeString(typ, b, &app.Bundle)
+		case 9:
+			return consumeInt8(typ, b, &app.PrivacyPolicy)
+		case 10:
+			return consumeInt8(typ, b, &app.Paid)
+		case 11:
+			app.Publisher = &openrtb2.Publisher{}
+			return consumeMessage(typ, b, func(b []byte) error { return unmarshalPublisher(b, app.Publisher) })
+		case 12:
+			app.Content = &openrtb2.Content{}
+			return consumeMessage(typ, b, func(b []byte) error { return unmarshalContent(b, app.Content) })
+		case 13:
+			return consumeString(typ, b, &app.Keywords)
+		case 16:
+			return consumeString(typ, b, &app.StoreURL)
+		case extFieldNumber:
+			return consumeJSON(typ, b, &app.Ext)
+		}
+		return 0, nil
+	})
+}
+
+func unmarshalPublisher(b []byte, publisher *openrtb2.Publisher) error {
+	return unmarshalFields("publisher", b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1:
+			return consumeString(typ, b, &publisher.ID)
+		case 2:
+			return consumeString(typ, b, &publisher.Name)
+		case 3:
+			return consumeStrings(typ, b, &publisher.Cat)
+		case 4:
+			return consumeString(typ, b, &publisher.Domain)
+		case extFieldNumber:
+			return consumeJSON(typ, b, &publisher.Ext)
+		}
+		return 0, nil
+	})
+}
+
+func unmarshalContent(b []byte, content *openrtb2.Content) error {
+	return unmarshalFields("content", b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		var v uint64
+		switch num {
+		case 1:
+			return consumeString(typ, b, &content.ID)
+		case 2:
+			return consumeInt64(typ, b, &content.Episode)
+		case 3:
+			return consumeString(typ, b, &content.Title)
+		case 4:
+			return consumeString(typ, b, &content.Series)
+		case 5:
+			return consumeString(typ, b, &content.Season)
+		case 6:
+			return consumeString(typ, b, &content.URL)
+		case 7:
+			return consumeStrings(typ, b, &content.Cat)
+		case 8:
+			n, err := consumeVarint(typ, b, &v)
+			content.VideoQuality = openrtb2.ProductionQuality(v).Ptr()
+			return n, err
+		case 9:
+			return consumeString(typ, b, &content.Keywords)
+		case 10:
+			return consumeString(typ, b, &content.ContentRating)
+		case 11:
+			return consumeString(typ, b, &content.UserRating)
+		case 13:
+			return consumeInt8(typ, b, &content.LiveStream)
+		case 14:
+			return consumeInt8(typ, b, &content.SourceRelationship)
+		case 15:
+			content.Producer = &openrtb2.Producer{}
+			return consumeMessage(typ, b, func(b []byte) error { return unmarshalProducer(b, content.Producer) })
+		case 16:
+			return consumeInt64(typ, b, &content.Len)
+		case 17:
+			n, err := consumeVarint(typ, b, &v)
+			content.QAGMediaRating = openrtb2.IQGMediaRating(v)
+			return n, err
+		case 18:
+			return consumeInt8(typ, b, &content.Embeddable)
+		case 19:
+			return consumeString(typ, b, &content.Language)
+		case 20:
+			n, err := consumeVarint(typ, b, &v)
+			content.Context = openrtb2.ContentContext(v)
+			return n, err
+		case 21:
+			return consumeString(typ, b, &content.Artist)
+		case 22:
+			return consumeString(typ, b, &content.Genre)
+		case 23:
+			return consumeString(typ, b, &content.Album)
+		case 24:
+			return consumeString(typ, b, &content.ISRC)
+		case 25:
+			n, err := consumeVarint(typ, b, &v)
+			content.ProdQ = openrtb2.ProductionQuality(v).Ptr()
+			return n, err
+		case 28:
+			return consumeMessage(typ, b, func(b []byte) error {
+				content.Data = append(content.Data, openrtb2.Data{})
+				return unmarshalData(b, &content.Data[len(content.Data)-1])
+			})
+		case extFieldNumber:
+			return consumeJSON(typ, b, &content.Ext)
+		}
+		return 0, nil
+	})
+}
+
+func unmarshalProducer(b []byte, producer *openrtb2.Producer) error {
+	return unmarshalFields("producer", b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1:
+			return consumeString(typ, b, &producer.ID)
+		case 2:
+			return consumeString(typ, b, &producer.Name)
+		case 3:
+			return consumeStrings(typ, b, &producer.Cat)
+		case 4:
+			return consumeString(typ, b, &producer.Domain)
+		case extFieldNumber:
+			return consumeJSON(typ, b, &producer.Ext)
+		}
+		return 0, nil
+	})
+}
+
+func unmarshalDevice(b []byte, device *openrtb2.Device) error {
+	return unmarshalFields("device", b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		var v uint64
+		switch num {
+		case 1:
+			return consumeInt8Ptr(typ, b, &device.DNT)
+		case 2:
+			return consumeString(typ, b, &device.UA)
+		case 3:
+			return consumeString(typ, b, &device.IP)
+		case 4:
+			device.Geo = &openrtb2.Geo{}
+			return consumeMessage(typ, b, func(b []byte) error { return unmarshalGeo(b, device.Geo) })
+		case 5:
+			return consumeString(typ, b, &device.DIDSHA1)
+		case 6:
+			return consumeString(typ, b, &device.DIDMD5)
+		case 7:
+			return consumeString(typ, b, &device.DPIDSHA1)
+		case 8:
+			return consumeString(typ, b, &device.DPIDMD5)
+		case 9:
+			return consumeString(typ, b, &device.IPv6)
+		case 10:
+			return consumeString(typ, b, &device.Carrier)
+		case 11:
+			return consumeString(typ, b, &device.Language)
+		case 12:
+			return consumeString(typ, b, &device.Make)
+		case 13:
+			return consumeString(typ, b, &device.Model)
+		case 14:
+			return consumeString(typ, b, &device.OS)
+		case 15:
+			return consumeString(typ, b, &device.OSV)
+		case 16:
+			return consumeInt8(typ, b, &device.JS)
+		case 17:
+			n, err := consumeVarint(typ, b, &v)
+			device.ConnectionType = openrtb2.ConnectionType(v).Ptr()
+			return n, err
+		case 18:
+			n, err := consumeVarint(typ, b, &v)
+			device.DeviceType = openrtb2.DeviceType(v)
+			return n, err
+		case 19:
+			return consumeString(typ, b, &device.FlashVer)
+		case 20:
+			return consumeString(typ, b, &device.IFA)
+		case 21:
+			return consumeString(typ, b, &device.MACSHA1)
+		case 22:
+			return consumeString(typ, b, &device.MACMD5)
+		case 23:
+			return consumeInt8Ptr(typ, b, &device.Lmt)
+		case 24:
+			return consumeString(typ, b, &device.HWV)
+		case 25:
+			return consumeInt64(typ, b, &device.W)
+		case 26:
+			return consumeInt64(typ, b, &device.H)
+		case 27:
+			return consumeInt64(typ, b, &device.PPI)
+		case 28:
+			return consumeDouble(typ, b, &device.PxRatio)
+		case 29:
+			return consumeInt8(typ, b, &device.GeoFetch)
+		case 30:
+			return consumeString(typ, b, &device.MCCMNC)
+		case extFieldNumber:
+			return consumeJSON(typ, b, &device.Ext)
+		}
+		return 0, nil
+	})
+}
+
+func unmarshalGeo(b []byte, geo *openrtb2.Geo) error {
+	return unmarshalFields("geo", b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		var v uint64
+		switch num {
+		case 1:
+			return consumeDouble(typ, b, &geo.Lat)
+		case 2:
+			return consumeDouble(typ, b, &geo.Lon)
+		case 3:
+			return consumeString(typ, b, &geo.Country)
+		case 4:
+			return consumeString(typ, b, &geo.Region)
+		case 5:
+			return consumeString(typ, b, &geo.RegionFIPS104)
+		case 6:
+			return consumeString(typ, b, &geo.Metro)
+		case 7:
+			return consumeString(typ, b, &geo.City)
+		case 8:
+			return consumeString(typ, b, &geo.ZIP)
+		case 9:
+			n, err := consumeVarint(typ, b, &v)
+			geo.Type = openrtb2.LocationType(v)
+			return n, err
+		case 10:
+			return consumeInt64(typ, b, &geo.UTCOffset)
+		case 11:
+			return consumeInt64(typ, b, &geo.Accuracy)
+		case 12:
+			return consumeInt64(typ, b, &geo.LastFix)
+		case 13:
+			n, err := consumeVarint(typ, b, &v)
+			geo.IPService = openrtb2.IPLocationService(v)
+			return n, err
+		case extFieldNumber:
+			return consumeJSON(typ, b, &geo.Ext)
+		}
+		return 0, nil
+	})
+}
+
+func unmarshalUser(b []byte, user *openrtb2.User) error {
+	return unmarshalFields("user", b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1:
+			return consumeString(typ, b, &user.ID)
+		case 2:
+			return consumeString(typ, b, &user.BuyerUID)
+		case 3:
+			return consumeInt64(typ, b, &user.Yob)
+		case 4:
+			return consumeString(typ, b, &user.Gender)
+		case 5:
+			return consumeString(typ, b, &user.Keywords)
+		case 6:
+			return consumeString(typ, b, &user.CustomData)
+		case 7:
+			user.Geo = &openrtb2.Geo{}
+			return consumeMessage(typ, b, func(b []byte) error { return unmarshalGeo(b, user.Geo) })
+		case 8:
+			return consumeMessage(typ, b, func(b []byte) error {
+				user.Data = append(user.Data, openrtb2.Data{})
+				return unmarshalData(b, &user.Data[len(user.Data)-1])
+			})
+		case extFieldNumber:
+			return consumeJSON(typ, b, &user.Ext)
+		}
+		return 0, nil
+	})
+}
+
+func unmarshalData(b []byte, data *openrtb2.Data) error {
+	return unmarshalFields("data", b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1:
+			return consumeString(typ, b, &data.ID)
+		case 2:
+			return consumeString(typ, b, &data.Name)
+		case 3:
+			return consumeMessage(typ, b, func(b []byte) error {
+				data.Segment = append(data.Segment, openrtb2.Segment{})
+				return unmarshalSegment(b, &data.Segment[len(data.Segment)-1])
+			})
+		case extFieldNumber:
+			return consumeJSON(typ, b, &data.Ext)
+		}
+		return 0, nil
+	})
+}
+
+func unmarshalSegment(b []byte, segment *openrtb2.Segment) error {
+	return unmarshalFields("segment", b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1:
+			return consumeString(typ, b, &segment.ID)
+		case 2:
+			return consumeString(typ, b, &segment.Name)
+		case 3:
+			return consumeString(typ, b, &segment.Value)
+		case extFieldNumber:
+			return consumeJSON(typ, b, &segment.Ext)
+		}
+		return 0, nil
+	})
+}
+
+func unmarshalRegs(b []byte, regs *openrtb2.Regs) error {
+	return unmarshalFields("regs", b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1:
+			return consumeInt8(typ, b, &regs.COPPA)
+		case extFieldNumber:
+			return consumeJSON(typ, b, &regs.Ext)
+		}
+		return 0, nil
+	})
+}
+
+// MarshalBidRequest encodes a BidRequest with the messages of openrtb.proto.
+func MarshalBidRequest(req *openrtb2.BidRequest) []byte {
+	var b []byte
+	b = appendString(b, 1, req.ID)
+	for i := range req.Imp {
+		b = appendMessage(b, 2, marshalImp(&req.Imp[i]))
+	}
+	if req.Site != nil {
+		b = appendMessage(b, 3, marshalSite(req.Site))
+	}
+	if req.App != nil {
+		b = appendMessage(b, 4, marshalApp(req.App))
+	}
+	if req.Device != nil {
+		b = appendMessage(b, 5, marshalDevice(req.Device))
+	}
+	if req.User != nil {
+		b = appendMessage(b, 6, marshalUser(req.User))
+	}
+	b = appendInt(b, 7, req.AT)
+	b = appendInt(b, 8, req.TMax)
+	b = appendStrings(b, 9, req.WSeat)
+	b = appendInt(b, 10, int64(req.AllImps))
+	b = appendStrings(b, 11, req.Cur)
+	b = appendStrings(b, 12, req.BCat)
+	b = appendStrings(b, 13, req.BAdv)
+	if req.Regs != nil {
+		b = appendMessage(b, 14, marshalRegs(req.Regs))
+	}
+	b = appendInt(b, 15, int64(req.Test))
+	b = appendStrings(b, 16, req.BApp)
+	b = appendStrings(b, 17, req.BSeat)
+	b = appendStrings(b, 18, req.WLang)
+	if req.Source != nil {
+		b = appendMessage(b, 19, marshalSource(req.Source))
+	}
+	return appendJSON(b, req.Ext)
+}
+
+func marshalSource(source *openrtb2.Source) []byte {
+	var b []byte
+	b = appendInt(b, 1, int64(source.FD))
+	b = appendString(b, 2, source.TID)
+	b = appendString(b, 3, source.PChain)
+	return appendJSON(b, source.Ext)
+}
+
+func marshalImp(imp *openrtb2.Imp) []byte {
+	var b []byte
+	b = appendString(b, 1, imp.ID)
+	if imp.Banner != nil {
+		b = appendMessage(b, 2, marshalBanner(imp.Banner))
+	}
+	if imp.Video != nil {
+		b = appendMessage(b, 3, marshalVideo(imp.Video))
+	}
+	b = appendString(b, 4, imp.DisplayManager)
+	b = appendString(b, 5, imp.DisplayManagerVer)
+	b = appendInt(b, 6, int64(imp.Instl))
+	b = appendString(b, 7, imp.TagID)
+	b = appendDouble(b, 8, imp.BidFloor)
+	b = appendString(b, 9, imp.BidFloorCur)
+	b = appendStrings(b, 10, imp.IframeBuster)
+	if imp.PMP != nil {
+		b = appendMessage(b, 11, marshalPMP(imp.PMP))
+	}
+	b = appendInt8Ptr(b, 12, imp.Secure)
+	if imp.Native != nil {
+		b = appendMessage(b, 13, marshalNative(imp.Native))
+	}
+	b = appendInt(b, 14, imp.Exp)
+	if imp.Audio != nil {
+		b = appendMessage(b, 15, marshalAudio(imp.Audio))
+	}
+	b = appendInt(b, 16, int64(imp.ClickBrowser))
+	for i := range imp.Metric {
+		b = appendMessage(b, 17, marshalMetric(&imp.Metric[i]))
+	}
+	return appendJSON(b, imp.Ext)
+}
+
+func marshalMetric(metric *openrtb2.Metric) []byte {
+	var b []byte
+	b = appendString(b, 1, metric.Type)
+	b = appendDouble(b, 2, metric.Value)
+	b = appendString(b, 3, metric.Vendor)
+	return appendJSON(b, metric.Ext)
+}
+
+func marshalBanner(banner *openrtb2.Banner) []byte {
+	var b []byte
+	b = appendIntPtr(b, 1, banner.W)
+	b = appendIntPtr(b, 2, banner.H)
+	b = appendString(b, 3, banner.ID)
+	if banner.Pos != nil {
+		b = appendInt8Ptr(b, 4, (*int8)(banner.Pos))
+	}
+	b = appendPacked(b, 5, int64s(banner.BType))
+	b = appendPacked(b, 6, int64s(banner.BAttr))
+	b = appendStrings(b, 7, banner.MIMEs)
+	b = appendInt(b, 8, int64(banner.TopFrame))
+	b = appendPacked(b, 9, int64s(banner.ExpDir))
+	b = appendPacked(b, 10, int64s(banner.API))
+	b = appendInt(b, 11, banner.WMax)
+	b = appendInt(b, 12, banner.HMax)
+	b = appendInt(b, 13, banner.WMin)
+	b = appendInt(b, 14, banner.HMin)
+	for i := range banner.Format {
+		b = appendMessage(b, 15, marshalFormat(&banner.Format[i]))
+	}
+	b = appendInt(b, 16, int64(banner.VCm))
+	return appendJSON(b, banner.Ext)
+}
+
+func marshalFormat(format *openrtb2.Format) []byte {
+	var b []byte
+	b = appendInt(b, 1, format.W)
+	b = appendInt(b, 2, format.H)
+	b = appendInt(b, 3, format.WRatio)
+	b = appendInt(b, 4, format.HRatio)
+	b = appendInt(b, 5, format.WMin)
+	return appendJSON(b, format.Ext)
+}
+
+func marshalVideo(video *openrtb2.Video) []byte {
+	var b []byte
+	b = appendStrings(b, 1, video.MIMEs)
+	b = appendInt(b, 2, int64(video.Linearity))
+	b = appendInt(b, 3, video.MinDuration)
+	b = appendInt(b, 4, video.MaxDuration)
+	b = appendInt(b, 5, int64(video.Protocol))
+	b = appendInt(b, 6, video.W)
+	b = appendInt(b, 7, video.H)
+	if video.StartDelay != nil {
+		b = appendIntPtr(b, 8, (*int64)(video.StartDelay))
+	}
+	b = appendInt(b, 9, int64(video.Sequence))
+	b = appendPacked(b, 10, int64s(video.BAttr))
+	b = appendInt(b, 11, video.MaxExtended)
+	b = appendInt(b, 12, video.MinBitRate)
+	b = appendInt(b, 13, video.MaxBitRate)
+	b = appendInt(b, 14, int64(video.BoxingAllowed))
+	b = appendPacked(b, 15, int64s(video.PlaybackMethod))
+	b = appendPacked(b, 16, int64s(video.Delivery))
+	if video.Pos != nil {
+		b = appendInt8Ptr(b, 17, (*int8)(video.Pos))
+	}
+	for i := range video.CompanionAd {
+		b = appendMessage(b, 18, marshalBanner(&video.CompanionAd[i]))
+	}
+	b = appendPacked(b, 19, int64s(video.API))
+	b = appendPacked(b, 20, int64s(video.CompanionType))
+	b = appendPacked(b, 21, int64s(video.Protocols))
+	b = appendInt8Ptr(b, 23, video.Skip)
+	b = appendInt(b, 24, video.SkipMin)
+	b = appendInt(b, 25, video.SkipAfter)
+	b = appendInt(b, 26, int64(video.Placement))
+	b = appendInt(b, 27, int64(video.PlaybackEnd))
+	return appendJSON(b, video.Ext)
+}
+
+func marshalAudio(audio *openrtb2.Audio) []byte {
+	var b []byte
+	b = appendStrings(b, 1, audio.MIMEs)
+	b = appendInt(b, 2, audio.MinDuration)
+	b = appendInt(b, 3, audio.MaxDuration)
+	b = appendPacked(b, 4, int64s(audio.Protocols))
+	if audio.StartDelay != nil {
+		b = appendIntPtr(b, 5, (*int64)(audio.StartDelay))
+	}
+	b = appendInt(b, 6, audio.Sequence)
+	b = appendPacked(b, 7, int64s(audio.BAttr))
+	b = appendInt(b, 8, audio.MaxExtended)
+	b = appendInt(b, 9, audio.MinBitrate)
+	b = appendInt(b, 10, audio.MaxBitrate)
+	b = appendPacked(b, 11, int64s(audio.Delivery))
+	for i := range audio.CompanionAd {
+		b = appendMessage(b, 12, marshalBanner(&audio.CompanionAd[i]))
+	}
+	b = appendPacked(b, 13, int64s(audio.API))
+	b = appendPacked(b, 20, int64s(audio.CompanionType))
+	b = appendInt(b, 21, audio.MaxSeq)
+	b = appendInt(b, 22, int64(audio.Feed))
+	b = appendInt(b, 23, int64(audio.Stitched))
+	if audio.NVol != nil {
+		b = appendInt8Ptr(b, 24, (*int8)(audio.NVol))
+	}
+	return appendJSON(b, audio.Ext)
+}
+
+func marshalNative(native *openrtb2.Native) []byte {
+	var b []byte
+	b = appendString(b, 1, native.Request)
+	b = appendString(b, 2, native.Ver)
+	b = appendPacked(b, 3, int64s(native.API))
+	b = appendPacked(b, 4, int64s(native.BAttr))
+	return appendJSON(b, native.Ext)
+}
+
+func marshalPMP(pmp *openrtb2.PMP) []byte {
+	var b []byte
+	b = appendInt(b, 1, int64(pmp.PrivateAuction))
+	for i := range pmp.Deals {
+		b = appendMessage(b, 2, marshalDeal(&pmp.Deals[i]))
+	}
+	return appendJSON(b, pmp.Ext)
+}
+
+func marshalDeal(deal *openrtb2.Deal) []byte {
+	var b []byte
+	b = appendString(b, 1, deal.ID)
+	b = appendDouble(b, 2, deal.BidFloor)
+	b = appendString(b, 3, deal.BidFloorCur)
+	b = appendStrings(b, 4, deal.WSeat)
+	b = appendStrings(b, 5, deal.WADomain)
+	b = appendInt(b, 6, deal.AT)
+	return appendJSON(b, deal.Ext)
+}
+
+func marshalSite(site *openrtb2.Site) []byte {
+	var b []byte
+	b = appendString(b, 1, site.ID)
+	b = appendString(b, 2, site.Name)
+	b = appendString(b, 3, site.Domain)
+	b = appendStrings(b, 4, site.Cat)
+	b = appendStrings(b, 5, site.SectionCat)
+	b = appendStrings(b, 6, site.PageCat)
+	b = appendString(b, 7, site.Page)
+	b = appendInt(b, 8, int64(site.PrivacyPolicy))
+	b = appendString(b, 9, site.Ref)
+	b = appendString(b, 10, site.Search)
+	if site.Publisher != nil {
+		b = appendMessage(b, 11, marshalPublisher(site.Publisher))
+	}
+	if site.Content != nil {
+		b = appendMessage(b, 12, marshalContent(site.Content))
+	}
+	b = appendString(b, 13, site.Keywords)
+	b = appendInt(b, 15, int64(site.Mobile))
+	return appendJSON(b, site.Ext)
+}
+
+func marshalApp(app *openrtb2.App) []byte {
+	var b []byte
+	b = appendString(b, 1, app.ID)
+	b = appendString(b, 2, app.Name)
+	b = appendString(b, 3, app.Domain)
+	b = appendStrings(b, 4, app.Cat)
+	b = appendStrings(b, 5, app.SectionCat)
+	b = appendStrings(b, 6, app.PageCat)
+	b = appendString(b, 7, app.Ver)
+	b = appendString(b, 8, app.Bundle)
+	b = appendInt(b, 9, int64(app.PrivacyPolicy))
+	b = appendInt(b, 10, int64(app.Paid))
+	if app.Publisher != nil {
+		b = appendMessage(b, 11, marshalPublisher(app.Publisher))
+	}
+	if app.Content != nil {
+		b = appendMessage(b, 12, marshalContent(app.Content))
+	}
+	b = appendString(b, 13, app.Keywords)
+	b = appendString(b, 16, app.StoreURL)
+	return appendJSON(b, app.Ext)
+}
+
+func marshalPublisher(publisher *openrtb2.Publisher) []byte {
+	var b []byte
+	b = appendString(b, 1, publisher.ID)
+	b = appendString(b, 2, publisher.Name)
+	b = appendStrings(b, 3, publisher.Cat)
+	b = appendString(b, 4, publisher.Domain)
+	return appendJSON(b, publisher.Ext)
+}
+
+func marshalContent(content *openrtb2.Content) []byte {
+	var b []byte
+	b = appendString(b, 1, content.ID)
+	b = appendInt(b, 2, content.Episode)
+	b = appendString(b, 3, content.Title)
+	b = appendString(b, 4, content.Series)
+	b = appendString(b, 5, content.Season)
+	b = appendString(b, 6, content.URL)
+	b = appendStrings(b, 7, content.Cat)
+	if content.VideoQuality != nil {
+		b = appendInt8Ptr(b, 8, (*int8)(content.VideoQuality))
+	}
+	b = appendString(b, 9, content.Keywords)
+	b = appendString(b, 10, content.ContentRating)
+	b = appendString(b, 11, content.UserRating)
+	b = appendInt(b, 13, int64(content.LiveStream))
+	b = appendInt(b, 14, int64(content.SourceRelationship))
+	if content.Producer != nil {
+		b = appendMessage(b, 15, marshalProducer(content.Producer))
+	}
+	b = appendInt(b, 16, content.Len)
+	b = appendInt(b, 17, int64(content.QAGMediaRating))
+	b = appendInt(b, 18, int64(content.Embeddable))
+	b = appendString(b, 19, content.Language)
+	b = appendInt(b, 20, int64(content.Context))
+	b = appendString(b, 21, content.Artist)
+	b = appendString(b, 22, content.Genre)
+	b = appendString(b, 23, content.Album)
+	b = appendString(b, 24, content.ISRC)
+	if content.ProdQ != nil {
+		b = appendInt8Ptr(b, 25, (*int8)(content.ProdQ))
+	}
+	for i := range content.Data {
+		b = appendMessage(b, 28, marshalData(&content.Data[i]))
+	}
+	return appendJSON(b, content.Ext)
+}
+
+func marshalProducer(producer *openrtb2.Producer) []byte {
+	var b []byte
+	b = appendString(b, 1, producer.ID)
+	b = appendString(b, 2, producer.Name)
+	b = appendStrings(b, 3, producer.Cat)
+	b = appendString(b, 4, producer.Domain)
+	return appendJSON(b, producer.Ext)
+}
+
+func marshalDevice(device *openrtb2.Device) []byte {
+	var b []byte
+	b = appendInt8Ptr(b, 1, device.DNT)
+	b = appendString(b, 2, device.UA)
+	b = appendString(b, 3, device.IP)
+	if device.Geo != nil {
+		b = appendMessage(b, 4, marshalGeo(device.Geo))
+	}
+	b = appendString(b, 5, device.DIDSHA1)
+	b = appendString(b, 6, device.DIDMD5)
+	b = appendString(b, 7, device.DPIDSHA1)
+	b = appendString(b, 8, device.DPIDMD5)
+	b = appendString(b, 9, device.IPv6)
+	b = appendString(b, 10, device.Carrier)
+	b = appendString(b, 11, device.Language)
+	b = appendString(b, 12, device.Make)
+	b = appendString(b, 13, device.Model)
+	b = appendString(b, 14, device.OS)
+	b = appendString(b, 15, device.OSV)
+	b = appendInt(b, 16, int64(device.JS))
+	if device.ConnectionType != nil {
+		b = appendInt8Ptr(b, 17, (*int8)(device.ConnectionType))
+	}
+	b = appendInt(b, 18, int64(device.DeviceType))
+	b = appendString(b, 19, device.FlashVer)
+	b = appendString(b, 20, device.IFA)
+	b = appendString(b, 21, device.MACSHA1)
+	b = appendString(b, 22, device.MACMD5)
+	b = appendInt8Ptr(b, 23, device.Lmt)
+	b = appendString(b, 24, device.HWV)
+	b = appendInt(b, 25, device.W)
+	b = appendInt(b, 26, device.H)
+	b = appendInt(b, 27, device.PPI)
+	b = appendDouble(b, 28, device.PxRatio)
+	b = appendInt(b, 29, int64(device.GeoFetch))
+	b = appendString(b, 30, device.MCCMNC)
+	return appendJSON(b, device.Ext)
+}
+
+func marshalGeo(geo *openrtb2.Geo) []byte {
+	var b []byte
+	b = appendDouble(b, 1, geo.Lat)
+	b = appendDouble(b, 2, geo.Lon)
+	b = appendString(b, 3, geo.Country)
+	b = appendString(b, 4, geo.Region)
+	b = appendString(b, 5, geo.RegionFIPS104)
+	b = appendString(b, 6, geo.Metro)
+	b = appendString(b, 7, geo.City)
+	b = appendString(b, 8, geo.ZIP)
+	b = appendInt(b, 9, int64(geo.Type))
+	b = appendInt(b, 10, geo.UTCOffset)
+	b = appendInt(b, 11, geo.Accuracy)
+	b = appendInt(b, 12, geo.LastFix)
+	b = appendInt(b, 13, int64(geo.IPService))
+	return appendJSON(b, geo.Ext)
+}
+
+func marshalUser(user *openrtb2.User) []byte {
+	var b []byte
+	b = appendString(b, 1, user.ID)
+	b = appendString(b, 2, user.BuyerUID)
+	b = appendInt(b, 3, user.Yob)
+	b = appendString(b, 4, user.Gender)
+	b = appendString(b, 5, user.Keywords)
+	b = appendString(b, 6, user.CustomData)
+	if user.Geo != nil {
+		b = appendMessage(b, 7, marshalGeo(user.Geo))
+	}
+	for i := range user.Data {
+		b = appendMessage(b, 8, marshalData(&user.Data[i]))
+	}
+	return appendJSON(b, user.Ext)
+}
+
+func marshalData(data *openrtb2.Data) []byte {
+	var b []byte
+	b = appendString(b, 1, data.ID)
+	b = appendString(b, 2, data.Name)
+	for i := range data.Segment {
+		b = appendMessage(b, 3, marshalSegment(&data.Segment[i]))
+	}
+	return appendJSON(b, data.Ext)
+}
+
+func marshalSegment(segment *openrtb2.Segment) []byte {
+	var b []byte
+	b = appendString(b, 1, segment.ID)
+	b = appendString(b, 2, segment.Name)
+	b = appendString(b, 3, segment.Value)
+	return appendJSON(b, segment.Ext)
+}
+
+func marshalRegs(regs *openrtb2.Regs) []byte {
+	var b []byte
+	b = appendInt(b, 1, int64(regs.COPPA))
+	return appendJSON(b, regs.Ext)
+}
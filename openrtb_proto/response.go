@@ -0,0 +1,186 @@
+package openrtb_proto
+
+import (
+	"math"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// MarshalBidResponse encodes a BidResponse with the messages of openrtb.proto.
+func MarshalBidResponse(resp *openrtb2.BidResponse) []byte {
+	var b []byte
+	b = appendString(b, 1, resp.ID)
+	for i := range resp.SeatBid {
+		b = appendMessage(b, 2, marshalSeatBid(&resp.SeatBid[i]))
+	}
+	b = appendString(b, 3, resp.BidID)
+	b = appendString(b, 4, resp.Cur)
+	b = appendString(b, 5, resp.CustomData)
+	if resp.NBR != nil {
+		b = appendInt8Ptr(b, 6, (*int8)(resp.NBR))
+	}
+	return appendJSON(b, resp.Ext)
+}
+
+func marshalSeatBid(seatBid *openrtb2.SeatBid) []byte {
+	var b []byte
+	for i := range seatBid.Bid {
+		b = appendMessage(b, 1, marshalBid(&seatBid.Bid[i]))
+	}
+	b = appendString(b, 2, seatBid.Seat)
+	b = appendInt(b, 3, int64(seatBid.Group))
+	return appendJSON(b, seatBid.Ext)
+}
+
+func marshalBid(bid *openrtb2.Bid) []byte {
+	var b []byte
+	b = appendString(b, 1, bid.ID)
+	b = appendString(b, 2, bid.ImpID)
+	// price is required, so it's written even when zero
+	b = protowire.AppendTag(b, 3, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, math.Float64bits(bid.Price))
+	b = appendString(b, 4, bid.AdID)
+	b = appendString(b, 5, bid.NURL)
+	b = appendString(b, 6, bid.AdM)
+	b = appendStrings(b, 7, bid.ADomain)
+	b = appendString(b, 8, bid.IURL)
+	b = appendString(b, 9, bid.CID)
+	b = appendString(b, 10, bid.CrID)
+	b = appendPacked(b, 11, int64s(bid.Attr))
+	b = appendString(b, 13, bid.DealID)
+	b = appendString(b, 14, bid.Bundle)
+	b = appendStrings(b, 15, bid.Cat)
+	b = appendInt(b, 16, bid.W)
+	b = appendInt(b, 17, bid.H)
+	b = appendInt(b, 18, int64(bid.API))
+	b = appendInt(b, 19, int64(bid.Protocol))
+	b = appendInt(b, 20, int64(bid.QAGMediaRating))
+	b = appendInt(b, 21, bid.Exp)
+	b = appendString(b, 22, bid.BURL)
+	b = appendString(b, 23, bid.LURL)
+	b = appendString(b, 24, bid.Tactic)
+	b = appendString(b, 25, bid.Language)
+	b = appendInt(b, 26, bid.WRatio)
+	b = appendInt(b, 27, bid.HRatio)
+	return appendJSON(b, bid.Ext)
+}
+
+// UnmarshalBidResponse decodes a BidResponse encoded with the messages of openrtb.proto.
+func UnmarshalBidResponse(b []byte, resp *openrtb2.BidResponse) error {
+	return unmarshalFields("bidresponse", b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1:
+			return consumeString(typ, b, &resp.ID)
+		case 2:
+			return consumeMessage(typ, b, func(b []byte) error {
+				resp.SeatBid = append(resp.SeatBid, openrtb2.SeatBid{})
+				return unmarshalSeatBid(b, &resp.SeatBid[len(resp.SeatBid)-1])
+			})
+		case 3:
+			return consumeString(typ, b, &resp.BidID)
+		case 4:
+			return consumeString(typ, b, &resp.Cur)
+		case 5:
+			return consumeString(typ, b, &resp.CustomData)
+		case 6:
+			var v uint64
+			n, err := consumeVarint(typ, b, &v)
+			resp.NBR = openrtb2.NoBidReasonCode(v).Ptr()
+			return n, err
+		case extFieldNumber:
+			return consumeJSON(typ, b, &resp.Ext)
+		}
+		return 0, nil
+	})
+}
+
+func unmarshalSeatBid(b []byte, seatBid *openrtb2.SeatBid) error {
+	return unmarshalFields("seatbid", b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1:
+			return consumeMessage(typ, b, func(b []byte) error {
+				seatBid.Bid = append(seatBid.Bid, openrtb2.Bid{})
+				return unmarshalBid(b, &seatBid.Bid[len(seatBid.Bid)-1])
+			})
+		case 2:
+			return consumeString(typ, b, &seatBid.Seat)
+		case 3:
+			return consumeInt8(typ, b, &seatBid.Group)
+		case extFieldNumber:
+			return consumeJSON(typ, b, &seatBid.Ext)
+		}
+		return 0, nil
+	})
+}
+
+func unmarshalBid(b []byte, bid *openrtb2.Bid) error {
+	return unmarshalFields("bid", b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		var v uint64
+		switch num {
+		case 1:
+			return consumeString(typ, b, &bid.ID)
+		case 2:
+			return consumeString(typ, b, &bid.ImpID)
+		case 3:
+			return consumeDouble(typ, b, &bid.Price)
+		case 4:
+			return consumeString(typ, b, &bid.AdID)
+		case 5:
+			return consumeString(typ, b, &bid.NURL)
+		case 6:
+			return consumeString(typ, b, &bid.AdM)
+		case 7:
+			return consumeStrings(typ, b, &bid.ADomain)
+		case 8:
+			return consumeString(typ, b, &bid.IURL)
+		case 9:
+			return consumeString(typ, b, &bid.CID)
+		case 10:
+			return consumeString(typ, b, &bid.CrID)
+		case 11:
+			return consumeVarints(typ, b, func(v uint64) { bid.Attr = append(bid.Attr, openrtb2.CreativeAttribute(v)) })
+		case 13:
+			return consumeString(typ, b, &bid.DealID)
+		case 14:
+			return consumeString(typ, b, &bid.Bundle)
+		case 15:
+			return consumeStrings(typ, b, &bid.Cat)
+		case 16:
+			return consumeInt64(typ, b, &bid.W)
+		case 17:
+			return consumeInt64(typ, b, &bid.H)
+		case 18:
+			n, err := consumeVarint(typ, b, &v)
+			bid.API = openrtb2.APIFramework(v)
+			return n, err
+		case 19:
+			n, err := consumeVarint(typ, b, &v)
+			bid.Protocol = openrtb2.Protocol(v)
+			return n, err
+		case 20:
+			n, err := consumeVarint(typ, b, &v)
+			bid.QAGMediaRating = openrtb2.IQGMediaRating(v)
+			return n, err
+		case 21:
+			return consumeInt64(typ, b, &bid.Exp)
+		case 22:
+			return consumeString(typ, b, &bid.BURL)
+		case 23:
+			return consumeString(typ, b, &bid.LURL)
+		case 24:
+			return consumeString(typ, b, &bid.Tactic)
+		case 25:
+			return consumeString(typ, b, &bid.Language)
+		case 26:
+			return consumeInt64(typ, b, &bid.WRatio)
+		case 27:
+			return consumeInt64(typ, b, &bid.HRatio)
+		case nativeObjectFieldNumber:
+			return 0, errNativeObject
+		case extFieldNumber:
+			return consumeJSON(typ, b, &bid.Ext)
+		}
+		return 0, nil
+	})
+}
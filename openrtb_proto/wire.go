@@ -0,0 +1,262 @@
+package openrtb_proto
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// extFieldNumber is the extension field which carries the JSON of the ext object in every message.
+const extFieldNumber protowire.Number = 100
+
+// nativeObjectFieldNumber is the field of imp.native.request_native and bid.adm_native, which hold the native
+// request and response as protobuf messages. The OpenRTB structs only hold them as JSON strings, in
+// imp.native.request and bid.adm, so they're rejected rather than dropped.
+const nativeObjectFieldNumber protowire.Number = 50
+
+// The defaults of the fields of openrtb.proto: the auction type of the BidRequest, which is second price, and the
+// currency of the floors of the Imp and the Deal.
+const (
+	defaultAuctionType = 2
+	defaultCurrency    = "USD"
+)
+
+var errWireType = errors.New("unexpected wire type")
+
+var errNativeObject = errors.New("the native objects aren't supported, the native markup must be sent as a JSON string")
+
+// unmarshalFields calls decodeField for every field of the message. The decoder returns the number of bytes
+// it consumed, or 0 if the field isn't supported, in which case it's skipped.
+func unmarshalFields(message string, b []byte, decodeField func(num protowire.Number, typ protowire.Type, b []byte) (int, error)) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return fmt.Errorf("%s: %v", message, protowire.ParseError(n))
+		}
+		b = b[n:]
+
+		n, err := decodeField(num, typ, b)
+		if err != nil {
+			return fmt.Errorf("%s field %d: %v", message, num, err)
+		}
+		if n == 0 {
+			if n = protowire.ConsumeFieldValue(num, typ, b); n < 0 {
+				return fmt.Errorf("%s field %d: %v", message, num, protowire.ParseError(n))
+			}
+		}
+		b = b[n:]
+	}
+	return nil
+}
+
+func consumeString(typ protowire.Type, b []byte, dst *string) (int, error) {
+	if typ != protowire.BytesType {
+		return 0, errWireType
+	}
+	v, n := protowire.ConsumeString(b)
+	if n < 0 {
+		return 0, protowire.ParseError(n)
+	}
+	*dst = v
+	return n, nil
+}
+
+func consumeStrings(typ protowire.Type, b []byte, dst *[]string) (int, error) {
+	var v string
+	n, err := consumeString(typ, b, &v)
+	if err == nil {
+		*dst = append(*dst, v)
+	}
+	return n, err
+}
+
+func consumeJSON(typ protowire.Type, b []byte, dst *json.RawMessage) (int, error) {
+	var v string
+	n, err := consumeString(typ, b, &v)
+	if err == nil {
+		*dst = json.RawMessage(v)
+	}
+	return n, err
+}
+
+func consumeVarint(typ protowire.Type, b []byte, dst *uint64) (int, error) {
+	if typ != protowire.VarintType {
+		return 0, errWireType
+	}
+	v, n := protowire.ConsumeVarint(b)
+	if n < 0 {
+		return 0, protowire.ParseError(n)
+	}
+	*dst = v
+	return n, nil
+}
+
+func consumeInt64(typ protowire.Type, b []byte, dst *int64) (int, error) {
+	var v uint64
+	n, err := consumeVarint(typ, b, &v)
+	if err == nil {
+		*dst = int64(v)
+	}
+	return n, err
+}
+
+// consumeInt8 reads the bools and the small enums which the OpenRTB structs store as int8.
+func consumeInt8(typ protowire.Type, b []byte, dst *int8) (int, error) {
+	var v uint64
+	n, err := consumeVarint(typ, b, &v)
+	if err == nil {
+		*dst = int8(v)
+	}
+	return n, err
+}
+
+func consumeInt8Ptr(typ protowire.Type, b []byte, dst **int8) (int, error) {
+	var v int8
+	n, err := consumeInt8(typ, b, &v)
+	if err == nil {
+		*dst = &v
+	}
+	return n, err
+}
+
+// consumeVarints reads a repeated varint field, in either its packed or its unpacked encoding.
+func consumeVarints(typ protowire.Type, b []byte, add func(v uint64)) (int, error) {
+	if typ == protowire.VarintType {
+		var v uint64
+		n, err := consumeVarint(typ, b, &v)
+		if err == nil {
+			add(v)
+		}
+		return n, err
+	}
+	if typ != protowire.BytesType {
+		return 0, errWireType
+	}
+	packed, n := protowire.ConsumeBytes(b)
+	if n < 0 {
+		return 0, protowire.ParseError(n)
+	}
+	for len(packed) > 0 {
+		v, vn := protowire.ConsumeVarint(packed)
+		if vn < 0 {
+			return 0, protowire.ParseError(vn)
+		}
+		add(v)
+		packed = packed[vn:]
+	}
+	return n, nil
+}
+
+func consumeDouble(typ protowire.Type, b []byte, dst *float64) (int, error) {
+	if typ != protowire.Fixed64Type {
+		return 0, errWireType
+	}
+	v, n := protowire.ConsumeFixed64(b)
+	if n < 0 {
+		return 0, protowire.ParseError(n)
+	}
+	*dst = math.Float64frombits(v)
+	return n, nil
+}
+
+func consumeMessage(typ protowire.Type, b []byte, decode func(b []byte) error) (int, error) {
+	if typ != protowire.BytesType {
+		return 0, errWireType
+	}
+	v, n := protowire.ConsumeBytes(b)
+	if n < 0 {
+		return 0, protowire.ParseError(n)
+	}
+	return n, decode(v)
+}
+
+// The append functions omit the fields which hold their zero value, like the omitempty JSON tags of the
+// OpenRTB structs.
+
+func appendString(b []byte, num protowire.Number, v string) []byte {
+	if v == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, v)
+}
+
+func appendStrings(b []byte, num protowire.Number, v []string) []byte {
+	for _, s := range v {
+		b = protowire.AppendTag(b, num, protowire.BytesType)
+		b = protowire.AppendString(b, s)
+	}
+	return b
+}
+
+func appendJSON(b []byte, v json.RawMessage) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, extFieldNumber, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+func appendInt(b []byte, num protowire.Number, v int64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(v))
+}
+
+func appendIntPtr(b []byte, num protowire.Number, v *int64) []byte {
+	if v == nil {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(*v))
+}
+
+func appendInt8Ptr(b []byte, num protowire.Number, v *int8) []byte {
+	if v == nil {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(*v))
+}
+
+func appendPacked(b []byte, num protowire.Number, v []int64) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	var packed []byte
+	for _, i := range v {
+		packed = protowire.AppendVarint(packed, uint64(i))
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, packed)
+}
+
+func appendDouble(b []byte, num protowire.Number, v float64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.Fixed64Type)
+	return protowire.AppendFixed64(b, math.Float64bits(v))
+}
+
+// appendMessage always writes the message, even when empty, since its presence is meaningful.
+func appendMessage(b []byte, num protowire.Number, message []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, message)
+}
+
+// int64s converts a slice of one of the OpenRTB enums to the values of a repeated int32 field.
+func int64s(enums interface{}) []int64 {
+	v := reflect.ValueOf(enums)
+	ints := make([]int64, v.Len())
+	for i := range ints {
+		ints[i] = v.Index(i).Int()
+	}
+	return ints
+}
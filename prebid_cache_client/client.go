@@ -14,9 +14,11 @@ import (
 
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/tracing"
 
 	"github.com/buger/jsonparser"
 	"github.com/golang/glog"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/net/context/ctxhttp"
 )
 
@@ -90,6 +92,9 @@ func (c *clientImpl) PutJson(ctx context.Context, values []Cacheable) (uuids []s
 		return nil, errs
 	}
 
+	ctx, span := tracing.StartSpan(ctx, "prebid_cache.PutJson", attribute.Int("prebid_cache.values", len(values)))
+	defer func() { tracing.EndSpanWithErrors(span, errs) }()
+
 	uuidsToReturn := make([]string, len(values))
 
 	postBody, err := encodeValues(values)
@@ -7,9 +7,10 @@ import (
 
 	"github.com/prebid/prebid-server/currency"
 	"github.com/prebid/prebid-server/endpoints"
+	"github.com/prebid/prebid-server/stored_requests"
 )
 
-func Admin(revision string, rateConverter *currency.RateConverter, rateConverterFetchingInterval time.Duration) *http.ServeMux {
+func Admin(revision string, rateConverter *currency.RateConverter, rateConverterFetchingInterval time.Duration, reloadConfig func() error, storedDataCaches map[string]stored_requests.InspectableCache) *http.ServeMux {
 	// Add endpoints to the admin server
 	// Making sure to add pprof routes
 	mux := http.NewServeMux()
@@ -22,5 +23,9 @@ func Admin(revision string, rateConverter *currency.RateConverter, rateConverter
 	// Register prebid-server defined admin handlers
 	mux.HandleFunc("/currency/rates", endpoints.NewCurrencyRatesEndpoint(rateConverter, rateConverterFetchingInterval))
 	mux.HandleFunc("/version", endpoints.NewVersionEndpoint(revision))
+	mux.HandleFunc("/config/reload", endpoints.NewConfigReloadEndpoint(reloadConfig))
+	mux.HandleFunc("/stored_data/caches", endpoints.NewStoredDataCachesEndpoint(storedDataCaches))
+	mux.HandleFunc("/stored_data/caches/entry", endpoints.NewStoredDataCacheEntryEndpoint(storedDataCaches))
+	mux.HandleFunc("/stored_data/caches/invalidate", endpoints.NewStoredDataCacheInvalidateEndpoint(storedDataCaches))
 	return mux
 }
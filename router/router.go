@@ -37,13 +37,17 @@ import (
 	infoEndpoints "github.com/prebid/prebid-server/endpoints/info"
 	"github.com/prebid/prebid-server/endpoints/openrtb2"
 	"github.com/prebid/prebid-server/exchange"
+	"github.com/prebid/prebid-server/floors"
 	"github.com/prebid/prebid-server/gdpr"
+	"github.com/prebid/prebid-server/hooks"
 	metricsConf "github.com/prebid/prebid-server/metrics/config"
+	"github.com/prebid/prebid-server/modules"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/pbs"
 	pbc "github.com/prebid/prebid-server/prebid_cache_client"
 	"github.com/prebid/prebid-server/router/aspects"
 	"github.com/prebid/prebid-server/server/ssl"
+	"github.com/prebid/prebid-server/stored_requests"
 	storedRequestsConf "github.com/prebid/prebid-server/stored_requests/config"
 	"github.com/prebid/prebid-server/usersync/usersyncers"
 
@@ -51,6 +55,7 @@ import (
 	"github.com/julienschmidt/httprouter"
 	_ "github.com/lib/pq"
 	"github.com/rs/cors"
+	"google.golang.org/grpc"
 )
 
 var dataCache cache.Cache
@@ -85,6 +90,11 @@ func NewJsonDirectoryServer(schemaDirectory string, validator openrtb_ext.Bidder
 		data[bidder] = json.RawMessage(validator.Schema(bidderName))
 	}
 
+	// Add in the hard aliases, which take the same params as their core bidder
+	for aliasName := range openrtb_ext.GetAliasBidderToParent() {
+		data[string(aliasName)] = json.RawMessage(validator.Schema(aliasName))
+	}
+
 	// Add in any default aliases
 	for aliasName, bidderName := range aliases {
 		bidderData, ok := data[bidderName]
@@ -170,6 +180,10 @@ type Router struct {
 	MetricsEngine   *metricsConf.DetailedMetricsEngine
 	ParamsValidator openrtb_ext.BidderParamValidator
 	Shutdown        func()
+	// StoredDataCaches are the in-memory caches of the stored requests, imps and accounts, keyed by name
+	StoredDataCaches map[string]stored_requests.InspectableCache
+	// GRPCServer serves the auction endpoint over gRPC. It's nil unless grpc.enabled is set.
+	GRPCServer *grpc.Server
 }
 
 func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *Router, err error) {
@@ -180,6 +194,15 @@ func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *R
 		Router: httprouter.New(),
 	}
 
+	// Register the hard aliases first, since all the lists of bidders built below must include them
+	for bidder, adapterCfg := range cfg.Adapters {
+		if adapterCfg.AliasOf != "" {
+			if err := openrtb_ext.SetAliasBidderName(bidder, adapterCfg.AliasOf); err != nil {
+				glog.Fatalf("Invalid hard alias in adapters.%s: %v", bidder, err)
+			}
+		}
+	}
+
 	// For bid processing, we need both the hardcoded certificates and the certificates found in container's
 	// local file system
 	certPool := ssl.GetRootCAPool()
@@ -214,14 +237,18 @@ func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *R
 
 	// Metrics engine
 	r.MetricsEngine = metricsConf.NewMetricsEngine(cfg, legacyBidderList)
-	db, shutdown, fetcher, ampFetcher, accounts, categoriesFetcher, videoFetcher := storedRequestsConf.NewStoredRequests(cfg, r.MetricsEngine, generalHttpClient, r.Router)
+	db, shutdown, fetcher, ampFetcher, accounts, categoriesFetcher, videoFetcher, storedDataCaches := storedRequestsConf.NewStoredRequests(cfg, r.MetricsEngine, generalHttpClient, r.Router)
+	r.StoredDataCaches = storedDataCaches
 	// todo(zachbadgett): better shutdown
 	r.Shutdown = shutdown
 	if err := loadDataCache(cfg, db); err != nil {
 		return nil, fmt.Errorf("Prebid Server could not load data cache: %v", err)
 	}
 
-	pbsAnalytics := analyticsConf.NewPBSAnalytics(&cfg.Analytics)
+	pbsAnalytics := analyticsConf.NewPBSAnalytics(&cfg.Analytics, r.MetricsEngine)
+	cfg.OnReload(func(reloaded *config.Configuration) {
+		analyticsConf.ReloadFilters(pbsAnalytics, &reloaded.Analytics)
+	})
 
 	paramsValidator, err := openrtb_ext.NewBidderParamsValidator(schemaDirectory)
 	if err != nil {
@@ -255,23 +282,45 @@ func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *R
 		glog.Fatalf("%v", errs)
 	}
 
-	theExchange := exchange.NewExchange(adapters, cacheClient, cfg, r.MetricsEngine, bidderInfos, gdprPerms, rateConvertor, categoriesFetcher)
+	var floorsFetcher floors.Fetcher = floors.EmptyFetcher{}
+	if cfg.PriceFloors.Enabled && cfg.PriceFloors.RulesFile != "" {
+		if floorsFetcher, err = floors.NewFileFetcher(cfg.PriceFloors.RulesFile); err != nil {
+			glog.Fatalf("Failed to load the price floor rules. %v", err)
+		}
+	}
+
+	var planBuilder hooks.ExecutionPlanBuilder = hooks.EmptyPlanBuilder{}
+	if cfg.Hooks.Enabled {
+		planBuilder, err = buildHookPlanBuilder(cfg.Hooks, generalHttpClient)
+		if err != nil {
+			glog.Fatalf("Failed to initialize hook modules. %v", err)
+		}
+	}
+
+	theExchange := exchange.NewExchange(adapters, cacheClient, cfg, r.MetricsEngine, bidderInfos, gdprPerms, rateConvertor, categoriesFetcher, floorsFetcher)
 
-	openrtbEndpoint, err := openrtb2.NewEndpoint(theExchange, paramsValidator, fetcher, accounts, cfg, r.MetricsEngine, pbsAnalytics, disabledBidders, defReqJSON, activeBidders)
+	openrtbEndpoint, err := openrtb2.NewEndpoint(theExchange, paramsValidator, fetcher, accounts, cfg, r.MetricsEngine, pbsAnalytics, disabledBidders, defReqJSON, activeBidders, planBuilder)
 	if err != nil {
 		glog.Fatalf("Failed to create the openrtb2 endpoint handler. %v", err)
 	}
 
-	ampEndpoint, err := openrtb2.NewAmpEndpoint(theExchange, paramsValidator, ampFetcher, accounts, cfg, r.MetricsEngine, pbsAnalytics, disabledBidders, defReqJSON, activeBidders)
+	ampEndpoint, err := openrtb2.NewAmpEndpoint(theExchange, paramsValidator, ampFetcher, accounts, cfg, r.MetricsEngine, pbsAnalytics, disabledBidders, defReqJSON, activeBidders, planBuilder)
 	if err != nil {
 		glog.Fatalf("Failed to create the amp endpoint handler. %v", err)
 	}
 
-	videoEndpoint, err := openrtb2.NewVideoEndpoint(theExchange, paramsValidator, fetcher, videoFetcher, accounts, cfg, r.MetricsEngine, pbsAnalytics, disabledBidders, defReqJSON, activeBidders, cacheClient)
+	videoEndpoint, err := openrtb2.NewVideoEndpoint(theExchange, paramsValidator, fetcher, videoFetcher, accounts, cfg, r.MetricsEngine, pbsAnalytics, disabledBidders, defReqJSON, activeBidders, cacheClient, planBuilder)
 	if err != nil {
 		glog.Fatalf("Failed to create the video endpoint handler. %v", err)
 	}
 
+	if cfg.GRPC.Enabled {
+		r.GRPCServer, err = openrtb2.NewGRPCEndpoint(theExchange, paramsValidator, fetcher, accounts, cfg, r.MetricsEngine, pbsAnalytics, disabledBidders, defReqJSON, activeBidders, planBuilder)
+		if err != nil {
+			glog.Fatalf("Failed to create the gRPC auction endpoint. %v", err)
+		}
+	}
+
 	requestTimeoutHeaders := config.RequestTimeoutHeaders{}
 	if cfg.RequestTimeoutHeaders != requestTimeoutHeaders {
 		videoEndpoint = aspects.QueuedRequestTimeout(videoEndpoint, cfg.RequestTimeoutHeaders, r.MetricsEngine, metrics.ReqTypeVideo)
@@ -378,6 +427,20 @@ func readDefaultRequest(defReqConfig config.DefReqConfig) (map[string]string, []
 	return aliases, []byte{}
 }
 
+func buildHookPlanBuilder(cfg config.Hooks, client *http.Client) (hooks.ExecutionPlanBuilder, error) {
+	builtModules, err := modules.Build(cfg.Modules, modules.ModuleDeps{HTTPClient: client})
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := hooks.NewHookRepository(builtModules)
+	if err != nil {
+		return nil, err
+	}
+
+	return hooks.NewExecutionPlanBuilder(cfg, repo), nil
+}
+
 func validateDefaultAliases(aliases map[string]string) error {
 	var errs []error
 
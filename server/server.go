@@ -16,17 +16,18 @@ import (
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/metrics"
 	metricsconfig "github.com/prebid/prebid-server/metrics/config"
+	"google.golang.org/grpc"
 )
 
 // Listen blocks forever, serving PBS requests on the given port. This will block forever, until the process is shut down.
-func Listen(cfg *config.Configuration, handler http.Handler, adminHandler http.Handler, metrics *metricsconfig.DetailedMetricsEngine) {
+// The gRPC server, if not nil, serves the gRPC auction endpoint on its own port.
+func Listen(cfg *config.Configuration, handler http.Handler, adminHandler http.Handler, grpcServer *grpc.Server, metrics *metricsconfig.DetailedMetricsEngine) {
 	stopSignals := make(chan os.Signal)
 	signal.Notify(stopSignals, syscall.SIGTERM, syscall.SIGINT)
 
 	// Run the servers. Fan any process-stopper signals out to each server for graceful shutdowns.
 	stopAdmin := make(chan os.Signal)
 	stopMain := make(chan os.Signal)
-	stopPrometheus := make(chan os.Signal)
 	done := make(chan struct{})
 
 	adminServer := newAdminServer(cfg, adminHandler)
@@ -47,8 +48,10 @@ func Listen(cfg *config.Configuration, handler http.Handler, adminHandler http.H
 	}
 	go runServer(mainServer, "Main", mainListener)
 	go runServer(adminServer, "Admin", adminListener)
+	stoppers := []chan<- os.Signal{stopMain, stopAdmin}
 
 	if cfg.Metrics.Prometheus.Port != 0 {
+		stopPrometheus := make(chan os.Signal)
 		prometheusServer := newPrometheusServer(cfg, metrics)
 		go shutdownAfterSignals(prometheusServer, stopPrometheus, done)
 		prometheusListener, err := newListener(prometheusServer.Addr, nil)
@@ -57,11 +60,23 @@ func Listen(cfg *config.Configuration, handler http.Handler, adminHandler http.H
 			return
 		}
 		go runServer(prometheusServer, "Prometheus", prometheusListener)
+		stoppers = append(stoppers, stopPrometheus)
+	}
 
-		wait(stopSignals, done, stopMain, stopAdmin, stopPrometheus)
-	} else {
-		wait(stopSignals, done, stopMain, stopAdmin)
+	if grpcServer != nil {
+		stopGRPC := make(chan os.Signal)
+		grpcAddr := cfg.Host + ":" + strconv.Itoa(cfg.GRPC.Port)
+		go shutdownGRPCAfterSignals(grpcServer, grpcAddr, stopGRPC, done)
+		grpcListener, err := newListener(grpcAddr, nil)
+		if err != nil {
+			glog.Errorf("Error listening for TCP connections on %s: %v for gRPC server", grpcAddr, err)
+			return
+		}
+		go runGRPCServer(grpcServer, grpcAddr, grpcListener)
+		stoppers = append(stoppers, stopGRPC)
 	}
+
+	wait(stopSignals, done, stoppers...)
 	return
 }
 
@@ -93,6 +108,12 @@ func runServer(server *http.Server, name string, listener net.Listener) {
 	glog.Errorf("%s server quit with error: %v", name, err)
 }
 
+func runGRPCServer(server *grpc.Server, addr string, listener net.Listener) {
+	glog.Infof("gRPC server starting on: %s", addr)
+	err := server.Serve(listener)
+	glog.Errorf("gRPC server quit with error: %v", err)
+}
+
 func newListener(address string, metrics metrics.MetricsEngine) (net.Listener, error) {
 	ln, err := net.Listen("tcp", address)
 	if err != nil {
@@ -139,6 +160,27 @@ func shutdownAfterSignals(server *http.Server, stopper <-chan os.Signal, done ch
 	done <- s
 }
 
+// shutdownGRPCAfterSignals stops the gRPC server gracefully, giving it the same time as the HTTP servers to finish
+// the auctions in progress.
+func shutdownGRPCAfterSignals(server *grpc.Server, addr string, stopper <-chan os.Signal, done chan<- struct{}) {
+	sig := <-stopper
+
+	var s struct{}
+	glog.Infof("Stopping %s because of signal: %s", addr, sig.String())
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(10 * time.Second):
+		glog.Errorf("Failed to shutdown %s gracefully, stopping it", addr)
+		server.Stop()
+	}
+	done <- s
+}
+
 func sendSignal(to chan<- os.Signal, sig os.Signal) {
 	to <- sig
 }
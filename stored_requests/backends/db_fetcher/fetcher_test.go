@@ -173,6 +173,49 @@ func TestRowErrors(t *testing.T) {
 	assertMapLength(t, 0, data)
 }
 
+// TestResponses makes sure we unpack the Stored Responses properly when the DB finds some of them.
+func TestResponses(t *testing.T) {
+	mockQuery := "SELECT id, data, 'response' AS dataType FROM responses_table WHERE id IN (?, ?)"
+	mockReturn := sqlmock.NewRows([]string{"id", "data", "dataType"}).
+		AddRow("response-id", `[{"bid":[]}]`, "response")
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+	mock.ExpectQuery(fmt.Sprintf("^%s$", regexp.QuoteMeta(mockQuery))).WithArgs("response-id", "response-id-2").WillReturnRows(mockReturn)
+	fetcher := &dbFetcher{
+		db:                 db,
+		queryMaker:         successfulQueryMaker(""),
+		responseQueryMaker: func(numIDs int) string { return mockQuery },
+	}
+
+	data, errs := fetcher.FetchResponses(context.Background(), []string{"response-id", "response-id-2"})
+
+	assertMockExpectations(t, mock)
+	assertErrorCount(t, 1, errs)
+	assertMapLength(t, 1, data)
+	assertHasData(t, data, "response-id", `[{"bid":[]}]`)
+}
+
+// TestResponsesWithoutQuery makes sure no Stored Responses are found when there's no query for them.
+func TestResponsesWithoutQuery(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Unexpected error stubbing DB: %v", err)
+	}
+	defer db.Close()
+
+	fetcher := &dbFetcher{
+		db:         db,
+		queryMaker: successfulQueryMaker(""),
+	}
+	data, errs := fetcher.FetchResponses(context.Background(), []string{"response-id"})
+	assertErrorCount(t, 1, errs)
+	assertMapLength(t, 0, data)
+}
+
 func newFetcher(t *testing.T, rows *sqlmock.Rows, query string, args ...driver.Value) (sqlmock.Sqlmock, *dbFetcher) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
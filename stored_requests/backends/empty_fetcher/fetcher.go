@@ -28,6 +28,17 @@ func (fetcher EmptyFetcher) FetchRequests(ctx context.Context, requestIDs []stri
 	return
 }
 
+func (fetcher EmptyFetcher) FetchResponses(ctx context.Context, ids []string) (data map[string]json.RawMessage, errs []error) {
+	errs = make([]error, 0, len(ids))
+	for _, id := range ids {
+		errs = append(errs, stored_requests.NotFoundError{
+			ID:       id,
+			DataType: "Response",
+		})
+	}
+	return
+}
+
 func (fetcher EmptyFetcher) FetchAccount(ctx context.Context, accountID string) (json.RawMessage, []error) {
 	return nil, []error{stored_requests.NotFoundError{accountID, "Account"}}
 }
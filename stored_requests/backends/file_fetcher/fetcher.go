@@ -15,6 +15,9 @@ import (
 //
 // This expects each file in the directory to be named "{config_id}.json".
 // For example, when asked to fetch the request with ID == "23", it will return the data from "directory/23.json".
+//
+// The Stored Requests, Imps and Responses are read from the "stored_requests", "stored_imps" and "stored_responses"
+// subdirectories.
 func NewFileFetcher(directory string) (stored_requests.AllFetcher, error) {
 	storedData, err := collectStoredData(directory, FileSystem{make(map[string]FileSystem), make(map[string]json.RawMessage)}, nil)
 	return &eagerFetcher{storedData, nil}, err
@@ -33,6 +36,11 @@ func (fetcher *eagerFetcher) FetchRequests(ctx context.Context, requestIDs []str
 	return storedRequests, storedImpressions, errs
 }
 
+func (fetcher *eagerFetcher) FetchResponses(ctx context.Context, ids []string) (map[string]json.RawMessage, []error) {
+	storedResponses := fetcher.FileSystem.Directories["stored_responses"].Files
+	return storedResponses, appendErrors("Response", ids, storedResponses, nil)
+}
+
 // FetchAccount fetches the host account configuration for a publisher
 func (fetcher *eagerFetcher) FetchAccount(ctx context.Context, accountID string) (json.RawMessage, []error) {
 	if len(accountID) == 0 {
@@ -38,6 +38,15 @@ func TestAccountFetcher(t *testing.T) {
 	assert.Equal(t, stored_requests.NotFoundError{"nonexistent", "Account"}, errs[0])
 }
 
+func TestResponseFetcher(t *testing.T) {
+	fetcher, err := NewFileFetcher("./test")
+	assert.NoError(t, err, "Failed to create test fetcher")
+
+	responses, errs := fetcher.FetchResponses(context.Background(), []string{"some-response", "nonexistent"})
+	assert.Equal(t, []error{stored_requests.NotFoundError{ID: "nonexistent", DataType: "Response"}}, errs)
+	assert.JSONEq(t, `[{"seat":"appnexus","bid":[{"id":"bid-1","price":1.5}]}]`, string(responses["some-response"]))
+}
+
 func TestInvalidDirectory(t *testing.T) {
 	_, err := NewFileFetcher("./nonexistant-directory")
 	if err == nil {
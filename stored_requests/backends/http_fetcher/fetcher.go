@@ -23,6 +23,9 @@ import (
 // Stored requests
 // GET {endpoint}?request-ids=["req1","req2"]&imp-ids=["imp1","imp2","imp3"]
 //
+// Stored responses
+// GET {endpoint}?resp-ids=["resp1","resp2"]
+//
 // Accounts
 // GET {endpoint}?account-ids=["acc1","acc2"]
 //
@@ -41,6 +44,13 @@ import (
 // }
 // or
 // {
+//   "responses": {
+//     "resp1": { ... stored data for resp1 ... },
+//     "resp2": null // If resp2 is not found
+//   }
+// }
+// or
+// {
 //   "accounts": {
 //     "acc1": { ... config data for acc1 ... },
 //     "acc2": { ... config data for acc2 ... },
@@ -97,6 +107,40 @@ func (fetcher *HttpFetcher) FetchRequests(ctx context.Context, requestIDs []stri
 	return
 }
 
+// FetchResponses retrieves the stored auction and bid responses
+//
+// GET {endpoint}?resp-ids=["resp1","resp2",...]
+func (fetcher *HttpFetcher) FetchResponses(ctx context.Context, ids []string) (data map[string]json.RawMessage, errs []error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", fetcher.Endpoint+"resp-ids=[\""+strings.Join(ids, "\",\"")+"\"]", nil)
+	if err != nil {
+		return nil, []error{err}
+	}
+	httpResp, err := ctxhttp.Do(ctx, fetcher.client, httpReq)
+	if err != nil {
+		return nil, []error{err}
+	}
+	defer httpResp.Body.Close()
+
+	respBytes, err := ioutil.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, []error{err}
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, []error{fmt.Errorf("Error fetching Stored Responses via HTTP. Response code was %d", httpResp.StatusCode)}
+	}
+
+	var responseData responsesResponseContract
+	if err := json.Unmarshal(respBytes, &responseData); err != nil {
+		return nil, []error{err}
+	}
+	errs = convertNullsToErrs(responseData.Responses, "Response", errs)
+	return responseData.Responses, errs
+}
+
 // FetchAccounts retrieves account configurations
 //
 // Request format is similar to the one for requests:
@@ -267,6 +311,10 @@ type responseContract struct {
 	Imps     map[string]json.RawMessage `json:"imps"`
 }
 
+type responsesResponseContract struct {
+	Responses map[string]json.RawMessage `json:"responses"`
+}
+
 type accountsResponseContract struct {
 	Accounts map[string]json.RawMessage `json:"accounts"`
 }
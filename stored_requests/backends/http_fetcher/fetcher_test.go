@@ -74,6 +74,24 @@ func TestMissingValues(t *testing.T) {
 	assert.Len(t, errs, 3, "Fetching 3 unknown reqs+imps should return 3 errors")
 }
 
+func TestFetchResponses(t *testing.T) {
+	fetcher, close := newTestResponsesFetcher(t, []string{"resp-1", "resp-2"}, "resp-2")
+	defer close()
+
+	respData, errs := fetcher.FetchResponses(context.Background(), []string{"resp-1", "resp-2"})
+	assertMapKeys(t, respData, "resp-1")
+	assert.Len(t, errs, 1)
+}
+
+func TestFetchResponsesErrResponse(t *testing.T) {
+	fetcher, close := newFetcherBrokenBackend()
+	defer close()
+
+	respData, errs := fetcher.FetchResponses(context.Background(), []string{"resp-1"})
+	assert.Nil(t, respData)
+	assert.Len(t, errs, 1)
+}
+
 func TestFetchAccounts(t *testing.T) {
 	fetcher, close := newTestAccountFetcher(t, []string{"acc-1", "acc-2"})
 	defer close()
@@ -270,6 +288,29 @@ func newHandler(t *testing.T, expectReqIDs []string, expectImpIDs []string, json
 	}
 }
 
+func newTestResponsesFetcher(t *testing.T, expectIDs []string, missingID string) (fetcher *HttpFetcher, closer func()) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		gotIDs := richSplit(r.URL.Query().Get("resp-ids"))
+		assertMatches(t, gotIDs, expectIDs)
+
+		respObj := responsesResponseContract{
+			Responses: make(map[string]json.RawMessage, len(gotIDs)),
+		}
+		for _, id := range gotIDs {
+			if id == missingID {
+				respObj.Responses[id] = jsonifyToNull(id)
+			} else {
+				respObj.Responses[id] = jsonifyID(id)
+			}
+		}
+
+		respBytes, _ := json.Marshal(respObj)
+		w.Write(respBytes)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	return NewFetcher(server.Client(), server.URL), server.Close
+}
+
 func newTestAccountFetcher(t *testing.T, expectAccIDs []string) (fetcher *HttpFetcher, closer func()) {
 	handler := newAccountHandler(t, expectAccIDs, jsonifyID)
 	server := httptest.NewServer(http.HandlerFunc(handler))
@@ -0,0 +1,190 @@
+package redis_fetcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/golang/glog"
+	"github.com/gomodule/redigo/redis"
+	"github.com/prebid/prebid-server/stored_requests"
+)
+
+// Pool is the subset of *redis.Pool used by the fetcher.
+type Pool interface {
+	GetContext(ctx context.Context) (redis.Conn, error)
+}
+
+// KeyPrefixes are prepended to the IDs to build the Redis key of each type of stored data.
+//
+// Stored Requests live under {Requests}{id}, Stored Imps under {Imps}{id}, Stored Responses under {Responses}{id}
+// and accounts under {Accounts}{id}.
+// Category mappings live under {Categories}{primaryAdServer} or {Categories}{primaryAdServer}_{publisherId},
+// and hold the same JSON as the files read by the file_fetcher.
+type KeyPrefixes struct {
+	Requests   string
+	Imps       string
+	Responses  string
+	Accounts   string
+	Categories string
+}
+
+// NewFetcher returns a Fetcher which reads the stored data from Redis.
+//
+// Batches of IDs are looked up with one MGET per data type, pipelined over a single connection.
+func NewFetcher(pool Pool, prefixes KeyPrefixes) stored_requests.AllFetcher {
+	if pool == nil {
+		glog.Fatalf("The Redis Stored Request Fetcher requires a connection pool. Please report this as a bug.")
+	}
+	return &redisFetcher{
+		pool:     pool,
+		prefixes: prefixes,
+	}
+}
+
+// redisFetcher fetches Stored Requests from Redis. This should be instantiated through the NewFetcher() function.
+type redisFetcher struct {
+	pool     Pool
+	prefixes KeyPrefixes
+}
+
+func (fetcher *redisFetcher) FetchRequests(ctx context.Context, requestIDs []string, impIDs []string) (map[string]json.RawMessage, map[string]json.RawMessage, []error) {
+	if len(requestIDs) == 0 && len(impIDs) == 0 {
+		return nil, nil, nil
+	}
+
+	values, err := fetcher.mget(ctx, buildKeys(fetcher.prefixes.Requests, requestIDs), buildKeys(fetcher.prefixes.Imps, impIDs))
+	if err != nil {
+		if err != context.DeadlineExceeded && err != context.Canceled {
+			glog.Errorf("Error reading from Stored Request Redis: %v", err)
+		}
+		return nil, nil, []error{err}
+	}
+
+	requestData, errs := unpackValues("Request", requestIDs, values[0], nil)
+	impData, errs := unpackValues("Imp", impIDs, values[1], errs)
+	return requestData, impData, errs
+}
+
+func (fetcher *redisFetcher) FetchResponses(ctx context.Context, ids []string) (map[string]json.RawMessage, []error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	values, err := fetcher.mget(ctx, buildKeys(fetcher.prefixes.Responses, ids))
+	if err != nil {
+		if err != context.DeadlineExceeded && err != context.Canceled {
+			glog.Errorf("Error reading from Stored Response Redis: %v", err)
+		}
+		return nil, []error{err}
+	}
+
+	return unpackValues("Response", ids, values[0], nil)
+}
+
+func (fetcher *redisFetcher) FetchAccount(ctx context.Context, accountID string) (json.RawMessage, []error) {
+	value, err := fetcher.get(ctx, fetcher.prefixes.Accounts+accountID)
+	if err == redis.ErrNil {
+		return nil, []error{stored_requests.NotFoundError{
+			ID:       accountID,
+			DataType: "Account",
+		}}
+	}
+	if err != nil {
+		return nil, []error{fmt.Errorf("Error fetching account %s via redis: %v", accountID, err)}
+	}
+	return value, nil
+}
+
+func (fetcher *redisFetcher) FetchCategories(ctx context.Context, primaryAdServer, publisherId, iabCategory string) (string, error) {
+	dataName := primaryAdServer
+	if publisherId != "" {
+		dataName = fmt.Sprintf("%s_%s", primaryAdServer, publisherId)
+	}
+
+	value, err := fetcher.get(ctx, fetcher.prefixes.Categories+dataName)
+	if err == redis.ErrNil {
+		return "", fmt.Errorf("Unable to find category mapping for adserver: '%s', publisherId: '%s'", primaryAdServer, publisherId)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	categories := make(map[string]stored_requests.Category)
+	if err := json.Unmarshal(value, &categories); err != nil {
+		return "", fmt.Errorf("Unable to unmarshal categories for adserver: '%s', publisherId: '%s'", primaryAdServer, publisherId)
+	}
+
+	if category, ok := categories[iabCategory]; ok {
+		return category.Id, nil
+	}
+	return "", fmt.Errorf("Unable to find category mapping for adserver: '%s', publisherId: '%s'", primaryAdServer, publisherId)
+}
+
+func (fetcher *redisFetcher) get(ctx context.Context, key string) ([]byte, error) {
+	conn, err := fetcher.pool.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	return redis.Bytes(redis.DoContext(conn, ctx, "GET", key))
+}
+
+// mget pipelines one MGET for each non-empty list of keys. The values are returned in the same order
+// as the keys, with a nil value for every key which doesn't exist.
+func (fetcher *redisFetcher) mget(ctx context.Context, keyLists ...[]string) ([][][]byte, error) {
+	conn, err := fetcher.pool.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	for _, keys := range keyLists {
+		if len(keys) > 0 {
+			if err := conn.Send("MGET", redis.Args{}.AddFlat(keys)...); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := conn.Flush(); err != nil {
+		return nil, err
+	}
+
+	values := make([][][]byte, len(keyLists))
+	for i, keys := range keyLists {
+		if len(keys) > 0 {
+			if values[i], err = redis.ByteSlices(redis.ReceiveContext(conn, ctx)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return values, nil
+}
+
+func buildKeys(prefix string, ids []string) []string {
+	if len(ids) == 0 {
+		return nil
+	}
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = prefix + id
+	}
+	return keys
+}
+
+// unpackValues maps the MGET values back to their IDs, and appends a NotFoundError for each missing ID.
+func unpackValues(dataType string, ids []string, values [][]byte, errs []error) (map[string]json.RawMessage, []error) {
+	data := make(map[string]json.RawMessage, len(ids))
+	for i, id := range ids {
+		if i < len(values) && values[i] != nil {
+			data[id] = values[i]
+		} else {
+			errs = append(errs, stored_requests.NotFoundError{
+				ID:       id,
+				DataType: dataType,
+			})
+		}
+	}
+	return data, errs
+}
@@ -0,0 +1,305 @@
+package redis_fetcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/prebid/prebid-server/stored_requests"
+	"github.com/stretchr/testify/assert"
+)
+
+var testPrefixes = KeyPrefixes{
+	Requests:   "req:",
+	Imps:       "imp:",
+	Responses:  "resp:",
+	Accounts:   "acc:",
+	Categories: "cat:",
+}
+
+func TestFetchRequests(t *testing.T) {
+	data := map[string]string{
+		"req:req-1": `{"id":"req-1"}`,
+		"req:req-2": `{"id":"req-2"}`,
+		"imp:imp-1": `{"id":"imp-1"}`,
+		// Same ID with a different prefix must not be returned as a request
+		"imp:req-3": `{"id":"req-3"}`,
+	}
+
+	testCases := []struct {
+		description      string
+		requestIDs       []string
+		impIDs           []string
+		expectedRequests map[string]json.RawMessage
+		expectedImps     map[string]json.RawMessage
+		expectedErrs     []error
+		expectedCommands []string
+	}{
+		{
+			description:      "No IDs",
+			expectedCommands: nil,
+		},
+		{
+			description:      "Requests only",
+			requestIDs:       []string{"req-1", "req-2"},
+			expectedRequests: map[string]json.RawMessage{"req-1": json.RawMessage(`{"id":"req-1"}`), "req-2": json.RawMessage(`{"id":"req-2"}`)},
+			expectedImps:     map[string]json.RawMessage{},
+			expectedCommands: []string{"MGET req:req-1 req:req-2"},
+		},
+		{
+			description:      "Imps only",
+			impIDs:           []string{"imp-1"},
+			expectedRequests: map[string]json.RawMessage{},
+			expectedImps:     map[string]json.RawMessage{"imp-1": json.RawMessage(`{"id":"imp-1"}`)},
+			expectedCommands: []string{"MGET imp:imp-1"},
+		},
+		{
+			description:      "Requests and imps are pipelined, missing IDs are reported",
+			requestIDs:       []string{"req-1", "req-3"},
+			impIDs:           []string{"imp-1", "imp-2"},
+			expectedRequests: map[string]json.RawMessage{"req-1": json.RawMessage(`{"id":"req-1"}`)},
+			expectedImps:     map[string]json.RawMessage{"imp-1": json.RawMessage(`{"id":"imp-1"}`)},
+			expectedErrs: []error{
+				stored_requests.NotFoundError{ID: "req-3", DataType: "Request"},
+				stored_requests.NotFoundError{ID: "imp-2", DataType: "Imp"},
+			},
+			expectedCommands: []string{"MGET req:req-1 req:req-3", "MGET imp:imp-1 imp:imp-2"},
+		},
+	}
+
+	for _, test := range testCases {
+		pool := newFakePool(data, nil)
+		fetcher := NewFetcher(pool, testPrefixes)
+
+		requests, imps, errs := fetcher.FetchRequests(context.Background(), test.requestIDs, test.impIDs)
+		assert.Equal(t, test.expectedRequests, requests, test.description)
+		assert.Equal(t, test.expectedImps, imps, test.description)
+		assert.Equal(t, test.expectedErrs, errs, test.description)
+		assert.Equal(t, test.expectedCommands, pool.conn.sent, test.description)
+		assert.Equal(t, len(test.expectedCommands), pool.conn.received, "%s: every pipelined reply should be received", test.description)
+		assert.Equal(t, len(test.expectedCommands) > 0, pool.conn.flushed, "%s: pipelined commands should be flushed", test.description)
+	}
+}
+
+func TestFetchRequestsConnectionErrors(t *testing.T) {
+	testCases := []struct {
+		description string
+		pool        *fakePool
+	}{
+		{
+			description: "Pool error",
+			pool:        &fakePool{conn: &fakeConn{}, err: errors.New("pool exhausted")},
+		},
+		{
+			description: "Receive error",
+			pool:        newFakePool(nil, errors.New("connection reset")),
+		},
+	}
+
+	for _, test := range testCases {
+		fetcher := NewFetcher(test.pool, testPrefixes)
+		requests, imps, errs := fetcher.FetchRequests(context.Background(), []string{"req-1"}, []string{"imp-1"})
+		assert.Nil(t, requests, test.description)
+		assert.Nil(t, imps, test.description)
+		assert.Len(t, errs, 1, test.description)
+		assert.Equal(t, test.pool.err == nil, test.pool.conn.closed, "%s: a connection should be returned to the pool", test.description)
+	}
+}
+
+func TestFetchResponses(t *testing.T) {
+	pool := newFakePool(map[string]string{"resp:resp-1": `[{"bid":[],"seat":"appnexus"}]`}, nil)
+	fetcher := NewFetcher(pool, testPrefixes)
+
+	responses, errs := fetcher.FetchResponses(context.Background(), []string{"resp-1", "resp-2"})
+	assert.Equal(t, map[string]json.RawMessage{"resp-1": json.RawMessage(`[{"bid":[],"seat":"appnexus"}]`)}, responses)
+	assert.Equal(t, []error{stored_requests.NotFoundError{ID: "resp-2", DataType: "Response"}}, errs)
+
+	fetcher = NewFetcher(newFakePool(nil, errors.New("connection reset")), testPrefixes)
+	responses, errs = fetcher.FetchResponses(context.Background(), []string{"resp-1"})
+	assert.Nil(t, responses)
+	assert.Equal(t, []error{errors.New("connection reset")}, errs)
+}
+
+func TestFetchAccount(t *testing.T) {
+	pool := newFakePool(map[string]string{"acc:1001": `{"id":"1001"}`}, nil)
+	fetcher := NewFetcher(pool, testPrefixes)
+
+	account, errs := fetcher.FetchAccount(context.Background(), "1001")
+	assert.Empty(t, errs)
+	assert.JSONEq(t, `{"id":"1001"}`, string(account))
+
+	account, errs = fetcher.FetchAccount(context.Background(), "1002")
+	assert.Nil(t, account)
+	assert.Equal(t, []error{stored_requests.NotFoundError{ID: "1002", DataType: "Account"}}, errs)
+
+	fetcher = NewFetcher(newFakePool(nil, errors.New("connection reset")), testPrefixes)
+	account, errs = fetcher.FetchAccount(context.Background(), "1001")
+	assert.Nil(t, account)
+	assert.Equal(t, []error{errors.New("Error fetching account 1001 via redis: connection reset")}, errs)
+}
+
+func TestFetchCategories(t *testing.T) {
+	data := map[string]string{
+		"cat:freewheel":     `{"IAB1-1":{"id":"Arts","name":"Arts"}}`,
+		"cat:freewheel_pub": `{"IAB1-1":{"id":"PubArts","name":"Arts"}}`,
+		"cat:broken":        `{`,
+	}
+
+	testCases := []struct {
+		description      string
+		primaryAdServer  string
+		publisherId      string
+		iabCategory      string
+		expectedCategory string
+		expectedErr      string
+	}{
+		{
+			description:      "Ad server mapping",
+			primaryAdServer:  "freewheel",
+			iabCategory:      "IAB1-1",
+			expectedCategory: "Arts",
+		},
+		{
+			description:      "Publisher mapping",
+			primaryAdServer:  "freewheel",
+			publisherId:      "pub",
+			iabCategory:      "IAB1-1",
+			expectedCategory: "PubArts",
+		},
+		{
+			description:     "Unknown category",
+			primaryAdServer: "freewheel",
+			iabCategory:     "IAB2-1",
+			expectedErr:     "Unable to find category mapping for adserver: 'freewheel', publisherId: ''",
+		},
+		{
+			description:     "Unknown mapping",
+			primaryAdServer: "dfp",
+			iabCategory:     "IAB1-1",
+			expectedErr:     "Unable to find category mapping for adserver: 'dfp', publisherId: ''",
+		},
+		{
+			description:     "Malformed mapping",
+			primaryAdServer: "broken",
+			iabCategory:     "IAB1-1",
+			expectedErr:     "Unable to unmarshal categories for adserver: 'broken', publisherId: ''",
+		},
+	}
+
+	for _, test := range testCases {
+		fetcher := NewFetcher(newFakePool(data, nil), testPrefixes)
+		category, err := fetcher.FetchCategories(context.Background(), test.primaryAdServer, test.publisherId, test.iabCategory)
+		if test.expectedErr != "" {
+			assert.EqualError(t, err, test.expectedErr, test.description)
+		} else {
+			assert.NoError(t, err, test.description)
+		}
+		assert.Equal(t, test.expectedCategory, category, test.description)
+	}
+}
+
+// fakePool hands out a single fakeConn which serves GET and MGET from an in-memory map.
+type fakePool struct {
+	conn *fakeConn
+	err  error
+}
+
+func newFakePool(data map[string]string, connErr error) *fakePool {
+	return &fakePool{conn: &fakeConn{data: data, err: connErr}}
+}
+
+func (p *fakePool) GetContext(ctx context.Context) (redis.Conn, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return p.conn, nil
+}
+
+type fakeConn struct {
+	data     map[string]string
+	err      error
+	pending  [][]interface{}
+	sent     []string
+	flushed  bool
+	received int
+	closed   bool
+}
+
+func (c *fakeConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+func (c *fakeConn) Err() error {
+	return c.err
+}
+
+func (c *fakeConn) Do(commandName string, args ...interface{}) (interface{}, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.execute(commandName, args)
+}
+
+func (c *fakeConn) DoContext(ctx context.Context, commandName string, args ...interface{}) (interface{}, error) {
+	return c.Do(commandName, args...)
+}
+
+func (c *fakeConn) DoWithTimeout(timeout time.Duration, commandName string, args ...interface{}) (interface{}, error) {
+	return c.Do(commandName, args...)
+}
+
+func (c *fakeConn) Send(commandName string, args ...interface{}) error {
+	command := commandName
+	for _, arg := range args {
+		command += " " + arg.(string)
+	}
+	c.sent = append(c.sent, command)
+	c.pending = append(c.pending, append([]interface{}{commandName}, args...))
+	return nil
+}
+
+func (c *fakeConn) Flush() error {
+	c.flushed = true
+	return nil
+}
+
+func (c *fakeConn) Receive() (interface{}, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	command := c.pending[0]
+	c.pending = c.pending[1:]
+	c.received++
+	return c.execute(command[0].(string), command[1:])
+}
+
+func (c *fakeConn) ReceiveContext(ctx context.Context) (interface{}, error) {
+	return c.Receive()
+}
+
+func (c *fakeConn) ReceiveWithTimeout(timeout time.Duration) (interface{}, error) {
+	return c.Receive()
+}
+
+func (c *fakeConn) execute(commandName string, args []interface{}) (interface{}, error) {
+	switch commandName {
+	case "GET":
+		if value, ok := c.data[args[0].(string)]; ok {
+			return []byte(value), nil
+		}
+		return nil, nil
+	case "MGET":
+		values := make([]interface{}, len(args))
+		for i, key := range args {
+			if value, ok := c.data[key.(string)]; ok {
+				values[i] = []byte(value)
+			}
+		}
+		return values, nil
+	}
+	return nil, errors.New("unsupported command " + commandName)
+}
@@ -3,7 +3,9 @@ package memory
 import (
 	"context"
 	"encoding/json"
+	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/coocood/freecache"
 	"github.com/golang/glog"
@@ -16,7 +18,7 @@ import (
 // 2. The cache is too large. This will cause the least recently used items to be evicted.
 //
 // For no TTL, use ttlSeconds <= 0
-func NewCache(size int, ttl int, dataType string) stored_requests.CacheJSON {
+func NewCache(size int, ttl int, dataType string) stored_requests.InspectableCache {
 	if ttl > 0 && size <= 0 {
 		// a positive ttl indicates "LRU" cache type, while unlimited size indicates an "unbounded" cache type
 		glog.Fatalf("unbounded in-memory %s cache with TTL not allowed. Config validation should have caught this. Failing fast because something is buggy.", dataType)
@@ -40,6 +42,9 @@ func NewCache(size int, ttl int, dataType string) stored_requests.CacheJSON {
 }
 
 type cache struct {
+	// hits and misses count the IDs looked up by Get. They come first to be 64-bit aligned for the atomic operations.
+	hits     int64
+	misses   int64
 	dataType string
 	cache    mapLike
 }
@@ -51,6 +56,8 @@ func (c *cache) Get(ctx context.Context, ids []string) (data map[string]json.Raw
 			data[id] = val
 		}
 	}
+	atomic.AddInt64(&c.hits, int64(len(data)))
+	atomic.AddInt64(&c.misses, int64(len(ids)-len(data)))
 	return
 }
 
@@ -65,3 +72,29 @@ func (c *cache) Invalidate(ctx context.Context, ids []string) {
 		c.cache.Delete(id)
 	}
 }
+
+// Stats implements the stored_requests.InspectableCache interface.
+func (c *cache) Stats() stored_requests.CacheStats {
+	return stored_requests.CacheStats{
+		Entries: c.cache.Len(),
+		Hits:    atomic.LoadInt64(&c.hits),
+		Misses:  atomic.LoadInt64(&c.misses),
+	}
+}
+
+// Peek implements the stored_requests.InspectableCache interface.
+func (c *cache) Peek(id string) (json.RawMessage, bool) {
+	return c.cache.Get(id)
+}
+
+// IDs implements the stored_requests.InspectableCache interface.
+func (c *cache) IDs(prefix string) []string {
+	ids := make([]string, 0)
+	c.cache.Range(func(id string) bool {
+		if strings.HasPrefix(id, prefix) {
+			ids = append(ids, id)
+		}
+		return true
+	})
+	return ids
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/prebid/prebid-server/stored_requests"
 	"github.com/prebid/prebid-server/stored_requests/caches/cachestest"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestLRURobustness(t *testing.T) {
@@ -34,6 +35,43 @@ func TestRaceUnboundedConcurrency(t *testing.T) {
 	doRaceTest(t, cache)
 }
 
+func TestInspect(t *testing.T) {
+	testCases := []struct {
+		description string
+		cache       stored_requests.InspectableCache
+	}{
+		{
+			description: "LRU",
+			cache:       NewCache(256*1024, -1, "TestData"),
+		},
+		{
+			description: "Unbounded",
+			cache:       NewCache(0, -1, "TestData"),
+		},
+	}
+
+	for _, test := range testCases {
+		test.cache.Save(context.Background(), map[string]json.RawMessage{
+			"pub1-a": json.RawMessage(`{"a":1}`),
+			"pub1-b": json.RawMessage(`{"b":1}`),
+			"pub2-a": json.RawMessage(`{"a":2}`),
+		})
+		test.cache.Get(context.Background(), []string{"pub1-a", "pub2-a", "unknown"})
+
+		assert.Equal(t, stored_requests.CacheStats{Entries: 3, Hits: 2, Misses: 1}, test.cache.Stats(), test.description+":stats")
+		assert.ElementsMatch(t, []string{"pub1-a", "pub1-b"}, test.cache.IDs("pub1-"), test.description+":prefix")
+		assert.ElementsMatch(t, []string{"pub1-a", "pub1-b", "pub2-a"}, test.cache.IDs(""), test.description+":all")
+		assert.Empty(t, test.cache.IDs("pub3-"), test.description+":none")
+
+		entry, found := test.cache.Peek("pub1-b")
+		assert.True(t, found, test.description+":peek")
+		assert.JSONEq(t, `{"b":1}`, string(entry), test.description+":peek")
+		_, found = test.cache.Peek("unknown")
+		assert.False(t, found, test.description+":peek unknown")
+		assert.Equal(t, stored_requests.CacheStats{Entries: 3, Hits: 2, Misses: 1}, test.cache.Stats(), test.description+":peeks aren't counted")
+	}
+}
+
 func doRaceTest(t *testing.T, cache stored_requests.CacheJSON) {
 	done := make(chan struct{})
 	sets := [][]int{rand.Perm(100), rand.Perm(100), rand.Perm(100)}
@@ -16,6 +16,9 @@ type mapLike interface {
 	Get(id string) (json.RawMessage, bool)
 	Set(id string, value json.RawMessage)
 	Delete(id string)
+	Len() int
+	// Range calls f for each entry, until it returns false
+	Range(f func(id string) bool)
 }
 
 // sync.Map wrapper which implements the interface
@@ -40,6 +43,21 @@ func (m *pbsSyncMap) Delete(id string) {
 	m.Map.Delete(id)
 }
 
+func (m *pbsSyncMap) Len() int {
+	length := 0
+	m.Map.Range(func(_, _ interface{}) bool {
+		length++
+		return true
+	})
+	return length
+}
+
+func (m *pbsSyncMap) Range(f func(id string) bool) {
+	m.Map.Range(func(id, _ interface{}) bool {
+		return f(id.(string))
+	})
+}
+
 // lruCache wrapper which implements the interface
 type pbsLRUCache struct {
 	*freecache.Cache
@@ -66,3 +84,16 @@ func (m *pbsLRUCache) Set(id string, value json.RawMessage) {
 func (m *pbsLRUCache) Delete(id string) {
 	m.Cache.Del([]byte(id))
 }
+
+func (m *pbsLRUCache) Len() int {
+	return int(m.Cache.EntryCount())
+}
+
+func (m *pbsLRUCache) Range(f func(id string) bool) {
+	iterator := m.Cache.NewIterator()
+	for entry := iterator.Next(); entry != nil; entry = iterator.Next() {
+		if !f(string(entry.Key)) {
+			return
+		}
+	}
+}
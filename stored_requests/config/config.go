@@ -9,6 +9,7 @@ import (
 	"github.com/prebid/prebid-server/metrics"
 
 	"github.com/golang/glog"
+	"github.com/gomodule/redigo/redis"
 	"github.com/julienschmidt/httprouter"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/stored_requests"
@@ -16,12 +17,14 @@ import (
 	"github.com/prebid/prebid-server/stored_requests/backends/empty_fetcher"
 	"github.com/prebid/prebid-server/stored_requests/backends/file_fetcher"
 	"github.com/prebid/prebid-server/stored_requests/backends/http_fetcher"
+	"github.com/prebid/prebid-server/stored_requests/backends/redis_fetcher"
 	"github.com/prebid/prebid-server/stored_requests/caches/memory"
 	"github.com/prebid/prebid-server/stored_requests/caches/nil_cache"
 	"github.com/prebid/prebid-server/stored_requests/events"
 	apiEvents "github.com/prebid/prebid-server/stored_requests/events/api"
 	httpEvents "github.com/prebid/prebid-server/stored_requests/events/http"
 	postgresEvents "github.com/prebid/prebid-server/stored_requests/events/postgres"
+	redisEvents "github.com/prebid/prebid-server/stored_requests/events/redis"
 	"github.com/prebid/prebid-server/util/task"
 )
 
@@ -36,13 +39,14 @@ type dbConnection struct {
 //
 // 1. A Fetcher which can be used to get Stored Requests
 // 2. A function which should be called on shutdown for graceful cleanups.
+// 3. The caches used by the Fetcher. They are NilCaches if the config doesn't call for an in-memory cache.
 //
 // If any errors occur, the program will exit with an error message.
 // It probably means you have a bad config or networking issue.
 //
 // As a side-effect, it will add some endpoints to the router if the config calls for it.
 // In the future we should look for ways to simplify this so that it's not doing two things.
-func CreateStoredRequests(cfg *config.StoredRequests, metricsEngine metrics.MetricsEngine, client *http.Client, router *httprouter.Router, dbc *dbConnection) (fetcher stored_requests.AllFetcher, shutdown func()) {
+func CreateStoredRequests(cfg *config.StoredRequests, metricsEngine metrics.MetricsEngine, client *http.Client, router *httprouter.Router, dbc *dbConnection) (fetcher stored_requests.AllFetcher, shutdown func(), cache stored_requests.Cache) {
 	// Create database connection if given options for one
 	if cfg.Postgres.ConnectionInfo.Database != "" {
 		conn := cfg.Postgres.ConnectionInfo.ConnString()
@@ -65,21 +69,40 @@ func CreateStoredRequests(cfg *config.StoredRequests, metricsEngine metrics.Metr
 		}
 	}
 
-	eventProducers := newEventProducers(cfg, client, dbc.db, metricsEngine, router)
-	fetcher = newFetcher(cfg, client, dbc.db)
+	// Unlike the database, every section may use its own Redis server
+	var redisPool *redis.Pool
+	if cfg.Redis.ConnectionInfo.Address != "" {
+		glog.Infof("Connecting to Redis for Stored %s. address=%s, db=%d",
+			cfg.DataType(),
+			cfg.Redis.ConnectionInfo.Address,
+			cfg.Redis.ConnectionInfo.Database)
+		redisPool = newRedisPool(cfg.DataType(), cfg.Redis.ConnectionInfo)
+	}
+
+	eventsCtx, cancelEvents := context.WithCancel(context.Background())
+	eventProducers := newEventProducers(eventsCtx, cfg, client, dbc.db, redisPool, metricsEngine, router)
+	fetcher = newFetcher(cfg, client, dbc.db, redisPool)
 
 	var shutdown1 func()
 
+	cache = stored_requests.Cache{Requests: &nil_cache.NilCache{}, Imps: &nil_cache.NilCache{}, Accounts: &nil_cache.NilCache{}, Categories: &nil_cache.NilCache{}}
 	if cfg.InMemoryCache.Type != "" {
-		cache := newCache(cfg)
+		cache = newCache(cfg)
 		fetcher = stored_requests.WithCache(fetcher, cache, metricsEngine)
 		shutdown1 = addListeners(cache, eventProducers)
 	}
+	fetcher = stored_requests.WithTracing(fetcher, string(cfg.DataType()))
 
 	shutdown = func() {
 		if shutdown1 != nil {
 			shutdown1()
 		}
+		cancelEvents()
+		if redisPool != nil {
+			if err := redisPool.Close(); err != nil {
+				glog.Errorf("Error closing Redis connection pool: %v", err)
+			}
+		}
 		if dbc.db != nil {
 			db := dbc.db
 			dbc.db = nil
@@ -93,21 +116,23 @@ func CreateStoredRequests(cfg *config.StoredRequests, metricsEngine metrics.Metr
 	return
 }
 
-// NewStoredRequests returns five things:
+// NewStoredRequests returns eight things:
 //
 // 1. A DB connection, if one was created. This may be nil.
 // 2. A function which should be called on shutdown for graceful cleanups.
 // 3. A Fetcher which can be used to get Stored Requests for /openrtb2/auction
 // 4. A Fetcher which can be used to get Stored Requests for /openrtb2/amp
-// 5. A Fetcher which can be used to get Category Mapping data
-// 6. A Fetcher which can be used to get Stored Requests for /openrtb2/video
+// 5. A Fetcher which can be used to get Accounts
+// 6. A Fetcher which can be used to get Category Mapping data
+// 7. A Fetcher which can be used to get Stored Requests for /openrtb2/video
+// 8. The in-memory caches of the Fetchers, keyed by name, so that the admin endpoints can look into them.
 //
 // If any errors occur, the program will exit with an error message.
 // It probably means you have a bad config or networking issue.
 //
 // As a side-effect, it will add some endpoints to the router if the config calls for it.
 // In the future we should look for ways to simplify this so that it's not doing two things.
-func NewStoredRequests(cfg *config.Configuration, metricsEngine metrics.MetricsEngine, client *http.Client, router *httprouter.Router) (db *sql.DB, shutdown func(), fetcher stored_requests.Fetcher, ampFetcher stored_requests.Fetcher, accountsFetcher stored_requests.AccountFetcher, categoriesFetcher stored_requests.CategoryFetcher, videoFetcher stored_requests.Fetcher) {
+func NewStoredRequests(cfg *config.Configuration, metricsEngine metrics.MetricsEngine, client *http.Client, router *httprouter.Router) (db *sql.DB, shutdown func(), fetcher stored_requests.Fetcher, ampFetcher stored_requests.Fetcher, accountsFetcher stored_requests.AccountFetcher, categoriesFetcher stored_requests.CategoryFetcher, videoFetcher stored_requests.Fetcher, caches map[string]stored_requests.InspectableCache) {
 	// TODO: Switch this to be set in config defaults
 	//if cfg.CategoryMapping.CacheEvents.Enabled && cfg.CategoryMapping.CacheEvents.Endpoint == "" {
 	//	cfg.CategoryMapping.CacheEvents.Endpoint = "/storedrequest/categorymapping"
@@ -115,11 +140,11 @@ func NewStoredRequests(cfg *config.Configuration, metricsEngine metrics.MetricsE
 
 	var dbc dbConnection
 
-	fetcher1, shutdown1 := CreateStoredRequests(&cfg.StoredRequests, metricsEngine, client, router, &dbc)
-	fetcher2, shutdown2 := CreateStoredRequests(&cfg.StoredRequestsAMP, metricsEngine, client, router, &dbc)
-	fetcher3, shutdown3 := CreateStoredRequests(&cfg.CategoryMapping, metricsEngine, client, router, &dbc)
-	fetcher4, shutdown4 := CreateStoredRequests(&cfg.StoredVideo, metricsEngine, client, router, &dbc)
-	fetcher5, shutdown5 := CreateStoredRequests(&cfg.Accounts, metricsEngine, client, router, &dbc)
+	fetcher1, shutdown1, cache1 := CreateStoredRequests(&cfg.StoredRequests, metricsEngine, client, router, &dbc)
+	fetcher2, shutdown2, cache2 := CreateStoredRequests(&cfg.StoredRequestsAMP, metricsEngine, client, router, &dbc)
+	fetcher3, shutdown3, cache3 := CreateStoredRequests(&cfg.CategoryMapping, metricsEngine, client, router, &dbc)
+	fetcher4, shutdown4, cache4 := CreateStoredRequests(&cfg.StoredVideo, metricsEngine, client, router, &dbc)
+	fetcher5, shutdown5, cache5 := CreateStoredRequests(&cfg.Accounts, metricsEngine, client, router, &dbc)
 
 	db = dbc.db
 
@@ -129,6 +154,13 @@ func NewStoredRequests(cfg *config.Configuration, metricsEngine metrics.MetricsE
 	videoFetcher = fetcher4.(stored_requests.Fetcher)
 	accountsFetcher = fetcher5.(stored_requests.AccountFetcher)
 
+	caches = make(map[string]stored_requests.InspectableCache)
+	addNamedCaches(caches, "", cache1)
+	addNamedCaches(caches, "amp_", cache2)
+	addNamedCaches(caches, "", cache3)
+	addNamedCaches(caches, "video_", cache4)
+	addNamedCaches(caches, "", cache5)
+
 	shutdown = func() {
 		shutdown1()
 		shutdown2()
@@ -140,6 +172,24 @@ func NewStoredRequests(cfg *config.Configuration, metricsEngine metrics.MetricsE
 	return
 }
 
+// addNamedCaches adds the in-memory caches of a section to the map, named after the section and the type of data
+// they hold: "requests", "imps", "accounts" and "categories", prefixed by "amp_" or "video_" for the sections of
+// those endpoints.
+func addNamedCaches(caches map[string]stored_requests.InspectableCache, prefix string, cache stored_requests.Cache) {
+	if requests, ok := cache.Requests.(stored_requests.InspectableCache); ok {
+		caches[prefix+"requests"] = requests
+	}
+	if imps, ok := cache.Imps.(stored_requests.InspectableCache); ok {
+		caches[prefix+"imps"] = imps
+	}
+	if accounts, ok := cache.Accounts.(stored_requests.InspectableCache); ok {
+		caches[prefix+"accounts"] = accounts
+	}
+	if categories, ok := cache.Categories.(stored_requests.InspectableCache); ok {
+		caches[prefix+"categories"] = categories
+	}
+}
+
 func addListeners(cache stored_requests.Cache, eventProducers []events.EventProducer) (shutdown func()) {
 	listeners := make([]*events.EventListener, 0, len(eventProducers))
 
@@ -156,7 +206,7 @@ func addListeners(cache stored_requests.Cache, eventProducers []events.EventProd
 	}
 }
 
-func newFetcher(cfg *config.StoredRequests, client *http.Client, db *sql.DB) (fetcher stored_requests.AllFetcher) {
+func newFetcher(cfg *config.StoredRequests, client *http.Client, db *sql.DB, redisPool redis_fetcher.Pool) (fetcher stored_requests.AllFetcher) {
 	idList := make(stored_requests.MultiFetcher, 0, 3)
 
 	if cfg.Files.Enabled {
@@ -165,24 +215,36 @@ func newFetcher(cfg *config.StoredRequests, client *http.Client, db *sql.DB) (fe
 	}
 	if cfg.Postgres.FetcherQueries.QueryTemplate != "" {
 		glog.Infof("Loading Stored %s data via Postgres.\nQuery: %s", cfg.DataType(), cfg.Postgres.FetcherQueries.QueryTemplate)
-		idList = append(idList, db_fetcher.NewFetcher(db, cfg.Postgres.FetcherQueries.MakeQuery))
+		idList = append(idList, db_fetcher.NewFetcher(db, cfg.Postgres.FetcherQueries.MakeQuery, cfg.Postgres.FetcherQueries.MakeResponsesQuery))
 	}
 	if cfg.HTTP.Endpoint != "" {
 		glog.Infof("Loading Stored %s data via HTTP. endpoint=%s", cfg.DataType(), cfg.HTTP.Endpoint)
 		idList = append(idList, http_fetcher.NewFetcher(client, cfg.HTTP.Endpoint))
 	}
+	if cfg.Redis.ConnectionInfo.Address != "" {
+		glog.Infof("Loading Stored %s data via Redis. address=%s", cfg.DataType(), cfg.Redis.ConnectionInfo.Address)
+		idList = append(idList, redis_fetcher.NewFetcher(redisPool, redis_fetcher.KeyPrefixes{
+			Requests:   cfg.Redis.KeyPrefixes.Requests,
+			Imps:       cfg.Redis.KeyPrefixes.Imps,
+			Responses:  cfg.Redis.KeyPrefixes.Responses,
+			Accounts:   cfg.Redis.KeyPrefixes.Accounts,
+			Categories: cfg.Redis.KeyPrefixes.Categories,
+		}))
+	}
 
 	fetcher = consolidate(cfg.DataType(), idList)
 	return
 }
 
 func newCache(cfg *config.StoredRequests) stored_requests.Cache {
-	cache := stored_requests.Cache{&nil_cache.NilCache{}, &nil_cache.NilCache{}, &nil_cache.NilCache{}}
+	cache := stored_requests.Cache{Requests: &nil_cache.NilCache{}, Imps: &nil_cache.NilCache{}, Accounts: &nil_cache.NilCache{}, Categories: &nil_cache.NilCache{}}
 	switch {
 	case cfg.InMemoryCache.Type == "none":
 		glog.Warningf("No %s cache configured. The %s Fetcher backend will be used for all data requests", cfg.DataType(), cfg.DataType())
 	case cfg.DataType() == config.AccountDataType:
 		cache.Accounts = memory.NewCache(cfg.InMemoryCache.Size, cfg.InMemoryCache.TTL, "Accounts")
+	case cfg.DataType() == config.CategoryDataType:
+		cache.Categories = memory.NewCache(cfg.InMemoryCache.Size, cfg.InMemoryCache.TTL, "Categories")
 	default:
 		cache.Requests = memory.NewCache(cfg.InMemoryCache.RequestCacheSize, cfg.InMemoryCache.TTL, "Requests")
 		cache.Imps = memory.NewCache(cfg.InMemoryCache.ImpCacheSize, cfg.InMemoryCache.TTL, "Imps")
@@ -190,7 +252,7 @@ func newCache(cfg *config.StoredRequests) stored_requests.Cache {
 	return cache
 }
 
-func newEventProducers(cfg *config.StoredRequests, client *http.Client, db *sql.DB, metricsEngine metrics.MetricsEngine, router *httprouter.Router) (eventProducers []events.EventProducer) {
+func newEventProducers(ctx context.Context, cfg *config.StoredRequests, client *http.Client, db *sql.DB, redisPool redisEvents.Pool, metricsEngine metrics.MetricsEngine, router *httprouter.Router) (eventProducers []events.EventProducer) {
 	if cfg.CacheEvents.Enabled {
 		eventProducers = append(eventProducers, newEventsAPI(router, cfg.CacheEvents.Endpoint))
 	}
@@ -213,6 +275,24 @@ func newEventProducers(cfg *config.StoredRequests, client *http.Client, db *sql.
 		pgEventTickerTask.Start()
 		eventProducers = append(eventProducers, pgEventProducer)
 	}
+	if cfg.Redis.Events.Enabled && cfg.Redis.ConnectionInfo.Address != "" {
+		redisEventCfg := redisEvents.RedisEventProducerConfig{
+			Pool:         redisPool,
+			RequestType:  cfg.DataType(),
+			Database:     cfg.Redis.ConnectionInfo.Database,
+			Channel:      cfg.Redis.Events.Channel,
+			FetchTimeout: cfg.Redis.ConnectionInfo.TimeoutDuration(),
+		}
+		if cfg.DataType() == config.AccountDataType {
+			redisEventCfg.AccountKeyPrefix = cfg.Redis.KeyPrefixes.Accounts
+		} else {
+			redisEventCfg.RequestKeyPrefix = cfg.Redis.KeyPrefixes.Requests
+			redisEventCfg.ImpKeyPrefix = cfg.Redis.KeyPrefixes.Imps
+		}
+		redisEventProducer := redisEvents.NewRedisEventProducer(redisEventCfg)
+		go redisEventProducer.Run(ctx)
+		eventProducers = append(eventProducers, redisEventProducer)
+	}
 	return
 }
 
@@ -252,6 +332,29 @@ func newPostgresDB(dataType config.DataType, cfg config.PostgresConnection) *sql
 	return db
 }
 
+func newRedisPool(dataType config.DataType, cfg config.RedisConnection) *redis.Pool {
+	timeout := cfg.TimeoutDuration()
+	pool := &redis.Pool{
+		MaxIdle: cfg.MaxIdle,
+		DialContext: func(ctx context.Context) (redis.Conn, error) {
+			return redis.DialContext(ctx, "tcp", cfg.Address,
+				redis.DialPassword(cfg.Password),
+				redis.DialDatabase(cfg.Database),
+				redis.DialConnectTimeout(timeout),
+				redis.DialReadTimeout(timeout),
+				redis.DialWriteTimeout(timeout))
+		},
+	}
+
+	conn := pool.Get()
+	defer conn.Close()
+	if _, err := conn.Do("PING"); err != nil {
+		glog.Fatalf("Failed to ping %s redis: %v", dataType, err)
+	}
+
+	return pool
+}
+
 // consolidate returns a single Fetcher from an array of fetchers of any size.
 func consolidate(dataType config.DataType, fetchers []stored_requests.AllFetcher) stored_requests.AllFetcher {
 	if len(fetchers) == 0 {
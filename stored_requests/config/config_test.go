@@ -41,7 +41,7 @@ func isMemoryCacheType(cache stored_requests.CacheJSON) bool {
 }
 
 func TestNewEmptyFetcher(t *testing.T) {
-	fetcher := newFetcher(&config.StoredRequests{}, nil, nil)
+	fetcher := newFetcher(&config.StoredRequests{}, nil, nil, nil)
 	if fetcher == nil {
 		t.Errorf("The fetcher should be non-nil, even with an empty config.")
 	}
@@ -55,7 +55,7 @@ func TestNewHTTPFetcher(t *testing.T) {
 		HTTP: config.HTTPFetcherConfig{
 			Endpoint: "stored-requests.prebid.com",
 		},
-	}, nil, nil)
+	}, nil, nil, nil)
 	if httpFetcher, ok := fetcher.(*http_fetcher.HttpFetcher); ok {
 		if httpFetcher.Endpoint != "stored-requests.prebid.com?" {
 			t.Errorf("The HTTP fetcher is using the wrong endpoint. Expected %s, got %s", "stored-requests.prebid.com?", httpFetcher.Endpoint)
@@ -81,7 +81,7 @@ func TestNewHTTPEvents(t *testing.T) {
 
 	metricsMock := &metrics.MetricsEngineMock{}
 
-	evProducers := newEventProducers(cfg, server1.Client(), nil, metricsMock, nil)
+	evProducers := newEventProducers(context.Background(), cfg, server1.Client(), nil, nil, metricsMock, nil)
 	assertSliceLength(t, evProducers, 1)
 	assertHttpWithURL(t, evProducers[0], server1.URL)
 }
@@ -118,6 +118,51 @@ func TestNewInMemoryAccountCache(t *testing.T) {
 	assert.True(t, isEmptyCacheType(cache.Imps), "The newCache method should return an empty Imp cache for Accounts config")
 }
 
+func TestNewInMemoryCategoryCache(t *testing.T) {
+	cache := newCache(typedConfig(config.CategoryDataType, &config.StoredRequests{
+		InMemoryCache: config.InMemoryCache{
+			TTL:  60,
+			Size: 100,
+		},
+	}))
+	assert.True(t, isMemoryCacheType(cache.Categories), "The newCache method should return an in-memory Category cache for CategoryMapping config")
+	assert.True(t, isEmptyCacheType(cache.Requests), "The newCache method should return an empty Request cache for CategoryMapping config")
+	assert.True(t, isEmptyCacheType(cache.Imps), "The newCache method should return an empty Imp cache for CategoryMapping config")
+	assert.True(t, isEmptyCacheType(cache.Accounts), "The newCache method should return an empty Account cache for CategoryMapping config")
+}
+
+func TestAddNamedCaches(t *testing.T) {
+	requestsCache := newCache(typedConfig(config.RequestDataType, &config.StoredRequests{
+		InMemoryCache: config.InMemoryCache{Type: "unbounded"},
+	}))
+	ampCache := newCache(typedConfig(config.AMPRequestDataType, &config.StoredRequests{
+		InMemoryCache: config.InMemoryCache{Type: "unbounded"},
+	}))
+	accountsCache := newCache(typedConfig(config.AccountDataType, &config.StoredRequests{
+		InMemoryCache: config.InMemoryCache{Type: "unbounded"},
+	}))
+	categoriesCache := newCache(typedConfig(config.CategoryDataType, &config.StoredRequests{
+		InMemoryCache: config.InMemoryCache{Type: "unbounded"},
+	}))
+	videoCache := newCache(&config.StoredRequests{InMemoryCache: config.InMemoryCache{Type: "none"}})
+
+	caches := make(map[string]stored_requests.InspectableCache)
+	addNamedCaches(caches, "", requestsCache)
+	addNamedCaches(caches, "amp_", ampCache)
+	addNamedCaches(caches, "", categoriesCache)
+	addNamedCaches(caches, "video_", videoCache)
+	addNamedCaches(caches, "", accountsCache)
+
+	assert.Equal(t, map[string]stored_requests.InspectableCache{
+		"requests":     requestsCache.Requests.(stored_requests.InspectableCache),
+		"imps":         requestsCache.Imps.(stored_requests.InspectableCache),
+		"amp_requests": ampCache.Requests.(stored_requests.InspectableCache),
+		"amp_imps":     ampCache.Imps.(stored_requests.InspectableCache),
+		"accounts":     accountsCache.Accounts.(stored_requests.InspectableCache),
+		"categories":   categoriesCache.Categories.(stored_requests.InspectableCache),
+	}, caches)
+}
+
 func TestNewPostgresEventProducers(t *testing.T) {
 	metricsMock := &metrics.MetricsEngineMock{}
 	metricsMock.Mock.On("RecordStoredDataFetchTime", mock.Anything, mock.Anything).Return()
@@ -143,7 +188,7 @@ func TestNewPostgresEventProducers(t *testing.T) {
 	}
 	mock.ExpectQuery("^" + regexp.QuoteMeta(cfg.Postgres.CacheInitialization.Query) + "$").WillReturnError(errors.New("Query failed"))
 
-	evProducers := newEventProducers(cfg, client, db, metricsMock, nil)
+	evProducers := newEventProducers(context.Background(), cfg, client, db, nil, metricsMock, nil)
 	assertProducerLength(t, evProducers, 1)
 
 	assertExpectationsMet(t, mock)
@@ -0,0 +1,335 @@
+package redis
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/buger/jsonparser"
+	"github.com/golang/glog"
+	"github.com/gomodule/redigo/redis"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/stored_requests/events"
+)
+
+const (
+	// healthCheckInterval is how often the subscription connection is pinged. If nothing (not even the pong)
+	// is received for twice as long, the connection is considered dead and is re-established.
+	healthCheckInterval = 30 * time.Second
+	minRetryDelay       = 1 * time.Second
+	maxRetryDelay       = 30 * time.Second
+)
+
+// Pool is the subset of *redis.Pool used by the event producer.
+type Pool interface {
+	GetContext(ctx context.Context) (redis.Conn, error)
+}
+
+type RedisEventProducerConfig struct {
+	Pool        Pool
+	RequestType config.DataType
+	// Database is the Redis database number the stored data lives in. It is needed to subscribe to keyspace notifications.
+	Database int
+	// Channel is a pub/sub channel to listen to for updates. If empty, keyspace notifications are used instead.
+	Channel string
+	// The key prefixes of each type of stored data. Keyspace notifications are only subscribed for non-empty prefixes.
+	RequestKeyPrefix string
+	ImpKeyPrefix     string
+	AccountKeyPrefix string
+	// FetchTimeout bounds the GET of a key after a keyspace notification reported it was written.
+	FetchTimeout time.Duration
+}
+
+// RedisEventProducer produces cache update and invalidation events from Redis.
+//
+// When configured with a Channel, it expects every message published there to be JSON like:
+//
+// {
+//   "requests": {
+//     "request1": { ... stored request data ... },
+//     "request2": { "deleted": true }
+//   },
+//   "imps": {
+//     "imp1": { ... stored data for imp1 ... }
+//   },
+//   "accounts": {
+//     "acc1": { ... config data for acc1 ... }
+//   }
+// }
+//
+// Otherwise it subscribes to the keyspace notifications of the configured key prefixes. The Redis server must
+// have them enabled for generic and string commands, as well as expiry and eviction events
+// (e.g. notify-keyspace-events "Kg$xe"). A written key is read back and saved, and a deleted, expired or evicted
+// key is invalidated.
+//
+// Updates made while the subscription is being re-established are not seen. The in-memory cache TTL bounds how
+// long such data may stay stale.
+type RedisEventProducer struct {
+	cfg           RedisEventProducerConfig
+	invalidations chan events.Invalidation
+	saves         chan events.Save
+	// dataTypes maps each subscribed keyspace pattern to the key prefix and type of data it notifies about.
+	dataTypes map[string]keyspaceDataType
+}
+
+type keyspaceDataType struct {
+	prefix   string
+	dataType string
+}
+
+func NewRedisEventProducer(cfg RedisEventProducerConfig) *RedisEventProducer {
+	if cfg.Pool == nil {
+		glog.Fatalf("The Redis Stored %s Loader needs a connection pool to work.", cfg.RequestType)
+	}
+
+	e := &RedisEventProducer{
+		cfg:           cfg,
+		saves:         make(chan events.Save, 1),
+		invalidations: make(chan events.Invalidation, 1),
+		dataTypes:     make(map[string]keyspaceDataType),
+	}
+	if cfg.Channel == "" {
+		e.addKeyspacePattern(cfg.RequestKeyPrefix, "requests")
+		e.addKeyspacePattern(cfg.ImpKeyPrefix, "imps")
+		e.addKeyspacePattern(cfg.AccountKeyPrefix, "accounts")
+	}
+	return e
+}
+
+func (e *RedisEventProducer) addKeyspacePattern(prefix string, dataType string) {
+	if prefix != "" {
+		pattern := fmt.Sprintf("__keyspace@%d__:%s*", e.cfg.Database, escapePattern(prefix))
+		e.dataTypes[pattern] = keyspaceDataType{prefix: prefix, dataType: dataType}
+	}
+}
+
+func (e *RedisEventProducer) Saves() <-chan events.Save {
+	return e.saves
+}
+
+func (e *RedisEventProducer) Invalidations() <-chan events.Invalidation {
+	return e.invalidations
+}
+
+// Run subscribes to the updates and produces events until the context is cancelled.
+// Lost connections are re-established with an increasing delay.
+func (e *RedisEventProducer) Run(ctx context.Context) {
+	retryDelay := minRetryDelay
+	for {
+		subscribed, err := e.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if subscribed {
+			retryDelay = minRetryDelay
+		}
+		glog.Errorf("Lost the Redis subscription for Stored %s updates, retrying in %v: %v", e.cfg.RequestType, retryDelay, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retryDelay):
+		}
+		if retryDelay *= 2; retryDelay > maxRetryDelay {
+			retryDelay = maxRetryDelay
+		}
+	}
+}
+
+// listen subscribes on a new connection and handles its messages until an error occurs. It returns
+// whether the subscription was confirmed before that happened.
+func (e *RedisEventProducer) listen(ctx context.Context) (subscribed bool, err error) {
+	conn, err := e.cfg.Pool.GetContext(ctx)
+	if err != nil {
+		return false, err
+	}
+	psc := redis.PubSubConn{Conn: conn}
+	defer psc.Close()
+
+	if e.cfg.Channel != "" {
+		err = psc.Subscribe(e.cfg.Channel)
+	} else {
+		patterns := make([]interface{}, 0, len(e.dataTypes))
+		for pattern := range e.dataTypes {
+			patterns = append(patterns, pattern)
+		}
+		err = psc.PSubscribe(patterns...)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(healthCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := psc.Ping(""); err != nil {
+					return
+				}
+			case <-ctx.Done():
+				// Unblocks the pending receive below
+				psc.Close()
+				return
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	for {
+		switch msg := psc.ReceiveWithTimeout(2 * healthCheckInterval).(type) {
+		case redis.Message:
+			e.handleMessage(ctx, msg)
+		case redis.Subscription:
+			if msg.Count > 0 {
+				subscribed = true
+				glog.Infof("Listening to Redis %s %s for Stored %s updates", msg.Kind, msg.Channel, e.cfg.RequestType)
+			}
+		case error:
+			return subscribed, msg
+		}
+	}
+}
+
+func (e *RedisEventProducer) handleMessage(ctx context.Context, msg redis.Message) {
+	if msg.Pattern == "" {
+		e.handleChannelMessage(ctx, msg.Data)
+		return
+	}
+
+	dataType, ok := e.dataTypes[msg.Pattern]
+	if !ok {
+		return
+	}
+	key := msg.Channel[strings.Index(msg.Channel, ":")+1:]
+	id := strings.TrimPrefix(key, dataType.prefix)
+
+	switch string(msg.Data) {
+	case "set", "rename_to":
+		data, err := e.fetch(ctx, key)
+		if err == redis.ErrNil {
+			// Deleted again before we could read it. A separate notification takes care of it.
+			return
+		}
+		if err != nil {
+			glog.Errorf("Failed to read Redis key %s after an update for Stored %s: %v", key, e.cfg.RequestType, err)
+			return
+		}
+		e.sendSave(ctx, newSave(dataType.dataType, map[string]json.RawMessage{id: data}))
+	case "del", "expired", "evicted", "rename_from":
+		e.sendInvalidation(ctx, newInvalidation(dataType.dataType, []string{id}))
+	}
+}
+
+func (e *RedisEventProducer) handleChannelMessage(ctx context.Context, data []byte) {
+	var update updateContract
+	if err := json.Unmarshal(data, &update); err != nil {
+		glog.Errorf("Failed to unmarshal a Stored %s update from Redis channel %s: %v", e.cfg.RequestType, e.cfg.Channel, err)
+		return
+	}
+
+	invalidation := events.Invalidation{
+		Requests: extractInvalidations(update.Requests),
+		Imps:     extractInvalidations(update.Imps),
+		Accounts: extractInvalidations(update.Accounts),
+	}
+	if len(update.Requests) > 0 || len(update.Imps) > 0 || len(update.Accounts) > 0 {
+		e.sendSave(ctx, events.Save{
+			Requests: update.Requests,
+			Imps:     update.Imps,
+			Accounts: update.Accounts,
+		})
+	}
+	if len(invalidation.Requests) > 0 || len(invalidation.Imps) > 0 || len(invalidation.Accounts) > 0 {
+		e.sendInvalidation(ctx, invalidation)
+	}
+}
+
+func (e *RedisEventProducer) fetch(ctx context.Context, key string) ([]byte, error) {
+	if e.cfg.FetchTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.cfg.FetchTimeout)
+		defer cancel()
+	}
+
+	conn, err := e.cfg.Pool.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	return redis.Bytes(redis.DoContext(conn, ctx, "GET", key))
+}
+
+func (e *RedisEventProducer) sendSave(ctx context.Context, save events.Save) {
+	select {
+	case e.saves <- save:
+	case <-ctx.Done():
+	}
+}
+
+func (e *RedisEventProducer) sendInvalidation(ctx context.Context, invalidation events.Invalidation) {
+	select {
+	case e.invalidations <- invalidation:
+	case <-ctx.Done():
+	}
+}
+
+func newSave(dataType string, data map[string]json.RawMessage) events.Save {
+	switch dataType {
+	case "requests":
+		return events.Save{Requests: data}
+	case "imps":
+		return events.Save{Imps: data}
+	default:
+		return events.Save{Accounts: data}
+	}
+}
+
+func newInvalidation(dataType string, ids []string) events.Invalidation {
+	switch dataType {
+	case "requests":
+		return events.Invalidation{Requests: ids}
+	case "imps":
+		return events.Invalidation{Imps: ids}
+	default:
+		return events.Invalidation{Accounts: ids}
+	}
+}
+
+func extractInvalidations(changes map[string]json.RawMessage) []string {
+	deletedIDs := make([]string, 0, len(changes))
+	for id, msg := range changes {
+		if value, _, _, err := jsonparser.Get(msg, "deleted"); err == nil && bytes.Equal(value, []byte("true")) {
+			delete(changes, id)
+			deletedIDs = append(deletedIDs, id)
+		}
+	}
+	return deletedIDs
+}
+
+// escapePattern escapes the glob-style special characters of a key prefix used in a PSUBSCRIBE pattern.
+func escapePattern(prefix string) string {
+	var escaped strings.Builder
+	for _, r := range prefix {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			escaped.WriteRune('\\')
+		}
+		escaped.WriteRune(r)
+	}
+	return escaped.String()
+}
+
+type updateContract struct {
+	Requests map[string]json.RawMessage `json:"requests"`
+	Imps     map[string]json.RawMessage `json:"imps"`
+	Accounts map[string]json.RawMessage `json:"accounts"`
+}
@@ -0,0 +1,249 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/stored_requests/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyspaceNotifications(t *testing.T) {
+	pool := newFakePool(map[string]string{"req:req-1": `{"id":"req-1"}`})
+	producer := NewRedisEventProducer(RedisEventProducerConfig{
+		Pool:             pool,
+		RequestType:      config.RequestDataType,
+		Database:         2,
+		RequestKeyPrefix: "req:",
+		ImpKeyPrefix:     "imp:",
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go producer.Run(ctx)
+
+	pool.replies <- subscription("psubscribe", "__keyspace@2__:req:*", 1)
+	pool.replies <- subscription("psubscribe", "__keyspace@2__:imp:*", 2)
+	pool.replies <- keyspaceMessage("__keyspace@2__:req:*", "__keyspace@2__:req:req-1", "set")
+	assert.Equal(t, events.Save{Requests: map[string]json.RawMessage{"req-1": json.RawMessage(`{"id":"req-1"}`)}}, expectSave(t, producer))
+
+	// Events which don't change the value are ignored
+	pool.replies <- keyspaceMessage("__keyspace@2__:imp:*", "__keyspace@2__:imp:imp-1", "expire")
+	pool.replies <- keyspaceMessage("__keyspace@2__:imp:*", "__keyspace@2__:imp:imp-1", "del")
+	assert.Equal(t, events.Invalidation{Imps: []string{"imp-1"}}, expectInvalidation(t, producer))
+
+	pool.replies <- keyspaceMessage("__keyspace@2__:req:*", "__keyspace@2__:req:req-2", "expired")
+	assert.Equal(t, events.Invalidation{Requests: []string{"req-2"}}, expectInvalidation(t, producer))
+
+	assert.Equal(t, []string{"PSUBSCRIBE __keyspace@2__:imp:* __keyspace@2__:req:*"}, pool.subscriptions())
+}
+
+func TestChannelMessages(t *testing.T) {
+	pool := newFakePool(nil)
+	producer := NewRedisEventProducer(RedisEventProducerConfig{
+		Pool:             pool,
+		RequestType:      config.AccountDataType,
+		Channel:          "stored-data",
+		AccountKeyPrefix: "acc:",
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go producer.Run(ctx)
+
+	pool.replies <- subscription("subscribe", "stored-data", 1)
+	pool.replies <- channelMessage("stored-data", `malformed`)
+	pool.replies <- channelMessage("stored-data", `{"accounts":{"acc-1":{"id":"acc-1"},"acc-2":{"deleted":true}}}`)
+	assert.Equal(t, events.Save{Accounts: map[string]json.RawMessage{"acc-1": json.RawMessage(`{"id":"acc-1"}`)}}, expectSave(t, producer))
+	assert.Equal(t, events.Invalidation{Requests: []string{}, Imps: []string{}, Accounts: []string{"acc-2"}}, expectInvalidation(t, producer))
+
+	assert.Equal(t, []string{"SUBSCRIBE stored-data"}, pool.subscriptions())
+}
+
+func TestResubscribe(t *testing.T) {
+	pool := newFakePool(nil)
+	producer := NewRedisEventProducer(RedisEventProducerConfig{
+		Pool:        pool,
+		RequestType: config.RequestDataType,
+		Channel:     "stored-data",
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go producer.Run(ctx)
+
+	pool.replies <- errors.New("connection reset")
+	pool.replies <- channelMessage("stored-data", `{"requests":{"req-1":{"deleted":true}}}`)
+	assert.Equal(t, []string{"req-1"}, expectInvalidation(t, producer).Requests)
+
+	assert.Equal(t, []string{"SUBSCRIBE stored-data", "SUBSCRIBE stored-data"}, pool.subscriptions())
+}
+
+func TestRunStopsWhenCancelled(t *testing.T) {
+	pool := newFakePool(nil)
+	producer := NewRedisEventProducer(RedisEventProducerConfig{
+		Pool:        pool,
+		RequestType: config.RequestDataType,
+		Channel:     "stored-data",
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		producer.Run(ctx)
+		close(stopped)
+	}()
+
+	pool.replies <- subscription("subscribe", "stored-data", 1)
+	cancel()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Run should return once the context is cancelled")
+	}
+}
+
+func TestEscapePattern(t *testing.T) {
+	assert.Equal(t, "stored:request:", escapePattern("stored:request:"))
+	assert.Equal(t, `a\*b\?c\[d\]e\\f`, escapePattern(`a*b?c[d]e\f`))
+}
+
+func expectSave(t *testing.T, producer *RedisEventProducer) events.Save {
+	t.Helper()
+	select {
+	case save := <-producer.Saves():
+		return save
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected a save event")
+	}
+	return events.Save{}
+}
+
+func expectInvalidation(t *testing.T, producer *RedisEventProducer) events.Invalidation {
+	t.Helper()
+	select {
+	case invalidation := <-producer.Invalidations():
+		return invalidation
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected an invalidation event")
+	}
+	return events.Invalidation{}
+}
+
+func subscription(kind string, channel string, count int64) interface{} {
+	return []interface{}{[]byte(kind), []byte(channel), count}
+}
+
+func keyspaceMessage(pattern string, channel string, event string) interface{} {
+	return []interface{}{[]byte("pmessage"), []byte(pattern), []byte(channel), []byte(event)}
+}
+
+func channelMessage(channel string, data string) interface{} {
+	return []interface{}{[]byte("message"), []byte(channel), []byte(data)}
+}
+
+// fakePool hands out connections which serve GET from an in-memory map, and receive
+// the pub/sub replies pushed on the shared replies channel.
+type fakePool struct {
+	data    map[string]string
+	replies chan interface{}
+
+	mu   sync.Mutex
+	sent []string
+}
+
+func newFakePool(data map[string]string) *fakePool {
+	return &fakePool{
+		data:    data,
+		replies: make(chan interface{}),
+	}
+}
+
+func (p *fakePool) GetContext(ctx context.Context) (redis.Conn, error) {
+	return &fakeConn{pool: p, closed: make(chan struct{})}, nil
+}
+
+func (p *fakePool) subscriptions() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var subscriptions []string
+	for _, command := range p.sent {
+		if strings.Contains(command, "SUBSCRIBE") {
+			subscriptions = append(subscriptions, command)
+		}
+	}
+	return subscriptions
+}
+
+type fakeConn struct {
+	pool      *fakePool
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func (c *fakeConn) Close() error {
+	c.closeOnce.Do(func() { close(c.closed) })
+	return nil
+}
+
+func (c *fakeConn) Err() error {
+	return nil
+}
+
+func (c *fakeConn) Do(commandName string, args ...interface{}) (interface{}, error) {
+	if commandName == "GET" {
+		if value, ok := c.pool.data[args[0].(string)]; ok {
+			return []byte(value), nil
+		}
+	}
+	return nil, nil
+}
+
+func (c *fakeConn) DoContext(ctx context.Context, commandName string, args ...interface{}) (interface{}, error) {
+	return c.Do(commandName, args...)
+}
+
+func (c *fakeConn) Send(commandName string, args ...interface{}) error {
+	patterns := make([]string, 0, len(args))
+	for _, arg := range args {
+		patterns = append(patterns, arg.(string))
+	}
+	sort.Strings(patterns)
+
+	c.pool.mu.Lock()
+	defer c.pool.mu.Unlock()
+	c.pool.sent = append(c.pool.sent, strings.Join(append([]string{commandName}, patterns...), " "))
+	return nil
+}
+
+func (c *fakeConn) Flush() error {
+	return nil
+}
+
+func (c *fakeConn) Receive() (interface{}, error) {
+	select {
+	case reply := <-c.pool.replies:
+		if err, ok := reply.(error); ok {
+			return nil, err
+		}
+		return reply, nil
+	case <-c.closed:
+		return nil, errors.New("use of closed connection")
+	}
+}
+
+func (c *fakeConn) ReceiveWithTimeout(timeout time.Duration) (interface{}, error) {
+	return c.Receive()
+}
+
+func (c *fakeConn) DoWithTimeout(timeout time.Duration, commandName string, args ...interface{}) (interface{}, error) {
+	return c.Do(commandName, args...)
+}
+
+func (c *fakeConn) ReceiveContext(ctx context.Context) (interface{}, error) {
+	return c.Receive()
+}
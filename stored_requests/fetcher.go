@@ -23,6 +23,12 @@ type Fetcher interface {
 	//
 	// The returned objects can only be read from. They may not be written to.
 	FetchRequests(ctx context.Context, requestIDs []string, impIDs []string) (requestData map[string]json.RawMessage, impData map[string]json.RawMessage, errs []error)
+
+	// FetchResponses fetches the stored auction and bid responses for the given IDs.
+	//
+	// The returned map will have a key for every ID in the list, unless errors exist.
+	// It can only be read from. It may not be written to.
+	FetchResponses(ctx context.Context, ids []string) (data map[string]json.RawMessage, errs []error)
 }
 
 type AccountFetcher interface {
@@ -66,6 +72,8 @@ type Cache struct {
 	Requests CacheJSON
 	Imps     CacheJSON
 	Accounts CacheJSON
+	// Categories holds the IDs of the ad server categories as JSON strings, keyed by CategoryCacheKey
+	Categories CacheJSON
 }
 type CacheJSON interface {
 	// Get works much like Fetcher.FetchRequests, with a few exceptions:
@@ -88,6 +96,35 @@ type CacheJSON interface {
 	Save(ctx context.Context, data map[string]json.RawMessage)
 }
 
+// InspectableCache is a CacheJSON whose content can be listed, so that the hosts can look into it.
+type InspectableCache interface {
+	CacheJSON
+
+	// Stats returns the number of entries in the cache, and the outcome of the lookups made through Get.
+	Stats() CacheStats
+
+	// IDs returns the IDs of the entries in the cache which start with the prefix.
+	IDs(prefix string) []string
+
+	// Peek returns the entry cached for the ID, if any. Unlike Get, it isn't counted in the Stats.
+	Peek(id string) (json.RawMessage, bool)
+}
+
+// CacheStats describes the content of a cache, and how often it had the data looked up.
+type CacheStats struct {
+	Entries int
+	Hits    int64
+	Misses  int64
+}
+
+// HitRatio returns the share of the lookups which found their data in the cache, or 0 if there weren't any.
+func (s CacheStats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
 // ComposedCache creates an interface to treat a slice of caches as a single cache
 type ComposedCache []CacheJSON
 
@@ -191,6 +228,12 @@ func (f *fetcherWithCache) FetchRequests(ctx context.Context, requestIDs []strin
 	return
 }
 
+// FetchResponses goes straight to the original Fetcher. The stored responses are meant for testing, so they
+// aren't kept in the in-memory caches.
+func (f *fetcherWithCache) FetchResponses(ctx context.Context, ids []string) (data map[string]json.RawMessage, errs []error) {
+	return f.fetcher.FetchResponses(ctx, ids)
+}
+
 func (f *fetcherWithCache) FetchAccount(ctx context.Context, accountID string) (account json.RawMessage, errs []error) {
 	accountData := f.cache.Accounts.Get(ctx, []string{accountID})
 	// TODO: add metrics
@@ -208,7 +251,27 @@ func (f *fetcherWithCache) FetchAccount(ctx context.Context, accountID string) (
 }
 
 func (f *fetcherWithCache) FetchCategories(ctx context.Context, primaryAdServer, publisherId, iabCategory string) (string, error) {
-	return "", nil
+	key := CategoryCacheKey(primaryAdServer, publisherId, iabCategory)
+	if cached, ok := f.cache.Categories.Get(ctx, []string{key})[key]; ok {
+		var category string
+		if err := json.Unmarshal(cached, &category); err == nil {
+			return category, nil
+		}
+	}
+
+	category, err := f.fetcher.FetchCategories(ctx, primaryAdServer, publisherId, iabCategory)
+	if err == nil {
+		if categoryJSON, err := json.Marshal(category); err == nil {
+			f.cache.Categories.Save(ctx, map[string]json.RawMessage{key: categoryJSON})
+		}
+	}
+	return category, err
+}
+
+// CategoryCacheKey returns the key of the category mapped to the IAB category for the ad server and publisher.
+// The keys of a publisher share the "{primaryAdServer}/{publisherId}/" prefix, so that they can be invalidated together.
+func CategoryCacheKey(primaryAdServer, publisherId, iabCategory string) string {
+	return primaryAdServer + "/" + publisherId + "/" + iabCategory
 }
 
 func findLeftovers(ids []string, data map[string]json.RawMessage) (leftovers []string) {
@@ -18,7 +18,7 @@ func setupFetcherWithCacheDeps() (*mockCache, *mockCache, *mockFetcher, AllFetch
 	impCache := &mockCache{}
 	metricsEngine := &metrics.MetricsEngineMock{}
 	fetcher := &mockFetcher{}
-	afetcherWithCache := WithCache(fetcher, Cache{reqCache, impCache, &nil_cache.NilCache{}}, metricsEngine)
+	afetcherWithCache := WithCache(fetcher, Cache{reqCache, impCache, &nil_cache.NilCache{}, &nil_cache.NilCache{}}, metricsEngine)
 
 	return reqCache, impCache, fetcher, afetcherWithCache, metricsEngine
 }
@@ -163,7 +163,7 @@ func setupAccountFetcherWithCacheDeps() (*mockCache, *mockFetcher, AllFetcher, *
 	accCache := &mockCache{}
 	metricsEngine := &metrics.MetricsEngineMock{}
 	fetcher := &mockFetcher{}
-	afetcherWithCache := WithCache(fetcher, Cache{&nil_cache.NilCache{}, &nil_cache.NilCache{}, accCache}, metricsEngine)
+	afetcherWithCache := WithCache(fetcher, Cache{&nil_cache.NilCache{}, &nil_cache.NilCache{}, accCache, &nil_cache.NilCache{}}, metricsEngine)
 
 	return accCache, fetcher, afetcherWithCache, metricsEngine
 }
@@ -212,6 +212,63 @@ func TestAccountCacheMiss(t *testing.T) {
 	assert.Len(t, errs, 0, "FetchAccount shouldn't return any errors")
 }
 
+func setupCategoryFetcherWithCacheDeps() (*mockCache, *mockFetcher, AllFetcher) {
+	catCache := &mockCache{}
+	fetcher := &mockFetcher{}
+	afetcherWithCache := WithCache(fetcher, Cache{&nil_cache.NilCache{}, &nil_cache.NilCache{}, &nil_cache.NilCache{}, catCache}, &metrics.MetricsEngineMock{})
+
+	return catCache, fetcher, afetcherWithCache
+}
+
+func TestCategoryCacheHit(t *testing.T) {
+	catCache, fetcher, aFetcherWithCache := setupCategoryFetcherWithCacheDeps()
+	ctx := context.Background()
+
+	catCache.On("Get", ctx, []string{"freewheel/pub1/IAB1-1"}).Return(
+		map[string]json.RawMessage{
+			"freewheel/pub1/IAB1-1": json.RawMessage(`"Movies"`),
+		})
+
+	category, err := aFetcherWithCache.FetchCategories(ctx, "freewheel", "pub1", "IAB1-1")
+
+	catCache.AssertExpectations(t)
+	fetcher.AssertExpectations(t)
+	assert.Equal(t, "Movies", category, "FetchCategories should fetch the right category")
+	assert.NoError(t, err, "FetchCategories shouldn't return an error")
+}
+
+func TestCategoryCacheMiss(t *testing.T) {
+	catCache, fetcher, aFetcherWithCache := setupCategoryFetcherWithCacheDeps()
+	ctx := context.Background()
+
+	catCache.On("Get", ctx, []string{"freewheel/pub1/IAB1-1"}).Return(map[string]json.RawMessage{})
+	catCache.On("Save", ctx, map[string]json.RawMessage{"freewheel/pub1/IAB1-1": json.RawMessage(`"Movies"`)})
+	fetcher.On("FetchCategories", ctx, "freewheel", "pub1", "IAB1-1").Return("Movies", nil)
+
+	category, err := aFetcherWithCache.FetchCategories(ctx, "freewheel", "pub1", "IAB1-1")
+
+	catCache.AssertExpectations(t)
+	fetcher.AssertExpectations(t)
+	assert.Equal(t, "Movies", category, "FetchCategories should fetch the right category")
+	assert.NoError(t, err, "FetchCategories shouldn't return an error")
+}
+
+func TestCategoryCacheMissNotFound(t *testing.T) {
+	catCache, fetcher, aFetcherWithCache := setupCategoryFetcherWithCacheDeps()
+	ctx := context.Background()
+
+	catCache.On("Get", ctx, []string{"freewheel//IAB1-1"}).Return(map[string]json.RawMessage{})
+	fetcher.On("FetchCategories", ctx, "freewheel", "", "IAB1-1").Return("", errors.New("Unable to find category mapping"))
+
+	category, err := aFetcherWithCache.FetchCategories(ctx, "freewheel", "", "IAB1-1")
+
+	catCache.AssertExpectations(t)
+	fetcher.AssertExpectations(t)
+	catCache.AssertNotCalled(t, "Save")
+	assert.Empty(t, category, "FetchCategories shouldn't find a category")
+	assert.Error(t, err, "FetchCategories should return the error of the fetcher")
+}
+
 func TestComposedCache(t *testing.T) {
 	c1 := &mockCache{}
 	c2 := &mockCache{}
@@ -272,13 +329,19 @@ func (f *mockFetcher) FetchRequests(ctx context.Context, requestIDs []string, im
 	return args.Get(0).(map[string]json.RawMessage), args.Get(1).(map[string]json.RawMessage), args.Get(2).([]error)
 }
 
+func (f *mockFetcher) FetchResponses(ctx context.Context, ids []string) (map[string]json.RawMessage, []error) {
+	args := f.Called(ctx, ids)
+	return args.Get(0).(map[string]json.RawMessage), args.Get(1).([]error)
+}
+
 func (a *mockFetcher) FetchAccount(ctx context.Context, accountID string) (json.RawMessage, []error) {
 	args := a.Called(ctx, accountID)
 	return args.Get(0).(json.RawMessage), args.Get(1).([]error)
 }
 
 func (f *mockFetcher) FetchCategories(ctx context.Context, primaryAdServer, publisherId, iabCategory string) (string, error) {
-	return "", nil
+	args := f.Called(ctx, primaryAdServer, publisherId, iabCategory)
+	return args.String(0), args.Error(1)
 }
 
 type mockCache struct {
@@ -49,6 +49,35 @@ func TestMultiFetcher(t *testing.T) {
 	assert.JSONEq(t, `{"imp_id": "imp-2"}`, string(impData["imp-2"]), "MultiFetcher should return the right imp data")
 }
 
+func TestMultiFetcherResponses(t *testing.T) {
+	f1 := &mockFetcher{}
+	f2 := &mockFetcher{}
+	fetcher := &MultiFetcher{f1, f2}
+	ctx := context.Background()
+
+	f1.On("FetchResponses", ctx, []string{"resp-1", "resp-2", "resp-3"}).Return(
+		map[string]json.RawMessage{
+			"resp-1": json.RawMessage(`[{"seat":"appnexus"}]`),
+		},
+		[]error{NotFoundError{"resp-2", "Response"}, NotFoundError{"resp-3", "Response"}},
+	)
+	f2.On("FetchResponses", ctx, []string{"resp-2", "resp-3"}).Return(
+		map[string]json.RawMessage{
+			"resp-2": json.RawMessage(`[{"seat":"rubicon"}]`),
+		},
+		[]error{NotFoundError{"resp-3", "Response"}},
+	)
+
+	data, errs := fetcher.FetchResponses(ctx, []string{"resp-1", "resp-2", "resp-3"})
+
+	f1.AssertExpectations(t)
+	f2.AssertExpectations(t)
+	assert.Len(t, data, 2, "MultiFetcher should return all the stored responses that exist")
+	assert.JSONEq(t, `[{"seat":"appnexus"}]`, string(data["resp-1"]), "MultiFetcher should return the right response data")
+	assert.JSONEq(t, `[{"seat":"rubicon"}]`, string(data["resp-2"]), "MultiFetcher should return the right response data")
+	assert.Equal(t, []error{NotFoundError{"resp-3", "Response"}}, errs, "MultiFetcher should return one NotFound error per missing ID")
+}
+
 func TestMissingID(t *testing.T) {
 	f1 := &mockFetcher{}
 	f2 := &mockFetcher{}
@@ -0,0 +1,56 @@
+package stored_requests
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/prebid/prebid-server/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type fetcherWithTracing struct {
+	fetcher  AllFetcher
+	dataType string
+}
+
+// WithTracing returns a Fetcher which records a span for each fetch of the original, labeled with the type
+// of the data fetched.
+func WithTracing(fetcher AllFetcher, dataType string) AllFetcher {
+	return &fetcherWithTracing{
+		fetcher:  fetcher,
+		dataType: dataType,
+	}
+}
+
+func (f *fetcherWithTracing) FetchRequests(ctx context.Context, requestIDs []string, impIDs []string) (requestData map[string]json.RawMessage, impData map[string]json.RawMessage, errs []error) {
+	ctx, span := tracing.StartSpan(ctx, "stored_requests.FetchRequests",
+		attribute.String("stored_requests.data_type", f.dataType),
+		attribute.Int("stored_requests.request_ids", len(requestIDs)),
+		attribute.Int("stored_requests.imp_ids", len(impIDs)))
+	requestData, impData, errs = f.fetcher.FetchRequests(ctx, requestIDs, impIDs)
+	tracing.EndSpanWithErrors(span, errs)
+	return
+}
+
+func (f *fetcherWithTracing) FetchResponses(ctx context.Context, ids []string) (data map[string]json.RawMessage, errs []error) {
+	ctx, span := tracing.StartSpan(ctx, "stored_requests.FetchResponses",
+		attribute.String("stored_requests.data_type", f.dataType),
+		attribute.Int("stored_requests.response_ids", len(ids)))
+	data, errs = f.fetcher.FetchResponses(ctx, ids)
+	tracing.EndSpanWithErrors(span, errs)
+	return
+}
+
+func (f *fetcherWithTracing) FetchAccount(ctx context.Context, accountID string) (json.RawMessage, []error) {
+	ctx, span := tracing.StartSpan(ctx, "stored_requests.FetchAccount",
+		attribute.String("stored_requests.data_type", f.dataType),
+		attribute.String("account.id", accountID))
+	account, errs := f.fetcher.FetchAccount(ctx, accountID)
+	tracing.EndSpanWithErrors(span, errs)
+	return account, errs
+}
+
+func (f *fetcherWithTracing) FetchCategories(ctx context.Context, primaryAdServer, publisherId, iabCategory string) (string, error) {
+	return f.fetcher.FetchCategories(ctx, primaryAdServer, publisherId, iabCategory)
+}
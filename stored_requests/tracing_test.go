@@ -0,0 +1,51 @@
+package stored_requests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestFetcherWithTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	fetcher := &mockFetcher{}
+	fetcher.On("FetchRequests", mock.Anything, []string{"req"}, []string{"imp-1", "imp-2"}).Return(
+		map[string]json.RawMessage{"req": json.RawMessage(`{}`)},
+		map[string]json.RawMessage{"imp-1": json.RawMessage(`{}`), "imp-2": json.RawMessage(`{}`)},
+		[]error{})
+	fetcher.On("FetchAccount", mock.Anything, "unknown").Return(json.RawMessage(nil), []error{errors.New("account not found")})
+	tracedFetcher := WithTracing(fetcher, "Request")
+
+	requestData, impData, errs := tracedFetcher.FetchRequests(context.Background(), []string{"req"}, []string{"imp-1", "imp-2"})
+	assert.Len(t, requestData, 1)
+	assert.Len(t, impData, 2)
+	assert.Empty(t, errs)
+
+	_, errs = tracedFetcher.FetchAccount(context.Background(), "unknown")
+	assert.Len(t, errs, 1)
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 2) {
+		assert.Equal(t, "stored_requests.FetchRequests", spans[0].Name())
+		assert.Contains(t, spans[0].Attributes(), attribute.String("stored_requests.data_type", "Request"))
+		assert.Contains(t, spans[0].Attributes(), attribute.Int("stored_requests.imp_ids", 2))
+		assert.Equal(t, codes.Unset, spans[0].Status().Code)
+
+		assert.Equal(t, "stored_requests.FetchAccount", spans[1].Name())
+		assert.Contains(t, spans[1].Attributes(), attribute.String("account.id", "unknown"))
+		assert.Equal(t, codes.Error, spans[1].Status().Code)
+	}
+}
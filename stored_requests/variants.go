@@ -0,0 +1,72 @@
+package stored_requests
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/buger/jsonparser"
+)
+
+// storedVariants defines the format of the Stored Requests and Imps split into weighted variants, for example:
+//
+//	{"variants":[{"id":"control","weight":90,"data":{...}},{"id":"test","weight":10,"data":{...}}]}
+type storedVariants struct {
+	Variants []storedVariant `json:"variants"`
+}
+
+type storedVariant struct {
+	// ID identifies the variant in the responses and analytics. It defaults to the index of the variant.
+	ID     string          `json:"id"`
+	Weight int             `json:"weight"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// PickVariant returns the data of one of the variants of the stored data, picked at random in proportion to their
+// weights, along with the ID of the variant. A variant with a weight of 0 is never picked.
+//
+// The data which isn't split into variants is returned unchanged, with an empty ID.
+func PickVariant(data json.RawMessage, randInt func(int) int) (json.RawMessage, string, error) {
+	if _, dataType, _, err := jsonparser.Get(data, "variants"); err != nil || dataType != jsonparser.Array {
+		return data, "", nil
+	}
+
+	var stored storedVariants
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, "", err
+	}
+	if len(stored.Variants) == 0 {
+		return nil, "", errors.New("the variants list is empty")
+	}
+
+	totalWeight := 0
+	for i, variant := range stored.Variants {
+		if variant.Weight < 0 {
+			return nil, "", fmt.Errorf("variant %s has a negative weight", variantID(variant, i))
+		}
+		totalWeight += variant.Weight
+	}
+	if totalWeight == 0 {
+		return nil, "", errors.New("none of the variants has a positive weight")
+	}
+
+	pick := randInt(totalWeight)
+	for i, variant := range stored.Variants {
+		pick -= variant.Weight
+		if pick < 0 {
+			if len(variant.Data) == 0 {
+				return nil, "", fmt.Errorf("variant %s has no data", variantID(variant, i))
+			}
+			return variant.Data, variantID(variant, i), nil
+		}
+	}
+	return nil, "", errors.New("no variant was picked")
+}
+
+func variantID(variant storedVariant, index int) string {
+	if variant.ID == "" {
+		return strconv.Itoa(index)
+	}
+	return variant.ID
+}
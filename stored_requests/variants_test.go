@@ -0,0 +1,95 @@
+package stored_requests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPickVariant(t *testing.T) {
+	testCases := []struct {
+		description     string
+		data            string
+		pick            int
+		expectedData    string
+		expectedVariant string
+		expectedErr     string
+	}{
+		{
+			description:  "No variants",
+			data:         `{"id":"stored","tmax":500}`,
+			expectedData: `{"id":"stored","tmax":500}`,
+		},
+		{
+			description:     "First variant",
+			data:            `{"variants":[{"id":"control","weight":90,"data":{"tmax":500}},{"id":"test","weight":10,"data":{"tmax":300}}]}`,
+			pick:            89,
+			expectedData:    `{"tmax":500}`,
+			expectedVariant: "control",
+		},
+		{
+			description:     "Second variant",
+			data:            `{"variants":[{"id":"control","weight":90,"data":{"tmax":500}},{"id":"test","weight":10,"data":{"tmax":300}}]}`,
+			pick:            90,
+			expectedData:    `{"tmax":300}`,
+			expectedVariant: "test",
+		},
+		{
+			description:     "Variant without an id",
+			data:            `{"variants":[{"weight":1,"data":{"tmax":500}},{"weight":1,"data":{"tmax":300}}]}`,
+			pick:            1,
+			expectedData:    `{"tmax":300}`,
+			expectedVariant: "1",
+		},
+		{
+			description:     "Variant without a weight",
+			data:            `{"variants":[{"id":"off","data":{"tmax":500}},{"id":"on","weight":1,"data":{"tmax":300}}]}`,
+			pick:            0,
+			expectedData:    `{"tmax":300}`,
+			expectedVariant: "on",
+		},
+		{
+			description: "Empty variants",
+			data:        `{"variants":[]}`,
+			expectedErr: "the variants list is empty",
+		},
+		{
+			description: "Negative weight",
+			data:        `{"variants":[{"id":"control","weight":-1,"data":{}}]}`,
+			expectedErr: "variant control has a negative weight",
+		},
+		{
+			description: "No positive weight",
+			data:        `{"variants":[{"id":"control","weight":0,"data":{}}]}`,
+			expectedErr: "none of the variants has a positive weight",
+		},
+		{
+			description: "Variant without data",
+			data:        `{"variants":[{"weight":1}]}`,
+			expectedErr: "variant 0 has no data",
+		},
+		{
+			description: "Malformed variants",
+			data:        `{"variants":[{"weight":"1"}]}`,
+			expectedErr: "cannot unmarshal string",
+		},
+	}
+
+	for _, test := range testCases {
+		data, variant, err := PickVariant(json.RawMessage(test.data), func(n int) int {
+			return test.pick
+		})
+
+		if test.expectedErr != "" {
+			if assert.Error(t, err, test.description) {
+				assert.Contains(t, err.Error(), test.expectedErr, test.description)
+			}
+			continue
+		}
+		if assert.NoError(t, err, test.description) {
+			assert.JSONEq(t, test.expectedData, string(data), test.description+":data")
+			assert.Equal(t, test.expectedVariant, variant, test.description+":variant")
+		}
+	}
+}
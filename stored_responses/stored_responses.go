@@ -0,0 +1,129 @@
+package stored_responses
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/stored_requests"
+)
+
+// ImpsWithBidResponses maps the IDs of the imps with a stored auction response to the stored seatbids,
+// which are returned in place of the bids of the bidders.
+type ImpsWithBidResponses map[string]json.RawMessage
+
+// BidderImpsWithBidResponses maps the bidders to the IDs of their imps with a stored bid response, and to
+// the stored bodies of the responses, which are fed to the bidders instead of calling them.
+type BidderImpsWithBidResponses map[openrtb_ext.BidderName]map[string]json.RawMessage
+
+// ProcessStoredResponses fetches the stored responses set in imp.ext.prebid.storedauctionresponse and
+// imp.ext.prebid.storedbidresponse.
+//
+// If one imp has a stored auction response, all of them must have one, since the bidders aren't called at all.
+// The requests setting stored responses are rejected unless the host has them enabled.
+func ProcessStoredResponses(ctx context.Context, request *openrtb2.BidRequest, fetcher stored_requests.Fetcher, enabled bool) (ImpsWithBidResponses, BidderImpsWithBidResponses, []error) {
+	auctionResponseIDs := make(map[string]string)
+	bidResponseIDs := make(map[openrtb_ext.BidderName]map[string]string)
+	var ids []string
+
+	for i, imp := range request.Imp {
+		extPrebid, bidders, err := parseImpExt(imp.Ext)
+		if err != nil {
+			return nil, nil, []error{fmt.Errorf("request.imp[%d].ext is invalid: %v", i, err)}
+		}
+
+		if extPrebid.StoredAuctionResponse != nil {
+			if len(extPrebid.StoredBidResponse) > 0 {
+				return nil, nil, []error{fmt.Errorf("request.imp[%d].ext.prebid has both a storedauctionresponse and a storedbidresponse", i)}
+			}
+			if extPrebid.StoredAuctionResponse.ID == "" {
+				return nil, nil, []error{fmt.Errorf("request.imp[%d].ext.prebid.storedauctionresponse.id is required", i)}
+			}
+			auctionResponseIDs[imp.ID] = extPrebid.StoredAuctionResponse.ID
+			ids = append(ids, extPrebid.StoredAuctionResponse.ID)
+			continue
+		}
+
+		for j, bidResponse := range extPrebid.StoredBidResponse {
+			if bidResponse.Bidder == "" || bidResponse.ID == "" {
+				return nil, nil, []error{fmt.Errorf("request.imp[%d].ext.prebid.storedbidresponse[%d] requires a bidder and an id", i, j)}
+			}
+			if _, ok := bidders[bidResponse.Bidder]; !ok {
+				return nil, nil, []error{fmt.Errorf("request.imp[%d].ext.prebid.storedbidresponse[%d].bidder %s isn't a bidder of the imp", i, j, bidResponse.Bidder)}
+			}
+			bidder := openrtb_ext.BidderName(bidResponse.Bidder)
+			if bidResponseIDs[bidder] == nil {
+				bidResponseIDs[bidder] = make(map[string]string)
+			}
+			bidResponseIDs[bidder][imp.ID] = bidResponse.ID
+			ids = append(ids, bidResponse.ID)
+		}
+	}
+
+	if len(auctionResponseIDs) > 0 && len(auctionResponseIDs) != len(request.Imp) {
+		return nil, nil, []error{errors.New("request.imp[].ext.prebid.storedauctionresponse must be set on all the imps or on none of them")}
+	}
+	if len(ids) == 0 {
+		return nil, nil, nil
+	}
+	if !enabled {
+		return nil, nil, []error{errors.New("request.imp[].ext.prebid.storedauctionresponse and storedbidresponse aren't enabled on this host")}
+	}
+
+	data, errs := fetcher.FetchResponses(ctx, ids)
+	if len(errs) > 0 {
+		return nil, nil, errs
+	}
+
+	var impsWithBidResponses ImpsWithBidResponses
+	if len(auctionResponseIDs) > 0 {
+		impsWithBidResponses = make(ImpsWithBidResponses, len(auctionResponseIDs))
+		for impID, id := range auctionResponseIDs {
+			impsWithBidResponses[impID] = data[id]
+		}
+	}
+
+	var bidderImpsWithBidResponses BidderImpsWithBidResponses
+	if len(bidResponseIDs) > 0 {
+		bidderImpsWithBidResponses = make(BidderImpsWithBidResponses, len(bidResponseIDs))
+		for bidder, impIDs := range bidResponseIDs {
+			bidderImpsWithBidResponses[bidder] = make(map[string]json.RawMessage, len(impIDs))
+			for impID, id := range impIDs {
+				bidderImpsWithBidResponses[bidder][impID] = data[id]
+			}
+		}
+	}
+
+	return impsWithBidResponses, bidderImpsWithBidResponses, nil
+}
+
+// parseImpExt returns the imp.ext.prebid of the imp, along with the names of its bidders, which are the keys of
+// imp.ext.prebid.bidder or, in the legacy format, of imp.ext.
+func parseImpExt(ext json.RawMessage) (openrtb_ext.ExtImpPrebid, map[string]struct{}, error) {
+	var extPrebid openrtb_ext.ExtImpPrebid
+	if len(ext) == 0 {
+		return extPrebid, nil, nil
+	}
+
+	var impExt map[string]json.RawMessage
+	if err := json.Unmarshal(ext, &impExt); err != nil {
+		return extPrebid, nil, err
+	}
+	if prebidJSON, ok := impExt[openrtb_ext.PrebidExtKey]; ok {
+		if err := json.Unmarshal(prebidJSON, &extPrebid); err != nil {
+			return extPrebid, nil, err
+		}
+	}
+
+	bidders := make(map[string]struct{}, len(impExt)+len(extPrebid.Bidder))
+	for bidder := range impExt {
+		bidders[bidder] = struct{}{}
+	}
+	for bidder := range extPrebid.Bidder {
+		bidders[bidder] = struct{}{}
+	}
+	return extPrebid, bidders, nil
+}
@@ -0,0 +1,144 @@
+package stored_responses
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/stored_requests"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessStoredResponses(t *testing.T) {
+	fetcher := &mockResponsesFetcher{
+		data: map[string]json.RawMessage{
+			"auction-1": json.RawMessage(`[{"seat":"appnexus","bid":[{"id":"bid-1","price":1}]}]`),
+			"auction-2": json.RawMessage(`[{"seat":"rubicon","bid":[{"id":"bid-2","price":2}]}]`),
+			"bid-1":     json.RawMessage(`{"id":"bid-response-1"}`),
+			"bid-2":     json.RawMessage(`{"id":"bid-response-2"}`),
+		},
+	}
+
+	testCases := []struct {
+		description                string
+		imps                       []string
+		disabled                   bool
+		expectedAuctionResponses   ImpsWithBidResponses
+		expectedBidderBidResponses BidderImpsWithBidResponses
+		expectedErrs               []error
+	}{
+		{
+			description: "No stored responses",
+			imps:        []string{`{"appnexus":{"placementId":1}}`},
+		},
+		{
+			description: "Stored auction responses",
+			imps: []string{
+				`{"appnexus":{"placementId":1},"prebid":{"storedauctionresponse":{"id":"auction-1"}}}`,
+				`{"prebid":{"bidder":{"rubicon":{}},"storedauctionresponse":{"id":"auction-2"}}}`,
+			},
+			expectedAuctionResponses: ImpsWithBidResponses{
+				"imp-0": json.RawMessage(`[{"seat":"appnexus","bid":[{"id":"bid-1","price":1}]}]`),
+				"imp-1": json.RawMessage(`[{"seat":"rubicon","bid":[{"id":"bid-2","price":2}]}]`),
+			},
+		},
+		{
+			description: "Stored bid responses",
+			imps: []string{
+				`{"appnexus":{"placementId":1},"prebid":{"storedbidresponse":[{"bidder":"appnexus","id":"bid-1"}]}}`,
+				`{"prebid":{"bidder":{"appnexus":{},"rubicon":{}},"storedbidresponse":[{"bidder":"appnexus","id":"bid-1"},{"bidder":"rubicon","id":"bid-2"}]}}`,
+				`{"rubicon":{}}`,
+			},
+			expectedBidderBidResponses: BidderImpsWithBidResponses{
+				"appnexus": {
+					"imp-0": json.RawMessage(`{"id":"bid-response-1"}`),
+					"imp-1": json.RawMessage(`{"id":"bid-response-1"}`),
+				},
+				"rubicon": {
+					"imp-1": json.RawMessage(`{"id":"bid-response-2"}`),
+				},
+			},
+		},
+		{
+			description: "Stored auction response on some of the imps",
+			imps: []string{
+				`{"appnexus":{},"prebid":{"storedauctionresponse":{"id":"auction-1"}}}`,
+				`{"appnexus":{}}`,
+			},
+			expectedErrs: []error{errors.New("request.imp[].ext.prebid.storedauctionresponse must be set on all the imps or on none of them")},
+		},
+		{
+			description:  "Stored auction response without an id",
+			imps:         []string{`{"appnexus":{},"prebid":{"storedauctionresponse":{}}}`},
+			expectedErrs: []error{errors.New("request.imp[0].ext.prebid.storedauctionresponse.id is required")},
+		},
+		{
+			description:  "Both stored auction and bid responses",
+			imps:         []string{`{"appnexus":{},"prebid":{"storedauctionresponse":{"id":"auction-1"},"storedbidresponse":[{"bidder":"appnexus","id":"bid-1"}]}}`},
+			expectedErrs: []error{errors.New("request.imp[0].ext.prebid has both a storedauctionresponse and a storedbidresponse")},
+		},
+		{
+			description:  "Stored bid response without a bidder",
+			imps:         []string{`{"appnexus":{},"prebid":{"storedbidresponse":[{"id":"bid-1"}]}}`},
+			expectedErrs: []error{errors.New("request.imp[0].ext.prebid.storedbidresponse[0] requires a bidder and an id")},
+		},
+		{
+			description:  "Stored bid response for another bidder",
+			imps:         []string{`{"appnexus":{},"prebid":{"storedbidresponse":[{"bidder":"rubicon","id":"bid-2"}]}}`},
+			expectedErrs: []error{errors.New("request.imp[0].ext.prebid.storedbidresponse[0].bidder rubicon isn't a bidder of the imp")},
+		},
+		{
+			description:  "Unknown stored response",
+			imps:         []string{`{"appnexus":{},"prebid":{"storedbidresponse":[{"bidder":"appnexus","id":"unknown"}]}}`},
+			expectedErrs: []error{stored_requests.NotFoundError{ID: "unknown", DataType: "Response"}},
+		},
+		{
+			description:  "Stored responses disabled",
+			imps:         []string{`{"appnexus":{},"prebid":{"storedauctionresponse":{"id":"auction-1"}}}`},
+			disabled:     true,
+			expectedErrs: []error{errors.New("request.imp[].ext.prebid.storedauctionresponse and storedbidresponse aren't enabled on this host")},
+		},
+		{
+			description: "No stored responses with stored responses disabled",
+			imps:        []string{`{"appnexus":{"placementId":1}}`},
+			disabled:    true,
+		},
+	}
+
+	for _, test := range testCases {
+		request := &openrtb2.BidRequest{ID: "request"}
+		for i, ext := range test.imps {
+			request.Imp = append(request.Imp, openrtb2.Imp{ID: fmt.Sprintf("imp-%d", i), Ext: json.RawMessage(ext)})
+		}
+
+		auctionResponses, bidderBidResponses, errs := ProcessStoredResponses(context.Background(), request, fetcher, !test.disabled)
+
+		assert.Equal(t, test.expectedAuctionResponses, auctionResponses, test.description+":auction_responses")
+		assert.Equal(t, test.expectedBidderBidResponses, bidderBidResponses, test.description+":bid_responses")
+		assert.Equal(t, test.expectedErrs, errs, test.description+":errors")
+	}
+}
+
+type mockResponsesFetcher struct {
+	data map[string]json.RawMessage
+}
+
+func (f *mockResponsesFetcher) FetchRequests(ctx context.Context, requestIDs []string, impIDs []string) (map[string]json.RawMessage, map[string]json.RawMessage, []error) {
+	return nil, nil, nil
+}
+
+func (f *mockResponsesFetcher) FetchResponses(ctx context.Context, ids []string) (map[string]json.RawMessage, []error) {
+	data := make(map[string]json.RawMessage, len(ids))
+	var errs []error
+	for _, id := range ids {
+		if value, ok := f.data[id]; ok {
+			data[id] = value
+		} else {
+			errs = append(errs, stored_requests.NotFoundError{ID: id, DataType: "Response"})
+		}
+	}
+	return data, errs
+}
@@ -0,0 +1,169 @@
+// Package tracing records the spans of the requests handled by Prebid Server, and of the calls made to serve them,
+// so that the time spent in an auction can be broken down.
+//
+// The spans are sent to the OpenTelemetry provider set by NewProvider. Until it's called, the spans are noops.
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/prebid/prebid-server/config"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const instrumentationName = "github.com/prebid/prebid-server"
+
+// NewProvider sets the global provider to one exporting the spans to the configured OTLP/HTTP collector, and the
+// global propagator to the W3C trace context. The returned function flushes the spans which weren't exported yet.
+func NewProvider(cfg config.Tracing) (shutdown func(), err error) {
+	options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+	if cfg.Insecure {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(context.Background(), options...)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRate))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(cfg.ServiceName))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return func() {
+		provider.Shutdown(context.Background())
+	}, nil
+}
+
+// StartSpan starts a span as a child of the span held by ctx, if any.
+func StartSpan(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// EndSpan ends the span, flagged as failed if err isn't nil.
+func EndSpan(span trace.Span, err error) {
+	SetError(span, err)
+	span.End()
+}
+
+// SetError flags the span as failed if err isn't nil.
+func SetError(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// EndSpanWithErrors ends the span, flagged as failed if there are any errors.
+func EndSpanWithErrors(span trace.Span, errs []error) {
+	for _, err := range errs {
+		span.RecordError(err)
+	}
+	if len(errs) > 0 {
+		span.SetStatus(codes.Error, errs[0].Error())
+	}
+	span.End()
+}
+
+// Inject adds the trace context of the span held by ctx to the headers of an outgoing request.
+func Inject(ctx context.Context, header http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// RequestContext returns a context holding the span of the request, so that the spans started from it belong to the
+// request's trace. Unlike the request's context, it isn't canceled when the client goes away.
+func RequestContext(r *http.Request) context.Context {
+	return trace.ContextWithSpan(context.Background(), trace.SpanFromContext(r.Context()))
+}
+
+// Middleware wraps the handler with a span for each request it serves. The span continues the trace of the client
+// when the request carries a trace context.
+func Middleware(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := otel.Tracer(instrumentationName).Start(ctx, "HTTP "+r.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPMethodKey.String(r.Method),
+				semconv.HTTPTargetKey.String(r.URL.Path),
+			))
+		defer span.End()
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		handler.ServeHTTP(recorder, r.WithContext(ctx))
+
+		span.SetAttributes(semconv.HTTPStatusCodeKey.Int(recorder.status))
+		if recorder.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(recorder.status))
+		}
+	})
+}
+
+// UnaryServerInterceptor is the counterpart of Middleware for the gRPC server. The trace context of the client is
+// read from the request's metadata.
+func UnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+	ctx, span := otel.Tracer(instrumentationName).Start(ctx, "gRPC "+info.FullMethod,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.RPCSystemKey.String("grpc"),
+			semconv.RPCMethodKey.String(info.FullMethod),
+		))
+	defer span.End()
+
+	resp, err := handler(ctx, req)
+
+	span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(status.Code(err))))
+	SetError(span, err)
+	return resp, err
+}
+
+// metadataCarrier lets the propagator read the trace context from gRPC metadata.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if values := metadata.MD(c).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// statusRecorder keeps the status code written by the handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
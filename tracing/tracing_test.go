@@ -0,0 +1,118 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestMiddleware(t *testing.T) {
+	recorder := setupRecorder()
+
+	var handlerSpan trace.SpanContext
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlerSpan = trace.SpanContextFromContext(r.Context())
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+
+	request := httptest.NewRequest("POST", "/openrtb2/auction", nil)
+	request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 1) {
+		span := spans[0]
+		assert.Equal(t, "HTTP POST", span.Name())
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String(), "The client's trace should be continued")
+		assert.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+		assert.Equal(t, span.SpanContext().SpanID(), handlerSpan.SpanID(), "The handler should get the request span")
+		assert.Contains(t, span.Attributes(), semconv.HTTPTargetKey.String("/openrtb2/auction"))
+		assert.Contains(t, span.Attributes(), semconv.HTTPStatusCodeKey.Int(http.StatusBadRequest))
+		assert.Equal(t, codes.Unset, span.Status().Code, "Client errors shouldn't flag the span as failed")
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	recorder := setupRecorder()
+
+	var handlerSpan trace.SpanContext
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		handlerSpan = trace.SpanContextFromContext(ctx)
+		return nil, status.Error(grpccodes.Internal, "auction failed")
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"))
+	_, err := UnaryServerInterceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/prebid.AuctionService/Auction"}, handler)
+	assert.Error(t, err)
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 1) {
+		span := spans[0]
+		assert.Equal(t, "gRPC /prebid.AuctionService/Auction", span.Name())
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String(), "The client's trace should be continued")
+		assert.Equal(t, span.SpanContext().SpanID(), handlerSpan.SpanID(), "The handler should get the request span")
+		assert.Contains(t, span.Attributes(), semconv.RPCGRPCStatusCodeKey.Int(int(grpccodes.Internal)))
+		assert.Equal(t, codes.Error, span.Status().Code)
+	}
+}
+
+func TestRequestContext(t *testing.T) {
+	setupRecorder()
+
+	requestCtx, cancel := context.WithCancel(context.Background())
+	requestCtx, span := StartSpan(requestCtx, "request")
+	cancel()
+
+	ctx := RequestContext(httptest.NewRequest("GET", "/", nil).WithContext(requestCtx))
+
+	assert.Equal(t, span.SpanContext(), trace.SpanContextFromContext(ctx))
+	assert.NoError(t, ctx.Err(), "The request cancellation shouldn't be kept")
+}
+
+func TestEndSpanWithErrors(t *testing.T) {
+	recorder := setupRecorder()
+
+	_, span := StartSpan(context.Background(), "fetch")
+	EndSpanWithErrors(span, []error{errors.New("first"), errors.New("second")})
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 1) {
+		assert.Equal(t, codes.Error, spans[0].Status().Code)
+		assert.Equal(t, "first", spans[0].Status().Description)
+		assert.Len(t, spans[0].Events(), 2, "Every error should be recorded")
+	}
+}
+
+func TestInject(t *testing.T) {
+	setupRecorder()
+
+	ctx, span := StartSpan(context.Background(), "bidder.request")
+	header := http.Header{}
+	Inject(ctx, header)
+
+	expected := "00-" + span.SpanContext().TraceID().String() + "-" + span.SpanContext().SpanID().String() + "-01"
+	assert.Equal(t, expected, header.Get("traceparent"))
+}
+
+// setupRecorder sets the global provider to one recording every span.
+func setupRecorder() *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return recorder
+}
@@ -205,6 +205,17 @@ func NewSyncerMap(cfg *config.Configuration) map[openrtb_ext.BidderName]usersync
 }
 
 func insertIntoMap(cfg *config.Configuration, syncers map[openrtb_ext.BidderName]usersync.Usersyncer, bidder openrtb_ext.BidderName, syncerFactory func(*template.Template) usersync.Usersyncer) {
+	insertSyncer(cfg, syncers, bidder, syncerFactory)
+
+	// Hard aliases sync like their core bidder, but under their own family name
+	for alias, adapterCfg := range cfg.Adapters {
+		if strings.EqualFold(adapterCfg.AliasOf, string(bidder)) {
+			insertSyncer(cfg, syncers, openrtb_ext.BidderName(alias), newAliasSyncerFactory(alias, syncerFactory))
+		}
+	}
+}
+
+func insertSyncer(cfg *config.Configuration, syncers map[openrtb_ext.BidderName]usersync.Usersyncer, bidder openrtb_ext.BidderName, syncerFactory func(*template.Template) usersync.Usersyncer) {
 	lowercased := strings.ToLower(string(bidder))
 	urlString := cfg.Adapters[lowercased].UserSyncURL
 	if urlString == "" {
@@ -213,3 +224,19 @@ func insertIntoMap(cfg *config.Configuration, syncers map[openrtb_ext.BidderName
 	}
 	syncers[bidder] = syncerFactory(template.Must(template.New(lowercased + "_usersync_url").Parse(urlString)))
 }
+
+func newAliasSyncerFactory(alias string, parentFactory func(*template.Template) usersync.Usersyncer) func(*template.Template) usersync.Usersyncer {
+	return func(urlTemplate *template.Template) usersync.Usersyncer {
+		return aliasSyncer{Usersyncer: parentFactory(urlTemplate), familyName: alias}
+	}
+}
+
+// aliasSyncer is the syncer of a hard alias, which stores its user IDs apart from its core bidder.
+type aliasSyncer struct {
+	usersync.Usersyncer
+	familyName string
+}
+
+func (s aliasSyncer) FamilyName() string {
+	return s.familyName
+}
@@ -6,6 +6,9 @@ import (
 
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/privacy"
+	"github.com/prebid/prebid-server/usersync"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestNewSyncerMap(t *testing.T) {
@@ -152,3 +155,26 @@ func TestNewSyncerMap(t *testing.T) {
 		}
 	}
 }
+
+func TestNewSyncerMapHardAliases(t *testing.T) {
+	cfg := &config.Configuration{
+		Adapters: map[string]config.Adapter{
+			"appnexus":   {UserSyncURL: "https://appnexus.com/sync"},
+			"whitelabel": {AliasOf: "appnexus", UserSyncURL: "https://whitelabel.com/sync"},
+			"nosync":     {AliasOf: "appnexus"},
+		},
+	}
+
+	syncers := NewSyncerMap(cfg)
+
+	assert.Len(t, syncers, 2)
+	if assert.Contains(t, syncers, openrtb_ext.BidderName("whitelabel")) {
+		syncer := syncers["whitelabel"]
+		assert.Equal(t, "whitelabel", syncer.FamilyName())
+		assert.Equal(t, "adnxs", syncers[openrtb_ext.BidderAppnexus].FamilyName())
+
+		syncInfo, err := syncer.GetUsersyncInfo(privacy.Policies{})
+		assert.NoError(t, err)
+		assert.Equal(t, &usersync.UsersyncInfo{URL: "https://whitelabel.com/sync", Type: "redirect", SupportCORS: false}, syncInfo)
+	}
+}